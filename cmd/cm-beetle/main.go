@@ -17,13 +17,20 @@ package main
 import (
 	"context"
 	"flag"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	tbmodel "github.com/cloud-barista/cb-tumblebug/src/core/model"
 	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/compat"
 	"github.com/cloud-barista/cm-beetle/pkg/config"
+	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/cloud-barista/cm-beetle/pkg/core/notification"
+	"github.com/cloud-barista/cm-beetle/pkg/core/policy"
 	"github.com/cloud-barista/cm-beetle/pkg/lkvstore"
 	"github.com/cloud-barista/cm-beetle/pkg/logger"
 	"github.com/go-resty/resty/v2"
@@ -72,6 +79,48 @@ func init() {
 		DbFilePath: dbFilePath,
 	})
 
+	// Load (or generate) the key encrypting the credentials of the wave plans, next to the lkvstore file
+	waveKeyFilePath := filepath.Join(filepath.Dir(dbFilePath), "wave-secret.pem")
+	if err := migration.InitWaveSecretKey(waveKeyFilePath); err != nil {
+		// * Note: Beetle does not start with an existing key it cannot load, since the stored wave plans cannot be resumed without it.
+		if _, statErr := os.Stat(waveKeyFilePath); statErr == nil {
+			log.Fatal().Err(err).Msg("failed to load the wave secret key. Exiting...")
+		}
+		log.Error().Err(err).Msg("failed to initialize the wave secret key; wave plans with credentials cannot be created")
+	}
+
+	// Load additional compatibility rule sets (the built-in rule sets are loaded by default)
+	if config.Beetle.Compat.RulesPath != "" {
+		loaded, err := compat.LoadRuleSetsFromDir(config.Beetle.Compat.RulesPath)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to load compatibility rule sets; the built-in rule sets are used")
+		} else {
+			log.Info().Msgf("loaded compatibility rule sets: %v", loaded)
+		}
+	}
+
+	// Load the migration policies (no policy is enforced by default)
+	if config.Beetle.Policy.Path != "" {
+		// * Note: Beetle does not start without the configured policies, so that the guardrails do not fail open.
+		loaded, err := policy.LoadPoliciesFromDir(config.Beetle.Policy.Path)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load migration policies. Exiting...")
+		}
+		log.Info().Msgf("loaded migration policies: %v", loaded)
+	}
+
+	// Re-evaluate the policies right before each wave, since they may have changed after the wave plan was created
+	migration.OnWaveExecuting(policy.EvaluateWave)
+
+	// Set the outbound notifications (disabled if no webhook is set)
+	notification.Init(notification.Config{
+		WebhookUrls: strings.Split(config.Beetle.Notification.Webhook.Urls, ","),
+		Secret:      config.Beetle.Notification.Webhook.Secret,
+		MaxAttempts: config.Beetle.Notification.MaxAttempts,
+		// The outbox is kept in its own file, so queueing an event does not rewrite the whole lkvstore file
+		OutboxFilePath: filepath.Join(filepath.Dir(dbFilePath), "notification-outbox.json"),
+	})
+
 	// Check Tumblebug readiness
 	apiUrl := config.Tumblebug.RestUrl + "/readyz"
 	isReady, err := checkReadiness(apiUrl)
@@ -217,6 +266,11 @@ func main() {
 	defer cleanupCancel()
 	common.StartRequestCleanupScheduler(cleanupCtx, 24*time.Hour, common.DefaultRequestRetentionPeriod)
 
+	// Start delivering the notifications, including the ones left in the outbox before a restart
+	if notification.Enabled() {
+		notification.StartDispatcher(cleanupCtx, 30*time.Second)
+	}
+
 	defer func() {
 		// Save the current state of the key-value store to file
 		if err := lkvstore.SaveLkvStore(); err != nil {
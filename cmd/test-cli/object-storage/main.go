@@ -284,10 +284,10 @@ func runTestCase(idx int, tc TestCase, cfg TestConfig, osRequest OsRequest, name
 		stopTesting = true
 	}
 
-	// Compute expected osIds using Late Binding: ComposeName(baseName, nameSeed) = "my-os-01"
+	// Compute expected osIds using Late Binding: ComposeBucketName(baseName, nameSeed) = "my-data-3f9a1c"
 	var osIds []string
 	for _, target := range recommendation.TargetObjectStorages {
-		osIds = append(osIds, common.ComposeName(target.BucketName, nameSeed))
+		osIds = append(osIds, common.ComposeBucketName(target.BucketName, nameSeed))
 	}
 
 	// Test 2: Migrate (create buckets)
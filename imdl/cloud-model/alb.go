@@ -0,0 +1,96 @@
+package cloudmodel
+
+// ============================================================================
+// ALB (L7 application load balancer) types
+// CB-Tumblebug provides L4 NLBs only, so these are Beetle-side models.
+// ============================================================================
+
+// L7 support levels of the target CSP
+const (
+	L7SupportNative = "native"  // The CSP offers a managed L7 load balancer
+	L7SupportL4Only = "l4-only" // The CSP offers L4 load balancers only; L7 features need a self-hosted proxy
+)
+
+// AlbReq is the recommended L7 application load balancer for an HTTP-mode source frontend
+// (one per source listener; routing rules select among the target groups).
+type AlbReq struct {
+	Name               string              `json:"name"`
+	Description        string              `json:"description,omitempty"`
+	Type               string              `json:"type"`                 // PUBLIC | INTERNAL
+	Scope              string              `json:"scope"`                // REGION | GLOBAL
+	L7Support          string              `json:"l7Support"`            // native | l4-only
+	CspService         string              `json:"cspService,omitempty"` // Managed L7 service of the target CSP (e.g., "Application Load Balancer")
+	Listener           AlbListenerReq      `json:"listener"`
+	TargetGroups       []AlbTargetGroupReq `json:"targetGroups"`
+	Rules              []AlbRoutingRuleReq `json:"rules,omitempty"`
+	DefaultTargetGroup string              `json:"defaultTargetGroup"` // Name of the target group serving unmatched requests
+	Notes              []string            `json:"notes,omitempty"`    // Remarks for manual review (e.g., L4-only CSP, unmapped features)
+}
+
+// AlbListenerReq is the listener of an ALB.
+type AlbListenerReq struct {
+	Protocol    string             `json:"protocol"` // HTTP | HTTPS
+	Port        string             `json:"port"`     // "1"–"65535"
+	Certificate *AlbCertificateReq `json:"certificate,omitempty"`
+}
+
+// AlbCertificateReq is a TLS certificate placeholder for an HTTPS listener.
+// Certificates are not collected from the source; replace the placeholder with
+// a certificate registered in the target CSP (e.g., an ACM certificate ARN).
+type AlbCertificateReq struct {
+	Placeholder string   `json:"placeholder" example:"<certificate-for-listener-443>"`
+	Hosts       []string `json:"hosts,omitempty"` // Host names the certificate must cover
+}
+
+// AlbTargetGroupReq is a target group of an ALB.
+type AlbTargetGroupReq struct {
+	Name          string              `json:"name"`
+	Protocol      string              `json:"protocol"`    // HTTP | HTTPS
+	Port          string              `json:"port"`        // Backend port
+	NodeGroupId   string              `json:"nodeGroupId"` // NodeGroup ID in the target Infra
+	HealthChecker AlbHealthCheckerReq `json:"healthChecker"`
+}
+
+// AlbHealthCheckerReq is the health check of an ALB target group.
+type AlbHealthCheckerReq struct {
+	Protocol  string `json:"protocol"`       // HTTP | TCP
+	Path      string `json:"path,omitempty"` // HTTP health check path (e.g., "/health")
+	Port      string `json:"port,omitempty"` // Empty = same as the target group port
+	Interval  int    `json:"interval"`       // Health check interval in seconds
+	Threshold int    `json:"threshold"`      // Unhealthy threshold count
+	Timeout   int    `json:"timeout"`        // Health check timeout in seconds
+}
+
+// AlbRoutingRuleReq is a host/path routing rule of an ALB listener.
+// Conditions of different kinds are ANDed; values of the same kind are ORed.
+type AlbRoutingRuleReq struct {
+	Priority     int      `json:"priority"` // Lower value is evaluated first
+	Hosts        []string `json:"hosts,omitempty"`
+	PathPrefixes []string `json:"pathPrefixes,omitempty"`
+	TargetGroup  string   `json:"targetGroup"` // Name of the target group
+}
+
+// RecommendedAlb is the request body for POST /migration/middleware/ns/{nsId}/infra/{infraId}/alb.
+// Use the TargetAlbList field from RecommendedInfra directly as input.
+type RecommendedAlb struct {
+	TargetAlbList []AlbReq `json:"targetAlbList" validate:"required"`
+}
+
+// MigratedAlbResult is the response returned after ALB migration.
+type MigratedAlbResult struct {
+	Status      string            `json:"status"` // "created" | "partial" | "failed"
+	Description string            `json:"description"`
+	AlbList     []MigratedAlbInfo `json:"albList"`
+}
+
+// MigratedAlbInfo is the migration result of an ALB.
+// Since CB-Tumblebug provisions L4 NLBs only, the listener is provisioned as an L4 NLB
+// forwarding to the default target group, and L7 features are returned as manual actions.
+type MigratedAlbInfo struct {
+	Name          string           `json:"name"`
+	L7Support     string           `json:"l7Support"`
+	Provisioning  string           `json:"provisioning"` // "l4-fallback" | "existing" | "failed"
+	Nlb           *MigratedNlbInfo `json:"nlb,omitempty"`
+	ManualActions []string         `json:"manualActions,omitempty"` // L7 features to configure manually in the target CSP
+	Error         string           `json:"error,omitempty"`
+}
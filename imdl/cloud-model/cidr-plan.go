@@ -0,0 +1,66 @@
+package cloudmodel
+
+// CIDR planning modes
+const (
+	CidrPlanModeKeep = "keep" // Keep the source addressing (default)
+	CidrPlanModePool = "pool" // Allocate non-overlapping ranges from the supplied pool
+)
+
+// CidrPlanReq specifies how the CIDR blocks of the target vNets are planned.
+// During a phased migration, the target vNets must be routable to the on-premise networks
+// (e.g., over VPN), so their address spaces must not overlap.
+type CidrPlanReq struct {
+	Mode         string   `json:"mode,omitempty" example:"pool"`                  // keep | pool (default: keep)
+	Pool         []string `json:"pool,omitempty" example:"10.100.0.0/16"`         // CIDR pool to allocate vNet blocks from (pool mode)
+	OnpremRanges []string `json:"onpremRanges,omitempty" example:"172.16.0.0/16"` // Other on-premise ranges routed to the target cloud (in addition to the source networks)
+	NsId         string   `json:"nsId,omitempty" example:"mig01"`                 // Namespace in CB-Tumblebug whose existing vNets must not overlap
+}
+
+// CidrPlan describes the planned CIDR blocks of the target vNets and the validation results.
+type CidrPlan struct {
+	Mode           string          `json:"mode"`
+	VNets          []VNetCidrPlan  `json:"vNets"`
+	ReservedRanges []ReservedRange `json:"reservedRanges,omitempty"` // Ranges the planned vNets are validated against
+	Conflicts      []string        `json:"conflicts,omitempty"`      // Overlaps that prevent routing between the networks
+	Notes          []string        `json:"notes,omitempty"`
+	IPv6           *IPv6Plan       `json:"ipv6,omitempty"` // Set only if the source has IPv6 networks
+}
+
+// VNetCidrPlan is the planned CIDR block of a target vNet.
+type VNetCidrPlan struct {
+	VNetName       string           `json:"vNetName"`
+	CidrBlock      string           `json:"cidrBlock"`
+	PrivateNetwork string           `json:"privateNetwork" example:"10.0.0.0/8"` // Private network of the source networks
+	IPv6CidrBlock  string           `json:"ipv6CidrBlock,omitempty"`             // Empty if IPv4-only or assigned by the CSP
+	Subnets        []SubnetCidrPlan `json:"subnets"`
+}
+
+// SubnetCidrPlan maps a source network to a target subnet.
+type SubnetCidrPlan struct {
+	SubnetName      string `json:"subnetName"`
+	CidrBlock       string `json:"cidrBlock"`
+	SourceCidrBlock string `json:"sourceCidrBlock"` // Source network whose nodes are placed in this subnet
+
+	IPv6CidrBlock       string `json:"ipv6CidrBlock,omitempty"`       // Empty if IPv4-only or assigned by the CSP
+	SourceIPv6CidrBlock string `json:"sourceIPv6CidrBlock,omitempty"` // Source IPv6 network of the nodes in this subnet
+}
+
+// ReservedRange is an address range that the target vNets are validated against.
+type ReservedRange struct {
+	CidrBlock string `json:"cidrBlock"`
+	Source    string `json:"source" example:"onprem"` // "source-network" | "onprem" | "vnet:<nsId>/<vNetId>"
+}
+
+// IPv6 support levels of the target CSP
+const (
+	IPv6SupportCustom   = "custom"   // Dual-stack vNets with user-defined IPv6 prefixes (the source ULA prefixes can be kept)
+	IPv6SupportAssigned = "assigned" // Dual-stack vNets with CSP-assigned IPv6 prefixes only
+	IPv6SupportNone     = "none"     // IPv4-only vNets
+)
+
+// IPv6Plan describes the dual-stack (IPv4/IPv6) plan of the target vNets.
+type IPv6Plan struct {
+	Support          string   `json:"support" example:"assigned"` // custom | assigned | none
+	SourceCidrBlocks []string `json:"sourceCidrBlocks"`           // IPv6 networks of the source
+	Warnings         []string `json:"warnings,omitempty"`         // IPv6 reachability that is lost or must be restored manually
+}
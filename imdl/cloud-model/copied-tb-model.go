@@ -538,6 +538,11 @@ type VNetReq struct { // Tumblebug
 	Description    string      `json:"description" example:"vnet00 managed by CB-Tumblebug"`
 	// todo: restore the tag list later
 	// TagList        []KeyValue    `json:"tagList,omitempty"`
+
+	// Dual-stack (IPv4/IPv6) settings recommended by Beetle (not part of CB-Tumblebug's VNetReq)
+	// IPv6CidrBlock is empty when the IPv6 prefix is assigned by the CSP.
+	EnableIPv6    bool   `json:"enableIPv6,omitempty"`
+	IPv6CidrBlock string `json:"ipv6CidrBlock,omitempty" example:"fd12:3456:789a::/48"`
 }
 
 // SubnetReq is a struct that represents TB subnet object.
@@ -548,6 +553,11 @@ type SubnetReq struct { // Tumblebug
 	Description string `json:"description,omitempty" example:"subnet00 managed by CB-Tumblebug"`
 	// todo: restore the tag list later
 	// TagList     []KeyValue `json:"tagList,omitempty"`
+
+	// Dual-stack (IPv4/IPv6) settings recommended by Beetle (not part of CB-Tumblebug's SubnetReq)
+	// IPv6_CIDR is empty when the IPv6 prefix is assigned by the CSP.
+	EnableIPv6 bool   `json:"enableIPv6,omitempty"`
+	IPv6_CIDR  string `json:"ipv6_CIDR,omitempty" example:"fd12:3456:789a:1::/64"`
 }
 
 // SshKeyReq is a struct to handle 'Create SSH key' request toward CB-Tumblebug.
@@ -0,0 +1,48 @@
+package cloudmodel
+
+// ============================================================================
+// Firewall policy and network ACL types
+// Security groups are allow-only, so the deny rules of the source firewalls are
+// compiled into the allow rules or recommended as network ACL rules.
+// These are Beetle-side models (CB-Tumblebug does not provision network ACLs).
+// ============================================================================
+
+// Network ACL support levels of the target CSP
+const (
+	AclSupportNative = "native" // The CSP offers deny rules (e.g., network ACLs, deny firewall rules)
+	AclSupportNone   = "none"   // Allow-only security groups
+)
+
+// FirewallPolicyReport describes how the ordered source firewall rules are compiled into security group rules.
+// Rules are described as "[<machineId>] <action> <direction> <protocol> <ports> <cidr>".
+type FirewallPolicyReport struct {
+	AclSupport    string   `json:"aclSupport"`              // native | none
+	CspService    string   `json:"cspService,omitempty"`    // Service enforcing deny rules (e.g., "Network ACL")
+	NarrowedRules []string `json:"narrowedRules,omitempty"` // Deny rules subtracted from the allow rules of the security groups
+	AclOnlyRules  []string `json:"aclOnlyRules,omitempty"`  // Deny rules enforced with a network ACL (see TargetNetworkAclList)
+	// UnenforcedRules are deny rules enforceable neither with security groups nor with network ACLs
+	// (e.g., outbound deny rules on an allow-only CSP, or deny rules for all ports whose stateless ACL rules would drop the return traffic)
+	UnenforcedRules []string `json:"unenforcedRules,omitempty"`
+	Notes           []string `json:"notes,omitempty"`
+}
+
+// NetworkAclReq is a recommended network ACL for subnets.
+// Network ACLs are stateless and their rules are evaluated in rule number order.
+type NetworkAclReq struct {
+	Name        string              `json:"name"`
+	VNetId      string              `json:"vNetId"`
+	SubnetIds   []string            `json:"subnetIds"`
+	Description string              `json:"description,omitempty"`
+	Rules       []NetworkAclRuleReq `json:"rules"`
+}
+
+// NetworkAclRuleReq is a rule of a network ACL.
+type NetworkAclRuleReq struct {
+	RuleNumber      int    `json:"ruleNumber"`                // Lower number is evaluated first
+	Direction       string `json:"direction"`                 // inbound | outbound
+	Protocol        string `json:"protocol"`                  // TCP | UDP | ICMP | ALL
+	CIDR            string `json:"cidr"`                      // Remote CIDR block
+	Ports           string `json:"ports,omitempty"`           // Single port or range (e.g., "22", "8000-8080"); empty for ICMP and ALL
+	Action          string `json:"action"`                    // allow | deny
+	SourceMachineId string `json:"sourceMachineId,omitempty"` // Source node whose firewall rule the ACL rule is derived from
+}
@@ -8,17 +8,31 @@ type RecommendedInfraModel struct {
 // RecommendedInfra represents the recommended virtual machine infrastructure information.
 // When NLB-aware recommendation is performed (POST /recommendation/infraWithNlb),
 // TargetNlbList is populated; otherwise it is omitted.
+// TargetAlbList is additionally populated for HTTP-mode source frontends (L7).
+// Trace is populated only when the recommendation is requested in explain mode.
+// Placement describes the availability zone placement of the NodeGroups, if applied.
+// AdditionalVNetList holds the other vNets when the source networks span multiple private networks,
+// and CidrPlan describes how the CIDR blocks of the vNets are planned.
+// FirewallPolicy describes how the source deny rules are enforced, and TargetNetworkAclList holds
+// the network ACLs for the deny rules that security groups cannot express.
 type RecommendedInfra struct {
-	Status                  string             `json:"status"`
-	Description             string             `json:"description"`
-	TargetCloud             CloudProperty      `json:"targetCloud"`
-	TargetInfra             InfraReq           `json:"targetInfra"`
-	TargetVNet              VNetReq            `json:"targetVNet"`
-	TargetSshKey            SshKeyReq          `json:"targetSshKey"`
-	TargetSpecList          []SpecInfo         `json:"targetSpecList"`
-	TargetOsImageList       []ImageInfo        `json:"targetOsImageList"`
-	TargetSecurityGroupList []SecurityGroupReq `json:"targetSecurityGroupList"`
-	TargetNlbList           []NlbReq           `json:"targetNlbList,omitempty"`
+	Status                  string                `json:"status"`
+	Description             string                `json:"description"`
+	TargetCloud             CloudProperty         `json:"targetCloud"`
+	TargetInfra             InfraReq              `json:"targetInfra"`
+	TargetVNet              VNetReq               `json:"targetVNet"`
+	AdditionalVNetList      []VNetReq             `json:"additionalVNetList,omitempty"`
+	TargetSshKey            SshKeyReq             `json:"targetSshKey"`
+	TargetSpecList          []SpecInfo            `json:"targetSpecList"`
+	TargetOsImageList       []ImageInfo           `json:"targetOsImageList"`
+	TargetSecurityGroupList []SecurityGroupReq    `json:"targetSecurityGroupList"`
+	TargetNlbList           []NlbReq              `json:"targetNlbList,omitempty"`
+	TargetAlbList           []AlbReq              `json:"targetAlbList,omitempty"`
+	TargetNetworkAclList    []NetworkAclReq       `json:"targetNetworkAclList,omitempty"`
+	FirewallPolicy          *FirewallPolicyReport `json:"firewallPolicy,omitempty"`
+	Placement               *PlacementPlan        `json:"placement,omitempty"`
+	CidrPlan                *CidrPlan             `json:"cidrPlan,omitempty"`
+	Trace                   *RecommendationTrace  `json:"trace,omitempty"`
 }
 
 // RecommendedNlb is the request body for POST /migration/middleware/ns/{nsId}/infra/{infraId}/nlb.
@@ -68,6 +82,7 @@ type RecommendedVNetList struct {
 	Description    string            `json:"description"`
 	Count          int               `json:"count"`
 	TargetVNetList []RecommendedVNet `json:"targetVNetList"`
+	CidrPlan       *CidrPlan         `json:"cidrPlan,omitempty"`
 }
 
 // RecommendedSecurityGroup represents the recommended security group information.
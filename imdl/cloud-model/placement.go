@@ -0,0 +1,22 @@
+package cloudmodel
+
+// PlacementPlan describes how the NodeGroups of a recommended infrastructure are placed
+// across the availability zones of the target region, and why.
+// The "spread" policy spreads the NodeGroups sharing a role across the zones; the load-balanced
+// (NLB backend) NodeGroups are kept whole in the primary zone, as noted in the plan.
+type PlacementPlan struct {
+	Policy     string               `json:"policy" example:"spread"` // "spread" | "none"
+	Zones      []string             `json:"zones"`                   // Zones of the region considered for placement
+	NodeGroups []NodeGroupPlacement `json:"nodeGroups"`
+	Notes      []string             `json:"notes,omitempty"`
+}
+
+// NodeGroupPlacement is the placement decision for a single NodeGroup.
+type NodeGroupPlacement struct {
+	NodeGroup     string `json:"nodeGroup"`
+	Tier          string `json:"tier" example:"nlb:web-backend"` // "nlb:<backend>" | "role:<role>" | "node:<machineId>"
+	Zone          string `json:"zone"`
+	SubnetId      string `json:"subnetId"`
+	NodeGroupSize int    `json:"nodeGroupSize"`
+	Rationale     string `json:"rationale"`
+}
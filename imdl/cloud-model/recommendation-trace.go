@@ -0,0 +1,74 @@
+package cloudmodel
+
+// RecommendationTrace is a structured decision trace explaining how a recommended infrastructure candidate was derived.
+// It is populated only when the recommendation is requested in explain mode; otherwise it is omitted.
+type RecommendationTrace struct {
+	CandidateIndex int                 `json:"candidateIndex"` // 1-based index of the candidate
+	Nodes          []NodeDecisionTrace `json:"nodes"`
+}
+
+// NodeDecisionTrace explains the spec/image decision made for a single source node.
+type NodeDecisionTrace struct {
+	SourceMachineId string `json:"sourceMachineId"`
+
+	// Search inputs and counts
+	SpecFilter           SpecSearchFilter `json:"specFilter"`           // Deployment-plan filters applied to the spec search
+	ImageFilter          ImageSearchQuery `json:"imageFilter"`          // Query applied to the OS image search
+	SpecsConsidered      int              `json:"specsConsidered"`      // Specs returned by the spec search
+	ImagesConsidered     int              `json:"imagesConsidered"`     // Images returned by the image search
+	SpecsAfterPreFilter  int              `json:"specsAfterPreFilter"`  // Specs left after CSP-specific pre-filtering
+	ImagesAfterPreFilter int              `json:"imagesAfterPreFilter"` // Images left after CSP-specific pre-filtering
+	CompatiblePairs      int              `json:"compatiblePairs"`      // Spec-image pairs that passed the compatibility check
+
+	// Compatibility rejections
+	RejectionSummary map[string]int    `json:"rejectionSummary,omitempty"` // Number of rejected pairs per reason
+	Rejections       []PairRejection   `json:"rejections,omitempty"`       // Rejected pairs (truncated)
+	Ranking          []RankedPairTrace `json:"ranking,omitempty"`          // Ranked compatible pairs (truncated)
+	Selected         *RankedPairTrace  `json:"selected,omitempty"`         // Pair selected for this candidate
+	Notes            []string          `json:"notes,omitempty"`            // Free-form remarks (e.g., fallbacks, skipped nodes)
+}
+
+// SpecSearchFilter describes the deployment-plan filters used to search VM specs.
+type SpecSearchFilter struct {
+	VCPUMin      uint32 `json:"vCpuMin"`
+	VCPUMax      uint32 `json:"vCpuMax"`
+	MemoryGiBMin uint32 `json:"memoryGiBMin"`
+	MemoryGiBMax uint32 `json:"memoryGiBMax"`
+	ProviderName string `json:"providerName"`
+	RegionName   string `json:"regionName"`
+	Architecture string `json:"architecture"`
+	RangeWeight  int    `json:"rangeWeight"` // Range weight at which specs were found (widened on retry)
+	Limit        int    `json:"limit"`
+	Priority     string `json:"priority"` // e.g., "cost"
+}
+
+// ImageSearchQuery describes the query used to search VM OS images.
+type ImageSearchQuery struct {
+	OSType   string   `json:"osType"`
+	Keywords string   `json:"keywords"`           // Keywords used for similarity scoring
+	Fallback bool     `json:"fallback,omitempty"` // Whether the query fell back to a broader OS type
+	Dropped  []string `json:"dropped,omitempty"`  // Images dropped before scoring (e.g., UEFI images on AWS)
+}
+
+// PairRejection records a spec-image pair rejected by the compatibility check.
+type PairRejection struct {
+	SpecId       string `json:"specId"`
+	CspSpecName  string `json:"cspSpecName"`
+	ImageId      string `json:"imageId"`
+	CspImageName string `json:"cspImageName"`
+	Reason       string `json:"reason"` // e.g., "virtualization-type", "ena", "nvme", "boot-mode", "generation"
+	Detail       string `json:"detail,omitempty"`
+}
+
+// RankedPairTrace records a compatible spec-image pair and its similarity scores.
+type RankedPairTrace struct {
+	Rank            int     `json:"rank"` // 1-based rank
+	SpecId          string  `json:"specId"`
+	CspSpecName     string  `json:"cspSpecName"`
+	ImageId         string  `json:"imageId"`
+	CspImageName    string  `json:"cspImageName"`
+	CpuMatchRate    float64 `json:"cpuMatchRate"`
+	MemoryMatchRate float64 `json:"memoryMatchRate"`
+	ImageMatchRate  float64 `json:"imageMatchRate"`
+	AvgMatchRate    float64 `json:"avgMatchRate"`
+}
@@ -10,10 +10,11 @@ type SourceNlbModel struct {
 // SourceNlb is the normalized, software-independent NLB configuration
 // extracted from the source environment by cm-honeybee.
 type SourceNlb struct {
-	Software    string                 `json:"software"`              // "haproxy"
-	Listener    NlbListenerProperty    `json:"listener"`
-	Backend     NlbBackendProperty     `json:"backend"`
-	HealthCheck NlbHealthCheckProperty `json:"healthCheck,omitempty"`
+	Software    string                   `json:"software"` // "haproxy"
+	Listener    NlbListenerProperty      `json:"listener"`
+	Backend     NlbBackendProperty       `json:"backend"`
+	HealthCheck NlbHealthCheckProperty   `json:"healthCheck,omitempty"`
+	Rules       []NlbRoutingRuleProperty `json:"rules,omitempty"`
 }
 
 type OnpremiseInfraModel struct {
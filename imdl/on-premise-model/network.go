@@ -5,7 +5,7 @@ package onpremisemodel
 // * [Important] Information in the IPv4Networks list should be as non-duplicated as possible.
 type NetworkProperty struct { // note: reference command `ip route`, `netstat -rn`, and `lshw -c network`
 	IPv4Networks NetworkDetail `json:"ipv4Networks,omitempty"`
-	IPv6Networks NetworkDetail `json:"ipv6Networks,omitempty"` // TBD
+	IPv6Networks NetworkDetail `json:"ipv6Networks,omitempty"` // e.g., fd12:3456:789a::/48 (used to plan dual-stack vNets)
 	// TODO: Add or update fields
 }
 
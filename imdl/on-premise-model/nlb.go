@@ -8,18 +8,20 @@ package onpremisemodel
 // NlbProperty represents a single NLB instance on the on-premise environment
 // (one entry per HAProxy frontend-backend pair).
 type NlbProperty struct {
-	HostMachineId string                 `json:"hostMachineId,omitempty"` // MachineId of the node running HAProxy
-	Software      string                 `json:"software"`                // "haproxy"
-	Listener      NlbListenerProperty    `json:"listener"`
-	Backend       NlbBackendProperty     `json:"backend"`
-	HealthCheck   NlbHealthCheckProperty `json:"healthCheck,omitempty"`
+	HostMachineId string                   `json:"hostMachineId,omitempty"` // MachineId of the node running HAProxy
+	Software      string                   `json:"software"`                // "haproxy"
+	Listener      NlbListenerProperty      `json:"listener"`
+	Backend       NlbBackendProperty       `json:"backend"`
+	HealthCheck   NlbHealthCheckProperty   `json:"healthCheck,omitempty"`
+	Rules         []NlbRoutingRuleProperty `json:"rules,omitempty"` // L7 routing rules selecting this backend (empty = default backend)
 }
 
 // NlbListenerProperty captures the frontend listener of the source NLB.
 type NlbListenerProperty struct {
-	BindAddress string `json:"bindAddress"` // "*" = all interfaces (→ PUBLIC), specific IP = INTERNAL
-	Port        int    `json:"port"`        // Listener port (1–65535)
-	Protocol    string `json:"protocol"`    // "tcp" | "udp"
+	BindAddress string `json:"bindAddress"`   // "*" = all interfaces (→ PUBLIC), specific IP = INTERNAL
+	Port        int    `json:"port"`          // Listener port (1–65535)
+	Protocol    string `json:"protocol"`      // "tcp" | "udp"
+	Tls         bool   `json:"tls,omitempty"` // TLS terminated at the listener (e.g., HAProxy "bind ... ssl")
 }
 
 // NlbBackendProperty captures the backend configuration of the source NLB.
@@ -46,4 +48,13 @@ type NlbHealthCheckProperty struct {
 	Interval  int    `json:"interval,omitempty"`  // seconds; default 10
 	Timeout   int    `json:"timeout,omitempty"`   // seconds; default 10
 	Threshold int    `json:"threshold,omitempty"` // default 3
+	Path      string `json:"path,omitempty"`      // HTTP health check path (e.g., "/health"); http only
+}
+
+// NlbRoutingRuleProperty captures an L7 routing rule (HTTP mode only) that selects the backend,
+// e.g., HAProxy "use_backend api if { path_beg /api }" or an nginx "location /api" block.
+// Conditions of different kinds are ANDed; values of the same kind are ORed.
+type NlbRoutingRuleProperty struct {
+	Hosts        []string `json:"hosts,omitempty"`        // Host header values (e.g., "api.example.com")
+	PathPrefixes []string `json:"pathPrefixes,omitempty"` // URL path prefixes (e.g., "/api")
 }
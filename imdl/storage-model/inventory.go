@@ -0,0 +1,41 @@
+package storagemodel
+
+// Source-side inventory types (built by scanning the objects of a source bucket)
+
+// BucketInventoryProperty is the object inventory of a source bucket (used for recommendation only).
+// It is built by the object storage scanner and explains the derived BucketUsageProperty.
+type BucketInventoryProperty struct {
+	ScannedAt                string             `json:"scannedAt"`                      // Scan time (RFC 3339)
+	OldestModified           string             `json:"oldestModified,omitempty"`       // Oldest last-modified time of the objects (RFC 3339)
+	NewestModified           string             `json:"newestModified,omitempty"`       // Newest last-modified time of the objects (RFC 3339)
+	SizeHistogram            []SizeHistogramBin `json:"sizeHistogram"`                  // Object counts by object size
+	LastModifiedDistribution []LastModifiedBin  `json:"lastModifiedDistribution"`       // Object counts by the age of the last modification
+	PrefixTree               []PrefixNode       `json:"prefixTree,omitempty"`           // Top-level prefixes ("/"-delimited) and their sub-prefixes
+	AccessFrequencyBasis     string             `json:"accessFrequencyBasis,omitempty"` // How AccessFrequency is inferred
+}
+
+// SizeHistogramBin counts the objects whose size is in [MinBytes, MaxBytes).
+type SizeHistogramBin struct {
+	Label          string `json:"label" example:"1MiB-16MiB"`
+	MinBytes       int64  `json:"minBytes"`
+	MaxBytes       int64  `json:"maxBytes,omitempty"` // 0 for the last (unbounded) bin
+	ObjectCount    int64  `json:"objectCount"`
+	TotalSizeBytes int64  `json:"totalSizeBytes"`
+}
+
+// LastModifiedBin counts the objects last modified within [MinAgeDays, MaxAgeDays) days before the scan.
+type LastModifiedBin struct {
+	Label          string `json:"label" example:"30d-90d"`
+	MinAgeDays     int    `json:"minAgeDays"`
+	MaxAgeDays     int    `json:"maxAgeDays,omitempty"` // 0 for the last (unbounded) bin
+	ObjectCount    int64  `json:"objectCount"`
+	TotalSizeBytes int64  `json:"totalSizeBytes"`
+}
+
+// PrefixNode is a "/"-delimited key prefix and the objects under it.
+type PrefixNode struct {
+	Prefix         string       `json:"prefix" example:"logs/2024/"`
+	ObjectCount    int64        `json:"objectCount"`
+	TotalSizeBytes int64        `json:"totalSizeBytes"`
+	Children       []PrefixNode `json:"children,omitempty"`
+}
@@ -34,6 +34,8 @@ type SourceObjectStorage struct {
 	BucketFeatureProperty // Feature configuration observed in the source
 	BucketUsageProperty   // Operational metrics (recommendation only)
 	BucketMetaProperty    // Metadata observed in the source
+
+	Inventory *BucketInventoryProperty `json:"inventory,omitempty"` // Object inventory built by the scanner (recommendation only)
 }
 
 // CloudProperty identifies the target cloud provider and region.
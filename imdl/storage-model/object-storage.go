@@ -16,6 +16,12 @@ type BucketSpecProperty struct {
 	VersioningEnabled bool       `json:"versioningEnabled"` // Whether to enable versioning
 	CORSEnabled       bool       `json:"corsEnabled"`       // Whether to configure CORS
 	CORSRule          []CORSRule `json:"corsRule,omitempty"` // CORS rules to apply
+
+	EncryptionEnabled   bool              `json:"encryptionEnabled"`                              // Whether to enable server-side encryption with CSP-managed keys
+	EncryptionAlgorithm string            `json:"encryptionAlgorithm,omitempty" example:"AES256"` // Server-side encryption algorithm (empty if disabled)
+	PublicAccessBlocked bool              `json:"publicAccessBlocked"`                            // Whether to block public access to the bucket and its objects
+	StorageClass        string            `json:"storageClass,omitempty" example:"STANDARD_IA"`   // CSP storage class derived from the source access frequency
+	Tags                map[string]string `json:"tags,omitempty"`                                 // Tags propagated from the source (normalized for the target CSP)
 }
 
 // Source-side property types
@@ -15,6 +15,7 @@ package controller
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -83,6 +84,113 @@ func RestGetRequest(c echo.Context) error {
 	return c.JSON(http.StatusNotFound, model.SimpleErrorResponse("Request ID not found"))
 }
 
+// Intervals of the request event stream
+const (
+	requestEventPollInterval      = 1 * time.Second
+	requestEventHeartbeatInterval = 15 * time.Second
+)
+
+// RestGetRequestEvents godoc
+// @ID GetRequestEvents
+// @Summary Stream request progress events
+// @Description Streams the progress of a specific API request as Server-Sent Events (text/event-stream).
+// @Description
+// @Description [Events]
+// @Description - progress: a step-level progress (common.ProgressInfo); the event ID is its index in `progress` of the request
+// @Description - status: the status of the request when it changes (Handling, Success, Error)
+// @Description - done: the final request details (common.RequestDetails); the stream ends after this event
+// @Description
+// @Description [Note]
+// @Description - The progress published before the connection is sent first (after the Last-Event-ID, if given on reconnection).
+// @Description - A comment line is sent periodically to keep the connection alive.
+// @Description - Long-running APIs publish progress, e.g., POST /migration/ns/{nsId}/infra?async=true.
+// @Tags [Admin] API Request Management
+// @Produce  text/event-stream
+// @Param reqId path string true "Request ID (from X-Request-Id header of a previous Beetle API call)"
+// @Param Last-Event-ID header string false "The ID of the last progress event received (to resume the stream)"
+// @Success 200 {string} string "Server-Sent Events stream"
+// @Failure 404 {object} model.ApiResponse[any]
+// @Router /request/{reqId}/events [get]
+func RestGetRequestEvents(c echo.Context) error {
+	reqId := c.Param("reqId")
+
+	details, ok := common.GetRequest(reqId)
+	if !ok {
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse("Request ID not found"))
+	}
+
+	// Resume after the last progress event received, if any
+	nextProgress := 0
+	if lastEventId, err := strconv.Atoi(c.Request().Header.Get("Last-Event-ID")); err == nil && lastEventId >= 0 {
+		nextProgress = lastEventId + 1
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // Disable buffering of reverse proxies (e.g., nginx)
+	res.WriteHeader(http.StatusOK)
+
+	writeEvent := func(event, id string, data any) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if id != "" {
+			if _, err := fmt.Fprintf(res, "id: %s\n", id); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return err
+		}
+		res.Flush()
+		return nil
+	}
+
+	pollTicker := time.NewTicker(requestEventPollInterval)
+	defer pollTicker.Stop()
+	heartbeatTicker := time.NewTicker(requestEventHeartbeatInterval)
+	defer heartbeatTicker.Stop()
+
+	lastStatus := ""
+	for {
+		// Send the new progress and the status change
+		for ; nextProgress < len(details.Progress); nextProgress++ {
+			if err := writeEvent("progress", strconv.Itoa(nextProgress), details.Progress[nextProgress]); err != nil {
+				return nil
+			}
+		}
+		if details.Status != lastStatus {
+			if err := writeEvent("status", "", map[string]string{"status": details.Status}); err != nil {
+				return nil
+			}
+			lastStatus = details.Status
+		}
+		if details.Status != common.RequestStatusHandling {
+			_ = writeEvent("done", "", details)
+			return nil
+		}
+
+		select {
+		case <-c.Request().Context().Done():
+			log.Debug().Str("reqId", reqId).Msg("Request event stream closed by the client")
+			return nil
+		case <-heartbeatTicker.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-pollTicker.C:
+			if details, ok = common.GetRequest(reqId); !ok {
+				_ = writeEvent("error", "", map[string]string{"error": "Request ID not found (deleted)"})
+				return nil
+			}
+		}
+	}
+}
+
 // RestGetAllRequests godoc
 // @ID GetAllRequests
 // @Summary Get all requests
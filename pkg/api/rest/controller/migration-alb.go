@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller has handlers and their request/response bodies for migration APIs
+package controller
+
+import (
+	"net/http"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// ALB Migration API
+// ============================================================================
+
+// MigrateAlbs godoc
+// @ID MigrateAlbs
+// @Summary (Preview) Migrate L7 ALBs to a cloud infra
+// @Description Migrate L7 application load balancers (HTTP-mode source frontends) to the target cloud infra based on recommendation results.
+// @Description
+// @Description [Prerequisites]
+// @Description - The target Namespace (nsId) must exist.
+// @Description - The target Infra (infraId) must exist and have at least one NodeGroup in Running state.
+// @Description - Each `targetAlbList[].targetGroups[].nodeGroupId` must reference an existing NodeGroup in the Infra.
+// @Description
+// @Description [Note] Input should be the `targetAlbList` field from the POST /recommendation/infraWithNlb response.
+// @Description
+// @Description [Note] CB-Tumblebug provisions L4 NLBs only. Each ALB listener is provisioned as an L4 NLB forwarding to its default target group
+// @Description (skipped if an NLB with the same listener port already exists, e.g., created via POST .../nlb).
+// @Description Host/path routing rules, TLS termination (certificate placeholders), HTTP health check paths, and non-default target groups
+// @Description are returned as `albList[].manualActions`.
+// @Description
+// @Description [Note] ALBs with `l7Support: l4-only` target a CSP without a managed L7 load balancer;
+// @Description their L7 features require a self-hosted proxy (e.g., HAProxy or nginx on a NodeGroup).
+// @Tags [Migration] Managed Network Load Balancer (NLB) - preview
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param infraId path string true "Infra ID (target infra with NodeGroups already created)"
+// @Param request body cloudmodel.RecommendedAlb true "ALB migration request (use targetAlbList[] from /recommendation/infraWithNlb)"
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided)"
+// @Success 201 {object} model.ApiResponse[cloudmodel.MigratedAlbResult] "ALBs provisioned (as L4 NLBs with manual L7 actions)"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 500 {object} model.ApiResponse[any] "Internal server error during ALB migration"
+// @Router /migration/middleware/ns/{nsId}/infra/{infraId}/alb [post]
+func MigrateAlbs(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	infraId := c.Param("infraId")
+	if infraId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("infraId required"))
+	}
+
+	var req cloudmodel.RecommendedAlb
+	if err := c.Bind(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to bind MigrateAlbs request")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	if len(req.TargetAlbList) == 0 {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("targetAlbList is required and must not be empty"))
+	}
+
+	log.Info().
+		Str("nsId", nsId).
+		Str("infraId", infraId).
+		Int("count", len(req.TargetAlbList)).
+		Msg("Starting ALB migration")
+
+	result, err := migration.CreateAlbs(nsId, infraId, req)
+	if err != nil {
+		log.Error().Err(err).Str("nsId", nsId).Str("infraId", infraId).Msg("ALB migration failed")
+		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponseWithMessage(result, result.Description))
+}
@@ -21,6 +21,9 @@ import (
 
 	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
 	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/cloud-barista/cm-beetle/pkg/core/notification"
+	"github.com/cloud-barista/cm-beetle/pkg/core/policy"
+	"github.com/cloud-barista/cm-beetle/pkg/core/project"
 	"github.com/cloud-barista/cm-beetle/transx"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
@@ -95,10 +98,22 @@ func GetDataMigrationEncryptionKey(c echo.Context) error {
 // @Description [Endpoint Requirements]
 // @Description * Both source and destination must be remote endpoints (SSH or object storage)
 // @Description * Local filesystem access is not allowed for security reasons
+// @Description * The migration policies are evaluated first; the request is rejected with 403 on a violation (see POST /policy/evaluate)
 // @Description
 // @Description [Transfer Options]
 // @Description * Strategy: auto (default), direct, relay
 // @Description * SSH: Supports PrivateKey content or PrivateKeyPath
+// @Description * Mode: files (default), bucket-content (object storage to object storage)
+// @Description
+// @Description [Bucket-Content Mode]
+// @Description * Copies all object versions (oldest first) with content headers, user metadata, tags and public-read grants
+// @Description * All versions are copied only if both buckets use the minio access type and the target bucket has versioning enabled; otherwise the current versions only
+// @Description * `bucketContent.publicRead`: report (default) lists public-read objects, acl grants public-read on the target objects
+// @Description * The result (preserved/dropped attribute counts, public-read objects, failed objects) is returned in the request status
+// @Description
+// @Description [Progress]
+// @Description * The milestones (started with the planned steps, finished/failed) are published in `progress` of GET /request/{reqId}
+// @Description * They are also delivered to the notification webhooks, if any (see GET /notification/outbox)
 // @Description
 // @Description [Encryption Support]
 // @Description * To encrypt sensitive fields, first call GET /migration/data/encryptionKey
@@ -112,9 +127,11 @@ func GetDataMigrationEncryptionKey(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used as reqId for tracking migration status."
+// @Param projectId query string false "Migration project ID to record the data migration job in"
 // @Param reqBody body transx.DataMigrationModel true "Data migration request (supports plaintext or encrypted with encryptionKeyId)"
 // @Success 202 {object} model.ApiResponse[model.AsyncJobResponse] "Migration started - use GET /request/{reqId} to check status"
 // @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters or decryption failed"
+// @Failure 403 {object} model.ApiResponse[policy.EvaluationResult] "Blocked by the migration policies"
 // @Router /migration/data [post]
 func MigrateData(c echo.Context) error {
 
@@ -165,6 +182,12 @@ func MigrateData(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Local filesystem access not allowed for destination; use SSH or object storage"))
 	}
 
+	// Evaluate the migration policies
+	if result := policy.EvaluateDataMigration(*req); !result.Allowed {
+		log.Warn().Err(result.Err()).Msg("rejected: data migration violates the policies")
+		return rejectByPolicies(c, result)
+	}
+
 	log.Info().
 		Str("sourceType", req.Source.StorageType).
 		Str("sourcePath", req.Source.Path).
@@ -176,6 +199,19 @@ func MigrateData(c echo.Context) error {
 	// Get the request ID from header for async tracking
 	reqID := c.Request().Header.Get(echo.HeaderXRequestID)
 
+	// Record the job in the migration project, if any
+	if projectId := c.QueryParam("projectId"); projectId != "" {
+		err := project.RecordJob(projectId, project.ProjectJob{
+			Type:        project.JobTypeData,
+			ReqId:       reqID,
+			StatusURL:   fmt.Sprintf("/beetle/request/%s", reqID),
+			Description: fmt.Sprintf("%s:%s to %s:%s", req.Source.StorageType, req.Source.Path, req.Destination.StorageType, req.Destination.Path),
+		})
+		if err != nil {
+			log.Warn().Err(err).Msgf("failed to record the data migration in the project (projectId: %s)", projectId)
+		}
+	}
+
 	// Execute migration asynchronously
 	go executeMigrationAsync(reqID, *req)
 
@@ -191,41 +227,116 @@ func MigrateData(c echo.Context) error {
 	))
 }
 
+// Progress milestones of the data migration
+const (
+	ProgressDataMigrationStarted  = "Data migration started"
+	ProgressDataMigrationFinished = "Data migration finished"
+	ProgressDataMigrationFailed   = "Data migration failed"
+)
+
+// DataMigrationProgress is the progress info of a data migration milestone
+type DataMigrationProgress struct {
+	SourceType     string   `json:"sourceType,omitempty" example:"filesystem"`
+	SourcePath     string   `json:"sourcePath,omitempty" example:"/data"`
+	DestType       string   `json:"destType,omitempty" example:"objectstorage"`
+	DestPath       string   `json:"destPath,omitempty" example:"my-bucket/data"`
+	Mode           string   `json:"mode,omitempty" example:"files"`
+	Steps          []string `json:"steps,omitempty"` // Planned transfer steps
+	ElapsedTime    string   `json:"elapsedTime,omitempty" example:"1m30s"`
+	ObjectCount    int      `json:"objectCount,omitempty" example:"100"`
+	TotalSizeBytes int64    `json:"totalSizeBytes,omitempty" example:"1048576"`
+	FailedObjects  int      `json:"failedObjects,omitempty" example:"0"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// reportDataMigrationProgress publishes a milestone of the data migration in the request progress
+// and to the webhooks (if any).
+func reportDataMigrationProgress(reqID, title string, info DataMigrationProgress) {
+	common.AppendRequestProgress(reqID, common.ProgressInfo{
+		Title: title,
+		Info:  info,
+		Time:  time.Now(),
+	})
+	notification.PublishProgress(notification.EventTypeDataMigrationProgress, reqID, title, info)
+}
+
 // executeMigrationAsync performs the data migration in background and updates request status.
+// The milestones (started, finished/failed) are published as the progress of the request.
 func executeMigrationAsync(reqID string, req transx.DataMigrationModel) {
 	startTime := time.Now()
 
+	started := DataMigrationProgress{
+		SourceType: req.Source.StorageType,
+		SourcePath: req.Source.Path,
+		DestType:   req.Destination.StorageType,
+		DestPath:   req.Destination.Path,
+		Mode:       req.Mode,
+	}
+	if req.Mode != transx.ModeBucketContent {
+		if pipeline, err := transx.Plan(req); err == nil {
+			for _, step := range pipeline.Steps {
+				started.Steps = append(started.Steps, step.Name)
+			}
+		}
+	}
+	reportDataMigrationProgress(reqID, ProgressDataMigrationStarted, started)
+
 	// Execute migration
-	err := transx.Transfer(req)
+	var bucketContentResult *transx.BucketContentResult
+	var err error
+	if req.Mode == transx.ModeBucketContent {
+		bucketContentResult, err = transx.MigrateBucketContent(req)
+	} else {
+		err = transx.Transfer(req)
+	}
 
 	// Calculate elapsed time
 	elapsedTime := time.Since(startTime)
 
-	// Get current request details
-	details, ok := common.GetRequest(reqID)
-	if !ok {
-		log.Error().Str("reqId", reqID).Msg("Failed to get request details for status update")
-		return
+	finished := DataMigrationProgress{ElapsedTime: elapsedTime.Round(time.Millisecond).String()}
+	if bucketContentResult != nil {
+		finished.ObjectCount = bucketContentResult.ObjectCount
+		finished.TotalSizeBytes = bucketContentResult.TotalSizeBytes
+		finished.FailedObjects = len(bucketContentResult.FailedObjects)
+	}
+	if err != nil {
+		finished.Error = err.Error()
+		reportDataMigrationProgress(reqID, ProgressDataMigrationFailed, finished)
+	} else {
+		reportDataMigrationProgress(reqID, ProgressDataMigrationFinished, finished)
 	}
 
-	// Update status based on result
-	details.EndTime = time.Now()
 	if err != nil {
 		log.Error().Err(err).Str("reqId", reqID).Dur("elapsedTime", elapsedTime).Msg("Data migration failed")
-		details.Status = common.RequestStatusError
-		details.ErrorResponse = fmt.Sprintf("Data migration failed: %v (%s)", err, elapsedTime.Round(time.Millisecond))
 	} else {
 		log.Info().Str("reqId", reqID).Dur("elapsedTime", elapsedTime).Msg("Data migration completed successfully")
-		details.Status = common.RequestStatusSuccess
-		details.ResponseData = map[string]any{
-			"message":     fmt.Sprintf("Data migrated successfully (%s)", elapsedTime.Round(time.Millisecond)),
-			"elapsedTime": elapsedTime.Round(time.Millisecond).String(),
-		}
 	}
 
-	// Save updated status
-	if err := common.SetRequest(reqID, details); err != nil {
-		log.Error().Err(err).Str("reqId", reqID).Msg("Failed to update request status")
+	// Update status based on result
+	updateErr := common.UpdateRequest(reqID, func(details *common.RequestDetails) {
+		details.EndTime = time.Now()
+		if err != nil {
+			details.Status = common.RequestStatusError
+			details.ErrorResponse = fmt.Sprintf("Data migration failed: %v (%s)", err, elapsedTime.Round(time.Millisecond))
+			if bucketContentResult != nil {
+				details.ResponseData = map[string]any{
+					"bucketContent": bucketContentResult,
+				}
+			}
+		} else {
+			details.Status = common.RequestStatusSuccess
+			responseData := map[string]any{
+				"message":     fmt.Sprintf("Data migrated successfully (%s)", elapsedTime.Round(time.Millisecond)),
+				"elapsedTime": elapsedTime.Round(time.Millisecond).String(),
+			}
+			if bucketContentResult != nil {
+				responseData["bucketContent"] = bucketContentResult
+			}
+			details.ResponseData = responseData
+		}
+	})
+	if updateErr != nil {
+		log.Error().Err(updateErr).Str("reqId", reqID).Msg("Failed to update request status")
 	}
 }
 
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller has handlers and their request/response bodies for migration APIs
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Drift Detection API
+// ============================================================================
+
+// GetInfraDrift godoc
+// @ID GetInfraDrift
+// @Summary Detect the drift of the migrated infrastructure
+// @Description Compare the target infrastructure model applied by the infra migration with the live infrastructure,
+// @Description and report the vNets, subnets, security groups, firewall rules, NodeGroups and nodes added, removed or changed afterwards.
+// @Description
+// @Description [Note]
+// @Description * The applied model is stored when the infra migration succeeds, so only the infrastructure migrated by Beetle can be checked
+// @Description * Node: spec, image, root disk size and the number of nodes per NodeGroup are compared
+// @Description * Firewall rule: compared per port (or port range); the SSH access rule added on the migration is a part of the applied rules
+// @Description * Set `remediation=true` to get the actions and the applied (desired) model of the drifted resources
+// @Tags [Migration] Infrastructure
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param infraId path string true "Infrastructure ID" default(mmci01)
+// @Param remediation query bool false "Generate the remediation" default(false)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[migration.DriftReport] "The drift report"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Applied model not found"
+// @Failure 500 {object} model.ApiResponse[any] "Failed to detect the drift"
+// @Router /migration/ns/{nsId}/infra/{infraId}/drift [get]
+func GetInfraDrift(c echo.Context) error {
+
+	// [Input]
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	infraId := c.Param("infraId")
+	if infraId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("infraId required"))
+	}
+
+	withRemediation := c.QueryParam("remediation") == "true"
+
+	// [Process]
+	report, err := migration.DetectDrift(nsId, infraId, withRemediation)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to detect the drift")
+		if strings.Contains(err.Error(), "does not exist") {
+			return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse(err.Error()))
+	}
+
+	// [Output]
+	message := "No drift detected"
+	if report.Drifted {
+		message = fmt.Sprintf("Drift detected: %d added, %d removed, %d changed",
+			report.Summary.Added, report.Summary.Removed, report.Summary.Changed)
+	}
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(report, message))
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller has handlers and their request/response bodies for migration APIs
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Migration Journal API
+// ============================================================================
+
+// ListMigrationJournals godoc
+// @ID ListMigrationJournals
+// @Summary List the migration journals
+// @Description List the migration journals (the resources created by each infra migration) in the namespace, newest first.
+// @Tags [Migration] Infrastructure
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[migration.MigrationJournalList] "The migration journals"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Router /migration/ns/{nsId}/journal [get]
+func ListMigrationJournals(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	journals := migration.ListMigrationJournals(nsId)
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(journals,
+		fmt.Sprintf("Listed %d migration journal(s)", len(journals.Journals))))
+}
+
+// GetMigrationJournal godoc
+// @ID GetMigrationJournal
+// @Summary Get a migration journal
+// @Description Get the migration journal, i.e., the resources created by the infra migration and their status.
+// @Tags [Migration] Infrastructure
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param journalId path string true "Migration journal ID (the request ID of the infra migration)"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[migration.MigrationJournal] "The migration journal"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Migration journal not found"
+// @Router /migration/ns/{nsId}/journal/{journalId} [get]
+func GetMigrationJournal(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	journalId := c.Param("journalId")
+	if journalId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("journalId required"))
+	}
+
+	journal, ok := migration.GetMigrationJournal(nsId, journalId)
+	if !ok {
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(fmt.Sprintf("Migration journal '%s' not found", journalId)))
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(journal))
+}
+
+// RollbackMigrationJournal godoc
+// @ID RollbackMigrationJournal
+// @Summary Roll back a migration journal
+// @Description Delete the resources created by the infra migration in reverse order of creation.
+// @Description
+// @Description [Note]
+// @Description * Only the resources created by the migration are deleted; reused (pre-existing) resources are never touched
+// @Description * Resources failed to be deleted are kept in the journal (status: DeleteFailed), so the rollback can be retried
+// @Description * A succeeded migration can also be rolled back (i.e., undone)
+// @Tags [Migration] Infrastructure
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param journalId path string true "Migration journal ID (the request ID of the infra migration)"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[migration.MigrationJournal] "The rolled-back migration journal"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Migration journal not found"
+// @Failure 409 {object} model.ApiResponse[any] "Migration journal in progress"
+// @Failure 500 {object} model.ApiResponse[migration.MigrationJournal] "Rollback failed for some resources"
+// @Router /migration/ns/{nsId}/journal/{journalId}/rollback [post]
+func RollbackMigrationJournal(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	journalId := c.Param("journalId")
+	if journalId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("journalId required"))
+	}
+
+	journal, err := migration.RollbackMigrationJournal(nsId, journalId)
+	if err != nil {
+		log.Error().Err(err).Str("journalId", journalId).Msg("Failed to roll back the migration journal")
+		switch {
+		case strings.Contains(err.Error(), "does not exist"):
+			return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(fmt.Sprintf("Migration journal '%s' not found", journalId)))
+		case strings.Contains(err.Error(), "cannot be rolled back"):
+			return c.JSON(http.StatusConflict, model.SimpleErrorResponse(err.Error()))
+		}
+		// Return the journal with the resources failed to be deleted
+		return c.JSON(http.StatusInternalServerError, model.ApiResponse[migration.MigrationJournal]{
+			Success: false,
+			Data:    journal,
+			Error:   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(journal,
+		fmt.Sprintf("Rolled back the migration journal '%s'", journalId)))
+}
@@ -22,6 +22,7 @@ import (
 	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
 	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
 	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/cloud-barista/cm-beetle/pkg/core/project"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -50,6 +51,7 @@ import (
 // @Param nsId path string true "Namespace ID" default(mig01)
 // @Param infraId path string true "Infra ID (target infra with NodeGroups already created)"
 // @Param request body cloudmodel.RecommendedNlb true "NLB migration request (use targetNlbList[] from /recommendation/infraWithNlb)"
+// @Param projectId query string false "Migration project ID to record the NLB migration job in"
 // @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided)"
 // @Success 201 {object} model.ApiResponse[cloudmodel.MigratedNlbResult] "NLBs created successfully"
 // @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
@@ -83,6 +85,24 @@ func MigrateNlbs(c echo.Context) error {
 		Msg("Starting NLB migration")
 
 	result, err := migration.CreateNlbs(nsId, infraId, req)
+
+	// Record the job in the migration project, if any
+	if projectId := c.QueryParam("projectId"); projectId != "" {
+		description := fmt.Sprintf("%d NLB(s)", len(req.TargetNlbList))
+		if err != nil {
+			description += fmt.Sprintf(" (failed: %v)", err)
+		}
+		recordErr := project.RecordJob(projectId, project.ProjectJob{
+			Type:        project.JobTypeNlb,
+			NsId:        nsId,
+			InfraId:     infraId,
+			ReqId:       c.Request().Header.Get(echo.HeaderXRequestID),
+			Description: description,
+		})
+		if recordErr != nil {
+			log.Warn().Err(recordErr).Msgf("failed to record the NLB migration in the project (projectId: %s)", projectId)
+		}
+	}
 	if err != nil {
 		log.Error().Err(err).Str("nsId", nsId).Str("infraId", infraId).Msg("NLB migration failed")
 		if strings.Contains(err.Error(), "all NLB migrations failed") {
@@ -23,6 +23,7 @@ import (
 	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
 	"github.com/cloud-barista/cm-beetle/pkg/core/common"
 	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/cloud-barista/cm-beetle/pkg/core/policy"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -49,9 +50,11 @@ type MigrateObjectStorageRequest struct {
 // @Description - This API creates object storages (buckets) in the target cloud within the specified namespace
 // @Description - Input should be the output from RecommendObjectStorage API
 // @Description - Connection name is automatically generated from CSP and region in the request body
+// @Description - The created buckets are labeled with the source bucket (`sourceBucket`), the migration time (`migratedAt`) and the request ID (`migrationReqId`)
+// @Description - The migration policies are evaluated first; the request is rejected with 403 on a violation (see POST /policy/evaluate)
 // @Description
 // @Description [Note] `nameSeed` enables dynamic naming via **Late Binding**.
-// @Description - If `nameSeed` query param is set (e.g., `?nameSeed=my`), bucket names are prefixed at migration time: `my-os-01`.
+// @Description - If `nameSeed` query param is set (e.g., `?nameSeed=my`), bucket names are prefixed (and lowercased) at migration time: `my-data-3f9a1c`.
 // @Description - If `nameSeed` is omitted, bucket names are used as-is from the recommendation result.
 // @Description
 // @Description [Examples]
@@ -61,11 +64,12 @@ type MigrateObjectStorageRequest struct {
 // @Accept json
 // @Produce	json
 // @Param nsId path string true "Namespace ID" default(mig01)
-// @Param nameSeed query string false "Optional prefix for bucket names (e.g., 'my' → 'my-os-01'). Applied at migration time."
+// @Param nameSeed query string false "Optional prefix for bucket names (e.g., 'my' → 'my-data-3f9a1c'). Applied at migration time."
 // @Param request body MigrateObjectStorageRequest true "Object storage migration request (use RecommendObjectStorage response)"
 // @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
 // @Success 201 "Created - Object storages created successfully"
 // @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 403 {object} model.ApiResponse[policy.EvaluationResult] "Blocked by the migration policies"
 // @Failure 500 {object} model.ApiResponse[any] "Internal server error during object storage creation"
 // @Router /migration/middleware/ns/{nsId}/objectStorage [post]
 func MigrateObjectStorage(c echo.Context) error {
@@ -96,9 +100,18 @@ func MigrateObjectStorage(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid nameSeed: "+detail))
 	}
 
-	if err := migration.CreateObjectStorage(nsId, req.RecommendedObjectStorage, nameSeed); err != nil {
+	// Evaluate the migration policies
+	if result := policy.EvaluateObjectStorage(req.RecommendedObjectStorage); !result.Allowed {
+		log.Warn().Err(result.Err()).Msg("rejected: object storage migration violates the policies")
+		return rejectByPolicies(c, result)
+	}
+
+	// Label the buckets with the request ID (and the source bucket and the migration time)
+	labels := map[string]string{common.LabelMigrationReqId: c.Request().Header.Get(echo.HeaderXRequestID)}
+
+	if err := migration.CreateObjectStorage(nsId, req.RecommendedObjectStorage, nameSeed, labels); err != nil {
 		log.Error().Err(err).Msg("Object storage migration failed")
-		if strings.Contains(err.Error(), "invalid cloud configuration") {
+		if strings.Contains(err.Error(), "invalid cloud configuration") || strings.Contains(err.Error(), "invalid bucket name") {
 			return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(err.Error()))
 		}
 		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse(err.Error()))
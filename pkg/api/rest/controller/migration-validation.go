@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller has handlers and their request/response bodies for migration APIs
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/validation"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Post-Migration Validation API
+// ============================================================================
+
+// ValidateMigratedInfra godoc
+// @ID ValidateMigratedInfra
+// @Summary Validate the migrated VMs
+// @Description Run the validation checks on the migrated VMs of the infrastructure via SSH and compare them with the source servers.
+// @Description
+// @Description [Note]
+// @Description * Built-in checks: OS release (ID and version), listening ports (single TCP ports allowed inbound by the source firewall rules),
+// @Description   data disks (mounted with the expected capacity), hostname (warning only) and timezone (against `expectedTimezone`, reported only if empty)
+// @Description * Custom checks: a command per check with the expected exit code (default: 0) and output (regular expression)
+// @Description * The source node of a VM is matched by the machine ID in the VM name, or by the hostname
+// @Description * The built-in checks are skipped for the VMs without a matching source node and for Windows sources
+// @Description * The result is stored as the latest validation of the infrastructure and included in the migration report
+// @Tags [Migration] Infrastructure
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param infraId path string true "Infrastructure ID" default(mmci01)
+// @Param request body validation.ValidationReq true "Source infrastructure and checks"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[validation.ValidationResult] "The validation result"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Infrastructure not found"
+// @Router /migration/ns/{nsId}/infra/{infraId}/validation [post]
+func ValidateMigratedInfra(c echo.Context) error {
+
+	// [Input]
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	infraId := c.Param("infraId")
+	if infraId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("infraId required"))
+	}
+
+	req := new(validation.ValidationReq)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	// [Process]
+	result, err := validation.ValidateInfra(nsId, infraId, *req)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to validate the migrated VMs")
+		if strings.Contains(err.Error(), "does not exist") {
+			return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(err.Error()))
+	}
+
+	// [Output]
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(result,
+		fmt.Sprintf("Validated %d node(s): %d passed, %d warned, %d failed", result.Summary.TotalNodes,
+			result.Summary.PassedNodes, result.Summary.WarnedNodes, result.Summary.FailedNodes)))
+}
+
+// GetMigratedInfraValidation godoc
+// @ID GetMigratedInfraValidation
+// @Summary Get the latest validation of the migrated VMs
+// @Description Get the latest validation result of the migrated VMs of the infrastructure.
+// @Tags [Migration] Infrastructure
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param infraId path string true "Infrastructure ID" default(mmci01)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[validation.ValidationResult] "The latest validation result"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Validation result not found"
+// @Router /migration/ns/{nsId}/infra/{infraId}/validation [get]
+func GetMigratedInfraValidation(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	infraId := c.Param("infraId")
+	if infraId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("infraId required"))
+	}
+
+	result, err := validation.GetLatestValidation(nsId, infraId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(result))
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller has handlers and their request/response bodies for migration APIs
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/cloud-barista/cm-beetle/pkg/core/policy"
+	"github.com/cloud-barista/cm-beetle/transx"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Migration Wave API
+// ============================================================================
+
+// CreateWavePlan godoc
+// @ID CreateWavePlan
+// @Summary Create a migration wave plan
+// @Description Create a wave plan which partitions the node groups, data migrations and NLB migrations into ordered waves
+// @Description (e.g., the DB tier first, then the app tier, then the web tier behind the NLB).
+// @Description
+// @Description [Waves]
+// @Description * The waves are executed in order; the steps of a wave are the Infra (node groups), the data migrations and the NLBs, in order
+// @Description * The node groups of a wave are migrated as an Infra named `<targetInfra.name>-<wave.name>`, reusing the vNets, SSH key and security groups created by the previous waves
+// @Description * Every node group of the target infra must be assigned to exactly one wave
+// @Description * `nlbs[].targetGroup.nodeGroupId` must be a node group of the same or a previous wave
+// @Description
+// @Description [Gates] (all of them must be met to start the wave)
+// @Description * `previousWaveHealthy`: the previous wave succeeded and the VMs of its Infra are all running
+// @Description * `manualApproval`: the wave is approved via POST /migration/ns/{nsId}/wavePlan/{planId}/wave/{waveName}/approve
+// @Description * `timeWindow`: the current time is within the daily time window (the wave plan is resumed automatically when the window opens)
+// @Description
+// @Description [Note]
+// @Description * The plan is only stored; start it via POST /migration/ns/{nsId}/wavePlan/{planId}/resume, or with `start=true`
+// @Description * Encrypted data migrations (see GET /migration/data/encryptionKey) are decrypted on creation
+// @Description * The credentials of the data migrations are stored encrypted with the wave secret key (`wave-secret.pem` next to the lkvstore file) and masked in the responses; keep the key to resume the wave plans
+// @Description * The migration policies are evaluated against the target infra and the data migrations on creation; the plan is rejected with 403 on a violation
+// @Tags [Migration] Wave (incubating)
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param start query bool false "Start the wave plan right after the creation (default: false)"
+// @Param wavePlanReq body migration.WavePlanReq true "Wave plan"
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
+// @Success 201 {object} model.ApiResponse[migration.WavePlan] "The created wave plan"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid wave plan"
+// @Failure 403 {object} model.ApiResponse[policy.EvaluationResult] "Blocked by the migration policies"
+// @Failure 409 {object} model.ApiResponse[any] "Wave plan already exists"
+// @Router /migration/ns/{nsId}/wavePlan [post]
+func CreateWavePlan(c echo.Context) error {
+
+	// [Input]
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	req := new(migration.WavePlanReq)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	// Decrypt the encrypted data migrations
+	for i := range req.Waves {
+		for j, dmm := range req.Waves[i].DataMigrations {
+			if !dmm.IsEncrypted() {
+				continue
+			}
+			decrypted, err := transx.DecryptModel(dmm)
+			if err != nil {
+				log.Error().Err(err).Str("keyId", dmm.EncryptionKeyID).Msg("Failed to decrypt request")
+				return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(
+					fmt.Sprintf("Decryption failed for data migration %d of wave %s", j+1, req.Waves[i].Name)))
+			}
+			req.Waves[i].DataMigrations[j] = decrypted
+		}
+	}
+
+	// Evaluate the migration policies against the target infra and the data migrations
+	results := []policy.EvaluationResult{policy.EvaluateInfra(req.TargetInfra)}
+	for _, wave := range req.Waves {
+		for _, dmm := range wave.DataMigrations {
+			results = append(results, policy.EvaluateDataMigration(dmm))
+		}
+	}
+	for _, result := range results {
+		if !result.Allowed {
+			log.Warn().Err(result.Err()).Msg("rejected: wave plan violates the policies")
+			return rejectByPolicies(c, result)
+		}
+	}
+
+	// [Process]
+	plan, err := migration.CreateWavePlan(nsId, *req)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create the wave plan")
+		if strings.Contains(err.Error(), "already exists") {
+			return c.JSON(http.StatusConflict, model.SimpleErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(err.Error()))
+	}
+
+	if c.QueryParam("start") == "true" {
+		reqId := c.Request().Header.Get(echo.HeaderXRequestID)
+		plan, err = migration.ResumeWavePlan(nsId, plan.Id, reqId)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to start the wave plan")
+			return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusCreated, model.SuccessResponseWithMessage(plan,
+			fmt.Sprintf("Created and started the wave plan '%s' (%d wave(s))", plan.Id, len(plan.Waves))))
+	}
+
+	// [Output]
+	return c.JSON(http.StatusCreated, model.SuccessResponseWithMessage(plan,
+		fmt.Sprintf("Created the wave plan '%s' (%d wave(s))", plan.Id, len(plan.Waves))))
+}
+
+// ListWavePlans godoc
+// @ID ListWavePlans
+// @Summary List the migration wave plans
+// @Description List the wave plans in the namespace and their execution state, newest first.
+// @Tags [Migration] Wave (incubating)
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[migration.WavePlanList] "The wave plans"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Router /migration/ns/{nsId}/wavePlan [get]
+func ListWavePlans(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	plans := migration.ListWavePlans(nsId)
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(plans,
+		fmt.Sprintf("Listed %d wave plan(s)", len(plans.WavePlans))))
+}
+
+// GetWavePlan godoc
+// @ID GetWavePlan
+// @Summary Get a migration wave plan
+// @Description Get the wave plan and its execution state (the status, gate and steps of each wave).
+// @Tags [Migration] Wave (incubating)
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param planId path string true "Wave plan ID"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[migration.WavePlan] "The wave plan"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Wave plan not found"
+// @Router /migration/ns/{nsId}/wavePlan/{planId} [get]
+func GetWavePlan(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	planId := c.Param("planId")
+	if planId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("planId required"))
+	}
+
+	plan, ok := migration.GetWavePlan(nsId, planId)
+	if !ok {
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(fmt.Sprintf("Wave plan '%s' not found", planId)))
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(plan))
+}
+
+// ResumeWavePlan godoc
+// @ID ResumeWavePlan
+// @Summary [Async] Start or resume a migration wave plan
+// @Description Start (or resume) the wave plan in the background from the first wave not succeeded.
+// @Description
+// @Description [Note]
+// @Description * The gate of the wave is checked again; the wave plan pauses if the gate is not met (see `currentWave` and `message`)
+// @Description * The succeeded steps of a failed wave are skipped
+// @Description * The progress of the waves is published in `progress` of GET /request/{reqId}, and the state is kept in the wave plan
+// @Tags [Migration] Wave (incubating)
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param planId path string true "Wave plan ID"
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used as reqId for tracking the progress."
+// @Success 202 {object} model.ApiResponse[model.AsyncJobResponse] "Wave plan started - use GET /request/{reqId} or GET /migration/ns/{nsId}/wavePlan/{planId} to check progress"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Wave plan not found"
+// @Failure 409 {object} model.ApiResponse[any] "Wave plan running or already succeeded"
+// @Router /migration/ns/{nsId}/wavePlan/{planId}/resume [post]
+func ResumeWavePlan(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	planId := c.Param("planId")
+	if planId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("planId required"))
+	}
+
+	reqId := c.Request().Header.Get(echo.HeaderXRequestID)
+	if _, err := migration.ResumeWavePlan(nsId, planId, reqId); err != nil {
+		return wavePlanErrorResponse(c, planId, err)
+	}
+
+	return c.JSON(http.StatusAccepted, model.SuccessResponseWithMessage(
+		model.AsyncJobResponse{
+			ReqID:     reqId,
+			Status:    common.RequestStatusHandling,
+			StatusURL: fmt.Sprintf("/beetle/request/%s", reqId),
+		},
+		fmt.Sprintf("Wave plan started. Use GET /request/{reqId} or GET /migration/ns/%s/wavePlan/%s to check progress.", nsId, planId),
+	))
+}
+
+// ApproveWave godoc
+// @ID ApproveWave
+// @Summary Approve a wave of a migration wave plan
+// @Description Approve the manual approval gate of the wave.
+// @Description If the wave plan is paused awaiting the approval of the wave, it is resumed in the background.
+// @Description A wave can also be approved in advance (e.g., while the previous waves are running).
+// @Tags [Migration] Wave (incubating)
+// @Accept json
+// @Produce json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param planId path string true "Wave plan ID"
+// @Param waveName path string true "Wave name"
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used as reqId for tracking the progress if resumed."
+// @Success 200 {object} model.ApiResponse[migration.WavePlan] "The wave plan"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Wave plan or wave not found"
+// @Failure 409 {object} model.ApiResponse[any] "Wave already succeeded"
+// @Router /migration/ns/{nsId}/wavePlan/{planId}/wave/{waveName}/approve [post]
+func ApproveWave(c echo.Context) error {
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("nsId required"))
+	}
+
+	planId := c.Param("planId")
+	waveName := c.Param("waveName")
+	if planId == "" || waveName == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("planId and waveName required"))
+	}
+
+	reqId := c.Request().Header.Get(echo.HeaderXRequestID)
+	plan, err := migration.ApproveWave(nsId, planId, waveName, reqId)
+	if err != nil {
+		return wavePlanErrorResponse(c, planId, err)
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(plan,
+		fmt.Sprintf("Approved the wave '%s' of the wave plan '%s' (status: %s)", waveName, planId, plan.Status)))
+}
+
+// wavePlanErrorResponse maps the error of the wave plan operation to the HTTP status.
+func wavePlanErrorResponse(c echo.Context, planId string, err error) error {
+	log.Error().Err(err).Str("planId", planId).Msg("Failed to operate the wave plan")
+	switch {
+	case strings.Contains(err.Error(), "does not exist"):
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+	case strings.Contains(err.Error(), "cannot be"):
+		return c.JSON(http.StatusConflict, model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse(err.Error()))
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	// cloudmodel "github.com/cloud-barista/cm-beetle/pkg/api/rest/model/cloud/infra"
 
@@ -26,6 +27,8 @@ import (
 	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
 	"github.com/cloud-barista/cm-beetle/pkg/core/common"
 	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/cloud-barista/cm-beetle/pkg/core/policy"
+	"github.com/cloud-barista/cm-beetle/pkg/core/project"
 	"github.com/labstack/echo/v4"
 
 	"github.com/rs/zerolog/log"
@@ -109,16 +112,46 @@ type MigrateInfraResponse struct {
 // @Description - **Non-empty**: TumbleBug sends this to Spider directly, bypassing the per-VM image DB lookup (prevents stale image failures, e.g., Alibaba alibase images).
 // @Description - **Empty**: TumbleBug uses `imageId` for the standard DB lookup (may encounter stale images for some CSPs).
 // @Description - Recommended: pass the recommendation API response as-is to use the latest resolved image.
+// @Description
+// @Description [Note]
+// @Description * The resources created (not reused) by the migration are recorded in a migration journal (journalId: the request ID)
+// @Description * On failure, the created resources are deleted in reverse order of creation according to `rollbackPolicy`
+// @Description * The journal can be checked via GET /migration/ns/{nsId}/journal/{journalId} and rolled back later via POST /migration/ns/{nsId}/journal/{journalId}/rollback
+// @Description
+// @Description [Labels]
+// @Description * The created resources are labeled with the migration: `migrationReqId`, `migratedAt`, `migrationProject` (with `projectId`) and `migrationWave` (by a wave plan)
+// @Description * Each VM is also labeled with its source server (`sourceMachineId`, `sourceHostname`), which the summary and the report use for correlation
+// @Description
+// @Description [Asynchronous Operation]
+// @Description * With `async=true`, this API returns 202 Accepted with the request ID right after the validation, and the migration runs in the background
+// @Description * The step-level progress (preflight, per-resource create/reuse, VM provisioning status) is published in `progress` of GET /request/{reqId}
+// @Description * Live updates are available as Server-Sent Events via GET /request/{reqId}/events
+// @Description
+// @Description [Migration Project]
+// @Description * With `projectId`, the migrated infrastructure and its status are recorded in the project
+// @Description * The approved candidate of the project is migrated if the request body has no target infrastructure
+// @Description * If the project has an approved candidate, a target infrastructure other than it is rejected with 409
+// @Description
+// @Description [Migration Policy]
+// @Description * The migration policies (allowed CSPs/regions, max monthly cost, forbidden ingress, required tags) are evaluated before the migration
+// @Description * The security groups are evaluated with the SSH access rule added by the migration
+// @Description * The request is rejected with 403 and the violations on a violation; evaluate in advance via POST /policy/evaluate
 // @Tags [Migration] Infrastructure
 // @Accept  json
 // @Produce  json
 // @Param nsId path string true "Namespace ID" default(mig01)
+// @Param projectId query string false "Migration project ID to record the migration in (and to get the approved candidate from)"
 // @Param nameSeed query string false "Optional prefix for all resource names (e.g., 'blue' → 'blue-infra101', 'blue-vnet-01'). Applied at migration time."
 // @Param useExisting query bool false "Reuse existing resources (VNet, SSH Key, Security Group) if they already exist, instead of creating new ones (default: true)"
+// @Param async query bool false "Run the migration in the background and return 202 Accepted with the request ID (default: false)"
+// @Param rollbackPolicy query string false "Rollback policy on failure: always (roll back the created resources), never (leave them), keep-for-debug (keep them to be rolled back later via the journal)" Enums(always,never,keep-for-debug) default(always)
 // @Param infraInfo body MigrateInfraRequest true "Specify the information for the targeted multi-cloud infrastructure"
 // @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
 // @Success 201 {object} model.ApiResponse[MigrateInfraResponse] "Successfully migrated to the multi-cloud infrastructure"
+// @Success 202 {object} model.ApiResponse[model.AsyncJobResponse] "Migration started (async=true) - use GET /request/{reqId} or GET /request/{reqId}/events to check progress"
+// @Failure 403 {object} model.ApiResponse[policy.EvaluationResult] "Blocked by the migration policies"
 // @Failure 404 {object} model.ApiResponse[any]
+// @Failure 409 {object} model.ApiResponse[any] "Not the approved candidate of the project, or the project has no approved candidate"
 // @Failure 500 {object} model.ApiResponse[any]
 // @Router /migration/ns/{nsId}/infra [post]
 func MigrateInfra(c echo.Context) error {
@@ -140,6 +173,26 @@ func MigrateInfra(c echo.Context) error {
 	// log.Debug().Msgf("req: %+v", req)
 	log.Debug().Msgf("req.RecommendedVmInfra: %+v", req.RecommendedInfra)
 
+	// Use the approved candidate of the migration project if the target infrastructure is not posted,
+	// and reject a posted one other than the approved candidate
+	projectId := c.QueryParam("projectId")
+	if projectId != "" {
+		if _, err := project.GetProject(projectId); err != nil {
+			return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+		}
+		if req.RecommendedInfra.TargetInfra.Name == "" {
+			approved, err := project.GetApprovedModel(projectId)
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to get the approved candidate of the project")
+				return c.JSON(http.StatusConflict, model.SimpleErrorResponse(err.Error()))
+			}
+			req.RecommendedInfra = approved
+		} else if err := project.CheckApprovedModel(projectId, req.RecommendedInfra); err != nil {
+			log.Warn().Err(err).Msg("rejected: the target infrastructure is not the approved candidate of the project")
+			return c.JSON(http.StatusConflict, model.SimpleErrorResponse(err.Error()))
+		}
+	}
+
 	// [Process]
 	// Apply NameSeed (Late Binding) from query param before migration.
 	// Query param takes precedence; if empty, no prefix is applied.
@@ -156,21 +209,56 @@ func MigrateInfra(c echo.Context) error {
 		useExisting = false
 	}
 
+	// Parse rollbackPolicy parameter (default: always)
+	rollbackPolicy := c.QueryParam("rollbackPolicy")
+	if !migration.IsValidRollbackPolicy(rollbackPolicy) {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(fmt.Sprintf("Invalid rollbackPolicy: %s (supported: always, never, keep-for-debug)", rollbackPolicy)))
+	}
+
 	// Validate names and referential integrity
 	if ok, detail := common.ValidateComposedNames(infraToMigrate); !ok {
 		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Naming/Reference validation failed: "+detail))
 	}
 
+	// Label the migrated resources with the migration project, if any
+	migration.SetMigrationLabels(&infraToMigrate, map[string]string{common.LabelMigrationProject: projectId})
+
+	// Evaluate the migration policies
+	if result := policy.EvaluateInfra(infraToMigrate); !result.Allowed {
+		log.Warn().Err(result.Err()).Msg("rejected: infra migration violates the policies")
+		return rejectByPolicies(c, result)
+	}
+
+	// The request ID is used as the ID of the migration journal and to publish the progress
+	reqId := c.Request().Header.Get(echo.HeaderXRequestID)
+
+	// Run the migration in background if requested
+	if c.QueryParam("async") == "true" {
+		recordProjectInfra(projectId, nsId, infraToMigrate.TargetInfra.Name, reqId, true, nil)
+		go executeInfraMigrationAsync(reqId, nsId, projectId, infraToMigrate, useExisting, rollbackPolicy)
+
+		log.Info().Str("reqId", reqId).Msg("Infra migration started asynchronously")
+		return c.JSON(http.StatusAccepted, model.SuccessResponseWithMessage(
+			model.AsyncJobResponse{
+				ReqID:     reqId,
+				Status:    common.RequestStatusHandling,
+				StatusURL: fmt.Sprintf("/beetle/request/%s", reqId),
+			},
+			"Migration started. Use GET /request/{reqId} or GET /request/{reqId}/events to check progress.",
+		))
+	}
+
 	// Create the VM infrastructure for migration
 	var mciInfo cloudmodel.VmInfraInfo
 	var err error
 	if useExisting {
-		mciInfo, err = migration.CreateInfraWithExisting(nsId, &infraToMigrate)
+		mciInfo, err = migration.CreateInfraWithExisting(nsId, &infraToMigrate, reqId, rollbackPolicy)
 	} else {
-		mciInfo, err = migration.CreateInfra(nsId, &infraToMigrate)
+		mciInfo, err = migration.CreateInfra(nsId, &infraToMigrate, reqId, rollbackPolicy)
 	}
 
 	log.Debug().Msgf("mciInfo: %+v", mciInfo)
+	recordProjectInfra(projectId, nsId, infraToMigrate.TargetInfra.Name, reqId, false, err)
 
 	// [Output]
 	if err != nil {
@@ -182,6 +270,134 @@ func MigrateInfra(c echo.Context) error {
 	return c.JSON(http.StatusCreated, model.SuccessResponse(mciInfo))
 }
 
+// executeInfraMigrationAsync performs the infra migration in background and updates request status.
+func executeInfraMigrationAsync(reqId, nsId, projectId string, infraToMigrate cloudmodel.RecommendedInfra, useExisting bool, rollbackPolicy string) {
+	startTime := time.Now()
+
+	var mciInfo cloudmodel.VmInfraInfo
+	var err error
+	if useExisting {
+		mciInfo, err = migration.CreateInfraWithExisting(nsId, &infraToMigrate, reqId, rollbackPolicy)
+	} else {
+		mciInfo, err = migration.CreateInfra(nsId, &infraToMigrate, reqId, rollbackPolicy)
+	}
+
+	elapsedTime := time.Since(startTime)
+	recordProjectInfra(projectId, nsId, infraToMigrate.TargetInfra.Name, reqId, false, err)
+
+	if err != nil {
+		log.Error().Err(err).Str("reqId", reqId).Dur("elapsedTime", elapsedTime).Msg("Infra migration failed")
+	} else {
+		log.Info().Str("reqId", reqId).Dur("elapsedTime", elapsedTime).Msg("Infra migration completed successfully")
+	}
+
+	// Update the request details (keeping the progress published so far)
+	updateErr := common.UpdateRequest(reqId, func(details *common.RequestDetails) {
+		details.EndTime = time.Now()
+		if err != nil {
+			details.Status = common.RequestStatusError
+			details.ErrorResponse = fmt.Sprintf("Infra migration failed: %v (%s)", err, elapsedTime.Round(time.Millisecond))
+		} else {
+			details.Status = common.RequestStatusSuccess
+			details.ResponseData = mciInfo
+		}
+	})
+	if updateErr != nil {
+		log.Error().Err(updateErr).Str("reqId", reqId).Msg("Failed to update request status")
+	}
+}
+
+// recordProjectInfra records the infra migration in the migration project, if any.
+// The status is Handling if the migration is in progress, Success or Error by the result otherwise.
+func recordProjectInfra(projectId, nsId, infraId, reqId string, inProgress bool, result error) {
+	if projectId == "" {
+		return
+	}
+
+	infra := project.MigratedInfra{NsId: nsId, InfraId: infraId, ReqId: reqId, Status: common.RequestStatusHandling}
+	if !inProgress {
+		infra.Status = common.RequestStatusSuccess
+		if result != nil {
+			infra.Status = common.RequestStatusError
+			infra.Error = result.Error()
+		}
+	}
+	if err := project.RecordInfra(projectId, infra); err != nil {
+		log.Warn().Err(err).Msgf("failed to record the infra migration in the project (projectId: %s)", projectId)
+	}
+}
+
+// PlanInfraMigration godoc
+// @ID PlanInfraMigration
+// @Summary Plan the infrastructure migration (what-if)
+// @Description Show what the infrastructure migration (POST /migration/ns/{nsId}/infra) would do with the same request, without creating anything.
+// @Description
+// @Description The plan runs the validation, the preflight check and the create/reuse decisions of the migration, and returns:
+// @Description - `resources`: the planned action per resource (vNet, sshKey, securityGroup, infra)
+// @Description   - `create`: the resource will be created (and journaled)
+// @Description   - `reuse`: the existing resource will be reused (useExisting=true only)
+// @Description   - `conflict`: the migration would fail on the resource (e.g., an existing name in the fresh mode, a vNet without the required subnets), see `reason`
+// @Description - `nodeGroups`: the preflight result per node group (image availability, resolved CSP image name, suggested root disk type)
+// @Description - `errors`: the validation, preflight and namespace errors
+// @Description - `executable`: true if there is no error and no conflict
+// @Description
+// @Description [Note]
+// @Description * The plan reflects the state at the time of the request; the resources may change before the migration
+// @Description * The resolved CSP image name and the suggested root disk type are applied automatically by the migration
+// @Tags [Migration] Infrastructure
+// @Accept  json
+// @Produce  json
+// @Param nsId path string true "Namespace ID" default(mig01)
+// @Param nameSeed query string false "Optional prefix for all resource names (same as the migration API)"
+// @Param useExisting query bool false "Plan with reusing existing resources (VNet, SSH Key, Security Group), the same as the migration API (default: true)"
+// @Param infraInfo body MigrateInfraRequest true "Specify the information for the targeted multi-cloud infrastructure"
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
+// @Success 200 {object} model.ApiResponse[migration.InfraPlan] "The plan of the infrastructure migration"
+// @Failure 400 {object} model.ApiResponse[any]
+// @Failure 500 {object} model.ApiResponse[any]
+// @Router /migration/ns/{nsId}/infra/plan [post]
+func PlanInfraMigration(c echo.Context) error {
+
+	// [Input]
+	nsId := c.Param("nsId")
+	if nsId == "" {
+		err := fmt.Errorf("invalid request, namespace ID (nsId: %s) is required", nsId)
+		log.Warn().Msg(err.Error())
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(err.Error()))
+	}
+
+	req := new(MigrateInfraRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	nameSeed := c.QueryParam("nameSeed")
+	if ok, detail := common.IsValidNameSeed(nameSeed); !ok {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid nameSeed: "+detail))
+	}
+	infraToPlan := common.ApplyNameSeed(req.RecommendedInfra, nameSeed)
+
+	// Parse useExisting parameter (default: true)
+	useExisting := c.QueryParam("useExisting") != "false"
+
+	// Validate names and referential integrity
+	if ok, detail := common.ValidateComposedNames(infraToPlan); !ok {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Naming/Reference validation failed: "+detail))
+	}
+
+	// [Process]
+	plan, err := migration.PlanInfra(nsId, &infraToPlan, useExisting)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to plan the infrastructure migration")
+		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse(err.Error()))
+	}
+
+	// [Output]
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(plan,
+		fmt.Sprintf("Plan: %d to create, %d to reuse, %d conflict(s), %d error(s)",
+			plan.Summary.ToCreate, plan.Summary.ToReuse, plan.Summary.Conflicts, len(plan.Errors))))
+}
+
 // ListInfra godoc
 // @ID ListInfra
 // @Summary Get the migrated multi-cloud infrastructure (MCI)
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller has handlers and their request/response bodies for notification APIs
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/notification"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Notification API
+// ============================================================================
+
+// ListNotificationOutbox godoc
+// @ID ListNotificationOutbox
+// @Summary List the events in the notification outbox
+// @Description List the events waiting for the delivery (pending) or given up (failed) in the notification outbox, oldest first.
+// @Description
+// @Description [Notifications]
+// @Description * The events are delivered to the webhooks set by `beetle.notification.webhook.urls` (or `BEETLE_NOTIFICATION_WEBHOOK_URLS`) as CloudEvents (v1.0, structured mode)
+// @Description * Event types: `org.cloud-barista.beetle.request.{handling,success,error}`, `org.cloud-barista.beetle.migration.data.progress`, `org.cloud-barista.beetle.migration.infra.completed` and `org.cloud-barista.beetle.policy.violated`
+// @Description * With the secret set, the events are signed: `X-Beetle-Signature: sha256=<hex(HMAC-SHA256(secret, "<X-Beetle-Timestamp>.<body>"))>`
+// @Description
+// @Description [Note]
+// @Description * The requests of the read-only methods (GET, HEAD, OPTIONS) are not notified
+// @Description * A failed delivery is retried with an exponential backoff (5s up to 10m) until the max attempts (`beetle.notification.maxattempts`)
+// @Description * The events are delivered at least once and in order per webhook; deduplicate them by `id` if needed
+// @Description * The delivered events are removed from the outbox, and the failed events are removed 7 days after the last attempt
+// @Description * Each webhook is delivered by its own worker; a slow or dead webhook does not hold the events of the others
+// @Tags [Admin] Notification (incubating)
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by the status" Enums(pending,failed)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[notification.OutboxList] "The events in the outbox"
+// @Router /notification/outbox [get]
+func ListNotificationOutbox(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status != "" && status != notification.OutboxStatusPending && status != notification.OutboxStatusFailed {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(
+			fmt.Sprintf("invalid status: %s (pending or failed)", status)))
+	}
+
+	outbox := notification.ListOutbox(status)
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(outbox,
+		fmt.Sprintf("Listed %d event(s) in the outbox", len(outbox.Entries))))
+}
+
+// RedeliverNotification godoc
+// @ID RedeliverNotification
+// @Summary Redeliver an event in the notification outbox
+// @Description Reset the attempts of an event in the notification outbox (e.g., failed after the max attempts) to deliver it again.
+// @Tags [Admin] Notification (incubating)
+// @Accept json
+// @Produce json
+// @Param entryId path string true "Outbox entry ID"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[notification.OutboxEntry] "The event is queued for the redelivery"
+// @Failure 404 {object} model.ApiResponse[any] "Outbox entry not found"
+// @Router /notification/outbox/{entryId}/redeliver [post]
+func RedeliverNotification(c echo.Context) error {
+	entryId := c.Param("entryId")
+
+	entry, err := notification.Redeliver(entryId)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to redeliver the event")
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(entry, "Queued the event for the redelivery"))
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller has handlers and their request/response bodies for migration policy APIs
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	storagemodel "github.com/cloud-barista/cm-beetle/imdl/storage-model"
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/notification"
+	"github.com/cloud-barista/cm-beetle/pkg/core/policy"
+	"github.com/cloud-barista/cm-beetle/transx"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Migration Policy API
+// ============================================================================
+
+// EvaluatePolicyRequest is the migration models to evaluate (at least one of them)
+type EvaluatePolicyRequest struct {
+	Infra         *cloudmodel.RecommendedInfra           `json:"infra,omitempty"`
+	DataMigration *transx.DataMigrationModel             `json:"dataMigration,omitempty"`
+	ObjectStorage *storagemodel.RecommendedObjectStorage `json:"objectStorage,omitempty"`
+}
+
+// EvaluatePolicyResponse is the evaluation result per migration model
+type EvaluatePolicyResponse struct {
+	Allowed bool                      `json:"allowed"`
+	Results []policy.EvaluationResult `json:"results"`
+}
+
+// ListPolicies godoc
+// @ID ListPolicies
+// @Summary List the migration policies
+// @Description List the migration policies loaded from the directory set by `beetle.policy.path` (or `BEETLE_POLICY_PATH`).
+// @Description
+// @Description [Note]
+// @Description * No policy is enforced if no policy is loaded
+// @Tags [Migration] Policy (incubating)
+// @Accept json
+// @Produce json
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[[]policy.Policy] "The migration policies"
+// @Router /policy [get]
+func ListPolicies(c echo.Context) error {
+	policies := policy.ListPolicies()
+	return c.JSON(http.StatusOK, model.SuccessListResponseWithMessage(policies,
+		fmt.Sprintf("Listed %d policy(ies)", len(policies))))
+}
+
+// EvaluatePolicy godoc
+// @ID EvaluatePolicy
+// @Summary Evaluate the migration policies (dry-run)
+// @Description Evaluate the migration policies against the migration models without executing anything,
+// @Description the same as the infra, data and object storage migration APIs do before executing.
+// @Description
+// @Description [Rules]
+// @Description * `allowedCsps`, `allowedRegions`: the target cloud of the infra and the object storage
+// @Description * `maxMonthlyCostUsd`: the estimated monthly cost of the infra (the hourly cost of the specs x the number of nodes x 730 hours)
+// @Description * `forbiddenIngress`: the inbound firewall rules of the security groups, including the SSH access rule added by the migration
+// @Description * `requiredTags`: the labels of the infra and the tags of the buckets
+// @Description * `encryptionRequired`: the server-side encryption of the buckets and the encryption in transit of the data migration (SSH, SSL/HTTPS)
+// @Description
+// @Description [Note]
+// @Description * `allowed` is false if any of the models has a violation of a policy in the `enforce` mode
+// @Description * The violations of the policies in the `warn` mode and the specs of unknown cost are reported as `warnings`
+// @Description * Encrypted data migrations can be evaluated as they are (the evaluated fields are not encrypted)
+// @Tags [Migration] Policy (incubating)
+// @Accept json
+// @Produce json
+// @Param request body EvaluatePolicyRequest true "Migration models to evaluate"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[EvaluatePolicyResponse] "The evaluation results"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Router /policy/evaluate [post]
+func EvaluatePolicy(c echo.Context) error {
+	req := new(EvaluatePolicyRequest)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+	if req.Infra == nil && req.DataMigration == nil && req.ObjectStorage == nil {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("at least one of infra, dataMigration and objectStorage is required"))
+	}
+
+	resp := EvaluatePolicyResponse{Allowed: true, Results: []policy.EvaluationResult{}}
+	if req.Infra != nil {
+		resp.Results = append(resp.Results, policy.EvaluateInfra(*req.Infra))
+	}
+	if req.DataMigration != nil {
+		resp.Results = append(resp.Results, policy.EvaluateDataMigration(*req.DataMigration))
+	}
+	if req.ObjectStorage != nil {
+		resp.Results = append(resp.Results, policy.EvaluateObjectStorage(*req.ObjectStorage))
+	}
+
+	violations := 0
+	for _, result := range resp.Results {
+		violations += len(result.Violations)
+		if !result.Allowed {
+			resp.Allowed = false
+		}
+	}
+
+	message := "Allowed by the migration policies"
+	if !resp.Allowed {
+		message = fmt.Sprintf("Blocked by %d policy violation(s)", violations)
+	}
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(resp, message))
+}
+
+// PolicyViolationEventData is the data of the event notifying a migration blocked by the policies
+type PolicyViolationEventData struct {
+	ReqId  string                  `json:"reqId" example:"1735689600000000000"`
+	Method string                  `json:"method" example:"POST"`
+	Url    string                  `json:"url" example:"/beetle/migration/ns/mig01/infra"`
+	Result policy.EvaluationResult `json:"result"`
+}
+
+// rejectByPolicies responds to a migration blocked by the policies with 403 and the violations,
+// and publishes the violations to the webhooks (if any).
+func rejectByPolicies(c echo.Context, result policy.EvaluationResult) error {
+	reqId := c.Request().Header.Get(echo.HeaderXRequestID)
+	notification.Publish(notification.EventTypePolicyViolated, reqId, PolicyViolationEventData{
+		ReqId:  reqId,
+		Method: c.Request().Method,
+		Url:    c.Request().URL.String(),
+		Result: result,
+	})
+
+	return c.JSON(http.StatusForbidden, model.ApiResponse[policy.EvaluationResult]{
+		Success: false,
+		Data:    result,
+		Error:   result.Err().Error(),
+	})
+}
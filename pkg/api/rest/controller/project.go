@@ -0,0 +1,323 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller has handlers and their request/response bodies for migration project APIs
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/project"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Migration Project API
+// ============================================================================
+
+// CreateProject godoc
+// @ID CreateProject
+// @Summary Create a migration project
+// @Description Create a migration project, which ties the artifacts of a migration together:
+// @Description the source infrastructure, the versioned recommendation results, the selected and approved candidate,
+// @Description the migrated infrastructures, the data/NLB migration jobs and the generated reports, with the history.
+// @Description
+// @Description [Note]
+// @Description * Pass `projectId` to the recommendation, migration and report APIs to record their results in the project
+// @Description * With `projectId`, the source infrastructure and the approved candidate don't need to be posted again
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param request body project.ProjectReq true "Migration project"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 201 {object} model.ApiResponse[project.Project] "The created project"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 409 {object} model.ApiResponse[any] "Project already exists"
+// @Router /project [post]
+func CreateProject(c echo.Context) error {
+	req := new(project.ProjectReq)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	created, err := project.CreateProject(*req)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create the project")
+		return c.JSON(projectErrorStatus(err), model.SimpleErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(created))
+}
+
+// ListProjects godoc
+// @ID ListProjects
+// @Summary List the migration projects
+// @Description List the migration projects, newest first (without the source infrastructure, the candidates and the report contents).
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[project.ProjectList] "The migration projects"
+// @Router /project [get]
+func ListProjects(c echo.Context) error {
+	projects := project.ListProjects()
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(projects,
+		fmt.Sprintf("Listed %d project(s)", len(projects.Projects))))
+}
+
+// GetProject godoc
+// @ID GetProject
+// @Summary Get a migration project
+// @Description Get the migration project with all its artifacts.
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID" default(mig-project01)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[project.Project] "The migration project"
+// @Failure 404 {object} model.ApiResponse[any] "Project not found"
+// @Router /project/{projectId} [get]
+func GetProject(c echo.Context) error {
+	found, err := project.GetProject(c.Param("projectId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, model.SuccessResponse(found))
+}
+
+// UpdateProject godoc
+// @ID UpdateProject
+// @Summary Update a migration project
+// @Description Update the description and the source infrastructure of the migration project.
+// @Description
+// @Description [Note]
+// @Description * The name (i.e., the project ID) cannot be changed
+// @Description * Changing the source infrastructure invalidates the approval
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID" default(mig-project01)
+// @Param request body project.ProjectReq true "Migration project"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[project.Project] "The updated project"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Project not found"
+// @Router /project/{projectId} [put]
+func UpdateProject(c echo.Context) error {
+	req := new(project.ProjectReq)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	updated, err := project.UpdateProject(c.Param("projectId"), *req)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to update the project")
+		return c.JSON(projectErrorStatus(err), model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, model.SuccessResponse(updated))
+}
+
+// DeleteProject godoc
+// @ID DeleteProject
+// @Summary Delete a migration project
+// @Description Delete the migration project. The migrated infrastructures are not deleted.
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID" default(mig-project01)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[any] "The project deleted"
+// @Failure 404 {object} model.ApiResponse[any] "Project not found"
+// @Router /project/{projectId} [delete]
+func DeleteProject(c echo.Context) error {
+	projectId := c.Param("projectId")
+	if err := project.DeleteProject(projectId); err != nil {
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, model.SimpleSuccessResponse(fmt.Sprintf("Project '%s' deleted", projectId)))
+}
+
+// GetProjectHistory godoc
+// @ID GetProjectHistory
+// @Summary Get the history of a migration project
+// @Description Get the history (created, recommended, selected, approved, migrated, reported, ...) of the migration project, oldest first.
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID" default(mig-project01)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[[]project.ProjectEvent] "The history"
+// @Failure 404 {object} model.ApiResponse[any] "Project not found"
+// @Router /project/{projectId}/history [get]
+func GetProjectHistory(c echo.Context) error {
+	history, err := project.GetHistory(c.Param("projectId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, model.SuccessListResponse(history))
+}
+
+// AddProjectRecommendationRequest is the request body to add a recommendation result to a project.
+type AddProjectRecommendationRequest struct {
+	Candidates  []cloudmodel.RecommendedInfra `json:"candidates" validate:"required"`
+	Description string                        `json:"description,omitempty"`
+}
+
+// AddProjectRecommendation godoc
+// @ID AddProjectRecommendation
+// @Summary Add a recommendation result to a migration project
+// @Description Add the recommendation result (candidates) as a new version of the migration project.
+// @Description
+// @Description [Note] The result of POST /recommendation/infra is added automatically with `projectId`.
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID" default(mig-project01)
+// @Param request body AddProjectRecommendationRequest true "Recommendation result"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 201 {object} model.ApiResponse[project.RecommendationVersion] "The added recommendation version"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Project not found"
+// @Router /project/{projectId}/recommendation [post]
+func AddProjectRecommendation(c echo.Context) error {
+	req := new(AddProjectRecommendationRequest)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	rec := project.RecommendationVersion{
+		ReqId:       c.Request().Header.Get(echo.HeaderXRequestID),
+		Candidates:  req.Candidates,
+		Description: req.Description,
+	}
+	if len(req.Candidates) > 0 {
+		rec.Csp = req.Candidates[0].TargetCloud.Csp
+		rec.Region = req.Candidates[0].TargetCloud.Region
+	}
+
+	added, err := project.AddRecommendation(c.Param("projectId"), rec)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to add the recommendation to the project")
+		return c.JSON(projectErrorStatus(err), model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusCreated, model.SuccessResponse(added))
+}
+
+// SelectProjectCandidate godoc
+// @ID SelectProjectCandidate
+// @Summary Select a candidate in a migration project
+// @Description Select a candidate of a recommendation version of the migration project. The previous approval, if any, is invalidated.
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID" default(mig-project01)
+// @Param request body project.SelectCandidateReq true "Recommendation version and candidate index"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[project.Project] "The updated project"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Project, version or candidate not found"
+// @Router /project/{projectId}/selection [put]
+func SelectProjectCandidate(c echo.Context) error {
+	req := new(project.SelectCandidateReq)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	updated, err := project.SelectCandidate(c.Param("projectId"), *req)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to select the candidate")
+		return c.JSON(projectErrorStatus(err), model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, model.SuccessResponse(updated))
+}
+
+// ApproveProject godoc
+// @ID ApproveProject
+// @Summary Approve the selected candidate of a migration project
+// @Description Approve the selected candidate of the migration project, so it can be migrated via POST /migration/ns/{nsId}/infra?projectId={projectId} without the request body.
+// @Tags [Migration] Project
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID" default(mig-project01)
+// @Param request body project.ApprovalReq true "Approval"
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {object} model.ApiResponse[project.Project] "The approved project"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Project not found"
+// @Failure 409 {object} model.ApiResponse[any] "No candidate selected"
+// @Router /project/{projectId}/approval [post]
+func ApproveProject(c echo.Context) error {
+	req := new(project.ApprovalReq)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	approved, err := project.Approve(c.Param("projectId"), *req)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to approve the project")
+		return c.JSON(projectErrorStatus(err), model.SimpleErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, model.SuccessResponse(approved))
+}
+
+// GetProjectReport godoc
+// @ID GetProjectReport
+// @Summary Get a migration report of a migration project
+// @Description Get the migration report generated in the migration project (via POST /report/migration/ns/{nsId}/infra/{infraId}?projectId={projectId}) in Markdown.
+// @Tags [Migration] Project
+// @Accept json
+// @Produce text/markdown
+// @Param projectId path string true "Project ID" default(mig-project01)
+// @Param reportId path int true "Report ID" default(1)
+// @Param X-Request-Id header string false "Unique request ID"
+// @Success 200 {string} string "Migration report in markdown"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 404 {object} model.ApiResponse[any] "Project or report not found"
+// @Router /project/{projectId}/report/{reportId} [get]
+func GetProjectReport(c echo.Context) error {
+	reportId, err := strconv.Atoi(c.Param("reportId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid reportId"))
+	}
+
+	found, err := project.GetReport(c.Param("projectId"), reportId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+	}
+	return c.Blob(http.StatusOK, "text/markdown; charset=utf-8", []byte(found.Markdown))
+}
+
+// projectErrorStatus maps the error of the project to the HTTP status.
+func projectErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "cannot be empty"):
+		return http.StatusBadRequest
+	case strings.Contains(err.Error(), "does not exist"):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "cannot be") || strings.Contains(err.Error(), "already exists"):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
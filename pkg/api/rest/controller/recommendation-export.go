@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/cloud-barista/cm-beetle/pkg/core/iac"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Infrastructure-as-Code Export API
+// ============================================================================
+
+// ExportTerraformRequest is the recommended infrastructure to export (e.g., a candidate of POST /recommendation/infra).
+type ExportTerraformRequest struct {
+	cloudmodel.RecommendedInfra
+}
+
+// ExportInfraAsTerraform godoc
+// @ID ExportInfraAsTerraform
+// @Summary Export a recommended infrastructure as a Terraform module
+// @Description Render the recommended infrastructure into a self-contained Terraform / OpenTofu module of the target CSP
+// @Description and download it as an archive (`<infraName>-<csp>/` with versions.tf, variables.tf, main.tf, outputs.tf, terraform.tfvars.example and README.md).
+// @Description
+// @Description **Response Format by 'format' Parameter:**
+// @Description - `format=zip` (default): Returns the module as a zip archive (Content-Type: application/zip)
+// @Description - `format=tar.gz`: Returns the module as a gzipped tarball (Content-Type: application/gzip)
+// @Description - `format=json`: Returns ApiResponse[TerraformModule] with the content of each file (for preview)
+// @Description
+// @Description [Note]
+// @Description * Supported CSPs: aws, azure, gcp
+// @Description * The module provisions the vNets, subnets, security groups, SSH key, node groups (with `count`) and NLBs
+// @Description * Secrets are module variables (e.g., `ssh_public_key`, `admin_password`, `subscription_id`); `targetSshKey.privateKey` is never exported
+// @Description * The public and private IPs of the nodes and the addresses of the NLBs are module outputs
+// @Description * What is not rendered (e.g., ALBs, network ACLs, IPv6) is listed in the Notes section of README.md
+// @Tags [Recommendation] Infrastructure
+// @Accept json
+// @Produce json
+// @Produce application/zip
+// @Produce application/gzip
+// @Param ExportTerraformRequest body ExportTerraformRequest true "Recommended infrastructure to export"
+// @Param format query string false "Archive format: zip, tar.gz, or json" Enums(zip,tar.gz,json) default(zip)
+// @Param nameSeed query string false "Optional prefix for all resource names (same as the migration API)"
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
+// @Success 200 {object} model.ApiResponse[iac.TerraformModule] "The Terraform module (an archive unless format=json)"
+// @Header 200 {string} Content-Disposition "attachment; filename="<infraName>-<csp>.zip" or "<infraName>-<csp>.tar.gz""
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters or unsupported recommended infrastructure"
+// @Failure 500 {object} model.ApiResponse[any] "Internal server error during archiving"
+// @Router /recommendation/infra/export [post]
+func ExportInfraAsTerraform(c echo.Context) error {
+
+	// [Input]
+	format := c.QueryParam("format")
+	if format == "" {
+		format = iac.ArchiveZip // default format
+	}
+	if format != "json" && !iac.IsValidArchiveFormat(format) {
+		log.Warn().Msgf("Invalid format: %s", format)
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Format must be 'zip', 'tar.gz', or 'json'"))
+	}
+
+	nameSeed := c.QueryParam("nameSeed")
+	if ok, detail := common.IsValidNameSeed(nameSeed); !ok {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid nameSeed: "+detail))
+	}
+
+	req := new(ExportTerraformRequest)
+	if err := c.Bind(req); err != nil {
+		log.Warn().Err(err).Msg("failed to bind a request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	// [Process]
+	infraToExport := common.ApplyNameSeed(req.RecommendedInfra, nameSeed)
+
+	module, err := iac.ExportTerraform(infraToExport)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to export the recommended infrastructure as a Terraform module")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(err.Error()))
+	}
+
+	// [Output]
+	if format == "json" {
+		return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(module,
+			fmt.Sprintf("Exported the Terraform module '%s' (%d file(s))", module.Name, len(module.Files))))
+	}
+
+	var buf bytes.Buffer
+	if err := module.WriteArchive(&buf, format); err != nil {
+		log.Error().Err(err).Msg("failed to archive the Terraform module")
+		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse("Archiving failed"))
+	}
+
+	contentType := "application/zip"
+	if format == iac.ArchiveTarGz {
+		contentType = "application/gzip"
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\""+module.ArchiveFileName(format)+"\"")
+	return c.Blob(http.StatusOK, contentType, buf.Bytes())
+}
@@ -3,10 +3,12 @@ package controller
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	storagemodel "github.com/cloud-barista/cm-beetle/imdl/storage-model"
 	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
-	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/core/recommendation"
+	"github.com/cloud-barista/cm-beetle/transx"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -21,6 +23,22 @@ type RecommendObjectStorageRequest struct {
 	SourceObjectStorages []storagemodel.SourceObjectStorage `json:"sourceObjectStorages" validate:"required,min=1"`
 }
 
+// ScanObjectStorageRequest represents a request to scan source object storages
+type ScanObjectStorageRequest struct {
+	DesiredCloud storagemodel.CloudProperty `json:"desiredCloud,omitempty"` // Copied to the response for the recommendation API (optional)
+	Sources      []ObjectStorageScanSource  `json:"sources" validate:"required,min=1"`
+}
+
+// ObjectStorageScanSource is a source bucket to scan and its features known by the user.
+type ObjectStorageScanSource struct {
+	// Location of the source bucket (storageType: objectstorage; path: "bucket-name" or "bucket-name/prefix")
+	Location transx.DataLocation `json:"location" validate:"required"`
+
+	// Features and tags of the source bucket (not observable by the scanner)
+	storagemodel.BucketFeatureProperty
+	storagemodel.BucketMetaProperty
+}
+
 // RecommendObjectStorage godoc
 // @ID RecommendObjectStorage
 // @Summary Recommend an object storage for cloud migration
@@ -31,11 +49,15 @@ type RecommendObjectStorageRequest struct {
 // @Description
 // @Description - If desiredCsp and desiredRegion are set on request body, the values in the query parameter will be ignored.
 // @Description
-// @Description [Note] The recommended bucket name uses a default pattern (`mig-bucket-01`, `mig-bucket-02`, ...).
+// @Description [Note] The recommended bucket name is derived from the source bucket name with a random suffix (e.g., `my-data-3f9a1c`).
 // @Description - Bucket names must be globally unique across all accounts in the target cloud provider.
+// @Description - The names comply with the bucket naming rules of the target CSP, leaving room for a `nameSeed` prefix.
 // @Description - CB-Tumblebug internally generates a uid and uses it as the actual bucket name in the cloud.
 // @Description - The `bucketName` field in the recommendation result represents the intended name, not the final cloud resource name.
 // @Description
+// @Description [Note] Server-side encryption, public access, tags and access frequency of the source buckets are carried
+// @Description as `encryptionEnabled`, `publicAccessBlocked`, `tags` and `storageClass` (CSP storage class derived from `accessFrequency`).
+// @Description
 // @Description [Note] To apply a naming prefix, use the `nameSeed` query parameter on the migration API (`POST /migration/.../objectStorage?nameSeed=xxx`).
 // @Description
 // @Tags [Recommendation] Managed Object Storage
@@ -90,96 +112,94 @@ func RecommendObjectStorage(c echo.Context) error {
 		Msg("Processing object storage recommendation request")
 
 	// [Process]
-	// Fetch CSP feature support from Tumblebug
-	supportResp, err := tbclient.NewSession().GetObjectStorageSupport(desiredCsp)
+	objectStorageInfo, err := recommendation.RecommendObjectStorage(desiredCsp, desiredRegion, req.SourceObjectStorages)
 	if err != nil {
-		log.Warn().Err(err).Str("csp", desiredCsp).Msg("Failed to fetch CSP object storage support info; proceeding without support check")
+		log.Error().Err(err).Msg("Failed to recommend object storage")
+		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse(err.Error()))
 	}
+	targetObjectStorages := objectStorageInfo.TargetObjectStorages
 
-	support, hasSupportInfo := supportResp.Supports[desiredCsp]
-	if !hasSupportInfo {
-		log.Warn().Str("csp", desiredCsp).Msg("No support info found for CSP; all features assumed supported")
-	}
-
-	corsSupported := !hasSupportInfo || support.Cors
-	versioningSupported := !hasSupportInfo || support.Versioning
+	log.Info().
+		Str("desiredCsp", desiredCsp).
+		Str("region", desiredRegion).
+		Int("targetBuckets", len(targetObjectStorages)).
+		Msg("Object storage recommendation completed successfully")
 
-	log.Debug().
-		Str("csp", desiredCsp).
-		Bool("corsSupported", corsSupported).
-		Bool("versioningSupported", versioningSupported).
-		Msg("CSP object storage feature support")
+	// [Output]
+	successMsg := fmt.Sprintf("Recommended %d object storage(s) for %s %s",
+		len(targetObjectStorages), desiredCsp, desiredRegion)
+	res := model.SuccessResponseWithMessage(objectStorageInfo, successMsg)
 
-	var warnings []string
-	targetObjectStorages := make([]storagemodel.TargetObjectStorage, 0, len(req.SourceObjectStorages))
+	return c.JSON(http.StatusOK, res)
+}
 
-	for i, source := range req.SourceObjectStorages {
-		targetBucketName := fmt.Sprintf("os-%02d", i+1)
+// ScanSourceObjectStorages godoc
+// @ID ScanSourceObjectStorages
+// @Summary Scan source object storages for the object storage recommendation
+// @Description Scan the objects of source buckets and build the source object storage models
+// @Description
+// @Description [Note] The response can be used as the request body of the object storage recommendation API (`POST /recommendation/middleware/objectStorage`).
+// @Description - `totalSizeBytes`, `objectCount` and `accessFrequency` are derived from the listed objects.
+// @Description - `inventory` holds the size histogram, the prefix tree and the last-modified distribution.
+// @Description - `accessFrequency` is inferred from the last-modified times only (see `inventory.accessFrequencyBasis`).
+// @Description
+// @Description [Note] The features (versioning, CORS, encryption, public access) and tags are not observable by the scanner.
+// @Description - Set them on each source in the request body; they are copied to the response as-is.
+// @Description
+// @Description [Note] Source credentials (`location.objectStorage`) support the `minio`, `spider` and `tumblebug` access types.
+// @Tags [Recommendation] Managed Object Storage
+// @Accept json
+// @Produce	json
+// @Param request body ScanObjectStorageRequest true "Specify the source buckets to scan"
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
+// @Success 200 {object} model.ApiResponse[RecommendObjectStorageRequest] "Successfully scanned source object storages"
+// @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
+// @Failure 500 {object} model.ApiResponse[any] "Internal server error during scan"
+// @Router /recommendation/middleware/objectStorage/scan [post]
+func ScanSourceObjectStorages(c echo.Context) error {
 
-		versioningEnabled := source.VersioningEnabled
-		corsEnabled := source.CORSEnabled
-		corsRule := source.CORSRule
+	// [Input]
+	var req ScanObjectStorageRequest
+	if err := c.Bind(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to bind request")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
 
-		// Adjust based on CSP support and emit warnings
-		if source.VersioningEnabled && !versioningSupported {
-			versioningEnabled = false
-			msg := fmt.Sprintf("Bucket '%s': versioning disabled (not supported on %s)", source.BucketName, desiredCsp)
-			warnings = append(warnings, msg)
-			log.Info().Msg(msg)
-		}
-		if source.CORSEnabled && !corsSupported {
-			corsEnabled = false
-			corsRule = nil
-			msg := fmt.Sprintf("Bucket '%s': CORS disabled (not supported on %s)", source.BucketName, desiredCsp)
-			warnings = append(warnings, msg)
-			log.Info().Msg(msg)
-		}
+	if len(req.Sources) == 0 {
+		log.Warn().Msg("At least one source bucket must be provided")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("At least one source bucket required"))
+	}
 
-		target := storagemodel.TargetObjectStorage{
-			SourceBucketName: source.BucketName,
-			BucketName:       targetBucketName,
-			BucketSpecProperty: storagemodel.BucketSpecProperty{
-				VersioningEnabled: versioningEnabled,
-				CORSEnabled:       corsEnabled,
-				CORSRule:          corsRule,
-			},
+	for i, source := range req.Sources {
+		if source.Location.StorageType != transx.StorageTypeObjectStorage {
+			msg := fmt.Sprintf("sources[%d]: storageType must be '%s'", i, transx.StorageTypeObjectStorage)
+			return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(msg))
 		}
-
-		targetObjectStorages = append(targetObjectStorages, target)
-
-		log.Debug().
-			Str("sourceBucket", source.BucketName).
-			Str("targetBucket", targetBucketName).
-			Bool("versioning", versioningEnabled).
-			Bool("cors", corsEnabled).
-			Msg("Generated target object storage recommendation")
 	}
 
-	// Determine overall status
-	status := "success"
-	if len(warnings) > 0 {
-		status = "partial"
-	}
+	// [Process]
+	sourceObjectStorages := make([]storagemodel.SourceObjectStorage, 0, len(req.Sources))
+	for _, source := range req.Sources {
+		scanned, err := recommendation.ScanSourceObjectStorage(source.Location)
+		if err != nil {
+			log.Error().Err(err).Str("path", source.Location.Path).Msg("Failed to scan source object storage")
+			if strings.Contains(err.Error(), "invalid source location") {
+				return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(err.Error()))
+			}
+			return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse(err.Error()))
+		}
+		scanned.BucketFeatureProperty = source.BucketFeatureProperty
+		scanned.BucketMetaProperty = source.BucketMetaProperty
 
-	// Build response
-	objectStorageInfo := storagemodel.RecommendedObjectStorage{
-		Status:               status,
-		Description:          fmt.Sprintf("Successfully recommended %d object storage configuration(s)", len(targetObjectStorages)),
-		Warnings:             warnings,
-		TargetCloud:          storagemodel.CloudProperty{Csp: desiredCsp, Region: desiredRegion},
-		TargetObjectStorages: targetObjectStorages,
+		sourceObjectStorages = append(sourceObjectStorages, scanned)
 	}
 
-	log.Info().
-		Str("desiredCsp", desiredCsp).
-		Str("region", desiredRegion).
-		Int("targetBuckets", len(targetObjectStorages)).
-		Msg("Object storage recommendation completed successfully")
-
 	// [Output]
-	successMsg := fmt.Sprintf("Recommended %d object storage(s) for %s %s",
-		len(targetObjectStorages), desiredCsp, desiredRegion)
-	res := model.SuccessResponseWithMessage(objectStorageInfo, successMsg)
+	res := RecommendObjectStorageRequest{
+		DesiredCloud:         req.DesiredCloud,
+		SourceObjectStorages: sourceObjectStorages,
+	}
+	successMsg := fmt.Sprintf("Scanned %d source object storage(s)", len(sourceObjectStorages))
 
-	return c.JSON(http.StatusOK, res)
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMessage(res, successMsg))
 }
@@ -31,6 +31,9 @@ import (
 // @Summary Recommend an appropriate virtual network for cloud migration
 // @Description Recommend an appropriate virtual network for cloud migration
 // @Description
+// @Description [Note] `cidrPlan` in the request body plans the CIDR blocks of the vNets (mode: keep | pool), and the plan is returned in `cidrPlan`.
+// @Description [Note] The vNets are planned as dual-stack if the source nodes have IPv6 addresses; IPv6 warnings are returned in `cidrPlan.ipv6`.
+// @Description
 // @Description [Note] `desiredProvider` and `desiredRegion` are required.
 // @Description - `desiredProvider` and `desiredRegion` can set on the query parameter or the request body.
 // @Description
@@ -70,7 +73,7 @@ func RecommendVNet(c echo.Context) error {
 	}
 
 	// [Process]
-	ret, err := recommendation.RecommendVNet(desiredProvider, desiredRegion, req.OnpremiseInfraModel)
+	ret, cidrPlan, err := recommendation.PlanVNets(desiredProvider, desiredRegion, req.OnpremiseInfraModel, req.CidrPlan)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to recommend vNet")
 		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse("VNet recommendation failed"))
@@ -90,6 +93,7 @@ func RecommendVNet(c echo.Context) error {
 		})
 	}
 	RecommendVNetList.TargetVNetList = tempList
+	RecommendVNetList.CidrPlan = cidrPlan
 
 	successMsg := fmt.Sprintf("Recommended %d vNet(s) for %s %s", len(ret), desiredProvider, desiredRegion)
 	res := model.SuccessResponseWithMessage(RecommendVNetList, successMsg)
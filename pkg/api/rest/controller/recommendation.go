@@ -28,7 +28,9 @@ import (
 	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
 
 	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/project"
 	"github.com/cloud-barista/cm-beetle/pkg/core/recommendation"
+	"github.com/cloud-barista/cm-beetle/pkg/nlbparser"
 	"github.com/labstack/echo/v4"
 
 	"github.com/rs/zerolog/log"
@@ -60,6 +62,10 @@ type RecommendInfraWithDefaultsResponse struct {
 // @Description **[Response Field: `nodeGroups[].cspImageName`]** Set only when the spec-image review resolved a newer image than the DB cache.
 // @Description - **Non-empty**: TumbleBug sends this to Spider directly, bypassing the per-VM image DB lookup (prevents stale image failures, e.g., Alibaba alibase images).
 // @Description - **Empty**: TumbleBug uses `imageId` for the standard DB lookup path.
+// @Description
+// @Description **[Response Field: `firewallPolicy`, `targetNetworkAclList`]** The ordered source firewall rules (incl. deny rules) are compiled into allow-only security group rules.
+// @Description - Deny rules that fragment the security group rules are recommended as network ACL rules (`targetNetworkAclList`, not provisioned by CB-Tumblebug).
+// @Description - Deny rules enforceable in neither way are listed in `firewallPolicy.unenforcedRules`.
 // @Tags [Recommendation] Infrastructure
 // @Accept  json
 // @Produce  json
@@ -125,6 +131,9 @@ func RecommendVMInfraWithDefaults(c echo.Context) error {
 type RecommendInfraRequest struct {
 	DesiredCspAndRegionPair cloudmodel.CloudProperty `json:"desiredCspAndRegionPair"`
 	OnpremiseInfraModel     onpremmodel.OnpremInfra
+
+	// CidrPlan specifies how the CIDR blocks of the target vNets are planned (default: keep the source addressing).
+	CidrPlan cloudmodel.CidrPlanReq `json:"cidrPlan,omitempty"`
 }
 
 type RecommendInfraResponse struct {
@@ -145,6 +154,9 @@ type RecommendInfraResponse struct {
 // @Description
 // @Description **[Optional Parameters: `minMatchRate`]** Minimum match rate threshold for highly-matched classification (default: 90.0, range: 0-100)
 // @Description
+// @Description **[Optional Parameters: `explain`]** If true, attach a decision trace (`trace`) to each candidate (default: false)
+// @Description - Per source node: deployment-plan filters, numbers of specs/images considered, pairs rejected by compatibility checks with reasons, similarity scores, and ranking
+// @Description
 // @Description **[Response Field: `status`]** Candidate status based on the match rate threshold
 // @Description - **highly-matched**: Candidates meet or exceed the match rate threshold
 // @Description - **partially-matched**: Valid candidates below the match rate threshold
@@ -152,6 +164,12 @@ type RecommendInfraResponse struct {
 // @Description **[Response Field: `description`]** Summary containing Candidate ID, status, match rate statistics (Min/Max/Avg), and VM counts
 // @Description - Example: "Candidate #1 | partially-matched | Overall Match Rate: Min=88.9% Max=100.0% Avg=98.7% | VMs: 3 total, 2 matched, 1 acceptable"
 // @Description
+// @Description **[Optional Request Field: `cidrPlan`]** How the CIDR blocks of the target vNets are planned
+// @Description - **mode=keep** (default): Keep the source addressing
+// @Description - **mode=pool**: Allocate non-overlapping blocks from `cidrPlan.pool` for hybrid connectivity (e.g., VPN) during a phased migration
+// @Description - The vNets are validated against `cidrPlan.onpremRanges` and the existing vNets in `cidrPlan.nsId`; overlaps are reported in `cidrPlan.conflicts` of the response
+// @Description - One vNet is planned per private network spanned by the source networks (`targetVNet` and `additionalVNetList`)
+// @Description
 // @Description **[Response Field: `nodeGroups[].cspImageName`]** Set only when the spec-image review resolved a newer image than the DB cache.
 // @Description - **Non-empty**: TumbleBug sends this to Spider directly, bypassing the per-VM image DB lookup (prevents stale image failures, e.g., Alibaba alibase images).
 // @Description - **Empty**: TumbleBug uses `imageId` for the standard DB lookup path.
@@ -165,6 +183,8 @@ type RecommendInfraResponse struct {
 // @Param desiredRegion query string false "Region (e.g., ap-northeast-2)" default(ap-northeast-2)
 // @Param limit query int false "Limit (default: 3) the number of recommended infrastructures"
 // @Param minMatchRate query number false "Minimum match rate for highly-matched classification (default: 90.0, range: 0-100)"
+// @Param explain query bool false "Attach a decision trace to each candidate (default: false)"
+// @Param projectId query string false "Migration project ID: the source infrastructure of the project is used if none is posted, and the candidates are added as a new recommendation version"
 // @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
 // @Success 200 {object} model.ApiResponse[[]cloudmodel.RecommendedInfra] "Successfully recommended infrastructure candidates"
 // @Failure 400 {object} model.ApiResponse[any] "Invalid request parameters"
@@ -195,6 +215,9 @@ func RecommendVmInfraCandidates(c echo.Context) error {
 		}
 	}
 
+	// Parse explain parameter (default: false)
+	explain, _ := strconv.ParseBool(c.QueryParam("explain"))
+
 	reqt := &RecommendInfraRequest{}
 	if err := c.Bind(reqt); err != nil {
 		log.Warn().Err(err).Msg("failed to bind a request body")
@@ -221,6 +244,16 @@ func RecommendVmInfraCandidates(c echo.Context) error {
 	}
 	sourceInfra := reqt.OnpremiseInfraModel
 
+	// Use the source infrastructure of the migration project if none is posted
+	projectId := c.QueryParam("projectId")
+	if projectId != "" && len(sourceInfra.Nodes) == 0 {
+		sourceInfra, err = project.GetSourceInfra(projectId)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to get the source infrastructure of the project")
+			return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+		}
+	}
+
 	ok, err := recommendation.IsValidCspAndRegion(csp, region)
 	if !ok {
 		log.Error().Err(err).Msg("failed to validate CSP and region")
@@ -228,12 +261,25 @@ func RecommendVmInfraCandidates(c echo.Context) error {
 	}
 
 	// [Process]
-	recommendedInfraCandidates, err := recommendation.RecommendVmInfraCandidates(csp, region, sourceInfra, limit, minMatchRate)
+	recommendedInfraCandidates, err := recommendation.RecommendVmInfraCandidates(csp, region, sourceInfra, limit, minMatchRate, explain, reqt.CidrPlan)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to recommend multiple candidates of appropriate multi-cloud infrastructure (MCI) for cloud migration")
 		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse("Recommendation failed"))
 	}
 
+	// Add the candidates to the migration project as a new recommendation version
+	if projectId != "" {
+		_, err := project.AddRecommendation(projectId, project.RecommendationVersion{
+			Csp:        csp,
+			Region:     region,
+			ReqId:      c.Request().Header.Get(echo.HeaderXRequestID),
+			Candidates: recommendedInfraCandidates,
+		})
+		if err != nil {
+			log.Warn().Err(err).Msgf("failed to add the recommendation to the project (projectId: %s)", projectId)
+		}
+	}
+
 	// [Output]
 	// Returns base names only. NameSeed is applied at migration time via query param on the migration API.
 	return c.JSON(http.StatusOK, model.SuccessListResponse(recommendedInfraCandidates))
@@ -248,6 +294,13 @@ type RecommendInfraWithNlbRequest struct {
 	DesiredCsp    string                  `json:"desiredCsp"`    // Target CSP (e.g., "aws")
 	DesiredRegion string                  `json:"desiredRegion"` // Target region (e.g., "ap-northeast-2")
 	SourceInfra   onpremmodel.OnpremInfra `json:"sourceInfra"   validate:"required"`
+
+	// NlbConfigs are raw load balancer configurations (e.g., haproxy.cfg, nginx.conf).
+	// They are parsed into NLBs and appended to sourceInfra.nlbs.
+	NlbConfigs []nlbparser.NlbConfig `json:"nlbConfigs,omitempty"`
+
+	// CidrPlan specifies how the CIDR blocks of the target vNets are planned (default: keep the source addressing).
+	CidrPlan cloudmodel.CidrPlanReq `json:"cidrPlan,omitempty"`
 }
 
 // RecommendInfraWithNlbCandidates godoc
@@ -262,8 +315,11 @@ type RecommendInfraWithNlbRequest struct {
 // @Description 4. Finds ranked compatible spec-image pairs per NodeGroup (representative node for NLB groups)
 // @Description 5. Generates up to `limit` candidates — candidate i uses the i-th ranked pair per NodeGroup
 // @Description 6. Maps source NLB configuration to target cloud NLB model (same for all candidates)
+// @Description 7. Maps HTTP-mode frontends to a target L7 ALB model (`targetAlbList`; host/path rules, TLS certificate placeholders, HTTP health check paths)
+// @Description    - `targetAlbList[].l7Support` is `l4-only` when the target CSP has no managed L7 load balancer
 // @Description
-// @Description [Note] `sourceInfra.nlbs` must be populated (HAProxy frontend-backend pairs from cm-honeybee).
+// @Description [Note] `sourceInfra.nlbs` or `nlbConfigs` must be populated (HAProxy frontend-backend pairs from cm-honeybee).
+// @Description Raw HAProxy (`haproxy.cfg`) and nginx (`stream`/`http` upstream) configurations in `nlbConfigs` are parsed and appended to `sourceInfra.nlbs`.
 // @Description
 // @Description [Note] The returned `targetInfra.nodeGroups[].name` values are referenced by `targetNlbList[].targetGroup.nodeGroupId`.
 // @Description Use the same NodeGroup IDs when calling POST /migration/infra so that the NLB migration can reference them immediately.
@@ -318,9 +374,17 @@ func RecommendInfraWithNlbCandidates(c echo.Context) error {
 	if len(req.SourceInfra.Nodes) == 0 {
 		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("sourceInfra.nodes is required"))
 	}
+	if len(req.NlbConfigs) > 0 {
+		parsedNlbs, err := nlbparser.ParseAll(req.NlbConfigs)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to parse nlbConfigs")
+			return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(err.Error()))
+		}
+		req.SourceInfra.NLBs = append(req.SourceInfra.NLBs, parsedNlbs...)
+	}
 	if len(req.SourceInfra.NLBs) == 0 {
 		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse(
-			"sourceInfra.nlbs is required for infraWithNlb; use /recommendation/infra for NLB-free recommendation"))
+			"sourceInfra.nlbs or nlbConfigs is required for infraWithNlb; use /recommendation/infra for NLB-free recommendation"))
 	}
 
 	log.Info().
@@ -334,7 +398,7 @@ func RecommendInfraWithNlbCandidates(c echo.Context) error {
 
 	// [Process]
 	candidates, err := recommendation.RecommendInfraWithNlbCandidates(
-		req.DesiredCsp, req.DesiredRegion, req.SourceInfra, limit, minMatchRate,
+		req.DesiredCsp, req.DesiredRegion, req.SourceInfra, limit, minMatchRate, req.CidrPlan,
 	)
 	if err != nil {
 		log.Error().Err(err).Msg("infraWithNlb recommendation failed")
@@ -369,6 +433,114 @@ type RecommendK8sClusterResponse struct {
 	tbmodel.K8sClusterDynamicReq
 }
 
+// RecommendK8sCluster godoc
+// @ID RecommendK8sCluster
+// @Summary Recommend K8s cluster with node groups
+// @Description Get recommendation for a K8s cluster and its node groups based on honeybee source cluster data
+// @Description
+// @Description - Specs are selected through the same spec search used for VMs, using the average CPU/memory per node type
+// @Description - If the source nodes report no CPU/memory, the default size (2 vCPU, 4 GiB) is used, and the status is `partial` with a warning in the description
+// @Description - The source cluster version is mapped to the nearest managed K8s version supported in the region
+// @Description - Node counts and autoscaling bounds are derived from the observed node counts
+// @Description
+// @Description `targetCluster` can be directly used with cb-tumblebug k8sClusterDynamic API,
+// @Description and each of `targetNodeGroups` with cb-tumblebug k8sNodeGroup API.
+// @Tags [Recommendation] K8s Cluster (prototype)
+// @Accept  json
+// @Produce  json
+// @Param UserK8sInfra body recommendation.KubernetesInfoList true "Source cluster information from honeybee"
+// @Param desiredProvider query string true "Provider (e.g., aws)" Enums(aws,azure,gcp,alibaba,ncp)
+// @Param desiredRegion query string true "Region (e.g., ap-northeast-2)" default(ap-northeast-2)
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
+// @Success 200 {object} model.ApiResponse[recommendation.RecommendedK8sCluster] "K8s cluster recommendation (ready for cb-tumblebug API)"
+// @Failure 400 {object} model.ApiResponse[any]
+// @Failure 500 {object} model.ApiResponse[any]
+// @Router /recommendation/k8sCluster [post]
+func RecommendK8sCluster(c echo.Context) error {
+	desiredProvider := c.QueryParam("desiredProvider")
+	desiredRegion := c.QueryParam("desiredRegion")
+
+	if desiredProvider == "" || desiredRegion == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("'desiredProvider' and 'desiredRegion' query parameters are required"))
+	}
+
+	reqt := &recommendation.KubernetesInfoList{}
+	if err := c.Bind(reqt); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	if len(reqt.Servers) == 0 {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("At least one cluster required"))
+	}
+
+	ok, err := recommendation.IsValidCspAndRegion(desiredProvider, desiredRegion)
+	if !ok {
+		log.Error().Err(err).Msg("invalid provider or region")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid provider or region"))
+	}
+
+	result, err := recommendation.RecommendK8sCluster(desiredProvider, desiredRegion, *reqt)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to recommend K8s cluster")
+		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse("K8s cluster recommendation failed"))
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(result))
+}
+
+type RecommendK8sWorkloadsRequest struct {
+	recommendation.KubernetesInfoList
+	Rules recommendation.WorkloadTranslationRules `json:"rules"`
+}
+
+// RecommendK8sWorkloads godoc
+// @ID RecommendK8sWorkloads
+// @Summary Recommend K8s workload migration plan
+// @Description Get a migration plan of workloads and Helm releases for the target K8s cluster based on honeybee source cluster data
+// @Description
+// @Description - Workloads are rewritten for the target cluster (StorageClass, ingress class, LoadBalancer annotations, image registries)
+// @Description - Resources managed by Helm are migrated through the Helm install plan
+// @Description - Resources that cannot be migrated automatically (e.g., hostPath volumes, node selectors) are reported as issues
+// @Description - Secret values are not included; they are replaced with placeholders to be set in the target cluster
+// @Description
+// @Description Empty fields of `rules` are filled with the defaults of the desired provider.
+// @Tags [Recommendation] K8s Cluster (prototype)
+// @Accept  json
+// @Produce  json
+// @Param UserK8sWorkloads body RecommendK8sWorkloadsRequest true "Source cluster information from honeybee and translation rules"
+// @Param desiredProvider query string true "Provider (e.g., aws)" Enums(aws,azure,gcp,alibaba,ncp)
+// @Param X-Request-Id header string false "Unique request ID (auto-generated if not provided). Used for tracking request status and correlating logs."
+// @Success 200 {object} model.ApiResponse[recommendation.WorkloadMigrationPlan] "Workload migration plan"
+// @Failure 400 {object} model.ApiResponse[any]
+// @Failure 500 {object} model.ApiResponse[any]
+// @Router /recommendation/k8sWorkloads [post]
+func RecommendK8sWorkloads(c echo.Context) error {
+	desiredProvider := c.QueryParam("desiredProvider")
+
+	if desiredProvider == "" {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("'desiredProvider' query parameter is required"))
+	}
+
+	reqt := &RecommendK8sWorkloadsRequest{}
+	if err := c.Bind(reqt); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
+	}
+
+	if len(reqt.Servers) == 0 {
+		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("At least one cluster required"))
+	}
+
+	result, err := recommendation.RecommendK8sWorkloadMigration(desiredProvider, reqt.KubernetesInfoList, reqt.Rules)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to recommend K8s workload migration")
+		return c.JSON(http.StatusInternalServerError, model.SimpleErrorResponse("K8s workload migration recommendation failed"))
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(result))
+}
+
 // RecommendK8sControlPlane godoc
 // @ID RecommendK8sControlPlane
 // @Summary Recommend K8s control plane configuration
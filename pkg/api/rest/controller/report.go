@@ -21,6 +21,7 @@ import (
 
 	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
 	"github.com/cloud-barista/cm-beetle/pkg/api/rest/model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/project"
 	"github.com/cloud-barista/cm-beetle/pkg/core/report"
 	"github.com/cloud-barista/cm-beetle/pkg/core/summary"
 	"github.com/labstack/echo/v4"
@@ -44,7 +45,8 @@ type GenerateMigrationReportRequest struct {
 // @Param infraId path string true "Infra ID" example("infra101") default(infra101)
 // @Param format query string false "Report format: md or html" Enums(md,html) default(md)
 // @Param download query string false "Download as file: true for file download, false for inline display (only affects browsers/Swagger UI, not curl)" Enums(true,false) default(false)
-// @Param onpremiseInfraModel body controller.GenerateMigrationReportRequest true "Source infrastructure data from on-premise"
+// @Param projectId query string false "Migration project ID: the source infrastructure of the project is used if none is posted, and the report is recorded in the project"
+// @Param onpremiseInfraModel body controller.GenerateMigrationReportRequest false "Source infrastructure data from on-premise (optional with projectId)"
 // @Success 200 {string} string "Migration report in markdown or HTML format"
 // @Header 200 {string} Content-Disposition "inline; filename="migration-report.md" or "migration-report.html" (or attachment when download=true)"
 // @Header 200 {string} Content-Type "text/markdown; charset=utf-8 or text/html; charset=utf-8"
@@ -91,6 +93,17 @@ func GenerateMigrationReport(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, model.SimpleErrorResponse("Invalid request format"))
 	}
 
+	// Use the source infrastructure of the migration project if none is posted
+	projectId := c.QueryParam("projectId")
+	if projectId != "" && len(req.OnpremiseInfraModel.Nodes) == 0 {
+		sourceInfra, err := project.GetSourceInfra(projectId)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to get the source infrastructure of the project")
+			return c.JSON(http.StatusNotFound, model.SimpleErrorResponse(err.Error()))
+		}
+		req.OnpremiseInfraModel = sourceInfra
+	}
+
 	// Validate source infrastructure
 	if len(req.OnpremiseInfraModel.Nodes) == 0 {
 		log.Warn().Msg("Source infrastructure must contain at least one node")
@@ -122,6 +135,14 @@ func GenerateMigrationReport(c echo.Context) error {
 	// Generate markdown report
 	markdownReport := report.GenerateMigrationReportMarkdown(migrationReport)
 
+	// Record the report in the migration project, if any
+	if projectId != "" {
+		_, err := project.RecordReport(projectId, project.ProjectReport{NsId: nsId, InfraId: infraId, Markdown: markdownReport})
+		if err != nil {
+			log.Warn().Err(err).Msgf("failed to record the report in the project (projectId: %s)", projectId)
+		}
+	}
+
 	var content []byte
 	var contentType string
 	var fileExtension string
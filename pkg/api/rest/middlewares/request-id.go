@@ -10,6 +10,9 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// RequestEventsPath is the route of the request event stream (SSE), which is not tracked as a request itself.
+const RequestEventsPath = "/beetle/request/:reqId/events"
+
 // RequestIdAndDetailsIssuer is a middleware that issues and tracks request IDs.
 //
 // This middleware runs BEFORE the Echo handler processes the request.
@@ -25,8 +28,8 @@ func RequestIdAndDetailsIssuer(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		// log.Debug().Msg("Start - Request ID middleware")
 
-		// Skip request tracking for API documentation paths
-		if strings.HasPrefix(c.Path(), "/beetle/api") {
+		// Skip request tracking for API documentation paths and request event streams
+		if strings.HasPrefix(c.Path(), "/beetle/api") || c.Path() == RequestEventsPath {
 			return next(c)
 		}
 
@@ -33,6 +33,10 @@ func ResponseBodyDump() echo.MiddlewareFunc {
 				strings.HasPrefix(c.Path(), "/beetle/swagger.json") {
 				return true
 			}
+			// Skip request event streams (not tracked, and must not be buffered)
+			if c.Path() == RequestEventsPath {
+				return true
+			}
 			return false
 		},
 		Handler: func(c echo.Context, reqBody, resBody []byte) {
@@ -56,13 +60,14 @@ func ResponseBodyDump() echo.MiddlewareFunc {
 			// log.Debug().Msgf("Request body: %s", string(reqBody))
 			// log.Debug().Msgf("Response body: %s", string(resBody))
 
-			// Load the request details by ID
-			details, ok := common.GetRequest(reqID)
-			if !ok {
+			// Check the request details by ID
+			if !common.HasRequest(reqID) {
 				log.Error().Msg("Request ID not found")
 				return
 			}
-			// log.Trace().Msg("OK, common.GetRequest(reqID)")
+
+			// Collect the result of the request, which is applied to the stored details at the end
+			var details common.RequestDetails
 			details.EndTime = time.Now()
 
 			details.Status = common.RequestStatusSuccess
@@ -144,7 +149,17 @@ func ResponseBodyDump() echo.MiddlewareFunc {
 			}
 
 			// Store details of the request (always executed for all content types)
-			if err := common.SetRequest(reqID, details); err != nil {
+			err := common.UpdateRequest(reqID, func(stored *common.RequestDetails) {
+				stored.EndTime = details.EndTime
+				stored.Status = details.Status
+				if details.ErrorResponse != "" {
+					stored.ErrorResponse = details.ErrorResponse
+				}
+				if details.ResponseData != nil {
+					stored.ResponseData = details.ResponseData
+				}
+			})
+			if err != nil {
 				log.Error().Err(err).Msg("Failed to store request details")
 			}
 			// log.Debug().Msg("End - BodyDump() middleware")
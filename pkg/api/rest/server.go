@@ -279,6 +279,7 @@ func RunServer(port string) {
 
 	// API Request Management APIs
 	gBeetle.GET("/request/:reqId", controller.RestGetRequest)
+	gBeetle.GET("/request/:reqId/events", controller.RestGetRequestEvents)
 	gBeetle.GET("/requests", controller.RestGetAllRequests)
 	gBeetle.DELETE("/request/:reqId", controller.RestDeleteRequest)
 	gBeetle.DELETE("/requests", controller.RestDeleteAllRequests)
@@ -301,6 +302,8 @@ func RunServer(port string) {
 	gNaming.POST("/preview", controller.PreviewInfra)
 
 	// Recommendation APIs for K8s Cluster (new endpoints)
+	gRecommendation.POST("/k8sCluster", controller.RecommendK8sCluster)
+	gRecommendation.POST("/k8sWorkloads", controller.RecommendK8sWorkloads)
 	gRecommendation.POST("/k8sControlPlane", controller.RecommendK8sControlPlane)
 	gRecommendation.POST("/k8sNodeGroup", controller.RecommendK8sNodeGroup)
 
@@ -315,6 +318,10 @@ func RunServer(port string) {
 	// Recommendation API for NLB-aware infrastructure (infraWithNlb)
 	gRecommendation.POST("/infraWithNlb", controller.RecommendInfraWithNlbCandidates)
 
+	// Export API for a recommended infrastructure (Terraform / OpenTofu module)
+	// Registered outside the group, since the export does not call CB-Tumblebug
+	gBeetle.POST("/recommendation/infra/export", controller.ExportInfraAsTerraform)
+
 	/*
 	 * API group for managed middleware recommendation
 	 */
@@ -322,6 +329,7 @@ func RunServer(port string) {
 
 	// Recommendation APIs for object storage
 	gRecommendationMiddleware.POST("/objectStorage", controller.RecommendObjectStorage)
+	gRecommendationMiddleware.POST("/objectStorage/scan", controller.ScanSourceObjectStorages)
 
 	/*
 	 * API group for computing infra migration
@@ -340,10 +348,30 @@ func RunServer(port string) {
 	// Migration APIs for VM infrastructure
 	gMigration.POST("/ns/:nsId/infraWithDefaults", controller.MigrateInfraWithDefaults)
 	gMigration.POST("/ns/:nsId/infra", controller.MigrateInfra)
+	gMigration.POST("/ns/:nsId/infra/plan", controller.PlanInfraMigration)
 	gMigration.GET("/ns/:nsId/infra", controller.ListInfra)
 	gMigration.GET("/ns/:nsId/infra/:infraId", controller.GetInfra)
 	gMigration.DELETE("/ns/:nsId/infra/:infraId", controller.DeleteInfra)
 
+	// Post-migration validation APIs (checks on the migrated VMs)
+	gMigration.POST("/ns/:nsId/infra/:infraId/validation", controller.ValidateMigratedInfra)
+	gMigration.GET("/ns/:nsId/infra/:infraId/validation", controller.GetMigratedInfraValidation)
+
+	// Drift detection API (applied model vs. live infrastructure)
+	gMigration.GET("/ns/:nsId/infra/:infraId/drift", controller.GetInfraDrift)
+
+	// Migration journal APIs (resources created by the infra migration and their rollback)
+	gMigration.GET("/ns/:nsId/journal", controller.ListMigrationJournals)
+	gMigration.GET("/ns/:nsId/journal/:journalId", controller.GetMigrationJournal)
+	gMigration.POST("/ns/:nsId/journal/:journalId/rollback", controller.RollbackMigrationJournal)
+
+	// Migration wave APIs (ordered multi-group migration with gates)
+	gMigration.POST("/ns/:nsId/wavePlan", controller.CreateWavePlan)
+	gMigration.GET("/ns/:nsId/wavePlan", controller.ListWavePlans)
+	gMigration.GET("/ns/:nsId/wavePlan/:planId", controller.GetWavePlan)
+	gMigration.POST("/ns/:nsId/wavePlan/:planId/resume", controller.ResumeWavePlan)
+	gMigration.POST("/ns/:nsId/wavePlan/:planId/wave/:waveName/approve", controller.ApproveWave)
+
 	// Migration APIs for resources for VM infrastructure
 	// APIs for the VM spec resources
 	// gMigration.GET("/ns/:nsId/resources/spec", controller.ListMigratedSpec)
@@ -399,6 +427,9 @@ func RunServer(port string) {
 	gMigrationMiddleware.GET("/ns/:nsId/infra/:infraId/nlb/:nlbId", controller.GetNlb)
 	gMigrationMiddleware.DELETE("/ns/:nsId/infra/:infraId/nlb/:nlbId", controller.DeleteNlb)
 
+	// Migration APIs for L7 ALB (provisioned as L4 NLBs with manual L7 actions)
+	gMigrationMiddleware.POST("/ns/:nsId/infra/:infraId/alb", controller.MigrateAlbs)
+
 	// Migration APIs for object storage
 	gMigrationMiddleware.POST("/ns/:nsId/objectStorage", controller.MigrateObjectStorage)
 	gMigrationMiddleware.GET("/ns/:nsId/objectStorage", controller.ListObjectStorages)
@@ -432,6 +463,41 @@ func RunServer(port string) {
 	// Summary APIs for source infrastructure
 	gSummary.POST("/source", controller.GenerateSourceInfraSummary)
 
+	/*
+	 * API group for migration projects
+	 */
+	gProject := gBeetle.Group("/project")
+
+	// Project APIs tying the artifacts of a migration together
+	gProject.POST("", controller.CreateProject)
+	gProject.GET("", controller.ListProjects)
+	gProject.GET("/:projectId", controller.GetProject)
+	gProject.PUT("/:projectId", controller.UpdateProject)
+	gProject.DELETE("/:projectId", controller.DeleteProject)
+	gProject.GET("/:projectId/history", controller.GetProjectHistory)
+	gProject.POST("/:projectId/recommendation", controller.AddProjectRecommendation)
+	gProject.PUT("/:projectId/selection", controller.SelectProjectCandidate)
+	gProject.POST("/:projectId/approval", controller.ApproveProject)
+	gProject.GET("/:projectId/report/:reportId", controller.GetProjectReport)
+
+	/*
+	 * API group for migration policies
+	 */
+	gPolicy := gBeetle.Group("/policy")
+
+	// Policy APIs for the guardrails evaluated before migrations execute
+	gPolicy.GET("", controller.ListPolicies)
+	gPolicy.POST("/evaluate", controller.EvaluatePolicy)
+
+	/*
+	 * API group for outbound notifications
+	 */
+	gNotification := gBeetle.Group("/notification")
+
+	// Notification APIs to inspect and redeliver the events in the outbox
+	gNotification.GET("/outbox", controller.ListNotificationOutbox)
+	gNotification.POST("/outbox/:entryId/redeliver", controller.RedeliverNotification)
+
 	// Start API server
 	selfEndpoint := config.Beetle.Self.Endpoint
 	apiDoc := "http://" + selfEndpoint + "/beetle/api" // To be deprecated
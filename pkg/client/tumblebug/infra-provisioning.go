@@ -273,3 +273,50 @@ func (s *Session) InfraRecommendSpec(planToSearchProperVm string) ([]tbmodel.Spe
 	log.Debug().Msgf("Found VM specs (count: %d) successfully", len(vmSpecInfoList))
 	return vmSpecInfoList, nil
 }
+
+// SshCmdResult is the result of a remote command executed on a Node.
+// Note: tbmodel.SshCmdResult cannot be used to unmarshal the response, since its 'err' field is an error interface.
+type SshCmdResult struct {
+	InfraId string         `json:"infraId"`
+	NodeId  string         `json:"nodeId"`
+	NodeIp  string         `json:"nodeIp"`
+	Command map[int]string `json:"command"`
+	Stdout  map[int]string `json:"stdout"`
+	Stderr  map[int]string `json:"stderr"`
+	Err     any            `json:"err"`
+}
+
+// InfraSshCmdResult is the response body for POST /ns/{nsId}/cmd/infra/{infraId}.
+type InfraSshCmdResult struct {
+	Results []SshCmdResult `json:"results"`
+}
+
+// RunRemoteCommand executes the commands on the Nodes of the Infra via SSH (on the Node only if nodeId is given)
+func (s *Session) RunRemoteCommand(nsId, infraId, nodeId string, reqBody tbmodel.InfraCmdReq) (InfraSshCmdResult, error) {
+	log.Debug().Msg("Running remote command on Infra")
+
+	var emptyRet InfraSshCmdResult
+
+	url := fmt.Sprintf("/ns/%s/cmd/infra/%s", nsId, infraId)
+	if nodeId != "" {
+		url += fmt.Sprintf("?nodeId=%s", nodeId)
+	}
+
+	resBody := InfraSshCmdResult{}
+
+	resp, err := s.
+		SetBody(reqBody).
+		SetResult(&resBody).
+		Post(url)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to run remote command")
+		return emptyRet, err
+	}
+	if resp.IsError() {
+		return emptyRet, fmt.Errorf("API request failed with status: %d, body: %s", resp.StatusCode(), resp.String())
+	}
+
+	log.Debug().Msgf("Ran remote command on Infra (infraId: %s, nodeId: %s, results: %d) successfully", infraId, nodeId, len(resBody.Results))
+	return resBody, nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tbclient provides client functions to interact with CB-Tumblebug API
+package tbclient
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// K8sAvailableVersion is an item of the response body for GET /availableK8sVersion.
+type K8sAvailableVersion struct {
+	Name string `json:"name"` // Version name (e.g., "1.30")
+	Id   string `json:"id"`   // CSP-specific version ID (e.g., "1.30.1-aliyun.1")
+}
+
+// ReadAvailableK8sVersions retrieves the managed K8s versions available in a specific provider and region
+func (s *Session) ReadAvailableK8sVersions(providerName, regionName string) ([]K8sAvailableVersion, error) {
+	log.Debug().Msg("Retrieving available K8s versions")
+
+	var emptyRet = []K8sAvailableVersion{}
+
+	resBody := []K8sAvailableVersion{}
+
+	resp, err := s.
+		SetQueryParams(map[string]string{
+			"providerName": providerName,
+			"regionName":   regionName,
+		}).
+		SetResult(&resBody).
+		Get("/availableK8sVersion")
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve available K8s versions")
+		return emptyRet, err
+	}
+	if resp.IsError() {
+		return emptyRet, fmt.Errorf("API request failed with status: %d, body: %s", resp.StatusCode(), resp.String())
+	}
+
+	log.Debug().Msgf("Retrieved %d available K8s versions (provider: %s, region: %s)", len(resBody), providerName, regionName)
+	return resBody, nil
+}
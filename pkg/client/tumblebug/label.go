@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tbclient provides client functions to interact with CB-Tumblebug API
+package tbclient
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Label types of the resources labeled by CM-Beetle
+const (
+	LabelTypeVNet          = "vNet"
+	LabelTypeSshKey        = "sshKey"
+	LabelTypeSecurityGroup = "securityGroup"
+	LabelTypeNode          = "node"
+	LabelTypeObjectStorage = "objectStorage"
+)
+
+// LabelReq is the request body to create or update the labels of a resource
+type LabelReq struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// CreateOrUpdateLabel creates or updates the labels of a resource identified by its label type and UID
+func (s *Session) CreateOrUpdateLabel(labelType, uid string, labels map[string]string) error {
+	log.Debug().Msgf("Labeling the resource (labelType: %s, uid: %s)", labelType, uid)
+
+	resp, err := s.
+		SetBody(LabelReq{Labels: labels}).
+		Put(fmt.Sprintf("/label/%s/%s", labelType, uid))
+
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to label the resource (labelType: %s, uid: %s)", labelType, uid)
+		return err
+	}
+
+	if resp.IsError() {
+		err := fmt.Errorf("API Error: %s (Body: %s)", resp.Status(), string(resp.Body()))
+		log.Error().Err(err).Msgf("Failed to label the resource (labelType: %s, uid: %s)", labelType, uid)
+		return err
+	}
+
+	log.Debug().Msgf("Labeled the resource (labelType: %s, uid: %s) successfully", labelType, uid)
+	return nil
+}
@@ -80,6 +80,37 @@ func (s *Session) ReadVNet(nsId, vNetId string) (tbmodel.VNetInfo, error) {
 	return resBody, nil
 }
 
+// VNetListResponse is the response body for GET /ns/{nsId}/resources/vNet.
+type VNetListResponse struct {
+	VNet []tbmodel.VNetInfo `json:"vNet"`
+}
+
+// ReadAllVNet retrieves all Virtual Networks (VNets) in the specified namespace
+func (s *Session) ReadAllVNet(nsId string) (VNetListResponse, error) {
+	log.Debug().Msg("Retrieving all Virtual Networks")
+
+	emptyRet := VNetListResponse{}
+
+	url := fmt.Sprintf("/ns/%s/resources/vNet", nsId)
+
+	resBody := VNetListResponse{}
+
+	resp, err := s.
+		SetResult(&resBody).
+		Get(url)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve VNets")
+		return emptyRet, err
+	}
+	if resp.IsError() {
+		return emptyRet, fmt.Errorf("API request failed with status: %d, body: %s", resp.StatusCode(), resp.String())
+	}
+
+	log.Debug().Msgf("Retrieved %d VNet(s) successfully", len(resBody.VNet))
+	return resBody, nil
+}
+
 func (s *Session) DeleteVNet(nsId, vNetId, action string) (tbmodel.SimpleMsg, error) {
 	log.Debug().Msg("Deleting Virtual Network")
 
@@ -1,26 +0,0 @@
-package compat
-
-import (
-	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
-	"github.com/rs/zerolog/log"
-)
-
-// CheckGcp checks compatibility between GCP VM spec and OS image
-func CheckGcp(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) bool {
-	log.Trace().Msgf("Starting GCP compatibility check for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-
-	// TODO: Add GCP-specific compatibility checks using Detail information
-	log.Info().Msg("GCP compatibility validation is planned for future implementation")
-
-	log.Trace().Msgf("GCP compatibility check passed for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-	return true
-}
-
-// === GCP-Specific Helper Functions ===
-// TODO: Add GCP-specific compatibility validation functions as needed
-// Examples:
-// - Machine type family compatibility
-// - GPU availability checks
-// - Regional capability validation
-// - Disk type compatibility
-// - Network performance requirements
@@ -1,26 +0,0 @@
-package compat
-
-import (
-	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
-	"github.com/rs/zerolog/log"
-)
-
-// CheckIbm checks compatibility between IBM Cloud VM spec and OS image
-func CheckIbm(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) bool {
-	log.Trace().Msgf("Starting IBM Cloud compatibility check for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-
-	// TODO: Add IBM Cloud-specific compatibility checks using Detail information
-	log.Info().Msg("IBM Cloud compatibility validation is planned for future implementation")
-
-	log.Trace().Msgf("IBM Cloud compatibility check passed for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-	return true
-}
-
-// === IBM Cloud-Specific Helper Functions ===
-// TODO: Add IBM Cloud-specific compatibility validation functions as needed
-// Examples:
-// - Virtual Server Instance profile compatibility
-// - Bare metal vs virtual server requirements
-// - GPU and FPGA support validation
-// - Network interface requirements
-// - Storage type compatibility (Block, File, Object)
@@ -1,26 +0,0 @@
-package compat
-
-import (
-	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
-	"github.com/rs/zerolog/log"
-)
-
-// CheckKt checks compatibility between KT Cloud VM spec and OS image
-func CheckKt(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) bool {
-	log.Trace().Msgf("Starting KT Cloud compatibility check for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-
-	// TODO: Add KT Cloud-specific compatibility checks using Detail information
-	log.Info().Msg("KT Cloud compatibility validation is planned for future implementation")
-
-	log.Trace().Msgf("KT Cloud compatibility check passed for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-	return true
-}
-
-// === KT Cloud-Specific Helper Functions ===
-// TODO: Add KT Cloud-specific compatibility validation functions as needed
-// Examples:
-// - Virtual machine tier compatibility
-// - Network zone requirements
-// - Storage type validation
-// - Security group compatibility
-// - Load balancer integration requirements
@@ -1,27 +0,0 @@
-package compat
-
-import (
-	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
-	"github.com/rs/zerolog/log"
-)
-
-// CheckOpenstack checks compatibility between OpenStack VM spec and OS image
-func CheckOpenstack(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) bool {
-	log.Trace().Msgf("Starting OpenStack compatibility check for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-
-	// TODO: Add OpenStack-specific compatibility checks using Detail information
-	log.Info().Msg("OpenStack compatibility validation is planned for future implementation")
-
-	log.Trace().Msgf("OpenStack compatibility check passed for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-	return true
-}
-
-// === OpenStack-Specific Helper Functions ===
-// TODO: Add OpenStack-specific compatibility validation functions as needed
-// Examples:
-// - Flavor compatibility validation
-// - Hypervisor type requirements (KVM, Xen, VMware, etc.)
-// - Image format compatibility (qcow2, raw, vmdk, etc.)
-// - Availability zone requirements
-// - Volume type and storage backend compatibility
-// - Network provider compatibility (ML2, OVS, etc.)
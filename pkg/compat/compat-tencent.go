@@ -1,26 +0,0 @@
-package compat
-
-import (
-	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
-	"github.com/rs/zerolog/log"
-)
-
-// CheckTencent checks compatibility between Tencent Cloud VM spec and OS image
-func CheckTencent(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) bool {
-	log.Trace().Msgf("Starting Tencent Cloud compatibility check for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-
-	// TODO: Add Tencent Cloud-specific compatibility checks using Detail information
-	log.Info().Msg("Tencent Cloud compatibility validation is planned for future implementation")
-
-	log.Trace().Msgf("Tencent Cloud compatibility check passed for Spec: %s, Image: %s", spec.CspSpecName, image.CspImageName)
-	return true
-}
-
-// === Tencent Cloud-Specific Helper Functions ===
-// TODO: Add Tencent Cloud-specific compatibility validation functions as needed
-// Examples:
-// - Instance family compatibility (CVM S5, M5, C4, etc.)
-// - Regional availability validation
-// - GPU instance type support
-// - Local disk vs cloud disk compatibility
-// - Network performance requirements
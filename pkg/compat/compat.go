@@ -1,8 +1,10 @@
 // Package compat provides compatibility checking functionality between VM specifications and images across different Cloud Service Providers (CSPs).
 // This package centralizes all CSP-specific compatibility validation logic.
+// CSP-specific checks are expressed as declarative rule sets (see rule-engine.go and rules/*.yaml).
 package compat
 
 import (
+	"fmt"
 	"strings"
 
 	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
@@ -14,6 +16,19 @@ type Checker interface {
 	CheckCompatibility(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) bool
 }
 
+// Incompatibility reasons reported by DiagnoseCompatibility
+const (
+	ReasonArchitecture       = "architecture"
+	ReasonVirtualizationType = "virtualization-type"
+	ReasonHypervisor         = "hypervisor"
+	ReasonEna                = "ena"
+	ReasonNvme               = "nvme"
+	ReasonBootMode           = "boot-mode"
+	ReasonGeneration         = "generation"
+	ReasonImageNotSupported  = "image-not-supported"
+	ReasonUnknown            = "unknown"
+)
+
 // CheckCompatibility performs compatibility check between spec and image for the specified CSP
 func CheckCompatibility(csp string, spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) bool {
 
@@ -22,32 +37,34 @@ func CheckCompatibility(csp string, spec cloudmodel.SpecInfo, image cloudmodel.I
 		return false
 	}
 
-	// 2. CSP-specific compatibility checks using Detail information
-	switch strings.ToLower(csp) {
-	case "aws":
-		return CheckAws(spec, image)
-	case "gcp":
-		return CheckGcp(spec, image)
-	case "azure":
-		return CheckAzure(spec, image)
-	case "ncp":
-		return CheckNcp(spec, image)
-	case "alibaba":
-		return CheckAlibaba(spec, image)
-	case "tencent":
-		return CheckTencent(spec, image)
-	case "ibm":
-		return CheckIbm(spec, image)
-	case "nhn":
-		return CheckNhn(spec, image)
-	case "kt":
-		return CheckKt(spec, image)
-	case "openstack":
-		return CheckOpenstack(spec, image)
-	default:
+	// 2. CSP-specific compatibility checks using Detail information (see rules/*.yaml)
+	checker, ok := GetChecker(csp)
+	if !ok {
 		log.Trace().Msgf("No specific compatibility checks for CSP: %s", csp)
 		return true
 	}
+	return checker.CheckCompatibility(spec, image)
+}
+
+// DiagnoseCompatibility performs the same checks as CheckCompatibility and,
+// if the pair is incompatible, returns the reason and a human-readable detail of the first failed check
+func DiagnoseCompatibility(csp string, spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) (compatible bool, reason string, detail string) {
+
+	if !isArchitectureCompatible(csp, spec, image) {
+		return false, ReasonArchitecture, fmt.Sprintf("spec architecture %s, image architecture %s", spec.Architecture, string(image.OSArchitecture))
+	}
+
+	checker, ok := GetChecker(csp)
+	if !ok {
+		return true, "", ""
+	}
+	if d, ok := checker.(diagnoser); ok {
+		return d.Diagnose(spec, image)
+	}
+	if checker.CheckCompatibility(spec, image) {
+		return true, "", ""
+	}
+	return false, ReasonUnknown, ""
 }
 
 // isArchitectureCompatible checks CPU architecture compatibility for all CSPs
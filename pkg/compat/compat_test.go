@@ -0,0 +1,266 @@
+package compat
+
+import (
+	"testing"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+)
+
+// details builds Details from key-value pairs
+func details(kvs ...string) []cloudmodel.KeyValue {
+	var result []cloudmodel.KeyValue
+	for i := 0; i+1 < len(kvs); i += 2 {
+		result = append(result, cloudmodel.KeyValue{Key: kvs[i], Value: kvs[i+1]})
+	}
+	return result
+}
+
+type compatCase struct {
+	name       string
+	spec       cloudmodel.SpecInfo
+	image      cloudmodel.ImageInfo
+	compatible bool
+	reason     string // Expected reason if incompatible
+}
+
+// The cases below are the results of the hand-written checkers (CheckAws, CheckAzure, CheckAlibaba, CheckNcp)
+// replaced by the built-in rule sets, which must keep the results.
+
+var awsCases = []compatCase{
+	{
+		name:       "no details",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "t3.micro"},
+		image:      cloudmodel.ImageInfo{CspImageName: "ami-1"},
+		compatible: true,
+	},
+	{
+		name:       "supported virtualization type",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "t3.micro", Details: details("SupportedVirtualizationTypes", "hvm; paravirtual")},
+		image:      cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("VirtualizationType", "HVM")},
+		compatible: true,
+	},
+	{
+		name:   "unsupported virtualization type",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "t3.micro", Details: details("SupportedVirtualizationTypes", "hvm")},
+		image:  cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("VirtualizationType", "paravirtual")},
+		reason: ReasonVirtualizationType,
+	},
+	{
+		name:   "ENA required but unsupported by the image",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "m5.large", Details: details("NetworkInfo", "{DefaultNetworkCardIndex:0,EfaSupported:false,EnaSupport:required,Ipv6Supported:true}")},
+		image:  cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("EnaSupport", "false")},
+		reason: ReasonEna,
+	},
+	{
+		name:       "ENA required and supported by the image",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "m5.large", Details: details("NetworkInfo", "{EnaSupport:required}")},
+		image:      cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("EnaSupport", "true")},
+		compatible: true,
+	},
+	{
+		name:   "NVMe required but unsupported by the image",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "m5.large", Details: details("EbsInfo", "{EncryptionSupport:supported,NvmeSupport:required}")},
+		image:  cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("NvmeSupport", "false")},
+		reason: ReasonNvme,
+	},
+	{
+		name:   "NVMe required and a Xen image without NVMe information",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "m5.large", Details: details("EbsInfo", "{NvmeSupport:required}")},
+		image:  cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("Hypervisor", "xen")},
+		reason: ReasonNvme,
+	},
+	{
+		name:       "Xen-on-Nitro does not require NVMe",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "m5.large", Details: details("Hypervisor", "nitro", "EbsInfo", "{NvmeSupport:required}")},
+		image:      cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("Hypervisor", "xen")},
+		compatible: true,
+	},
+	{
+		name:   "Xen-on-Nitro still requires ENA",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "m5.large", Details: details("Hypervisor", "nitro", "NetworkInfo", "{EnaSupport:required}", "EbsInfo", "{NvmeSupport:required}")},
+		image:  cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("Hypervisor", "xen", "EnaSupport", "false")},
+		reason: ReasonEna,
+	},
+	{
+		name:   "unsupported boot mode",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "m7g.large", Details: details("SupportedBootModes", "uefi")},
+		image:  cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("BootMode", "legacy-bios")},
+		reason: ReasonBootMode,
+	},
+	{
+		name:       "uefi-preferred image on a legacy-bios spec",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "t2.micro", Details: details("SupportedBootModes", "legacy-bios")},
+		image:      cloudmodel.ImageInfo{CspImageName: "ami-1", Details: details("BootMode", "uefi-preferred")},
+		compatible: true,
+	},
+	{
+		name:   "architecture mismatch",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "m7g.large", Architecture: "arm64"},
+		image:  cloudmodel.ImageInfo{CspImageName: "ami-1", OSArchitecture: "x86_64"},
+		reason: ReasonArchitecture,
+	},
+}
+
+var azureCases = []compatCase{
+	{
+		name:       "Gen1-only size with a Generation 1 image",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "Standard_A2"},
+		image:      cloudmodel.ImageInfo{CspImageName: "Canonical:UbuntuServer:18.04-LTS:latest"},
+		compatible: true,
+	},
+	{
+		name:   "Gen1-only size with a Gen2 image by name",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "Standard_A2"},
+		image:  cloudmodel.ImageInfo{CspImageName: "Canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2:latest"},
+		reason: ReasonGeneration,
+	},
+	{
+		name:   "small D-v3 size is Gen1-only",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "Standard_D2_v3"},
+		image:  cloudmodel.ImageInfo{CspImageName: "image-g2"},
+		reason: ReasonGeneration,
+	},
+	{
+		name:   "Gen2-only size with a Generation 1 image by default",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "Standard_DC2s_v3"},
+		image:  cloudmodel.ImageInfo{CspImageName: "Canonical:UbuntuServer:18.04-LTS:latest"},
+		reason: ReasonGeneration,
+	},
+	{
+		name:       "Gen2-only size with a Generation 2 image by properties",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "Standard_DC2s_v3"},
+		image:      cloudmodel.ImageInfo{CspImageName: "image-1", Details: details("Properties", "{architecture:x64,hyperVGeneration:V2}")},
+		compatible: true,
+	},
+	{
+		name:       "size supporting both generations",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "Standard_D2s_v5"},
+		image:      cloudmodel.ImageInfo{CspImageName: "image-1", Details: details("Properties", "{hyperVGeneration:V1}")},
+		compatible: true,
+	},
+}
+
+var alibabaCases = []compatCase{
+	{
+		name:   "NVMe required but unsupported by the image",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "ecs.g7.large", Details: details("NvmeSupport", "required")},
+		image:  cloudmodel.ImageInfo{CspImageName: "img-1", Details: details("Features", "{NvmeSupport:unsupported}")},
+		reason: ReasonNvme,
+	},
+	{
+		name:   "NVMe supported by the spec but unsupported by the image",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "ecs.g7.large", Details: details("NvmeSupport", "supported")},
+		image:  cloudmodel.ImageInfo{CspImageName: "img-1", Details: details("Features", "{NvmeSupport:unsupported}")},
+		reason: ReasonNvme,
+	},
+	{
+		name:   "NVMe required by the image but unsupported by the spec",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "ecs.g6.large", Details: details("NvmeSupport", "unsupported")},
+		image:  cloudmodel.ImageInfo{CspImageName: "img-1", Details: details("Features", "{NvmeSupport:required}")},
+		reason: ReasonNvme,
+	},
+	{
+		name:       "NVMe drivers unused on a non-NVMe spec",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "ecs.g6.large", Details: details("NvmeSupport", "unsupported")},
+		image:      cloudmodel.ImageInfo{CspImageName: "img-1", Details: details("Features", "{NvmeSupport:supported}")},
+		compatible: true,
+	},
+	{
+		name:       "NVMe required and no image information",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "ecs.g7.large", Details: details("NvmeSupport", "required")},
+		image:      cloudmodel.ImageInfo{CspImageName: "img-1"},
+		compatible: true,
+	},
+	{
+		name:       "supported boot mode",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "ecs.g7.large", Details: details("SupportedBootModes", "{SupportedBootMode:[BIOS,UEFI]}")},
+		image:      cloudmodel.ImageInfo{CspImageName: "img-1", Details: details("BootMode", "UEFI")},
+		compatible: true,
+	},
+	{
+		name:   "unsupported boot mode",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "ecs.g6.large", Details: details("SupportedBootModes", "{SupportedBootMode:[BIOS]}")},
+		image:  cloudmodel.ImageInfo{CspImageName: "img-1", Details: details("BootMode", "UEFI")},
+		reason: ReasonBootMode,
+	},
+	{
+		name:       "UEFI-Preferred image on a BIOS spec",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "ecs.g6.large", Details: details("SupportedBootModes", "{SupportedBootMode:[BIOS]}")},
+		image:      cloudmodel.ImageInfo{CspImageName: "img-1", Details: details("BootMode", "UEFI-Preferred")},
+		compatible: true,
+	},
+}
+
+var ncpCases = []compatCase{
+	{
+		name:       "no corresponding image IDs",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "s2-g3"},
+		image:      cloudmodel.ImageInfo{CspImageName: "23214590"},
+		compatible: true,
+	},
+	{
+		name:       "image ID by name in the corresponding image IDs",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "s2-g3", Details: details("CorrespondingImageIds", "23214590, 23221307")},
+		image:      cloudmodel.ImageInfo{CspImageName: "23221307"},
+		compatible: true,
+	},
+	{
+		name:   "image ID by name not in the corresponding image IDs",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "s2-g3", Details: details("CorrespondingImageIds", "23214590,23221307")},
+		image:  cloudmodel.ImageInfo{CspImageName: "23000000"},
+		reason: ReasonImageNotSupported,
+	},
+	{
+		name:       "image ID by details",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "s2-g3", Details: details("CorrespondingImageIds", "23214590,23221307")},
+		image:      cloudmodel.ImageInfo{CspImageName: "ubuntu-22.04", Details: details("ImageId", "23214590")},
+		compatible: true,
+	},
+	{
+		name:   "longest number in the name",
+		spec:   cloudmodel.SpecInfo{CspSpecName: "s2-g3", Details: details("CorrespondingImageIds", "23214590")},
+		image:  cloudmodel.ImageInfo{CspImageName: "ubuntu-22.04-base"},
+		reason: ReasonImageNotSupported,
+	},
+	{
+		name:       "no image ID",
+		spec:       cloudmodel.SpecInfo{CspSpecName: "s2-g3", Details: details("CorrespondingImageIds", "23214590")},
+		image:      cloudmodel.ImageInfo{CspImageName: "ubuntu"},
+		compatible: true,
+	},
+}
+
+func TestBuiltInRuleSets(t *testing.T) {
+	cspCases := map[string][]compatCase{
+		"aws":     awsCases,
+		"azure":   azureCases,
+		"alibaba": alibabaCases,
+		"ncp":     ncpCases,
+	}
+
+	for csp, cases := range cspCases {
+		checker, ok := GetChecker(csp)
+		if !ok {
+			t.Fatalf("no built-in checker for %s", csp)
+		}
+
+		for _, tc := range cases {
+			t.Run(csp+"/"+tc.name, func(t *testing.T) {
+				// The architecture is checked in common, before the checker
+				if tc.reason != ReasonArchitecture {
+					if got := checker.CheckCompatibility(tc.spec, tc.image); got != tc.compatible {
+						t.Errorf("CheckCompatibility() = %v, want %v", got, tc.compatible)
+					}
+				}
+
+				compatible, reason, _ := DiagnoseCompatibility(csp, tc.spec, tc.image)
+				if compatible != tc.compatible || reason != tc.reason {
+					t.Errorf("DiagnoseCompatibility() = (%v, %q), want (%v, %q)", compatible, reason, tc.compatible, tc.reason)
+				}
+				if got := CheckCompatibility(csp, tc.spec, tc.image); got != tc.compatible {
+					t.Errorf("CheckCompatibility(%s) = %v, want %v", csp, got, tc.compatible)
+				}
+			})
+		}
+	}
+}
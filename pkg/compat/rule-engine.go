@@ -0,0 +1,532 @@
+// rule-engine.go provides a declarative rule engine for spec/image compatibility checks.
+// Rules are expressed as versioned data (YAML/JSON) per CSP and evaluated behind the Checker interface.
+package compat
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSetVersionV1 is the rule set schema version supported by the engine
+const RuleSetVersionV1 = "v1"
+
+// Rule operators
+const (
+	OperatorContains = "contains" // Spec values must contain the image value (or one of its aliases)
+	OperatorAllow    = "allow"    // If the spec value is listed in a pair, the image value must be listed in that pair
+	OperatorDeny     = "deny"     // The pair of spec and image values must not be listed
+)
+
+// Value source fields
+const (
+	FieldDetail       = "detail"       // Value of a key in Details (default)
+	FieldName         = "name"         // CspSpecName or CspImageName
+	FieldArchitecture = "architecture" // Architecture or OSArchitecture
+)
+
+// Built-in rule sets, which can be overridden by rule sets loaded from a directory
+//
+//go:embed rules/*.yaml
+var embeddedRuleSets embed.FS
+
+// RuleSet is a versioned set of compatibility rules for a CSP
+type RuleSet struct {
+	Version     string `json:"version" yaml:"version"`
+	Csp         string `json:"csp" yaml:"csp"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Rules       []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule is a single compatibility rule evaluated in order. The first failed rule makes the pair incompatible.
+type Rule struct {
+	Id          string              `json:"id" yaml:"id"`
+	Reason      string              `json:"reason" yaml:"reason"` // Reported by DiagnoseCompatibility (e.g., "boot-mode")
+	Description string              `json:"description,omitempty" yaml:"description,omitempty"`
+	Spec        ValueSource         `json:"spec" yaml:"spec"`
+	Image       ValueSource         `json:"image" yaml:"image"`
+	Operator    string              `json:"operator" yaml:"operator"`                       // contains | allow | deny
+	Aliases     map[string][]string `json:"aliases,omitempty" yaml:"aliases,omitempty"`     // Image value -> spec values also accepted (contains)
+	Pairs       []ValuePair         `json:"pairs,omitempty" yaml:"pairs,omitempty"`         // Value pairs (allow, deny)
+	Unless      []Condition         `json:"unless,omitempty" yaml:"unless,omitempty"`       // The rule is skipped if all conditions match
+	OnMissing   string              `json:"onMissing,omitempty" yaml:"onMissing,omitempty"` // "pass" (default) or "fail" if a value is not found
+}
+
+// ValuePair is a pair of spec and image values. "*" matches any value.
+type ValuePair struct {
+	Spec  []string `json:"spec" yaml:"spec"`
+	Image []string `json:"image" yaml:"image"`
+}
+
+// Condition matches if the value extracted from the target is one of the values
+type Condition struct {
+	Target string      `json:"target" yaml:"target"` // spec | image
+	Source ValueSource `json:"source" yaml:"source"`
+	Values []string    `json:"values" yaml:"values"`
+}
+
+// ValueSource describes how to extract values from a spec or an image
+type ValueSource struct {
+	Field       string         `json:"field,omitempty" yaml:"field,omitempty"`             // detail (default) | name | architecture
+	Keys        []string       `json:"keys,omitempty" yaml:"keys,omitempty"`               // Detail keys (case-insensitive, the first found is used)
+	KeyContains bool           `json:"keyContains,omitempty" yaml:"keyContains,omitempty"` // Match detail keys by substring
+	Pattern     string         `json:"pattern,omitempty" yaml:"pattern,omitempty"`         // Regex applied to the value (the 1st capture group is used if any)
+	Longest     bool           `json:"longest,omitempty" yaml:"longest,omitempty"`         // Use the longest match of the pattern instead of the first
+	Separator   string         `json:"separator,omitempty" yaml:"separator,omitempty"`     // Split the value into a list
+	Lowercase   bool           `json:"lowercase,omitempty" yaml:"lowercase,omitempty"`
+	Map         []ValueMapping `json:"map,omitempty" yaml:"map,omitempty"`         // Normalize values (the first match is used, unmatched values are dropped)
+	FirstOf     []ValueSource  `json:"firstOf,omitempty" yaml:"firstOf,omitempty"` // Alternative sources (the first one with values is used)
+	Default     string         `json:"default,omitempty" yaml:"default,omitempty"` // Used if no value is found
+
+	patternRe *regexp.Regexp
+}
+
+// ValueMapping normalizes a value matching the regex to the given value
+type ValueMapping struct {
+	Match string `json:"match" yaml:"match"`
+	Value string `json:"value" yaml:"value"`
+
+	matchRe *regexp.Regexp
+}
+
+// RuleSetChecker evaluates a rule set. It implements the Checker interface.
+type RuleSetChecker struct {
+	ruleSet RuleSet
+}
+
+// diagnoser is implemented by checkers able to report the reason of incompatibility
+type diagnoser interface {
+	Diagnose(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) (compatible bool, reason string, detail string)
+}
+
+var (
+	checkersMu sync.RWMutex
+	checkers   = map[string]Checker{}
+)
+
+func init() {
+	entries, err := embeddedRuleSets.ReadDir("rules")
+	if err != nil {
+		log.Error().Err(err).Msg("failed to read the built-in compatibility rule sets")
+		return
+	}
+	for _, entry := range entries {
+		data, err := embeddedRuleSets.ReadFile("rules/" + entry.Name())
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to read the built-in compatibility rule set (%s)", entry.Name())
+			continue
+		}
+		ruleSet, err := ParseRuleSet(entry.Name(), data)
+		if err != nil {
+			log.Error().Err(err).Msgf("invalid built-in compatibility rule set (%s)", entry.Name())
+			continue
+		}
+		RegisterChecker(ruleSet.Csp, NewRuleSetChecker(ruleSet))
+	}
+}
+
+// RegisterChecker registers (or replaces) the checker for a CSP
+func RegisterChecker(csp string, checker Checker) {
+	checkersMu.Lock()
+	defer checkersMu.Unlock()
+	checkers[strings.ToLower(csp)] = checker
+}
+
+// GetChecker returns the checker registered for a CSP
+func GetChecker(csp string) (Checker, bool) {
+	checkersMu.RLock()
+	defer checkersMu.RUnlock()
+	checker, ok := checkers[strings.ToLower(csp)]
+	return checker, ok
+}
+
+// LoadRuleSetsFromDir loads rule sets (*.yaml, *.yml, *.json) from a directory and registers them.
+// A rule set for a CSP replaces the built-in one, so rule sets can be added or tuned without recompiling.
+// All rule sets are parsed first, and none is registered if any of them is invalid.
+func LoadRuleSetsFromDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the rule set directory (%s): %w", dir, err)
+	}
+
+	var ruleSets []RuleSet
+	var paths []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the rule set (%s): %w", path, err)
+		}
+		ruleSet, err := ParseRuleSet(entry.Name(), data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule set (%s): %w", path, err)
+		}
+		ruleSets = append(ruleSets, ruleSet)
+		paths = append(paths, path)
+	}
+
+	var loaded []string
+	for i, ruleSet := range ruleSets {
+		RegisterChecker(ruleSet.Csp, NewRuleSetChecker(ruleSet))
+		loaded = append(loaded, ruleSet.Csp)
+		log.Info().Msgf("loaded compatibility rule set for %s (version: %s, rules: %d, file: %s)",
+			ruleSet.Csp, ruleSet.Version, len(ruleSet.Rules), paths[i])
+	}
+
+	sort.Strings(loaded)
+	return loaded, nil
+}
+
+// ParseRuleSet parses a rule set in YAML or JSON (by the file extension) and validates it
+func ParseRuleSet(fileName string, data []byte) (RuleSet, error) {
+	var ruleSet RuleSet
+
+	var err error
+	if strings.EqualFold(filepath.Ext(fileName), ".json") {
+		err = json.Unmarshal(data, &ruleSet)
+	} else {
+		err = yaml.Unmarshal(data, &ruleSet)
+	}
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("failed to parse: %w", err)
+	}
+
+	if err := ruleSet.compile(); err != nil {
+		return RuleSet{}, err
+	}
+	return ruleSet, nil
+}
+
+// NewRuleSetChecker creates a checker evaluating the (compiled) rule set
+func NewRuleSetChecker(ruleSet RuleSet) *RuleSetChecker {
+	return &RuleSetChecker{ruleSet: ruleSet}
+}
+
+// RuleSet returns the rule set evaluated by the checker
+func (c *RuleSetChecker) RuleSet() RuleSet {
+	return c.ruleSet
+}
+
+// CheckCompatibility checks if the spec and the image satisfy all rules
+func (c *RuleSetChecker) CheckCompatibility(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) bool {
+	compatible, _, _ := c.Diagnose(spec, image)
+	return compatible
+}
+
+// Diagnose evaluates the rules in order and returns the reason and detail of the first failed rule
+func (c *RuleSetChecker) Diagnose(spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) (bool, string, string) {
+	csp := strings.ToUpper(c.ruleSet.Csp)
+	log.Trace().Msgf("Starting %s compatibility check for Spec: %s, Image: %s", csp, spec.CspSpecName, image.CspImageName)
+
+	specSubject := subject{name: spec.CspSpecName, architecture: spec.Architecture, details: spec.Details}
+	imageSubject := subject{name: image.CspImageName, architecture: string(image.OSArchitecture), details: image.Details}
+
+	for _, rule := range c.ruleSet.Rules {
+		passed, specValues, imageValues := rule.evaluate(specSubject, imageSubject)
+		if !passed {
+			log.Trace().Msgf("%s compatibility failed by rule %s - Spec: %s %v, Image: %s %v",
+				csp, rule.Id, spec.CspSpecName, specValues, image.CspImageName, imageValues)
+			return false, rule.Reason, fmt.Sprintf("spec %v, image %v (rule: %s)", specValues, imageValues, rule.Id)
+		}
+	}
+
+	log.Trace().Msgf("%s compatibility check passed for Spec: %s, Image: %s", csp, spec.CspSpecName, image.CspImageName)
+	return true, "", ""
+}
+
+/*
+ * Rule evaluation
+ */
+
+// subject is a spec or an image seen by the rules
+type subject struct {
+	name         string
+	architecture string
+	details      []cloudmodel.KeyValue
+}
+
+// evaluate evaluates a rule and returns whether it passed with the values compared
+func (r *Rule) evaluate(spec, image subject) (bool, []string, []string) {
+
+	if len(r.Unless) > 0 {
+		skip := true
+		for _, cond := range r.Unless {
+			target := spec
+			if cond.Target == "image" {
+				target = image
+			}
+			if !containsFold(cond.Values, cond.Source.values(target)...) {
+				skip = false
+				break
+			}
+		}
+		if skip {
+			return true, nil, nil
+		}
+	}
+
+	specValues := r.Spec.values(spec)
+	imageValues := r.Image.values(image)
+
+	if len(specValues) == 0 || len(imageValues) == 0 {
+		return r.OnMissing != "fail", specValues, imageValues
+	}
+
+	switch r.Operator {
+	case OperatorContains:
+		for _, imageValue := range imageValues {
+			candidates := append([]string{imageValue}, r.Aliases[strings.ToLower(imageValue)]...)
+			if containsFold(specValues, candidates...) {
+				return true, specValues, imageValues
+			}
+		}
+		return false, specValues, imageValues
+
+	case OperatorAllow:
+		for _, pair := range r.Pairs {
+			if matchValues(pair.Spec, specValues) {
+				return matchValues(pair.Image, imageValues), specValues, imageValues
+			}
+		}
+		return true, specValues, imageValues
+
+	case OperatorDeny:
+		for _, pair := range r.Pairs {
+			if matchValues(pair.Spec, specValues) && matchValues(pair.Image, imageValues) {
+				return false, specValues, imageValues
+			}
+		}
+		return true, specValues, imageValues
+
+	default:
+		// Unreachable for compiled rule sets
+		return true, specValues, imageValues
+	}
+}
+
+// values extracts values from the subject
+func (s *ValueSource) values(subj subject) []string {
+	var result []string
+
+	if len(s.FirstOf) > 0 {
+		for i := range s.FirstOf {
+			if result = s.FirstOf[i].values(subj); len(result) > 0 {
+				return result
+			}
+		}
+	} else if raw, found := s.rawValue(subj); found {
+		if s.patternRe != nil {
+			raw = matchPattern(s.patternRe, raw, s.Longest)
+		}
+		if s.Lowercase {
+			raw = strings.ToLower(raw)
+		}
+
+		parts := []string{raw}
+		if s.Separator != "" {
+			parts = strings.Split(raw, s.Separator)
+		}
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if len(s.Map) > 0 {
+				mapped, ok := s.mapValue(part)
+				if !ok {
+					continue
+				}
+				part = mapped
+			}
+			result = append(result, part)
+		}
+	}
+
+	if len(result) == 0 && s.Default != "" {
+		result = []string{s.Default}
+	}
+	return result
+}
+
+// rawValue returns the raw value of the field
+func (s *ValueSource) rawValue(subj subject) (string, bool) {
+	switch s.Field {
+	case FieldName:
+		return subj.name, subj.name != ""
+	case FieldArchitecture:
+		return subj.architecture, subj.architecture != ""
+	}
+
+	for _, kv := range subj.details {
+		key := strings.ToLower(strings.TrimSpace(kv.Key))
+		for _, k := range s.Keys {
+			k = strings.ToLower(k)
+			if key == k || (s.KeyContains && strings.Contains(key, k)) {
+				return strings.TrimSpace(kv.Value), true
+			}
+		}
+	}
+	return "", false
+}
+
+// mapValue normalizes a value by the mappings
+func (s *ValueSource) mapValue(value string) (string, bool) {
+	for _, m := range s.Map {
+		if m.matchRe.MatchString(value) {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
+// matchPattern returns the 1st capture group (or the whole match) of the first or the longest match
+func matchPattern(re *regexp.Regexp, value string, longest bool) string {
+	var best string
+	for _, m := range re.FindAllStringSubmatch(value, -1) {
+		matched := m[0]
+		if len(m) > 1 {
+			matched = m[1]
+		}
+		if !longest {
+			return matched
+		}
+		if len(matched) > len(best) {
+			best = matched
+		}
+	}
+	return best
+}
+
+// matchValues checks if any of the values is listed ("*" matches any)
+func matchValues(listed []string, values []string) bool {
+	for _, l := range listed {
+		if l == "*" {
+			return true
+		}
+	}
+	return containsFold(listed, values...)
+}
+
+// containsFold checks if any of the values is in the list (case-insensitive)
+func containsFold(list []string, values ...string) bool {
+	for _, v := range values {
+		for _, l := range list {
+			if strings.EqualFold(l, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+ * Rule set validation
+ */
+
+// compile validates the rule set and compiles its regular expressions
+func (rs *RuleSet) compile() error {
+	if rs.Version != RuleSetVersionV1 {
+		return fmt.Errorf("unsupported rule set version: %q (supported: %s)", rs.Version, RuleSetVersionV1)
+	}
+	if rs.Csp == "" {
+		return fmt.Errorf("csp is required")
+	}
+	rs.Csp = strings.ToLower(rs.Csp)
+
+	for i := range rs.Rules {
+		rule := &rs.Rules[i]
+		if rule.Id == "" {
+			return fmt.Errorf("rules[%d]: id is required", i)
+		}
+		if rule.Reason == "" {
+			rule.Reason = ReasonUnknown
+		}
+		switch rule.Operator {
+		case OperatorContains, OperatorAllow, OperatorDeny:
+		default:
+			return fmt.Errorf("rule %s: unsupported operator: %q", rule.Id, rule.Operator)
+		}
+		if rule.Operator != OperatorContains && len(rule.Pairs) == 0 {
+			return fmt.Errorf("rule %s: pairs are required for operator %s", rule.Id, rule.Operator)
+		}
+		if rule.OnMissing != "" && rule.OnMissing != "pass" && rule.OnMissing != "fail" {
+			return fmt.Errorf("rule %s: unsupported onMissing: %q", rule.Id, rule.OnMissing)
+		}
+
+		// Alias keys are compared in lowercase
+		aliases := make(map[string][]string, len(rule.Aliases))
+		for k, v := range rule.Aliases {
+			aliases[strings.ToLower(k)] = v
+		}
+		rule.Aliases = aliases
+
+		if err := rule.Spec.compile(); err != nil {
+			return fmt.Errorf("rule %s: spec: %w", rule.Id, err)
+		}
+		if err := rule.Image.compile(); err != nil {
+			return fmt.Errorf("rule %s: image: %w", rule.Id, err)
+		}
+		for j := range rule.Unless {
+			if t := rule.Unless[j].Target; t != "spec" && t != "image" {
+				return fmt.Errorf("rule %s: unless[%d]: unsupported target: %q", rule.Id, j, t)
+			}
+			if err := rule.Unless[j].Source.compile(); err != nil {
+				return fmt.Errorf("rule %s: unless[%d]: %w", rule.Id, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// compile validates the value source and compiles its regular expressions
+func (s *ValueSource) compile() error {
+	if len(s.FirstOf) > 0 {
+		for i := range s.FirstOf {
+			if err := s.FirstOf[i].compile(); err != nil {
+				return fmt.Errorf("firstOf[%d]: %w", i, err)
+			}
+		}
+		return nil
+	}
+
+	switch s.Field {
+	case "", FieldDetail:
+		if len(s.Keys) == 0 {
+			return fmt.Errorf("keys are required for field %s", FieldDetail)
+		}
+	case FieldName, FieldArchitecture:
+	default:
+		return fmt.Errorf("unsupported field: %q", s.Field)
+	}
+
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		s.patternRe = re
+	}
+	for i := range s.Map {
+		re, err := regexp.Compile(s.Map[i].Match)
+		if err != nil {
+			return fmt.Errorf("map[%d]: invalid match: %w", i, err)
+		}
+		s.Map[i].matchRe = re
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package compat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRuleSetErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+		wantErr  string
+	}{
+		{
+			name:     "invalid YAML",
+			fileName: "bad.yaml",
+			data:     "version: v1\ncsp: [",
+			wantErr:  "failed to parse",
+		},
+		{
+			name:     "invalid JSON",
+			fileName: "bad.json",
+			data:     `{"version": "v1", "csp": }`,
+			wantErr:  "failed to parse",
+		},
+		{
+			name:     "unsupported version",
+			fileName: "test.yaml",
+			data:     "version: v2\ncsp: test",
+			wantErr:  "unsupported rule set version",
+		},
+		{
+			name:     "missing CSP",
+			fileName: "test.yaml",
+			data:     "version: v1",
+			wantErr:  "csp is required",
+		},
+		{
+			name:     "missing rule ID",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - operator: contains
+    spec: {keys: [A]}
+    image: {keys: [B]}`,
+			wantErr: "rules[0]: id is required",
+		},
+		{
+			name:     "unsupported operator",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - id: r1
+    operator: equals
+    spec: {keys: [A]}
+    image: {keys: [B]}`,
+			wantErr: `unsupported operator: "equals"`,
+		},
+		{
+			name:     "missing pairs",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - id: r1
+    operator: deny
+    spec: {keys: [A]}
+    image: {keys: [B]}`,
+			wantErr: "pairs are required for operator deny",
+		},
+		{
+			name:     "unsupported onMissing",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - id: r1
+    operator: contains
+    onMissing: skip
+    spec: {keys: [A]}
+    image: {keys: [B]}`,
+			wantErr: `unsupported onMissing: "skip"`,
+		},
+		{
+			name:     "missing detail keys",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - id: r1
+    operator: contains
+    spec: {keys: [A]}
+    image: {field: detail}`,
+			wantErr: "rule r1: image: keys are required",
+		},
+		{
+			name:     "unsupported field",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - id: r1
+    operator: contains
+    spec: {field: label}
+    image: {keys: [B]}`,
+			wantErr: `rule r1: spec: unsupported field: "label"`,
+		},
+		{
+			name:     "invalid pattern",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - id: r1
+    operator: contains
+    spec: {keys: [A], pattern: "("}
+    image: {keys: [B]}`,
+			wantErr: "rule r1: spec: invalid pattern",
+		},
+		{
+			name:     "invalid map in firstOf",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - id: r1
+    operator: contains
+    spec: {keys: [A]}
+    image:
+      firstOf:
+        - keys: [B]
+        - keys: [C]
+          map: [{match: "[", value: x}]`,
+			wantErr: "rule r1: image: firstOf[1]: map[0]: invalid match",
+		},
+		{
+			name:     "unsupported unless target",
+			fileName: "test.yaml",
+			data: `version: v1
+csp: test
+rules:
+  - id: r1
+    operator: contains
+    spec: {keys: [A]}
+    image: {keys: [B]}
+    unless:
+      - target: both
+        source: {keys: [C]}
+        values: [x]`,
+			wantErr: `rule r1: unless[0]: unsupported target: "both"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuleSet(tt.fileName, []byte(tt.data))
+			if err == nil {
+				t.Fatalf("ParseRuleSet() succeeded, want error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRuleSet() error = %q, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRuleSet(t *testing.T) {
+	data := `{
+  "version": "v1",
+  "csp": "TEST",
+  "rules": [
+    {"id": "r1", "operator": "contains", "spec": {"keys": ["A"]}, "image": {"keys": ["B"]}, "aliases": {"UEFI-Preferred": ["uefi"]}}
+  ]
+}`
+	ruleSet, err := ParseRuleSet("test.json", []byte(data))
+	if err != nil {
+		t.Fatalf("ParseRuleSet failed: %v", err)
+	}
+	if ruleSet.Csp != "test" {
+		t.Errorf("csp = %q, want %q", ruleSet.Csp, "test")
+	}
+	if got := ruleSet.Rules[0].Reason; got != ReasonUnknown {
+		t.Errorf("default reason = %q, want %q", got, ReasonUnknown)
+	}
+	if got := ruleSet.Rules[0].Aliases; !reflect.DeepEqual(got, map[string][]string{"uefi-preferred": {"uefi"}}) {
+		t.Errorf("aliases = %v, want lowercase keys", got)
+	}
+}
+
+func TestLoadRuleSetsFromDir(t *testing.T) {
+	const validRuleSet = `version: v1
+csp: %s
+rules:
+  - id: r1
+    operator: contains
+    spec: {keys: [A]}
+    image: {keys: [B]}
+`
+	t.Cleanup(func() {
+		checkersMu.Lock()
+		defer checkersMu.Unlock()
+		delete(checkers, "test-a")
+		delete(checkers, "test-b")
+	})
+
+	writeFile := func(t *testing.T, dir, name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("an invalid file registers none", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "a.yaml", fmt.Sprintf(validRuleSet, "test-a"))
+		writeFile(t, dir, "b.yaml", "version: v2\ncsp: test-b\n")
+
+		loaded, err := LoadRuleSetsFromDir(dir)
+		if err == nil {
+			t.Fatal("LoadRuleSetsFromDir() succeeded with an invalid rule set")
+		}
+		if len(loaded) != 0 {
+			t.Errorf("loaded = %v, want none", loaded)
+		}
+		if _, ok := GetChecker("test-a"); ok {
+			t.Error("the valid rule set was registered although another one is invalid")
+		}
+	})
+
+	t.Run("valid files are registered", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "b.yml", fmt.Sprintf(validRuleSet, "test-b"))
+		writeFile(t, dir, "a.yaml", fmt.Sprintf(validRuleSet, "test-a"))
+		writeFile(t, dir, "README.md", "not a rule set")
+
+		loaded, err := LoadRuleSetsFromDir(dir)
+		if err != nil {
+			t.Fatalf("LoadRuleSetsFromDir failed: %v", err)
+		}
+		if want := []string{"test-a", "test-b"}; !reflect.DeepEqual(loaded, want) {
+			t.Errorf("loaded = %v, want %v", loaded, want)
+		}
+		for _, csp := range loaded {
+			if _, ok := GetChecker(csp); !ok {
+				t.Errorf("no checker registered for %s", csp)
+			}
+		}
+	})
+}
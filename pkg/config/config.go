@@ -22,16 +22,19 @@ type Config struct {
 }
 
 type BeetleConfig struct {
-	Root        string            `mapstructure:"root"`
-	Self        SelfConfig        `mapstructure:"self"`
-	API         ApiConfig         `mapstructure:"api"`
-	LKVStore    LkvStoreConfig    `mapstructure:"lkvstore"`
-	LogFile     LogfileConfig     `mapstructure:"logfile"`
-	LogLevel    string            `mapstructure:"loglevel"`
-	LogWriter   string            `mapstructure:"logwriter"`
-	Node        NodeConfig        `mapstructure:"node"`
-	AutoControl AutoControlConfig `mapstructure:"autocontrol"`
-	Tumblebug   TumblebugConfig   `mapstructure:"tumblebug"`
+	Root         string             `mapstructure:"root"`
+	Self         SelfConfig         `mapstructure:"self"`
+	API          ApiConfig          `mapstructure:"api"`
+	LKVStore     LkvStoreConfig     `mapstructure:"lkvstore"`
+	LogFile      LogfileConfig      `mapstructure:"logfile"`
+	LogLevel     string             `mapstructure:"loglevel"`
+	LogWriter    string             `mapstructure:"logwriter"`
+	Node         NodeConfig         `mapstructure:"node"`
+	AutoControl  AutoControlConfig  `mapstructure:"autocontrol"`
+	Tumblebug    TumblebugConfig    `mapstructure:"tumblebug"`
+	Compat       CompatConfig       `mapstructure:"compat"`
+	Policy       PolicyConfig       `mapstructure:"policy"`
+	Notification NotificationConfig `mapstructure:"notification"`
 }
 
 type SelfConfig struct {
@@ -73,6 +76,24 @@ type AutoControlConfig struct {
 	DurationMilliSec int `mapstructure:"duration_ms"`
 }
 
+type CompatConfig struct {
+	RulesPath string `mapstructure:"rulespath"` // Directory of additional compatibility rule sets (optional)
+}
+
+type PolicyConfig struct {
+	Path string `mapstructure:"path"` // Directory of migration policies (optional)
+}
+
+type NotificationConfig struct {
+	Webhook     WebhookConfig `mapstructure:"webhook"`
+	MaxAttempts int           `mapstructure:"maxattempts"` // Max delivery attempts of an event per webhook
+}
+
+type WebhookConfig struct {
+	Urls   string `mapstructure:"urls"`   // Comma-separated webhook URLs (no notification if empty)
+	Secret string `mapstructure:"secret"` // Secret to sign the events with HMAC-SHA256 (unsigned if empty)
+}
+
 type TumblebugConfig struct {
 	Endpoint string             `mapstructure:"endpoint"`
 	RestUrl  string             `mapstructure:"resturl"`
@@ -203,6 +224,11 @@ func bindEnvironmentVariables() {
 	viper.BindEnv("beetle.tumblebug.resturl", "BEETLE_TUMBLEBUG_REST_URL")
 	viper.BindEnv("beetle.tumblebug.api.username", "BEETLE_TUMBLEBUG_API_USERNAME")
 	viper.BindEnv("beetle.tumblebug.api.password", "BEETLE_TUMBLEBUG_API_PASSWORD")
+	viper.BindEnv("beetle.compat.rulespath", "BEETLE_COMPAT_RULESPATH")
+	viper.BindEnv("beetle.policy.path", "BEETLE_POLICY_PATH")
+	viper.BindEnv("beetle.notification.webhook.urls", "BEETLE_NOTIFICATION_WEBHOOK_URLS")
+	viper.BindEnv("beetle.notification.webhook.secret", "BEETLE_NOTIFICATION_WEBHOOK_SECRET")
+	viper.BindEnv("beetle.notification.maxattempts", "BEETLE_NOTIFICATION_MAXATTEMPTS")
 }
 
 // TODO: Implement security validation for authentication configuration
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import "strings"
+
+// Labels linking the migrated resources to their source and to the migration.
+// The source labels are set on the NodeGroups by the recommendation, and the migration labels are set
+// on all resources created by the migration. Summaries and reports correlate the VMs with the source servers by them.
+const (
+	LabelSourceMachineId  = "sourceMachineId"  // Machine ID of the source server
+	LabelSourceMachineIds = "sourceMachineIds" // Comma-separated machine IDs of the source servers (NodeGroup of multiple nodes)
+	LabelSourceHostname   = "sourceHostname"   // Hostname of the source server
+	LabelSourceHostnames  = "sourceHostnames"  // Comma-separated hostnames in the order of sourceMachineIds
+	LabelSourceBucket     = "sourceBucket"     // Name of the source bucket
+
+	LabelMigrationProject = "migrationProject" // ID of the migration project
+	LabelMigrationWave    = "migrationWave"    // Name of the migration wave (as "<wavePlan>/<wave>")
+	LabelMigratedAt       = "migratedAt"       // Migration time (RFC 3339, UTC)
+	LabelMigrationReqId   = "migrationReqId"   // Request ID of the migration
+)
+
+// MigrationLabelKeys are the keys of the labels describing the migration (not the source)
+var MigrationLabelKeys = []string{LabelMigrationProject, LabelMigrationWave, LabelMigratedAt, LabelMigrationReqId}
+
+// SourceMachineIds returns the source machine IDs in the labels
+func SourceMachineIds(label map[string]string) []string {
+	return splitLabel(label, LabelSourceMachineId, LabelSourceMachineIds)
+}
+
+// SourceHostnames returns the source hostnames in the labels (in the order of the source machine IDs)
+func SourceHostnames(label map[string]string) []string {
+	return splitLabel(label, LabelSourceHostname, LabelSourceHostnames)
+}
+
+// splitLabel returns the value of the single-value key, or the comma-separated values of the multi-value key
+func splitLabel(label map[string]string, singleKey, multiKey string) []string {
+	if value := strings.TrimSpace(label[singleKey]); value != "" {
+		return []string{value}
+	}
+	if strings.TrimSpace(label[multiKey]) == "" {
+		return nil
+	}
+	// Empty values are kept to keep the order
+	values := strings.Split(label[multiKey], ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
@@ -15,6 +15,7 @@ package common
 
 import (
 	"fmt"
+	"strings"
 
 	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
 )
@@ -43,6 +44,30 @@ func ApplyNameSeed(infra cloudmodel.RecommendedInfra, seed string) cloudmodel.Re
 	for i, subnet := range infra.TargetVNet.SubnetInfoList {
 		result.TargetVNet.SubnetInfoList[i].Name = ComposeName(subnet.Name, seed)
 	}
+	result.AdditionalVNetList = make([]cloudmodel.VNetReq, len(infra.AdditionalVNetList))
+	for i, vNet := range infra.AdditionalVNetList {
+		result.AdditionalVNetList[i] = vNet
+		result.AdditionalVNetList[i].Name = ComposeName(vNet.Name, seed)
+		result.AdditionalVNetList[i].SubnetInfoList = make([]cloudmodel.SubnetReq, len(vNet.SubnetInfoList))
+		for j, subnet := range vNet.SubnetInfoList {
+			result.AdditionalVNetList[i].SubnetInfoList[j] = subnet
+			result.AdditionalVNetList[i].SubnetInfoList[j].Name = ComposeName(subnet.Name, seed)
+		}
+	}
+	if infra.CidrPlan != nil {
+		cidrPlan := *infra.CidrPlan
+		cidrPlan.VNets = make([]cloudmodel.VNetCidrPlan, len(infra.CidrPlan.VNets))
+		for i, vNetPlan := range infra.CidrPlan.VNets {
+			cidrPlan.VNets[i] = vNetPlan
+			cidrPlan.VNets[i].VNetName = ComposeName(vNetPlan.VNetName, seed)
+			cidrPlan.VNets[i].Subnets = make([]cloudmodel.SubnetCidrPlan, len(vNetPlan.Subnets))
+			for j, subnetPlan := range vNetPlan.Subnets {
+				cidrPlan.VNets[i].Subnets[j] = subnetPlan
+				cidrPlan.VNets[i].Subnets[j].SubnetName = ComposeName(subnetPlan.SubnetName, seed)
+			}
+		}
+		result.CidrPlan = &cidrPlan
+	}
 
 	// 2. Update SSH Key
 	result.TargetSshKey.Name = ComposeName(infra.TargetSshKey.Name, seed)
@@ -51,8 +76,8 @@ func ApplyNameSeed(infra cloudmodel.RecommendedInfra, seed string) cloudmodel.Re
 	for i, sg := range infra.TargetSecurityGroupList {
 		result.TargetSecurityGroupList[i].Name = ComposeName(sg.Name, seed)
 		// Update reference to VNetId if it's a relative name
-		if sg.VNetId == infra.TargetVNet.Name {
-			result.TargetSecurityGroupList[i].VNetId = result.TargetVNet.Name
+		if isPlannedVNet(infra, sg.VNetId) {
+			result.TargetSecurityGroupList[i].VNetId = ComposeName(sg.VNetId, seed)
 		}
 	}
 
@@ -75,6 +100,19 @@ func ApplyNameSeed(infra cloudmodel.RecommendedInfra, seed string) cloudmodel.Re
 	return result
 }
 
+// isPlannedVNet reports whether the given name is the target vNet or one of the additional vNets in the model.
+func isPlannedVNet(infra cloudmodel.RecommendedInfra, vNetName string) bool {
+	if infra.TargetVNet.Name == vNetName {
+		return true
+	}
+	for _, vNet := range infra.AdditionalVNetList {
+		if vNet.Name == vNetName {
+			return true
+		}
+	}
+	return false
+}
+
 // ResourceType constants follow the cb-tumblebug naming convention.
 // Ref: cb-tumblebug/src/core/model/common.go
 const (
@@ -103,6 +141,11 @@ func PropagateNameChange(infra cloudmodel.RecommendedInfra, resourceType, oldNam
 		if result.TargetVNet.Name == oldName {
 			result.TargetVNet.Name = newName
 		}
+		for i := range result.AdditionalVNetList {
+			if result.AdditionalVNetList[i].Name == oldName {
+				result.AdditionalVNetList[i].Name = newName
+			}
+		}
 		// 2. Propagate to SecurityGroups
 		for i := range result.TargetSecurityGroupList {
 			if result.TargetSecurityGroupList[i].VNetId == oldName {
@@ -123,6 +166,13 @@ func PropagateNameChange(infra cloudmodel.RecommendedInfra, resourceType, oldNam
 				result.TargetVNet.SubnetInfoList[i].Name = newName
 			}
 		}
+		for i := range result.AdditionalVNetList {
+			for j := range result.AdditionalVNetList[i].SubnetInfoList {
+				if result.AdditionalVNetList[i].SubnetInfoList[j].Name == oldName {
+					result.AdditionalVNetList[i].SubnetInfoList[j].Name = newName
+				}
+			}
+		}
 		// 2. Propagate to NodeGroups
 		for i := range result.TargetInfra.NodeGroups {
 			if result.TargetInfra.NodeGroups[i].SubnetId == oldName {
@@ -208,6 +258,55 @@ func IsValidNameSeed(seed string) (bool, string) {
 	return true, ""
 }
 
+// BucketNameSeedReserve is the room left in recommended bucket names for a nameSeed prefix
+// (up to 20 characters and a hyphen), so that late binding keeps the names within the CSP limits.
+const BucketNameSeedReserve = 21
+
+// bucketNameMaxLength holds the CSP-specific maximum bucket name lengths (default: 63).
+var bucketNameMaxLength = map[string]int{
+	"tencent": 38, // COS appends "-<APPID>" to the bucket name within 50 characters
+}
+
+// BucketNameMaxLength returns the maximum bucket name length of the CSP.
+func BucketNameMaxLength(csp string) int {
+	if maxLen, ok := bucketNameMaxLength[strings.ToLower(csp)]; ok {
+		return maxLen
+	}
+	return 63
+}
+
+// ComposeBucketName combines a bucket name and a seed.
+// Bucket names are lowercased since CSPs do not accept uppercase letters in bucket names.
+func ComposeBucketName(baseName, seed string) string {
+	return strings.ToLower(ComposeName(baseName, seed))
+}
+
+// IsValidBucketName checks if the name complies with the bucket naming rules of the CSP.
+// Rules: 3 to BucketNameMaxLength(csp) characters, lowercase alphanumeric and hyphens,
+// starts and ends with alphanumeric, no consecutive hyphens (Azure), and no "goog" prefix or "google" (GCP).
+func IsValidBucketName(csp, name string) (bool, string) {
+	csp = strings.ToLower(csp)
+	if maxLen := BucketNameMaxLength(csp); len(name) < 3 || len(name) > maxLen {
+		return false, fmt.Sprintf("bucket name length must be between 3 and %d characters on %s", maxLen, csp)
+	}
+	for i, r := range name {
+		isAlnum := (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+		if (i == 0 || i == len(name)-1) && !isAlnum {
+			return false, "bucket name must start and end with a lowercase letter or digit"
+		}
+		if !isAlnum && r != '-' {
+			return false, "bucket name contains invalid characters (only lowercase letters, digits and hyphens allowed)"
+		}
+	}
+	if csp == "azure" && strings.Contains(name, "--") {
+		return false, "bucket name must not contain consecutive hyphens on azure"
+	}
+	if csp == "gcp" && (strings.HasPrefix(name, "goog") || strings.Contains(name, "google")) {
+		return false, "bucket name must not start with \"goog\" or contain \"google\" on gcp"
+	}
+	return true, ""
+}
+
 // ValidateComposedNames checks all resource names and referential integrity.
 // This function expects names to already have NameSeed applied (if any).
 func ValidateComposedNames(infra cloudmodel.RecommendedInfra) (bool, string) {
@@ -224,6 +323,18 @@ func ValidateComposedNames(infra cloudmodel.RecommendedInfra) (bool, string) {
 		}
 	}
 
+	// Check additional VNets and their Subnets
+	for _, vNet := range infra.AdditionalVNetList {
+		if ok, detail := IsValidName(vNet.Name); !ok {
+			return false, fmt.Sprintf("VNet name [%s]: %s", vNet.Name, detail)
+		}
+		for _, subnet := range vNet.SubnetInfoList {
+			if ok, detail := IsValidName(subnet.Name); !ok {
+				return false, fmt.Sprintf("Subnet name [%s]: %s", subnet.Name, detail)
+			}
+		}
+	}
+
 	// Check SSH Key
 	if ok, detail := IsValidName(infra.TargetSshKey.Name); !ok {
 		return false, fmt.Sprintf("SSH Key name [%s]: %s", infra.TargetSshKey.Name, detail)
@@ -257,13 +368,16 @@ func ValidateComposedNames(infra cloudmodel.RecommendedInfra) (bool, string) {
 // ValidateReferentialIntegrity verifies that all internal references (IDs)
 // in the model point to resources that exist within the same model.
 func ValidateReferentialIntegrity(infra cloudmodel.RecommendedInfra) (bool, string) {
-	vnetName := infra.TargetVNet.Name
 	sshKeyName := infra.TargetSshKey.Name
 
-	// Map subnets for quick lookup
-	subnets := make(map[string]bool)
-	for _, s := range infra.TargetVNet.SubnetInfoList {
-		subnets[s.Name] = true
+	// Map VNets to their subnets for quick lookup
+	vnetSubnets := make(map[string]map[string]bool)
+	for _, vNet := range append([]cloudmodel.VNetReq{infra.TargetVNet}, infra.AdditionalVNetList...) {
+		subnets := make(map[string]bool)
+		for _, s := range vNet.SubnetInfoList {
+			subnets[s.Name] = true
+		}
+		vnetSubnets[vNet.Name] = subnets
 	}
 
 	// Map security groups for quick lookup
@@ -271,14 +385,15 @@ func ValidateReferentialIntegrity(infra cloudmodel.RecommendedInfra) (bool, stri
 	for _, sg := range infra.TargetSecurityGroupList {
 		sgs[sg.Name] = true
 		// Check SG -> VNet reference
-		if sg.VNetId != vnetName {
+		if _, ok := vnetSubnets[sg.VNetId]; !ok {
 			return false, fmt.Sprintf("Security Group [%s] refers to non-existent VNet [%s]", sg.Name, sg.VNetId)
 		}
 	}
 
 	// Check Infra NodeGroups references
 	for _, ng := range infra.TargetInfra.NodeGroups {
-		if ng.VNetId != vnetName {
+		subnets, ok := vnetSubnets[ng.VNetId]
+		if !ok {
 			return false, fmt.Sprintf("NodeGroup [%s] refers to non-existent VNet [%s]", ng.Name, ng.VNetId)
 		}
 		if !subnets[ng.SubnetId] {
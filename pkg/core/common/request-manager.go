@@ -50,12 +50,13 @@ type RequestInfo struct {
 
 // RequestDetails contains detailed information about an HTTP request and its processing status.
 type RequestDetails struct {
-	StartTime     time.Time   `json:"startTime"`     // The time when the request was received by the server.
-	EndTime       time.Time   `json:"endTime"`       // The time when the request was fully processed.
-	Status        string      `json:"status"`        // The current status of the request (e.g., "Handling", "Error", "Success").
-	RequestInfo   RequestInfo `json:"requestInfo"`   // Extracted information about the request.
-	ResponseData  any         `json:"responseData"`  // The data sent back in response to the request.
-	ErrorResponse string      `json:"errorResponse"` // A message describing any error that occurred during request processing.
+	StartTime     time.Time      `json:"startTime"`          // The time when the request was received by the server.
+	EndTime       time.Time      `json:"endTime"`            // The time when the request was fully processed.
+	Status        string         `json:"status"`             // The current status of the request (e.g., "Handling", "Error", "Success").
+	RequestInfo   RequestInfo    `json:"requestInfo"`        // Extracted information about the request.
+	ResponseData  any            `json:"responseData"`       // The data sent back in response to the request.
+	ErrorResponse string         `json:"errorResponse"`      // A message describing any error that occurred during request processing.
+	Progress      []ProgressInfo `json:"progress,omitempty"` // Step-level progress of a long-running (e.g., asynchronous) request.
 }
 
 // ProgressInfo contains the progress information of a request.
@@ -97,10 +98,85 @@ type RequestFilter struct {
 	Since  time.Time // Filter by start time (requests after this time)
 }
 
+// RequestStatusHandler is called when the status of a request changes (including a new request in "Handling").
+type RequestStatusHandler func(reqID string, details RequestDetails)
+
+var (
+	requestHandlersMutex  sync.RWMutex
+	requestStatusHandlers []RequestStatusHandler
+
+	// requestMutex serializes the updates of the request details (e.g., the progress appended by a watcher
+	// and the final status set by an asynchronous job), so that no update is lost
+	requestMutex sync.Mutex
+)
+
+// OnRequestStatusChanged registers a handler called on the status transitions of the requests
+// (Handling → Success/Error). The handlers are called synchronously, so they must not block.
+func OnRequestStatusChanged(handler RequestStatusHandler) {
+	requestHandlersMutex.Lock()
+	defer requestHandlersMutex.Unlock()
+	requestStatusHandlers = append(requestStatusHandlers, handler)
+}
+
 // SetRequest stores request details with the given request ID.
 // It uses lkvstore for persistence across server restarts.
+// The status handlers are called if the status of the request changes.
+// To update the stored details, use UpdateRequest instead, so that concurrent updates are not lost.
 func SetRequest(reqID string, details RequestDetails) error {
-	return lkvstore.Put(requestKeyPrefix+reqID, details)
+	requestMutex.Lock()
+	changed, err := putRequest(reqID, details)
+	requestMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		notifyRequestStatus(reqID, details)
+	}
+	return nil
+}
+
+// UpdateRequest applies the update to the stored request details and stores them, atomically
+// with respect to the other updates of the requests. The status handlers are called if the status changes.
+func UpdateRequest(reqID string, update func(details *RequestDetails)) error {
+	requestMutex.Lock()
+	details, ok := GetRequest(reqID)
+	if !ok {
+		requestMutex.Unlock()
+		return fmt.Errorf("request (%s) not found", reqID)
+	}
+	update(&details)
+	changed, err := putRequest(reqID, details)
+	requestMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		notifyRequestStatus(reqID, details)
+	}
+	return nil
+}
+
+// putRequest stores the request details, and returns whether the status is changed (the caller holds requestMutex).
+func putRequest(reqID string, details RequestDetails) (bool, error) {
+	prev, exists := GetRequest(reqID)
+
+	if err := lkvstore.Put(requestKeyPrefix+reqID, details); err != nil {
+		return false, err
+	}
+	return !exists || !strings.EqualFold(prev.Status, details.Status), nil
+}
+
+// notifyRequestStatus calls the status handlers (out of requestMutex, as the handlers may take a while).
+func notifyRequestStatus(reqID string, details RequestDetails) {
+	requestHandlersMutex.RLock()
+	handlers := requestStatusHandlers
+	requestHandlersMutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(reqID, details)
+	}
 }
 
 // GetRequest retrieves request details by request ID.
@@ -326,31 +402,41 @@ func ExtractRequestInfo(r *http.Request) RequestInfo {
 	}
 }
 
-// UpdateRequestProgress updates the handling status of the request.
-func UpdateRequestProgress(reqID string, progressData any) {
-	details, ok := GetRequest(reqID)
-	if !ok {
-		log.Warn().Str("reqID", reqID).Msg("Request ID not found")
-		return
+// AppendRequestProgress appends a step-level progress to the request.
+// The progress is kept apart from ResponseData, so it is preserved when the request completes.
+func AppendRequestProgress(reqID string, progress ProgressInfo) {
+	if progress.Time.IsZero() {
+		progress.Time = time.Now()
 	}
 
-	var responseData []any
-	if details.ResponseData != nil {
-		// Safe type assertion for existing ResponseData
-		if existing, ok := details.ResponseData.([]any); ok {
-			responseData = existing
-		} else {
-			// If ResponseData is not []any, wrap existing data in a slice
-			responseData = []any{details.ResponseData}
-		}
+	err := UpdateRequest(reqID, func(details *RequestDetails) {
+		details.Progress = append(details.Progress, progress)
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("reqID", reqID).Msg("Failed to append request progress")
 	}
+}
 
-	// Append the new progressData to the existing ResponseData
-	responseData = append(responseData, progressData)
-	details.ResponseData = responseData
+// UpdateRequestProgress updates the handling status of the request.
+func UpdateRequestProgress(reqID string, progressData any) {
+	err := UpdateRequest(reqID, func(details *RequestDetails) {
+		var responseData []any
+		if details.ResponseData != nil {
+			// Safe type assertion for existing ResponseData
+			if existing, ok := details.ResponseData.([]any); ok {
+				responseData = existing
+			} else {
+				// If ResponseData is not []any, wrap existing data in a slice
+				responseData = []any{details.ResponseData}
+			}
+		}
 
-	if err := SetRequest(reqID, details); err != nil {
-		log.Error().Err(err).Str("reqID", reqID).Msg("Failed to update request progress")
+		// Append the new progressData to the existing ResponseData
+		responseData = append(responseData, progressData)
+		details.ResponseData = responseData
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("reqID", reqID).Msg("Failed to update request progress")
 	}
 }
 
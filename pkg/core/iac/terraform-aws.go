@@ -0,0 +1,302 @@
+package iac
+
+import (
+	"fmt"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+)
+
+// ============================================================================
+// AWS (hashicorp/aws)
+// vNet -> aws_vpc (with an internet gateway), subnet -> aws_subnet,
+// security group -> aws_security_group, SSH key -> aws_key_pair,
+// node group -> aws_instance (count), NLB -> aws_lb (network)
+// ============================================================================
+
+// awsRootDiskTypes are the EBS volume types accepted as the root disk type
+var awsRootDiskTypes = map[string]bool{
+	"standard": true, "gp2": true, "gp3": true, "io1": true, "io2": true, "sc1": true, "st1": true,
+}
+
+func renderAws(r *terraformRenderer) error {
+	infra := r.infra
+
+	// versions.tf
+	r.versions.WriteString(`terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+
+provider "aws" {
+  region = var.region
+}
+`)
+
+	// variables.tf
+	r.variable(tfVariable{Name: "region", Description: "AWS region", Type: "string", Default: hclString(infra.TargetCloud.Region)})
+	r.commonVariables()
+
+	// vNets and subnets
+	for _, vNet := range r.vNets() {
+		v := tfLocalName(vNet.Name)
+		r.printf("# vNet: %s\n", vNet.Name)
+		r.printf("resource \"aws_vpc\" %s {\n", hclString(v))
+		r.printf("  cidr_block           = %s\n", hclString(vNet.CidrBlock))
+		r.printf("  enable_dns_support   = true\n")
+		r.printf("  enable_dns_hostnames = true\n\n")
+		r.printf("  tags = {\n    Name = %s\n  }\n}\n\n", hclString(vNet.Name))
+
+		r.printf("resource \"aws_internet_gateway\" %s {\n", hclString(v))
+		r.printf("  vpc_id = aws_vpc.%s.id\n\n", v)
+		r.printf("  tags = {\n    Name = %s\n  }\n}\n\n", hclString(vNet.Name+"-igw"))
+
+		r.printf("resource \"aws_route_table\" %s {\n", hclString(v))
+		r.printf("  vpc_id = aws_vpc.%s.id\n\n", v)
+		r.printf("  route {\n    cidr_block = \"0.0.0.0/0\"\n    gateway_id = aws_internet_gateway.%s.id\n  }\n\n", v)
+		r.printf("  tags = {\n    Name = %s\n  }\n}\n\n", hclString(vNet.Name+"-rt"))
+
+		for _, subnet := range vNet.SubnetInfoList {
+			s := tfLocalName(vNet.Name, subnet.Name)
+			r.printf("resource \"aws_subnet\" %s {\n", hclString(s))
+			r.printf("  vpc_id                  = aws_vpc.%s.id\n", v)
+			r.printf("  cidr_block              = %s\n", hclString(subnet.IPv4_CIDR))
+			if subnet.Zone != "" {
+				r.printf("  availability_zone       = %s\n", hclString(subnet.Zone))
+			}
+			r.printf("  map_public_ip_on_launch = true\n\n")
+			r.printf("  tags = {\n    Name = %s\n  }\n}\n\n", hclString(subnet.Name))
+
+			r.printf("resource \"aws_route_table_association\" %s {\n", hclString(s))
+			r.printf("  subnet_id      = aws_subnet.%s.id\n", s)
+			r.printf("  route_table_id = aws_route_table.%s.id\n}\n\n", v)
+		}
+	}
+
+	// Security groups
+	for _, sg := range infra.TargetSecurityGroupList {
+		if err := renderAwsSecurityGroup(r, sg); err != nil {
+			return err
+		}
+	}
+
+	// SSH key
+	keyName := infra.TargetSshKey.Name
+	if keyName == "" {
+		keyName = infra.TargetInfra.Name + "-key"
+	}
+	k := tfLocalName(keyName)
+	r.printf("# SSH key: the public key is given by the variable\n")
+	r.printf("resource \"aws_key_pair\" %s {\n", hclString(k))
+	r.printf("  key_name   = %s\n", hclString(keyName))
+	r.printf("  public_key = var.ssh_public_key\n}\n\n")
+
+	// Node groups
+	for _, ng := range infra.TargetInfra.NodeGroups {
+		if err := r.validateNodeGroup(ng); err != nil {
+			return err
+		}
+		n := tfLocalName(ng.Name)
+		img, _ := r.image(ng)
+
+		sgRefs := make([]string, len(ng.SecurityGroupIds))
+		for i, sgId := range ng.SecurityGroupIds {
+			sgRefs[i] = fmt.Sprintf("aws_security_group.%s.id", tfLocalName(sgId))
+		}
+
+		r.printf("# Node group: %s\n", ng.Name)
+		r.printf("resource \"aws_instance\" %s {\n", hclString(n))
+		r.printf("  count = %d\n\n", nodeCount(ng))
+		r.printf("  ami                    = %s\n", hclString(img))
+		r.printf("  instance_type          = %s\n", hclString(r.specName(ng)))
+		r.printf("  subnet_id              = aws_subnet.%s.id\n", tfLocalName(ng.VNetId, ng.SubnetId))
+		r.printf("  vpc_security_group_ids = [%s]\n", strings.Join(sgRefs, ", "))
+		r.printf("  key_name               = aws_key_pair.%s.key_name\n", k)
+
+		diskType := strings.ToLower(ng.RootDiskType)
+		if awsRootDiskTypes[diskType] || ng.RootDiskSize > 0 {
+			r.printf("\n  root_block_device {\n")
+			if awsRootDiskTypes[diskType] {
+				r.printf("    volume_type = %s\n", hclString(diskType))
+			}
+			if ng.RootDiskSize > 0 {
+				r.printf("    volume_size = %d\n", ng.RootDiskSize)
+			}
+			r.printf("  }\n")
+		}
+		r.printf("\n  tags = {\n    Name = %s\n  }\n}\n\n", hclNodeName(ng.Name, ""))
+
+		r.output(tfOutput{
+			Name:        n + "_public_ips",
+			Description: fmt.Sprintf("Public IPs of the nodes of %s", ng.Name),
+			Value:       fmt.Sprintf("aws_instance.%s[*].public_ip", n),
+		})
+		r.output(tfOutput{
+			Name:        n + "_private_ips",
+			Description: fmt.Sprintf("Private IPs of the nodes of %s", ng.Name),
+			Value:       fmt.Sprintf("aws_instance.%s[*].private_ip", n),
+		})
+	}
+
+	// NLBs
+	for _, nlb := range infra.TargetNlbList {
+		if err := renderAwsNlb(r, nlb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderAwsSecurityGroup renders a security group with inline rules.
+// Terraform removes the default allow-all egress rule of AWS, so it is added when no outbound rule is given.
+func renderAwsSecurityGroup(r *terraformRenderer, sg cloudmodel.SecurityGroupReq) error {
+	vNetName := sg.VNetId
+	if vNetName == "" {
+		vNetName = r.infra.TargetVNet.Name
+	}
+	if !r.hasVNet(vNetName) {
+		return fmt.Errorf("security group '%s': vNet '%s' not found", sg.Name, vNetName)
+	}
+
+	r.printf("# Security group: %s\n", sg.Name)
+	r.printf("resource \"aws_security_group\" %s {\n", hclString(tfLocalName(sg.Name)))
+	r.printf("  name        = %s\n", hclString(sg.Name))
+	r.printf("  description = %s\n", hclString(defaultString(sg.Description, "Security group "+sg.Name)))
+	r.printf("  vpc_id      = aws_vpc.%s.id\n", tfLocalName(vNetName))
+
+	hasEgress := false
+	for _, rule := range firewallRules(sg) {
+		block := "ingress"
+		if strings.EqualFold(rule.Direction, "outbound") {
+			block = "egress"
+			hasEgress = true
+		}
+
+		cidr := defaultString(rule.CIDR, "0.0.0.0/0")
+		cidrAttr := "cidr_blocks"
+		if isIPv6Cidr(cidr) {
+			cidrAttr = "ipv6_cidr_blocks"
+		}
+		w := len(cidrAttr) // Width to align the attributes as terraform fmt does
+
+		protocol := strings.ToLower(rule.Protocol)
+		switch protocol {
+		case "all", "-1", "*":
+			r.printf("\n  %s {\n    %-*s = \"-1\"\n    %-*s = 0\n    %-*s = 0\n    %s = [%s]\n  }\n",
+				block, w, "protocol", w, "from_port", w, "to_port", cidrAttr, hclString(cidr))
+			continue
+		case "icmp":
+			r.printf("\n  %s {\n    %-*s = \"icmp\"\n    %-*s = -1\n    %-*s = -1\n    %s = [%s]\n  }\n",
+				block, w, "protocol", w, "from_port", w, "to_port", cidrAttr, hclString(cidr))
+			continue
+		}
+
+		ranges, err := parsePortRanges(rule.Ports)
+		if err != nil {
+			return fmt.Errorf("security group '%s': %w", sg.Name, err)
+		}
+		for _, p := range ranges {
+			r.printf("\n  %s {\n    %-*s = %s\n    %-*s = %d\n    %-*s = %d\n    %s = [%s]\n  }\n",
+				block, w, "protocol", hclString(protocol), w, "from_port", p.From, w, "to_port", p.To, cidrAttr, hclString(cidr))
+		}
+	}
+	if !hasEgress {
+		r.printf("\n  egress {\n    protocol    = \"-1\"\n    from_port   = 0\n    to_port     = 0\n    cidr_blocks = [\"0.0.0.0/0\"]\n  }\n")
+	}
+
+	r.printf("\n  tags = {\n    Name = %s\n  }\n}\n\n", hclString(sg.Name))
+	return nil
+}
+
+// renderAwsNlb renders a network load balancer, its target group and listener.
+func renderAwsNlb(r *terraformRenderer, nlb cloudmodel.NlbReq) error {
+	ngs, err := r.nlbNodeGroups(nlb)
+	if err != nil {
+		return err
+	}
+
+	name := nlbName(nlb)
+	l := tfLocalName(name)
+
+	// An NLB takes at most one subnet per availability zone
+	var subnetRefs []string
+	zones := map[string]bool{}
+	for _, ng := range ngs {
+		subnet, err := r.findSubnet(ng.VNetId, ng.SubnetId)
+		if err != nil {
+			return fmt.Errorf("NLB '%s': %w", name, err)
+		}
+		if zones[subnet.Zone] {
+			continue
+		}
+		zones[subnet.Zone] = true
+		subnetRefs = append(subnetRefs, fmt.Sprintf("aws_subnet.%s.id", tfLocalName(ng.VNetId, ng.SubnetId)))
+	}
+
+	// Network load balancers forward TCP/UDP only
+	listenerProtocol := strings.ToUpper(defaultString(nlb.Listener.Protocol, "TCP"))
+	targetProtocol := strings.ToUpper(defaultString(nlb.TargetGroup.Protocol, listenerProtocol))
+	if targetProtocol != "UDP" {
+		targetProtocol = "TCP"
+	}
+	if listenerProtocol != "UDP" {
+		listenerProtocol = "TCP"
+	}
+	listenerPort, targetPort, err := nlbPorts(nlb)
+	if err != nil {
+		return err
+	}
+	threshold := clamp(nlb.HealthChecker.Threshold, 3, 2, 10)
+
+	r.printf("# NLB: %s\n", name)
+	r.printf("resource \"aws_lb\" %s {\n", hclString(l))
+	r.printf("  name               = %s\n", hclString(truncateName(name, 32)))
+	r.printf("  load_balancer_type = \"network\"\n")
+	r.printf("  internal           = %t\n", strings.EqualFold(nlb.Type, "INTERNAL"))
+	r.printf("  subnets            = [%s]\n}\n\n", strings.Join(subnetRefs, ", "))
+
+	r.printf("resource \"aws_lb_target_group\" %s {\n", hclString(l))
+	r.printf("  name     = %s\n", hclString(truncateName(name, 32)))
+	r.printf("  port     = %d\n", targetPort)
+	r.printf("  protocol = %s\n", hclString(targetProtocol))
+	r.printf("  vpc_id   = aws_vpc.%s.id\n\n", tfLocalName(ngs[0].VNetId))
+	r.printf("  health_check {\n")
+	r.printf("    protocol            = \"TCP\"\n")
+	r.printf("    port                = \"traffic-port\"\n")
+	r.printf("    interval            = %d\n", clamp(nlb.HealthChecker.Interval, 10, 5, 300))
+	r.printf("    healthy_threshold   = %d\n", threshold)
+	r.printf("    unhealthy_threshold = %d\n", threshold)
+	r.printf("  }\n}\n\n")
+
+	for _, ng := range ngs {
+		n := tfLocalName(ng.Name)
+		r.printf("resource \"aws_lb_target_group_attachment\" %s {\n", hclString(tfLocalName(name, ng.Name)))
+		r.printf("  count = length(aws_instance.%s)\n\n", n)
+		r.printf("  target_group_arn = aws_lb_target_group.%s.arn\n", l)
+		r.printf("  target_id        = aws_instance.%s[count.index].id\n", n)
+		r.printf("  port             = %d\n}\n\n", targetPort)
+	}
+
+	r.printf("resource \"aws_lb_listener\" %s {\n", hclString(l))
+	r.printf("  load_balancer_arn = aws_lb.%s.arn\n", l)
+	r.printf("  port              = %d\n", listenerPort)
+	r.printf("  protocol          = %s\n\n", hclString(listenerProtocol))
+	r.printf("  default_action {\n")
+	r.printf("    type             = \"forward\"\n")
+	r.printf("    target_group_arn = aws_lb_target_group.%s.arn\n", l)
+	r.printf("  }\n}\n\n")
+
+	r.output(tfOutput{
+		Name:        l + "_dns_name",
+		Description: fmt.Sprintf("DNS name of the NLB %s", name),
+		Value:       fmt.Sprintf("aws_lb.%s.dns_name", l),
+	})
+	return nil
+}
@@ -0,0 +1,395 @@
+package iac
+
+import (
+	"fmt"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+)
+
+// ============================================================================
+// Azure (hashicorp/azurerm)
+// All resources are placed in a resource group given by the variable.
+// vNet -> azurerm_virtual_network, subnet -> azurerm_subnet,
+// security group -> azurerm_network_security_group (associated to the NICs),
+// node group -> azurerm_public_ip + azurerm_network_interface + azurerm_{linux|windows}_virtual_machine (count),
+// NLB -> azurerm_lb (Standard)
+// ============================================================================
+
+// azureDiskTypes map the root disk types of CB-Tumblebug to the storage account types of the managed disks
+var azureDiskTypes = map[string]string{
+	"premiumssd":      "Premium_LRS",
+	"premium_lrs":     "Premium_LRS",
+	"standardssd":     "StandardSSD_LRS",
+	"standardssd_lrs": "StandardSSD_LRS",
+	"standardhdd":     "Standard_LRS",
+	"standard_lrs":    "Standard_LRS",
+}
+
+// azureNsgPriorityStart and azureNsgPriorityEnd are the priority range of the NSG rules
+const (
+	azureNsgPriorityStart = 100
+	azureNsgPriorityEnd   = 4096
+)
+
+func renderAzure(r *terraformRenderer) error {
+	infra := r.infra
+
+	// versions.tf
+	r.versions.WriteString(`terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    azurerm = {
+      source  = "hashicorp/azurerm"
+      version = "~> 4.0"
+    }
+  }
+}
+
+provider "azurerm" {
+  features {}
+  subscription_id = var.subscription_id
+}
+`)
+
+	// variables.tf
+	r.variable(tfVariable{Name: "subscription_id", Description: "Azure subscription ID", Type: "string", Sensitive: true})
+	r.variable(tfVariable{Name: "location", Description: "Azure location (region)", Type: "string", Default: hclString(infra.TargetCloud.Region)})
+	r.variable(tfVariable{Name: "resource_group_name", Description: "Name of the resource group to create", Type: "string", Default: hclString(infra.TargetInfra.Name + "-rg")})
+	r.variable(tfVariable{Name: "admin_username", Description: "Admin user name of the nodes", Type: "string", Default: hclString(r.nodeUserName())})
+	r.commonVariables()
+
+	r.printf("resource \"azurerm_resource_group\" \"main\" {\n")
+	r.printf("  name     = var.resource_group_name\n")
+	r.printf("  location = var.location\n}\n\n")
+
+	// vNets and subnets
+	for _, vNet := range r.vNets() {
+		v := tfLocalName(vNet.Name)
+		r.printf("# vNet: %s\n", vNet.Name)
+		r.printf("resource \"azurerm_virtual_network\" %s {\n", hclString(v))
+		r.printf("  name                = %s\n", hclString(vNet.Name))
+		r.printf("  location            = azurerm_resource_group.main.location\n")
+		r.printf("  resource_group_name = azurerm_resource_group.main.name\n")
+		r.printf("  address_space       = [%s]\n}\n\n", hclString(vNet.CidrBlock))
+
+		for _, subnet := range vNet.SubnetInfoList {
+			r.printf("resource \"azurerm_subnet\" %s {\n", hclString(tfLocalName(vNet.Name, subnet.Name)))
+			r.printf("  name                 = %s\n", hclString(subnet.Name))
+			r.printf("  resource_group_name  = azurerm_resource_group.main.name\n")
+			r.printf("  virtual_network_name = azurerm_virtual_network.%s.name\n", v)
+			r.printf("  address_prefixes     = [%s]\n}\n\n", hclString(subnet.IPv4_CIDR))
+		}
+	}
+
+	// Security groups
+	for _, sg := range infra.TargetSecurityGroupList {
+		if err := renderAzureSecurityGroup(r, sg); err != nil {
+			return err
+		}
+	}
+
+	// Node groups
+	needsPassword := false
+	for _, ng := range infra.TargetInfra.NodeGroups {
+		if err := r.validateNodeGroup(ng); err != nil {
+			return err
+		}
+		isWindows, err := renderAzureNodeGroup(r, ng)
+		if err != nil {
+			return err
+		}
+		needsPassword = needsPassword || isWindows
+	}
+	if needsPassword {
+		r.variable(tfVariable{Name: "admin_password", Description: "Admin password of the Windows nodes", Type: "string", Sensitive: true})
+	}
+
+	// NLBs
+	for _, nlb := range infra.TargetNlbList {
+		if err := renderAzureNlb(r, nlb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderAzureSecurityGroup renders a network security group with a rule per port range.
+func renderAzureSecurityGroup(r *terraformRenderer, sg cloudmodel.SecurityGroupReq) error {
+	r.printf("# Security group: %s\n", sg.Name)
+	r.printf("resource \"azurerm_network_security_group\" %s {\n", hclString(tfLocalName(sg.Name)))
+	r.printf("  name                = %s\n", hclString(sg.Name))
+	r.printf("  location            = azurerm_resource_group.main.location\n")
+	r.printf("  resource_group_name = azurerm_resource_group.main.name\n")
+
+	priority := azureNsgPriorityStart
+	for i, rule := range firewallRules(sg) {
+		direction := "Inbound"
+		if strings.EqualFold(rule.Direction, "outbound") {
+			direction = "Outbound"
+		}
+		cidr := defaultString(rule.CIDR, "*")
+		if cidr == "0.0.0.0/0" {
+			cidr = "*"
+		}
+		sourcePrefix, destinationPrefix := cidr, "*"
+		if direction == "Outbound" {
+			sourcePrefix, destinationPrefix = "*", cidr
+		}
+
+		var protocol string
+		ports := []string{"*"}
+		switch strings.ToLower(rule.Protocol) {
+		case "all", "-1", "*":
+			protocol = "*"
+		case "icmp":
+			protocol = "Icmp"
+		case "tcp", "udp":
+			protocol = strings.ToUpper(rule.Protocol[:1]) + strings.ToLower(rule.Protocol[1:])
+			ranges, err := parsePortRanges(rule.Ports)
+			if err != nil {
+				return fmt.Errorf("security group '%s': %w", sg.Name, err)
+			}
+			if !isAllPorts(ranges) {
+				ports = ports[:0]
+				for _, p := range ranges {
+					ports = append(ports, p.String())
+				}
+			}
+		default:
+			return fmt.Errorf("security group '%s': unsupported protocol '%s'", sg.Name, rule.Protocol)
+		}
+
+		if priority > azureNsgPriorityEnd {
+			r.note("The security group '%s' has more rules than the NSG priorities allow; the rules from #%d are not rendered.", sg.Name, i+1)
+			break
+		}
+
+		r.printf("\n  security_rule {\n")
+		r.printf("    name                       = %s\n", hclString(fmt.Sprintf("%s-%d", strings.ToLower(direction), i+1)))
+		r.printf("    priority                   = %d\n", priority)
+		r.printf("    direction                  = %s\n", hclString(direction))
+		r.printf("    access                     = \"Allow\"\n")
+		r.printf("    protocol                   = %s\n", hclString(protocol))
+		r.printf("    source_port_range          = \"*\"\n")
+		if len(ports) == 1 {
+			r.printf("    destination_port_range     = %s\n", hclString(ports[0]))
+		} else {
+			r.printf("    destination_port_ranges    = %s\n", hclStringList(ports))
+		}
+		r.printf("    source_address_prefix      = %s\n", hclString(sourcePrefix))
+		r.printf("    destination_address_prefix = %s\n", hclString(destinationPrefix))
+		r.printf("  }\n")
+		priority += 10
+	}
+
+	r.printf("}\n\n")
+	return nil
+}
+
+// renderAzureNodeGroup renders the nodes of a node group and returns whether they are Windows nodes.
+// A NIC takes a single NSG, so the first security group of the node group is associated.
+func renderAzureNodeGroup(r *terraformRenderer, ng cloudmodel.CreateNodeGroupReq) (bool, error) {
+	n := tfLocalName(ng.Name)
+	img, isWindows := r.image(ng)
+	subnet, _ := r.findSubnet(ng.VNetId, ng.SubnetId)
+
+	imageRef, err := azureImageReference(img)
+	if err != nil {
+		return false, fmt.Errorf("node group '%s': %w", ng.Name, err)
+	}
+
+	r.printf("# Node group: %s\n", ng.Name)
+	r.printf("resource \"azurerm_public_ip\" %s {\n", hclString(n))
+	r.printf("  count = %d\n\n", nodeCount(ng))
+	r.printf("  name                = %s\n", hclNodeName(ng.Name, "-pip"))
+	r.printf("  location            = azurerm_resource_group.main.location\n")
+	r.printf("  resource_group_name = azurerm_resource_group.main.name\n")
+	r.printf("  allocation_method   = \"Static\"\n")
+	r.printf("  sku                 = \"Standard\"\n}\n\n")
+
+	r.printf("resource \"azurerm_network_interface\" %s {\n", hclString(n))
+	r.printf("  count = %d\n\n", nodeCount(ng))
+	r.printf("  name                = %s\n", hclNodeName(ng.Name, "-nic"))
+	r.printf("  location            = azurerm_resource_group.main.location\n")
+	r.printf("  resource_group_name = azurerm_resource_group.main.name\n\n")
+	r.printf("  ip_configuration {\n")
+	r.printf("    name                          = \"primary\"\n")
+	r.printf("    subnet_id                     = azurerm_subnet.%s.id\n", tfLocalName(ng.VNetId, ng.SubnetId))
+	r.printf("    private_ip_address_allocation = \"Dynamic\"\n")
+	r.printf("    public_ip_address_id          = azurerm_public_ip.%s[count.index].id\n", n)
+	r.printf("  }\n}\n\n")
+
+	if len(ng.SecurityGroupIds) > 0 {
+		if len(ng.SecurityGroupIds) > 1 {
+			r.note("Azure associates a single NSG with a NIC; only the first security group of the node group '%s' (%s) is associated.", ng.Name, ng.SecurityGroupIds[0])
+		}
+		r.printf("resource \"azurerm_network_interface_security_group_association\" %s {\n", hclString(n))
+		r.printf("  count = %d\n\n", nodeCount(ng))
+		r.printf("  network_interface_id      = azurerm_network_interface.%s[count.index].id\n", n)
+		r.printf("  network_security_group_id = azurerm_network_security_group.%s.id\n}\n\n", tfLocalName(ng.SecurityGroupIds[0]))
+	}
+
+	vmType := "azurerm_linux_virtual_machine"
+	if isWindows {
+		vmType = "azurerm_windows_virtual_machine"
+	}
+	r.printf("resource %s %s {\n", hclString(vmType), hclString(n))
+	r.printf("  count = %d\n\n", nodeCount(ng))
+	r.printf("  name                  = %s\n", hclNodeName(ng.Name, ""))
+	if isWindows {
+		// The computer name of Windows is limited to 15 characters
+		r.printf("  computer_name         = %s\n", hclNodeName(truncateName(ng.Name, 11), ""))
+	}
+	r.printf("  location              = azurerm_resource_group.main.location\n")
+	r.printf("  resource_group_name   = azurerm_resource_group.main.name\n")
+	r.printf("  size                  = %s\n", hclString(r.specName(ng)))
+	r.printf("  admin_username        = var.admin_username\n")
+	if isWindows {
+		r.printf("  admin_password        = var.admin_password\n")
+	}
+	r.printf("  network_interface_ids = [azurerm_network_interface.%s[count.index].id]\n", n)
+	if isAzureZone(subnet.Zone) {
+		r.printf("  zone                  = %s\n", hclString(subnet.Zone))
+	}
+	if !isWindows {
+		r.printf("\n  admin_ssh_key {\n")
+		r.printf("    username   = var.admin_username\n")
+		r.printf("    public_key = var.ssh_public_key\n")
+		r.printf("  }\n")
+	}
+
+	r.printf("\n  os_disk {\n")
+	r.printf("    caching              = \"ReadWrite\"\n")
+	storageType, ok := azureDiskTypes[strings.ToLower(ng.RootDiskType)]
+	if !ok {
+		storageType = "StandardSSD_LRS"
+	}
+	r.printf("    storage_account_type = %s\n", hclString(storageType))
+	if ng.RootDiskSize > 0 {
+		r.printf("    disk_size_gb         = %d\n", ng.RootDiskSize)
+	}
+	r.printf("  }\n\n")
+	r.printf("%s}\n\n", imageRef)
+
+	r.output(tfOutput{
+		Name:        n + "_public_ips",
+		Description: fmt.Sprintf("Public IPs of the nodes of %s", ng.Name),
+		Value:       fmt.Sprintf("azurerm_public_ip.%s[*].ip_address", n),
+	})
+	r.output(tfOutput{
+		Name:        n + "_private_ips",
+		Description: fmt.Sprintf("Private IPs of the nodes of %s", ng.Name),
+		Value:       fmt.Sprintf("azurerm_network_interface.%s[*].private_ip_address", n),
+	})
+	return isWindows, nil
+}
+
+// azureImageReference renders the image of a VM from a marketplace URN ("publisher:offer:sku:version") or an image resource ID.
+func azureImageReference(img string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(img), "/subscriptions/") {
+		return fmt.Sprintf("  source_image_id = %s\n", hclString(img)), nil
+	}
+
+	parts := strings.Split(img, ":")
+	if len(parts) != 4 {
+		return "", fmt.Errorf("cannot resolve the Azure image '%s' (expected a URN 'publisher:offer:sku:version' or an image resource ID)", img)
+	}
+	return fmt.Sprintf("  source_image_reference {\n    publisher = %s\n    offer     = %s\n    sku       = %s\n    version   = %s\n  }\n",
+		hclString(parts[0]), hclString(parts[1]), hclString(parts[2]), hclString(parts[3])), nil
+}
+
+// isAzureZone checks if the zone is an availability zone of Azure (i.e., "1", "2" or "3").
+func isAzureZone(zone string) bool {
+	return zone == "1" || zone == "2" || zone == "3"
+}
+
+// renderAzureNlb renders a Standard load balancer with a TCP probe and a load balancing rule.
+func renderAzureNlb(r *terraformRenderer, nlb cloudmodel.NlbReq) error {
+	ngs, err := r.nlbNodeGroups(nlb)
+	if err != nil {
+		return err
+	}
+	listenerPort, targetPort, err := nlbPorts(nlb)
+	if err != nil {
+		return err
+	}
+
+	name := nlbName(nlb)
+	l := tfLocalName(name)
+	isInternal := strings.EqualFold(nlb.Type, "INTERNAL")
+	protocol := "Tcp"
+	if strings.EqualFold(nlb.Listener.Protocol, "UDP") {
+		protocol = "Udp"
+	}
+
+	r.printf("# NLB: %s\n", name)
+	if !isInternal {
+		r.printf("resource \"azurerm_public_ip\" %s {\n", hclString(l))
+		r.printf("  name                = %s\n", hclString(name+"-pip"))
+		r.printf("  location            = azurerm_resource_group.main.location\n")
+		r.printf("  resource_group_name = azurerm_resource_group.main.name\n")
+		r.printf("  allocation_method   = \"Static\"\n")
+		r.printf("  sku                 = \"Standard\"\n}\n\n")
+	}
+
+	r.printf("resource \"azurerm_lb\" %s {\n", hclString(l))
+	r.printf("  name                = %s\n", hclString(name))
+	r.printf("  location            = azurerm_resource_group.main.location\n")
+	r.printf("  resource_group_name = azurerm_resource_group.main.name\n")
+	r.printf("  sku                 = \"Standard\"\n\n")
+	r.printf("  frontend_ip_configuration {\n")
+	if isInternal {
+		r.printf("    name                          = \"frontend\"\n")
+		r.printf("    subnet_id                     = azurerm_subnet.%s.id\n", tfLocalName(ngs[0].VNetId, ngs[0].SubnetId))
+		r.printf("    private_ip_address_allocation = \"Dynamic\"\n")
+	} else {
+		r.printf("    name                 = \"frontend\"\n")
+		r.printf("    public_ip_address_id = azurerm_public_ip.%s.id\n", l)
+	}
+	r.printf("  }\n}\n\n")
+
+	r.printf("resource \"azurerm_lb_backend_address_pool\" %s {\n", hclString(l))
+	r.printf("  name            = \"backend\"\n")
+	r.printf("  loadbalancer_id = azurerm_lb.%s.id\n}\n\n", l)
+
+	for _, ng := range ngs {
+		n := tfLocalName(ng.Name)
+		r.printf("resource \"azurerm_network_interface_backend_address_pool_association\" %s {\n", hclString(tfLocalName(name, ng.Name)))
+		r.printf("  count = length(azurerm_network_interface.%s)\n\n", n)
+		r.printf("  network_interface_id    = azurerm_network_interface.%s[count.index].id\n", n)
+		r.printf("  ip_configuration_name   = \"primary\"\n")
+		r.printf("  backend_address_pool_id = azurerm_lb_backend_address_pool.%s.id\n}\n\n", l)
+	}
+
+	r.printf("resource \"azurerm_lb_probe\" %s {\n", hclString(l))
+	r.printf("  name                = \"probe\"\n")
+	r.printf("  loadbalancer_id     = azurerm_lb.%s.id\n", l)
+	r.printf("  protocol            = \"Tcp\"\n")
+	r.printf("  port                = %d\n", targetPort)
+	r.printf("  interval_in_seconds = %d\n", clamp(nlb.HealthChecker.Interval, 10, 5, 2147483646))
+	r.printf("  number_of_probes    = %d\n}\n\n", clamp(nlb.HealthChecker.Threshold, 3, 1, 100))
+
+	r.printf("resource \"azurerm_lb_rule\" %s {\n", hclString(l))
+	r.printf("  name                           = \"rule\"\n")
+	r.printf("  loadbalancer_id                = azurerm_lb.%s.id\n", l)
+	r.printf("  protocol                       = %s\n", hclString(protocol))
+	r.printf("  frontend_port                  = %d\n", listenerPort)
+	r.printf("  backend_port                   = %d\n", targetPort)
+	r.printf("  frontend_ip_configuration_name = \"frontend\"\n")
+	r.printf("  backend_address_pool_ids       = [azurerm_lb_backend_address_pool.%s.id]\n", l)
+	r.printf("  probe_id                       = azurerm_lb_probe.%s.id\n}\n\n", l)
+
+	value := fmt.Sprintf("azurerm_public_ip.%s.ip_address", l)
+	if isInternal {
+		value = fmt.Sprintf("azurerm_lb.%s.frontend_ip_configuration[0].private_ip_address", l)
+	}
+	r.output(tfOutput{
+		Name:        l + "_ip",
+		Description: fmt.Sprintf("Frontend IP of the NLB %s", name),
+		Value:       value,
+	})
+	return nil
+}
@@ -0,0 +1,333 @@
+package iac
+
+import (
+	"fmt"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+)
+
+// ============================================================================
+// GCP (hashicorp/google)
+// vNet -> google_compute_network (custom mode), subnet -> google_compute_subnetwork,
+// security group -> google_compute_firewall (per rule, applied to the nodes by a network tag),
+// node group -> google_compute_instance (count),
+// NLB -> regional backend service + forwarding rule (passthrough network load balancer)
+// ============================================================================
+
+// gcpHealthCheckRanges are the source ranges of the health checks of Google Cloud load balancers
+var gcpHealthCheckRanges = []string{"35.191.0.0/16", "130.211.0.0/22"}
+
+func renderGcp(r *terraformRenderer) error {
+	infra := r.infra
+
+	// versions.tf
+	r.versions.WriteString(`terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    google = {
+      source  = "hashicorp/google"
+      version = "~> 6.0"
+    }
+  }
+}
+
+provider "google" {
+  project = var.project_id
+  region  = var.region
+}
+`)
+
+	// variables.tf
+	r.variable(tfVariable{Name: "project_id", Description: "GCP project ID", Type: "string"})
+	r.variable(tfVariable{Name: "region", Description: "GCP region", Type: "string", Default: hclString(infra.TargetCloud.Region)})
+	r.variable(tfVariable{Name: "zone", Description: "GCP zone of the nodes whose subnet has no zone", Type: "string", Default: hclString(infra.TargetCloud.Region + "-a")})
+	r.variable(tfVariable{Name: "ssh_username", Description: "SSH user name of the nodes", Type: "string", Default: hclString(r.nodeUserName())})
+	r.commonVariables()
+
+	// vNets and subnets
+	for _, vNet := range r.vNets() {
+		v := tfLocalName(vNet.Name)
+		r.printf("# vNet: %s (the CIDR block %s is covered by the subnets; VPC networks of GCP are global)\n", vNet.Name, vNet.CidrBlock)
+		r.printf("resource \"google_compute_network\" %s {\n", hclString(v))
+		r.printf("  name                    = %s\n", hclString(gcpName(vNet.Name)))
+		r.printf("  auto_create_subnetworks = false\n}\n\n")
+
+		for _, subnet := range vNet.SubnetInfoList {
+			r.printf("resource \"google_compute_subnetwork\" %s {\n", hclString(tfLocalName(vNet.Name, subnet.Name)))
+			r.printf("  name          = %s\n", hclString(gcpName(subnet.Name)))
+			r.printf("  network       = google_compute_network.%s.id\n", v)
+			r.printf("  region        = var.region\n")
+			r.printf("  ip_cidr_range = %s\n}\n\n", hclString(subnet.IPv4_CIDR))
+		}
+	}
+
+	// Security groups
+	for _, sg := range infra.TargetSecurityGroupList {
+		if err := renderGcpSecurityGroup(r, sg); err != nil {
+			return err
+		}
+	}
+
+	// Node groups
+	for _, ng := range infra.TargetInfra.NodeGroups {
+		if err := r.validateNodeGroup(ng); err != nil {
+			return err
+		}
+		renderGcpNodeGroup(r, ng)
+	}
+
+	// NLBs
+	for _, nlb := range infra.TargetNlbList {
+		if err := renderGcpNlb(r, nlb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderGcpSecurityGroup renders a firewall rule per rule of the security group.
+// The rules target the network tag of the security group, which is attached to the nodes using it.
+func renderGcpSecurityGroup(r *terraformRenderer, sg cloudmodel.SecurityGroupReq) error {
+	vNetName := sg.VNetId
+	if vNetName == "" {
+		vNetName = r.infra.TargetVNet.Name
+	}
+	if !r.hasVNet(vNetName) {
+		return fmt.Errorf("security group '%s': vNet '%s' not found", sg.Name, vNetName)
+	}
+
+	r.printf("# Security group: %s (network tag: %s)\n", sg.Name, gcpName(sg.Name))
+	for i, rule := range firewallRules(sg) {
+		direction := "INGRESS"
+		rangesAttr := "source_ranges     "
+		if strings.EqualFold(rule.Direction, "outbound") {
+			direction = "EGRESS"
+			rangesAttr = "destination_ranges"
+		}
+
+		allow := ""
+		switch protocol := strings.ToLower(rule.Protocol); protocol {
+		case "all", "-1", "*":
+			allow = "    protocol = \"all\"\n"
+		case "icmp":
+			allow = "    protocol = \"icmp\"\n"
+		case "tcp", "udp":
+			ranges, err := parsePortRanges(rule.Ports)
+			if err != nil {
+				return fmt.Errorf("security group '%s': %w", sg.Name, err)
+			}
+			allow = fmt.Sprintf("    protocol = %s\n", hclString(protocol))
+			if !isAllPorts(ranges) {
+				ports := make([]string, len(ranges))
+				for j, p := range ranges {
+					ports[j] = p.String()
+				}
+				allow += fmt.Sprintf("    ports    = %s\n", hclStringList(ports))
+			}
+		default:
+			return fmt.Errorf("security group '%s': unsupported protocol '%s'", sg.Name, rule.Protocol)
+		}
+
+		name := fmt.Sprintf("%s-%s-%d", sg.Name, strings.ToLower(direction[:2]), i+1)
+		r.printf("resource \"google_compute_firewall\" %s {\n", hclString(tfLocalName(name)))
+		r.printf("  name               = %s\n", hclString(gcpName(name)))
+		r.printf("  network            = google_compute_network.%s.id\n", tfLocalName(vNetName))
+		r.printf("  direction          = %s\n", hclString(direction))
+		r.printf("  %s = [%s]\n", rangesAttr, hclString(defaultString(rule.CIDR, "0.0.0.0/0")))
+		r.printf("  target_tags        = [%s]\n\n", hclString(gcpName(sg.Name)))
+		r.printf("  allow {\n%s  }\n}\n\n", allow)
+	}
+	return nil
+}
+
+// renderGcpNodeGroup renders the instances of a node group.
+func renderGcpNodeGroup(r *terraformRenderer, ng cloudmodel.CreateNodeGroupReq) {
+	n := tfLocalName(ng.Name)
+	img, isWindows := r.image(ng)
+
+	tags := make([]string, len(ng.SecurityGroupIds))
+	for i, sgId := range ng.SecurityGroupIds {
+		tags[i] = gcpName(sgId)
+	}
+
+	r.printf("# Node group: %s\n", ng.Name)
+	r.printf("resource \"google_compute_instance\" %s {\n", hclString(n))
+	r.printf("  count = %d\n\n", nodeCount(ng))
+	r.printf("  name         = %s\n", hclNodeName(truncateName(gcpName(ng.Name), 58), ""))
+	r.printf("  machine_type = %s\n", hclString(r.specName(ng)))
+	r.printf("  zone         = %s\n", gcpZone(r, ng))
+	r.printf("  tags         = %s\n\n", hclStringList(tags))
+
+	r.printf("  boot_disk {\n    initialize_params {\n")
+	r.printf("      image = %s\n", hclString(img))
+	if ng.RootDiskSize > 0 {
+		r.printf("      size  = %d\n", ng.RootDiskSize)
+	}
+	if strings.HasPrefix(ng.RootDiskType, "pd-") || strings.HasPrefix(ng.RootDiskType, "hyperdisk-") {
+		r.printf("      type  = %s\n", hclString(ng.RootDiskType))
+	}
+	r.printf("    }\n  }\n\n")
+
+	r.printf("  network_interface {\n")
+	r.printf("    subnetwork = google_compute_subnetwork.%s.id\n\n", tfLocalName(ng.VNetId, ng.SubnetId))
+	r.printf("    access_config {}\n")
+	r.printf("  }\n")
+
+	if isWindows {
+		r.note("The Windows nodes of the node group '%s' get no SSH key; set their passwords with 'gcloud compute reset-windows-password'.", ng.Name)
+	} else {
+		r.printf("\n  metadata = {\n")
+		r.printf("    ssh-keys = \"${var.ssh_username}:${var.ssh_public_key}\"\n")
+		r.printf("  }\n")
+	}
+	r.printf("}\n\n")
+
+	r.output(tfOutput{
+		Name:        n + "_public_ips",
+		Description: fmt.Sprintf("Public IPs of the nodes of %s", ng.Name),
+		Value:       fmt.Sprintf("google_compute_instance.%s[*].network_interface[0].access_config[0].nat_ip", n),
+	})
+	r.output(tfOutput{
+		Name:        n + "_private_ips",
+		Description: fmt.Sprintf("Private IPs of the nodes of %s", ng.Name),
+		Value:       fmt.Sprintf("google_compute_instance.%s[*].network_interface[0].network_ip", n),
+	})
+}
+
+// gcpZone returns the HCL expression of the zone of the node group (the zone of its subnet or the zone variable).
+func gcpZone(r *terraformRenderer, ng cloudmodel.CreateNodeGroupReq) string {
+	subnet, _ := r.findSubnet(ng.VNetId, ng.SubnetId)
+	if subnet.Zone != "" {
+		return hclString(subnet.Zone)
+	}
+	return "var.zone"
+}
+
+// renderGcpNlb renders a passthrough network load balancer, i.e., an unmanaged instance group per node group,
+// a regional TCP health check, a regional backend service and a forwarding rule.
+func renderGcpNlb(r *terraformRenderer, nlb cloudmodel.NlbReq) error {
+	ngs, err := r.nlbNodeGroups(nlb)
+	if err != nil {
+		return err
+	}
+	listenerPort, targetPort, err := nlbPorts(nlb)
+	if err != nil {
+		return err
+	}
+	if listenerPort != targetPort {
+		r.note("The passthrough NLB '%s' of GCP does not translate ports; the listener port %d is forwarded to the same port of the nodes (not %d).",
+			nlbName(nlb), listenerPort, targetPort)
+	}
+
+	name := nlbName(nlb)
+	l := tfLocalName(name)
+	scheme := "EXTERNAL"
+	if strings.EqualFold(nlb.Type, "INTERNAL") {
+		scheme = "INTERNAL"
+	}
+	protocol := "TCP"
+	if strings.EqualFold(nlb.Listener.Protocol, "UDP") {
+		protocol = "UDP"
+	}
+	interval := clamp(nlb.HealthChecker.Interval, 10, 1, 300)
+
+	r.printf("# NLB: %s\n", name)
+	var tags []string
+	for _, ng := range ngs {
+		n := tfLocalName(ng.Name)
+		r.printf("resource \"google_compute_instance_group\" %s {\n", hclString(tfLocalName(name, ng.Name)))
+		r.printf("  name      = %s\n", hclString(gcpName(name+"-"+ng.Name)))
+		r.printf("  zone      = %s\n", gcpZone(r, ng))
+		r.printf("  instances = google_compute_instance.%s[*].self_link\n}\n\n", n)
+		for _, sgId := range ng.SecurityGroupIds {
+			tags = append(tags, gcpName(sgId))
+		}
+	}
+
+	r.printf("resource \"google_compute_region_health_check\" %s {\n", hclString(l))
+	r.printf("  name                = %s\n", hclString(gcpName(name+"-hc")))
+	r.printf("  region              = var.region\n")
+	r.printf("  check_interval_sec  = %d\n", interval)
+	r.printf("  timeout_sec         = %d\n", clamp(nlb.HealthChecker.Timeout, interval, 1, interval))
+	r.printf("  unhealthy_threshold = %d\n\n", clamp(nlb.HealthChecker.Threshold, 3, 1, 10))
+	r.printf("  tcp_health_check {\n    port = %d\n  }\n}\n\n", listenerPort)
+
+	// Health check probes come from the Google Cloud ranges, which the security groups may not allow
+	r.printf("resource \"google_compute_firewall\" %s {\n", hclString(tfLocalName(name, "hc")))
+	r.printf("  name          = %s\n", hclString(gcpName(name+"-hc")))
+	r.printf("  network       = google_compute_network.%s.id\n", tfLocalName(ngs[0].VNetId))
+	r.printf("  direction     = \"INGRESS\"\n")
+	r.printf("  source_ranges = %s\n", hclStringList(gcpHealthCheckRanges))
+	if len(tags) > 0 {
+		r.printf("  target_tags   = %s\n", hclStringList(uniqueStrings(tags)))
+	}
+	r.printf("\n  allow {\n    protocol = \"tcp\"\n    ports    = [\"%d\"]\n  }\n}\n\n", listenerPort)
+
+	r.printf("resource \"google_compute_region_backend_service\" %s {\n", hclString(l))
+	r.printf("  name                  = %s\n", hclString(gcpName(name)))
+	r.printf("  region                = var.region\n")
+	r.printf("  protocol              = %s\n", hclString(protocol))
+	r.printf("  load_balancing_scheme = %s\n", hclString(scheme))
+	r.printf("  health_checks         = [google_compute_region_health_check.%s.id]\n", l)
+	for _, ng := range ngs {
+		r.printf("\n  backend {\n")
+		r.printf("    group          = google_compute_instance_group.%s.id\n", tfLocalName(name, ng.Name))
+		r.printf("    balancing_mode = \"CONNECTION\"\n")
+		r.printf("  }\n")
+	}
+	r.printf("}\n\n")
+
+	r.printf("resource \"google_compute_forwarding_rule\" %s {\n", hclString(l))
+	r.printf("  name                  = %s\n", hclString(gcpName(name)))
+	r.printf("  region                = var.region\n")
+	r.printf("  load_balancing_scheme = %s\n", hclString(scheme))
+	r.printf("  ip_protocol           = %s\n", hclString(protocol))
+	r.printf("  ports                 = [\"%d\"]\n", listenerPort)
+	r.printf("  backend_service       = google_compute_region_backend_service.%s.id\n", l)
+	if scheme == "INTERNAL" {
+		r.printf("  network               = google_compute_network.%s.id\n", tfLocalName(ngs[0].VNetId))
+		r.printf("  subnetwork            = google_compute_subnetwork.%s.id\n", tfLocalName(ngs[0].VNetId, ngs[0].SubnetId))
+	}
+	r.printf("}\n\n")
+
+	r.output(tfOutput{
+		Name:        l + "_ip",
+		Description: fmt.Sprintf("IP of the NLB %s", name),
+		Value:       fmt.Sprintf("google_compute_forwarding_rule.%s.ip_address", l),
+	})
+	return nil
+}
+
+// gcpName converts a name to a resource name of GCP (lowercase letters, digits and hyphens; up to 63 characters).
+func gcpName(name string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(name) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			b.WriteRune(c)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	s := b.String()
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		s = "r-" + s
+	}
+	return truncateName(s, 63)
+}
+
+// uniqueStrings removes the duplicated strings keeping the order.
+func uniqueStrings(items []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			unique = append(unique, item)
+		}
+	}
+	return unique
+}
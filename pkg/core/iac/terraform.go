@@ -0,0 +1,679 @@
+// Package iac renders a recommended infrastructure into infrastructure-as-code (Terraform / OpenTofu modules)
+package iac
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+)
+
+// ============================================================================
+// Terraform / OpenTofu export
+// Renders a cloudmodel.RecommendedInfra into a self-contained Terraform module
+// per CSP, so that the target infrastructure can be provisioned by the user's
+// own IaC pipeline instead of CB-Tumblebug.
+// Secrets (e.g., SSH public key, admin password) are module variables; they are
+// never taken from the recommendation (e.g., TargetSshKey.PrivateKey is ignored).
+// ============================================================================
+
+// Archive formats of the exported module
+const (
+	ArchiveZip   = "zip"
+	ArchiveTarGz = "tar.gz"
+)
+
+// defaultNodeUserName is the default user name of the nodes (same as CB-Tumblebug)
+const defaultNodeUserName = "cb-user"
+
+// TerraformFile is a file of the exported Terraform module.
+type TerraformFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// TerraformModule is a Terraform module rendered from a recommended infrastructure.
+type TerraformModule struct {
+	Name   string          `json:"name"` // Module (directory) name: <infraName>-<csp>
+	Csp    string          `json:"csp"`
+	Region string          `json:"region"`
+	Files  []TerraformFile `json:"files"`
+	Notes  []string        `json:"notes,omitempty"` // What is not rendered or needs the user's attention
+}
+
+// terraformRenderers render the module body of each CSP.
+var terraformRenderers = map[string]func(r *terraformRenderer) error{
+	"aws":   renderAws,
+	"azure": renderAzure,
+	"gcp":   renderGcp,
+}
+
+// SupportedTerraformCsps returns the CSPs supported by the Terraform export.
+func SupportedTerraformCsps() []string {
+	csps := make([]string, 0, len(terraformRenderers))
+	for csp := range terraformRenderers {
+		csps = append(csps, csp)
+	}
+	sort.Strings(csps)
+	return csps
+}
+
+// IsValidArchiveFormat checks if the archive format is supported.
+func IsValidArchiveFormat(format string) bool {
+	return format == ArchiveZip || format == ArchiveTarGz
+}
+
+// ExportTerraform renders the recommended infrastructure into a Terraform module of the target CSP.
+// The module consists of versions.tf, variables.tf, main.tf, outputs.tf, terraform.tfvars.example and README.md.
+// All returned errors are caused by the recommended infrastructure (e.g., unsupported CSP, dangling references).
+func ExportTerraform(infra cloudmodel.RecommendedInfra) (*TerraformModule, error) {
+	csp := strings.ToLower(infra.TargetCloud.Csp)
+	render, ok := terraformRenderers[csp]
+	if !ok {
+		return nil, fmt.Errorf("unsupported CSP for the Terraform export: '%s' (supported: %s)",
+			infra.TargetCloud.Csp, strings.Join(SupportedTerraformCsps(), ", "))
+	}
+	if infra.TargetCloud.Region == "" {
+		return nil, fmt.Errorf("the target region is required")
+	}
+	if infra.TargetInfra.Name == "" {
+		return nil, fmt.Errorf("the name of the target infra is required")
+	}
+	if len(infra.TargetInfra.NodeGroups) == 0 {
+		return nil, fmt.Errorf("the target infra '%s' has no node group", infra.TargetInfra.Name)
+	}
+
+	r := &terraformRenderer{infra: infra, csp: csp}
+	if err := render(r); err != nil {
+		return nil, fmt.Errorf("failed to render the Terraform module for %s: %w", csp, err)
+	}
+
+	// Common notes on what the module does not cover
+	if len(infra.TargetAlbList) > 0 {
+		r.note("Application load balancers (targetAlbList) are not rendered; create them after applying the module.")
+	}
+	if len(infra.TargetNetworkAclList) > 0 {
+		r.note("Network ACLs (targetNetworkAclList) are not rendered; the deny rules listed in firewallPolicy are not enforced by this module.")
+	}
+	for _, vNet := range r.vNets() {
+		if vNet.EnableIPv6 {
+			r.note("IPv6 (dual-stack) settings of the vNets are not rendered; the module provisions IPv4 only.")
+			break
+		}
+	}
+	if len(infra.TargetInfra.PostCommand.Command) > 0 {
+		r.note("The post-deployment commands (targetInfra.postCommand) are not rendered.")
+	}
+
+	module := &TerraformModule{
+		Name:   tfResourceName(infra.TargetInfra.Name) + "-" + csp,
+		Csp:    csp,
+		Region: infra.TargetCloud.Region,
+		Notes:  r.notes,
+	}
+	module.Files = []TerraformFile{
+		{Name: "versions.tf", Content: r.versions.String()},
+		{Name: "variables.tf", Content: r.renderVariables()},
+		{Name: "main.tf", Content: r.main.String()},
+		{Name: "outputs.tf", Content: r.renderOutputs()},
+		{Name: "terraform.tfvars.example", Content: r.renderTfvarsExample()},
+		{Name: "README.md", Content: r.renderReadme(module)},
+	}
+	return module, nil
+}
+
+// WriteArchive writes the module files into an archive (zip or tar.gz) under the module directory.
+func (m *TerraformModule) WriteArchive(w io.Writer, format string) error {
+	modTime := time.Now()
+
+	switch format {
+	case ArchiveZip:
+		zw := zip.NewWriter(w)
+		for _, f := range m.Files {
+			fw, err := zw.CreateHeader(&zip.FileHeader{
+				Name:     path.Join(m.Name, f.Name),
+				Method:   zip.Deflate,
+				Modified: modTime,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to add %s to the archive: %w", f.Name, err)
+			}
+			if _, err := io.WriteString(fw, f.Content); err != nil {
+				return fmt.Errorf("failed to write %s to the archive: %w", f.Name, err)
+			}
+		}
+		return zw.Close()
+
+	case ArchiveTarGz:
+		gw := gzip.NewWriter(w)
+		tw := tar.NewWriter(gw)
+		for _, f := range m.Files {
+			hdr := &tar.Header{
+				Name:    path.Join(m.Name, f.Name),
+				Mode:    0644,
+				Size:    int64(len(f.Content)),
+				ModTime: modTime,
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return fmt.Errorf("failed to add %s to the archive: %w", f.Name, err)
+			}
+			if _, err := io.WriteString(tw, f.Content); err != nil {
+				return fmt.Errorf("failed to write %s to the archive: %w", f.Name, err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gw.Close()
+	}
+
+	return fmt.Errorf("unsupported archive format: %s", format)
+}
+
+// ArchiveFileName returns the file name of the module archive.
+func (m *TerraformModule) ArchiveFileName(format string) string {
+	if format == ArchiveTarGz {
+		return m.Name + ".tar.gz"
+	}
+	return m.Name + ".zip"
+}
+
+// ============================================================================
+// Renderer
+// ============================================================================
+
+// tfVariable is an input variable of the module (Default is an HCL expression; empty means required).
+type tfVariable struct {
+	Name        string
+	Description string
+	Type        string
+	Default     string
+	Sensitive   bool
+}
+
+// tfOutput is an output value of the module (Value is an HCL expression).
+type tfOutput struct {
+	Name        string
+	Description string
+	Value       string
+}
+
+// terraformRenderer accumulates the module files while the CSP renderer walks the recommended infrastructure.
+type terraformRenderer struct {
+	infra     cloudmodel.RecommendedInfra
+	csp       string
+	versions  strings.Builder
+	main      strings.Builder
+	variables []tfVariable
+	outputs   []tfOutput
+	notes     []string
+}
+
+func (r *terraformRenderer) variable(v tfVariable) {
+	for _, existing := range r.variables {
+		if existing.Name == v.Name {
+			return
+		}
+	}
+	r.variables = append(r.variables, v)
+}
+
+func (r *terraformRenderer) output(o tfOutput) {
+	r.outputs = append(r.outputs, o)
+}
+
+func (r *terraformRenderer) note(format string, args ...any) {
+	n := fmt.Sprintf(format, args...)
+	for _, existing := range r.notes {
+		if existing == n {
+			return
+		}
+	}
+	r.notes = append(r.notes, n)
+}
+
+// printf writes to main.tf.
+func (r *terraformRenderer) printf(format string, args ...any) {
+	fmt.Fprintf(&r.main, format, args...)
+}
+
+// vNets returns the target vNet and the additional vNets.
+func (r *terraformRenderer) vNets() []cloudmodel.VNetReq {
+	vNets := []cloudmodel.VNetReq{}
+	if r.infra.TargetVNet.Name != "" {
+		vNets = append(vNets, r.infra.TargetVNet)
+	}
+	for _, vNet := range r.infra.AdditionalVNetList {
+		if vNet.Name != "" {
+			vNets = append(vNets, vNet)
+		}
+	}
+	return vNets
+}
+
+// hasVNet checks if the vNet exists.
+func (r *terraformRenderer) hasVNet(name string) bool {
+	for _, vNet := range r.vNets() {
+		if vNet.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findSubnet finds the subnet of the vNet by name.
+func (r *terraformRenderer) findSubnet(vNetName, subnetName string) (cloudmodel.SubnetReq, error) {
+	for _, vNet := range r.vNets() {
+		if vNet.Name != vNetName {
+			continue
+		}
+		for _, subnet := range vNet.SubnetInfoList {
+			if subnet.Name == subnetName {
+				return subnet, nil
+			}
+		}
+		return cloudmodel.SubnetReq{}, fmt.Errorf("subnet '%s' not found in vNet '%s'", subnetName, vNetName)
+	}
+	return cloudmodel.SubnetReq{}, fmt.Errorf("vNet '%s' not found", vNetName)
+}
+
+// findSecurityGroup finds the security group by name.
+func (r *terraformRenderer) findSecurityGroup(name string) (cloudmodel.SecurityGroupReq, error) {
+	for _, sg := range r.infra.TargetSecurityGroupList {
+		if sg.Name == name {
+			return sg, nil
+		}
+	}
+	return cloudmodel.SecurityGroupReq{}, fmt.Errorf("security group '%s' not found", name)
+}
+
+// findNodeGroup finds the node group by name.
+func (r *terraformRenderer) findNodeGroup(name string) (cloudmodel.CreateNodeGroupReq, error) {
+	for _, ng := range r.infra.TargetInfra.NodeGroups {
+		if ng.Name == name {
+			return ng, nil
+		}
+	}
+	return cloudmodel.CreateNodeGroupReq{}, fmt.Errorf("node group '%s' not found", name)
+}
+
+// validateNodeGroup checks the references of the node group.
+func (r *terraformRenderer) validateNodeGroup(ng cloudmodel.CreateNodeGroupReq) error {
+	if _, err := r.findSubnet(ng.VNetId, ng.SubnetId); err != nil {
+		return fmt.Errorf("node group '%s': %w", ng.Name, err)
+	}
+	for _, sgId := range ng.SecurityGroupIds {
+		if _, err := r.findSecurityGroup(sgId); err != nil {
+			return fmt.Errorf("node group '%s': %w", ng.Name, err)
+		}
+	}
+	return nil
+}
+
+// specName resolves the CSP spec name (e.g., t3.small) of the node group.
+func (r *terraformRenderer) specName(ng cloudmodel.CreateNodeGroupReq) string {
+	for _, spec := range r.infra.TargetSpecList {
+		if spec.Id == ng.SpecId && spec.CspSpecName != "" {
+			return spec.CspSpecName
+		}
+	}
+	// Spec IDs of CB-Tumblebug are "<csp>+<region>+<cspSpecName>"
+	parts := strings.Split(ng.SpecId, "+")
+	return parts[len(parts)-1]
+}
+
+// image resolves the CSP image (e.g., AMI ID, Azure URN) of the node group and whether it is a Windows image.
+func (r *terraformRenderer) image(ng cloudmodel.CreateNodeGroupReq) (string, bool) {
+	cspImage := ng.CspImageName
+	isWindows := false
+	for _, img := range r.infra.TargetOsImageList {
+		if img.Id != ng.ImageId && img.CspImageName != ng.ImageId {
+			continue
+		}
+		if cspImage == "" {
+			cspImage = img.CspImageName
+		}
+		if cspImage == "" {
+			cspImage = img.CspImageId
+		}
+		isWindows = img.OSPlatform == cloudmodel.Windows
+		break
+	}
+	if cspImage == "" {
+		cspImage = ng.ImageId
+	}
+	return cspImage, isWindows
+}
+
+// nodeCount returns the number of nodes of the node group (CB-Tumblebug creates one node for size 0).
+func nodeCount(ng cloudmodel.CreateNodeGroupReq) int {
+	if ng.NodeGroupSize < 1 {
+		return 1
+	}
+	return ng.NodeGroupSize
+}
+
+// nodeUserName returns the user name of the nodes.
+func (r *terraformRenderer) nodeUserName() string {
+	for _, ng := range r.infra.TargetInfra.NodeGroups {
+		if ng.NodeUserName != "" {
+			return ng.NodeUserName
+		}
+	}
+	return defaultNodeUserName
+}
+
+// nlbName returns the name of the NLB (CB-Tumblebug names an NLB after its node group).
+func nlbName(nlb cloudmodel.NlbReq) string {
+	return fmt.Sprintf("%s-nlb-%s", nlb.TargetGroup.NodeGroupId, nlb.Listener.Port)
+}
+
+// nlbNodeGroups returns the node groups of the NLB target group.
+// CB-Tumblebug binds a single node group to a target group, and so does the rendered configuration.
+func (r *terraformRenderer) nlbNodeGroups(nlb cloudmodel.NlbReq) ([]cloudmodel.CreateNodeGroupReq, error) {
+	ng, err := r.findNodeGroup(nlb.TargetGroup.NodeGroupId)
+	if err != nil {
+		return nil, fmt.Errorf("NLB '%s': %w", nlbName(nlb), err)
+	}
+	return []cloudmodel.CreateNodeGroupReq{ng}, nil
+}
+
+// nlbPorts returns the listener port and the target port of the NLB.
+func nlbPorts(nlb cloudmodel.NlbReq) (int, int, error) {
+	listenerPort, err := strconv.Atoi(nlb.Listener.Port)
+	if err != nil || listenerPort < 1 || listenerPort > 65535 {
+		return 0, 0, fmt.Errorf("NLB '%s': invalid listener port '%s'", nlbName(nlb), nlb.Listener.Port)
+	}
+	if nlb.TargetGroup.Port == "" {
+		return listenerPort, listenerPort, nil
+	}
+	targetPort, err := strconv.Atoi(nlb.TargetGroup.Port)
+	if err != nil || targetPort < 1 || targetPort > 65535 {
+		return 0, 0, fmt.Errorf("NLB '%s': invalid target port '%s'", nlbName(nlb), nlb.TargetGroup.Port)
+	}
+	return listenerPort, targetPort, nil
+}
+
+// ============================================================================
+// Common files
+// ============================================================================
+
+// commonVariables registers the variables shared by all CSPs.
+func (r *terraformRenderer) commonVariables() {
+	r.variable(tfVariable{
+		Name:        "ssh_public_key",
+		Description: "SSH public key (OpenSSH format) to access the nodes",
+		Type:        "string",
+	})
+}
+
+func (r *terraformRenderer) renderVariables() string {
+	var b strings.Builder
+	for i, v := range r.variables {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "variable %s {\n", hclString(v.Name))
+		fmt.Fprintf(&b, "  description = %s\n", hclString(v.Description))
+		fmt.Fprintf(&b, "  type        = %s\n", v.Type)
+		if v.Default != "" {
+			fmt.Fprintf(&b, "  default     = %s\n", v.Default)
+		}
+		if v.Sensitive {
+			b.WriteString("  sensitive   = true\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func (r *terraformRenderer) renderOutputs() string {
+	var b strings.Builder
+	for i, o := range r.outputs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "output %s {\n", hclString(o.Name))
+		fmt.Fprintf(&b, "  description = %s\n", hclString(o.Description))
+		fmt.Fprintf(&b, "  value       = %s\n", o.Value)
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// renderTfvarsExample lists the required variables (i.e., without defaults) to be set by the user.
+func (r *terraformRenderer) renderTfvarsExample() string {
+	var b strings.Builder
+	b.WriteString("# Copy to terraform.tfvars and set the values (do not commit the secrets).\n")
+	for _, v := range r.variables {
+		if v.Default != "" {
+			fmt.Fprintf(&b, "# %s = %s\n", v.Name, v.Default)
+			continue
+		}
+		fmt.Fprintf(&b, "%s = \"\"\n", v.Name)
+	}
+	return b.String()
+}
+
+func (r *terraformRenderer) renderReadme(m *TerraformModule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", m.Name)
+	fmt.Fprintf(&b, "Terraform / OpenTofu module of the recommended infrastructure `%s` (CSP: %s, region: %s), exported by CM-Beetle.\n\n",
+		r.infra.TargetInfra.Name, m.Csp, m.Region)
+	if r.infra.Description != "" {
+		fmt.Fprintf(&b, "> %s\n\n", r.infra.Description)
+	}
+
+	b.WriteString("## Resources\n\n")
+	b.WriteString("| Node group | Nodes | Spec | Image |\n")
+	b.WriteString("|---|---|---|---|\n")
+	for _, ng := range r.infra.TargetInfra.NodeGroups {
+		img, _ := r.image(ng)
+		fmt.Fprintf(&b, "| %s | %d | %s | %s |\n", ng.Name, nodeCount(ng), r.specName(ng), img)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Usage\n\n")
+	b.WriteString("```bash\n")
+	b.WriteString("cp terraform.tfvars.example terraform.tfvars  # set the variables\n")
+	b.WriteString("terraform init    # or: tofu init\n")
+	b.WriteString("terraform plan\n")
+	b.WriteString("terraform apply\n")
+	b.WriteString("```\n\n")
+
+	b.WriteString("## Variables\n\n")
+	b.WriteString("| Name | Description | Default |\n")
+	b.WriteString("|---|---|---|\n")
+	for _, v := range r.variables {
+		def := "(required)"
+		if v.Default != "" {
+			def = "`" + v.Default + "`"
+		}
+		desc := v.Description
+		if v.Sensitive {
+			desc += " (sensitive)"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", v.Name, desc, def)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Outputs\n\n")
+	for _, o := range r.outputs {
+		fmt.Fprintf(&b, "- `%s`: %s\n", o.Name, o.Description)
+	}
+	b.WriteString("\n")
+
+	if len(r.notes) > 0 {
+		b.WriteString("## Notes\n\n")
+		for _, n := range r.notes {
+			fmt.Fprintf(&b, "- %s\n", n)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ============================================================================
+// HCL helpers
+// ============================================================================
+
+// hclEscape escapes a string literal of HCL, including the template sequences (${ and %{).
+func hclEscape(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return replacer.Replace(s)
+}
+
+// hclString returns a quoted HCL string literal.
+func hclString(s string) string {
+	return `"` + hclEscape(s) + `"`
+}
+
+// hclStringList returns an HCL list of string literals.
+func hclStringList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = hclString(item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// hclNodeName returns an HCL string of the node name, i.e., "<nodeGroup>-<index>" (same as CB-Tumblebug).
+func hclNodeName(ngName, suffix string) string {
+	return `"` + hclEscape(ngName) + `-${count.index + 1}` + hclEscape(suffix) + `"`
+}
+
+// tfResourceName converts a name to a Terraform resource name (letters, digits, underscores and hyphens).
+func tfResourceName(name string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(name) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '_', c == '-':
+			b.WriteRune(c)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	s := b.String()
+	if s == "" || (s[0] >= '0' && s[0] <= '9') || s[0] == '-' {
+		s = "r_" + s
+	}
+	return s
+}
+
+// tfLocalName joins the names into a Terraform resource name.
+func tfLocalName(names ...string) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = tfResourceName(name)
+	}
+	return strings.Join(parts, "_")
+}
+
+// portRange is a port or a port range of a firewall rule.
+type portRange struct {
+	From int
+	To   int
+}
+
+func (p portRange) String() string {
+	if p.From == p.To {
+		return fmt.Sprintf("%d", p.From)
+	}
+	return fmt.Sprintf("%d-%d", p.From, p.To)
+}
+
+// parsePortRanges parses the ports of a firewall rule (e.g., "22,900-1000"); empty means all ports.
+func parsePortRanges(ports string) ([]portRange, error) {
+	ports = strings.TrimSpace(ports)
+	if ports == "" || ports == "*" || ports == "-1" {
+		return []portRange{{From: 1, To: 65535}}, nil
+	}
+
+	var ranges []portRange
+	for _, part := range strings.Split(ports, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var p portRange
+		if from, to, found := strings.Cut(part, "-"); found {
+			if _, err := fmt.Sscanf(from+" "+to, "%d %d", &p.From, &p.To); err != nil {
+				return nil, fmt.Errorf("invalid port range '%s'", part)
+			}
+		} else {
+			if _, err := fmt.Sscanf(part, "%d", &p.From); err != nil {
+				return nil, fmt.Errorf("invalid port '%s'", part)
+			}
+			p.To = p.From
+		}
+		if p.From < 0 || p.To > 65535 || p.From > p.To {
+			return nil, fmt.Errorf("invalid port range '%s'", part)
+		}
+		ranges = append(ranges, p)
+	}
+	return ranges, nil
+}
+
+// isAllPorts checks if the port ranges cover all ports.
+func isAllPorts(ranges []portRange) bool {
+	return len(ranges) == 1 && ranges[0].From <= 1 && ranges[0].To == 65535
+}
+
+// isIPv6Cidr checks if the CIDR block is an IPv6 one.
+func isIPv6Cidr(cidr string) bool {
+	return strings.Contains(cidr, ":")
+}
+
+// firewallRules returns the firewall rules of the security group.
+func firewallRules(sg cloudmodel.SecurityGroupReq) []cloudmodel.FirewallRuleReq {
+	if sg.FirewallRules == nil {
+		return nil
+	}
+	return *sg.FirewallRules
+}
+
+// clamp limits the value to [min, max], using def for unset (zero) values.
+func clamp(v, def, min, max int) int {
+	if v == 0 {
+		v = def
+	}
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// defaultString returns def if s is empty.
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// truncateName truncates the name to the maximum length, trimming trailing hyphens.
+func truncateName(name string, max int) string {
+	if len(name) > max {
+		name = name[:max]
+	}
+	return strings.TrimRight(name, "-")
+}
@@ -0,0 +1,233 @@
+package iac
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+)
+
+// update rewrites the golden files with the rendered modules (go test ./pkg/core/iac -update)
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// testInfra returns a small infrastructure of the CSP: a node group of two nodes behind an NLB,
+// with a security group having IPv4 and IPv6 rules.
+func testInfra(csp, region, zone, cspSpecName, cspImageName string) cloudmodel.RecommendedInfra {
+	rules := []cloudmodel.FirewallRuleReq{
+		{Direction: "inbound", Protocol: "TCP", Ports: "22", CIDR: "0.0.0.0/0"},
+		{Direction: "inbound", Protocol: "TCP", Ports: "80,443", CIDR: "::/0"},
+		{Direction: "inbound", Protocol: "TCP", Ports: "8080-8081", CIDR: "10.0.0.0/16"},
+		{Direction: "inbound", Protocol: "ICMP", CIDR: "10.0.0.0/16"},
+		{Direction: "outbound", Protocol: "ALL", CIDR: "0.0.0.0/0"},
+		{Direction: "outbound", Protocol: "ALL", CIDR: "::/0"},
+	}
+	specId := csp + "+" + region + "+" + cspSpecName
+
+	return cloudmodel.RecommendedInfra{
+		TargetCloud: cloudmodel.CloudProperty{Csp: csp, Region: region},
+		TargetInfra: cloudmodel.InfraReq{
+			Name: "web-infra",
+			NodeGroups: []cloudmodel.CreateNodeGroupReq{{
+				Name:             "web",
+				NodeGroupSize:    2,
+				SpecId:           specId,
+				ImageId:          "ubuntu22.04",
+				VNetId:           "mig-vnet-01",
+				SubnetId:         "mig-subnet-01",
+				SecurityGroupIds: []string{"mig-sg-01"},
+				RootDiskSize:     50,
+			}},
+		},
+		TargetVNet: cloudmodel.VNetReq{
+			Name:      "mig-vnet-01",
+			CidrBlock: "10.0.0.0/16",
+			SubnetInfoList: []cloudmodel.SubnetReq{
+				{Name: "mig-subnet-01", IPv4_CIDR: "10.0.1.0/24", Zone: zone},
+			},
+		},
+		TargetSshKey:      cloudmodel.SshKeyReq{Name: "mig-sshkey-01", PrivateKey: "must-not-be-rendered"},
+		TargetSpecList:    []cloudmodel.SpecInfo{{Id: specId, CspSpecName: cspSpecName}},
+		TargetOsImageList: []cloudmodel.ImageInfo{{Id: "ubuntu22.04", CspImageName: cspImageName}},
+		TargetSecurityGroupList: []cloudmodel.SecurityGroupReq{{
+			Name:          "mig-sg-01",
+			VNetId:        "mig-vnet-01",
+			Description:   "Web tier (${env})",
+			FirewallRules: &rules,
+		}},
+		TargetNlbList: []cloudmodel.NlbReq{{
+			Type:          "PUBLIC",
+			Listener:      cloudmodel.NlbListenerReq{Protocol: "TCP", Port: "80"},
+			TargetGroup:   cloudmodel.NlbTargetGroupReq{Protocol: "TCP", Port: "8080", NodeGroupId: "web"},
+			HealthChecker: cloudmodel.NlbHealthCheckerReq{Interval: 10, Threshold: 3, Timeout: 5},
+		}},
+	}
+}
+
+func TestExportTerraformGolden(t *testing.T) {
+	tests := []struct {
+		csp   string
+		infra cloudmodel.RecommendedInfra
+	}{
+		{"aws", testInfra("aws", "ap-northeast-2", "ap-northeast-2a", "t3.small", "ami-0123456789abcdef0")},
+		{"azure", testInfra("azure", "koreacentral", "1", "Standard_B2s", "Canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2:latest")},
+		{"gcp", testInfra("gcp", "asia-northeast3", "asia-northeast3-a", "e2-small", "projects/ubuntu-os-cloud/global/images/family/ubuntu-2204-lts")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.csp, func(t *testing.T) {
+			module, err := ExportTerraform(tt.infra)
+			if err != nil {
+				t.Fatalf("ExportTerraform() error = %v", err)
+			}
+			if module.Name != "web-infra-"+tt.csp {
+				t.Errorf("module name = %s, want web-infra-%s", module.Name, tt.csp)
+			}
+
+			dir := filepath.Join("testdata", tt.csp)
+			if *update {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, f := range module.Files {
+				if strings.Contains(f.Content, tt.infra.TargetSshKey.PrivateKey) {
+					t.Errorf("%s contains the SSH private key", f.Name)
+				}
+
+				golden := filepath.Join(dir, f.Name)
+				if *update {
+					if err := os.WriteFile(golden, []byte(f.Content), 0644); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("failed to read the golden file (run with -update to create it): %v", err)
+				}
+				if line, got, want := firstDiffLine(f.Content, string(want)); line > 0 {
+					t.Errorf("%s differs from %s at line %d (run with -update to accept the change):\n got: %s\nwant: %s",
+						f.Name, golden, line, got, want)
+				}
+			}
+		})
+	}
+}
+
+// firstDiffLine returns the first different line (1-based) of the texts, or 0 if they are the same.
+func firstDiffLine(got, want string) (int, string, string) {
+	gotLines, wantLines := strings.Split(got, "\n"), strings.Split(want, "\n")
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w || i >= len(gotLines) || i >= len(wantLines) {
+			return i + 1, g, w
+		}
+	}
+	return 0, "", ""
+}
+
+func TestExportTerraformErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(infra *cloudmodel.RecommendedInfra)
+	}{
+		{"unsupported CSP", func(infra *cloudmodel.RecommendedInfra) { infra.TargetCloud.Csp = "ncp" }},
+		{"no region", func(infra *cloudmodel.RecommendedInfra) { infra.TargetCloud.Region = "" }},
+		{"no node group", func(infra *cloudmodel.RecommendedInfra) { infra.TargetInfra.NodeGroups = nil }},
+		{"unknown subnet", func(infra *cloudmodel.RecommendedInfra) { infra.TargetInfra.NodeGroups[0].SubnetId = "unknown" }},
+		{"unknown NLB node group", func(infra *cloudmodel.RecommendedInfra) { infra.TargetNlbList[0].TargetGroup.NodeGroupId = "unknown" }},
+		{"invalid ports", func(infra *cloudmodel.RecommendedInfra) {
+			(*infra.TargetSecurityGroupList[0].FirewallRules)[0].Ports = "22-"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infra := testInfra("aws", "ap-northeast-2", "ap-northeast-2a", "t3.small", "ami-0123456789abcdef0")
+			tt.modify(&infra)
+			if _, err := ExportTerraform(infra); err == nil {
+				t.Error("ExportTerraform() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestHclEscape(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain", "plain"},
+		{`say "hi"`, `say \"hi\"`},
+		{`C:\path`, `C:\\path`},
+		{"line1\nline2\ttab\r", `line1\nline2\ttab\r`},
+		{"${var.secret}", "$${var.secret}"},
+		{"%{ if true }", "%%{ if true }"},
+		{"$5 and 100%", "$5 and 100%"},
+	}
+	for _, tt := range tests {
+		if got := hclEscape(tt.s); got != tt.want {
+			t.Errorf("hclEscape(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParsePortRanges(t *testing.T) {
+	tests := []struct {
+		ports   string
+		want    []portRange
+		wantErr bool
+	}{
+		{"", []portRange{{1, 65535}}, false},
+		{"*", []portRange{{1, 65535}}, false},
+		{"-1", []portRange{{1, 65535}}, false},
+		{"22", []portRange{{22, 22}}, false},
+		{"22, 80,443", []portRange{{22, 22}, {80, 80}, {443, 443}}, false},
+		{"900-1000", []portRange{{900, 1000}}, false},
+		{"22,,80", []portRange{{22, 22}, {80, 80}}, false},
+		{"1-65535", []portRange{{1, 65535}}, false},
+		{"ssh", nil, true},
+		{"22-", nil, true},
+		{"1000-900", nil, true},
+		{"70000", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parsePortRanges(tt.ports)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePortRanges(%q) error = %v, wantErr %v", tt.ports, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePortRanges(%q) = %v, want %v", tt.ports, got, tt.want)
+		}
+	}
+}
+
+func TestTfResourceName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"mig-vnet-01", "mig-vnet-01"},
+		{"Web_Tier", "web_tier"},
+		{"web.tier 01", "web_tier_01"},
+		{"01-web", "r_01-web"},
+		{"-web", "r_-web"},
+		{"", "r_"},
+	}
+	for _, tt := range tests {
+		if got := tfResourceName(tt.name); got != tt.want {
+			t.Errorf("tfResourceName(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+# vNet: mig-vnet-01
+resource "aws_vpc" "mig-vnet-01" {
+  cidr_block           = "10.0.0.0/16"
+  enable_dns_support   = true
+  enable_dns_hostnames = true
+
+  tags = {
+    Name = "mig-vnet-01"
+  }
+}
+
+resource "aws_internet_gateway" "mig-vnet-01" {
+  vpc_id = aws_vpc.mig-vnet-01.id
+
+  tags = {
+    Name = "mig-vnet-01-igw"
+  }
+}
+
+resource "aws_route_table" "mig-vnet-01" {
+  vpc_id = aws_vpc.mig-vnet-01.id
+
+  route {
+    cidr_block = "0.0.0.0/0"
+    gateway_id = aws_internet_gateway.mig-vnet-01.id
+  }
+
+  tags = {
+    Name = "mig-vnet-01-rt"
+  }
+}
+
+resource "aws_subnet" "mig-vnet-01_mig-subnet-01" {
+  vpc_id                  = aws_vpc.mig-vnet-01.id
+  cidr_block              = "10.0.1.0/24"
+  availability_zone       = "ap-northeast-2a"
+  map_public_ip_on_launch = true
+
+  tags = {
+    Name = "mig-subnet-01"
+  }
+}
+
+resource "aws_route_table_association" "mig-vnet-01_mig-subnet-01" {
+  subnet_id      = aws_subnet.mig-vnet-01_mig-subnet-01.id
+  route_table_id = aws_route_table.mig-vnet-01.id
+}
+
+# Security group: mig-sg-01
+resource "aws_security_group" "mig-sg-01" {
+  name        = "mig-sg-01"
+  description = "Web tier ($${env})"
+  vpc_id      = aws_vpc.mig-vnet-01.id
+
+  ingress {
+    protocol    = "tcp"
+    from_port   = 22
+    to_port     = 22
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  ingress {
+    protocol         = "tcp"
+    from_port        = 80
+    to_port          = 80
+    ipv6_cidr_blocks = ["::/0"]
+  }
+
+  ingress {
+    protocol         = "tcp"
+    from_port        = 443
+    to_port          = 443
+    ipv6_cidr_blocks = ["::/0"]
+  }
+
+  ingress {
+    protocol    = "tcp"
+    from_port   = 8080
+    to_port     = 8081
+    cidr_blocks = ["10.0.0.0/16"]
+  }
+
+  ingress {
+    protocol    = "icmp"
+    from_port   = -1
+    to_port     = -1
+    cidr_blocks = ["10.0.0.0/16"]
+  }
+
+  egress {
+    protocol    = "-1"
+    from_port   = 0
+    to_port     = 0
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  egress {
+    protocol         = "-1"
+    from_port        = 0
+    to_port          = 0
+    ipv6_cidr_blocks = ["::/0"]
+  }
+
+  tags = {
+    Name = "mig-sg-01"
+  }
+}
+
+# SSH key: the public key is given by the variable
+resource "aws_key_pair" "mig-sshkey-01" {
+  key_name   = "mig-sshkey-01"
+  public_key = var.ssh_public_key
+}
+
+# Node group: web
+resource "aws_instance" "web" {
+  count = 2
+
+  ami                    = "ami-0123456789abcdef0"
+  instance_type          = "t3.small"
+  subnet_id              = aws_subnet.mig-vnet-01_mig-subnet-01.id
+  vpc_security_group_ids = [aws_security_group.mig-sg-01.id]
+  key_name               = aws_key_pair.mig-sshkey-01.key_name
+
+  root_block_device {
+    volume_size = 50
+  }
+
+  tags = {
+    Name = "web-${count.index + 1}"
+  }
+}
+
+# NLB: web-nlb-80
+resource "aws_lb" "web-nlb-80" {
+  name               = "web-nlb-80"
+  load_balancer_type = "network"
+  internal           = false
+  subnets            = [aws_subnet.mig-vnet-01_mig-subnet-01.id]
+}
+
+resource "aws_lb_target_group" "web-nlb-80" {
+  name     = "web-nlb-80"
+  port     = 8080
+  protocol = "TCP"
+  vpc_id   = aws_vpc.mig-vnet-01.id
+
+  health_check {
+    protocol            = "TCP"
+    port                = "traffic-port"
+    interval            = 10
+    healthy_threshold   = 3
+    unhealthy_threshold = 3
+  }
+}
+
+resource "aws_lb_target_group_attachment" "web-nlb-80_web" {
+  count = length(aws_instance.web)
+
+  target_group_arn = aws_lb_target_group.web-nlb-80.arn
+  target_id        = aws_instance.web[count.index].id
+  port             = 8080
+}
+
+resource "aws_lb_listener" "web-nlb-80" {
+  load_balancer_arn = aws_lb.web-nlb-80.arn
+  port              = 80
+  protocol          = "TCP"
+
+  default_action {
+    type             = "forward"
+    target_group_arn = aws_lb_target_group.web-nlb-80.arn
+  }
+}
+
@@ -0,0 +1,14 @@
+output "web_public_ips" {
+  description = "Public IPs of the nodes of web"
+  value       = aws_instance.web[*].public_ip
+}
+
+output "web_private_ips" {
+  description = "Private IPs of the nodes of web"
+  value       = aws_instance.web[*].private_ip
+}
+
+output "web-nlb-80_dns_name" {
+  description = "DNS name of the NLB web-nlb-80"
+  value       = aws_lb.web-nlb-80.dns_name
+}
@@ -0,0 +1,10 @@
+variable "region" {
+  description = "AWS region"
+  type        = string
+  default     = "ap-northeast-2"
+}
+
+variable "ssh_public_key" {
+  description = "SSH public key (OpenSSH format) to access the nodes"
+  type        = string
+}
@@ -0,0 +1,216 @@
+resource "azurerm_resource_group" "main" {
+  name     = var.resource_group_name
+  location = var.location
+}
+
+# vNet: mig-vnet-01
+resource "azurerm_virtual_network" "mig-vnet-01" {
+  name                = "mig-vnet-01"
+  location            = azurerm_resource_group.main.location
+  resource_group_name = azurerm_resource_group.main.name
+  address_space       = ["10.0.0.0/16"]
+}
+
+resource "azurerm_subnet" "mig-vnet-01_mig-subnet-01" {
+  name                 = "mig-subnet-01"
+  resource_group_name  = azurerm_resource_group.main.name
+  virtual_network_name = azurerm_virtual_network.mig-vnet-01.name
+  address_prefixes     = ["10.0.1.0/24"]
+}
+
+# Security group: mig-sg-01
+resource "azurerm_network_security_group" "mig-sg-01" {
+  name                = "mig-sg-01"
+  location            = azurerm_resource_group.main.location
+  resource_group_name = azurerm_resource_group.main.name
+
+  security_rule {
+    name                       = "inbound-1"
+    priority                   = 100
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_range     = "22"
+    source_address_prefix      = "*"
+    destination_address_prefix = "*"
+  }
+
+  security_rule {
+    name                       = "inbound-2"
+    priority                   = 110
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_ranges    = ["80", "443"]
+    source_address_prefix      = "::/0"
+    destination_address_prefix = "*"
+  }
+
+  security_rule {
+    name                       = "inbound-3"
+    priority                   = 120
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_range     = "8080-8081"
+    source_address_prefix      = "10.0.0.0/16"
+    destination_address_prefix = "*"
+  }
+
+  security_rule {
+    name                       = "inbound-4"
+    priority                   = 130
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Icmp"
+    source_port_range          = "*"
+    destination_port_range     = "*"
+    source_address_prefix      = "10.0.0.0/16"
+    destination_address_prefix = "*"
+  }
+
+  security_rule {
+    name                       = "outbound-5"
+    priority                   = 140
+    direction                  = "Outbound"
+    access                     = "Allow"
+    protocol                   = "*"
+    source_port_range          = "*"
+    destination_port_range     = "*"
+    source_address_prefix      = "*"
+    destination_address_prefix = "*"
+  }
+
+  security_rule {
+    name                       = "outbound-6"
+    priority                   = 150
+    direction                  = "Outbound"
+    access                     = "Allow"
+    protocol                   = "*"
+    source_port_range          = "*"
+    destination_port_range     = "*"
+    source_address_prefix      = "*"
+    destination_address_prefix = "::/0"
+  }
+}
+
+# Node group: web
+resource "azurerm_public_ip" "web" {
+  count = 2
+
+  name                = "web-${count.index + 1}-pip"
+  location            = azurerm_resource_group.main.location
+  resource_group_name = azurerm_resource_group.main.name
+  allocation_method   = "Static"
+  sku                 = "Standard"
+}
+
+resource "azurerm_network_interface" "web" {
+  count = 2
+
+  name                = "web-${count.index + 1}-nic"
+  location            = azurerm_resource_group.main.location
+  resource_group_name = azurerm_resource_group.main.name
+
+  ip_configuration {
+    name                          = "primary"
+    subnet_id                     = azurerm_subnet.mig-vnet-01_mig-subnet-01.id
+    private_ip_address_allocation = "Dynamic"
+    public_ip_address_id          = azurerm_public_ip.web[count.index].id
+  }
+}
+
+resource "azurerm_network_interface_security_group_association" "web" {
+  count = 2
+
+  network_interface_id      = azurerm_network_interface.web[count.index].id
+  network_security_group_id = azurerm_network_security_group.mig-sg-01.id
+}
+
+resource "azurerm_linux_virtual_machine" "web" {
+  count = 2
+
+  name                  = "web-${count.index + 1}"
+  location              = azurerm_resource_group.main.location
+  resource_group_name   = azurerm_resource_group.main.name
+  size                  = "Standard_B2s"
+  admin_username        = var.admin_username
+  network_interface_ids = [azurerm_network_interface.web[count.index].id]
+  zone                  = "1"
+
+  admin_ssh_key {
+    username   = var.admin_username
+    public_key = var.ssh_public_key
+  }
+
+  os_disk {
+    caching              = "ReadWrite"
+    storage_account_type = "StandardSSD_LRS"
+    disk_size_gb         = 50
+  }
+
+  source_image_reference {
+    publisher = "Canonical"
+    offer     = "0001-com-ubuntu-server-jammy"
+    sku       = "22_04-lts-gen2"
+    version   = "latest"
+  }
+}
+
+# NLB: web-nlb-80
+resource "azurerm_public_ip" "web-nlb-80" {
+  name                = "web-nlb-80-pip"
+  location            = azurerm_resource_group.main.location
+  resource_group_name = azurerm_resource_group.main.name
+  allocation_method   = "Static"
+  sku                 = "Standard"
+}
+
+resource "azurerm_lb" "web-nlb-80" {
+  name                = "web-nlb-80"
+  location            = azurerm_resource_group.main.location
+  resource_group_name = azurerm_resource_group.main.name
+  sku                 = "Standard"
+
+  frontend_ip_configuration {
+    name                 = "frontend"
+    public_ip_address_id = azurerm_public_ip.web-nlb-80.id
+  }
+}
+
+resource "azurerm_lb_backend_address_pool" "web-nlb-80" {
+  name            = "backend"
+  loadbalancer_id = azurerm_lb.web-nlb-80.id
+}
+
+resource "azurerm_network_interface_backend_address_pool_association" "web-nlb-80_web" {
+  count = length(azurerm_network_interface.web)
+
+  network_interface_id    = azurerm_network_interface.web[count.index].id
+  ip_configuration_name   = "primary"
+  backend_address_pool_id = azurerm_lb_backend_address_pool.web-nlb-80.id
+}
+
+resource "azurerm_lb_probe" "web-nlb-80" {
+  name                = "probe"
+  loadbalancer_id     = azurerm_lb.web-nlb-80.id
+  protocol            = "Tcp"
+  port                = 8080
+  interval_in_seconds = 10
+  number_of_probes    = 3
+}
+
+resource "azurerm_lb_rule" "web-nlb-80" {
+  name                           = "rule"
+  loadbalancer_id                = azurerm_lb.web-nlb-80.id
+  protocol                       = "Tcp"
+  frontend_port                  = 80
+  backend_port                   = 8080
+  frontend_ip_configuration_name = "frontend"
+  backend_address_pool_ids       = [azurerm_lb_backend_address_pool.web-nlb-80.id]
+  probe_id                       = azurerm_lb_probe.web-nlb-80.id
+}
+
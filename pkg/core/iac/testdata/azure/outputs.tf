@@ -0,0 +1,14 @@
+output "web_public_ips" {
+  description = "Public IPs of the nodes of web"
+  value       = azurerm_public_ip.web[*].ip_address
+}
+
+output "web_private_ips" {
+  description = "Private IPs of the nodes of web"
+  value       = azurerm_network_interface.web[*].private_ip_address
+}
+
+output "web-nlb-80_ip" {
+  description = "Frontend IP of the NLB web-nlb-80"
+  value       = azurerm_public_ip.web-nlb-80.ip_address
+}
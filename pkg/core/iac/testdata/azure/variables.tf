@@ -0,0 +1,28 @@
+variable "subscription_id" {
+  description = "Azure subscription ID"
+  type        = string
+  sensitive   = true
+}
+
+variable "location" {
+  description = "Azure location (region)"
+  type        = string
+  default     = "koreacentral"
+}
+
+variable "resource_group_name" {
+  description = "Name of the resource group to create"
+  type        = string
+  default     = "web-infra-rg"
+}
+
+variable "admin_username" {
+  description = "Admin user name of the nodes"
+  type        = string
+  default     = "cb-user"
+}
+
+variable "ssh_public_key" {
+  description = "SSH public key (OpenSSH format) to access the nodes"
+  type        = string
+}
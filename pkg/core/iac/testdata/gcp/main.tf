@@ -0,0 +1,170 @@
+# vNet: mig-vnet-01 (the CIDR block 10.0.0.0/16 is covered by the subnets; VPC networks of GCP are global)
+resource "google_compute_network" "mig-vnet-01" {
+  name                    = "mig-vnet-01"
+  auto_create_subnetworks = false
+}
+
+resource "google_compute_subnetwork" "mig-vnet-01_mig-subnet-01" {
+  name          = "mig-subnet-01"
+  network       = google_compute_network.mig-vnet-01.id
+  region        = var.region
+  ip_cidr_range = "10.0.1.0/24"
+}
+
+# Security group: mig-sg-01 (network tag: mig-sg-01)
+resource "google_compute_firewall" "mig-sg-01-in-1" {
+  name               = "mig-sg-01-in-1"
+  network            = google_compute_network.mig-vnet-01.id
+  direction          = "INGRESS"
+  source_ranges      = ["0.0.0.0/0"]
+  target_tags        = ["mig-sg-01"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+}
+
+resource "google_compute_firewall" "mig-sg-01-in-2" {
+  name               = "mig-sg-01-in-2"
+  network            = google_compute_network.mig-vnet-01.id
+  direction          = "INGRESS"
+  source_ranges      = ["::/0"]
+  target_tags        = ["mig-sg-01"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80", "443"]
+  }
+}
+
+resource "google_compute_firewall" "mig-sg-01-in-3" {
+  name               = "mig-sg-01-in-3"
+  network            = google_compute_network.mig-vnet-01.id
+  direction          = "INGRESS"
+  source_ranges      = ["10.0.0.0/16"]
+  target_tags        = ["mig-sg-01"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["8080-8081"]
+  }
+}
+
+resource "google_compute_firewall" "mig-sg-01-in-4" {
+  name               = "mig-sg-01-in-4"
+  network            = google_compute_network.mig-vnet-01.id
+  direction          = "INGRESS"
+  source_ranges      = ["10.0.0.0/16"]
+  target_tags        = ["mig-sg-01"]
+
+  allow {
+    protocol = "icmp"
+  }
+}
+
+resource "google_compute_firewall" "mig-sg-01-eg-5" {
+  name               = "mig-sg-01-eg-5"
+  network            = google_compute_network.mig-vnet-01.id
+  direction          = "EGRESS"
+  destination_ranges = ["0.0.0.0/0"]
+  target_tags        = ["mig-sg-01"]
+
+  allow {
+    protocol = "all"
+  }
+}
+
+resource "google_compute_firewall" "mig-sg-01-eg-6" {
+  name               = "mig-sg-01-eg-6"
+  network            = google_compute_network.mig-vnet-01.id
+  direction          = "EGRESS"
+  destination_ranges = ["::/0"]
+  target_tags        = ["mig-sg-01"]
+
+  allow {
+    protocol = "all"
+  }
+}
+
+# Node group: web
+resource "google_compute_instance" "web" {
+  count = 2
+
+  name         = "web-${count.index + 1}"
+  machine_type = "e2-small"
+  zone         = "asia-northeast3-a"
+  tags         = ["mig-sg-01"]
+
+  boot_disk {
+    initialize_params {
+      image = "projects/ubuntu-os-cloud/global/images/family/ubuntu-2204-lts"
+      size  = 50
+    }
+  }
+
+  network_interface {
+    subnetwork = google_compute_subnetwork.mig-vnet-01_mig-subnet-01.id
+
+    access_config {}
+  }
+
+  metadata = {
+    ssh-keys = "${var.ssh_username}:${var.ssh_public_key}"
+  }
+}
+
+# NLB: web-nlb-80
+resource "google_compute_instance_group" "web-nlb-80_web" {
+  name      = "web-nlb-80-web"
+  zone      = "asia-northeast3-a"
+  instances = google_compute_instance.web[*].self_link
+}
+
+resource "google_compute_region_health_check" "web-nlb-80" {
+  name                = "web-nlb-80-hc"
+  region              = var.region
+  check_interval_sec  = 10
+  timeout_sec         = 5
+  unhealthy_threshold = 3
+
+  tcp_health_check {
+    port = 80
+  }
+}
+
+resource "google_compute_firewall" "web-nlb-80_hc" {
+  name          = "web-nlb-80-hc"
+  network       = google_compute_network.mig-vnet-01.id
+  direction     = "INGRESS"
+  source_ranges = ["35.191.0.0/16", "130.211.0.0/22"]
+  target_tags   = ["mig-sg-01"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80"]
+  }
+}
+
+resource "google_compute_region_backend_service" "web-nlb-80" {
+  name                  = "web-nlb-80"
+  region                = var.region
+  protocol              = "TCP"
+  load_balancing_scheme = "EXTERNAL"
+  health_checks         = [google_compute_region_health_check.web-nlb-80.id]
+
+  backend {
+    group          = google_compute_instance_group.web-nlb-80_web.id
+    balancing_mode = "CONNECTION"
+  }
+}
+
+resource "google_compute_forwarding_rule" "web-nlb-80" {
+  name                  = "web-nlb-80"
+  region                = var.region
+  load_balancing_scheme = "EXTERNAL"
+  ip_protocol           = "TCP"
+  ports                 = ["80"]
+  backend_service       = google_compute_region_backend_service.web-nlb-80.id
+}
+
@@ -0,0 +1,14 @@
+output "web_public_ips" {
+  description = "Public IPs of the nodes of web"
+  value       = google_compute_instance.web[*].network_interface[0].access_config[0].nat_ip
+}
+
+output "web_private_ips" {
+  description = "Private IPs of the nodes of web"
+  value       = google_compute_instance.web[*].network_interface[0].network_ip
+}
+
+output "web-nlb-80_ip" {
+  description = "IP of the NLB web-nlb-80"
+  value       = google_compute_forwarding_rule.web-nlb-80.ip_address
+}
@@ -0,0 +1,27 @@
+variable "project_id" {
+  description = "GCP project ID"
+  type        = string
+}
+
+variable "region" {
+  description = "GCP region"
+  type        = string
+  default     = "asia-northeast3"
+}
+
+variable "zone" {
+  description = "GCP zone of the nodes whose subnet has no zone"
+  type        = string
+  default     = "asia-northeast3-a"
+}
+
+variable "ssh_username" {
+  description = "SSH user name of the nodes"
+  type        = string
+  default     = "cb-user"
+}
+
+variable "ssh_public_key" {
+  description = "SSH public key (OpenSSH format) to access the nodes"
+  type        = string
+}
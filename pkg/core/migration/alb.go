@@ -0,0 +1,248 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration is to provision target multi-cloud infra for migration
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/rs/zerolog/log"
+)
+
+// Provisioning results of an ALB
+const (
+	AlbProvisioningL4Fallback = "l4-fallback" // Provisioned as an L4 NLB forwarding to the default target group
+	AlbProvisioningExisting   = "existing"    // An NLB with the same listener port already exists (e.g., created via the NLB migration)
+	AlbProvisioningFailed     = "failed"
+)
+
+// ============================================================================
+// Core functions
+// ============================================================================
+
+// CreateAlbs migrates L7 ALBs to the target cloud infra.
+//
+// * Note: CB-Tumblebug provisions L4 NLBs only. Each ALB listener is provisioned as an L4 NLB
+// forwarding to its default target group, and the L7 features (host/path routing rules,
+// TLS termination, HTTP health check paths, non-default target groups) are returned as
+// manual actions per ALB. For CSPs marked "l4-only", the L7 features have no managed equivalent.
+func CreateAlbs(nsId, infraId string, req cloudmodel.RecommendedAlb) (cloudmodel.MigratedAlbResult, error) {
+	log.Info().
+		Str("nsId", nsId).
+		Str("infraId", infraId).
+		Int("count", len(req.TargetAlbList)).
+		Msg("Starting ALB migration")
+
+	// Existing NLBs by listener port (to avoid duplicating NLBs created via the NLB migration)
+	existingByPort := map[string]cloudmodel.MigratedNlbInfo{}
+	if existing, err := ListNlbs(nsId, infraId); err != nil {
+		log.Warn().Err(err).Msg("Failed to list existing NLBs; duplicate listener ports will not be detected")
+	} else {
+		for _, nlb := range existing {
+			existingByPort[nlb.Listener.Port] = nlb
+		}
+	}
+
+	var albList []cloudmodel.MigratedAlbInfo
+	var errs []string
+	provisioned := 0
+
+	for i, alb := range req.TargetAlbList {
+		info := cloudmodel.MigratedAlbInfo{
+			Name:          alb.Name,
+			L7Support:     alb.L7Support,
+			ManualActions: buildAlbManualActions(alb),
+		}
+
+		target, err := defaultAlbTargetGroup(alb)
+		if err != nil {
+			msg := fmt.Sprintf("ALB[%d] (%s): %v", i, alb.Name, err)
+			log.Warn().Msg(msg)
+			errs = append(errs, msg)
+			info.Provisioning = AlbProvisioningFailed
+			info.Error = err.Error()
+			albList = append(albList, info)
+			continue
+		}
+
+		if nlb, ok := existingByPort[alb.Listener.Port]; ok {
+			log.Info().
+				Str("alb", alb.Name).
+				Str("nlbId", nlb.Id).
+				Str("listenerPort", alb.Listener.Port).
+				Msg("NLB with the same listener port already exists; skipping L4 provisioning")
+			info.Provisioning = AlbProvisioningExisting
+			info.Nlb = &nlb
+			albList = append(albList, info)
+			provisioned++
+			continue
+		}
+
+		log.Debug().
+			Int("index", i+1).
+			Int("total", len(req.TargetAlbList)).
+			Str("alb", alb.Name).
+			Str("listenerPort", alb.Listener.Port).
+			Str("nodeGroupId", target.NodeGroupId).
+			Msg("Creating L4 NLB for ALB")
+
+		tbReq := toTumblebugNLBReq(cloudmodel.NlbReq{
+			Description: fmt.Sprintf("L4 fallback for ALB %s: %s", alb.Name, alb.Description),
+			Type:        alb.Type,
+			Scope:       alb.Scope,
+			Listener: cloudmodel.NlbListenerReq{
+				Protocol: "TCP",
+				Port:     alb.Listener.Port,
+			},
+			TargetGroup: cloudmodel.NlbTargetGroupReq{
+				Protocol:    target.Protocol,
+				Port:        target.Port,
+				NodeGroupId: target.NodeGroupId,
+			},
+			HealthChecker: cloudmodel.NlbHealthCheckerReq{
+				Interval:  target.HealthChecker.Interval,
+				Threshold: target.HealthChecker.Threshold,
+				Timeout:   target.HealthChecker.Timeout,
+			},
+		})
+		created, err := tbclient.NewSession().CreateNlb(nsId, infraId, tbReq)
+		if err != nil {
+			msg := fmt.Sprintf("ALB[%d] (%s, listenerPort=%s, nodeGroupId=%s): %v",
+				i, alb.Name, alb.Listener.Port, target.NodeGroupId, err)
+			log.Error().Err(err).Msg("Failed to create L4 NLB for ALB")
+			errs = append(errs, msg)
+			info.Provisioning = AlbProvisioningFailed
+			info.Error = err.Error()
+			albList = append(albList, info)
+			continue
+		}
+
+		nlb := toMigratedNlbInfo(created)
+		info.Provisioning = AlbProvisioningL4Fallback
+		info.Nlb = &nlb
+		albList = append(albList, info)
+		provisioned++
+
+		log.Info().
+			Str("alb", alb.Name).
+			Str("nlbId", created.Id).
+			Str("listenerPort", alb.Listener.Port).
+			Int("manualActions", len(info.ManualActions)).
+			Msg("ALB provisioned as L4 NLB")
+	}
+
+	// Determine overall status
+	status := "created"
+	switch {
+	case provisioned == 0:
+		status = "failed"
+	case len(errs) > 0:
+		status = "partial"
+	}
+
+	desc := fmt.Sprintf("%d ALB(s) provisioned as L4 NLB(s); L7 settings must be applied manually", provisioned)
+	if len(errs) > 0 {
+		desc += fmt.Sprintf("; %d failed", len(errs))
+	}
+
+	result := cloudmodel.MigratedAlbResult{
+		Status:      status,
+		Description: desc,
+		AlbList:     albList,
+	}
+
+	if status == "failed" {
+		return result, fmt.Errorf("all ALB migrations failed: %s", strings.Join(errs, "; "))
+	}
+
+	log.Info().
+		Str("nsId", nsId).
+		Str("infraId", infraId).
+		Int("provisioned", provisioned).
+		Int("failed", len(errs)).
+		Msg("ALB migration completed")
+
+	return result, nil
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// defaultAlbTargetGroup returns the default target group of an ALB.
+func defaultAlbTargetGroup(alb cloudmodel.AlbReq) (cloudmodel.AlbTargetGroupReq, error) {
+	for _, tg := range alb.TargetGroups {
+		if tg.Name == alb.DefaultTargetGroup {
+			if tg.NodeGroupId == "" {
+				return tg, fmt.Errorf("targetGroup '%s' has no nodeGroupId", tg.Name)
+			}
+			return tg, nil
+		}
+	}
+	return cloudmodel.AlbTargetGroupReq{}, fmt.Errorf("default target group '%s' not found in targetGroups", alb.DefaultTargetGroup)
+}
+
+// buildAlbManualActions lists the L7 features of an ALB that are not provisioned by the L4 fallback.
+func buildAlbManualActions(alb cloudmodel.AlbReq) []string {
+	var actions []string
+
+	if alb.L7Support == cloudmodel.L7SupportL4Only {
+		actions = append(actions,
+			"[L4-only CSP] No managed L7 load balancer is available; deploy a self-hosted proxy (e.g., HAProxy or nginx) to serve the L7 features below.")
+	} else if alb.CspService != "" {
+		actions = append(actions, fmt.Sprintf(
+			"Create a %s for listener %s/%s and replace the L4 NLB after verification.",
+			alb.CspService, alb.Listener.Protocol, alb.Listener.Port))
+	}
+
+	if alb.Listener.Certificate != nil {
+		hosts := ""
+		if len(alb.Listener.Certificate.Hosts) > 0 {
+			hosts = fmt.Sprintf(" covering %s", strings.Join(alb.Listener.Certificate.Hosts, ", "))
+		}
+		actions = append(actions, fmt.Sprintf(
+			"Terminate TLS on port %s with a certificate%s (placeholder: %s).",
+			alb.Listener.Port, hosts, alb.Listener.Certificate.Placeholder))
+	}
+
+	for _, tg := range alb.TargetGroups {
+		if tg.Name != alb.DefaultTargetGroup {
+			actions = append(actions, fmt.Sprintf(
+				"Create target group '%s' (%s:%s, nodeGroupId=%s); the L4 NLB forwards to '%s' only.",
+				tg.Name, tg.Protocol, tg.Port, tg.NodeGroupId, alb.DefaultTargetGroup))
+		}
+		if tg.HealthChecker.Protocol == "HTTP" && tg.HealthChecker.Path != "" {
+			actions = append(actions, fmt.Sprintf(
+				"Configure HTTP health check path '%s' for target group '%s'.", tg.HealthChecker.Path, tg.Name))
+		}
+	}
+
+	for _, rule := range alb.Rules {
+		var conditions []string
+		if len(rule.Hosts) > 0 {
+			conditions = append(conditions, "host in ["+strings.Join(rule.Hosts, ", ")+"]")
+		}
+		if len(rule.PathPrefixes) > 0 {
+			conditions = append(conditions, "path prefix in ["+strings.Join(rule.PathPrefixes, ", ")+"]")
+		}
+		actions = append(actions, fmt.Sprintf(
+			"Add routing rule #%d: %s → target group '%s'.",
+			rule.Priority, strings.Join(conditions, " AND "), rule.TargetGroup))
+	}
+
+	return actions
+}
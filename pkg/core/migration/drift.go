@@ -0,0 +1,485 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/lkvstore"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Drift detection
+// Compares the target infrastructure model applied by the infra migration
+// (stored on success) with the live infrastructure in Tumblebug, and reports
+// the resources added, removed or changed afterwards (e.g., in the CSP console).
+// ============================================================================
+
+const appliedModelKeyPrefix = "/beetle/migration/model/"
+
+// Changes of a drifted resource
+const (
+	DriftAdded   = "added"
+	DriftRemoved = "removed"
+	DriftChanged = "changed"
+)
+
+// Types of a drifted resource
+const (
+	DriftResourceVNet          = "vNet"
+	DriftResourceSubnet        = "subnet"
+	DriftResourceSecurityGroup = "securityGroup"
+	DriftResourceFirewallRule  = "firewallRule"
+	DriftResourceNodeGroup     = "nodeGroup"
+	DriftResourceNode          = "node"
+)
+
+// DriftItem is a difference between the applied model and the live infrastructure.
+type DriftItem struct {
+	ResourceType string `json:"resourceType" example:"firewallRule"`
+	ResourceId   string `json:"resourceId" example:"mig-sg-01"`
+	Change       string `json:"change" enums:"added,removed,changed" example:"added"`
+	Field        string `json:"field,omitempty" example:"specId"`
+	Expected     string `json:"expected,omitempty" example:"aws+ap-northeast-2+t3a.xlarge"`
+	Actual       string `json:"actual,omitempty" example:"aws+ap-northeast-2+t3a.2xlarge"`
+}
+
+// DriftSummary is the number of drift items per change.
+type DriftSummary struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// RemediationAction is an action to bring a drifted resource back to the applied model.
+type RemediationAction struct {
+	ResourceType string `json:"resourceType" example:"firewallRule"`
+	ResourceId   string `json:"resourceId" example:"mig-sg-01"`
+	Action       string `json:"action" example:"delete"`
+	Detail       string `json:"detail" example:"inbound tcp 3389 from 0.0.0.0/0"`
+}
+
+// DriftRemediation is the remediation of the drift.
+// The model contains the applied (desired) state of the drifted vNets, security groups and NodeGroups only.
+type DriftRemediation struct {
+	Actions []RemediationAction         `json:"actions"`
+	Model   cloudmodel.RecommendedInfra `json:"model"`
+}
+
+// DriftReport is the drift between the applied model and the live infrastructure.
+type DriftReport struct {
+	NsId        string            `json:"nsId" example:"mig01"`
+	InfraId     string            `json:"infraId" example:"mmci01"`
+	CheckedAt   time.Time         `json:"checkedAt"`
+	AppliedAt   time.Time         `json:"appliedAt"`
+	Drifted     bool              `json:"drifted"`
+	Summary     DriftSummary      `json:"summary"`
+	Items       []DriftItem       `json:"items"`
+	Remediation *DriftRemediation `json:"remediation,omitempty"`
+}
+
+// AppliedInfraModel is the target infrastructure model applied by the infra migration.
+type AppliedInfraModel struct {
+	NsId      string                      `json:"nsId"`
+	InfraId   string                      `json:"infraId"`
+	AppliedAt time.Time                   `json:"appliedAt"`
+	Model     cloudmodel.RecommendedInfra `json:"model"`
+}
+
+// putAppliedModel stores the target infrastructure model applied to the Infra (without the SSH private key).
+func putAppliedModel(nsId, infraId string, targetInfraModel *cloudmodel.RecommendedInfra) {
+	applied := AppliedInfraModel{
+		NsId:      nsId,
+		InfraId:   infraId,
+		AppliedAt: time.Now(),
+		Model:     *targetInfraModel,
+	}
+	applied.Model.TargetSshKey.PrivateKey = ""
+
+	if err := lkvstore.Put(appliedModelKeyPrefix+nsId+"/"+infraId, applied); err != nil {
+		log.Error().Err(err).Msgf("failed to store the applied model (nsId: %s, infraId: %s)", nsId, infraId)
+	}
+}
+
+// deleteAppliedModel deletes the applied model of the Infra.
+func deleteAppliedModel(nsId, infraId string) {
+	lkvstore.Delete(appliedModelKeyPrefix + nsId + "/" + infraId)
+}
+
+// GetAppliedModel returns the target infrastructure model applied to the Infra by the infra migration.
+func GetAppliedModel(nsId, infraId string) (AppliedInfraModel, bool) {
+	value, ok := lkvstore.Get(appliedModelKeyPrefix + nsId + "/" + infraId)
+	if !ok {
+		return AppliedInfraModel{}, false
+	}
+	return convertToAppliedInfraModel(value)
+}
+
+// convertToAppliedInfraModel converts the stored value (a struct, or a map once loaded from the file) to AppliedInfraModel.
+func convertToAppliedInfraModel(value any) (AppliedInfraModel, bool) {
+	switch v := value.(type) {
+	case AppliedInfraModel:
+		return v, true
+	case map[string]any:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to marshal value to JSON")
+			return AppliedInfraModel{}, false
+		}
+
+		var applied AppliedInfraModel
+		if err := json.Unmarshal(jsonBytes, &applied); err != nil {
+			log.Error().Err(err).Msg("Failed to unmarshal JSON to AppliedInfraModel")
+			return AppliedInfraModel{}, false
+		}
+		return applied, true
+	default:
+		log.Error().Msgf("Unexpected value type: %T", value)
+		return AppliedInfraModel{}, false
+	}
+}
+
+// DetectDrift compares the applied model of the Infra with the live Infra, security groups and vNets.
+// The remediation (actions and the desired model of the drifted resources) is generated if withRemediation is set.
+func DetectDrift(nsId, infraId string, withRemediation bool) (DriftReport, error) {
+	log.Info().Msgf("Detecting the drift of the infrastructure (nsId: %s, infraId: %s)", nsId, infraId)
+
+	applied, ok := GetAppliedModel(nsId, infraId)
+	if !ok {
+		return DriftReport{}, fmt.Errorf("the applied model does not exist (nsId: %s, infraId: %s); only the infrastructure migrated by Beetle can be checked", nsId, infraId)
+	}
+	model := applied.Model
+
+	infraInfo, err := GetVMInfra(nsId, infraId)
+	if err != nil {
+		return DriftReport{}, fmt.Errorf("failed to read the infrastructure (nsId: %s, infraId: %s): %w", nsId, infraId, err)
+	}
+
+	report := DriftReport{
+		NsId:      nsId,
+		InfraId:   infraId,
+		CheckedAt: time.Now(),
+		AppliedAt: applied.AppliedAt,
+		Items:     []DriftItem{},
+	}
+
+	driftedVNets, err := diffVNets(nsId, targetVNets(&model), &report)
+	if err != nil {
+		return DriftReport{}, err
+	}
+	driftedSgs, err := diffSecurityGroups(nsId, model.TargetSecurityGroupList, infraInfo.Node, &report)
+	if err != nil {
+		return DriftReport{}, err
+	}
+	driftedNodeGroups := diffNodeGroups(model.TargetInfra.NodeGroups, infraInfo.Node, &report)
+
+	for _, item := range report.Items {
+		switch item.Change {
+		case DriftAdded:
+			report.Summary.Added++
+		case DriftRemoved:
+			report.Summary.Removed++
+		case DriftChanged:
+			report.Summary.Changed++
+		}
+	}
+	report.Drifted = len(report.Items) > 0
+
+	if withRemediation {
+		report.Remediation = buildRemediation(model, report.Items, driftedVNets, driftedSgs, driftedNodeGroups)
+	}
+
+	log.Info().Msgf("Detected the drift of the infrastructure (nsId: %s, infraId: %s, added: %d, removed: %d, changed: %d)",
+		nsId, infraId, report.Summary.Added, report.Summary.Removed, report.Summary.Changed)
+	return report, nil
+}
+
+// diffVNets compares the vNets and their subnets, and returns the names of the drifted vNets.
+// A vNet is removed only if Tumblebug confirms it is not found; the other read errors are returned.
+func diffVNets(nsId string, vNets []cloudmodel.VNetReq, report *DriftReport) (map[string]bool, error) {
+	drifted := map[string]bool{}
+	add := func(item DriftItem, vNetName string) {
+		report.Items = append(report.Items, item)
+		drifted[vNetName] = true
+	}
+
+	for _, vNet := range vNets {
+		vNetInfo, err := tbclient.NewSession().ReadVNet(nsId, vNet.Name)
+		if err != nil && !isNotFound(err) {
+			return nil, fmt.Errorf("failed to read the vNet (nsId: %s, vNetId: %s): %w", nsId, vNet.Name, err)
+		}
+		if err != nil || vNetInfo.Id == "" {
+			add(DriftItem{ResourceType: DriftResourceVNet, ResourceId: vNet.Name, Change: DriftRemoved, Expected: vNet.CidrBlock}, vNet.Name)
+			continue
+		}
+		if vNet.CidrBlock != "" && vNetInfo.CidrBlock != vNet.CidrBlock {
+			add(DriftItem{ResourceType: DriftResourceVNet, ResourceId: vNet.Name, Change: DriftChanged,
+				Field: "cidrBlock", Expected: vNet.CidrBlock, Actual: vNetInfo.CidrBlock}, vNet.Name)
+		}
+
+		liveSubnets := map[string]string{}
+		for _, subnet := range vNetInfo.SubnetInfoList {
+			liveSubnets[subnet.Name] = subnet.IPv4_CIDR
+		}
+		for _, subnet := range vNet.SubnetInfoList {
+			subnetId := vNet.Name + "/" + subnet.Name
+			cidr, exists := liveSubnets[subnet.Name]
+			switch {
+			case !exists:
+				add(DriftItem{ResourceType: DriftResourceSubnet, ResourceId: subnetId, Change: DriftRemoved, Expected: subnet.IPv4_CIDR}, vNet.Name)
+			case cidr != subnet.IPv4_CIDR:
+				add(DriftItem{ResourceType: DriftResourceSubnet, ResourceId: subnetId, Change: DriftChanged,
+					Field: "ipv4_CIDR", Expected: subnet.IPv4_CIDR, Actual: cidr}, vNet.Name)
+			}
+			delete(liveSubnets, subnet.Name)
+		}
+		for _, name := range sortedKeys(liveSubnets) {
+			add(DriftItem{ResourceType: DriftResourceSubnet, ResourceId: vNet.Name + "/" + name, Change: DriftAdded, Actual: liveSubnets[name]}, vNet.Name)
+		}
+	}
+	return drifted, nil
+}
+
+// diffSecurityGroups compares the firewall rules of the security groups, and the security groups attached to the nodes,
+// and returns the names of the drifted security groups.
+// A security group is removed only if Tumblebug confirms it is not found; the other read errors are returned.
+func diffSecurityGroups(nsId string, sgReqs []cloudmodel.SecurityGroupReq, nodes []cloudmodel.NodeInfo, report *DriftReport) (map[string]bool, error) {
+	drifted := map[string]bool{}
+	add := func(item DriftItem, sgName string) {
+		report.Items = append(report.Items, item)
+		drifted[sgName] = true
+	}
+
+	expectedSgs := map[string]bool{}
+	for _, sgReq := range sgReqs {
+		expectedSgs[sgReq.Name] = true
+
+		sgInfo, err := tbclient.NewSession().ReadSecurityGroup(nsId, sgReq.Name)
+		if err != nil && !isNotFound(err) {
+			return nil, fmt.Errorf("failed to read the security group (nsId: %s, securityGroupId: %s): %w", nsId, sgReq.Name, err)
+		}
+		if err != nil || sgInfo.Id == "" {
+			add(DriftItem{ResourceType: DriftResourceSecurityGroup, ResourceId: sgReq.Name, Change: DriftRemoved}, sgReq.Name)
+			continue
+		}
+
+		// The SSH access rule added on the migration is a part of the applied rules
+		expectedRules := map[string]bool{}
+		for _, rule := range AppliedFirewallRules(sgReq) {
+			for _, key := range firewallRuleKeys(rule.Direction, rule.Protocol, rule.Ports, rule.CIDR) {
+				expectedRules[key] = true
+			}
+		}
+		liveRules := map[string]bool{}
+		for _, rule := range sgInfo.FirewallRules {
+			for _, key := range firewallRuleKeys(rule.Direction, rule.Protocol, rule.Port, rule.CIDR) {
+				liveRules[key] = true
+			}
+		}
+
+		for _, key := range sortedKeys(expectedRules) {
+			if !liveRules[key] {
+				add(DriftItem{ResourceType: DriftResourceFirewallRule, ResourceId: sgReq.Name, Change: DriftRemoved, Expected: key}, sgReq.Name)
+			}
+		}
+		for _, key := range sortedKeys(liveRules) {
+			if !expectedRules[key] {
+				add(DriftItem{ResourceType: DriftResourceFirewallRule, ResourceId: sgReq.Name, Change: DriftAdded, Actual: key}, sgReq.Name)
+			}
+		}
+	}
+
+	// The security groups attached to the nodes afterwards
+	for _, node := range nodes {
+		for _, sgId := range node.SecurityGroupIds {
+			if !expectedSgs[sgId] {
+				report.Items = append(report.Items, DriftItem{ResourceType: DriftResourceSecurityGroup, ResourceId: sgId,
+					Change: DriftAdded, Field: "node", Actual: node.Id})
+			}
+		}
+	}
+	return drifted, nil
+}
+
+// isNotFound reports whether the error of the Tumblebug client is a 404 (Not Found) response.
+func isNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "status: 404")
+}
+
+// AppliedFirewallRules returns the firewall rules of the security group as applied by the migration.
+func AppliedFirewallRules(sgReq cloudmodel.SecurityGroupReq) []cloudmodel.FirewallRuleReq {
+	// Copy the rules, since checkAndSupportSSHAccessRule appends to them
+	if sgReq.FirewallRules != nil {
+		rules := append([]cloudmodel.FirewallRuleReq{}, *sgReq.FirewallRules...)
+		sgReq.FirewallRules = &rules
+	}
+	sgReq = checkAndSupportSSHAccessRule(sgReq)
+	return *sgReq.FirewallRules
+}
+
+// firewallRuleKeys normalizes a firewall rule to the keys (e.g., "inbound tcp 22 from 0.0.0.0/0"),
+// one per port or port range, since the comma-separated ports are split into the rules by the CSPs.
+func firewallRuleKeys(direction, protocol, ports, cidr string) []string {
+	direction = strings.ToLower(strings.TrimSpace(direction))
+	protocol = strings.ToLower(strings.TrimSpace(protocol))
+	if protocol == "-1" || protocol == "*" {
+		protocol = "all"
+	}
+	if cidr == "" {
+		cidr = "0.0.0.0/0"
+	}
+	preposition := "from"
+	if direction == "outbound" {
+		preposition = "to"
+	}
+
+	var keys []string
+	for _, port := range strings.Split(ports, ",") {
+		port = strings.TrimSpace(port)
+		if port == "" || port == "-1" || port == "*" || port == "1-65535" || protocol == "all" || protocol == "icmp" {
+			port = "*"
+		}
+		// e.g., "22-22" is the same as "22"
+		if from, to, found := strings.Cut(port, "-"); found && from == to {
+			port = from
+		}
+		keys = append(keys, fmt.Sprintf("%s %s %s %s %s", direction, protocol, port, preposition, cidr))
+	}
+	return keys
+}
+
+// diffNodeGroups compares the node counts, specs, images and root disk sizes of the NodeGroups,
+// and returns the names of the drifted NodeGroups.
+func diffNodeGroups(nodeGroups []cloudmodel.CreateNodeGroupReq, nodes []cloudmodel.NodeInfo, report *DriftReport) map[string]bool {
+	drifted := map[string]bool{}
+	add := func(item DriftItem, nodeGroupName string) {
+		report.Items = append(report.Items, item)
+		drifted[nodeGroupName] = true
+	}
+
+	liveNodes := map[string][]cloudmodel.NodeInfo{}
+	for _, node := range nodes {
+		liveNodes[node.NodeGroupId] = append(liveNodes[node.NodeGroupId], node)
+	}
+
+	for _, ng := range nodeGroups {
+		expectedSize := ng.NodeGroupSize
+		if expectedSize == 0 {
+			expectedSize = 1
+		}
+		groupNodes := liveNodes[ng.Name]
+		delete(liveNodes, ng.Name)
+
+		switch {
+		case len(groupNodes) == 0:
+			add(DriftItem{ResourceType: DriftResourceNodeGroup, ResourceId: ng.Name, Change: DriftRemoved,
+				Expected: fmt.Sprintf("%d node(s)", expectedSize)}, ng.Name)
+			continue
+		case len(groupNodes) != expectedSize:
+			add(DriftItem{ResourceType: DriftResourceNodeGroup, ResourceId: ng.Name, Change: DriftChanged, Field: "nodeGroupSize",
+				Expected: fmt.Sprintf("%d", expectedSize), Actual: fmt.Sprintf("%d", len(groupNodes))}, ng.Name)
+		}
+
+		for _, node := range groupNodes {
+			if node.SpecId != ng.SpecId {
+				add(DriftItem{ResourceType: DriftResourceNode, ResourceId: node.Id, Change: DriftChanged,
+					Field: "specId", Expected: ng.SpecId, Actual: node.SpecId}, ng.Name)
+			}
+			if node.ImageId != ng.ImageId {
+				add(DriftItem{ResourceType: DriftResourceNode, ResourceId: node.Id, Change: DriftChanged,
+					Field: "imageId", Expected: ng.ImageId, Actual: node.ImageId}, ng.Name)
+			}
+			if ng.RootDiskSize > 0 && node.RootDiskSize != ng.RootDiskSize {
+				add(DriftItem{ResourceType: DriftResourceNode, ResourceId: node.Id, Change: DriftChanged,
+					Field: "rootDiskSize", Expected: fmt.Sprintf("%d", ng.RootDiskSize), Actual: fmt.Sprintf("%d", node.RootDiskSize)}, ng.Name)
+			}
+		}
+	}
+
+	// The NodeGroups added afterwards
+	for _, name := range sortedKeys(liveNodes) {
+		report.Items = append(report.Items, DriftItem{ResourceType: DriftResourceNodeGroup, ResourceId: name, Change: DriftAdded,
+			Actual: fmt.Sprintf("%d node(s)", len(liveNodes[name]))})
+	}
+	return drifted
+}
+
+// buildRemediation builds the actions to revert the drift items,
+// and the model of the applied (desired) state of the drifted resources.
+func buildRemediation(model cloudmodel.RecommendedInfra, items []DriftItem, driftedVNets, driftedSgs, driftedNodeGroups map[string]bool) *DriftRemediation {
+	remediation := &DriftRemediation{Actions: []RemediationAction{}}
+
+	for _, item := range items {
+		action := RemediationAction{ResourceType: item.ResourceType, ResourceId: item.ResourceId}
+		switch {
+		case item.ResourceType == DriftResourceFirewallRule && item.Change == DriftAdded:
+			action.Action, action.Detail = "delete", item.Actual
+		case item.ResourceType == DriftResourceFirewallRule && item.Change == DriftRemoved:
+			action.Action, action.Detail = "create", item.Expected
+		case item.ResourceType == DriftResourceSecurityGroup && item.Change == DriftAdded:
+			action.Action, action.Detail = "detach", fmt.Sprintf("from the node %s", item.Actual)
+		case item.ResourceType == DriftResourceNodeGroup && item.Change == DriftAdded:
+			action.Action, action.Detail = "delete", fmt.Sprintf("the NodeGroup not in the applied model (%s)", item.Actual)
+		case item.ResourceType == DriftResourceNodeGroup && item.Change == DriftChanged:
+			action.Action, action.Detail = "scale", fmt.Sprintf("the NodeGroup from %s to %s node(s)", item.Actual, item.Expected)
+		case item.ResourceType == DriftResourceNode && item.Field == "specId":
+			action.Action, action.Detail = "resize", fmt.Sprintf("the node from %s to %s", item.Actual, item.Expected)
+		case item.ResourceType == DriftResourceNode:
+			action.Action, action.Detail = "recreate", fmt.Sprintf("the node with %s %s", item.Field, item.Expected)
+		case item.Change == DriftRemoved:
+			action.Action, action.Detail = "create", fmt.Sprintf("the %s as applied %s", item.ResourceType, item.Expected)
+		case item.Change == DriftAdded:
+			action.Action, action.Detail = "delete", fmt.Sprintf("the %s not in the applied model %s", item.ResourceType, item.Actual)
+		default:
+			action.Action, action.Detail = "update", fmt.Sprintf("%s from %s to %s", item.Field, item.Actual, item.Expected)
+		}
+		action.Detail = strings.TrimSpace(action.Detail)
+		remediation.Actions = append(remediation.Actions, action)
+	}
+
+	// The applied state of the drifted resources only
+	desired := cloudmodel.RecommendedInfra{
+		Status:      model.Status,
+		Description: fmt.Sprintf("Remediation of the drift of the infrastructure (%s)", model.TargetInfra.Name),
+		TargetCloud: model.TargetCloud,
+		TargetInfra: model.TargetInfra,
+	}
+	desired.TargetInfra.NodeGroups = nil
+	for _, ng := range model.TargetInfra.NodeGroups {
+		if driftedNodeGroups[ng.Name] {
+			desired.TargetInfra.NodeGroups = append(desired.TargetInfra.NodeGroups, ng)
+		}
+	}
+	for _, vNet := range targetVNets(&model) {
+		if !driftedVNets[vNet.Name] {
+			continue
+		}
+		if desired.TargetVNet.Name == "" {
+			desired.TargetVNet = vNet
+		} else {
+			desired.AdditionalVNetList = append(desired.AdditionalVNetList, vNet)
+		}
+	}
+	for _, sgReq := range model.TargetSecurityGroupList {
+		if driftedSgs[sgReq.Name] {
+			desired.TargetSecurityGroupList = append(desired.TargetSecurityGroupList, sgReq)
+		}
+	}
+	remediation.Model = desired
+
+	return remediation
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package migration
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+)
+
+func TestFirewallRuleKeys(t *testing.T) {
+	tests := []struct {
+		name                             string
+		direction, protocol, ports, cidr string
+		want                             []string
+	}{
+		{"single port", "Inbound", "TCP", "22", "0.0.0.0/0", []string{"inbound tcp 22 from 0.0.0.0/0"}},
+		{"comma-separated ports", "inbound", "tcp", "80, 443", "10.0.0.0/8", []string{"inbound tcp 80 from 10.0.0.0/8", "inbound tcp 443 from 10.0.0.0/8"}},
+		{"port range", "inbound", "udp", "1000-2000", "10.0.0.0/8", []string{"inbound udp 1000-2000 from 10.0.0.0/8"}},
+		{"single-port range", "inbound", "tcp", "22-22", "10.0.0.0/8", []string{"inbound tcp 22 from 10.0.0.0/8"}},
+		{"all ports", "inbound", "tcp", "1-65535", "10.0.0.0/8", []string{"inbound tcp * from 10.0.0.0/8"}},
+		{"all protocols", "inbound", "-1", "22", "10.0.0.0/8", []string{"inbound all * from 10.0.0.0/8"}},
+		{"all protocols (*)", "inbound", "*", "", "10.0.0.0/8", []string{"inbound all * from 10.0.0.0/8"}},
+		{"icmp", "inbound", "ICMP", "-1", "10.0.0.0/8", []string{"inbound icmp * from 10.0.0.0/8"}},
+		{"empty CIDR", "inbound", "tcp", "443", "", []string{"inbound tcp 443 from 0.0.0.0/0"}},
+		{"outbound", "outbound", "tcp", "443", "0.0.0.0/0", []string{"outbound tcp 443 to 0.0.0.0/0"}},
+		{"IPv6", "inbound", "tcp", "443", "::/0", []string{"inbound tcp 443 from ::/0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firewallRuleKeys(tt.direction, tt.protocol, tt.ports, tt.cidr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("firewallRuleKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffNodeGroups(t *testing.T) {
+	nodeGroups := []cloudmodel.CreateNodeGroupReq{
+		{Name: "web", NodeGroupSize: 2, SpecId: "spec-a", ImageId: "img-a", RootDiskSize: 50},
+		{Name: "db", SpecId: "spec-b", ImageId: "img-b"}, // NodeGroupSize 0 means 1 node
+	}
+	node := func(id, nodeGroupId, specId, imageId string, rootDiskSize int) cloudmodel.NodeInfo {
+		return cloudmodel.NodeInfo{Id: id, NodeGroupId: nodeGroupId, SpecId: specId, ImageId: imageId, RootDiskSize: rootDiskSize}
+	}
+
+	tests := []struct {
+		name        string
+		nodes       []cloudmodel.NodeInfo
+		wantItems   []DriftItem
+		wantDrifted map[string]bool
+	}{
+		{
+			name: "no drift",
+			nodes: []cloudmodel.NodeInfo{
+				node("web-1", "web", "spec-a", "img-a", 50),
+				node("web-2", "web", "spec-a", "img-a", 50),
+				node("db-1", "db", "spec-b", "img-b", 30), // The root disk size is not checked without the applied one
+			},
+			wantDrifted: map[string]bool{},
+		},
+		{
+			name: "scaled, resized and removed",
+			nodes: []cloudmodel.NodeInfo{
+				node("web-1", "web", "spec-c", "img-a", 100),
+			},
+			wantItems: []DriftItem{
+				{ResourceType: DriftResourceNodeGroup, ResourceId: "web", Change: DriftChanged, Field: "nodeGroupSize", Expected: "2", Actual: "1"},
+				{ResourceType: DriftResourceNode, ResourceId: "web-1", Change: DriftChanged, Field: "specId", Expected: "spec-a", Actual: "spec-c"},
+				{ResourceType: DriftResourceNode, ResourceId: "web-1", Change: DriftChanged, Field: "rootDiskSize", Expected: "50", Actual: "100"},
+				{ResourceType: DriftResourceNodeGroup, ResourceId: "db", Change: DriftRemoved, Expected: "1 node(s)"},
+			},
+			wantDrifted: map[string]bool{"web": true, "db": true},
+		},
+		{
+			name: "image changed and NodeGroups added",
+			nodes: []cloudmodel.NodeInfo{
+				node("web-1", "web", "spec-a", "img-a", 50),
+				node("web-2", "web", "spec-a", "img-a", 50),
+				node("db-1", "db", "spec-b", "img-c", 0),
+				node("cache-1", "cache", "spec-a", "img-a", 0),
+				node("batch-1", "batch", "spec-a", "img-a", 0),
+				node("batch-2", "batch", "spec-a", "img-a", 0),
+			},
+			wantItems: []DriftItem{
+				{ResourceType: DriftResourceNode, ResourceId: "db-1", Change: DriftChanged, Field: "imageId", Expected: "img-b", Actual: "img-c"},
+				{ResourceType: DriftResourceNodeGroup, ResourceId: "batch", Change: DriftAdded, Actual: "2 node(s)"},
+				{ResourceType: DriftResourceNodeGroup, ResourceId: "cache", Change: DriftAdded, Actual: "1 node(s)"},
+			},
+			wantDrifted: map[string]bool{"db": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := DriftReport{}
+			drifted := diffNodeGroups(nodeGroups, tt.nodes, &report)
+			if !reflect.DeepEqual(report.Items, tt.wantItems) {
+				t.Errorf("items = %+v, want %+v", report.Items, tt.wantItems)
+			}
+			if !reflect.DeepEqual(drifted, tt.wantDrifted) {
+				t.Errorf("drifted = %v, want %v", drifted, tt.wantDrifted)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New(`API request failed with status: 404, body: {"message":"not found"}`), true},
+		{errors.New(`API request failed with status: 500, body: {"message":"timeout after 404 ms"}`), false},
+		{errors.New("dial tcp 127.0.0.1:1323: connect: connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := isNotFound(tt.err); got != tt.want {
+			t.Errorf("isNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
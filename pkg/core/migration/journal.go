@@ -0,0 +1,360 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/lkvstore"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Migration journal (saga-style infra migration)
+// Records every resource created (not reused) by an infra migration, so that
+// the partial resources are compensated in reverse order on failure.
+// ============================================================================
+
+// Rollback policies of the infra migration
+const (
+	// RollbackPolicyAlways rolls back the created resources as soon as the migration fails (default)
+	RollbackPolicyAlways = "always"
+
+	// RollbackPolicyNever leaves the created resources as they are on failure (e.g., to be reused by a retry with useExisting=true)
+	RollbackPolicyNever = "never"
+
+	// RollbackPolicyKeepForDebug keeps the created resources (including the failed Infra) for troubleshooting on failure;
+	// they are expected to be rolled back later via the journal
+	RollbackPolicyKeepForDebug = "keep-for-debug"
+)
+
+// Journal statuses
+const (
+	JournalStatusInProgress     = "InProgress"
+	JournalStatusSucceeded      = "Succeeded"
+	JournalStatusFailed         = "Failed"
+	JournalStatusKeptForDebug   = "KeptForDebug"
+	JournalStatusRollingBack    = "RollingBack"
+	JournalStatusRolledBack     = "RolledBack"
+	JournalStatusRollbackFailed = "RollbackFailed"
+)
+
+// Types of the journaled resources
+const (
+	JournalResourceVNet          = "vNet"
+	JournalResourceSshKey        = "sshKey"
+	JournalResourceSecurityGroup = "securityGroup"
+	JournalResourceInfra         = "infra"
+)
+
+// Statuses of the journaled resources
+const (
+	JournalResourceCreated      = "Created"
+	JournalResourceDeleted      = "Deleted"
+	JournalResourceDeleteFailed = "DeleteFailed"
+)
+
+const journalKeyPrefix = "/beetle/migration/journal/"
+
+// journalMutex serializes the updates of the journals
+var journalMutex sync.Mutex
+
+// MigrationJournal records the resources created by an infra migration, in creation order.
+type MigrationJournal struct {
+	Id             string            `json:"id"`
+	NsId           string            `json:"nsId"`
+	InfraName      string            `json:"infraName"`
+	RollbackPolicy string            `json:"rollbackPolicy" example:"always"`
+	Status         string            `json:"status" example:"Succeeded"`
+	Resources      []JournalResource `json:"resources"`
+	Error          string            `json:"error,omitempty"`         // Error of the migration
+	RollbackError  string            `json:"rollbackError,omitempty"` // Error of the last rollback
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+}
+
+// JournalResource is a resource created by the infra migration.
+type JournalResource struct {
+	Type      string    `json:"type" example:"vNet"`
+	Id        string    `json:"id" example:"mig-vnet-01"`
+	Status    string    `json:"status" example:"Created"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// MigrationJournalList is the list of migration journals.
+type MigrationJournalList struct {
+	Journals []MigrationJournal `json:"journals"`
+}
+
+// IsValidRollbackPolicy checks if the rollback policy is supported (empty means the default, always).
+func IsValidRollbackPolicy(policy string) bool {
+	switch policy {
+	case "", RollbackPolicyAlways, RollbackPolicyNever, RollbackPolicyKeepForDebug:
+		return true
+	}
+	return false
+}
+
+// infraSaga executes an infra migration as a saga, journaling the created resources.
+type infraSaga struct {
+	journal MigrationJournal
+	reqId   string // Request ID to publish the rollback progress
+}
+
+// newInfraSaga starts a journal for the infra migration.
+// The journal ID is the request ID if given and not used yet; otherwise it is generated from the Infra name.
+func newInfraSaga(nsId, infraName, reqId, rollbackPolicy string) *infraSaga {
+	if rollbackPolicy == "" {
+		rollbackPolicy = RollbackPolicyAlways
+	}
+	journalId := reqId
+	if _, exists := GetMigrationJournal(nsId, journalId); journalId == "" || exists {
+		journalId = fmt.Sprintf("%s-%d", infraName, time.Now().UnixMilli())
+	}
+
+	now := time.Now()
+	s := &infraSaga{
+		journal: MigrationJournal{
+			Id:             journalId,
+			NsId:           nsId,
+			InfraName:      infraName,
+			RollbackPolicy: rollbackPolicy,
+			Status:         JournalStatusInProgress,
+			Resources:      []JournalResource{},
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		},
+		reqId: reqId,
+	}
+	s.save()
+
+	log.Info().Msgf("migration journal started (nsId: %s, journalId: %s, rollbackPolicy: %s)", nsId, journalId, rollbackPolicy)
+	return s
+}
+
+// record journals a resource created by the migration.
+func (s *infraSaga) record(resourceType, id string) {
+	s.journal.Resources = append(s.journal.Resources, JournalResource{
+		Type:      resourceType,
+		Id:        id,
+		Status:    JournalResourceCreated,
+		CreatedAt: time.Now(),
+	})
+	s.save()
+	log.Debug().Msgf("journaled the created %s (journalId: %s, id: %s)", resourceType, s.journal.Id, id)
+}
+
+// succeed closes the journal of the succeeded migration.
+func (s *infraSaga) succeed() {
+	s.journal.Status = JournalStatusSucceeded
+	s.save()
+}
+
+// fail closes the journal of the failed migration and applies the rollback policy.
+// It returns the migration error annotated with the journal ID and the rollback result.
+func (s *infraSaga) fail(cause error) error {
+	s.journal.Error = cause.Error()
+
+	// The failed Infra may have been registered by CB-Tumblebug (e.g., with failed VMs)
+	infraInfo, err := tbclient.NewSession().ReadInfra(s.journal.NsId, s.journal.InfraName)
+	if err == nil && infraInfo.Id != "" && !s.hasResource(JournalResourceInfra, infraInfo.Id) {
+		s.record(JournalResourceInfra, infraInfo.Id)
+	}
+
+	switch s.journal.RollbackPolicy {
+	case RollbackPolicyNever:
+		s.journal.Status = JournalStatusFailed
+		s.save()
+		log.Warn().Msgf("the created resources are left as they are (rollbackPolicy: never, journalId: %s, resources: %d)",
+			s.journal.Id, len(s.journal.Resources))
+		return fmt.Errorf("%w (journalId: %s, rollback: skipped)", cause, s.journal.Id)
+
+	case RollbackPolicyKeepForDebug:
+		s.journal.Status = JournalStatusKeptForDebug
+		s.save()
+		log.Warn().Msgf("the created resources are kept for debugging; roll them back via the journal when done (journalId: %s, resources: %d)",
+			s.journal.Id, len(s.journal.Resources))
+		return fmt.Errorf("%w (journalId: %s, rollback: kept for debugging)", cause, s.journal.Id)
+	}
+
+	s.journal.Status = JournalStatusFailed
+	s.save()
+
+	reportProgress(s.reqId, ProgressRollingBack, s.journal.Resources)
+	rolledBack, rollbackErr := RollbackMigrationJournal(s.journal.NsId, s.journal.Id)
+	reportProgress(s.reqId, ProgressRollbackFinished, rolledBack.Resources)
+	if rollbackErr != nil {
+		log.Error().Err(rollbackErr).Msgf("failed to roll back the created resources (journalId: %s)", s.journal.Id)
+		return fmt.Errorf("%w (journalId: %s, rollback failed: %v)", cause, s.journal.Id, rollbackErr)
+	}
+	s.journal = rolledBack
+	return fmt.Errorf("%w (journalId: %s, rollback: done)", cause, s.journal.Id)
+}
+
+// hasResource checks if the resource is journaled.
+func (s *infraSaga) hasResource(resourceType, id string) bool {
+	for _, r := range s.journal.Resources {
+		if r.Type == resourceType && r.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *infraSaga) save() {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+	putMigrationJournal(&s.journal)
+}
+
+// putMigrationJournal stores the journal (the caller holds journalMutex).
+func putMigrationJournal(journal *MigrationJournal) {
+	journal.UpdatedAt = time.Now()
+	if err := lkvstore.Put(journalKeyPrefix+journal.NsId+"/"+journal.Id, *journal); err != nil {
+		log.Error().Err(err).Msgf("failed to store the migration journal (journalId: %s)", journal.Id)
+	}
+}
+
+// GetMigrationJournal returns the migration journal.
+func GetMigrationJournal(nsId, journalId string) (MigrationJournal, bool) {
+	value, ok := lkvstore.Get(journalKeyPrefix + nsId + "/" + journalId)
+	if !ok {
+		return MigrationJournal{}, false
+	}
+	return convertToMigrationJournal(value)
+}
+
+// ListMigrationJournals returns the migration journals in the namespace, newest first.
+func ListMigrationJournals(nsId string) MigrationJournalList {
+	ret := MigrationJournalList{Journals: []MigrationJournal{}}
+
+	values, ok := lkvstore.GetWithPrefix(journalKeyPrefix + nsId + "/")
+	if !ok {
+		return ret
+	}
+	for _, value := range values {
+		if journal, ok := convertToMigrationJournal(value); ok {
+			ret.Journals = append(ret.Journals, journal)
+		}
+	}
+	sort.Slice(ret.Journals, func(i, j int) bool {
+		return ret.Journals[i].CreatedAt.After(ret.Journals[j].CreatedAt)
+	})
+	return ret
+}
+
+// convertToMigrationJournal converts the stored value (a struct, or a map once loaded from the file) to MigrationJournal.
+func convertToMigrationJournal(value any) (MigrationJournal, bool) {
+	switch v := value.(type) {
+	case MigrationJournal:
+		return v, true
+	case map[string]any:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to marshal value to JSON")
+			return MigrationJournal{}, false
+		}
+
+		var journal MigrationJournal
+		if err := json.Unmarshal(jsonBytes, &journal); err != nil {
+			log.Error().Err(err).Msg("Failed to unmarshal JSON to MigrationJournal")
+			return MigrationJournal{}, false
+		}
+		return journal, true
+	default:
+		log.Error().Msgf("Unexpected value type: %T", value)
+		return MigrationJournal{}, false
+	}
+}
+
+// RollbackMigrationJournal deletes the resources created by the migration in reverse order of creation.
+// Only the journaled resources are deleted, so reused (pre-existing) resources are never touched.
+// Resources that failed to be deleted are kept in the journal, so the rollback can be retried.
+func RollbackMigrationJournal(nsId, journalId string) (MigrationJournal, error) {
+	journalMutex.Lock()
+	journal, ok := GetMigrationJournal(nsId, journalId)
+	if !ok {
+		journalMutex.Unlock()
+		return MigrationJournal{}, fmt.Errorf("the migration journal (%s) does not exist", journalId)
+	}
+	switch journal.Status {
+	case JournalStatusInProgress, JournalStatusRollingBack:
+		journalMutex.Unlock()
+		return journal, fmt.Errorf("the migration journal (%s) cannot be rolled back in the status (%s)", journalId, journal.Status)
+	case JournalStatusRolledBack:
+		journalMutex.Unlock()
+		return journal, nil
+	}
+	journal.Status = JournalStatusRollingBack
+	putMigrationJournal(&journal)
+	journalMutex.Unlock()
+
+	log.Info().Msgf("Rolling back the migration (nsId: %s, journalId: %s, resources: %d)", nsId, journalId, len(journal.Resources))
+
+	failed := 0
+	for i := len(journal.Resources) - 1; i >= 0; i-- {
+		r := &journal.Resources[i]
+		if r.Status == JournalResourceDeleted {
+			continue
+		}
+
+		err := deleteJournalResource(nsId, *r)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to delete the %s (nsId: %s, id: %s)", r.Type, nsId, r.Id)
+			r.Status = JournalResourceDeleteFailed
+			r.Error = err.Error()
+			failed++
+		} else {
+			log.Debug().Msgf("the %s deleted (nsId: %s, id: %s)", r.Type, nsId, r.Id)
+			r.Status = JournalResourceDeleted
+			r.Error = ""
+		}
+
+		journalMutex.Lock()
+		putMigrationJournal(&journal)
+		journalMutex.Unlock()
+	}
+
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+	if failed > 0 {
+		journal.Status = JournalStatusRollbackFailed
+		journal.RollbackError = fmt.Sprintf("failed to delete %d of %d resource(s)", failed, len(journal.Resources))
+		putMigrationJournal(&journal)
+		return journal, fmt.Errorf("%s (journalId: %s)", journal.RollbackError, journalId)
+	}
+	journal.Status = JournalStatusRolledBack
+	journal.RollbackError = ""
+	putMigrationJournal(&journal)
+
+	log.Info().Msgf("Successfully rolled back the migration (nsId: %s, journalId: %s)", nsId, journalId)
+	return journal, nil
+}
+
+// deleteJournalResource deletes a journaled resource via CB-Tumblebug.
+func deleteJournalResource(nsId string, r JournalResource) error {
+	switch r.Type {
+	case JournalResourceInfra:
+		_, err := tbclient.NewSession().DeleteInfra(nsId, r.Id, "terminate")
+		if err != nil {
+			return err
+		}
+		// Wait for the VMs to release the security groups, SSH keys and subnets
+		time.Sleep(3 * time.Second)
+		return nil
+	case JournalResourceSecurityGroup:
+		_, err := tbclient.NewSession().DeleteSecurityGroup(nsId, r.Id)
+		return err
+	case JournalResourceSshKey:
+		_, err := tbclient.NewSession().DeleteSshKey(nsId, r.Id)
+		return err
+	case JournalResourceVNet:
+		return deleteVNetWithRetry(nsId, r.Id)
+	default:
+		return fmt.Errorf("unsupported resource type (%s)", r.Type)
+	}
+}
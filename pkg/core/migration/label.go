@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/rs/zerolog/log"
+
+	tbmodel "github.com/cloud-barista/cb-tumblebug/src/core/model"
+)
+
+// ============================================================================
+// Migration labels
+// Links the resources created by the migration to their source and to the migration
+// (see the label keys in the common package), so that they can be correlated without parsing names.
+// ============================================================================
+
+// SetMigrationLabels merges the labels into the labels of the Infra and its NodeGroups (empty values are skipped).
+// The label maps are replaced, not modified, since they may be shared with other copies of the model.
+func SetMigrationLabels(targetInfraModel *cloudmodel.RecommendedInfra, labels map[string]string) {
+	targetInfraModel.TargetInfra.Label = mergeLabels(targetInfraModel.TargetInfra.Label, labels)
+
+	nodeGroups := make([]cloudmodel.CreateNodeGroupReq, len(targetInfraModel.TargetInfra.NodeGroups))
+	for i, ng := range targetInfraModel.TargetInfra.NodeGroups {
+		ng.Label = mergeLabels(ng.Label, labels)
+		nodeGroups[i] = ng
+	}
+	targetInfraModel.TargetInfra.NodeGroups = nodeGroups
+}
+
+// setMigrationRequestLabels sets the request ID and the time of the migration
+func setMigrationRequestLabels(targetInfraModel *cloudmodel.RecommendedInfra, reqId string) {
+	SetMigrationLabels(targetInfraModel, map[string]string{
+		common.LabelMigrationReqId: reqId,
+		common.LabelMigratedAt:     time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// migrationLabelsOf returns the migration labels (not the source labels) in the labels
+func migrationLabelsOf(label map[string]string) map[string]string {
+	labels := map[string]string{}
+	for _, key := range common.MigrationLabelKeys {
+		if value := label[key]; value != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// labelResource attaches the labels to a resource created by the migration.
+// It is best-effort, since the labels are not essential to the migration.
+func labelResource(labelType, uid string, labels map[string]string) {
+	if uid == "" || len(labels) == 0 {
+		return
+	}
+	if err := tbclient.NewSession().CreateOrUpdateLabel(labelType, uid, labels); err != nil {
+		log.Warn().Err(err).Msgf("failed to label the %s (uid: %s)", labelType, uid)
+	}
+}
+
+// labelMigratedNodes labels each node of the Infra with the migration labels and its source server.
+// The source servers of a NodeGroup of multiple nodes are assigned in the order of the nodes.
+func labelMigratedNodes(infraInfo tbmodel.InfraInfo, nodeGroups []cloudmodel.CreateNodeGroupReq) {
+	nodesByGroup := map[string][]tbmodel.NodeInfo{}
+	for _, node := range infraInfo.Node {
+		nodesByGroup[node.NodeGroupId] = append(nodesByGroup[node.NodeGroupId], node)
+	}
+
+	for _, ng := range nodeGroups {
+		nodes := nodesByGroup[ng.Name]
+		// e.g., "g1-2" before "g1-10"
+		sort.Slice(nodes, func(i, j int) bool { return nodeIndex(nodes[i].Name) < nodeIndex(nodes[j].Name) })
+
+		machineIds := common.SourceMachineIds(ng.Label)
+		hostnames := common.SourceHostnames(ng.Label)
+		for i, node := range nodes {
+			labels := mergeLabels(node.Label, migrationLabelsOf(ng.Label))
+			if i < len(machineIds) {
+				labels[common.LabelSourceMachineId] = machineIds[i]
+			}
+			if i < len(hostnames) && hostnames[i] != "" {
+				labels[common.LabelSourceHostname] = hostnames[i]
+			}
+			labelResource(tbclient.LabelTypeNode, node.Uid, labels)
+		}
+	}
+}
+
+// nodeIndex returns the index suffix of the node name (e.g., 2 for "g1-2"), or 0 if none
+func nodeIndex(nodeName string) int {
+	index, err := strconv.Atoi(nodeName[strings.LastIndex(nodeName, "-")+1:])
+	if err != nil {
+		return 0
+	}
+	return index
+}
+
+// mergeLabels returns a new map of the base labels overwritten by the labels (empty values are skipped)
+func mergeLabels(base, labels map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(labels))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		if v != "" {
+			merged[k] = v
+		}
+	}
+	return merged
+}
@@ -16,7 +16,9 @@ package migration
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	tbmodel "github.com/cloud-barista/cb-tumblebug/src/core/model"
 	storagemodel "github.com/cloud-barista/cm-beetle/imdl/storage-model"
@@ -42,6 +44,34 @@ func GenerateConnectionName(csp, region string) (string, error) {
 	return connectionName, nil
 }
 
+// describeBucketSpec builds the bucket description recording the source bucket and
+// the bucket settings not provisioned by CB-Tumblebug (e.g., "Created by CM-Beetle (source: data; sse: AES256; public-access: blocked; storage-class: STANDARD_IA; tags: env=prod)").
+func describeBucketSpec(target storagemodel.TargetObjectStorage) string {
+	settings := []string{fmt.Sprintf("source: %s", target.SourceBucketName)}
+	if target.EncryptionEnabled {
+		settings = append(settings, fmt.Sprintf("sse: %s", target.EncryptionAlgorithm))
+	}
+	if target.PublicAccessBlocked {
+		settings = append(settings, "public-access: blocked")
+	}
+	if target.StorageClass != "" {
+		settings = append(settings, fmt.Sprintf("storage-class: %s", target.StorageClass))
+	}
+	if len(target.Tags) > 0 {
+		keys := make([]string, 0, len(target.Tags))
+		for k := range target.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		tags := make([]string, 0, len(keys))
+		for _, k := range keys {
+			tags = append(tags, fmt.Sprintf("%s=%s", k, target.Tags[k]))
+		}
+		settings = append(settings, fmt.Sprintf("tags: %s", strings.Join(tags, ",")))
+	}
+	return fmt.Sprintf("Created by CM-Beetle (%s)", strings.Join(settings, "; "))
+}
+
 // toMigratedObjectStorageInfo converts a TB ObjectStorageInfo to the migration model representation.
 func toMigratedObjectStorageInfo(src tbmodel.ObjectStorageInfo) MigratedObjectStorageInfo {
 	return MigratedObjectStorageInfo{
@@ -61,7 +91,11 @@ func toMigratedObjectStorageInfo(src tbmodel.ObjectStorageInfo) MigratedObjectSt
 // CreateObjectStorage migrates object storages to the target cloud.
 // It applies late-binding via the seed parameter, creates each bucket, then configures
 // versioning and CORS according to CSP support information.
-func CreateObjectStorage(nsId string, req storagemodel.RecommendedObjectStorage, seed string) error {
+// The created buckets are labeled with the labels (e.g., the request ID), the source bucket and the migration time.
+//
+// * Note: CB-Tumblebug does not take the server-side encryption, public access block, storage class and tags
+// * of a bucket yet. They are recorded in the bucket description and must be applied to the bucket manually.
+func CreateObjectStorage(nsId string, req storagemodel.RecommendedObjectStorage, seed string, labels map[string]string) error {
 	log.Info().
 		Str("nsId", nsId).
 		Str("csp", req.TargetCloud.Csp).
@@ -78,10 +112,19 @@ func CreateObjectStorage(nsId string, req storagemodel.RecommendedObjectStorage,
 	// Apply NameSeed (Late Binding) from migration query param
 	if seed != "" {
 		for i := range req.TargetObjectStorages {
-			req.TargetObjectStorages[i].BucketName = common.ComposeName(req.TargetObjectStorages[i].BucketName, seed)
+			req.TargetObjectStorages[i].BucketName = common.ComposeBucketName(req.TargetObjectStorages[i].BucketName, seed)
+		}
+	}
+
+	// Validate bucket names before creating any bucket
+	for _, target := range req.TargetObjectStorages {
+		if ok, detail := common.IsValidBucketName(req.TargetCloud.Csp, target.BucketName); !ok {
+			return fmt.Errorf("invalid bucket name '%s': %s", target.BucketName, detail)
 		}
 	}
 
+	labels = mergeLabels(labels, map[string]string{common.LabelMigratedAt: time.Now().UTC().Format(time.RFC3339)})
+
 	// Create each bucket
 	for i, target := range req.TargetObjectStorages {
 		log.Debug().
@@ -94,17 +137,30 @@ func CreateObjectStorage(nsId string, req storagemodel.RecommendedObjectStorage,
 		createReq := tbmodel.ObjectStorageCreateRequest{
 			BucketName:     target.BucketName,
 			ConnectionName: connName,
-			Description:    "Created by CM-Beetle",
+			Description:    describeBucketSpec(target),
 		}
-		if _, err := tbclient.NewSession().CreateObjectStorage(nsId, createReq); err != nil {
+		osInfo, err := tbclient.NewSession().CreateObjectStorage(nsId, createReq)
+		if err != nil {
 			log.Error().Err(err).Str("bucketName", target.BucketName).Msg("Failed to create object storage")
 			return fmt.Errorf("failed to create object storage '%s': %w", target.BucketName, err)
 		}
+		labelResource(tbclient.LabelTypeObjectStorage, osInfo.Uid,
+			mergeLabels(labels, map[string]string{common.LabelSourceBucket: target.SourceBucketName}))
 
 		log.Info().
 			Str("sourceBucket", target.SourceBucketName).
 			Str("targetBucket", target.BucketName).
 			Msg("Object storage created")
+
+		if target.EncryptionEnabled || target.PublicAccessBlocked || target.StorageClass != "" || len(target.Tags) > 0 {
+			log.Warn().
+				Str("bucket", target.BucketName).
+				Bool("encryption", target.EncryptionEnabled).
+				Bool("publicAccessBlocked", target.PublicAccessBlocked).
+				Str("storageClass", target.StorageClass).
+				Int("tags", len(target.Tags)).
+				Msg("Encryption, public access block, storage class and tags are not provisioned by CB-Tumblebug; apply them to the bucket manually")
+		}
 	}
 
 	// Configure versioning and CORS
@@ -0,0 +1,210 @@
+package migration
+
+import (
+	"fmt"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// What-if plan of the infra migration
+// Runs the validation, the preflight check and the create/reuse decisions of
+// CreateInfra/CreateInfraWithExisting without creating anything (like `terraform plan`).
+// ============================================================================
+
+// Planned actions of a resource
+const (
+	PlanActionCreate   = "create"
+	PlanActionReuse    = "reuse"
+	PlanActionConflict = "conflict"
+)
+
+// PlannedResource is the planned action of a resource (the type is one of the JournalResource* types).
+type PlannedResource struct {
+	Type    string   `json:"type" example:"vNet"`
+	Id      string   `json:"id" example:"mig-vnet-01"`
+	Action  string   `json:"action" enums:"create,reuse,conflict" example:"create"`
+	Subnets []string `json:"subnets,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// NodeGroupPreflight is the preflight result (CSP image and system disk) of a NodeGroup.
+type NodeGroupPreflight struct {
+	NodeGroup             string `json:"nodeGroup" example:"g1"`
+	NodeGroupSize         int    `json:"nodeGroupSize" example:"1"`
+	SpecId                string `json:"specId"`
+	ImageId               string `json:"imageId"`
+	Checked               bool   `json:"checked"`
+	ImageAvailable        bool   `json:"imageAvailable"`
+	CspImageName          string `json:"cspImageName,omitempty"`
+	ResolvedCspImageName  string `json:"resolvedCspImageName,omitempty"`
+	RootDiskType          string `json:"rootDiskType,omitempty"`
+	SuggestedRootDiskType string `json:"suggestedRootDiskType,omitempty"`
+	Message               string `json:"message,omitempty"`
+}
+
+// InfraPlanSummary is the number of resources per planned action.
+type InfraPlanSummary struct {
+	ToCreate  int `json:"toCreate"`
+	ToReuse   int `json:"toReuse"`
+	Conflicts int `json:"conflicts"`
+}
+
+// InfraPlan is the what-if plan of the infra migration.
+type InfraPlan struct {
+	NsId        string               `json:"nsId" example:"mig01"`
+	InfraName   string               `json:"infraName" example:"mmci01"`
+	UseExisting bool                 `json:"useExisting"`
+	Executable  bool                 `json:"executable"`
+	Errors      []string             `json:"errors,omitempty"`
+	Summary     InfraPlanSummary     `json:"summary"`
+	Resources   []PlannedResource    `json:"resources"`
+	NodeGroups  []NodeGroupPreflight `json:"nodeGroups"`
+}
+
+// PlanInfra returns what the infra migration (CreateInfraWithExisting if useExisting, CreateInfra otherwise)
+// would do with the target infrastructure model. Nothing is created, and the model is not modified.
+// The plan is executable if there is no error (validation, preflight, namespace) and no conflicting resource.
+func PlanInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra, useExisting bool) (InfraPlan, error) {
+	if targetInfraModel == nil {
+		return InfraPlan{}, fmt.Errorf("target infrastructure model is nil")
+	}
+	log.Info().Msgf("Planning the infra migration (nsId: %s, infraName: %s, useExisting: %t)",
+		nsId, targetInfraModel.TargetInfra.Name, useExisting)
+
+	plan := InfraPlan{
+		NsId:        nsId,
+		InfraName:   targetInfraModel.TargetInfra.Name,
+		UseExisting: useExisting,
+		Resources:   []PlannedResource{},
+		NodeGroups:  []NodeGroupPreflight{},
+	}
+
+	// 1. Validate the target infrastructure model
+	// * Note: In the fresh mode, the existing resources are reported as conflicts below instead of a validation error.
+	var err error
+	if useExisting {
+		err = validateTargeInfraModelWithExisting(nsId, targetInfraModel)
+	} else {
+		err = validateTargeInfraModelReferences(nsId, targetInfraModel)
+	}
+	if err != nil {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("validation: %v", err))
+	}
+
+	// 2. Preflight check (image and system disk) per NodeGroup
+	for _, ng := range targetInfraModel.TargetInfra.NodeGroups {
+		result := preflightNodeGroup(ng)
+		if result.Checked && !result.ImageAvailable {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("preflight: %s", result.Message))
+		}
+		plan.NodeGroups = append(plan.NodeGroups, result)
+	}
+
+	// 3. Check if the namespace exists
+	if _, err := tbclient.NewSession().ReadNamespace(nsId); err != nil {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("namespace: failed to read the namespace (nsId: %s): %v", nsId, err))
+	}
+
+	// 4. Decide to create or reuse the resources
+	if useExisting {
+		for _, netReq := range deriveNetworkIds(targetInfraModel.TargetInfra.NodeGroups) {
+			planned, _ := planNetwork(nsId, netReq, targetVNets(targetInfraModel))
+			plan.Resources = append(plan.Resources, planned)
+		}
+		for _, sshKeyReq := range deriveSshKeyIds(targetInfraModel.TargetInfra.NodeGroups) {
+			planned, _ := planSshKey(nsId, sshKeyReq, targetInfraModel.TargetSshKey)
+			plan.Resources = append(plan.Resources, planned)
+		}
+		for _, sgReq := range deriveSecurityGroupIds(targetInfraModel.TargetInfra.NodeGroups) {
+			planned, _ := planSecurityGroup(nsId, sgReq, targetInfraModel.TargetSecurityGroupList)
+			plan.Resources = append(plan.Resources, planned)
+		}
+	} else {
+		plan.Resources = append(plan.Resources, planFreshResources(nsId, targetInfraModel)...)
+	}
+	plan.Resources = append(plan.Resources, planInfraResource(nsId, targetInfraModel.TargetInfra))
+
+	// [Output] Summarize the plan
+	for _, res := range plan.Resources {
+		switch res.Action {
+		case PlanActionCreate:
+			plan.Summary.ToCreate++
+		case PlanActionReuse:
+			plan.Summary.ToReuse++
+		case PlanActionConflict:
+			plan.Summary.Conflicts++
+		}
+	}
+	plan.Executable = len(plan.Errors) == 0 && plan.Summary.Conflicts == 0
+
+	log.Info().Msgf("Planned the infra migration (nsId: %s, infraName: %s, create: %d, reuse: %d, conflict: %d, errors: %d)",
+		nsId, plan.InfraName, plan.Summary.ToCreate, plan.Summary.ToReuse, plan.Summary.Conflicts, len(plan.Errors))
+	return plan, nil
+}
+
+// planFreshResources decides the resources of the fresh creation (useExisting=false),
+// in which every vNet, SSH key and security group is created, so an existing one is a conflict.
+func planFreshResources(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) []PlannedResource {
+	var planned []PlannedResource
+
+	// For the vNets, SSH key and security groups, it's normal if the resources don't exist
+	for _, vNet := range targetVNets(targetInfraModel) {
+		res := PlannedResource{Type: JournalResourceVNet, Id: vNet.Name, Action: PlanActionCreate}
+		for _, subnet := range vNet.SubnetInfoList {
+			res.Subnets = append(res.Subnets, subnet.Name)
+		}
+		vNetInfo, err := tbclient.NewSession().ReadVNet(nsId, vNet.Name)
+		if err != nil {
+			log.Debug().Msgf("the vNet not found (nsId: %s, vNet.Name: %s), which is normal case", nsId, vNet.Name)
+		}
+		if vNetInfo.Id != "" {
+			res.Action = PlanActionConflict
+			res.Reason = fmt.Sprintf("the vNet already exists (nsId: %s, vNetInfo.Id: %s)", nsId, vNetInfo.Id)
+		}
+		planned = append(planned, res)
+	}
+
+	sshKeyName := targetInfraModel.TargetSshKey.Name
+	res := PlannedResource{Type: JournalResourceSshKey, Id: sshKeyName, Action: PlanActionCreate}
+	sshKeyInfo, err := tbclient.NewSession().ReadSshKey(nsId, sshKeyName)
+	if err != nil {
+		log.Debug().Err(err).Msgf("SSH key not found (nsId: %s, sshKey.Name: %s), which is normal case", nsId, sshKeyName)
+	}
+	if sshKeyInfo.Id != "" {
+		res.Action = PlanActionConflict
+		res.Reason = fmt.Sprintf("the SSH key already exists (nsId: %s, sshKey.Id: %s)", nsId, sshKeyInfo.Id)
+	}
+	planned = append(planned, res)
+
+	for _, sg := range targetInfraModel.TargetSecurityGroupList {
+		res := PlannedResource{Type: JournalResourceSecurityGroup, Id: sg.Name, Action: PlanActionCreate}
+		sgInfo, err := tbclient.NewSession().ReadSecurityGroup(nsId, sg.Name)
+		if err != nil {
+			log.Debug().Msgf("the security group not found (nsId: %s, sg.Name: %s), which is normal case", nsId, sg.Name)
+		}
+		if sgInfo.Id != "" {
+			res.Action = PlanActionConflict
+			res.Reason = fmt.Sprintf("the security group already exists (nsId: %s, sgInfo.Id: %s)", nsId, sgInfo.Id)
+		}
+		planned = append(planned, res)
+	}
+
+	return planned
+}
+
+// planInfraResource decides the Infra, which is always created (an existing one is a conflict)
+func planInfraResource(nsId string, infraReq cloudmodel.InfraReq) PlannedResource {
+	res := PlannedResource{Type: JournalResourceInfra, Id: infraReq.Name, Action: PlanActionCreate}
+	infraInfo, err := tbclient.NewSession().ReadInfra(nsId, infraReq.Name)
+	if err != nil {
+		log.Debug().Msgf("the Infra not found (nsId: %s, infraName: %s), which is normal case", nsId, infraReq.Name)
+	}
+	if infraInfo.Id != "" {
+		res.Action = PlanActionConflict
+		res.Reason = fmt.Sprintf("the Infra already exists (nsId: %s, infraName: %s)", nsId, infraReq.Name)
+	}
+	return res
+}
@@ -0,0 +1,146 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/cloud-barista/cm-beetle/pkg/core/notification"
+	"github.com/rs/zerolog/log"
+
+	tbmodel "github.com/cloud-barista/cb-tumblebug/src/core/model"
+)
+
+// ============================================================================
+// Step-level progress of the infra migration
+// Published into the request (common.ProgressInfo) tracked by the request ID,
+// so that it is visible via GET /request/{reqId} and its event stream.
+// ============================================================================
+
+// Progress titles of the infra migration
+const (
+	ProgressValidated         = "Validated the target infrastructure model"
+	ProgressPreflightChecked  = "Preflight check completed"
+	ProgressVNetCreated       = "vNet created"
+	ProgressVNetReused        = "vNet reused"
+	ProgressSshKeyCreated     = "SSH key created"
+	ProgressSshKeyReused      = "SSH key reused"
+	ProgressSgCreated         = "Security group created"
+	ProgressSgReused          = "Security group reused"
+	ProgressInfraProvisioning = "Provisioning the infrastructure"
+	ProgressVmStatus          = "VM provisioning status"
+	ProgressInfraCreated      = "Infrastructure created"
+	ProgressRollingBack       = "Rolling back the created resources"
+	ProgressRollbackFinished  = "Rollback finished"
+	ProgressWaveStarted       = "Wave started"
+	ProgressWaveStepFinished  = "Wave step finished"
+	ProgressWaveFinished      = "Wave finished"
+	ProgressWavePaused        = "Wave paused at the gate"
+)
+
+// infraStatusPollInterval is the interval to poll the provisioning status of the Infra from CB-Tumblebug
+const infraStatusPollInterval = 10 * time.Second
+
+// ResourceProgress is the progress info of a resource step.
+type ResourceProgress struct {
+	Id string `json:"id"`
+}
+
+// VmProvisioningProgress is the progress info of the VM provisioning polled from CB-Tumblebug.
+type VmProvisioningProgress struct {
+	InfraId       string `json:"infraId"`
+	Status        string `json:"status"`
+	CountTotal    int    `json:"countTotal"`
+	CountCreating int    `json:"countCreating"`
+	CountRunning  int    `json:"countRunning"`
+	CountFailed   int    `json:"countFailed"`
+}
+
+// reportProgress publishes a step-level progress of the request (no-op without the request ID).
+func reportProgress(reqId, title string, info any) {
+	if reqId == "" {
+		return
+	}
+	common.AppendRequestProgress(reqId, common.ProgressInfo{
+		Title: title,
+		Info:  info,
+		Time:  time.Now(),
+	})
+}
+
+// InfraMigratedEventData is the data of the event notifying the completion of an infra migration
+type InfraMigratedEventData struct {
+	NsId      string `json:"nsId" example:"mig01"`
+	InfraId   string `json:"infraId" example:"mmci01"`
+	Status    string `json:"status" example:"Running:2 (R:2/2)"`
+	NodeCount int    `json:"nodeCount" example:"2"`
+	ReqId     string `json:"reqId,omitempty" example:"1735689600000000000"`
+	ProjectId string `json:"projectId,omitempty" example:"prj-web"`
+	Wave      string `json:"wave,omitempty" example:"plan01/wave-1"`
+}
+
+// notifyInfraMigrated publishes the completion of an infra migration to the webhooks (if any)
+func notifyInfraMigrated(nsId, reqId string, infraInfo tbmodel.InfraInfo, label map[string]string) {
+	notification.Publish(notification.EventTypeInfraMigrated, infraInfo.Id, InfraMigratedEventData{
+		NsId:      nsId,
+		InfraId:   infraInfo.Id,
+		Status:    infraInfo.Status,
+		NodeCount: len(infraInfo.Node),
+		ReqId:     reqId,
+		ProjectId: label[common.LabelMigrationProject],
+		Wave:      label[common.LabelMigrationWave],
+	})
+}
+
+// watchInfraProvisioning polls the provisioning status of the Infra from CB-Tumblebug and
+// publishes it whenever it changes, until the returned stop function is called.
+func watchInfraProvisioning(nsId, infraName, reqId string) (stop func()) {
+	if reqId == "" {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(infraStatusPollInterval)
+		defer ticker.Stop()
+
+		var last VmProvisioningProgress
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// The Infra is registered by CB-Tumblebug shortly after the creation request
+			infraInfo, err := tbclient.NewSession().ReadInfra(nsId, infraName)
+			if err != nil || infraInfo.Id == "" {
+				log.Trace().Err(err).Msgf("the Infra is not available yet (nsId: %s, infraName: %s)", nsId, infraName)
+				continue
+			}
+
+			current := VmProvisioningProgress{
+				InfraId:       infraInfo.Id,
+				Status:        infraInfo.Status,
+				CountTotal:    infraInfo.StatusCount.CountTotal,
+				CountCreating: infraInfo.StatusCount.CountCreating,
+				CountRunning:  infraInfo.StatusCount.CountRunning,
+				CountFailed:   infraInfo.StatusCount.CountFailed,
+			}
+			if current != last {
+				reportProgress(reqId, ProgressVmStatus, current)
+				last = current
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
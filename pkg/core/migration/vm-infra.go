@@ -119,8 +119,10 @@ func CreateVMInfraWithDefaults(nsId string, infraModel *cloudmodel.InfraDynamicR
 	return convertedVmInfraInfo, nil
 }
 
-// CreateInfra creates a VM infrastructure for the computing infra migration by creating fresh resources (useExisting=false)
-func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cloudmodel.VmInfraInfo, error) {
+// CreateInfra creates a VM infrastructure for the computing infra migration by creating fresh resources (useExisting=false).
+// The created resources are journaled and compensated on failure by the rollbackPolicy.
+// The request ID (reqId, optional) is used as the journal ID and to publish the step-level progress.
+func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra, reqId, rollbackPolicy string) (cloudmodel.VmInfraInfo, error) {
 	log.Info().Msg("Creating VM infrastructure")
 
 	emptyRet := cloudmodel.VmInfraInfo{}
@@ -135,6 +137,7 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 		return emptyRet, err
 	}
 	log.Info().Msgf("the target infrastructure model is valid (nsId: %s)", nsId)
+	reportProgress(reqId, ProgressValidated, nil)
 
 	// Preflight: resolve the latest CSP image and confirm available system disk per nodegroup.
 	err = preflightCheckCspProvisioning(nsId, targetInfraModel.TargetInfra.NodeGroups)
@@ -142,6 +145,7 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 		log.Error().Err(err).Msgf("failed to run preflight check for CSP provisioning (nsId: %s)", nsId)
 		return emptyRet, err
 	}
+	reportProgress(reqId, ProgressPreflightChecked, nil)
 
 	// Initialize Tumblebug session
 	// tbSess := tbclient.NewSession()
@@ -158,10 +162,9 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 	}
 
 	log.Debug().Msgf("Checking if the Infra (%s) exists in the namespace (%s)", targetInfraModel.TargetInfra.Name, nsId)
-	tempInfraInfo, err := tbclient.NewSession().ReadInfra(nsId, targetInfraModel.TargetInfra.Name)
-	if tempInfraInfo.Id != "" {
-		log.Error().Err(err).Msgf("the Infra already exist (nsId: %s, infraName: %s)", nsId, targetInfraModel.TargetInfra.Name)
-		return emptyRet, err
+	if planned := planInfraResource(nsId, targetInfraModel.TargetInfra); planned.Action == PlanActionConflict {
+		log.Error().Msg(planned.Reason)
+		return emptyRet, fmt.Errorf("%s", planned.Reason)
 	}
 
 	// 2. Create a VM specification (vmSpec)
@@ -170,27 +173,48 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 	// 3. Create a VM OS image (vmOsImage)
 	// * Skip: No need to regenerate vmOsImage in namespace
 
-	// 4. Create a virtual network (vNet)
-	// Get vNet request body from the input infraModel
-	vNetReq := targetInfraModel.TargetVNet
-	log.Debug().Msgf("Creating a vNet (nsId: %s, vNetName: %s)", nsId, vNetReq.Name)
-	log.Debug().Msgf("vNetReq: %+v", vNetReq)
+	// Label the resources with the request ID and the time of the migration
+	setMigrationRequestLabels(targetInfraModel, reqId)
+	resourceLabels := migrationLabelsOf(targetInfraModel.TargetInfra.Label)
 
-	// Convert model from 'cloudmodel.VNetReq' to 'tbmodel.VNetReq'
-	tbVNetReq, err := modelconv.ConvertWithValidation[cloudmodel.VNetReq, tbmodel.VNetReq](vNetReq)
-	if err != nil {
-		log.Error().Err(err).Msgf("failed to convert vNet request (nsId: %s)", nsId)
-		return emptyRet, err
-	}
+	// Start the journal of the created resources (compensated in reverse order on failure)
+	saga := newInfraSaga(nsId, targetInfraModel.TargetInfra.Name, reqId, rollbackPolicy)
 
-	vNetInfo, err := tbclient.NewSession().CreateVNet(nsId, tbVNetReq)
-	if err != nil {
-		log.Error().Err(err).Msgf("failed to create the vNet (nsId: %s)", nsId)
-		return emptyRet, err
-	}
+	// 4. Create virtual networks (vNets)
+	// Get vNet request bodies from the input infraModel (the target vNet and the additional vNets, if any)
+	for _, vNetReq := range targetVNets(targetInfraModel) {
+		log.Debug().Msgf("Creating a vNet (nsId: %s, vNetName: %s)", nsId, vNetReq.Name)
+		log.Debug().Msgf("vNetReq: %+v", vNetReq)
+
+		// Convert model from 'cloudmodel.VNetReq' to 'tbmodel.VNetReq'
+		tbVNetReq, err := modelconv.ConvertWithValidation[cloudmodel.VNetReq, tbmodel.VNetReq](vNetReq)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to convert vNet request (nsId: %s)", nsId)
+			return emptyRet, saga.fail(err)
+		}
+
+		vNetInfo, err := tbclient.NewSession().CreateVNet(nsId, tbVNetReq)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to create the vNet (nsId: %s, vNetName: %s)", nsId, vNetReq.Name)
+			return emptyRet, saga.fail(err)
+		}
 
-	log.Debug().Msgf("vNet created: %s", vNetInfo.Id)
-	// * Note: "vNetInfo.Id" should be used if any of the following steps require vNetId.
+		log.Debug().Msgf("vNet created: %s", vNetInfo.Id)
+		saga.record(JournalResourceVNet, vNetInfo.Id)
+		labelResource(tbclient.LabelTypeVNet, vNetInfo.Uid, resourceLabels)
+		reportProgress(reqId, ProgressVNetCreated, ResourceProgress{Id: vNetInfo.Id})
+		// * Note: "vNetInfo.Id" should be used if any of the following steps require vNetId.
+
+		// * Note: CB-Tumblebug does not take the dual-stack settings, so IPv6 must be enabled on the vNet manually.
+		if vNetReq.EnableIPv6 {
+			ipv6CidrBlock := vNetReq.IPv6CidrBlock
+			if ipv6CidrBlock == "" {
+				ipv6CidrBlock = "CSP-assigned"
+			}
+			log.Warn().Msgf("the vNet (%s) is recommended as dual-stack (IPv6: %s), but IPv6 is not provisioned by CB-Tumblebug; enable IPv6 on the vNet and its subnets manually",
+				vNetInfo.Id, ipv6CidrBlock)
+		}
+	}
 
 	// 5. Create a SSH key pair (sshKey)
 	sshKeyReq := targetInfraModel.TargetSshKey
@@ -201,15 +225,18 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 	tbSshKeyReq, err := modelconv.ConvertWithValidation[cloudmodel.SshKeyReq, tbmodel.SshKeyReq](sshKeyReq)
 	if err != nil {
 		log.Error().Err(err).Msgf("failed to convert SSH key request (nsId: %s)", nsId)
-		return emptyRet, err
+		return emptyRet, saga.fail(err)
 	}
 
 	sshKeyInfo, err := tbclient.NewSession().CreateSshKey(nsId, tbSshKeyReq)
 	if err != nil {
 		log.Error().Err(err).Msgf("failed to create the SSH key (nsId: %s)", nsId)
-		return emptyRet, err
+		return emptyRet, saga.fail(err)
 	}
 	log.Debug().Msgf("SSH key created: %s", sshKeyInfo.Id)
+	saga.record(JournalResourceSshKey, sshKeyInfo.Id)
+	labelResource(tbclient.LabelTypeSshKey, sshKeyInfo.Uid, resourceLabels)
+	reportProgress(reqId, ProgressSshKeyCreated, ResourceProgress{Id: sshKeyInfo.Id})
 
 	// 6. Create a security group (sg)
 	// Get security group request body from the input infraModel
@@ -224,28 +251,37 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 		sgReq = checkAndSupportSSHAccessRule(sgReq)
 
 		// Create security group
-		log.Debug().Msgf("Creating a security group (nsId: %s, sgReq.sgName: %s, sgReq.VNetId: %s, vNetInfo.vNetId: %s)",
-			nsId, sgReq.Name, sgReq.VNetId, vNetInfo.Id)
+		log.Debug().Msgf("Creating a security group (nsId: %s, sgReq.sgName: %s, sgReq.VNetId: %s)",
+			nsId, sgReq.Name, sgReq.VNetId)
 
 		// Convert model from 'cloudmodel.SecurityGroupReq' to 'tbmodel.SecurityGroupReq'
 		tbSgReq, err := modelconv.ConvertWithValidation[cloudmodel.SecurityGroupReq, tbmodel.SecurityGroupReq](sgReq)
 		if err != nil {
 			log.Error().Err(err).Msgf("failed to convert SSH key request (nsId: %s)", nsId)
-			return emptyRet, err
+			return emptyRet, saga.fail(err)
 		}
 
 		sgInfo, err := tbclient.NewSession().CreateSecurityGroup(nsId, tbSgReq, "")
 		if err != nil {
 			log.Error().Err(err).Msgf("failed to create the security group (nsId: %s)", nsId)
-			return emptyRet, err
+			return emptyRet, saga.fail(err)
 		}
 		log.Debug().Msgf("security group created: %s", sgInfo.Id)
+		saga.record(JournalResourceSecurityGroup, sgInfo.Id)
+		labelResource(tbclient.LabelTypeSecurityGroup, sgInfo.Uid, resourceLabels)
+		reportProgress(reqId, ProgressSgCreated, ResourceProgress{Id: sgInfo.Id})
 
 		sgInfoList = append(sgInfoList, sgInfo)
 	}
 	log.Debug().Msgf("sgInfoList length: %d", len(sgInfoList))
 	log.Debug().Msgf("sgInfoList: %+v", sgInfoList)
 
+	// * Note: CB-Tumblebug does not take network ACLs, so the recommended ACLs must be associated with the subnets manually.
+	for _, acl := range targetInfraModel.TargetNetworkAclList {
+		log.Warn().Msgf("the network ACL (%s, %d rules) is recommended for the subnets %v of the vNet (%s), but it is not provisioned by CB-Tumblebug; create and associate it manually",
+			acl.Name, len(acl.Rules), acl.SubnetIds, acl.VNetId)
+	}
+
 	// 7. Create a VM infrastructure (i.e., Infra)
 	// Get multi-cloud infrastructure (Infra) request body from the input infraModel
 	infraReq := targetInfraModel.TargetInfra
@@ -256,7 +292,7 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 	tbInfraReq, err := modelconv.ConvertWithValidation[cloudmodel.InfraReq, tbmodel.InfraReq](infraReq)
 	if err != nil {
 		log.Error().Err(err).Msgf("failed to convert the Infra request (nsId: %s)", nsId)
-		return emptyRet, err
+		return emptyRet, saga.fail(err)
 	}
 	log.Debug().Msgf("tbInfraReq: %+v", tbInfraReq)
 
@@ -276,18 +312,25 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 		}
 	}
 
-	// Create multi-cloud infrastructure
+	// Create multi-cloud infrastructure (polling the VM provisioning status meanwhile)
+	reportProgress(reqId, ProgressInfraProvisioning, ResourceProgress{Id: tbInfraReq.Name})
+	stopWatching := watchInfraProvisioning(nsId, tbInfraReq.Name, reqId)
 	infraInfo, err := tbclient.NewSession().CreateInfra(nsId, tbInfraReq)
+	stopWatching()
 	if err != nil {
 		log.Error().Err(err).Msgf("failed to create the multi-cloud infrastructure (nsId: %s)", nsId)
 
-		// TODO: Consider implementing resource rollback in case of failure at this step (e.g., delete created vNet, SSH key, security groups)
-		// ! But first, be cautious about the rollback since it may cause unintended consequences if not implemented properly (e.g., deleting resources that are shared with other infrastructures or used by other applications)
-		// ? Second, consider the trade-off between keeping the failed infrastructure for troubleshooting and rolling back the created resources, which is more beneficial for users in case of failure at this step
-
-		return emptyRet, err
+		// * Note: Only the journaled (created) resources are rolled back, so resources shared with others are never deleted.
+		// * The rollbackPolicy decides whether to roll back now or to keep the resources (e.g., for troubleshooting).
+		return emptyRet, saga.fail(err)
 	}
 	log.Debug().Msgf("multi-cloud infrastructure created: %s", infraInfo.Id)
+	saga.record(JournalResourceInfra, infraInfo.Id)
+	saga.succeed()
+	labelMigratedNodes(infraInfo, targetInfraModel.TargetInfra.NodeGroups)
+	putAppliedModel(nsId, infraInfo.Id, targetInfraModel)
+	reportProgress(reqId, ProgressInfraCreated, ResourceProgress{Id: infraInfo.Id})
+	notifyInfraMigrated(nsId, reqId, infraInfo, targetInfraModel.TargetInfra.Label)
 
 	/*
 	 * [Output] Return the created multi-cloud infrastructure info
@@ -306,8 +349,10 @@ func CreateInfra(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cl
 	return temp, nil
 }
 
-// CreateInfraWithExisting creates a VM infrastructure by reusing/ensuring existing resources (useExisting=true)
-func CreateInfraWithExisting(nsId string, targetInfraModel *cloudmodel.RecommendedInfra) (cloudmodel.VmInfraInfo, error) {
+// CreateInfraWithExisting creates a VM infrastructure by reusing/ensuring existing resources (useExisting=true).
+// Only the resources created (not reused) are journaled and compensated on failure by the rollbackPolicy.
+// The request ID (reqId, optional) is used as the journal ID and to publish the step-level progress.
+func CreateInfraWithExisting(nsId string, targetInfraModel *cloudmodel.RecommendedInfra, reqId, rollbackPolicy string) (cloudmodel.VmInfraInfo, error) {
 	log.Info().Msg("Creating VM infrastructure with existing resources")
 	emptyRet := cloudmodel.VmInfraInfo{}
 
@@ -320,6 +365,7 @@ func CreateInfraWithExisting(nsId string, targetInfraModel *cloudmodel.Recommend
 		return emptyRet, err
 	}
 	log.Info().Msgf("the target infrastructure model is valid (nsId: %s)", nsId)
+	reportProgress(reqId, ProgressValidated, nil)
 
 	// Preflight: resolve the latest CSP image and confirm available system disk per nodegroup.
 	err = preflightCheckCspProvisioning(nsId, targetInfraModel.TargetInfra.NodeGroups)
@@ -327,6 +373,7 @@ func CreateInfraWithExisting(nsId string, targetInfraModel *cloudmodel.Recommend
 		log.Error().Err(err).Msgf("failed to run preflight check for CSP provisioning (nsId: %s)", nsId)
 		return emptyRet, err
 	}
+	reportProgress(reqId, ProgressPreflightChecked, nil)
 
 	/*
 	 * [Process] Create a VM infrastructure
@@ -340,10 +387,9 @@ func CreateInfraWithExisting(nsId string, targetInfraModel *cloudmodel.Recommend
 	}
 
 	log.Debug().Msgf("Checking if the Infra (%s) exists in the namespace (%s)", targetInfraModel.TargetInfra.Name, nsId)
-	tempInfraInfo, err := tbclient.NewSession().ReadInfra(nsId, targetInfraModel.TargetInfra.Name)
-	if tempInfraInfo.Id != "" {
-		log.Error().Err(err).Msgf("the Infra already exist (nsId: %s, infraName: %s)", nsId, targetInfraModel.TargetInfra.Name)
-		return emptyRet, err
+	if planned := planInfraResource(nsId, targetInfraModel.TargetInfra); planned.Action == PlanActionConflict {
+		log.Error().Msg(planned.Reason)
+		return emptyRet, fmt.Errorf("%s", planned.Reason)
 	}
 
 	// 2. Create a VM specification (vmSpec)
@@ -352,33 +398,58 @@ func CreateInfraWithExisting(nsId string, targetInfraModel *cloudmodel.Recommend
 	// 3. Create a VM OS image (vmOsImage)
 	// * Skip: No need to regenerate vmOsImage in namespace
 
+	// Label the resources with the request ID and the time of the migration
+	setMigrationRequestLabels(targetInfraModel, reqId)
+	resourceLabels := migrationLabelsOf(targetInfraModel.TargetInfra.Label)
+
+	// Start the journal of the created resources (compensated in reverse order on failure)
+	saga := newInfraSaga(nsId, targetInfraModel.TargetInfra.Name, reqId, rollbackPolicy)
+
 	// 4. Use/Create virtual networks (vNet, Subnets)
 	netReqs := deriveNetworkIds(targetInfraModel.TargetInfra.NodeGroups)
 	for _, netReq := range netReqs {
-		err = useOrCreateNetwork(nsId, netReq, targetInfraModel.TargetVNet)
+		created, err := useOrCreateNetwork(nsId, netReq, targetVNets(targetInfraModel), resourceLabels)
 		if err != nil {
 			log.Error().Err(err).Msgf("failed to use or create virtual network %s (nsId: %s)", netReq.VNetId, nsId)
-			return emptyRet, err
+			return emptyRet, saga.fail(err)
+		}
+		if created {
+			saga.record(JournalResourceVNet, netReq.VNetId)
+			reportProgress(reqId, ProgressVNetCreated, ResourceProgress{Id: netReq.VNetId})
+		} else {
+			reportProgress(reqId, ProgressVNetReused, ResourceProgress{Id: netReq.VNetId})
 		}
 	}
 
 	// 5. Use/Create SSH key pairs (sshKey)
 	sshKeyReqs := deriveSshKeyIds(targetInfraModel.TargetInfra.NodeGroups)
 	for _, sshKeyReq := range sshKeyReqs {
-		err = useOrCreateSshKey(nsId, sshKeyReq, targetInfraModel.TargetSshKey)
+		created, err := useOrCreateSshKey(nsId, sshKeyReq, targetInfraModel.TargetSshKey, resourceLabels)
 		if err != nil {
 			log.Error().Err(err).Msgf("failed to use or create SSH key %s (nsId: %s)", sshKeyReq.SshKeyId, nsId)
-			return emptyRet, err
+			return emptyRet, saga.fail(err)
+		}
+		if created {
+			saga.record(JournalResourceSshKey, sshKeyReq.SshKeyId)
+			reportProgress(reqId, ProgressSshKeyCreated, ResourceProgress{Id: sshKeyReq.SshKeyId})
+		} else {
+			reportProgress(reqId, ProgressSshKeyReused, ResourceProgress{Id: sshKeyReq.SshKeyId})
 		}
 	}
 
 	// 6. Use/Create security groups (sg)
 	sgReqs := deriveSecurityGroupIds(targetInfraModel.TargetInfra.NodeGroups)
 	for _, sgReq := range sgReqs {
-		err = useOrCreateSecurityGroup(nsId, sgReq, targetInfraModel.TargetSecurityGroupList)
+		created, err := useOrCreateSecurityGroup(nsId, sgReq, targetInfraModel.TargetSecurityGroupList, resourceLabels)
 		if err != nil {
 			log.Error().Err(err).Msgf("failed to use or create security group %s (nsId: %s)", sgReq.SecurityGroupId, nsId)
-			return emptyRet, err
+			return emptyRet, saga.fail(err)
+		}
+		if created {
+			saga.record(JournalResourceSecurityGroup, sgReq.SecurityGroupId)
+			reportProgress(reqId, ProgressSgCreated, ResourceProgress{Id: sgReq.SecurityGroupId})
+		} else {
+			reportProgress(reqId, ProgressSgReused, ResourceProgress{Id: sgReq.SecurityGroupId})
 		}
 	}
 
@@ -388,7 +459,7 @@ func CreateInfraWithExisting(nsId string, targetInfraModel *cloudmodel.Recommend
 	tbInfraReq, err := modelconv.ConvertWithValidation[cloudmodel.InfraReq, tbmodel.InfraReq](infraReq)
 	if err != nil {
 		log.Error().Err(err).Msgf("failed to convert the Infra request (nsId: %s)", nsId)
-		return emptyRet, err
+		return emptyRet, saga.fail(err)
 	}
 
 	if len(tbInfraReq.PostCommand.Command) == 0 {
@@ -399,12 +470,21 @@ func CreateInfraWithExisting(nsId string, targetInfraModel *cloudmodel.Recommend
 		}
 	}
 
+	reportProgress(reqId, ProgressInfraProvisioning, ResourceProgress{Id: tbInfraReq.Name})
+	stopWatching := watchInfraProvisioning(nsId, tbInfraReq.Name, reqId)
 	infraInfo, err := tbclient.NewSession().CreateInfra(nsId, tbInfraReq)
+	stopWatching()
 	if err != nil {
 		log.Error().Err(err).Msgf("failed to create the infrastructure (nsId: %s)", nsId)
-		return emptyRet, err
+		return emptyRet, saga.fail(err)
 	}
 	log.Debug().Msgf("infrastructure created: %s", infraInfo.Id)
+	saga.record(JournalResourceInfra, infraInfo.Id)
+	saga.succeed()
+	labelMigratedNodes(infraInfo, targetInfraModel.TargetInfra.NodeGroups)
+	putAppliedModel(nsId, infraInfo.Id, targetInfraModel)
+	reportProgress(reqId, ProgressInfraCreated, ResourceProgress{Id: infraInfo.Id})
+	notifyInfraMigrated(nsId, reqId, infraInfo, targetInfraModel.TargetInfra.Label)
 
 	infraInfoConverted, err := modelconv.ConvertWithValidation[tbmodel.InfraInfo, cloudmodel.InfraInfo](infraInfo)
 	if err != nil {
@@ -521,6 +601,7 @@ func DeleteVMInfra(nsId, infraId, option string) (common.SimpleMsg, error) {
 		return common.SimpleMsg{}, err
 	}
 	log.Debug().Msgf("Infra deleted (nsId: %s, infraId: %s, IdList: %s)", nsId, infraId, idList.IdList)
+	deleteAppliedModel(nsId, infraId)
 
 	// Sleep for a while to ensure previous deletions are completed
 	log.Debug().Msgf("Sleeping for 3 seconds to ensure Infra is deleted (nsId: %s)", nsId)
@@ -585,32 +666,9 @@ func DeleteVMInfra(nsId, infraId, option string) (common.SimpleMsg, error) {
 	log.Debug().Msgf("Deleting VNets (nsId: %s, vNets: %v)", nsId, vNetIdMap)
 
 	// Delete all vNet
-
-	const vNetDeleteMaxRetries = 10
-	const vNetDeleteRetryInterval = 10 * time.Second
-
 	for vNetId := range vNetIdMap {
-		var deleteErr error
-		for attempt := 1; attempt <= vNetDeleteMaxRetries; attempt++ {
-			log.Debug().Msgf("Deleting VNet (nsId: %s, vNetId: %s, attempt: %d/%d)",
-				nsId, vNetId, attempt, vNetDeleteMaxRetries)
-			msg, err := tbclient.NewSession().DeleteVNet(nsId, vNetId, "withsubnets")
-			if err == nil {
-				log.Debug().Msgf("VNet deleted (nsId: %s, vNetId: %s, msg: %s)", nsId, vNetId, msg)
-				deleteErr = nil
-				break
-			}
-			deleteErr = err
-			if attempt < vNetDeleteMaxRetries {
-				log.Warn().Err(err).Msgf("VNet deletion failed (nsId: %s, vNetId: %s, attempt: %d/%d) — "+
-					"CSP may still be releasing subnet dependencies. Retrying in %s...",
-					nsId, vNetId, attempt, vNetDeleteMaxRetries, vNetDeleteRetryInterval)
-				time.Sleep(vNetDeleteRetryInterval)
-			}
-		}
-		if deleteErr != nil {
-			log.Error().Err(deleteErr).Msgf("failed to delete VNet after %d attempts (nsId: %s, vNetId: %s)",
-				vNetDeleteMaxRetries, nsId, vNetId)
+		if err := deleteVNetWithRetry(nsId, vNetId); err != nil {
+			log.Error().Err(err).Msgf("failed to delete VNet (nsId: %s, vNetId: %s)", nsId, vNetId)
 		}
 	}
 
@@ -637,36 +695,88 @@ func DeleteVMInfra(nsId, infraId, option string) (common.SimpleMsg, error) {
 	return ret, nil
 }
 
+// deleteVNetWithRetry deletes the vNet with its subnets, retrying while the CSP releases the subnet dependencies
+func deleteVNetWithRetry(nsId, vNetId string) error {
+	const vNetDeleteMaxRetries = 10
+	const vNetDeleteRetryInterval = 10 * time.Second
+
+	var deleteErr error
+	for attempt := 1; attempt <= vNetDeleteMaxRetries; attempt++ {
+		log.Debug().Msgf("Deleting VNet (nsId: %s, vNetId: %s, attempt: %d/%d)",
+			nsId, vNetId, attempt, vNetDeleteMaxRetries)
+		msg, err := tbclient.NewSession().DeleteVNet(nsId, vNetId, "withsubnets")
+		if err == nil {
+			log.Debug().Msgf("VNet deleted (nsId: %s, vNetId: %s, msg: %s)", nsId, vNetId, msg)
+			return nil
+		}
+		deleteErr = err
+		if attempt < vNetDeleteMaxRetries {
+			log.Warn().Err(err).Msgf("VNet deletion failed (nsId: %s, vNetId: %s, attempt: %d/%d) — "+
+				"CSP may still be releasing subnet dependencies. Retrying in %s...",
+				nsId, vNetId, attempt, vNetDeleteMaxRetries, vNetDeleteRetryInterval)
+			time.Sleep(vNetDeleteRetryInterval)
+		}
+	}
+	return fmt.Errorf("failed to delete VNet after %d attempts: %w", vNetDeleteMaxRetries, deleteErr)
+}
+
 // preflightCheckCspProvisioning resolves the latest CSP image and confirms available system disk per nodegroup
 func preflightCheckCspProvisioning(nsId string, nodeGroups []cloudmodel.CreateNodeGroupReq) error {
 	log.Info().Msgf("running preflight check for all nodegroups (nsId: %s)", nsId)
 	for i := range nodeGroups {
 		ng := &nodeGroups[i]
-		precheck, reviewErr := recommendation.PreflightCheckCspProvisioning(
-			ng.SpecId, ng.ImageId, ng.CspImageName, ng.RootDiskType,
-		)
-		if reviewErr != nil {
-			log.Warn().Err(reviewErr).Msgf("preflight check failed for nodegroup %s (specId: %s, imageId: %s); proceeding with cached image",
-				ng.Name, ng.SpecId, ng.ImageId)
+		result := preflightNodeGroup(*ng)
+		if !result.Checked {
+			log.Warn().Msgf("%s; proceeding with cached image", result.Message)
 			continue
 		}
-		if !precheck.IsAvailable {
-			return fmt.Errorf("image %s is not available for nodegroup %s (specId: %s); aborting migration",
-				ng.ImageId, ng.Name, ng.SpecId)
+		if !result.ImageAvailable {
+			return fmt.Errorf("%s; aborting migration", result.Message)
 		}
-		if precheck.ResolvedCspImageName != ng.CspImageName {
-			log.Info().Msgf("nodegroup %s: CspImageName resolved from %q to %q", ng.Name, ng.CspImageName, precheck.ResolvedCspImageName)
-			ng.CspImageName = precheck.ResolvedCspImageName
+		if result.ResolvedCspImageName != ng.CspImageName {
+			log.Info().Msgf("nodegroup %s: CspImageName resolved from %q to %q", ng.Name, ng.CspImageName, result.ResolvedCspImageName)
+			ng.CspImageName = result.ResolvedCspImageName
 		}
-		if precheck.SuggestedSystemDisk != "" && ng.RootDiskType != precheck.SuggestedSystemDisk {
-			log.Info().Msgf("nodegroup %s: RootDiskType updated from %q to suggested %q", ng.Name, ng.RootDiskType, precheck.SuggestedSystemDisk)
-			ng.RootDiskType = precheck.SuggestedSystemDisk
+		if result.SuggestedRootDiskType != "" && ng.RootDiskType != result.SuggestedRootDiskType {
+			log.Info().Msgf("nodegroup %s: RootDiskType updated from %q to suggested %q", ng.Name, ng.RootDiskType, result.SuggestedRootDiskType)
+			ng.RootDiskType = result.SuggestedRootDiskType
 		}
 	}
 	log.Info().Msgf("spec-image pair preflight check passed (nsId: %s)", nsId)
 	return nil
 }
 
+// preflightNodeGroup runs the preflight check of a nodegroup without modifying it
+func preflightNodeGroup(ng cloudmodel.CreateNodeGroupReq) NodeGroupPreflight {
+	result := NodeGroupPreflight{
+		NodeGroup:     ng.Name,
+		NodeGroupSize: ng.NodeGroupSize,
+		SpecId:        ng.SpecId,
+		ImageId:       ng.ImageId,
+		CspImageName:  ng.CspImageName,
+		RootDiskType:  ng.RootDiskType,
+	}
+
+	precheck, reviewErr := recommendation.PreflightCheckCspProvisioning(
+		ng.SpecId, ng.ImageId, ng.CspImageName, ng.RootDiskType,
+	)
+	if reviewErr != nil {
+		result.Message = fmt.Sprintf("preflight check failed for nodegroup %s (specId: %s, imageId: %s): %v",
+			ng.Name, ng.SpecId, ng.ImageId, reviewErr)
+		return result
+	}
+
+	result.Checked = true
+	result.ImageAvailable = precheck.IsAvailable
+	result.ResolvedCspImageName = precheck.ResolvedCspImageName
+	result.SuggestedRootDiskType = precheck.SuggestedSystemDisk
+	if !precheck.IsAvailable {
+		result.Message = fmt.Sprintf("image %s is not available for nodegroup %s (specId: %s)",
+			ng.ImageId, ng.Name, ng.SpecId)
+	}
+	return result
+}
+
 // NetworkRequirement represents the virtual network and subnets required by the NodeGroups
 type NetworkRequirement struct {
 	VNetId         string
@@ -717,33 +827,84 @@ func deriveNetworkIds(nodeGroups []cloudmodel.CreateNodeGroupReq) []NetworkRequi
 	return reqs
 }
 
-// useOrCreateNetwork checks if VNet and required subnets exist, and creates them from the creation request if missing
-func useOrCreateNetwork(nsId string, netReq NetworkRequirement, vNetCreationReq cloudmodel.VNetReq) error {
+// targetVNets returns the vNets of the target infrastructure model (the target vNet first, then the additional vNets).
+func targetVNets(targetInfraModel *cloudmodel.RecommendedInfra) []cloudmodel.VNetReq {
+	return append([]cloudmodel.VNetReq{targetInfraModel.TargetVNet}, targetInfraModel.AdditionalVNetList...)
+}
+
+// useOrCreateNetwork checks if VNet and required subnets exist, and creates them from the creation request if missing.
+// It returns true if the vNet is created. The created vNet is labeled with the labels.
+func useOrCreateNetwork(nsId string, netReq NetworkRequirement, vNetCreationReqs []cloudmodel.VNetReq, labels map[string]string) (bool, error) {
+	planned, vNetReq := planNetwork(nsId, netReq, vNetCreationReqs)
+	switch planned.Action {
+	case PlanActionReuse:
+		log.Info().Msgf("vNet %s and all required subnets already exist. CM-Beetle will reuse it.", netReq.VNetId)
+		return false, nil
+	case PlanActionConflict:
+		return false, fmt.Errorf("%s", planned.Reason)
+	}
+
+	log.Debug().Msgf("Creating a vNet (nsId: %s, vNetName: %s)", nsId, vNetReq.Name)
+	tbVNetReq, err := modelconv.ConvertWithValidation[cloudmodel.VNetReq, tbmodel.VNetReq](vNetReq)
+	if err != nil {
+		return false, err
+	}
+
+	vNetInfo, err := tbclient.NewSession().CreateVNet(nsId, tbVNetReq)
+	if err != nil {
+		return false, err
+	}
+
+	log.Debug().Msgf("vNet created: %s", vNetReq.Name)
+	labelResource(tbclient.LabelTypeVNet, vNetInfo.Uid, labels)
+	return true, nil
+}
+
+// planNetwork decides whether the vNet is reused or created (read-only), and returns the creation request for the latter.
+// The creation request with the same name as the required vNet is used (the first one if none matches).
+// An existing vNet with missing subnets is a conflict, since the vNet cannot be created again with the same name.
+func planNetwork(nsId string, netReq NetworkRequirement, vNetCreationReqs []cloudmodel.VNetReq) (PlannedResource, cloudmodel.VNetReq) {
+	planned := PlannedResource{Type: JournalResourceVNet, Id: netReq.VNetId, Subnets: netReq.SubnetIds}
+
 	vNetInfo, err := tbclient.NewSession().ReadVNet(nsId, netReq.VNetId)
 	vNetExists := (err == nil && vNetInfo.Id != "")
-	allSubnetsExist := true
 
 	if vNetExists {
 		existingSubnets := make(map[string]bool)
 		for _, sub := range vNetInfo.SubnetInfoList {
 			existingSubnets[sub.Name] = true
 		}
+		var missingSubnets []string
 		for _, reqSubnet := range netReq.SubnetIds {
 			if !existingSubnets[reqSubnet] {
-				allSubnetsExist = false
 				log.Warn().Msgf("subnet %s is missing in existing vNet %s", reqSubnet, netReq.VNetId)
-				break
+				missingSubnets = append(missingSubnets, reqSubnet)
 			}
 		}
+		if len(missingSubnets) == 0 {
+			planned.Action = PlanActionReuse
+			return planned, cloudmodel.VNetReq{}
+		}
+		planned.Action = PlanActionConflict
+		planned.Reason = fmt.Sprintf("vNet %s already exists without the required subnets %v", netReq.VNetId, missingSubnets)
+		return planned, cloudmodel.VNetReq{}
 	}
 
-	if vNetExists && allSubnetsExist {
-		log.Info().Msgf("vNet %s and all required subnets already exist. CM-Beetle will reuse it.", netReq.VNetId)
-		return nil
+	var vNetCreationReq cloudmodel.VNetReq
+	if len(vNetCreationReqs) > 0 {
+		vNetCreationReq = vNetCreationReqs[0]
+	}
+	for _, req := range vNetCreationReqs {
+		if req.Name == netReq.VNetId {
+			vNetCreationReq = req
+			break
+		}
 	}
 
 	if vNetCreationReq.CidrBlock == "" {
-		return fmt.Errorf("vNet %s (or its subnets) does not exist, and VNet creation request is missing or invalid", netReq.VNetId)
+		planned.Action = PlanActionConflict
+		planned.Reason = fmt.Sprintf("vNet %s (or its subnets) does not exist, and VNet creation request is missing or invalid", netReq.VNetId)
+		return planned, cloudmodel.VNetReq{}
 	}
 
 	vNetReq := vNetCreationReq
@@ -755,9 +916,17 @@ func useOrCreateNetwork(nsId string, netReq NetworkRequirement, vNetCreationReq
 	var newSubnetList []cloudmodel.SubnetReq
 	for idx, subnetName := range netReq.SubnetIds {
 		var subReq cloudmodel.SubnetReq
-		if idx < len(vNetCreationReq.SubnetInfoList) {
+		matched := false
+		for _, sub := range vNetCreationReq.SubnetInfoList {
+			if sub.Name == subnetName {
+				subReq = sub
+				matched = true
+				break
+			}
+		}
+		if !matched && idx < len(vNetCreationReq.SubnetInfoList) {
 			subReq = vNetCreationReq.SubnetInfoList[idx]
-		} else if len(vNetCreationReq.SubnetInfoList) > 0 {
+		} else if !matched && len(vNetCreationReq.SubnetInfoList) > 0 {
 			subReq = vNetCreationReq.SubnetInfoList[0]
 		}
 		subReq.Name = subnetName
@@ -767,19 +936,8 @@ func useOrCreateNetwork(nsId string, netReq NetworkRequirement, vNetCreationReq
 		vNetReq.SubnetInfoList = newSubnetList
 	}
 
-	log.Debug().Msgf("Creating a vNet (nsId: %s, vNetName: %s)", nsId, vNetReq.Name)
-	tbVNetReq, err := modelconv.ConvertWithValidation[cloudmodel.VNetReq, tbmodel.VNetReq](vNetReq)
-	if err != nil {
-		return err
-	}
-
-	_, err = tbclient.NewSession().CreateVNet(nsId, tbVNetReq)
-	if err != nil {
-		return err
-	}
-
-	log.Debug().Msgf("vNet created: %s", vNetReq.Name)
-	return nil
+	planned.Action = PlanActionCreate
+	return planned, vNetReq
 }
 
 // SshKeyRequirement represents the SSH key required by the NodeGroups
@@ -805,16 +963,46 @@ func deriveSshKeyIds(nodeGroups []cloudmodel.CreateNodeGroupReq) []SshKeyRequire
 	return reqs
 }
 
-// useOrCreateSshKey checks if SSH key exists, and creates it from the creation request if missing
-func useOrCreateSshKey(nsId string, sshKeyReq SshKeyRequirement, sshKeyCreationReq cloudmodel.SshKeyReq) error {
+// useOrCreateSshKey checks if SSH key exists, and creates it from the creation request if missing (returns true if created)
+func useOrCreateSshKey(nsId string, sshKeyReq SshKeyRequirement, sshKeyCreationReq cloudmodel.SshKeyReq, labels map[string]string) (bool, error) {
+	planned, req := planSshKey(nsId, sshKeyReq, sshKeyCreationReq)
+	switch planned.Action {
+	case PlanActionReuse:
+		log.Info().Msgf("SSH key %s already exists. CM-Beetle will reuse it.", sshKeyReq.SshKeyId)
+		return false, nil
+	case PlanActionConflict:
+		return false, fmt.Errorf("%s", planned.Reason)
+	}
+
+	log.Debug().Msgf("Creating a SSH key (nsId: %s, sshKeyName: %s)", nsId, req.Name)
+	tbSshKeyReq, err := modelconv.ConvertWithValidation[cloudmodel.SshKeyReq, tbmodel.SshKeyReq](req)
+	if err != nil {
+		return false, err
+	}
+
+	sshKeyInfo, err := tbclient.NewSession().CreateSshKey(nsId, tbSshKeyReq)
+	if err != nil {
+		return false, err
+	}
+	log.Debug().Msgf("SSH key created: %s", req.Name)
+	labelResource(tbclient.LabelTypeSshKey, sshKeyInfo.Uid, labels)
+	return true, nil
+}
+
+// planSshKey decides whether the SSH key is reused or created (read-only), and returns the creation request for the latter
+func planSshKey(nsId string, sshKeyReq SshKeyRequirement, sshKeyCreationReq cloudmodel.SshKeyReq) (PlannedResource, cloudmodel.SshKeyReq) {
+	planned := PlannedResource{Type: JournalResourceSshKey, Id: sshKeyReq.SshKeyId}
+
 	sshKeyInfo, err := tbclient.NewSession().ReadSshKey(nsId, sshKeyReq.SshKeyId)
 	if err == nil && sshKeyInfo.Id != "" {
-		log.Info().Msgf("SSH key %s already exists. CM-Beetle will reuse it.", sshKeyReq.SshKeyId)
-		return nil
+		planned.Action = PlanActionReuse
+		return planned, cloudmodel.SshKeyReq{}
 	}
 
 	if sshKeyCreationReq.Name == "" {
-		return fmt.Errorf("SSH key %s does not exist, and SSH key creation request is missing or invalid", sshKeyReq.SshKeyId)
+		planned.Action = PlanActionConflict
+		planned.Reason = fmt.Sprintf("SSH key %s does not exist, and SSH key creation request is missing or invalid", sshKeyReq.SshKeyId)
+		return planned, cloudmodel.SshKeyReq{}
 	}
 
 	req := sshKeyCreationReq
@@ -823,18 +1011,8 @@ func useOrCreateSshKey(nsId string, sshKeyReq SshKeyRequirement, sshKeyCreationR
 		req.ConnectionName = sshKeyReq.ConnectionName
 	}
 
-	log.Debug().Msgf("Creating a SSH key (nsId: %s, sshKeyName: %s)", nsId, req.Name)
-	tbSshKeyReq, err := modelconv.ConvertWithValidation[cloudmodel.SshKeyReq, tbmodel.SshKeyReq](req)
-	if err != nil {
-		return err
-	}
-
-	_, err = tbclient.NewSession().CreateSshKey(nsId, tbSshKeyReq)
-	if err != nil {
-		return err
-	}
-	log.Debug().Msgf("SSH key created: %s", req.Name)
-	return nil
+	planned.Action = PlanActionCreate
+	return planned, req
 }
 
 // SecurityGroupRequirement represents the security group required by the NodeGroups
@@ -864,12 +1042,42 @@ func deriveSecurityGroupIds(nodeGroups []cloudmodel.CreateNodeGroupReq) []Securi
 	return reqs
 }
 
-// useOrCreateSecurityGroup checks if security group exists, and creates it from the creation request list if missing
-func useOrCreateSecurityGroup(nsId string, sgReq SecurityGroupRequirement, sgCreationReqList []cloudmodel.SecurityGroupReq) error {
+// useOrCreateSecurityGroup checks if security group exists, and creates it from the creation request list if missing (returns true if created)
+func useOrCreateSecurityGroup(nsId string, sgReq SecurityGroupRequirement, sgCreationReqList []cloudmodel.SecurityGroupReq, labels map[string]string) (bool, error) {
+	planned, sgCreationReq := planSecurityGroup(nsId, sgReq, sgCreationReqList)
+	switch planned.Action {
+	case PlanActionReuse:
+		log.Info().Msgf("Security group %s already exists. CM-Beetle will reuse it.", sgReq.SecurityGroupId)
+		return false, nil
+	case PlanActionConflict:
+		return false, fmt.Errorf("%s", planned.Reason)
+	}
+
+	sgCreationReq = checkAndSupportSSHAccessRule(sgCreationReq)
+
+	log.Debug().Msgf("Creating a security group (nsId: %s, sgName: %s, VNetId: %s)", nsId, sgCreationReq.Name, sgCreationReq.VNetId)
+	tbSgReq, err := modelconv.ConvertWithValidation[cloudmodel.SecurityGroupReq, tbmodel.SecurityGroupReq](sgCreationReq)
+	if err != nil {
+		return false, err
+	}
+
+	sgInfo, err := tbclient.NewSession().CreateSecurityGroup(nsId, tbSgReq, "")
+	if err != nil {
+		return false, err
+	}
+	log.Debug().Msgf("security group created: %s", sgCreationReq.Name)
+	labelResource(tbclient.LabelTypeSecurityGroup, sgInfo.Uid, labels)
+	return true, nil
+}
+
+// planSecurityGroup decides whether the security group is reused or created (read-only), and returns the creation request for the latter
+func planSecurityGroup(nsId string, sgReq SecurityGroupRequirement, sgCreationReqList []cloudmodel.SecurityGroupReq) (PlannedResource, cloudmodel.SecurityGroupReq) {
+	planned := PlannedResource{Type: JournalResourceSecurityGroup, Id: sgReq.SecurityGroupId}
+
 	sgInfo, err := tbclient.NewSession().ReadSecurityGroup(nsId, sgReq.SecurityGroupId)
 	if err == nil && sgInfo.Id != "" {
-		log.Info().Msgf("Security group %s already exists. CM-Beetle will reuse it.", sgReq.SecurityGroupId)
-		return nil
+		planned.Action = PlanActionReuse
+		return planned, cloudmodel.SecurityGroupReq{}
 	}
 
 	var sgCreationReq cloudmodel.SecurityGroupReq
@@ -893,27 +1101,36 @@ func useOrCreateSecurityGroup(nsId string, sgReq SecurityGroupRequirement, sgCre
 	}
 
 	if sgCreationReq.ConnectionName == "" || sgCreationReq.VNetId == "" {
-		return fmt.Errorf("security group %s does not exist, and required ConnectionName or VNetId is missing", sgReq.SecurityGroupId)
+		planned.Action = PlanActionConflict
+		planned.Reason = fmt.Sprintf("security group %s does not exist, and required ConnectionName or VNetId is missing", sgReq.SecurityGroupId)
+		return planned, cloudmodel.SecurityGroupReq{}
 	}
 
-	sgCreationReq = checkAndSupportSSHAccessRule(sgCreationReq)
+	planned.Action = PlanActionCreate
+	return planned, sgCreationReq
+}
 
-	log.Debug().Msgf("Creating a security group (nsId: %s, sgName: %s, VNetId: %s)", nsId, sgCreationReq.Name, sgCreationReq.VNetId)
-	tbSgReq, err := modelconv.ConvertWithValidation[cloudmodel.SecurityGroupReq, tbmodel.SecurityGroupReq](sgCreationReq)
-	if err != nil {
+// validateTargeInfraModel validates the target infrastructure model for fresh creation (useExisting=false)
+func validateTargeInfraModel(nsId string, targetVmInfraModel *cloudmodel.RecommendedInfra) error {
+	if err := validateTargeInfraModelReferences(nsId, targetVmInfraModel); err != nil {
 		return err
 	}
 
-	_, err = tbclient.NewSession().CreateSecurityGroup(nsId, tbSgReq, "")
-	if err != nil {
-		return err
+	// * 3. Validate that the vNet, SSH key, and security groups do not exist
+	// Note: VM specs and VM OS images validation is handled by the spec-image compatibility check
+	for _, planned := range planFreshResources(nsId, targetVmInfraModel) {
+		if planned.Action == PlanActionConflict {
+			log.Error().Msg(planned.Reason)
+			return fmt.Errorf("%s", planned.Reason)
+		}
 	}
-	log.Debug().Msgf("security group created: %s", sgCreationReq.Name)
+
 	return nil
 }
 
-// validateTargeInfraModel validates the target infrastructure model for fresh creation (useExisting=false)
-func validateTargeInfraModel(nsId string, targetVmInfraModel *cloudmodel.RecommendedInfra) error {
+// validateTargeInfraModelReferences validates the names, the references and the spec-image compatibility
+// of the target infrastructure model for fresh creation (useExisting=false)
+func validateTargeInfraModelReferences(nsId string, targetVmInfraModel *cloudmodel.RecommendedInfra) error {
 	// * 1. Validate that name fields are not empty
 	if targetVmInfraModel == nil {
 		log.Error().Msgf("target infrastructure model is nil (nsId: %s)", nsId)
@@ -923,9 +1140,11 @@ func validateTargeInfraModel(nsId string, targetVmInfraModel *cloudmodel.Recomme
 		log.Error().Msgf("target VM infrastructure name is empty (nsId: %s)", nsId)
 		return fmt.Errorf("target VM infrastructure name is empty")
 	}
-	if targetVmInfraModel.TargetVNet.Name == "" {
-		log.Error().Msgf("target VNet name is empty (nsId: %s)", nsId)
-		return fmt.Errorf("target VNet name is empty")
+	for _, vNet := range targetVNets(targetVmInfraModel) {
+		if vNet.Name == "" {
+			log.Error().Msgf("target VNet name is empty (nsId: %s)", nsId)
+			return fmt.Errorf("target VNet name is empty")
+		}
 	}
 	if targetVmInfraModel.TargetSshKey.Name == "" {
 		log.Error().Msgf("target SSH key name is empty (nsId: %s)", nsId)
@@ -939,9 +1158,13 @@ func validateTargeInfraModel(nsId string, targetVmInfraModel *cloudmodel.Recomme
 	}
 
 	// * 2. Validate that the names or IDs are matched in the model
-	// Check if each Node's vNetId matches the target VNet name
+	// Check if each Node's vNetId matches the target VNet name (or one of the additional VNet names)
+	vNetNames := map[string]bool{}
+	for _, vNet := range targetVNets(targetVmInfraModel) {
+		vNetNames[vNet.Name] = true
+	}
 	for _, nodegroup := range targetVmInfraModel.TargetInfra.NodeGroups {
-		if nodegroup.VNetId != targetVmInfraModel.TargetVNet.Name {
+		if !vNetNames[nodegroup.VNetId] {
 			log.Error().Msgf("target VM infrastructure vNetId (%s) does not match target VNet name (%s)",
 				nodegroup.VNetId, targetVmInfraModel.TargetVNet.Name)
 			return fmt.Errorf("target VM infrastructure vNetId (%s) does not match target VNet name (%s)",
@@ -1065,45 +1288,6 @@ func validateTargeInfraModel(nsId string, targetVmInfraModel *cloudmodel.Recomme
 		}
 	}
 
-	// * 3. Validate that the vNet, VM specs, VM OS images, and security groups exist
-	// Validate that the vNet exists by the vNet name
-	// For VNet, it's normal if the resource doesn't exist
-	vNetInfo, err := tbclient.NewSession().ReadVNet(nsId, targetVmInfraModel.TargetVNet.Name)
-	if err != nil {
-		log.Debug().Msgf("the vNet not found (nsId: %s, vNet.Name: %s), which is normal case", nsId, targetVmInfraModel.TargetVNet.Name)
-	}
-	if vNetInfo.Id != "" {
-		log.Error().Msgf("the vNet already exists (nsId: %s, vNetInfo.Id: %s)", nsId, vNetInfo.Id)
-		return fmt.Errorf("the vNet already exists (nsId: %s, vNetInfo.Id: %s)", nsId, vNetInfo.Id)
-	}
-
-	// Validate that the SSH key exists by the SSH key name
-	// For SSH Key, it's normal if the resource doesn't exist
-	sshKeyInfo, err := tbclient.NewSession().ReadSshKey(nsId, targetVmInfraModel.TargetSshKey.Name)
-	if err != nil {
-		log.Debug().Err(err).Msgf("SSH key not found (nsId: %s, sshKey.Name: %s), which is normal case", nsId, targetVmInfraModel.TargetSshKey.Name)
-	}
-	if sshKeyInfo.Id != "" {
-		log.Error().Msgf("the SSH key already exists (nsId: %s, sshKey.Id: %s)", nsId, sshKeyInfo.Id)
-		return fmt.Errorf("the SSH key already exists (nsId: %s, sshKey.Id: %s)", nsId, sshKeyInfo.Id)
-	}
-
-	// Note: VM specs and VM OS images validation is now handled above in the spec-image compatibility check loop
-	// This provides better validation by checking both existence and compatibility together
-
-	// Validate that the security groups exist by the security group name
-	// For Security Groups, it's normal if the resources don't exist
-	for _, sg := range targetVmInfraModel.TargetSecurityGroupList {
-		sgInfo, err := tbclient.NewSession().ReadSecurityGroup(nsId, sg.Name)
-		if err != nil {
-			log.Debug().Msgf("the security group not found (nsId: %s, sg.Name: %s), which is normal case", nsId, sg.Name)
-		}
-		if sgInfo.Id != "" {
-			log.Error().Msgf("the security group already exists (nsId: %s, sgInfo.Id: %s)", nsId, sgInfo.Id)
-			return fmt.Errorf("the security group already exists (nsId: %s, sgInfo.Id: %s)", nsId, sgInfo.Id)
-		}
-	}
-
 	return nil
 }
 
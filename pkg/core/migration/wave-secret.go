@@ -0,0 +1,249 @@
+package migration
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	"github.com/cloud-barista/cm-beetle/transx"
+	"github.com/cloud-barista/cm-beetle/transx/fieldsec"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Credentials of the wave plans at rest
+// The data migrations of a wave plan are stored with their sensitive fields
+// (SSH private keys, S3 credentials, auth passwords/tokens) encrypted by
+// transx.EncryptModel with the wave secret key, and decrypted right before
+// each data migration step. The SSH private key of the target infra is
+// encrypted in the same way and decrypted right before each infra step.
+// The key is kept in a PEM file (mode 0600) apart from the lkvstore file,
+// so a leaked lkvstore file does not leak the credentials.
+// ============================================================================
+
+// secretMask is the value shown instead of a sensitive field in the responses.
+const secretMask = "********"
+
+var (
+	waveKeyMutex   sync.RWMutex
+	waveSecretKey  *transx.KeyPair
+	errNoSecretKey = errors.New("the wave secret key is not initialized")
+)
+
+// InitWaveSecretKey loads the wave secret key from the PEM file, or generates it if the file does not exist.
+// The key must be kept to resume the wave plans stored with it.
+func InitWaveSecretKey(keyFilePath string) error {
+	privateKey, err := loadOrCreateRsaKey(keyFilePath)
+	if err != nil {
+		return err
+	}
+
+	pubASN1, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the public key: %w", err)
+	}
+	fingerprint := sha256.Sum256(pubASN1)
+
+	waveKeyMutex.Lock()
+	defer waveKeyMutex.Unlock()
+	waveSecretKey = &transx.KeyPair{
+		ID:         "wave-" + hex.EncodeToString(fingerprint[:8]),
+		PublicKey:  &privateKey.PublicKey,
+		PrivateKey: privateKey,
+		ExpiresAt:  time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), // Does not expire
+	}
+	log.Info().Msgf("loaded the wave secret key (keyId: %s, path: %s)", waveSecretKey.ID, keyFilePath)
+	return nil
+}
+
+// loadOrCreateRsaKey reads the RSA private key (PKCS#8 PEM) of the file, generating and writing one if missing.
+func loadOrCreateRsaKey(keyFilePath string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(keyFilePath)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM block in the key file (%s)", keyFilePath)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the key file (%s): %w", keyFilePath, err)
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("the key file (%s) is not an RSA private key", keyFilePath)
+		}
+		return rsaKey, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read the key file (%s): %w", keyFilePath, err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate an RSA key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the RSA key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFilePath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the directory of the key file (%s): %w", keyFilePath, err)
+	}
+	if err := os.WriteFile(keyFilePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write the key file (%s): %w", keyFilePath, err)
+	}
+	log.Info().Msgf("generated a wave secret key (path: %s)", keyFilePath)
+	return rsaKey, nil
+}
+
+// sealDataMigration encrypts the sensitive fields of the data migration with the wave secret key.
+func sealDataMigration(dmm transx.DataMigrationModel) (transx.DataMigrationModel, error) {
+	waveKeyMutex.RLock()
+	keyPair := waveSecretKey
+	waveKeyMutex.RUnlock()
+	if keyPair == nil {
+		return transx.DataMigrationModel{}, errNoSecretKey
+	}
+	return transx.EncryptModel(dmm, keyPair.PublicKey, keyPair.ID)
+}
+
+// openDataMigration decrypts the sensitive fields of the data migration sealed with the wave secret key.
+// A data migration stored in plaintext (e.g., by an earlier version) is returned as-is.
+func openDataMigration(dmm transx.DataMigrationModel) (transx.DataMigrationModel, error) {
+	if !dmm.IsEncrypted() {
+		return dmm, nil
+	}
+
+	waveKeyMutex.RLock()
+	keyPair := waveSecretKey
+	waveKeyMutex.RUnlock()
+	if keyPair == nil {
+		return transx.DataMigrationModel{}, errNoSecretKey
+	}
+	decrypted, err := transx.DecryptModelWith(dmm, keyPair)
+	if err != nil {
+		return transx.DataMigrationModel{}, fmt.Errorf("failed to decrypt the credentials (keyId: %s): %w", dmm.EncryptionKeyID, err)
+	}
+	return decrypted, nil
+}
+
+// sshKeySensitiveFields are the JSON paths of the sensitive fields of the SSH key.
+var sshKeySensitiveFields = []string{"privateKey"}
+
+// sealSshKey encrypts the private key of the SSH key with the wave secret key, and returns the ID of the key.
+// An SSH key without a private key is returned as-is (with an empty key ID).
+func sealSshKey(sshKey cloudmodel.SshKeyReq) (cloudmodel.SshKeyReq, string, error) {
+	if sshKey.PrivateKey == "" {
+		return sshKey, "", nil
+	}
+
+	waveKeyMutex.RLock()
+	keyPair := waveSecretKey
+	waveKeyMutex.RUnlock()
+	if keyPair == nil {
+		return cloudmodel.SshKeyReq{}, "", errNoSecretKey
+	}
+	sealed, err := fieldsec.Encrypt(sshKey, sshKeySensitiveFields, keyPair.PublicKey, "", "")
+	if err != nil {
+		return cloudmodel.SshKeyReq{}, "", err
+	}
+	return sealed.(cloudmodel.SshKeyReq), keyPair.ID, nil
+}
+
+// openSshKey decrypts the private key of the SSH key sealed with the wave secret key (keyId).
+// An SSH key stored in plaintext (i.e., an empty keyId) is returned as-is.
+func openSshKey(sshKey cloudmodel.SshKeyReq, keyId string) (cloudmodel.SshKeyReq, error) {
+	if keyId == "" {
+		return sshKey, nil
+	}
+
+	waveKeyMutex.RLock()
+	keyPair := waveSecretKey
+	waveKeyMutex.RUnlock()
+	if keyPair == nil {
+		return cloudmodel.SshKeyReq{}, errNoSecretKey
+	}
+	opened, err := fieldsec.DecryptWithKeyPair(sshKey, sshKeySensitiveFields, keyPair, keyId, "")
+	if err != nil {
+		return cloudmodel.SshKeyReq{}, fmt.Errorf("failed to decrypt the SSH private key (keyId: %s): %w", keyId, err)
+	}
+	return opened.(cloudmodel.SshKeyReq), nil
+}
+
+// maskDataMigration returns a copy of the data migration with the sensitive fields masked (empty fields are left empty).
+func maskDataMigration(dmm transx.DataMigrationModel) transx.DataMigrationModel {
+	dmm.Source = maskDataLocation(dmm.Source)
+	dmm.Destination = maskDataLocation(dmm.Destination)
+	dmm.EncryptionKeyID = ""
+	return dmm
+}
+
+// maskDataLocation returns a copy of the data location with the credentials masked.
+func maskDataLocation(loc transx.DataLocation) transx.DataLocation {
+	if loc.Filesystem != nil && loc.Filesystem.SSH != nil {
+		fs := *loc.Filesystem
+		ssh := *fs.SSH
+		ssh.PrivateKey = maskSecret(ssh.PrivateKey)
+		fs.SSH = &ssh
+		loc.Filesystem = &fs
+	}
+
+	if loc.ObjectStorage != nil {
+		objStorage := *loc.ObjectStorage
+		if objStorage.Minio != nil {
+			minio := *objStorage.Minio
+			minio.AccessKeyId = maskSecret(minio.AccessKeyId)
+			minio.SecretAccessKey = maskSecret(minio.SecretAccessKey)
+			objStorage.Minio = &minio
+		}
+		if objStorage.Spider != nil {
+			spider := *objStorage.Spider
+			spider.Auth = maskAuth(spider.Auth)
+			objStorage.Spider = &spider
+		}
+		if objStorage.Tumblebug != nil {
+			tumblebug := *objStorage.Tumblebug
+			tumblebug.Auth = maskAuth(tumblebug.Auth)
+			objStorage.Tumblebug = &tumblebug
+		}
+		loc.ObjectStorage = &objStorage
+	}
+	return loc
+}
+
+// maskAuth returns a copy of the auth config with the password and the token masked.
+func maskAuth(auth *transx.AuthConfig) *transx.AuthConfig {
+	if auth == nil {
+		return nil
+	}
+	masked := *auth
+	if masked.Basic != nil {
+		basic := *masked.Basic
+		basic.Password = maskSecret(basic.Password)
+		masked.Basic = &basic
+	}
+	if masked.JWT != nil {
+		jwt := *masked.JWT
+		jwt.Token = maskSecret(jwt.Token)
+		masked.JWT = &jwt
+	}
+	return &masked
+}
+
+// maskSecret returns the mask for a non-empty secret.
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return secretMask
+}
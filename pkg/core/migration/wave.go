@@ -0,0 +1,897 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/cloud-barista/cm-beetle/pkg/lkvstore"
+	"github.com/cloud-barista/cm-beetle/transx"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Migration waves
+// Partitions the node groups, data migrations and NLB migrations of a migration
+// into ordered waves (e.g., DB tier, then app tier, then web tier behind the NLB).
+// Each wave starts when its gate conditions are met and is executed via
+// CreateInfraWithExisting, transx.MigrateData and CreateNlbs. The state of the
+// waves is persisted, so a paused or failed wave plan can be resumed.
+// ============================================================================
+
+// Wave plan statuses
+const (
+	WavePlanStatusPending   = "Pending"
+	WavePlanStatusRunning   = "Running"
+	WavePlanStatusPaused    = "Paused" // Waiting at the gate of a wave
+	WavePlanStatusSucceeded = "Succeeded"
+	WavePlanStatusFailed    = "Failed"
+)
+
+// Wave statuses
+const (
+	WaveStatusPending          = "Pending"
+	WaveStatusBlocked          = "Blocked" // The previous wave is not healthy
+	WaveStatusAwaitingApproval = "AwaitingApproval"
+	WaveStatusWaitingForWindow = "WaitingForWindow"
+	WaveStatusRunning          = "Running"
+	WaveStatusSucceeded        = "Succeeded"
+	WaveStatusFailed           = "Failed"
+)
+
+// Types and statuses of the wave steps
+const (
+	WaveStepInfra = "infra"
+	WaveStepData  = "data"
+	WaveStepNlb   = "nlb"
+
+	WaveStepPending   = "Pending"
+	WaveStepSucceeded = "Succeeded"
+	WaveStepFailed    = "Failed"
+)
+
+const wavePlanKeyPrefix = "/beetle/migration/wave/"
+
+var (
+	// wavePlanMutex serializes the updates of the wave plans
+	wavePlanMutex sync.Mutex
+
+	// runningWavePlans is the set of the wave plans being executed (key: nsId/planId)
+	runningWavePlans = map[string]bool{}
+
+	waveGuardsMutex sync.RWMutex
+	waveGuards      []WaveGuard
+)
+
+// WaveGuard checks a wave right before it is executed; an error fails the wave (e.g., a policy violation).
+type WaveGuard func(plan WavePlan, wave Wave) error
+
+// OnWaveExecuting registers a guard called right before each wave is executed,
+// so that the conditions changed after the wave plan was created (e.g., the policies) are enforced.
+func OnWaveExecuting(guard WaveGuard) {
+	waveGuardsMutex.Lock()
+	defer waveGuardsMutex.Unlock()
+	waveGuards = append(waveGuards, guard)
+}
+
+// checkWaveGuards calls the registered guards in order and returns the first error.
+func checkWaveGuards(plan WavePlan, wave Wave) error {
+	waveGuardsMutex.RLock()
+	guards := append([]WaveGuard(nil), waveGuards...)
+	waveGuardsMutex.RUnlock()
+
+	for _, guard := range guards {
+		if err := guard(plan, wave); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WavePlanReq is the request to create a wave plan.
+type WavePlanReq struct {
+	// Name is the ID of the wave plan in the namespace
+	Name string `json:"name" validate:"required" example:"wave01"`
+	// RollbackPolicy is applied to the infra migration of each wave (default: always)
+	RollbackPolicy string `json:"rollbackPolicy,omitempty" enums:"always,never,keep-for-debug" example:"always"`
+	// TargetInfra is the recommended infrastructure whose node groups are partitioned into the waves
+	TargetInfra cloudmodel.RecommendedInfra `json:"targetInfra" validate:"required"`
+	// Waves are executed in order
+	Waves []WaveReq `json:"waves" validate:"required"`
+}
+
+// WaveReq is a wave of the wave plan.
+type WaveReq struct {
+	Name string `json:"name" validate:"required" example:"db"`
+	// NodeGroups are the names of the node groups of the target infra migrated in this wave (as an Infra named '<targetInfra>-<wave>')
+	NodeGroups []string `json:"nodeGroups,omitempty" example:"g1"`
+	// DataMigrations are executed after the node groups are migrated
+	DataMigrations []transx.DataMigrationModel `json:"dataMigrations,omitempty"`
+	// Nlbs are migrated last; targetGroup.nodeGroupId must be a node group of this or a previous wave
+	Nlbs []cloudmodel.NlbReq `json:"nlbs,omitempty"`
+	// Gate is the conditions to start this wave
+	Gate WaveGate `json:"gate"`
+}
+
+// WaveGate is the gate conditions to start a wave (all of them must be met).
+type WaveGate struct {
+	// PreviousWaveHealthy requires that the previous wave succeeded and the VMs of its Infra are all running
+	PreviousWaveHealthy bool `json:"previousWaveHealthy"`
+	// ManualApproval requires an approval via POST /migration/ns/{nsId}/wavePlan/{planId}/wave/{waveName}/approve
+	ManualApproval bool `json:"manualApproval"`
+	// TimeWindow is the daily time window in which the wave may start
+	TimeWindow *WaveTimeWindow `json:"timeWindow,omitempty"`
+}
+
+// WaveTimeWindow is a daily time window (HH:MM, may cross midnight, e.g., 22:00-04:00).
+type WaveTimeWindow struct {
+	Start    string `json:"start" example:"02:00"`
+	End      string `json:"end" example:"05:00"`
+	Timezone string `json:"timezone,omitempty" example:"Asia/Seoul"` // IANA time zone (default: UTC)
+}
+
+// WavePlan is a wave plan and its execution state.
+type WavePlan struct {
+	Id             string                      `json:"id" example:"wave01"`
+	NsId           string                      `json:"nsId" example:"mig01"`
+	RollbackPolicy string                      `json:"rollbackPolicy" example:"always"`
+	Status         string                      `json:"status" example:"Pending"`
+	CurrentWave    string                      `json:"currentWave,omitempty" example:"db"`
+	Message        string                      `json:"message,omitempty"`
+	TargetInfra    cloudmodel.RecommendedInfra `json:"targetInfra"`
+	SshKeySealedBy string                      `json:"sshKeySealedBy,omitempty"` // ID of the wave secret key encrypting the SSH private key of the target infra
+	Waves          []Wave                      `json:"waves"`
+	CreatedAt      time.Time                   `json:"createdAt"`
+	UpdatedAt      time.Time                   `json:"updatedAt"`
+}
+
+// Wave is a wave and its execution state.
+type Wave struct {
+	WaveReq
+	InfraId    string     `json:"infraId,omitempty" example:"mmci01-db"`
+	Status     string     `json:"status" example:"Pending"`
+	Error      string     `json:"error,omitempty"`
+	Steps      []WaveStep `json:"steps"`
+	ApprovedAt *time.Time `json:"approvedAt,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// WaveStep is a step of a wave; the succeeded steps are skipped when the wave is resumed.
+type WaveStep struct {
+	Type   string `json:"type" example:"infra"`
+	Id     string `json:"id" example:"mmci01-db"`
+	Status string `json:"status" example:"Pending"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WavePlanList is the list of wave plans.
+type WavePlanList struct {
+	WavePlans []WavePlan `json:"wavePlans"`
+}
+
+// ============================================================================
+// Wave plan management
+// ============================================================================
+
+// CreateWavePlan validates and stores a wave plan (to be executed by ResumeWavePlan).
+func CreateWavePlan(nsId string, req WavePlanReq) (WavePlan, error) {
+	if ok, detail := common.IsValidName(req.Name); !ok {
+		return WavePlan{}, fmt.Errorf("invalid wave plan name (%s): %s", req.Name, detail)
+	}
+	if !IsValidRollbackPolicy(req.RollbackPolicy) {
+		return WavePlan{}, fmt.Errorf("invalid rollbackPolicy (%s)", req.RollbackPolicy)
+	}
+	if err := validateWaves(req); err != nil {
+		return WavePlan{}, err
+	}
+
+	rollbackPolicy := req.RollbackPolicy
+	if rollbackPolicy == "" {
+		rollbackPolicy = RollbackPolicyAlways
+	}
+
+	now := time.Now()
+	plan := WavePlan{
+		Id:             req.Name,
+		NsId:           nsId,
+		RollbackPolicy: rollbackPolicy,
+		Status:         WavePlanStatusPending,
+		TargetInfra:    req.TargetInfra,
+		Waves:          []Wave{},
+		CreatedAt:      now,
+	}
+
+	// The SSH private key of the target infra is stored encrypted
+	sshKey, keyId, err := sealSshKey(plan.TargetInfra.TargetSshKey)
+	if err != nil {
+		return WavePlan{}, fmt.Errorf("failed to encrypt the SSH private key of the target infra: %w", err)
+	}
+	plan.TargetInfra.TargetSshKey = sshKey
+	plan.SshKeySealedBy = keyId
+
+	for _, waveReq := range req.Waves {
+		wave := newWave(req.TargetInfra.TargetInfra.Name, waveReq)
+
+		// The credentials of the data migrations are stored encrypted
+		sealed := make([]transx.DataMigrationModel, len(wave.DataMigrations))
+		for j, dmm := range wave.DataMigrations {
+			var err error
+			if sealed[j], err = sealDataMigration(dmm); err != nil {
+				return WavePlan{}, fmt.Errorf("failed to encrypt data migration %d of wave %s: %w", j+1, wave.Name, err)
+			}
+		}
+		wave.DataMigrations = sealed
+		plan.Waves = append(plan.Waves, wave)
+	}
+
+	wavePlanMutex.Lock()
+	defer wavePlanMutex.Unlock()
+	if _, exists := getWavePlan(nsId, plan.Id); exists {
+		return WavePlan{}, fmt.Errorf("the wave plan (%s) already exists", plan.Id)
+	}
+	putWavePlan(&plan)
+
+	log.Info().Msgf("wave plan created (nsId: %s, planId: %s, waves: %d)", nsId, plan.Id, len(plan.Waves))
+	return redactWavePlan(plan), nil
+}
+
+// newWave initializes the state and the steps of a wave.
+func newWave(infraName string, waveReq WaveReq) Wave {
+	wave := Wave{
+		WaveReq: waveReq,
+		Status:  WaveStatusPending,
+		Steps:   []WaveStep{},
+	}
+	if len(waveReq.NodeGroups) > 0 {
+		wave.InfraId = waveInfraName(infraName, waveReq.Name)
+		wave.Steps = append(wave.Steps, WaveStep{Type: WaveStepInfra, Id: wave.InfraId, Status: WaveStepPending})
+	}
+	for i := range waveReq.DataMigrations {
+		wave.Steps = append(wave.Steps, WaveStep{Type: WaveStepData, Id: fmt.Sprintf("data-%d", i+1), Status: WaveStepPending})
+	}
+	for _, nlb := range waveReq.Nlbs {
+		wave.Steps = append(wave.Steps, WaveStep{
+			Type:   WaveStepNlb,
+			Id:     fmt.Sprintf("%s:%s", nlb.TargetGroup.NodeGroupId, nlb.Listener.Port),
+			Status: WaveStepPending,
+		})
+	}
+	return wave
+}
+
+// waveInfraName is the name of the Infra migrated in the wave.
+func waveInfraName(infraName, waveName string) string {
+	return infraName + "-" + waveName
+}
+
+// validateWaves validates the waves of the wave plan request.
+func validateWaves(req WavePlanReq) error {
+	if len(req.Waves) == 0 {
+		return fmt.Errorf("no wave in the wave plan")
+	}
+
+	nodeGroups := map[string]bool{}
+	for _, ng := range req.TargetInfra.TargetInfra.NodeGroups {
+		nodeGroups[ng.Name] = true
+	}
+
+	waveNames := map[string]bool{}
+	assigned := map[string]string{} // node group -> wave
+	for i, wave := range req.Waves {
+		if ok, detail := common.IsValidName(wave.Name); !ok {
+			return fmt.Errorf("invalid wave name (%s): %s", wave.Name, detail)
+		}
+		if waveNames[wave.Name] {
+			return fmt.Errorf("duplicate wave name (%s)", wave.Name)
+		}
+		waveNames[wave.Name] = true
+
+		if len(wave.NodeGroups) == 0 && len(wave.DataMigrations) == 0 && len(wave.Nlbs) == 0 {
+			return fmt.Errorf("wave %s has nothing to migrate", wave.Name)
+		}
+
+		if len(wave.NodeGroups) > 0 {
+			infraName := waveInfraName(req.TargetInfra.TargetInfra.Name, wave.Name)
+			if ok, detail := common.IsValidName(infraName); !ok {
+				return fmt.Errorf("invalid Infra name (%s) of wave %s: %s", infraName, wave.Name, detail)
+			}
+		}
+		for _, ng := range wave.NodeGroups {
+			if !nodeGroups[ng] {
+				return fmt.Errorf("node group %s of wave %s does not exist in the target infra", ng, wave.Name)
+			}
+			if prev, ok := assigned[ng]; ok {
+				return fmt.Errorf("node group %s is assigned to both wave %s and wave %s", ng, prev, wave.Name)
+			}
+			assigned[ng] = wave.Name
+		}
+
+		for j, dmm := range wave.DataMigrations {
+			if dmm.IsEncrypted() {
+				return fmt.Errorf("data migration %d of wave %s is still encrypted", j+1, wave.Name)
+			}
+			if err := transx.Validate(dmm); err != nil {
+				return fmt.Errorf("invalid data migration %d of wave %s: %w", j+1, wave.Name, err)
+			}
+			if dmm.Source.IsLocal() || dmm.Destination.IsLocal() {
+				return fmt.Errorf("data migration %d of wave %s uses the local filesystem; use SSH or object storage", j+1, wave.Name)
+			}
+		}
+
+		// The node group of the NLB must be migrated in this or a previous wave
+		for _, nlb := range wave.Nlbs {
+			ngId := nlb.TargetGroup.NodeGroupId
+			if ngId == "" {
+				return fmt.Errorf("targetGroup.nodeGroupId of an NLB of wave %s is empty", wave.Name)
+			}
+			if _, ok := assigned[ngId]; !ok {
+				return fmt.Errorf("node group %s of an NLB of wave %s is not migrated in this or a previous wave", ngId, wave.Name)
+			}
+		}
+
+		if wave.Gate.TimeWindow != nil {
+			if _, _, err := wave.Gate.TimeWindow.nextOpen(time.Now()); err != nil {
+				return fmt.Errorf("invalid time window of wave %s: %w", wave.Name, err)
+			}
+		}
+		if i == 0 && wave.Gate.PreviousWaveHealthy {
+			log.Debug().Msgf("the first wave (%s) has no previous wave; the previousWaveHealthy gate is ignored", wave.Name)
+		}
+	}
+
+	for _, ng := range req.TargetInfra.TargetInfra.NodeGroups {
+		if _, ok := assigned[ng.Name]; !ok {
+			return fmt.Errorf("node group %s is not assigned to any wave", ng.Name)
+		}
+	}
+	return nil
+}
+
+// GetWavePlan returns the wave plan with the sensitive fields of the data migrations masked.
+func GetWavePlan(nsId, planId string) (WavePlan, bool) {
+	plan, ok := getWavePlan(nsId, planId)
+	if !ok {
+		return WavePlan{}, false
+	}
+	return redactWavePlan(plan), true
+}
+
+// ListWavePlans returns the wave plans in the namespace (with the sensitive fields masked), newest first.
+func ListWavePlans(nsId string) WavePlanList {
+	ret := WavePlanList{WavePlans: []WavePlan{}}
+
+	values, ok := lkvstore.GetWithPrefix(wavePlanKeyPrefix + nsId + "/")
+	if !ok {
+		return ret
+	}
+	for _, value := range values {
+		if plan, ok := convertToWavePlan(value); ok {
+			ret.WavePlans = append(ret.WavePlans, redactWavePlan(plan))
+		}
+	}
+	sort.Slice(ret.WavePlans, func(i, j int) bool {
+		return ret.WavePlans[i].CreatedAt.After(ret.WavePlans[j].CreatedAt)
+	})
+	return ret
+}
+
+// ApproveWave approves the manual approval gate of a wave.
+// If the wave plan is paused waiting for the approval of the wave, it is resumed in the background.
+func ApproveWave(nsId, planId, waveName, reqId string) (WavePlan, error) {
+	var resume bool
+	plan, err := updateWavePlan(nsId, planId, func(p *WavePlan) error {
+		wave := p.wave(waveName)
+		if wave == nil {
+			return fmt.Errorf("the wave (%s) does not exist in the wave plan (%s)", waveName, planId)
+		}
+		if wave.Status == WaveStatusSucceeded {
+			return fmt.Errorf("the wave (%s) cannot be approved in the status (%s)", waveName, wave.Status)
+		}
+		now := time.Now()
+		wave.ApprovedAt = &now
+		resume = p.Status == WavePlanStatusPaused && p.CurrentWave == waveName
+		return nil
+	})
+	if err != nil {
+		return WavePlan{}, err
+	}
+	log.Info().Msgf("wave approved (nsId: %s, planId: %s, wave: %s)", nsId, planId, waveName)
+
+	if resume {
+		return ResumeWavePlan(nsId, planId, reqId)
+	}
+	return redactWavePlan(plan), nil
+}
+
+// ResumeWavePlan starts (or resumes) the wave plan in the background from the first wave not succeeded.
+// The succeeded steps of a failed wave are skipped. The request ID (reqId, optional) is used to publish the progress.
+func ResumeWavePlan(nsId, planId, reqId string) (WavePlan, error) {
+	key := nsId + "/" + planId
+
+	wavePlanMutex.Lock()
+	plan, ok := getWavePlan(nsId, planId)
+	if !ok {
+		wavePlanMutex.Unlock()
+		return WavePlan{}, fmt.Errorf("the wave plan (%s) does not exist", planId)
+	}
+	if runningWavePlans[key] {
+		wavePlanMutex.Unlock()
+		return redactWavePlan(plan), fmt.Errorf("the wave plan (%s) cannot be resumed in the status (%s)", planId, WavePlanStatusRunning)
+	}
+	if plan.Status == WavePlanStatusSucceeded {
+		wavePlanMutex.Unlock()
+		return redactWavePlan(plan), fmt.Errorf("the wave plan (%s) cannot be resumed in the status (%s)", planId, plan.Status)
+	}
+	runningWavePlans[key] = true
+	plan.Status = WavePlanStatusRunning
+	plan.Message = ""
+	putWavePlan(&plan)
+	wavePlanMutex.Unlock()
+
+	go executeWavePlan(nsId, planId, reqId)
+
+	log.Info().Msgf("wave plan started (nsId: %s, planId: %s)", nsId, planId)
+	return redactWavePlan(plan), nil
+}
+
+// ============================================================================
+// Wave plan execution
+// ============================================================================
+
+// executeWavePlan executes the waves in order until a wave pauses at its gate or fails.
+func executeWavePlan(nsId, planId, reqId string) {
+	defer func() {
+		wavePlanMutex.Lock()
+		delete(runningWavePlans, nsId+"/"+planId)
+		wavePlanMutex.Unlock()
+	}()
+
+	plan, ok := getWavePlan(nsId, planId)
+	if !ok {
+		log.Error().Msgf("the wave plan (%s) does not exist (nsId: %s)", planId, nsId)
+		return
+	}
+
+	for i := range plan.Waves {
+		if plan.Waves[i].Status == WaveStatusSucceeded {
+			continue
+		}
+		waveName := plan.Waves[i].Name
+
+		// Check the gate
+		plan, _ = getWavePlan(nsId, planId) // reload (e.g., approved meanwhile)
+		gateStatus, gateMsg, retryAt := checkWaveGate(nsId, plan, i)
+		if gateStatus != "" {
+			plan, _ = updateWavePlan(nsId, planId, func(p *WavePlan) error {
+				p.Waves[i].Status = gateStatus
+				p.Status = WavePlanStatusPaused
+				p.CurrentWave = waveName
+				p.Message = gateMsg
+				return nil
+			})
+			log.Info().Msgf("wave plan paused (nsId: %s, planId: %s, wave: %s): %s", nsId, planId, waveName, gateMsg)
+			reportProgress(reqId, ProgressWavePaused, WaveProgress{Wave: waveName, Status: gateStatus, Message: gateMsg})
+			if !retryAt.IsZero() {
+				scheduleWaveWindow(nsId, planId, waveName, retryAt)
+			}
+			completeWaveRequest(reqId, plan)
+			return
+		}
+
+		// Execute the wave
+		plan, _ = updateWavePlan(nsId, planId, func(p *WavePlan) error {
+			now := time.Now()
+			p.Waves[i].Status = WaveStatusRunning
+			p.Waves[i].Error = ""
+			p.Waves[i].StartedAt = &now
+			p.Waves[i].FinishedAt = nil
+			p.CurrentWave = waveName
+			p.Message = ""
+			return nil
+		})
+		log.Info().Msgf("wave started (nsId: %s, planId: %s, wave: %s)", nsId, planId, waveName)
+		reportProgress(reqId, ProgressWaveStarted, WaveProgress{Wave: waveName, Status: WaveStatusRunning})
+
+		waveErr := executeWave(nsId, plan, i, reqId)
+
+		plan, _ = updateWavePlan(nsId, planId, func(p *WavePlan) error {
+			now := time.Now()
+			p.Waves[i].FinishedAt = &now
+			if waveErr != nil {
+				p.Waves[i].Status = WaveStatusFailed
+				p.Waves[i].Error = waveErr.Error()
+				p.Status = WavePlanStatusFailed
+				p.Message = fmt.Sprintf("wave %s failed: %v", waveName, waveErr)
+				return nil
+			}
+			p.Waves[i].Status = WaveStatusSucceeded
+			return nil
+		})
+		if waveErr != nil {
+			log.Error().Err(waveErr).Msgf("wave failed (nsId: %s, planId: %s, wave: %s)", nsId, planId, waveName)
+			reportProgress(reqId, ProgressWaveFinished, WaveProgress{Wave: waveName, Status: WaveStatusFailed, Message: waveErr.Error()})
+			completeWaveRequest(reqId, plan)
+			return
+		}
+		log.Info().Msgf("wave succeeded (nsId: %s, planId: %s, wave: %s)", nsId, planId, waveName)
+		reportProgress(reqId, ProgressWaveFinished, WaveProgress{Wave: waveName, Status: WaveStatusSucceeded})
+	}
+
+	plan, _ = updateWavePlan(nsId, planId, func(p *WavePlan) error {
+		p.Status = WavePlanStatusSucceeded
+		p.CurrentWave = ""
+		p.Message = fmt.Sprintf("all %d wave(s) succeeded", len(p.Waves))
+		return nil
+	})
+	log.Info().Msgf("wave plan succeeded (nsId: %s, planId: %s)", nsId, planId)
+	completeWaveRequest(reqId, plan)
+}
+
+// WaveProgress is the progress info of a wave.
+type WaveProgress struct {
+	Wave    string `json:"wave"`
+	Step    string `json:"step,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// checkWaveGate checks the gate of the wave. It returns the wave status to pause with (empty if the gate is open),
+// the reason, and the time to retry automatically (zero if none).
+func checkWaveGate(nsId string, plan WavePlan, idx int) (string, string, time.Time) {
+	wave := plan.Waves[idx]
+
+	if wave.Gate.PreviousWaveHealthy && idx > 0 {
+		if err := checkWaveHealth(nsId, plan.Waves[idx-1]); err != nil {
+			return WaveStatusBlocked, fmt.Sprintf("the previous wave is not healthy: %v", err), time.Time{}
+		}
+	}
+
+	if wave.Gate.ManualApproval && wave.ApprovedAt == nil {
+		return WaveStatusAwaitingApproval, fmt.Sprintf("wave %s is awaiting approval", wave.Name), time.Time{}
+	}
+
+	if wave.Gate.TimeWindow != nil {
+		open, nextStart, err := wave.Gate.TimeWindow.nextOpen(time.Now())
+		if err != nil {
+			return WaveStatusBlocked, fmt.Sprintf("invalid time window: %v", err), time.Time{}
+		}
+		if !open {
+			return WaveStatusWaitingForWindow, fmt.Sprintf("wave %s is waiting for the time window (starts at %s)",
+				wave.Name, nextStart.Format(time.RFC3339)), nextStart
+		}
+	}
+
+	return "", "", time.Time{}
+}
+
+// checkWaveHealth checks that the wave succeeded and the VMs of its Infra (if any) are all running.
+func checkWaveHealth(nsId string, wave Wave) error {
+	if wave.Status != WaveStatusSucceeded {
+		return fmt.Errorf("wave %s is %s", wave.Name, wave.Status)
+	}
+	if len(wave.NodeGroups) == 0 {
+		return nil
+	}
+
+	infraInfo, err := tbclient.NewSession().ReadInfra(nsId, wave.InfraId)
+	if err != nil {
+		return fmt.Errorf("failed to read the Infra (%s) of wave %s: %w", wave.InfraId, wave.Name, err)
+	}
+	count := infraInfo.StatusCount
+	if count.CountTotal == 0 || count.CountRunning != count.CountTotal {
+		return fmt.Errorf("%d of %d VM(s) of the Infra (%s) are running (status: %s)",
+			count.CountRunning, count.CountTotal, wave.InfraId, infraInfo.Status)
+	}
+	return nil
+}
+
+// nextOpen reports whether the time window is open at the time, and the next start of the window otherwise.
+func (w WaveTimeWindow) nextOpen(now time.Time) (bool, time.Time, error) {
+	loc := time.UTC
+	if w.Timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(w.Timezone)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid timezone (%s): %w", w.Timezone, err)
+		}
+	}
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid start (%s), expected HH:MM", w.Start)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid end (%s), expected HH:MM", w.End)
+	}
+
+	t := now.In(loc)
+	cur := t.Hour()*60 + t.Minute()
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+
+	var open bool
+	if startMin <= endMin {
+		open = cur >= startMin && cur < endMin
+	} else { // crosses midnight
+		open = cur >= startMin || cur < endMin
+	}
+	if open {
+		return true, time.Time{}, nil
+	}
+
+	nextStart := time.Date(t.Year(), t.Month(), t.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+	if !nextStart.After(t) {
+		nextStart = nextStart.AddDate(0, 0, 1)
+	}
+	return false, nextStart, nil
+}
+
+// scheduleWaveWindow resumes the wave plan when the time window of the wave opens,
+// if the wave plan is still waiting for it (the timer does not survive a restart; resume the wave plan then).
+func scheduleWaveWindow(nsId, planId, waveName string, at time.Time) {
+	time.AfterFunc(time.Until(at), func() {
+		plan, ok := getWavePlan(nsId, planId)
+		if !ok || plan.Status != WavePlanStatusPaused || plan.CurrentWave != waveName {
+			return
+		}
+		if wave := plan.wave(waveName); wave == nil || wave.Status != WaveStatusWaitingForWindow {
+			return
+		}
+		if _, err := ResumeWavePlan(nsId, planId, ""); err != nil {
+			log.Error().Err(err).Msgf("failed to resume the wave plan at the time window (nsId: %s, planId: %s, wave: %s)", nsId, planId, waveName)
+		}
+	})
+	log.Info().Msgf("the wave plan will be resumed at the time window (nsId: %s, planId: %s, wave: %s, at: %s)",
+		nsId, planId, waveName, at.Format(time.RFC3339))
+}
+
+// executeWave executes the steps of the wave not succeeded yet: the Infra, the data migrations and the NLBs, in order.
+// The wave guards (e.g., the policies) are checked first.
+func executeWave(nsId string, plan WavePlan, idx int, reqId string) error {
+	wave := plan.Waves[idx]
+	dataIdx, nlbIdx := 0, 0
+
+	if err := checkWaveGuards(plan, wave); err != nil {
+		return fmt.Errorf("wave %s is not allowed: %w", wave.Name, err)
+	}
+
+	for j, step := range wave.Steps {
+		// The succeeded steps are executed only once
+		if step.Status == WaveStepSucceeded {
+			switch step.Type {
+			case WaveStepData:
+				dataIdx++
+			case WaveStepNlb:
+				nlbIdx++
+			}
+			continue
+		}
+
+		var result string
+		var err error
+		switch step.Type {
+		case WaveStepInfra:
+			result, err = executeWaveInfra(nsId, plan, wave, reqId)
+		case WaveStepData:
+			result, err = executeWaveData(wave.DataMigrations[dataIdx])
+			dataIdx++
+		case WaveStepNlb:
+			result, err = executeWaveNlb(nsId, plan, wave.Nlbs[nlbIdx])
+			nlbIdx++
+		default:
+			err = fmt.Errorf("unsupported step type (%s)", step.Type)
+		}
+
+		_, _ = updateWavePlan(nsId, plan.Id, func(p *WavePlan) error {
+			s := &p.Waves[idx].Steps[j]
+			if err != nil {
+				s.Status = WaveStepFailed
+				s.Error = err.Error()
+			} else {
+				s.Status = WaveStepSucceeded
+				s.Error = ""
+			}
+			s.Result = result
+			return nil
+		})
+		status := WaveStepSucceeded
+		message := result
+		if err != nil {
+			status = WaveStepFailed
+			message = err.Error()
+		}
+		reportProgress(reqId, ProgressWaveStepFinished, WaveProgress{
+			Wave: wave.Name, Step: step.Type + "/" + step.Id, Status: status, Message: message,
+		})
+		if err != nil {
+			return fmt.Errorf("%s step (%s) failed: %w", step.Type, step.Id, err)
+		}
+	}
+	return nil
+}
+
+// executeWaveInfra migrates the node groups of the wave as an Infra, reusing the vNets, SSH keys and
+// security groups created by the previous waves (i.e., CreateInfraWithExisting).
+func executeWaveInfra(nsId string, plan WavePlan, wave Wave, reqId string) (string, error) {
+	inWave := map[string]bool{}
+	for _, ng := range wave.NodeGroups {
+		inWave[ng] = true
+	}
+
+	waveInfra := plan.TargetInfra
+	waveInfra.TargetInfra.Name = wave.InfraId
+	waveInfra.TargetInfra.NodeGroups = []cloudmodel.CreateNodeGroupReq{}
+	for _, ng := range plan.TargetInfra.TargetInfra.NodeGroups {
+		if inWave[ng.Name] {
+			waveInfra.TargetInfra.NodeGroups = append(waveInfra.TargetInfra.NodeGroups, ng)
+		}
+	}
+
+	sshKey, err := openSshKey(plan.TargetInfra.TargetSshKey, plan.SshKeySealedBy)
+	if err != nil {
+		return "", err
+	}
+	waveInfra.TargetSshKey = sshKey
+
+	SetMigrationLabels(&waveInfra, map[string]string{common.LabelMigrationWave: plan.Id + "/" + wave.Name})
+
+	infraInfo, err := CreateInfraWithExisting(nsId, &waveInfra, reqId, plan.RollbackPolicy)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Infra %s created (status: %s)", infraInfo.Id, infraInfo.Status), nil
+}
+
+// executeWaveData executes a data migration of the wave, decrypting its credentials stored encrypted.
+func executeWaveData(dmm transx.DataMigrationModel) (string, error) {
+	dmm, err := openDataMigration(dmm)
+	if err != nil {
+		return "", err
+	}
+
+	startTime := time.Now()
+	if err := transx.MigrateData(dmm); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data migrated (%s)", time.Since(startTime).Round(time.Millisecond)), nil
+}
+
+// executeWaveNlb migrates an NLB of the wave into the Infra of the wave that migrated its node group.
+func executeWaveNlb(nsId string, plan WavePlan, nlb cloudmodel.NlbReq) (string, error) {
+	var infraId string
+	for _, wave := range plan.Waves {
+		for _, ng := range wave.NodeGroups {
+			if ng == nlb.TargetGroup.NodeGroupId {
+				infraId = wave.InfraId
+			}
+		}
+	}
+	if infraId == "" {
+		return "", fmt.Errorf("node group %s is not migrated by any wave", nlb.TargetGroup.NodeGroupId)
+	}
+
+	result, err := CreateNlbs(nsId, infraId, cloudmodel.RecommendedNlb{TargetNlbList: []cloudmodel.NlbReq{nlb}})
+	if err != nil {
+		return "", err
+	}
+	return result.Description, nil
+}
+
+// wave returns the wave of the name (nil if none).
+func (p *WavePlan) wave(name string) *Wave {
+	for i := range p.Waves {
+		if p.Waves[i].Name == name {
+			return &p.Waves[i]
+		}
+	}
+	return nil
+}
+
+// completeWaveRequest updates the status of the request executing the wave plan (no-op without the request ID).
+func completeWaveRequest(reqId string, plan WavePlan) {
+	if reqId == "" {
+		return
+	}
+	err := common.UpdateRequest(reqId, func(details *common.RequestDetails) {
+		details.EndTime = time.Now()
+		if plan.Status == WavePlanStatusFailed {
+			details.Status = common.RequestStatusError
+			details.ErrorResponse = fmt.Sprintf("Wave plan failed: %s", plan.Message)
+		} else {
+			// A paused wave plan is resumed by another request (or at the time window)
+			details.Status = common.RequestStatusSuccess
+			details.ResponseData = redactWavePlan(plan)
+		}
+	})
+	if err != nil {
+		log.Error().Err(err).Str("reqId", reqId).Msg("Failed to update request status")
+	}
+}
+
+// ============================================================================
+// Wave plan store
+// ============================================================================
+
+// updateWavePlan applies the update to the stored wave plan and stores it.
+func updateWavePlan(nsId, planId string, update func(p *WavePlan) error) (WavePlan, error) {
+	wavePlanMutex.Lock()
+	defer wavePlanMutex.Unlock()
+
+	plan, ok := getWavePlan(nsId, planId)
+	if !ok {
+		return WavePlan{}, fmt.Errorf("the wave plan (%s) does not exist", planId)
+	}
+	if err := update(&plan); err != nil {
+		return plan, err
+	}
+	putWavePlan(&plan)
+	return plan, nil
+}
+
+// putWavePlan stores the wave plan (the caller holds wavePlanMutex).
+func putWavePlan(plan *WavePlan) {
+	plan.UpdatedAt = time.Now()
+	if err := lkvstore.Put(wavePlanKeyPrefix+plan.NsId+"/"+plan.Id, *plan); err != nil {
+		log.Error().Err(err).Msgf("failed to store the wave plan (planId: %s)", plan.Id)
+	}
+}
+
+// getWavePlan returns the stored wave plan (with the credentials of the data migrations and the SSH private key encrypted).
+func getWavePlan(nsId, planId string) (WavePlan, bool) {
+	value, ok := lkvstore.Get(wavePlanKeyPrefix + nsId + "/" + planId)
+	if !ok {
+		return WavePlan{}, false
+	}
+	return convertToWavePlan(value)
+}
+
+// convertToWavePlan converts the stored value (a struct, or a map once loaded from the file) to WavePlan.
+func convertToWavePlan(value any) (WavePlan, bool) {
+	switch v := value.(type) {
+	case WavePlan:
+		return v, true
+	case map[string]any:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to marshal value to JSON")
+			return WavePlan{}, false
+		}
+
+		var plan WavePlan
+		if err := json.Unmarshal(jsonBytes, &plan); err != nil {
+			log.Error().Err(err).Msg("Failed to unmarshal JSON to WavePlan")
+			return WavePlan{}, false
+		}
+		return plan, true
+	default:
+		log.Error().Msgf("Unexpected value type: %T", value)
+		return WavePlan{}, false
+	}
+}
+
+// redactWavePlan masks the sensitive fields of the data migrations and the SSH private key of the wave plan.
+func redactWavePlan(plan WavePlan) WavePlan {
+	if plan.TargetInfra.TargetSshKey.PrivateKey != "" {
+		plan.TargetInfra.TargetSshKey.PrivateKey = secretMask
+	}
+	plan.SshKeySealedBy = ""
+
+	waves := make([]Wave, len(plan.Waves))
+	for i, wave := range plan.Waves {
+		dmms := make([]transx.DataMigrationModel, len(wave.DataMigrations))
+		for j, dmm := range wave.DataMigrations {
+			dmms[j] = maskDataMigration(dmm)
+		}
+		wave.DataMigrations = dmms
+		waves[i] = wave
+	}
+	plan.Waves = waves
+	return plan
+}
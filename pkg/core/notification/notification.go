@@ -0,0 +1,199 @@
+// Package notification delivers the events of Beetle (e.g., request status transitions, migration
+// milestones and policy violations) to the webhooks as CloudEvents, through a persisted outbox.
+package notification
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Outbound notifications
+// The events are formatted as CloudEvents (v1.0, structured mode), queued in the outbox
+// per webhook, and delivered by the dispatcher with retries (see outbox.go).
+// ============================================================================
+
+// Types of the events
+const (
+	EventTypeRequestHandling       = "org.cloud-barista.beetle.request.handling"
+	EventTypeRequestSuccess        = "org.cloud-barista.beetle.request.success"
+	EventTypeRequestError          = "org.cloud-barista.beetle.request.error"
+	EventTypeDataMigrationProgress = "org.cloud-barista.beetle.migration.data.progress"
+	EventTypeInfraMigrated         = "org.cloud-barista.beetle.migration.infra.completed"
+	EventTypePolicyViolated        = "org.cloud-barista.beetle.policy.violated"
+)
+
+// eventSource is the source (URI-reference) of the events
+const eventSource = "/beetle"
+
+// defaultMaxAttempts is the default max delivery attempts of an event per webhook
+const defaultMaxAttempts = 10
+
+// Config is the configuration of the notifications
+type Config struct {
+	WebhookUrls []string // Webhook URLs receiving the events (no notification if empty)
+	Secret      string   // Secret to sign the events with HMAC-SHA256 (unsigned if empty)
+	MaxAttempts int      // Max delivery attempts of an event per webhook (default: 10)
+	// OutboxFilePath is the file persisting the undelivered events (default: .lkvstore/notification-outbox.json)
+	OutboxFilePath string
+}
+
+// CloudEvent is an event in the CloudEvents (v1.0) JSON format
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion" example:"1.0"`
+	Id              string    `json:"id" example:"1735689600000000000-1"`
+	Source          string    `json:"source" example:"/beetle"`
+	Type            string    `json:"type" example:"org.cloud-barista.beetle.request.success"`
+	Subject         string    `json:"subject,omitempty" example:"1735689600000000000"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype" example:"application/json"`
+	Data            any       `json:"data,omitempty"`
+}
+
+// RequestEventData is the data of the request events
+type RequestEventData struct {
+	ReqId         string    `json:"reqId" example:"1735689600000000000"`
+	Status        string    `json:"status" example:"Success"`
+	Method        string    `json:"method" example:"POST"`
+	Url           string    `json:"url" example:"/beetle/migration/ns/mig01/infra"`
+	StartTime     time.Time `json:"startTime"`
+	EndTime       time.Time `json:"endTime"`
+	ErrorResponse string    `json:"errorResponse,omitempty"`
+}
+
+// ProgressEventData is the data of the progress events
+type ProgressEventData struct {
+	ReqId string `json:"reqId" example:"1735689600000000000"`
+	Title string `json:"title" example:"Data migration started"`
+	Info  any    `json:"info,omitempty"`
+}
+
+var (
+	configMutex sync.RWMutex
+	notifConfig Config
+
+	eventSeqMutex sync.Mutex
+	eventSeq      uint64
+)
+
+// Init sets the configuration, loads the outbox left before a restart, and subscribes the status transitions of the requests.
+// The requests of the read-only methods (GET, HEAD, OPTIONS) are not notified.
+func Init(config Config) {
+	urls := []string{}
+	for _, url := range config.WebhookUrls {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	config.WebhookUrls = urls
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.OutboxFilePath == "" {
+		config.OutboxFilePath = defaultOutboxFilePath
+	}
+
+	configMutex.Lock()
+	notifConfig = config
+	configMutex.Unlock()
+
+	if len(urls) == 0 {
+		log.Info().Msg("no webhook is set; the notifications are disabled")
+		return
+	}
+
+	if err := loadOutbox(config.OutboxFilePath); err != nil {
+		// Set the broken file aside, so that it is not overwritten by the next save
+		log.Error().Err(err).Msgf("failed to load the notification outbox; the file is moved to %s.broken", config.OutboxFilePath)
+		if err := os.Rename(config.OutboxFilePath, config.OutboxFilePath+".broken"); err != nil {
+			log.Error().Err(err).Msgf("failed to move the notification outbox file (%s)", config.OutboxFilePath)
+		}
+	}
+
+	common.OnRequestStatusChanged(notifyRequestStatus)
+	log.Info().Msgf("the notifications are enabled (webhooks: %d)", len(urls))
+}
+
+// Enabled reports whether any webhook is set
+func Enabled() bool {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return len(notifConfig.WebhookUrls) > 0
+}
+
+// Publish queues an event of the type to all webhooks and saves the outbox (no-op if no webhook is set).
+// The subject is the ID of the subject of the event (e.g., the request ID).
+func Publish(eventType, subject string, data any) {
+	configMutex.RLock()
+	urls := notifConfig.WebhookUrls
+	configMutex.RUnlock()
+	if len(urls) == 0 {
+		return
+	}
+
+	event := CloudEvent{
+		SpecVersion:     "1.0",
+		Id:              newEventId(),
+		Source:          eventSource,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+	for i, url := range urls {
+		enqueue(url, i, event)
+	}
+	saveOutbox()
+	wakeDispatcher()
+}
+
+// PublishProgress publishes a progress event of the request
+func PublishProgress(eventType, reqId, title string, info any) {
+	Publish(eventType, reqId, ProgressEventData{ReqId: reqId, Title: title, Info: info})
+}
+
+// notifyRequestStatus publishes the status transition of a request
+func notifyRequestStatus(reqID string, details common.RequestDetails) {
+	switch details.RequestInfo.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return
+	}
+
+	var eventType string
+	switch {
+	case strings.EqualFold(details.Status, common.RequestStatusHandling):
+		eventType = EventTypeRequestHandling
+	case strings.EqualFold(details.Status, common.RequestStatusSuccess):
+		eventType = EventTypeRequestSuccess
+	case strings.EqualFold(details.Status, common.RequestStatusError):
+		eventType = EventTypeRequestError
+	default:
+		return
+	}
+
+	Publish(eventType, reqID, RequestEventData{
+		ReqId:         reqID,
+		Status:        details.Status,
+		Method:        details.RequestInfo.Method,
+		Url:           details.RequestInfo.URL,
+		StartTime:     details.StartTime,
+		EndTime:       details.EndTime,
+		ErrorResponse: details.ErrorResponse,
+	})
+}
+
+// newEventId returns a unique ID of an event (the receivers may deduplicate the redelivered events by it)
+func newEventId() string {
+	eventSeqMutex.Lock()
+	defer eventSeqMutex.Unlock()
+	eventSeq++
+	return fmt.Sprintf("%d-%d", time.Now().UnixNano(), eventSeq)
+}
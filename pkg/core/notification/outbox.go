@@ -0,0 +1,460 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Outbox
+// Each event is queued per webhook in the outbox, which is kept in its own file (apart from
+// the lkvstore file), so the undelivered events survive restarts. The outbox file is saved
+// when an event is queued and after each delivery round, so a crash loses no queued event
+// (a delivered event may be redelivered). Only the outbox is written, not the whole lkvstore.
+// The dispatcher delivers the due entries in order per webhook, one worker per webhook
+// (a slow or dead webhook does not hold the others), and retries a failed delivery with
+// an exponential backoff up to the max attempts. The failed entries are removed after the retention.
+// The delivery is at-least-once; the receivers may deduplicate the events by their IDs.
+// ============================================================================
+
+// defaultOutboxFilePath is the default file persisting the outbox
+const defaultOutboxFilePath = ".lkvstore/notification-outbox.json"
+
+// Statuses of an outbox entry (a delivered entry is removed from the outbox)
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusFailed  = "failed" // The max attempts are exhausted
+)
+
+// Headers of the delivery
+const (
+	HeaderSignature = "X-Beetle-Signature" // "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+	HeaderTimestamp = "X-Beetle-Timestamp" // Unix time (seconds) of the delivery
+)
+
+const (
+	deliveryTimeout = 10 * time.Second
+	minRetryBackoff = 5 * time.Second
+	maxRetryBackoff = 10 * time.Minute
+
+	// failedRetention is how long a failed entry is kept (e.g., to be redelivered) before removal
+	failedRetention = 7 * 24 * time.Hour
+)
+
+// OutboxEntry is an event to deliver to a webhook
+type OutboxEntry struct {
+	Id            string     `json:"id" example:"1735689600000000000-1.0"`
+	Url           string     `json:"url" example:"https://ci.example.com/hooks/beetle"`
+	Event         CloudEvent `json:"event"`
+	Status        string     `json:"status" example:"pending"`
+	Attempts      int        `json:"attempts" example:"1"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	LastError     string     `json:"lastError,omitempty" example:"503 Service Unavailable"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	FailedAt      *time.Time `json:"failedAt,omitempty"` // When the max attempts are exhausted
+}
+
+// OutboxList is the list of the outbox entries
+type OutboxList struct {
+	Entries []OutboxEntry `json:"entries"`
+}
+
+var (
+	// outboxMutex serializes the updates of the outbox entries
+	outboxMutex   sync.Mutex
+	outboxEntries = map[string]OutboxEntry{}
+
+	// outboxSaveMutex serializes the saves of the outbox file
+	outboxSaveMutex sync.Mutex
+	outboxFilePath  = defaultOutboxFilePath
+
+	// wakeCh wakes up the dispatcher when an event is queued
+	wakeCh = make(chan struct{}, 1)
+
+	// deliveringUrls is the set of the webhooks being delivered by a worker
+	deliveringMutex sync.Mutex
+	deliveringUrls  = map[string]bool{}
+
+	httpClient = &http.Client{Timeout: deliveryTimeout}
+)
+
+// StartDispatcher starts delivering the events in the outbox, including the ones left before a restart.
+// The outbox is checked at the interval and whenever an event is queued, until the context is done.
+func StartDispatcher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		dispatch()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("stopped the notification dispatcher")
+				return
+			case <-ticker.C:
+			case <-wakeCh:
+			}
+			dispatch()
+		}
+	}()
+}
+
+// ListOutbox returns the outbox entries of the status (all if empty), oldest first
+func ListOutbox(status string) OutboxList {
+	ret := OutboxList{Entries: []OutboxEntry{}}
+	for _, entry := range listOutboxEntries() {
+		if status == "" || entry.Status == status {
+			ret.Entries = append(ret.Entries, entry)
+		}
+	}
+	return ret
+}
+
+// Redeliver resets the attempts of an outbox entry (e.g., a failed one) to deliver it again
+func Redeliver(entryId string) (OutboxEntry, error) {
+	outboxMutex.Lock()
+	entry, ok := getOutboxEntry(entryId)
+	if !ok {
+		outboxMutex.Unlock()
+		return OutboxEntry{}, fmt.Errorf("outbox entry (id: %s) not found", entryId)
+	}
+	entry.Status = OutboxStatusPending
+	entry.Attempts = 0
+	entry.NextAttemptAt = time.Now()
+	entry.FailedAt = nil
+	putOutboxEntry(entry)
+	outboxMutex.Unlock()
+
+	saveOutbox()
+	wakeDispatcher()
+	return entry, nil
+}
+
+// enqueue queues the event to the webhook
+func enqueue(url string, idx int, event CloudEvent) {
+	entry := OutboxEntry{
+		Id:            event.Id + "." + strconv.Itoa(idx),
+		Url:           url,
+		Event:         event,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: event.Time,
+		CreatedAt:     event.Time,
+	}
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+	putOutboxEntry(entry)
+}
+
+// wakeDispatcher wakes up the dispatcher (no-op if already woken up)
+func wakeDispatcher() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// loadOutbox loads the outbox entries from the file (an empty outbox if the file does not exist).
+func loadOutbox(filePath string) error {
+	outboxSaveMutex.Lock()
+	defer outboxSaveMutex.Unlock()
+	outboxFilePath = filePath
+
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the outbox file (%s): %w", filePath, err)
+	}
+
+	var list OutboxList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse the outbox file (%s): %w", filePath, err)
+	}
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+	outboxEntries = map[string]OutboxEntry{}
+	for _, entry := range list.Entries {
+		outboxEntries[entry.Id] = entry
+	}
+	return nil
+}
+
+// saveOutbox saves the outbox to its file, so the outbox survives a crash.
+// The file is replaced atomically, so a crash while saving leaves the previous file intact.
+func saveOutbox() {
+	if err := writeOutbox(); err != nil {
+		log.Warn().Err(err).Msgf("failed to save the notification outbox (%s)", outboxFilePath)
+	}
+}
+
+// writeOutbox writes the current outbox entries to the outbox file.
+func writeOutbox() error {
+	// The snapshot is taken while holding the save lock, so an older snapshot never overwrites a newer one
+	outboxSaveMutex.Lock()
+	defer outboxSaveMutex.Unlock()
+
+	data, err := json.Marshal(OutboxList{Entries: listOutboxEntries()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the outbox: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outboxFilePath), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory: %w", err)
+	}
+	tmpFilePath := outboxFilePath + ".tmp"
+	file, err := os.Create(tmpFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create the file: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(tmpFilePath)
+		return fmt.Errorf("failed to write the file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmpFilePath)
+		return fmt.Errorf("failed to sync the file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpFilePath)
+		return fmt.Errorf("failed to close the file: %w", err)
+	}
+	if err := os.Rename(tmpFilePath, outboxFilePath); err != nil {
+		return fmt.Errorf("failed to replace the file: %w", err)
+	}
+	return nil
+}
+
+// dispatch removes the expired failed entries, and starts a worker per webhook with pending entries
+// (unless one is already delivering to the webhook).
+func dispatch() {
+	pendingByUrl := map[string][]OutboxEntry{}
+	var urls []string
+	expired := 0
+	for _, entry := range listOutboxEntries() {
+		switch entry.Status {
+		case OutboxStatusPending:
+			if _, ok := pendingByUrl[entry.Url]; !ok {
+				urls = append(urls, entry.Url)
+			}
+			pendingByUrl[entry.Url] = append(pendingByUrl[entry.Url], entry)
+		case OutboxStatusFailed:
+			if removeExpiredEntry(entry) {
+				expired++
+			}
+		}
+	}
+	if expired > 0 {
+		log.Info().Msgf("removed %d failed event(s) older than %s from the outbox", expired, failedRetention)
+		saveOutbox()
+	}
+
+	for _, url := range urls {
+		deliveringMutex.Lock()
+		if deliveringUrls[url] {
+			deliveringMutex.Unlock()
+			continue
+		}
+		deliveringUrls[url] = true
+		deliveringMutex.Unlock()
+
+		go func(url string, entries []OutboxEntry) {
+			attempted := deliverInOrder(entries)
+
+			deliveringMutex.Lock()
+			delete(deliveringUrls, url)
+			deliveringMutex.Unlock()
+
+			if attempted {
+				saveOutbox()
+				// Pick up the entries queued while delivering
+				wakeDispatcher()
+			}
+		}(url, pendingByUrl[url])
+	}
+}
+
+// deliverInOrder delivers the due entries of a webhook in order, and returns whether any entry is attempted.
+// The entries behind an entry waiting for a retry are held to keep the order.
+func deliverInOrder(entries []OutboxEntry) bool {
+	attempted := false
+	for _, entry := range entries {
+		if entry.NextAttemptAt.After(time.Now()) {
+			break
+		}
+		attempted = true
+		if !deliver(entry) {
+			break
+		}
+	}
+	return attempted
+}
+
+// removeExpiredEntry removes the failed entry if it has been kept longer than the retention.
+func removeExpiredEntry(entry OutboxEntry) bool {
+	failedAt := entry.CreatedAt
+	if entry.FailedAt != nil {
+		failedAt = *entry.FailedAt
+	}
+	if time.Since(failedAt) < failedRetention {
+		return false
+	}
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+
+	// The entry may be redelivered meanwhile
+	current, ok := getOutboxEntry(entry.Id)
+	if !ok || current.Status != OutboxStatusFailed {
+		return false
+	}
+	delete(outboxEntries, entry.Id)
+	return true
+}
+
+// deliver posts the event of the entry to its webhook, and removes the entry if delivered
+// or schedules a retry otherwise. It returns whether the entry is delivered (or given up).
+func deliver(entry OutboxEntry) bool {
+	err := post(entry.Url, entry.Event)
+
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+
+	// The entry may be removed or redelivered while posting
+	current, ok := getOutboxEntry(entry.Id)
+	if !ok || current.Attempts != entry.Attempts {
+		return true
+	}
+
+	if err == nil {
+		delete(outboxEntries, entry.Id)
+		log.Debug().Msgf("delivered the event (type: %s, id: %s) to %s", entry.Event.Type, entry.Event.Id, entry.Url)
+		return true
+	}
+
+	configMutex.RLock()
+	maxAttempts := notifConfig.MaxAttempts
+	configMutex.RUnlock()
+
+	entry.Attempts++
+	entry.LastError = err.Error()
+	if entry.Attempts >= maxAttempts {
+		now := time.Now()
+		entry.Status = OutboxStatusFailed
+		entry.FailedAt = &now
+		log.Error().Err(err).Msgf("gave up delivering the event (type: %s, id: %s) to %s after %d attempt(s)",
+			entry.Event.Type, entry.Event.Id, entry.Url, entry.Attempts)
+		putOutboxEntry(entry)
+		return true
+	}
+
+	entry.NextAttemptAt = time.Now().Add(retryBackoff(entry.Attempts))
+	log.Warn().Err(err).Msgf("failed to deliver the event (type: %s, id: %s) to %s; retry at %s",
+		entry.Event.Type, entry.Event.Id, entry.Url, entry.NextAttemptAt.Format(time.RFC3339))
+	putOutboxEntry(entry)
+	return false
+}
+
+// post sends the event to the webhook in the CloudEvents structured mode, signed if the secret is set
+func post(url string, event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+
+	configMutex.RLock()
+	secret := notifConfig.Secret
+	configMutex.RUnlock()
+	if secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, "sha256="+Sign(secret, timestamp, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of the timestamp and the body ("<timestamp>.<body>").
+// The receivers verify the X-Beetle-Signature header by it.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryBackoff returns the backoff before the next attempt (5s, 10s, 20s, ... up to 10m)
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// listOutboxEntries returns the outbox entries, oldest first
+func listOutboxEntries() []OutboxEntry {
+	outboxMutex.Lock()
+	entries := make([]OutboxEntry, 0, len(outboxEntries))
+	for _, entry := range outboxEntries {
+		entries = append(entries, entry)
+	}
+	outboxMutex.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].Id < entries[j].Id
+	})
+	return entries
+}
+
+// putOutboxEntry stores the outbox entry (the caller holds outboxMutex).
+func putOutboxEntry(entry OutboxEntry) {
+	outboxEntries[entry.Id] = entry
+}
+
+// getOutboxEntry returns the outbox entry (the caller holds outboxMutex).
+func getOutboxEntry(entryId string) (OutboxEntry, bool) {
+	entry, ok := outboxEntries[entryId]
+	return entry, ok
+}
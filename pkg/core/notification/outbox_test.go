@@ -0,0 +1,350 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setupOutbox sets an empty outbox in a temporary file and the config, restored at the cleanup
+func setupOutbox(t *testing.T, config Config) {
+	t.Helper()
+
+	configMutex.Lock()
+	savedConfig := notifConfig
+	notifConfig = config
+	configMutex.Unlock()
+
+	outboxMutex.Lock()
+	savedEntries := outboxEntries
+	outboxEntries = map[string]OutboxEntry{}
+	outboxMutex.Unlock()
+
+	savedPath := outboxFilePath
+	outboxFilePath = filepath.Join(t.TempDir(), "notification-outbox.json")
+
+	t.Cleanup(func() {
+		configMutex.Lock()
+		notifConfig = savedConfig
+		configMutex.Unlock()
+
+		outboxMutex.Lock()
+		outboxEntries = savedEntries
+		outboxMutex.Unlock()
+
+		outboxFilePath = savedPath
+	})
+}
+
+// webhook is a test webhook recording the IDs of the received events
+type webhook struct {
+	mu       sync.Mutex
+	received []string
+	failIds  map[string]bool // The events answered with 503
+	headers  []http.Header
+	bodies   [][]byte
+}
+
+func (w *webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var event CloudEvent
+	_ = json.Unmarshal(body, &event)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.received = append(w.received, event.Id)
+	w.headers = append(w.headers, r.Header.Clone())
+	w.bodies = append(w.bodies, body)
+	if w.failIds[event.Id] {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func newEntry(url, id string, createdAt time.Time) OutboxEntry {
+	return OutboxEntry{
+		Id:            id,
+		Url:           url,
+		Event:         CloudEvent{SpecVersion: "1.0", Id: id, Source: eventSource, Type: EventTypeRequestSuccess, Time: createdAt},
+		Status:        OutboxStatusPending,
+		NextAttemptAt: createdAt,
+		CreatedAt:     createdAt,
+	}
+}
+
+func storeEntries(entries ...OutboxEntry) {
+	outboxMutex.Lock()
+	defer outboxMutex.Unlock()
+	for _, entry := range entries {
+		putOutboxEntry(entry)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{7, 320 * time.Second},
+		{8, 10 * time.Minute}, // 640s is capped
+		{100, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := retryBackoff(tt.attempts); got != tt.want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverInOrder(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		entries       func(url string) []OutboxEntry
+		failIds       []string
+		wantAttempted bool
+		wantReceived  []string
+		wantLeft      map[string]string // ID -> status of the entries left in the outbox
+	}{
+		{
+			name: "all delivered in order",
+			entries: func(url string) []OutboxEntry {
+				return []OutboxEntry{newEntry(url, "e1", past), newEntry(url, "e2", past.Add(time.Second)), newEntry(url, "e3", past.Add(2*time.Second))}
+			},
+			wantAttempted: true,
+			wantReceived:  []string{"e1", "e2", "e3"},
+			wantLeft:      map[string]string{},
+		},
+		{
+			name: "a failed delivery holds the next entries",
+			entries: func(url string) []OutboxEntry {
+				return []OutboxEntry{newEntry(url, "e1", past), newEntry(url, "e2", past.Add(time.Second)), newEntry(url, "e3", past.Add(2*time.Second))}
+			},
+			failIds:       []string{"e2"},
+			wantAttempted: true,
+			wantReceived:  []string{"e1", "e2"},
+			wantLeft:      map[string]string{"e2": OutboxStatusPending, "e3": OutboxStatusPending},
+		},
+		{
+			name: "an entry waiting for a retry holds the next entries",
+			entries: func(url string) []OutboxEntry {
+				first := newEntry(url, "e1", past)
+				first.NextAttemptAt = time.Now().Add(time.Minute)
+				return []OutboxEntry{first, newEntry(url, "e2", past.Add(time.Second))}
+			},
+			wantAttempted: false,
+			wantLeft:      map[string]string{"e1": OutboxStatusPending, "e2": OutboxStatusPending},
+		},
+		{
+			name: "an entry given up does not hold the next entries",
+			entries: func(url string) []OutboxEntry {
+				first := newEntry(url, "e1", past)
+				first.Attempts = 2 // The last attempt
+				return []OutboxEntry{first, newEntry(url, "e2", past.Add(time.Second))}
+			},
+			failIds:       []string{"e1"},
+			wantAttempted: true,
+			wantReceived:  []string{"e1", "e2"},
+			wantLeft:      map[string]string{"e1": OutboxStatusFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &webhook{failIds: map[string]bool{}}
+			for _, id := range tt.failIds {
+				hook.failIds[id] = true
+			}
+			server := httptest.NewServer(hook)
+			defer server.Close()
+
+			setupOutbox(t, Config{WebhookUrls: []string{server.URL}, MaxAttempts: 3})
+			entries := tt.entries(server.URL)
+			storeEntries(entries...)
+
+			if attempted := deliverInOrder(entries); attempted != tt.wantAttempted {
+				t.Errorf("attempted = %v, want %v", attempted, tt.wantAttempted)
+			}
+			if !reflect.DeepEqual(hook.received, tt.wantReceived) {
+				t.Errorf("received = %v, want %v", hook.received, tt.wantReceived)
+			}
+
+			left := map[string]string{}
+			for _, entry := range listOutboxEntries() {
+				left[entry.Id] = entry.Status
+				if tt.failIds != nil && hook.failIds[entry.Id] {
+					if entry.Attempts == 0 || entry.LastError == "" {
+						t.Errorf("the failed entry %s has no attempt recorded: %+v", entry.Id, entry)
+					}
+					if entry.Status == OutboxStatusPending && !entry.NextAttemptAt.After(time.Now()) {
+						t.Errorf("the retry of the entry %s is not scheduled: %v", entry.Id, entry.NextAttemptAt)
+					}
+					if entry.Status == OutboxStatusFailed && entry.FailedAt == nil {
+						t.Errorf("the entry %s given up has no failedAt", entry.Id)
+					}
+				}
+			}
+			if !reflect.DeepEqual(left, tt.wantLeft) {
+				t.Errorf("left = %v, want %v", left, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestRemoveExpiredEntry(t *testing.T) {
+	failedEntry := func(id string, failedAt *time.Time, createdAt time.Time) OutboxEntry {
+		entry := newEntry("http://localhost", id, createdAt)
+		entry.Status = OutboxStatusFailed
+		entry.FailedAt = failedAt
+		return entry
+	}
+	ago := func(d time.Duration) *time.Time {
+		t := time.Now().Add(-d)
+		return &t
+	}
+
+	tests := []struct {
+		name        string
+		entry       OutboxEntry
+		stored      *OutboxEntry // The stored entry if different (e.g., redelivered meanwhile)
+		wantRemoved bool
+	}{
+		{
+			name:        "failed longer than the retention",
+			entry:       failedEntry("e1", ago(failedRetention+time.Hour), *ago(failedRetention + 2*time.Hour)),
+			wantRemoved: true,
+		},
+		{
+			name:  "failed within the retention",
+			entry: failedEntry("e1", ago(time.Hour), *ago(failedRetention + time.Hour)),
+		},
+		{
+			name:        "failed without failedAt, created before the retention",
+			entry:       failedEntry("e1", nil, *ago(failedRetention + time.Hour)),
+			wantRemoved: true,
+		},
+		{
+			name:  "redelivered meanwhile",
+			entry: failedEntry("e1", ago(failedRetention+time.Hour), *ago(failedRetention + 2*time.Hour)),
+			stored: func() *OutboxEntry {
+				entry := newEntry("http://localhost", "e1", time.Now())
+				return &entry
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupOutbox(t, Config{MaxAttempts: 3})
+			if tt.stored != nil {
+				storeEntries(*tt.stored)
+			} else {
+				storeEntries(tt.entry)
+			}
+
+			if removed := removeExpiredEntry(tt.entry); removed != tt.wantRemoved {
+				t.Errorf("removeExpiredEntry() = %v, want %v", removed, tt.wantRemoved)
+			}
+			outboxMutex.Lock()
+			_, exists := getOutboxEntry(tt.entry.Id)
+			outboxMutex.Unlock()
+			if exists == tt.wantRemoved {
+				t.Errorf("the entry exists = %v after removeExpiredEntry() = %v", exists, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	const secret = "s3cret"
+	hook := &webhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	setupOutbox(t, Config{WebhookUrls: []string{server.URL}, Secret: secret, MaxAttempts: 3})
+	if err := post(server.URL, newEntry(server.URL, "e1", time.Now()).Event); err != nil {
+		t.Fatalf("post failed: %v", err)
+	}
+
+	// Verify the signature as a receiver does
+	header, body := hook.headers[0], hook.bodies[0]
+	timestamp := header.Get(HeaderTimestamp)
+	if timestamp == "" {
+		t.Fatal("no timestamp header")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := header.Get(HeaderSignature); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+	if got := header.Get("Content-Type"); !strings.HasPrefix(got, "application/cloudevents+json") {
+		t.Errorf("content type = %s", got)
+	}
+
+	// The signature changes with the body and the timestamp
+	if Sign(secret, timestamp, body) == Sign(secret, timestamp, append(body, ' ')) ||
+		Sign(secret, timestamp, body) == Sign(secret, timestamp+"0", body) {
+		t.Error("the signature does not cover the body and the timestamp")
+	}
+
+	// Unsigned without the secret
+	setupOutbox(t, Config{WebhookUrls: []string{server.URL}, MaxAttempts: 3})
+	if err := post(server.URL, newEntry(server.URL, "e2", time.Now()).Event); err != nil {
+		t.Fatalf("post failed: %v", err)
+	}
+	if got := hook.headers[1].Get(HeaderSignature); got != "" {
+		t.Errorf("signature = %s without the secret", got)
+	}
+}
+
+func TestOutboxFile(t *testing.T) {
+	setupOutbox(t, Config{MaxAttempts: 3})
+	now := time.Now().UTC().Truncate(time.Second)
+	storeEntries(newEntry("http://localhost", "e2", now.Add(time.Second)), newEntry("http://localhost", "e1", now))
+	saveOutbox()
+
+	// Reload the outbox as after a restart
+	outboxMutex.Lock()
+	outboxEntries = map[string]OutboxEntry{}
+	outboxMutex.Unlock()
+	if err := loadOutbox(outboxFilePath); err != nil {
+		t.Fatalf("loadOutbox failed: %v", err)
+	}
+	var ids []string
+	for _, entry := range listOutboxEntries() {
+		ids = append(ids, entry.Id)
+	}
+	if want := []string{"e1", "e2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("loaded = %v, want %v", ids, want)
+	}
+
+	if err := loadOutbox(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("loadOutbox() of a missing file failed: %v", err)
+	}
+
+	broken := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(broken, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadOutbox(broken); err == nil {
+		t.Error("loadOutbox() of a broken file succeeded")
+	}
+}
@@ -0,0 +1,399 @@
+package policy
+
+import (
+	"fmt"
+	"net/netip"
+	"path"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	storagemodel "github.com/cloud-barista/cm-beetle/imdl/storage-model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/cloud-barista/cm-beetle/transx"
+)
+
+// Evaluation targets
+const (
+	TargetInfra         = "infra"
+	TargetDataMigration = "dataMigration"
+	TargetObjectStorage = "objectStorage"
+)
+
+// HoursPerMonth is used to estimate the monthly cost from the hourly cost of the specs
+const HoursPerMonth = 730
+
+// Violation is a rule of a policy not satisfied by a resource
+type Violation struct {
+	PolicyId    string `json:"policyId" example:"prod-guardrails"`
+	Rule        string `json:"rule" example:"forbiddenIngress"`
+	Resource    string `json:"resource" example:"securityGroup/mig-sg-01"`
+	Message     string `json:"message" example:"inbound tcp 22 from 0.0.0.0/0 is forbidden"`
+	Enforcement string `json:"enforcement" example:"enforce"`
+}
+
+// EvaluationResult is the result of evaluating the policies against a migration model
+type EvaluationResult struct {
+	Target   string   `json:"target" example:"infra"`
+	Allowed  bool     `json:"allowed"`
+	Policies []string `json:"policies"` // IDs of the evaluated policies
+	// EstimatedMonthlyCostUsd is the estimated monthly cost of the target infrastructure (infra only)
+	EstimatedMonthlyCostUsd float64 `json:"estimatedMonthlyCostUsd,omitempty"`
+	// Violations block the migration
+	Violations []Violation `json:"violations"`
+	// Warnings are the violations of the policies in the warn mode and the rules not verifiable
+	Warnings []Violation `json:"warnings,omitempty"`
+}
+
+// Err returns the error describing the violations, or nil if the migration is allowed
+func (r EvaluationResult) Err() error {
+	if r.Allowed {
+		return nil
+	}
+
+	messages := make([]string, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		messages = append(messages, fmt.Sprintf("[%s/%s] %s: %s", v.PolicyId, v.Rule, v.Resource, v.Message))
+	}
+	return fmt.Errorf("blocked by %d policy violation(s): %s", len(r.Violations), strings.Join(messages, "; "))
+}
+
+// evaluation collects the violations of the policies
+type evaluation struct {
+	result EvaluationResult
+}
+
+func newEvaluation(target string) *evaluation {
+	return &evaluation{result: EvaluationResult{Target: target, Policies: []string{}, Violations: []Violation{}}}
+}
+
+func (e *evaluation) violate(p Policy, rule, resource, format string, args ...any) {
+	v := Violation{PolicyId: p.Id, Rule: rule, Resource: resource, Message: fmt.Sprintf(format, args...), Enforcement: p.Enforcement}
+	if p.Enforcement == EnforcementWarn {
+		e.result.Warnings = append(e.result.Warnings, v)
+		return
+	}
+	e.result.Violations = append(e.result.Violations, v)
+}
+
+func (e *evaluation) done() EvaluationResult {
+	e.result.Allowed = len(e.result.Violations) == 0
+	return e.result
+}
+
+// ============================================================================
+// Evaluation per migration model
+// ============================================================================
+
+// EvaluateInfra evaluates the policies against the recommended infrastructure.
+// The security groups are evaluated with the rules as applied by the migration (e.g., the SSH access rule added for remote management).
+func EvaluateInfra(infra cloudmodel.RecommendedInfra) EvaluationResult {
+	return evaluateInfra(infra, ListPolicies())
+}
+
+// evaluateInfra evaluates the given policies against the recommended infrastructure
+func evaluateInfra(infra cloudmodel.RecommendedInfra, policies []Policy) EvaluationResult {
+	e := newEvaluation(TargetInfra)
+
+	cost, unknownSpecs := estimateMonthlyCost(infra)
+	e.result.EstimatedMonthlyCostUsd = cost
+
+	for _, p := range policies {
+		e.result.Policies = append(e.result.Policies, p.Id)
+		infraResource := "infra/" + infra.TargetInfra.Name
+
+		checkCloud(e, p, infraResource, infra.TargetCloud.Csp, infra.TargetCloud.Region)
+
+		if p.Rules.MaxMonthlyCostUsd > 0 {
+			if cost > p.Rules.MaxMonthlyCostUsd {
+				e.violate(p, RuleMaxMonthlyCost, infraResource,
+					"the estimated monthly cost $%.2f exceeds the maximum $%.2f", cost, p.Rules.MaxMonthlyCostUsd)
+			}
+			// The cost cannot be verified without the price of the specs, so an unknown cost blocks unless allowed
+			for _, specId := range unknownSpecs {
+				if p.Rules.AllowUnknownCost {
+					e.result.Warnings = append(e.result.Warnings, Violation{
+						PolicyId: p.Id, Rule: RuleMaxMonthlyCost, Resource: "spec/" + specId,
+						Message:     "the cost of the spec is unknown and not included in the estimated monthly cost",
+						Enforcement: EnforcementWarn,
+					})
+					continue
+				}
+				e.violate(p, RuleMaxMonthlyCost, "spec/"+specId,
+					"the cost of the spec is unknown, so the maximum monthly cost cannot be verified")
+			}
+		}
+
+		for _, sg := range infra.TargetSecurityGroupList {
+			for _, rule := range migration.AppliedFirewallRules(sg) {
+				checkIngress(e, p, "securityGroup/"+sg.Name, rule)
+			}
+		}
+
+		checkTags(e, p, infraResource, infra.TargetInfra.Label)
+	}
+
+	return e.done()
+}
+
+// EvaluateDataMigration evaluates the policies against the data migration.
+// Only the encryption in transit applies to the data migration.
+func EvaluateDataMigration(dataModel transx.DataMigrationModel) EvaluationResult {
+	return evaluateDataMigration(dataModel, ListPolicies())
+}
+
+// evaluateDataMigration evaluates the given policies against the data migration
+func evaluateDataMigration(dataModel transx.DataMigrationModel, policies []Policy) EvaluationResult {
+	e := newEvaluation(TargetDataMigration)
+
+	for _, p := range policies {
+		e.result.Policies = append(e.result.Policies, p.Id)
+
+		if p.Rules.EncryptionRequired {
+			checkTransport(e, p, "source", dataModel.Source)
+			checkTransport(e, p, "destination", dataModel.Destination)
+		}
+	}
+
+	return e.done()
+}
+
+// EvaluateObjectStorage evaluates the policies against the recommended object storage.
+func EvaluateObjectStorage(objectStorage storagemodel.RecommendedObjectStorage) EvaluationResult {
+	return evaluateObjectStorage(objectStorage, ListPolicies())
+}
+
+// evaluateObjectStorage evaluates the given policies against the recommended object storage
+func evaluateObjectStorage(objectStorage storagemodel.RecommendedObjectStorage, policies []Policy) EvaluationResult {
+	e := newEvaluation(TargetObjectStorage)
+
+	for _, p := range policies {
+		e.result.Policies = append(e.result.Policies, p.Id)
+
+		checkCloud(e, p, "objectStorage", objectStorage.TargetCloud.Csp, objectStorage.TargetCloud.Region)
+
+		for _, bucket := range objectStorage.TargetObjectStorages {
+			resource := "bucket/" + bucket.BucketName
+			if p.Rules.EncryptionRequired && !bucket.EncryptionEnabled {
+				e.violate(p, RuleEncryptionRequired, resource, "server-side encryption must be enabled")
+			}
+			checkTags(e, p, resource, bucket.Tags)
+		}
+	}
+
+	return e.done()
+}
+
+// EvaluateWave evaluates the policies against the target infra and the data migrations of a wave plan
+// right before the wave is executed, so that the policies changed after the wave plan was created are enforced.
+// It is registered as a wave guard (see migration.OnWaveExecuting).
+func EvaluateWave(plan migration.WavePlan, wave migration.Wave) error {
+	return evaluateWave(plan, wave, ListPolicies())
+}
+
+// evaluateWave evaluates the given policies against the wave and returns the violations as an error
+func evaluateWave(plan migration.WavePlan, wave migration.Wave, policies []Policy) error {
+	results := []EvaluationResult{evaluateInfra(plan.TargetInfra, policies)}
+	// The credentials of the stored data migrations are encrypted, but the transport settings are not
+	for _, dmm := range wave.DataMigrations {
+		results = append(results, evaluateDataMigration(dmm, policies))
+	}
+	for _, result := range results {
+		if err := result.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// Rule checks
+// ============================================================================
+
+// checkCloud checks the allowed CSPs and regions
+func checkCloud(e *evaluation, p Policy, resource, csp, region string) {
+	csp = strings.ToLower(csp)
+	region = strings.ToLower(region)
+
+	if len(p.Rules.AllowedCsps) > 0 {
+		allowed := false
+		for _, allowedCsp := range p.Rules.AllowedCsps {
+			if strings.EqualFold(allowedCsp, csp) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			e.violate(p, RuleAllowedCsps, resource, "CSP %q is not allowed (allowed: %s)", csp, strings.Join(p.Rules.AllowedCsps, ", "))
+		}
+	}
+
+	if len(p.Rules.AllowedRegions) > 0 {
+		allowed := false
+		for _, allowedRegion := range p.Rules.AllowedRegions {
+			pattern := strings.ToLower(allowedRegion)
+			if allowedCsp, r, found := strings.Cut(pattern, ":"); found {
+				if allowedCsp != csp {
+					continue
+				}
+				pattern = r
+			}
+			if matched, _ := path.Match(pattern, region); matched {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			e.violate(p, RuleAllowedRegions, resource, "region %q of %s is not allowed (allowed: %s)", region, csp, strings.Join(p.Rules.AllowedRegions, ", "))
+		}
+	}
+}
+
+// checkIngress checks the firewall rule against the forbidden ingress.
+// An inbound rule whose CIDR or ports cannot be parsed is a violation, since it cannot be verified.
+func checkIngress(e *evaluation, p Policy, resource string, rule cloudmodel.FirewallRuleReq) {
+	if len(p.Rules.ForbiddenIngress) == 0 || !strings.EqualFold(strings.TrimSpace(rule.Direction), "inbound") {
+		return
+	}
+
+	cidr := strings.TrimSpace(rule.CIDR)
+	if cidr == "" {
+		cidr = "0.0.0.0/0"
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		e.violate(p, RuleForbiddenIngress, resource, "the inbound rule cannot be verified: invalid CIDR %q", rule.CIDR)
+		return
+	}
+	prefix = prefix.Masked()
+
+	protocol := strings.ToLower(strings.TrimSpace(rule.Protocol))
+	allProtocols := protocol == "all" || protocol == "-1" || protocol == "*"
+	var ports []portRange
+	if !allProtocols && protocol != "icmp" {
+		ports, err = parsePorts(rule.Ports)
+		if err != nil {
+			e.violate(p, RuleForbiddenIngress, resource, "the inbound rule cannot be verified: %v", err)
+			return
+		}
+	}
+
+	for _, forbidden := range p.Rules.ForbiddenIngress {
+		if !containsAnyPrefix(prefix, forbidden.cidrs) {
+			continue
+		}
+		if !allProtocols && len(forbidden.Protocols) > 0 && !contains(forbidden.Protocols, protocol) {
+			continue
+		}
+		// ICMP has no port, so it is forbidden only if all ports are forbidden
+		if len(forbidden.ports) > 0 && !allProtocols && (protocol == "icmp" || !overlaps(ports, forbidden.ports)) {
+			continue
+		}
+
+		e.violate(p, RuleForbiddenIngress, resource, "inbound %s %s from %s is forbidden (cidrs: %s, ports: %s)",
+			protocol, rule.Ports, cidr, strings.Join(forbidden.Cidrs, ", "), strings.Join(forbidden.Ports, ", "))
+		return
+	}
+}
+
+// checkTags checks the required tags (labels)
+func checkTags(e *evaluation, p Policy, resource string, tags map[string]string) {
+	var missing []string
+	for _, key := range p.Rules.RequiredTags {
+		if strings.TrimSpace(tags[key]) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		e.violate(p, RuleRequiredTags, resource, "required tags are missing: %s", strings.Join(missing, ", "))
+	}
+}
+
+// checkTransport checks that the data location is accessed with encryption in transit
+func checkTransport(e *evaluation, p Policy, role string, location transx.DataLocation) {
+	resource := role + "/" + location.Path
+
+	switch location.StorageType {
+	case transx.StorageTypeObjectStorage:
+		access := location.ObjectStorage
+		if access == nil {
+			return
+		}
+		switch {
+		case access.Minio != nil && !access.Minio.UseSSL:
+			e.violate(p, RuleEncryptionRequired, resource, "the object storage must be accessed with SSL (useSSL)")
+		case access.Spider != nil && !isHttps(access.Spider.Endpoint):
+			e.violate(p, RuleEncryptionRequired, resource, "the CB-Spider endpoint must use HTTPS (%s)", access.Spider.Endpoint)
+		case access.Tumblebug != nil && !isHttps(access.Tumblebug.Endpoint):
+			e.violate(p, RuleEncryptionRequired, resource, "the CB-Tumblebug endpoint must use HTTPS (%s)", access.Tumblebug.Endpoint)
+		}
+	default:
+		// A remote filesystem is accessed via SSH, and a local filesystem has no transport
+	}
+}
+
+// estimateMonthlyCost estimates the monthly cost of the node groups by the hourly cost of their specs,
+// and returns the IDs of the specs whose cost is unknown.
+func estimateMonthlyCost(infra cloudmodel.RecommendedInfra) (float64, []string) {
+	costPerHour := map[string]float64{}
+	for _, spec := range infra.TargetSpecList {
+		costPerHour[spec.Id] = float64(spec.CostPerHour)
+	}
+
+	total := 0.0
+	var unknown []string
+	seen := map[string]bool{}
+	for _, nodeGroup := range infra.TargetInfra.NodeGroups {
+		size := nodeGroup.NodeGroupSize
+		if size < 1 {
+			size = 1
+		}
+		cost, ok := costPerHour[nodeGroup.SpecId]
+		if !ok || cost <= 0 {
+			if !seen[nodeGroup.SpecId] {
+				seen[nodeGroup.SpecId] = true
+				unknown = append(unknown, nodeGroup.SpecId)
+			}
+			continue
+		}
+		total += cost * float64(size) * HoursPerMonth
+	}
+	return total, unknown
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// containsAnyPrefix returns true if the prefix includes all addresses of one of the prefixes
+func containsAnyPrefix(prefix netip.Prefix, prefixes []netip.Prefix) bool {
+	for _, p := range prefixes {
+		if p.Addr().Is4() == prefix.Addr().Is4() && prefix.Bits() <= p.Bits() && prefix.Contains(p.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(a, b []portRange) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.from <= y.to && y.from <= x.to {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isHttps(endpoint string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(endpoint)), "https://")
+}
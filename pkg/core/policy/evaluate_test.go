@@ -0,0 +1,399 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	storagemodel "github.com/cloud-barista/cm-beetle/imdl/storage-model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/migration"
+	"github.com/cloud-barista/cm-beetle/transx"
+)
+
+func mustParsePolicy(t *testing.T, data string) Policy {
+	t.Helper()
+	p, err := ParsePolicy("policy.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("ParsePolicy failed: %v", err)
+	}
+	return p
+}
+
+// summarize returns the violations as "rule resource" for the comparison
+func summarize(violations []Violation) []string {
+	var out []string
+	for _, v := range violations {
+		out = append(out, v.Rule+" "+v.Resource)
+	}
+	return out
+}
+
+func testInfra(csp, region string) cloudmodel.RecommendedInfra {
+	return cloudmodel.RecommendedInfra{
+		TargetCloud: cloudmodel.CloudProperty{Csp: csp, Region: region},
+		TargetInfra: cloudmodel.InfraReq{
+			Name:       "infra01",
+			Label:      map[string]string{"owner": "ops"},
+			NodeGroups: []cloudmodel.CreateNodeGroupReq{{Name: "g1", SpecId: "spec-a", NodeGroupSize: 1}},
+		},
+		TargetSpecList: []cloudmodel.SpecInfo{{Id: "spec-a", CostPerHour: 0.5}},
+	}
+}
+
+func TestEvaluateInfra(t *testing.T) {
+	withSecurityGroup := func(infra cloudmodel.RecommendedInfra, rules ...cloudmodel.FirewallRuleReq) cloudmodel.RecommendedInfra {
+		infra.TargetSecurityGroupList = []cloudmodel.SecurityGroupReq{{Name: "sg-01", FirewallRules: &rules}}
+		return infra
+	}
+	inbound := func(protocol, ports, cidr string) cloudmodel.FirewallRuleReq {
+		return cloudmodel.FirewallRuleReq{Direction: "inbound", Protocol: protocol, Ports: ports, CIDR: cidr}
+	}
+	const forbidRdp = `version: v1
+id: p1
+rules:
+  forbiddenIngress:
+    - cidrs: ["0.0.0.0/0", "::/0"]
+      ports: ["3389"]
+      protocols: [tcp]
+`
+
+	tests := []struct {
+		name           string
+		policy         string
+		infra          cloudmodel.RecommendedInfra
+		wantViolations []string
+		wantWarnings   []string
+	}{
+		// Cloud
+		{
+			name:   "allowed CSP and region",
+			policy: "version: v1\nid: p1\nrules:\n  allowedCsps: [AWS, azure]\n  allowedRegions: [ap-northeast-*]\n",
+			infra:  testInfra("aws", "ap-northeast-2"),
+		},
+		{
+			name:           "CSP not allowed",
+			policy:         "version: v1\nid: p1\nrules:\n  allowedCsps: [azure]\n",
+			infra:          testInfra("aws", "ap-northeast-2"),
+			wantViolations: []string{"allowedCsps infra/infra01"},
+		},
+		{
+			name:           "region qualified by another CSP",
+			policy:         "version: v1\nid: p1\nrules:\n  allowedRegions: [azure:ap-northeast-2]\n",
+			infra:          testInfra("aws", "ap-northeast-2"),
+			wantViolations: []string{"allowedRegions infra/infra01"},
+		},
+		{
+			name:   "region qualified by the CSP",
+			policy: "version: v1\nid: p1\nrules:\n  allowedRegions: [aws:ap-northeast-2]\n",
+			infra:  testInfra("AWS", "AP-NORTHEAST-2"),
+		},
+		// Cost
+		{
+			name:   "cost under the maximum",
+			policy: "version: v1\nid: p1\nrules:\n  maxMonthlyCostUsd: 1000\n",
+			infra:  testInfra("aws", "ap-northeast-2"),
+		},
+		{
+			name:   "cost over the maximum",
+			policy: "version: v1\nid: p1\nrules:\n  maxMonthlyCostUsd: 500\n",
+			infra: func() cloudmodel.RecommendedInfra {
+				infra := testInfra("aws", "ap-northeast-2")
+				infra.TargetInfra.NodeGroups[0].NodeGroupSize = 2
+				return infra
+			}(),
+			wantViolations: []string{"maxMonthlyCostUsd infra/infra01"},
+		},
+		{
+			name:   "unknown cost",
+			policy: "version: v1\nid: p1\nrules:\n  maxMonthlyCostUsd: 1000\n",
+			infra: func() cloudmodel.RecommendedInfra {
+				infra := testInfra("aws", "ap-northeast-2")
+				infra.TargetInfra.NodeGroups = append(infra.TargetInfra.NodeGroups,
+					cloudmodel.CreateNodeGroupReq{Name: "g2", SpecId: "spec-b"},
+					cloudmodel.CreateNodeGroupReq{Name: "g3", SpecId: "spec-b"})
+				return infra
+			}(),
+			wantViolations: []string{"maxMonthlyCostUsd spec/spec-b"},
+		},
+		{
+			name:   "unknown cost allowed",
+			policy: "version: v1\nid: p1\nrules:\n  maxMonthlyCostUsd: 1000\n  allowUnknownCost: true\n",
+			infra: func() cloudmodel.RecommendedInfra {
+				infra := testInfra("aws", "ap-northeast-2")
+				infra.TargetSpecList[0].CostPerHour = 0
+				return infra
+			}(),
+			wantWarnings: []string{"maxMonthlyCostUsd spec/spec-a"},
+		},
+		{
+			name:   "unknown cost without the maximum",
+			policy: "version: v1\nid: p1\nrules:\n  allowedCsps: [aws]\n",
+			infra: func() cloudmodel.RecommendedInfra {
+				infra := testInfra("aws", "ap-northeast-2")
+				infra.TargetSpecList = nil
+				return infra
+			}(),
+		},
+		// Ingress (the SSH access rule from 0.0.0.0/0 is added to the security groups by the migration)
+		{
+			name:           "forbidden port open to the world",
+			policy:         forbidRdp,
+			infra:          withSecurityGroup(testInfra("aws", "ap-northeast-2"), inbound("tcp", "3389", "0.0.0.0/0")),
+			wantViolations: []string{"forbiddenIngress securityGroup/sg-01"},
+		},
+		{
+			name:           "forbidden port in a port range",
+			policy:         forbidRdp,
+			infra:          withSecurityGroup(testInfra("aws", "ap-northeast-2"), inbound("TCP", "80,3000-4000", "")),
+			wantViolations: []string{"forbiddenIngress securityGroup/sg-01"},
+		},
+		{
+			name:           "forbidden port with all protocols",
+			policy:         forbidRdp,
+			infra:          withSecurityGroup(testInfra("aws", "ap-northeast-2"), inbound("-1", "", "0.0.0.0/0")),
+			wantViolations: []string{"forbiddenIngress securityGroup/sg-01"},
+		},
+		{
+			name:           "forbidden port open to the IPv6 world",
+			policy:         forbidRdp,
+			infra:          withSecurityGroup(testInfra("aws", "ap-northeast-2"), inbound("tcp", "3389", "::/0")),
+			wantViolations: []string{"forbiddenIngress securityGroup/sg-01"},
+		},
+		{
+			name:   "allowed ingress",
+			policy: forbidRdp,
+			infra: withSecurityGroup(testInfra("aws", "ap-northeast-2"),
+				inbound("tcp", "3389", "10.0.0.0/8"),
+				inbound("udp", "3389", "0.0.0.0/0"),
+				inbound("icmp", "", "0.0.0.0/0"),
+				inbound("tcp", "443", "0.0.0.0/0"),
+				cloudmodel.FirewallRuleReq{Direction: "outbound", Protocol: "tcp", Ports: "3389", CIDR: "0.0.0.0/0"},
+			),
+		},
+		{
+			name:   "unparsable inbound rules",
+			policy: forbidRdp,
+			infra: withSecurityGroup(testInfra("aws", "ap-northeast-2"),
+				inbound("tcp", "443", "0.0.0.0 /0"),
+				inbound("tcp", "22,abc", "10.0.0.0/8"),
+				inbound("tcp", "70000", "10.0.0.0/8"),
+			),
+			wantViolations: []string{
+				"forbiddenIngress securityGroup/sg-01",
+				"forbiddenIngress securityGroup/sg-01",
+				"forbiddenIngress securityGroup/sg-01",
+			},
+		},
+		{
+			name:   "unparsable rules without forbidden ingress",
+			policy: "version: v1\nid: p1\nrules:\n  allowedCsps: [aws]\n",
+			infra:  withSecurityGroup(testInfra("aws", "ap-northeast-2"), inbound("tcp", "abc", "invalid")),
+		},
+		{
+			name:           "SSH access rule added by the migration",
+			policy:         "version: v1\nid: p1\nrules:\n  forbiddenIngress:\n    - cidrs: [0.0.0.0/0]\n      ports: [\"22\"]\n",
+			infra:          withSecurityGroup(testInfra("aws", "ap-northeast-2")),
+			wantViolations: []string{"forbiddenIngress securityGroup/sg-01"},
+		},
+		// Tags
+		{
+			name:           "required tags missing",
+			policy:         "version: v1\nid: p1\nrules:\n  requiredTags: [owner, cost-center]\n",
+			infra:          testInfra("aws", "ap-northeast-2"),
+			wantViolations: []string{"requiredTags infra/infra01"},
+		},
+		{
+			name:   "required tags present",
+			policy: "version: v1\nid: p1\nrules:\n  requiredTags: [owner]\n",
+			infra:  testInfra("aws", "ap-northeast-2"),
+		},
+		// Enforcement
+		{
+			name:         "warn enforcement",
+			policy:       "version: v1\nid: p1\nenforcement: warn\nrules:\n  allowedCsps: [azure]\n  maxMonthlyCostUsd: 100\n",
+			infra:        testInfra("aws", "ap-northeast-2"),
+			wantWarnings: []string{"allowedCsps infra/infra01", "maxMonthlyCostUsd infra/infra01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evaluateInfra(tt.infra, []Policy{mustParsePolicy(t, tt.policy)})
+			if got := summarize(result.Violations); !reflect.DeepEqual(got, tt.wantViolations) {
+				t.Errorf("violations = %v, want %v", got, tt.wantViolations)
+			}
+			if got := summarize(result.Warnings); !reflect.DeepEqual(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+			}
+			if result.Allowed != (len(tt.wantViolations) == 0) {
+				t.Errorf("allowed = %v with violations %v", result.Allowed, result.Violations)
+			}
+			if allowed := result.Err() == nil; allowed != result.Allowed {
+				t.Errorf("Err() = %v, allowed = %v", result.Err(), result.Allowed)
+			}
+		})
+	}
+}
+
+func TestEstimateMonthlyCost(t *testing.T) {
+	infra := testInfra("aws", "ap-northeast-2")
+	infra.TargetInfra.NodeGroups = append(infra.TargetInfra.NodeGroups,
+		cloudmodel.CreateNodeGroupReq{Name: "g2", SpecId: "spec-a", NodeGroupSize: 3},
+		cloudmodel.CreateNodeGroupReq{Name: "g3", SpecId: "spec-a"}, // Counted as 1 node
+		cloudmodel.CreateNodeGroupReq{Name: "g4", SpecId: "spec-b"},
+	)
+
+	cost, unknown := estimateMonthlyCost(infra)
+	if want := 0.5 * 5 * HoursPerMonth; cost != want {
+		t.Errorf("cost = %v, want %v", cost, want)
+	}
+	if want := []string{"spec-b"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("unknown specs = %v, want %v", unknown, want)
+	}
+}
+
+func TestEvaluateDataMigration(t *testing.T) {
+	objectStorage := func(access transx.ObjectStorageAccess) transx.DataLocation {
+		return transx.DataLocation{StorageType: transx.StorageTypeObjectStorage, Path: "bucket", ObjectStorage: &access}
+	}
+	const encryptionRequired = "version: v1\nid: p1\nrules:\n  encryptionRequired: true\n"
+
+	tests := []struct {
+		name           string
+		policy         string
+		model          transx.DataMigrationModel
+		wantViolations []string
+	}{
+		{
+			name:   "SSL and HTTPS",
+			policy: encryptionRequired,
+			model: transx.DataMigrationModel{
+				Source:      objectStorage(transx.ObjectStorageAccess{Minio: &transx.S3MinioConfig{UseSSL: true}}),
+				Destination: objectStorage(transx.ObjectStorageAccess{Tumblebug: &transx.TumblebugConfig{Endpoint: "HTTPS://tb.example.com/tumblebug"}}),
+			},
+		},
+		{
+			name:   "plaintext object storage",
+			policy: encryptionRequired,
+			model: transx.DataMigrationModel{
+				Source:      objectStorage(transx.ObjectStorageAccess{Minio: &transx.S3MinioConfig{}}),
+				Destination: objectStorage(transx.ObjectStorageAccess{Spider: &transx.SpiderConfig{Endpoint: "http://localhost:1024/spider"}}),
+			},
+			wantViolations: []string{"encryptionRequired source/bucket", "encryptionRequired destination/bucket"},
+		},
+		{
+			name:   "filesystem",
+			policy: encryptionRequired,
+			model: transx.DataMigrationModel{
+				Source:      transx.DataLocation{StorageType: transx.StorageTypeFilesystem, Path: "/data"},
+				Destination: transx.DataLocation{StorageType: transx.StorageTypeFilesystem, Path: "/data"},
+			},
+		},
+		{
+			name:   "encryption not required",
+			policy: "version: v1\nid: p1\nrules:\n  allowedCsps: [aws]\n",
+			model: transx.DataMigrationModel{
+				Source: objectStorage(transx.ObjectStorageAccess{Minio: &transx.S3MinioConfig{}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evaluateDataMigration(tt.model, []Policy{mustParsePolicy(t, tt.policy)})
+			if got := summarize(result.Violations); !reflect.DeepEqual(got, tt.wantViolations) {
+				t.Errorf("violations = %v, want %v", got, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestEvaluateObjectStorage(t *testing.T) {
+	model := storagemodel.RecommendedObjectStorage{
+		TargetCloud: storagemodel.CloudProperty{Csp: "aws", Region: "ap-northeast-2"},
+		TargetObjectStorages: []storagemodel.TargetObjectStorage{
+			{BucketName: "b1", BucketSpecProperty: storagemodel.BucketSpecProperty{EncryptionEnabled: true, Tags: map[string]string{"owner": "ops"}}},
+			{BucketName: "b2"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		policy         string
+		wantViolations []string
+	}{
+		{
+			name:           "encryption at rest",
+			policy:         "version: v1\nid: p1\nrules:\n  encryptionRequired: true\n",
+			wantViolations: []string{"encryptionRequired bucket/b2"},
+		},
+		{
+			name:           "bucket tags",
+			policy:         "version: v1\nid: p1\nrules:\n  requiredTags: [owner]\n",
+			wantViolations: []string{"requiredTags bucket/b2"},
+		},
+		{
+			name:           "target cloud",
+			policy:         "version: v1\nid: p1\nrules:\n  allowedCsps: [gcp]\n  allowedRegions: [ap-northeast-2]\n",
+			wantViolations: []string{"allowedCsps objectStorage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evaluateObjectStorage(model, []Policy{mustParsePolicy(t, tt.policy)})
+			if got := summarize(result.Violations); !reflect.DeepEqual(got, tt.wantViolations) {
+				t.Errorf("violations = %v, want %v", got, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestEvaluateWave(t *testing.T) {
+	plainMinio := transx.DataLocation{
+		StorageType:   transx.StorageTypeObjectStorage,
+		Path:          "bucket",
+		ObjectStorage: &transx.ObjectStorageAccess{Minio: &transx.S3MinioConfig{}},
+	}
+	plan := migration.WavePlan{TargetInfra: testInfra("aws", "ap-northeast-2")}
+
+	tests := []struct {
+		name    string
+		policy  string
+		wave    migration.Wave
+		wantErr bool
+	}{
+		{
+			name:   "allowed",
+			policy: "version: v1\nid: p1\nrules:\n  allowedCsps: [aws]\n  encryptionRequired: true\n",
+			wave:   migration.Wave{WaveReq: migration.WaveReq{Name: "db"}},
+		},
+		{
+			name:    "target infra not allowed",
+			policy:  "version: v1\nid: p1\nrules:\n  allowedCsps: [azure]\n",
+			wave:    migration.Wave{WaveReq: migration.WaveReq{Name: "db"}},
+			wantErr: true,
+		},
+		{
+			name:   "data migration not allowed",
+			policy: "version: v1\nid: p1\nrules:\n  encryptionRequired: true\n",
+			wave: migration.Wave{WaveReq: migration.WaveReq{Name: "db",
+				DataMigrations: []transx.DataMigrationModel{{Source: plainMinio, Destination: plainMinio}}}},
+			wantErr: true,
+		},
+		{
+			name:   "violations as warnings",
+			policy: "version: v1\nid: p1\nenforcement: warn\nrules:\n  allowedCsps: [azure]\n",
+			wave:   migration.Wave{WaveReq: migration.WaveReq{Name: "db"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := evaluateWave(plan, tt.wave, []Policy{mustParsePolicy(t, tt.policy)})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("evaluateWave() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Package policy provides the guardrails evaluated before a migration executes.
+// Policies are expressed as versioned data (YAML/JSON) and loaded from a directory at startup.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyVersionV1 is the policy schema version supported by the engine
+const PolicyVersionV1 = "v1"
+
+// Enforcement modes
+const (
+	EnforcementEnforce = "enforce" // Violations block the migration (default)
+	EnforcementWarn    = "warn"    // Violations are reported as warnings only
+)
+
+// Rule names, reported in the violations
+const (
+	RuleAllowedCsps        = "allowedCsps"
+	RuleAllowedRegions     = "allowedRegions"
+	RuleMaxMonthlyCost     = "maxMonthlyCostUsd"
+	RuleForbiddenIngress   = "forbiddenIngress"
+	RuleRequiredTags       = "requiredTags"
+	RuleEncryptionRequired = "encryptionRequired"
+)
+
+// Policy is a versioned set of guardrails
+type Policy struct {
+	Version     string `json:"version" yaml:"version"`
+	Id          string `json:"id" yaml:"id"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Enforcement string `json:"enforcement,omitempty" yaml:"enforcement,omitempty"` // enforce (default) | warn
+	Rules       Rules  `json:"rules" yaml:"rules"`
+}
+
+// Rules are the guardrails of a policy. An empty rule is not evaluated.
+type Rules struct {
+	// AllowedCsps are the CSPs allowed as the migration target (e.g., aws, azure)
+	AllowedCsps []string `json:"allowedCsps,omitempty" yaml:"allowedCsps,omitempty"`
+	// AllowedRegions are the regions allowed as the migration target.
+	// A region may be qualified by the CSP (e.g., aws:ap-northeast-2) and may have wildcards (e.g., ap-northeast-*).
+	AllowedRegions []string `json:"allowedRegions,omitempty" yaml:"allowedRegions,omitempty"`
+	// MaxMonthlyCostUsd is the maximum estimated monthly cost (USD) of the target infrastructure
+	MaxMonthlyCostUsd float64 `json:"maxMonthlyCostUsd,omitempty" yaml:"maxMonthlyCostUsd,omitempty"`
+	// AllowUnknownCost reports the specs of unknown cost as warnings instead of violations of the maximum monthly cost
+	AllowUnknownCost bool `json:"allowUnknownCost,omitempty" yaml:"allowUnknownCost,omitempty"`
+	// ForbiddenIngress are the inbound firewall rules not allowed in the security groups
+	ForbiddenIngress []ForbiddenIngress `json:"forbiddenIngress,omitempty" yaml:"forbiddenIngress,omitempty"`
+	// RequiredTags are the tag (label) keys the target resources must have with a value
+	RequiredTags []string `json:"requiredTags,omitempty" yaml:"requiredTags,omitempty"`
+	// EncryptionRequired requires the encryption of the buckets at rest and of the data migration in transit
+	EncryptionRequired bool `json:"encryptionRequired,omitempty" yaml:"encryptionRequired,omitempty"`
+}
+
+// ForbiddenIngress matches an inbound firewall rule allowing all of one of the CIDRs
+// on one of the ports with one of the protocols.
+type ForbiddenIngress struct {
+	Cidrs     []string `json:"cidrs" yaml:"cidrs"`                             // e.g., 0.0.0.0/0, ::/0
+	Ports     []string `json:"ports,omitempty" yaml:"ports,omitempty"`         // e.g., 22, 3389, 1-1024 (all ports if empty)
+	Protocols []string `json:"protocols,omitempty" yaml:"protocols,omitempty"` // tcp, udp, icmp (all protocols if empty)
+
+	cidrs []netip.Prefix
+	ports []portRange
+}
+
+// portRange is an inclusive range of ports
+type portRange struct {
+	from, to int
+}
+
+var (
+	policiesMu sync.RWMutex
+	policies   = map[string]Policy{}
+)
+
+// RegisterPolicy registers (or replaces) the (validated) policy
+func RegisterPolicy(policy Policy) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	policies[policy.Id] = policy
+}
+
+// ListPolicies returns the registered policies sorted by ID
+func ListPolicies() []Policy {
+	policiesMu.RLock()
+	defer policiesMu.RUnlock()
+
+	list := make([]Policy, 0, len(policies))
+	for _, policy := range policies {
+		list = append(list, policy)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	return list
+}
+
+// LoadPoliciesFromDir loads policies (*.yaml, *.yml, *.json) from a directory and registers them.
+// A policy replaces the registered one with the same ID.
+// All policies are parsed first, and none is registered if any of them is invalid.
+func LoadPoliciesFromDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the policy directory (%s): %w", dir, err)
+	}
+
+	var parsed []Policy
+	var paths []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the policy (%s): %w", path, err)
+		}
+		policy, err := ParsePolicy(entry.Name(), data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy (%s): %w", path, err)
+		}
+		parsed = append(parsed, policy)
+		paths = append(paths, path)
+	}
+
+	var loaded []string
+	for i, policy := range parsed {
+		RegisterPolicy(policy)
+		loaded = append(loaded, policy.Id)
+		log.Info().Msgf("loaded migration policy %s (version: %s, enforcement: %s, file: %s)",
+			policy.Id, policy.Version, policy.Enforcement, paths[i])
+	}
+
+	sort.Strings(loaded)
+	return loaded, nil
+}
+
+// ParsePolicy parses a policy in YAML or JSON (by the file extension) and validates it
+func ParsePolicy(fileName string, data []byte) (Policy, error) {
+	var policy Policy
+
+	var err error
+	if strings.EqualFold(filepath.Ext(fileName), ".json") {
+		err = json.Unmarshal(data, &policy)
+	} else {
+		err = yaml.Unmarshal(data, &policy)
+	}
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to parse: %w", err)
+	}
+
+	if err := policy.compile(); err != nil {
+		return Policy{}, err
+	}
+	return policy, nil
+}
+
+// compile validates the policy and prepares the rules for the evaluation
+func (p *Policy) compile() error {
+	if p.Version != PolicyVersionV1 {
+		return fmt.Errorf("unsupported policy version: %q (supported: %s)", p.Version, PolicyVersionV1)
+	}
+	if p.Id == "" {
+		return fmt.Errorf("id is required")
+	}
+
+	switch p.Enforcement {
+	case "":
+		p.Enforcement = EnforcementEnforce
+	case EnforcementEnforce, EnforcementWarn:
+	default:
+		return fmt.Errorf("unsupported enforcement: %q (supported: %s, %s)", p.Enforcement, EnforcementEnforce, EnforcementWarn)
+	}
+
+	if p.Rules.MaxMonthlyCostUsd < 0 {
+		return fmt.Errorf("maxMonthlyCostUsd must not be negative")
+	}
+
+	for i := range p.Rules.ForbiddenIngress {
+		ingress := &p.Rules.ForbiddenIngress[i]
+		if len(ingress.Cidrs) == 0 {
+			return fmt.Errorf("forbiddenIngress[%d]: cidrs are required", i)
+		}
+		ingress.cidrs = nil
+		for _, cidr := range ingress.Cidrs {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+			if err != nil {
+				return fmt.Errorf("forbiddenIngress[%d]: invalid cidr %q: %w", i, cidr, err)
+			}
+			ingress.cidrs = append(ingress.cidrs, prefix.Masked())
+		}
+		ingress.ports = nil
+		for _, port := range ingress.Ports {
+			ranges, err := parsePorts(port)
+			if err != nil {
+				return fmt.Errorf("forbiddenIngress[%d]: %w", i, err)
+			}
+			ingress.ports = append(ingress.ports, ranges...)
+		}
+		for j, protocol := range ingress.Protocols {
+			ingress.Protocols[j] = strings.ToLower(strings.TrimSpace(protocol))
+		}
+	}
+
+	return nil
+}
+
+// parsePorts parses comma-separated ports or port ranges (e.g., "22,900-1000").
+// An empty value, "*" or "-1" means all ports.
+func parsePorts(ports string) ([]portRange, error) {
+	var ranges []portRange
+	for _, port := range strings.Split(ports, ",") {
+		port = strings.TrimSpace(port)
+		if port == "" || port == "*" || port == "-1" {
+			ranges = append(ranges, portRange{from: 1, to: 65535})
+			continue
+		}
+
+		fromStr, toStr, found := strings.Cut(port, "-")
+		if !found {
+			toStr = fromStr
+		}
+		from, err := strconv.Atoi(strings.TrimSpace(fromStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", port)
+		}
+		to, err := strconv.Atoi(strings.TrimSpace(toStr))
+		if err != nil || to < from || from < 0 || to > 65535 {
+			return nil, fmt.Errorf("invalid port range %q", port)
+		}
+		ranges = append(ranges, portRange{from: from, to: to})
+	}
+	return ranges, nil
+}
@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadPoliciesFromDir(t *testing.T) {
+	const validPolicy = `version: v1
+id: %s
+rules:
+  allowedCsps: [aws]
+`
+	t.Cleanup(func() {
+		policiesMu.Lock()
+		defer policiesMu.Unlock()
+		delete(policies, "test-a")
+		delete(policies, "test-b")
+	})
+
+	writeFile := func(t *testing.T, dir, name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registered := func(id string) bool {
+		for _, p := range ListPolicies() {
+			if p.Id == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("an invalid file registers none", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "a.yaml", fmt.Sprintf(validPolicy, "test-a"))
+		writeFile(t, dir, "b.yaml", "version: v1\nid: test-b\nenforcement: audit\n")
+
+		loaded, err := LoadPoliciesFromDir(dir)
+		if err == nil {
+			t.Fatal("LoadPoliciesFromDir() succeeded with an invalid policy")
+		}
+		if len(loaded) != 0 {
+			t.Errorf("loaded = %v, want none", loaded)
+		}
+		if registered("test-a") {
+			t.Error("the valid policy was registered although another one is invalid")
+		}
+	})
+
+	t.Run("valid files are registered", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "b.json", `{"version": "v1", "id": "test-b", "enforcement": "warn"}`)
+		writeFile(t, dir, "a.yml", fmt.Sprintf(validPolicy, "test-a"))
+		writeFile(t, dir, "README.md", "not a policy")
+
+		loaded, err := LoadPoliciesFromDir(dir)
+		if err != nil {
+			t.Fatalf("LoadPoliciesFromDir failed: %v", err)
+		}
+		if want := []string{"test-a", "test-b"}; !reflect.DeepEqual(loaded, want) {
+			t.Errorf("loaded = %v, want %v", loaded, want)
+		}
+		for _, id := range loaded {
+			if !registered(id) {
+				t.Errorf("policy %s is not registered", id)
+			}
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		if _, err := LoadPoliciesFromDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("LoadPoliciesFromDir() succeeded with a missing directory")
+		}
+	})
+}
@@ -0,0 +1,531 @@
+// Package project manages the migration projects, which tie the artifacts of a migration together
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/cloud-barista/cm-beetle/pkg/lkvstore"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Migration project
+// Holds the source infrastructure, the versioned recommendation results, the
+// selected and approved candidate, the migrated infrastructures, the data/NLB
+// migration jobs and the generated reports of a migration, with its history.
+// ============================================================================
+
+const projectKeyPrefix = "/beetle/project/"
+
+// Types of a migration job
+const (
+	JobTypeData = "data"
+	JobTypeNlb  = "nlb"
+)
+
+// Events of the project history
+const (
+	EventCreated             = "created"
+	EventUpdated             = "updated"
+	EventRecommended         = "recommended"
+	EventCandidateSelected   = "candidateSelected"
+	EventApproved            = "approved"
+	EventInfraMigration      = "infraMigration"
+	EventJobStarted          = "jobStarted"
+	EventReportGenerated     = "reportGenerated"
+	EventApprovalInvalidated = "approvalInvalidated"
+)
+
+// ProjectReq is the request to create or update a migration project.
+type ProjectReq struct {
+	Name        string                   `json:"name" validate:"required" example:"mig-project01"`
+	Description string                   `json:"description,omitempty" example:"Migration of the web service"`
+	SourceInfra *onpremmodel.OnpremInfra `json:"sourceInfra,omitempty"`
+}
+
+// RecommendationVersion is a recommendation result of the project.
+type RecommendationVersion struct {
+	Version     int                           `json:"version" example:"1"`
+	CreatedAt   time.Time                     `json:"createdAt"`
+	Csp         string                        `json:"csp,omitempty" example:"aws"`
+	Region      string                        `json:"region,omitempty" example:"ap-northeast-2"`
+	ReqId       string                        `json:"reqId,omitempty"`
+	Candidates  []cloudmodel.RecommendedInfra `json:"candidates"`
+	Description string                        `json:"description,omitempty"`
+}
+
+// SelectedCandidate is the candidate selected among the recommendation results.
+type SelectedCandidate struct {
+	Version        int       `json:"version" example:"1"`
+	CandidateIndex int       `json:"candidateIndex" example:"0"`
+	SelectedAt     time.Time `json:"selectedAt"`
+	SelectedBy     string    `json:"selectedBy,omitempty" example:"alice"`
+}
+
+// SelectCandidateReq is the request to select a candidate.
+type SelectCandidateReq struct {
+	Version        int    `json:"version" validate:"required" example:"1"`
+	CandidateIndex int    `json:"candidateIndex" example:"0"`
+	SelectedBy     string `json:"selectedBy,omitempty" example:"alice"`
+}
+
+// Approval is the approval record of the selected candidate.
+type Approval struct {
+	Version        int       `json:"version" example:"1"`
+	CandidateIndex int       `json:"candidateIndex" example:"0"`
+	ApprovedBy     string    `json:"approvedBy" example:"bob"`
+	ApprovedAt     time.Time `json:"approvedAt"`
+	Comment        string    `json:"comment,omitempty" example:"Approved after the cost review"`
+}
+
+// ApprovalReq is the request to approve the selected candidate.
+type ApprovalReq struct {
+	ApprovedBy string `json:"approvedBy" validate:"required" example:"bob"`
+	Comment    string `json:"comment,omitempty" example:"Approved after the cost review"`
+}
+
+// MigratedInfra is an infrastructure migrated in the project.
+type MigratedInfra struct {
+	NsId      string    `json:"nsId" example:"mig01"`
+	InfraId   string    `json:"infraId" example:"mmci01"`
+	ReqId     string    `json:"reqId,omitempty"`
+	Status    string    `json:"status" example:"Success"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ProjectJob is a data or NLB migration job of the project (the status is tracked by GET /request/{reqId}).
+type ProjectJob struct {
+	Type        string    `json:"type" enums:"data,nlb" example:"data"`
+	NsId        string    `json:"nsId,omitempty" example:"mig01"`
+	InfraId     string    `json:"infraId,omitempty" example:"mmci01"`
+	ReqId       string    `json:"reqId"`
+	StatusURL   string    `json:"statusUrl,omitempty" example:"/beetle/request/1234"`
+	Description string    `json:"description,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+}
+
+// ProjectReport is a migration report generated in the project.
+type ProjectReport struct {
+	Id          int       `json:"id" example:"1"`
+	NsId        string    `json:"nsId" example:"mig01"`
+	InfraId     string    `json:"infraId" example:"mmci01"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Markdown    string    `json:"markdown,omitempty"`
+}
+
+// ProjectEvent is an event of the project history.
+type ProjectEvent struct {
+	At     time.Time `json:"at"`
+	Event  string    `json:"event" example:"approved"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Project is a migration project.
+type Project struct {
+	Id                string                   `json:"id" example:"mig-project01"`
+	Name              string                   `json:"name" example:"mig-project01"`
+	Description       string                   `json:"description,omitempty"`
+	CreatedAt         time.Time                `json:"createdAt"`
+	UpdatedAt         time.Time                `json:"updatedAt"`
+	SourceInfra       *onpremmodel.OnpremInfra `json:"sourceInfra,omitempty"`
+	Recommendations   []RecommendationVersion  `json:"recommendations"`
+	SelectedCandidate *SelectedCandidate       `json:"selectedCandidate,omitempty"`
+	Approval          *Approval                `json:"approval,omitempty"`
+	Infras            []MigratedInfra          `json:"infras"`
+	Jobs              []ProjectJob             `json:"jobs"`
+	Reports           []ProjectReport          `json:"reports"`
+	History           []ProjectEvent           `json:"history"`
+}
+
+// ProjectList is the list of the migration projects (summarized, without the models and the reports).
+type ProjectList struct {
+	Projects []Project `json:"projects"`
+}
+
+var projectMutex sync.Mutex
+
+// CreateProject creates a migration project.
+func CreateProject(req ProjectReq) (Project, error) {
+	if ok, detail := common.IsValidName(req.Name); !ok {
+		return Project{}, fmt.Errorf("invalid project name (%s): %s", req.Name, detail)
+	}
+
+	projectMutex.Lock()
+	defer projectMutex.Unlock()
+
+	if _, exists := getProject(req.Name); exists {
+		return Project{}, fmt.Errorf("the project (%s) already exists", req.Name)
+	}
+
+	now := time.Now()
+	project := Project{
+		Id:              req.Name,
+		Name:            req.Name,
+		Description:     req.Description,
+		CreatedAt:       now,
+		SourceInfra:     req.SourceInfra,
+		Recommendations: []RecommendationVersion{},
+		Infras:          []MigratedInfra{},
+		Jobs:            []ProjectJob{},
+		Reports:         []ProjectReport{},
+		History:         []ProjectEvent{},
+	}
+	addEvent(&project, EventCreated, "")
+	putProject(&project)
+
+	log.Info().Msgf("project created (projectId: %s)", project.Id)
+	return project, nil
+}
+
+// GetProject returns the migration project.
+func GetProject(projectId string) (Project, error) {
+	project, ok := getProject(projectId)
+	if !ok {
+		return Project{}, fmt.Errorf("the project (%s) does not exist", projectId)
+	}
+	return project, nil
+}
+
+// ListProjects returns the migration projects, newest first.
+// The source infrastructure, the recommendation candidates and the report contents are omitted.
+func ListProjects() ProjectList {
+	ret := ProjectList{Projects: []Project{}}
+
+	values, ok := lkvstore.GetWithPrefix(projectKeyPrefix)
+	if !ok {
+		return ret
+	}
+	for _, value := range values {
+		project, ok := convertToProject(value)
+		if !ok {
+			continue
+		}
+		project.SourceInfra = nil
+		recommendations := make([]RecommendationVersion, len(project.Recommendations))
+		for i, rec := range project.Recommendations {
+			rec.Candidates = nil
+			recommendations[i] = rec
+		}
+		project.Recommendations = recommendations
+		reports := make([]ProjectReport, len(project.Reports))
+		for i, report := range project.Reports {
+			report.Markdown = ""
+			reports[i] = report
+		}
+		project.Reports = reports
+		ret.Projects = append(ret.Projects, project)
+	}
+	sort.Slice(ret.Projects, func(i, j int) bool {
+		return ret.Projects[i].CreatedAt.After(ret.Projects[j].CreatedAt)
+	})
+	return ret
+}
+
+// UpdateProject updates the description and the source infrastructure of the project.
+// The name (i.e., the ID) cannot be changed. Changing the source infrastructure invalidates the approval.
+func UpdateProject(projectId string, req ProjectReq) (Project, error) {
+	if req.Name != "" && req.Name != projectId {
+		return Project{}, fmt.Errorf("the project name cannot be changed (%s to %s)", projectId, req.Name)
+	}
+
+	return updateProject(projectId, func(project *Project) error {
+		project.Description = req.Description
+		if req.SourceInfra != nil {
+			project.SourceInfra = req.SourceInfra
+			if project.Approval != nil {
+				project.Approval = nil
+				addEvent(project, EventApprovalInvalidated, "the source infrastructure has been changed")
+			}
+		}
+		addEvent(project, EventUpdated, "")
+		return nil
+	})
+}
+
+// DeleteProject deletes the migration project (the migrated infrastructures are not deleted).
+func DeleteProject(projectId string) error {
+	projectMutex.Lock()
+	defer projectMutex.Unlock()
+
+	if _, exists := getProject(projectId); !exists {
+		return fmt.Errorf("the project (%s) does not exist", projectId)
+	}
+	lkvstore.Delete(projectKeyPrefix + projectId)
+
+	log.Info().Msgf("project deleted (projectId: %s)", projectId)
+	return nil
+}
+
+// GetHistory returns the history of the project, oldest first.
+func GetHistory(projectId string) ([]ProjectEvent, error) {
+	project, err := GetProject(projectId)
+	if err != nil {
+		return nil, err
+	}
+	return project.History, nil
+}
+
+// AddRecommendation adds the recommendation result as a new version of the project.
+func AddRecommendation(projectId string, rec RecommendationVersion) (RecommendationVersion, error) {
+	if len(rec.Candidates) == 0 {
+		return RecommendationVersion{}, fmt.Errorf("the recommendation result cannot be empty")
+	}
+
+	_, err := updateProject(projectId, func(project *Project) error {
+		rec.Version = len(project.Recommendations) + 1
+		rec.CreatedAt = time.Now()
+		project.Recommendations = append(project.Recommendations, rec)
+		addEvent(project, EventRecommended, fmt.Sprintf("version %d (%d candidate(s), %s %s)", rec.Version, len(rec.Candidates), rec.Csp, rec.Region))
+		return nil
+	})
+	if err != nil {
+		return RecommendationVersion{}, err
+	}
+	return rec, nil
+}
+
+// SelectCandidate selects a candidate of a recommendation version. The previous approval, if any, is invalidated.
+func SelectCandidate(projectId string, req SelectCandidateReq) (Project, error) {
+	return updateProject(projectId, func(project *Project) error {
+		if _, err := findCandidate(project, req.Version, req.CandidateIndex); err != nil {
+			return err
+		}
+		project.SelectedCandidate = &SelectedCandidate{
+			Version:        req.Version,
+			CandidateIndex: req.CandidateIndex,
+			SelectedAt:     time.Now(),
+			SelectedBy:     req.SelectedBy,
+		}
+		addEvent(project, EventCandidateSelected, fmt.Sprintf("version %d, candidate %d", req.Version, req.CandidateIndex))
+		if project.Approval != nil {
+			project.Approval = nil
+			addEvent(project, EventApprovalInvalidated, "another candidate has been selected")
+		}
+		return nil
+	})
+}
+
+// Approve approves the selected candidate of the project.
+func Approve(projectId string, req ApprovalReq) (Project, error) {
+	if req.ApprovedBy == "" {
+		return Project{}, fmt.Errorf("the approver (approvedBy) cannot be empty")
+	}
+
+	return updateProject(projectId, func(project *Project) error {
+		if project.SelectedCandidate == nil {
+			return fmt.Errorf("no candidate is selected in the project (%s), so it cannot be approved", project.Id)
+		}
+		project.Approval = &Approval{
+			Version:        project.SelectedCandidate.Version,
+			CandidateIndex: project.SelectedCandidate.CandidateIndex,
+			ApprovedBy:     req.ApprovedBy,
+			ApprovedAt:     time.Now(),
+			Comment:        req.Comment,
+		}
+		addEvent(project, EventApproved, fmt.Sprintf("version %d, candidate %d by %s",
+			project.Approval.Version, project.Approval.CandidateIndex, req.ApprovedBy))
+		return nil
+	})
+}
+
+// GetApprovedModel returns the approved candidate (the target infrastructure model) of the project.
+func GetApprovedModel(projectId string) (cloudmodel.RecommendedInfra, error) {
+	project, err := GetProject(projectId)
+	if err != nil {
+		return cloudmodel.RecommendedInfra{}, err
+	}
+	if project.Approval == nil {
+		return cloudmodel.RecommendedInfra{}, fmt.Errorf("the project (%s) has no approved candidate, so it cannot be migrated", projectId)
+	}
+	return findCandidate(&project, project.Approval.Version, project.Approval.CandidateIndex)
+}
+
+// CheckApprovedModel checks that the target infrastructure model is the approved candidate of the project.
+// A project without an approval accepts any model.
+func CheckApprovedModel(projectId string, targetModel cloudmodel.RecommendedInfra) error {
+	project, err := GetProject(projectId)
+	if err != nil {
+		return err
+	}
+	if project.Approval == nil {
+		return nil
+	}
+	approved, err := findCandidate(&project, project.Approval.Version, project.Approval.CandidateIndex)
+	if err != nil {
+		return err
+	}
+	if !sameModel(approved, targetModel) {
+		return fmt.Errorf("the target infrastructure does not match the approved candidate (version %d, candidate %d) of the project (%s)",
+			project.Approval.Version, project.Approval.CandidateIndex, projectId)
+	}
+	return nil
+}
+
+// sameModel reports whether the models are the same, compared by their JSON encoding.
+func sameModel(a, b cloudmodel.RecommendedInfra) bool {
+	aJson, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJson, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aJson) == string(bJson)
+}
+
+// GetSourceInfra returns the source infrastructure of the project.
+func GetSourceInfra(projectId string) (onpremmodel.OnpremInfra, error) {
+	project, err := GetProject(projectId)
+	if err != nil {
+		return onpremmodel.OnpremInfra{}, err
+	}
+	if project.SourceInfra == nil {
+		return onpremmodel.OnpremInfra{}, fmt.Errorf("the source infrastructure of the project (%s) does not exist", projectId)
+	}
+	return *project.SourceInfra, nil
+}
+
+// RecordInfra records (or updates by the request ID) an infrastructure migrated in the project.
+func RecordInfra(projectId string, infra MigratedInfra) error {
+	_, err := updateProject(projectId, func(project *Project) error {
+		infra.UpdatedAt = time.Now()
+		updated := false
+		for i, existing := range project.Infras {
+			if infra.ReqId != "" && existing.ReqId == infra.ReqId {
+				project.Infras[i] = infra
+				updated = true
+				break
+			}
+		}
+		if !updated {
+			project.Infras = append(project.Infras, infra)
+		}
+		addEvent(project, EventInfraMigration, fmt.Sprintf("%s/%s: %s", infra.NsId, infra.InfraId, infra.Status))
+		return nil
+	})
+	return err
+}
+
+// RecordJob records a data or NLB migration job of the project.
+func RecordJob(projectId string, job ProjectJob) error {
+	_, err := updateProject(projectId, func(project *Project) error {
+		job.StartedAt = time.Now()
+		project.Jobs = append(project.Jobs, job)
+		addEvent(project, EventJobStarted, fmt.Sprintf("%s migration (reqId: %s)", job.Type, job.ReqId))
+		return nil
+	})
+	return err
+}
+
+// RecordReport records a migration report generated in the project.
+func RecordReport(projectId string, report ProjectReport) (ProjectReport, error) {
+	_, err := updateProject(projectId, func(project *Project) error {
+		report.Id = len(project.Reports) + 1
+		report.GeneratedAt = time.Now()
+		project.Reports = append(project.Reports, report)
+		addEvent(project, EventReportGenerated, fmt.Sprintf("report %d of %s/%s", report.Id, report.NsId, report.InfraId))
+		return nil
+	})
+	if err != nil {
+		return ProjectReport{}, err
+	}
+	return report, nil
+}
+
+// GetReport returns a migration report of the project.
+func GetReport(projectId string, reportId int) (ProjectReport, error) {
+	project, err := GetProject(projectId)
+	if err != nil {
+		return ProjectReport{}, err
+	}
+	for _, report := range project.Reports {
+		if report.Id == reportId {
+			return report, nil
+		}
+	}
+	return ProjectReport{}, fmt.Errorf("the report (%d) of the project (%s) does not exist", reportId, projectId)
+}
+
+// findCandidate returns the candidate of the recommendation version.
+func findCandidate(project *Project, version, candidateIndex int) (cloudmodel.RecommendedInfra, error) {
+	for _, rec := range project.Recommendations {
+		if rec.Version != version {
+			continue
+		}
+		if candidateIndex < 0 || candidateIndex >= len(rec.Candidates) {
+			return cloudmodel.RecommendedInfra{}, fmt.Errorf("the candidate (%d) of the recommendation version (%d) does not exist", candidateIndex, version)
+		}
+		return rec.Candidates[candidateIndex], nil
+	}
+	return cloudmodel.RecommendedInfra{}, fmt.Errorf("the recommendation version (%d) does not exist", version)
+}
+
+func addEvent(project *Project, event, detail string) {
+	project.History = append(project.History, ProjectEvent{At: time.Now(), Event: event, Detail: detail})
+}
+
+// updateProject applies the update to the stored project under the lock, and stores it if the update succeeds.
+func updateProject(projectId string, update func(project *Project) error) (Project, error) {
+	projectMutex.Lock()
+	defer projectMutex.Unlock()
+
+	project, ok := getProject(projectId)
+	if !ok {
+		return Project{}, fmt.Errorf("the project (%s) does not exist", projectId)
+	}
+	if err := update(&project); err != nil {
+		return Project{}, err
+	}
+	putProject(&project)
+	return project, nil
+}
+
+// putProject stores the project (the caller holds projectMutex).
+func putProject(project *Project) {
+	project.UpdatedAt = time.Now()
+	if err := lkvstore.Put(projectKeyPrefix+project.Id, *project); err != nil {
+		log.Error().Err(err).Msgf("failed to store the project (projectId: %s)", project.Id)
+	}
+}
+
+// getProject returns the stored project.
+func getProject(projectId string) (Project, bool) {
+	value, ok := lkvstore.Get(projectKeyPrefix + projectId)
+	if !ok {
+		return Project{}, false
+	}
+	return convertToProject(value)
+}
+
+// convertToProject converts the stored value (a struct, or a map once loaded from the file) to Project.
+func convertToProject(value any) (Project, bool) {
+	switch v := value.(type) {
+	case Project:
+		return v, true
+	case map[string]any:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to marshal value to JSON")
+			return Project{}, false
+		}
+
+		var project Project
+		if err := json.Unmarshal(jsonBytes, &project); err != nil {
+			log.Error().Err(err).Msg("Failed to unmarshal JSON to Project")
+			return Project{}, false
+		}
+		return project, true
+	default:
+		log.Error().Msgf("Unexpected value type: %T", value)
+		return Project{}, false
+	}
+}
@@ -0,0 +1,358 @@
+package recommendation
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// CIDR planning for the target vNets
+// ============================================================================
+
+// privateNetworks are the IPv4 private networks (RFC 1918).
+var privateNetworks = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+// PlanVNets plans the target vNets for the source networks.
+// One vNet is planned per private network spanned by the source networks (see RecommendVNet).
+//   - keep: the source addressing is kept; the subnets are the source networks.
+//   - pool: each vNet is re-addressed to a block of the same size allocated from the pool,
+//     avoiding the source networks, the on-premise ranges and the existing vNets in the namespace.
+//     The relative layout of the subnets in the vNet is preserved.
+//
+// In both modes, the planned vNets are validated against the reserved ranges, and
+// overlaps that prevent routing (e.g., over VPN) are reported as conflicts.
+// The vNets are planned as dual-stack if the source nodes have IPv6 addresses (see planIPv6).
+func PlanVNets(csp, region string, srcInfra onpremmodel.OnpremInfra, req cloudmodel.CidrPlanReq) ([]cloudmodel.VNetReq, *cloudmodel.CidrPlan, error) {
+
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	if mode == "" {
+		mode = cloudmodel.CidrPlanModeKeep
+	}
+	if mode != cloudmodel.CidrPlanModeKeep && mode != cloudmodel.CidrPlanModePool {
+		return nil, nil, fmt.Errorf("invalid CIDR plan mode '%s' (keep | pool)", req.Mode)
+	}
+	if mode == cloudmodel.CidrPlanModePool && len(req.Pool) == 0 {
+		return nil, nil, fmt.Errorf("CIDR pool is required in pool mode")
+	}
+
+	vNets, err := RecommendVNet(csp, region, srcInfra)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan := &cloudmodel.CidrPlan{Mode: mode}
+
+	// Reserved ranges: source networks, on-premise ranges and existing vNets in the namespace
+	for _, vNet := range vNets {
+		for _, subnet := range vNet.SubnetInfoList {
+			plan.ReservedRanges = append(plan.ReservedRanges, cloudmodel.ReservedRange{CidrBlock: subnet.IPv4_CIDR, Source: "source-network"})
+		}
+	}
+	for _, cidr := range req.OnpremRanges {
+		networkAddr, err := toNetworkAddress(cidr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid on-premise range: %w", err)
+		}
+		plan.ReservedRanges = append(plan.ReservedRanges, cloudmodel.ReservedRange{CidrBlock: networkAddr, Source: "onprem"})
+	}
+	if req.NsId != "" {
+		existing, err := tbclient.NewSession().ReadAllVNet(req.NsId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the existing vNets in namespace %s: %w", req.NsId, err)
+		}
+		for _, vNet := range existing.VNet {
+			if vNet.CidrBlock == "" {
+				continue
+			}
+			plan.ReservedRanges = append(plan.ReservedRanges, cloudmodel.ReservedRange{
+				CidrBlock: vNet.CidrBlock,
+				Source:    fmt.Sprintf("vnet:%s/%s", req.NsId, vNet.Id),
+			})
+		}
+	}
+
+	// Re-address the vNets in pool mode
+	if mode == cloudmodel.CidrPlanModePool {
+		var used []string
+		for _, r := range plan.ReservedRanges {
+			used = append(used, r.CidrBlock)
+		}
+		plan.VNets, err = readdressVNets(vNets, req.Pool, used)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		for _, vNet := range vNets {
+			vNetPlan := cloudmodel.VNetCidrPlan{CidrBlock: vNet.CidrBlock, PrivateNetwork: whichPrivateNetwork(vNet.CidrBlock)}
+			for _, subnet := range vNet.SubnetInfoList {
+				vNetPlan.Subnets = append(vNetPlan.Subnets, cloudmodel.SubnetCidrPlan{CidrBlock: subnet.IPv4_CIDR, SourceCidrBlock: subnet.IPv4_CIDR})
+			}
+			plan.VNets = append(plan.VNets, vNetPlan)
+		}
+		plan.Notes = append(plan.Notes,
+			"The source addressing is kept. The target vNets overlap the source networks, so they cannot be routed to each other (e.g., over VPN) during a phased migration; use the pool mode for hybrid connectivity.")
+	}
+
+	// Plan the dual-stack vNets for the source IPv6 networks
+	planIPv6(csp, srcInfra, vNets, plan)
+
+	// Validate the planned vNets against the reserved ranges
+	for _, vNet := range vNets {
+		for _, r := range plan.ReservedRanges {
+			if mode == cloudmodel.CidrPlanModeKeep && r.Source == "source-network" {
+				continue // Overlaps by design
+			}
+			if cidrsOverlap(vNet.CidrBlock, r.CidrBlock) {
+				plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("vNet %s overlaps %s (%s)", vNet.CidrBlock, r.CidrBlock, r.Source))
+			}
+		}
+	}
+	if len(vNets) > 1 {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(
+			"The source networks span %d private networks; a vNet is planned for each of them.", len(vNets)))
+	}
+
+	log.Debug().
+		Str("mode", mode).
+		Int("vNets", len(vNets)).
+		Int("reservedRanges", len(plan.ReservedRanges)).
+		Int("conflicts", len(plan.Conflicts)).
+		Msg("CIDR plan completed")
+
+	return vNets, plan, nil
+}
+
+// setPlannedVNets sets the planned vNets to the infra: the first one as TargetVNet and the others as AdditionalVNetList.
+// The vNets and subnets are named with the formats (e.g., "mig-vnet-%02d", "mig-subnet-%02d"); subnets are numbered across vNets.
+func setPlannedVNets(infra *cloudmodel.RecommendedInfra, vNets []cloudmodel.VNetReq, plan *cloudmodel.CidrPlan, vNetNameFormat, subnetNameFormat string) {
+	if len(vNets) == 0 {
+		return
+	}
+
+	subnetNum := 0
+	for i := range vNets {
+		vNets[i].Name = fmt.Sprintf(vNetNameFormat, i+1)
+		vNets[i].Description = "a recommended vNet for migration"
+		for j := range vNets[i].SubnetInfoList {
+			subnetNum++
+			vNets[i].SubnetInfoList[j].Name = fmt.Sprintf(subnetNameFormat, subnetNum)
+			vNets[i].SubnetInfoList[j].Description = "a recommended subnet for migration"
+		}
+
+		if plan != nil && i < len(plan.VNets) {
+			plan.VNets[i].VNetName = vNets[i].Name
+			for j := range plan.VNets[i].Subnets {
+				if j < len(vNets[i].SubnetInfoList) {
+					plan.VNets[i].Subnets[j].SubnetName = vNets[i].SubnetInfoList[j].Name
+				}
+			}
+		}
+	}
+
+	infra.TargetVNet = vNets[0]
+	infra.AdditionalVNetList = vNets[1:]
+	if len(infra.AdditionalVNetList) == 0 {
+		infra.AdditionalVNetList = nil
+	}
+	infra.CidrPlan = plan
+}
+
+// selectNodeNetwork returns the vNet and subnet for the source node by matching its IP addresses
+// with the source networks of the CIDR plan. The first subnet of the TargetVNet is returned if not matched.
+func selectNodeNetwork(infra cloudmodel.RecommendedInfra, node onpremmodel.NodeProperty) (string, string) {
+	if infra.CidrPlan != nil {
+		for _, nic := range node.Interfaces {
+			for _, cidr := range nic.IPv4CidrBlocks {
+				ip, _, err := net.ParseCIDR(cidr)
+				if err != nil {
+					ip = net.ParseIP(cidr)
+				}
+				if ip == nil || ip.IsLoopback() {
+					continue
+				}
+				for _, vNetPlan := range infra.CidrPlan.VNets {
+					for _, subnetPlan := range vNetPlan.Subnets {
+						_, srcNet, err := net.ParseCIDR(subnetPlan.SourceCidrBlock)
+						if err == nil && srcNet.Contains(ip) && subnetPlan.SubnetName != "" {
+							return vNetPlan.VNetName, subnetPlan.SubnetName
+						}
+					}
+				}
+			}
+		}
+	}
+
+	subnetId := ""
+	if len(infra.TargetVNet.SubnetInfoList) > 0 {
+		subnetId = infra.TargetVNet.SubnetInfoList[0].Name
+	}
+	return infra.TargetVNet.Name, subnetId
+}
+
+// bindSecurityGroupsToVNets makes each NodeGroup refer to security groups in its own vNet.
+// A security group is bound to a vNet, so a copy is added for NodeGroups in the other vNets.
+func bindSecurityGroupsToVNets(infra *cloudmodel.RecommendedInfra) {
+	if len(infra.AdditionalVNetList) == 0 {
+		return
+	}
+
+	// Copy the list shared with the other candidates
+	infra.TargetSecurityGroupList = append([]cloudmodel.SecurityGroupReq(nil), infra.TargetSecurityGroupList...)
+
+	sgIdx := map[string]int{}
+	for i, sg := range infra.TargetSecurityGroupList {
+		sgIdx[sg.Name] = i
+	}
+
+	for i := range infra.TargetInfra.NodeGroups {
+		ng := &infra.TargetInfra.NodeGroups[i]
+		sgIds := make([]string, len(ng.SecurityGroupIds))
+		for j, sgId := range ng.SecurityGroupIds {
+			sgIds[j] = sgId
+			idx, ok := sgIdx[sgId]
+			if !ok || infra.TargetSecurityGroupList[idx].VNetId == ng.VNetId {
+				continue
+			}
+
+			copyName := fmt.Sprintf("%s-%s", sgId, ng.VNetId)
+			if _, exists := sgIdx[copyName]; !exists {
+				sgCopy := infra.TargetSecurityGroupList[idx]
+				sgCopy.Name = copyName
+				sgCopy.VNetId = ng.VNetId
+				sgCopy.Description = fmt.Sprintf("%s (in %s)", sgCopy.Description, ng.VNetId)
+				infra.TargetSecurityGroupList = append(infra.TargetSecurityGroupList, sgCopy)
+				sgIdx[copyName] = len(infra.TargetSecurityGroupList) - 1
+			}
+			sgIds[j] = copyName
+		}
+		ng.SecurityGroupIds = sgIds
+	}
+}
+
+// rewriteSecurityGroupCidrs replaces the source networks in the firewall rules with the planned subnets (pool mode).
+func rewriteSecurityGroupCidrs(sgList []cloudmodel.SecurityGroupReq, plan *cloudmodel.CidrPlan) {
+	if plan == nil || plan.Mode != cloudmodel.CidrPlanModePool {
+		return
+	}
+
+	targetBySource := map[string]string{}
+	for _, vNetPlan := range plan.VNets {
+		for _, subnetPlan := range vNetPlan.Subnets {
+			targetBySource[subnetPlan.SourceCidrBlock] = subnetPlan.CidrBlock
+		}
+	}
+
+	for i := range sgList {
+		if sgList[i].FirewallRules == nil {
+			continue
+		}
+		rules := make([]cloudmodel.FirewallRuleReq, len(*sgList[i].FirewallRules))
+		copy(rules, *sgList[i].FirewallRules)
+		for j := range rules {
+			if target, ok := targetBySource[rules[j].CIDR]; ok {
+				rules[j].CIDR = target
+			}
+		}
+		sgList[i].FirewallRules = &rules
+	}
+}
+
+// readdressVNets re-addresses each vNet to a block of the same size allocated from the pool,
+// avoiding the used blocks and the blocks allocated to the preceding vNets.
+// The subnets are moved to the same offsets in the new block. The vNets are updated in place.
+func readdressVNets(vNets []cloudmodel.VNetReq, pool []string, used []string) ([]cloudmodel.VNetCidrPlan, error) {
+	used = append([]string(nil), used...)
+
+	var vNetPlans []cloudmodel.VNetCidrPlan
+	for i := range vNets {
+		srcVNetCidr := vNets[i].CidrBlock
+		_, srcVNet, err := net.ParseCIDR(srcVNetCidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vNet CIDR '%s': %w", srcVNetCidr, err)
+		}
+		prefixLen, _ := srcVNet.Mask.Size()
+
+		newVNetCidr, err := allocateFromPool(pool, prefixLen, used)
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate a /%d block for the source networks in %s: %w", prefixLen, srcVNetCidr, err)
+		}
+		used = append(used, newVNetCidr)
+		_, newVNet, _ := net.ParseCIDR(newVNetCidr)
+
+		vNetPlan := cloudmodel.VNetCidrPlan{CidrBlock: newVNetCidr, PrivateNetwork: whichPrivateNetwork(srcVNetCidr)}
+		for j, subnet := range vNets[i].SubnetInfoList {
+			newSubnetCidr, err := rebaseCidr(subnet.IPv4_CIDR, srcVNet, newVNet)
+			if err != nil {
+				return nil, err
+			}
+			vNetPlan.Subnets = append(vNetPlan.Subnets, cloudmodel.SubnetCidrPlan{CidrBlock: newSubnetCidr, SourceCidrBlock: subnet.IPv4_CIDR})
+			vNets[i].SubnetInfoList[j].IPv4_CIDR = newSubnetCidr
+		}
+		vNets[i].CidrBlock = newVNetCidr
+		vNetPlans = append(vNetPlans, vNetPlan)
+
+		log.Debug().Str("source", srcVNetCidr).Str("target", newVNetCidr).Msg("vNet re-addressed from the CIDR pool")
+	}
+	return vNetPlans, nil
+}
+
+// allocateFromPool returns the first block of the prefix length in the pool that does not overlap the used blocks.
+func allocateFromPool(pool []string, prefixLen int, used []string) (string, error) {
+	var errs []string
+	for _, poolCidr := range pool {
+		cidr, err := nextFreeSubnet(poolCidr, prefixLen, used)
+		if err == nil {
+			return cidr, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", poolCidr, err))
+	}
+	return "", fmt.Errorf("no free block in the pool (%s)", strings.Join(errs, "; "))
+}
+
+// rebaseCidr moves the CIDR block from the source network to the same offset in the target network.
+func rebaseCidr(cidr string, from, to *net.IPNet) (string, error) {
+	_, block, err := net.ParseCIDR(cidr)
+	if err != nil || block.IP.To4() == nil {
+		return "", fmt.Errorf("invalid IPv4 CIDR '%s'", cidr)
+	}
+	if !from.Contains(block.IP) {
+		return "", fmt.Errorf("CIDR '%s' is not in %s", cidr, from.String())
+	}
+
+	offset := binary.BigEndian.Uint32(block.IP.To4()) - binary.BigEndian.Uint32(from.IP.To4())
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, binary.BigEndian.Uint32(to.IP.To4())+offset)
+	return (&net.IPNet{IP: ip, Mask: block.Mask}).String(), nil
+}
+
+// cidrsOverlap reports whether the two CIDR blocks overlap.
+func cidrsOverlap(a, b string) bool {
+	_, netA, errA := net.ParseCIDR(a)
+	_, netB, errB := net.ParseCIDR(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return netA.Contains(netB.IP) || netB.Contains(netA.IP)
+}
+
+// whichPrivateNetwork returns the private network containing the CIDR block ("" if not private).
+func whichPrivateNetwork(cidr string) string {
+	_, block, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return ""
+	}
+	for _, p := range privateNetworks {
+		_, privateNet, _ := net.ParseCIDR(p)
+		if privateNet.Contains(block.IP) {
+			return p
+		}
+	}
+	return ""
+}
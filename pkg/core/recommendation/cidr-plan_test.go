@@ -0,0 +1,215 @@
+package recommendation
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+)
+
+func TestAllocateFromPool(t *testing.T) {
+	tests := []struct {
+		name      string
+		pool      []string
+		prefixLen int
+		used      []string
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "first block of the pool",
+			pool:      []string{"10.100.0.0/16"},
+			prefixLen: 24,
+			want:      "10.100.0.0/24",
+		},
+		{
+			name:      "on-premise range and existing vNet are avoided",
+			pool:      []string{"10.100.0.0/16"},
+			prefixLen: 24,
+			used:      []string{"10.100.0.0/24", "10.100.1.0/25"},
+			want:      "10.100.2.0/24",
+		},
+		{
+			name:      "pool covered by a used block falls back to the next pool",
+			pool:      []string{"10.100.0.0/16", "172.20.0.0/16"},
+			prefixLen: 20,
+			used:      []string{"10.0.0.0/8"},
+			want:      "172.20.0.0/20",
+		},
+		{
+			name:      "pool exhausted",
+			pool:      []string{"10.100.0.0/23"},
+			prefixLen: 24,
+			used:      []string{"10.100.0.0/24", "10.100.1.0/24"},
+			wantErr:   "no free block in the pool (10.100.0.0/23: address space exhausted)",
+		},
+		{
+			name:      "block larger than the pool",
+			pool:      []string{"10.100.0.0/24"},
+			prefixLen: 16,
+			wantErr:   "prefix length /16 does not fit in 10.100.0.0/24",
+		},
+		{
+			name:      "invalid pool",
+			pool:      []string{"10.100.0.0"},
+			prefixLen: 24,
+			wantErr:   "invalid IPv4 vNet CIDR '10.100.0.0'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allocateFromPool(tt.pool, tt.prefixLen, tt.used)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("allocateFromPool() = %q, %v, want error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("allocateFromPool failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("allocateFromPool() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRebaseCidr(t *testing.T) {
+	_, from, _ := net.ParseCIDR("192.168.0.0/16")
+	_, to, _ := net.ParseCIDR("10.100.0.0/16")
+
+	tests := []struct {
+		cidr    string
+		want    string
+		wantErr bool
+	}{
+		{cidr: "192.168.0.0/16", want: "10.100.0.0/16"},
+		{cidr: "192.168.1.0/24", want: "10.100.1.0/24"},
+		{cidr: "192.168.10.128/25", want: "10.100.10.128/25"},
+		{cidr: "192.168.255.252/30", want: "10.100.255.252/30"},
+		{cidr: "192.168.3.7/24", want: "10.100.3.0/24"}, // Host bits are masked
+		{cidr: "172.16.1.0/24", wantErr: true},
+		{cidr: "2001:db8::/64", wantErr: true},
+		{cidr: "192.168.1.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := rebaseCidr(tt.cidr, from, to)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("rebaseCidr(%q) = %q, want error", tt.cidr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("rebaseCidr(%q) failed: %v", tt.cidr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("rebaseCidr(%q) = %q, want %q", tt.cidr, got, tt.want)
+		}
+	}
+}
+
+func TestReaddressVNets(t *testing.T) {
+	newVNets := func() []cloudmodel.VNetReq {
+		return []cloudmodel.VNetReq{
+			{
+				CidrBlock: "192.168.0.0/16",
+				SubnetInfoList: []cloudmodel.SubnetReq{
+					{IPv4_CIDR: "192.168.1.0/24"},
+					{IPv4_CIDR: "192.168.2.0/24"},
+				},
+			},
+			{
+				CidrBlock: "10.0.0.0/22",
+				SubnetInfoList: []cloudmodel.SubnetReq{
+					{IPv4_CIDR: "10.0.0.0/24"},
+					{IPv4_CIDR: "10.0.2.0/23"},
+				},
+			},
+		}
+	}
+
+	t.Run("reserved ranges are avoided and the subnet layout is kept", func(t *testing.T) {
+		vNets := newVNets()
+		used := []string{
+			"192.168.1.0/24", "192.168.2.0/24", "10.0.0.0/24", "10.0.2.0/23", // Source networks
+			"10.1.0.0/16", // On-premise range
+			"10.0.0.0/16", // Existing vNet
+		}
+		usedLen := len(used)
+
+		got, err := readdressVNets(vNets, []string{"10.0.0.0/8"}, used)
+		if err != nil {
+			t.Fatalf("readdressVNets failed: %v", err)
+		}
+
+		want := []cloudmodel.VNetCidrPlan{
+			{
+				CidrBlock:      "10.2.0.0/16",
+				PrivateNetwork: "192.168.0.0/16",
+				Subnets: []cloudmodel.SubnetCidrPlan{
+					{CidrBlock: "10.2.1.0/24", SourceCidrBlock: "192.168.1.0/24"},
+					{CidrBlock: "10.2.2.0/24", SourceCidrBlock: "192.168.2.0/24"},
+				},
+			},
+			{
+				CidrBlock:      "10.3.0.0/22",
+				PrivateNetwork: "10.0.0.0/8",
+				Subnets: []cloudmodel.SubnetCidrPlan{
+					{CidrBlock: "10.3.0.0/24", SourceCidrBlock: "10.0.0.0/24"},
+					{CidrBlock: "10.3.2.0/23", SourceCidrBlock: "10.0.2.0/23"},
+				},
+			},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("readdressVNets() = %+v, want %+v", got, want)
+		}
+
+		for i, vNetPlan := range want {
+			if vNets[i].CidrBlock != vNetPlan.CidrBlock {
+				t.Errorf("vNets[%d].CidrBlock = %q, want %q", i, vNets[i].CidrBlock, vNetPlan.CidrBlock)
+			}
+			for j, subnetPlan := range vNetPlan.Subnets {
+				if got := vNets[i].SubnetInfoList[j].IPv4_CIDR; got != subnetPlan.CidrBlock {
+					t.Errorf("vNets[%d].SubnetInfoList[%d].IPv4_CIDR = %q, want %q", i, j, got, subnetPlan.CidrBlock)
+				}
+			}
+		}
+		if len(used) != usedLen {
+			t.Errorf("the used blocks of the caller were modified: %v", used)
+		}
+	})
+
+	t.Run("pool exhausted by the preceding vNets", func(t *testing.T) {
+		_, err := readdressVNets(newVNets(), []string{"10.100.0.0/16"}, nil)
+		if err == nil || !strings.Contains(err.Error(), "failed to allocate a /22 block for the source networks in 10.0.0.0/22") {
+			t.Errorf("readdressVNets() error = %v, want the /22 allocation failure", err)
+		}
+	})
+}
+
+func TestCidrsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"10.0.0.0/16", "10.0.1.0/24", true},
+		{"10.0.1.0/24", "10.0.0.0/16", true},
+		{"10.0.0.0/24", "10.0.0.0/24", true},
+		{"10.0.0.0/24", "10.0.1.0/24", false},
+		{"10.0.0.0/8", "172.16.0.0/12", false},
+		{"10.0.0.0/8", "invalid", false},
+	}
+
+	for _, tt := range tests {
+		if got := cidrsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("cidrsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -66,8 +66,20 @@ func FindCompatibleSpecAndImage(specs []cloudmodel.SpecInfo, images []cloudmodel
 	return bestSpec, bestImage, nil
 }
 
+// Limits on the number of entries recorded in a decision trace to keep the response size reasonable
+const (
+	maxTracedRejections = 20
+	maxTracedRanking    = 10
+)
+
 // FindCompatibleVmSpecAndImagePairs finds all compatible VM spec and image pairs by performing CSP-specific compatibility checks
 func FindCompatibleVmSpecAndImagePairs(specs []cloudmodel.SpecInfo, images []cloudmodel.ImageInfo, csp string) ([]CompatibleSpecImagePair, error) {
+	return findCompatibleVmSpecAndImagePairs(specs, images, csp, nil)
+}
+
+// findCompatibleVmSpecAndImagePairs finds all compatible VM spec and image pairs.
+// If trace is not nil, the counts after pre-filtering and the rejected pairs with reasons are recorded to it (used in explain mode).
+func findCompatibleVmSpecAndImagePairs(specs []cloudmodel.SpecInfo, images []cloudmodel.ImageInfo, csp string, trace *cloudmodel.NodeDecisionTrace) ([]CompatibleSpecImagePair, error) {
 
 	if len(specs) == 0 {
 		return nil, fmt.Errorf("no VM specs provided")
@@ -81,6 +93,11 @@ func FindCompatibleVmSpecAndImagePairs(specs []cloudmodel.SpecInfo, images []clo
 	// Pre-filter specs and images based on CSP-specific rules
 	filteredSpecs, filteredImages := preFilterByCsp(csp, specs, images)
 
+	if trace != nil {
+		trace.SpecsAfterPreFilter = len(filteredSpecs)
+		trace.ImagesAfterPreFilter = len(filteredImages)
+	}
+
 	if len(filteredSpecs) == 0 {
 		return nil, fmt.Errorf("no compatible VM specs found after CSP-specific filtering")
 	}
@@ -91,7 +108,7 @@ func FindCompatibleVmSpecAndImagePairs(specs []cloudmodel.SpecInfo, images []clo
 	log.Debug().Msgf("After pre-filtering - specs: %d, images: %d", len(filteredSpecs), len(filteredImages))
 
 	// Find best compatible pair without scoring
-	compatiblePairs, err := findCompatiblePairs(csp, filteredSpecs, filteredImages)
+	compatiblePairs, err := findCompatiblePairs(csp, filteredSpecs, filteredImages, trace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find compatible spec-image pair: %w", err)
 	}
@@ -158,7 +175,8 @@ func findCompatiblePair(csp string, specs []cloudmodel.SpecInfo, images []cloudm
 }
 
 // findCompatiblePairs finds all compatible spec-image pairs using comprehensive compatibility checks
-func findCompatiblePairs(csp string, specs []cloudmodel.SpecInfo, images []cloudmodel.ImageInfo) ([]CompatibleSpecImagePair, error) {
+// If trace is not nil, incompatible pairs are diagnosed and recorded with their reasons.
+func findCompatiblePairs(csp string, specs []cloudmodel.SpecInfo, images []cloudmodel.ImageInfo, trace *cloudmodel.NodeDecisionTrace) ([]CompatibleSpecImagePair, error) {
 
 	var compatiblePairs []CompatibleSpecImagePair
 
@@ -177,9 +195,17 @@ func findCompatiblePairs(csp string, specs []cloudmodel.SpecInfo, images []cloud
 			} else {
 				log.Debug().Msgf("Incompatible pair - Spec: %s, Image: %s",
 					spec.CspSpecName, image.CspImageName)
+				if trace != nil {
+					recordPairRejection(trace, cspLower, spec, image)
+				}
 			}
 		}
 	}
+
+	if trace != nil {
+		trace.CompatiblePairs = len(compatiblePairs)
+	}
+
 	if len(compatiblePairs) == 0 {
 		return nil, fmt.Errorf("no compatible spec-image pairs found")
 	}
@@ -187,6 +213,28 @@ func findCompatiblePairs(csp string, specs []cloudmodel.SpecInfo, images []cloud
 	return compatiblePairs, nil
 }
 
+// recordPairRejection diagnoses why a spec-image pair is incompatible and records it to the trace
+func recordPairRejection(trace *cloudmodel.NodeDecisionTrace, csp string, spec cloudmodel.SpecInfo, image cloudmodel.ImageInfo) {
+	_, reason, detail := compat.DiagnoseCompatibility(csp, spec, image)
+
+	if trace.RejectionSummary == nil {
+		trace.RejectionSummary = make(map[string]int)
+	}
+	trace.RejectionSummary[reason]++
+
+	// Keep only the first rejections in detail; the summary covers the rest
+	if len(trace.Rejections) < maxTracedRejections {
+		trace.Rejections = append(trace.Rejections, cloudmodel.PairRejection{
+			SpecId:       spec.Id,
+			CspSpecName:  spec.CspSpecName,
+			ImageId:      image.Id,
+			CspImageName: image.CspImageName,
+			Reason:       reason,
+			Detail:       detail,
+		})
+	}
+}
+
 // filterNcpVmSpecsByHypervisor filters NCP VM specs to include only KVM hypervisor specs
 func filterNcpVmSpecsByHypervisor(vmSpecs []cloudmodel.SpecInfo) []cloudmodel.SpecInfo {
 	if len(vmSpecs) == 0 {
@@ -2,9 +2,15 @@ package recommendation
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	tbmodel "github.com/cloud-barista/cb-tumblebug/src/core/model"
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
 	"github.com/rs/zerolog/log"
 )
 
@@ -17,6 +23,7 @@ type Kubernetes struct {
 	NodeCount NodeCount              `json:"node_count"`
 	Nodes     []Node                 `json:"nodes"`
 	Workloads map[string]interface{} `json:"workloads"`
+	Helm      *Helm                  `json:"helm,omitempty"`
 }
 
 type NodeCount struct {
@@ -76,98 +83,317 @@ type NodeGroupInfo struct {
 	TotalMemory int // in MiB
 }
 
-// RecommendK8sControlPlane recommends K8s control plane configuration based on honeybee source cluster data
-func RecommendK8sControlPlane(provider, region string, k8sInfoList KubernetesInfoList) (tbmodel.K8sClusterDynamicReq, error) {
-	var result tbmodel.K8sClusterDynamicReq
+// AvgCPU returns the average number of CPU cores per node (rounded up)
+func (n NodeGroupInfo) AvgCPU() int {
+	if n.Count == 0 {
+		return 0
+	}
+	return (n.TotalCPU + n.Count - 1) / n.Count
+}
 
-	// TODO: Use the following code when implementing actual API calls
-	// client := resty.New()
-	// client.SetBaseURL(config.Tumblebug.RestUrl)
-	// client.SetBasicAuth(config.Tumblebug.API.Username, config.Tumblebug.API.Password)
+// AvgMemoryGiB returns the average memory size per node in GiB (rounded up)
+func (n NodeGroupInfo) AvgMemoryGiB() int {
+	if n.Count == 0 {
+		return 0
+	}
+	avgMiB := (n.TotalMemory + n.Count - 1) / n.Count
+	return (avgMiB + 1023) / 1024
+}
 
-	// Collect control plane node information
-	// cpInfo := &NodeGroupInfo{Count: 0, TotalCPU: 0, TotalMemory: 0}
+const (
+	// Default node size used when the source cluster does not report NodeSpec for a node type
+	defaultK8sNodeCPU       = 2
+	defaultK8sNodeMemoryGiB = 4
 
-	log.Info().Int("totalServers", len(k8sInfoList.Servers)).Msg("Processing K8s servers for control plane recommendation")
+	// Upper bound of the worker autoscaling range, as a multiple of the observed worker count
+	k8sAutoscalingMaxFactor = 2
 
-	// Count kubernetes clusters to check if kubernetes cluster exists
-	k8sCount := 0
-	for i, k8s := range k8sInfoList.Servers {
-		if k8s.NodeCount.Total == 0 {
-			log.Warn().
-				Int("serverIndex", i).
-				Msg("Server has zero total nodes, skipping")
-			continue
-		}
-		k8sCount += 1
+	// Default node group and cluster names
+	defaultK8sClusterName         = "recommended-k8s-cluster"
+	defaultK8sSystemNodeGroupName = "recommended-system-nodegroup"
+	defaultK8sWorkerNodeGroupName = "recommended-worker-nodegroup"
+)
+
+// k8sSourceCluster is the source cluster selected and aggregated once,
+// from which the control plane and the worker node group are recommended.
+type k8sSourceCluster struct {
+	nodeGroupInfos map[NodeType]NodeGroupInfo
+	architecture   string
+	version        string
+	cpCount        int
+	workerType     NodeType // Node type of the source nodes the worker node group is sized from
+	workerCount    int
+}
+
+// analyzeK8sSourceCluster selects the source cluster and aggregates its node information
+// * Note: for the time being, the 1st kubernetes cluster in the list is used.
+func analyzeK8sSourceCluster(k8sInfoList KubernetesInfoList) (k8sSourceCluster, error) {
+	k8sClusterInfo, err := selectK8sCluster(k8sInfoList)
+	if err != nil {
+		return k8sSourceCluster{}, err
 	}
 
-	// Validate kubernetes cluster existence
-	if k8sCount == 0 {
-		log.Warn().Msg("No kubernetes clusters found in the source cluster, skipping recommendation")
-		return result, fmt.Errorf("no kubernetes clusters found in the source cluster")
+	src := k8sSourceCluster{
+		nodeGroupInfos: aggregateK8sNodeGroupInfo(k8sClusterInfo),
+		architecture:   extractK8sNodeInfoValue(k8sClusterInfo, "architecture"),
+		version:        extractK8sNodeInfoValue(k8sClusterInfo, "kubeletVersion", "kubelet_version"),
+		cpCount:        max(k8sClusterInfo.NodeCount.ControlPlane, 1),
+		workerType:     NodeTypeWorker,
+		workerCount:    k8sClusterInfo.NodeCount.Worker,
 	}
 
-	// Note: for the time being, use the 1st control plane in the list
-	k8sClusterInfo := Kubernetes{}
-	for _, k8s := range k8sInfoList.Servers {
-		if k8s.NodeCount.Total == 0 {
-			continue
-		}
+	// * Note: In a cluster without dedicated workers, workloads run on the control-plane nodes,
+	// * so the worker node group is sized from the control-plane nodes.
+	if src.workerCount == 0 {
+		src.workerType = NodeTypeControlPlane
+		src.workerCount = src.cpCount
+	}
+	return src, nil
+}
+
+// RecommendK8sCluster recommends a K8s cluster and its node groups based on honeybee source cluster data
+// * Note: for the time being, the 1st kubernetes cluster in the list is used.
+func RecommendK8sCluster(provider, region string, k8sInfoList KubernetesInfoList) (RecommendedK8sCluster, error) {
+	var emptyRes = RecommendedK8sCluster{}
 
-		k8sClusterInfo = k8s
-		break
+	csp := strings.ToLower(provider)
+	region = strings.ToLower(region)
+
+	/*
+	 * [Input] Select the source cluster and aggregate node information
+	 */
+	src, err := analyzeK8sSourceCluster(k8sInfoList)
+	if err != nil {
+		return emptyRes, err
 	}
 
-	log.Debug().Msgf("Selected kubernetes cluster information: %+v", k8sClusterInfo)
+	/*
+	 * [Process]
+	 */
+	targetCluster, cpSpec, warnings := recommendK8sControlPlane(csp, region, src)
+	workerNodeGroup, workerSpec, workerWarnings := recommendK8sWorkerNodeGroup(csp, region, src)
+	warnings = append(warnings, workerWarnings...)
+
+	/*
+	 * [Output]
+	 */
+	result := RecommendedK8sCluster{
+		Status:           string(FullyRecommended),
+		SourceVersion:    src.version,
+		TargetCluster:    targetCluster,
+		TargetNodeGroups: []tbmodel.K8sNodeGroupReq{workerNodeGroup},
+	}
 
-	// TODO: Implement spec recommendation logic based on actual resource requirements
-	// For now, using hardcoded spec for testing
+	switch {
+	case cpSpec.Id == "" && workerSpec.Id == "":
+		result.Status = string(NothingRecommended)
+	case len(warnings) > 0:
+		result.Status = string(PartiallyRecommended)
+	}
 
-	k8sControlPlaneNodeCount := k8sClusterInfo.NodeCount.ControlPlane
+	result.Description = fmt.Sprintf("Recommended K8s cluster (version: %s) | system: %d x %s | worker: %d x %s",
+		targetCluster.Version, src.cpCount, cpSpec.CspSpecName, src.workerCount, workerSpec.CspSpecName)
+	if len(warnings) > 0 {
+		result.Description += " | " + strings.Join(warnings, "; ")
+	}
 
-	// Build K8sClusterDynamicReq
-	recommendedControlPlane := tbmodel.K8sClusterDynamicReq{
-		Name:            "recommended-k8s-cluster",
-		NodeGroupName:   "recommended-worker-nodegroup",
-		ConnectionName:  fmt.Sprintf("%s-%s", provider, region),
-		SpecId:          "azure+koreacentral+standard_b4ms", // TODO: Search and assign a recommended spec ID
-		ImageId:         "default",                          // TODO: Search and assign a recommended image ID
-		Version:         "1.33",
-		DesiredNodeSize: k8sControlPlaneNodeCount,
-		MinNodeSize:     1,
-		MaxNodeSize:     k8sControlPlaneNodeCount,
+	log.Info().
+		Str("clusterName", targetCluster.Name).
+		Str("version", targetCluster.Version).
+		Str("systemSpecId", targetCluster.SpecId).
+		Str("workerSpecId", workerNodeGroup.SpecId).
+		Str("status", result.Status).
+		Msg("K8s cluster recommendation completed")
+
+	return result, nil
+}
+
+// recommendK8sControlPlane recommends the cluster with the system (control-plane sized) node group.
+// It returns the cluster, the spec of the system node group, and the warnings on what is not resolved.
+func recommendK8sControlPlane(csp, region string, src k8sSourceCluster) (tbmodel.K8sClusterDynamicReq, cloudmodel.SpecInfo, []string) {
+	var warnings []string
+
+	// 1. Map the source version to the nearest supported managed K8s version
+	targetVersion, err := RecommendK8sVersion(csp, region, src.version)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to recommend K8s version (source version: %s)", src.version)
+		warnings = append(warnings, fmt.Sprintf("version not resolved: %v", err))
+	}
+
+	// 2. Recommend the spec of the system (control-plane sized) node group
+	cpu, memoryGiB, warning := k8sNodeSize(NodeTypeControlPlane, src.nodeGroupInfos[NodeTypeControlPlane])
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+	cpSpec, err := recommendK8sNodeSpec(csp, region, NodeTypeControlPlane, cpu, memoryGiB, src.architecture)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to recommend a spec for the control-plane node group")
+		warnings = append(warnings, fmt.Sprintf("control-plane spec not found: %v", err))
+	}
+
+	// 3. Build the cluster with the system node group
+	// * Note: "default" lets the CSP choose the managed node image and root disk type.
+	targetCluster := tbmodel.K8sClusterDynamicReq{
+		Name:            defaultK8sClusterName,
+		NodeGroupName:   defaultK8sSystemNodeGroupName,
+		ConnectionName:  fmt.Sprintf("%s-%s", csp, region),
+		SpecId:          cpSpec.Id,
+		ImageId:         "default",
+		Version:         targetVersion,
+		DesiredNodeSize: src.cpCount,
+		MinNodeSize:     src.cpCount,
+		MaxNodeSize:     src.cpCount,
 		OnAutoScaling:   "false",
 		RootDiskType:    "default",
 		RootDiskSize:    0,
-		Description:     fmt.Sprintf("A recommended control plane with %d nodes", k8sControlPlaneNodeCount),
+		Description:     fmt.Sprintf("A recommended cluster (source version: %s) with a system node group of %d nodes", src.version, src.cpCount),
+	}
+	return targetCluster, cpSpec, warnings
+}
+
+// recommendK8sWorkerNodeGroup recommends the worker node group with autoscaling bounds from the observed worker count.
+// It returns the node group, its spec, and the warnings on what is not resolved.
+func recommendK8sWorkerNodeGroup(csp, region string, src k8sSourceCluster) (tbmodel.K8sNodeGroupReq, cloudmodel.SpecInfo, []string) {
+	var warnings []string
+
+	cpu, memoryGiB, warning := k8sNodeSize(NodeTypeWorker, src.nodeGroupInfos[src.workerType])
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+	workerSpec, err := recommendK8sNodeSpec(csp, region, NodeTypeWorker, cpu, memoryGiB, src.architecture)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to recommend a spec for the worker node group")
+		warnings = append(warnings, fmt.Sprintf("worker spec not found: %v", err))
+	}
+
+	maxNodeSize := src.workerCount * k8sAutoscalingMaxFactor
+	workerNodeGroup := tbmodel.K8sNodeGroupReq{
+		Name:            defaultK8sWorkerNodeGroupName,
+		SpecId:          workerSpec.Id,
+		ImageId:         "default",
+		DesiredNodeSize: src.workerCount,
+		MinNodeSize:     src.workerCount,
+		MaxNodeSize:     maxNodeSize,
+		OnAutoScaling:   "true",
+		RootDiskType:    "default",
+		RootDiskSize:    0,
+		Description:     fmt.Sprintf("A recommended worker node group with %d nodes (autoscaling: %d-%d)", src.workerCount, src.workerCount, maxNodeSize),
+	}
+	return workerNodeGroup, workerSpec, warnings
+}
+
+// RecommendK8sControlPlane recommends K8s control plane configuration based on honeybee source cluster data
+// * Note: only the control plane is recommended; the worker node group is not.
+func RecommendK8sControlPlane(provider, region string, k8sInfoList KubernetesInfoList) (tbmodel.K8sClusterDynamicReq, error) {
+	src, err := analyzeK8sSourceCluster(k8sInfoList)
+	if err != nil {
+		return tbmodel.K8sClusterDynamicReq{}, err
+	}
+
+	targetCluster, _, warnings := recommendK8sControlPlane(strings.ToLower(provider), strings.ToLower(region), src)
+	if targetCluster.SpecId == "" {
+		return tbmodel.K8sClusterDynamicReq{}, fmt.Errorf("no spec recommended for the K8s control plane: %s", strings.Join(warnings, "; "))
 	}
 
 	log.Info().
-		Str("clusterName", recommendedControlPlane.Name).
-		Str("specId", recommendedControlPlane.SpecId).
-		Int("desiredNodeSize", recommendedControlPlane.DesiredNodeSize).
+		Str("clusterName", targetCluster.Name).
+		Str("specId", targetCluster.SpecId).
+		Int("desiredNodeSize", targetCluster.DesiredNodeSize).
 		Msg("K8s control plane recommendation completed")
 
-	return recommendedControlPlane, nil
+	return targetCluster, nil
 }
 
 // RecommendK8sNodeGroup recommends K8s worker node group configuration based on honeybee source cluster data
+// * Note: only the worker node group is recommended; the control plane is not.
 func RecommendK8sNodeGroup(provider, region string, k8sInfoList KubernetesInfoList) (tbmodel.K8sNodeGroupReq, error) {
-	var emptyRes = tbmodel.K8sNodeGroupReq{}
+	src, err := analyzeK8sSourceCluster(k8sInfoList)
+	if err != nil {
+		return tbmodel.K8sNodeGroupReq{}, err
+	}
 
-	// TODO: Use the following code when implementing actual API calls
-	// client := resty.New()
-	// client.SetBaseURL(config.Tumblebug.RestUrl)
-	// client.SetBasicAuth(config.Tumblebug.API.Username, config.Tumblebug.API.Password)
+	workerNodeGroup, _, warnings := recommendK8sWorkerNodeGroup(strings.ToLower(provider), strings.ToLower(region), src)
+	if workerNodeGroup.SpecId == "" {
+		return tbmodel.K8sNodeGroupReq{}, fmt.Errorf("no spec recommended for the K8s worker node group: %s", strings.Join(warnings, "; "))
+	}
+
+	log.Info().
+		Str("nodeGroupName", workerNodeGroup.Name).
+		Str("specId", workerNodeGroup.SpecId).
+		Int("desiredNodeSize", workerNodeGroup.DesiredNodeSize).
+		Msg("K8s worker node group recommendation completed")
+
+	return workerNodeGroup, nil
+}
+
+// RecommendK8sVersion maps the source K8s version to the nearest managed K8s version supported in the provider and region.
+// The same minor version is preferred, then the closest newer one (upgrade path), then the latest older one.
+func RecommendK8sVersion(provider, region, sourceVersion string) (string, error) {
+
+	availableVersions, err := tbclient.NewSession().ReadAvailableK8sVersions(provider, region)
+	if err != nil {
+		return "", fmt.Errorf("failed to read available K8s versions: %w", err)
+	}
+	if len(availableVersions) == 0 {
+		return "", fmt.Errorf("no K8s versions available in %s/%s", provider, region)
+	}
+
+	type candidate struct {
+		name         string
+		major, minor int
+	}
+	var candidates []candidate
+	for _, v := range availableVersions {
+		name := v.Name
+		if name == "" {
+			name = v.Id
+		}
+		major, minor, ok := parseK8sVersion(name)
+		if !ok {
+			log.Debug().Msgf("skip unparsable K8s version: %s", name)
+			continue
+		}
+		candidates = append(candidates, candidate{name: name, major: major, minor: minor})
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no parsable K8s versions available in %s/%s", provider, region)
+	}
+
+	// Sort in ascending order of version
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].major != candidates[j].major {
+			return candidates[i].major < candidates[j].major
+		}
+		return candidates[i].minor < candidates[j].minor
+	})
+
+	srcMajor, srcMinor, ok := parseK8sVersion(sourceVersion)
+	if !ok {
+		// Unknown source version: use the latest supported version
+		latest := candidates[len(candidates)-1].name
+		log.Warn().Msgf("unknown source K8s version (%s), using the latest supported version: %s", sourceVersion, latest)
+		return latest, nil
+	}
+
+	// The same or the closest newer version
+	for _, c := range candidates {
+		if c.major > srcMajor || (c.major == srcMajor && c.minor >= srcMinor) {
+			log.Debug().Msgf("K8s version mapped: %s -> %s", sourceVersion, c.name)
+			return c.name, nil
+		}
+	}
+
+	// All supported versions are older than the source: use the latest one
+	latest := candidates[len(candidates)-1].name
+	log.Warn().Msgf("source K8s version (%s) is newer than all supported versions, using %s", sourceVersion, latest)
+	return latest, nil
+}
 
-	// Collect control plane node information
-	// cpInfo := &NodeGroupInfo{Count: 0, TotalCPU: 0, TotalMemory: 0}
+// selectK8sCluster selects the 1st kubernetes cluster having nodes in the list
+func selectK8sCluster(k8sInfoList KubernetesInfoList) (Kubernetes, error) {
 
-	log.Info().Int("totalServers", len(k8sInfoList.Servers)).Msg("Processing K8s servers for worker node group recommendation")
+	log.Info().Int("totalServers", len(k8sInfoList.Servers)).Msg("Processing K8s servers for cluster recommendation")
 
-	// Count kubernetes clusters to check if kubernetes cluster exists
-	k8sCount := 0
 	for i, k8s := range k8sInfoList.Servers {
 		if k8s.NodeCount.Total == 0 {
 			log.Warn().
@@ -175,55 +401,127 @@ func RecommendK8sNodeGroup(provider, region string, k8sInfoList KubernetesInfoLi
 				Msg("Server has zero total nodes, skipping")
 			continue
 		}
-		k8sCount += 1
-	}
 
-	// Validate kubernetes cluster existence
-	if k8sCount == 0 {
-		log.Warn().Msg("No kubernetes clusters found in the source cluster, skipping recommendation")
-		return emptyRes, fmt.Errorf("no kubernetes clusters found in the source cluster")
+		log.Debug().Msgf("Selected kubernetes cluster information: %+v", k8s)
+		return k8s, nil
 	}
 
-	// Note: for the time being, use the 1st kubernetes cluster in the list
-	k8sClusterInfo := Kubernetes{}
-	for _, k8s := range k8sInfoList.Servers {
-		if k8s.NodeCount.Total == 0 {
+	log.Warn().Msg("No kubernetes clusters found in the source cluster, skipping recommendation")
+	return Kubernetes{}, fmt.Errorf("no kubernetes clusters found in the source cluster")
+}
+
+// aggregateK8sNodeGroupInfo aggregates NodeSpec CPU and memory per node type
+func aggregateK8sNodeGroupInfo(k8s Kubernetes) map[NodeType]NodeGroupInfo {
+	infos := map[NodeType]NodeGroupInfo{}
+
+	for _, node := range k8s.Nodes {
+		if node.NodeSpec.CPU == 0 && node.NodeSpec.Memory == 0 {
 			continue
 		}
+		info := infos[node.Type]
+		info.Count++
+		info.TotalCPU += node.NodeSpec.CPU
+		info.TotalMemory += node.NodeSpec.Memory
+		infos[node.Type] = info
+	}
 
-		k8sClusterInfo = k8s
-		break
+	for nodeType, info := range infos {
+		log.Debug().
+			Str("nodeType", string(nodeType)).
+			Int("count", info.Count).
+			Int("avgCPU", info.AvgCPU()).
+			Int("avgMemoryGiB", info.AvgMemoryGiB()).
+			Msg("Aggregated K8s node group information")
 	}
 
-	log.Debug().Msgf("Selected kubernetes cluster information: %+v", k8sClusterInfo)
+	return infos
+}
 
-	// TODO: Implement spec recommendation logic based on actual resource requirements
-	// For now, using hardcoded spec for testing
+// k8sNodeSize returns the average size (vCPU, memory in GiB) of the source nodes of a node type.
+// If the source cluster does not report the node spec, it returns the default size with a warning.
+func k8sNodeSize(nodeType NodeType, info NodeGroupInfo) (int, int, string) {
+	cpu := info.AvgCPU()
+	memoryGiB := info.AvgMemoryGiB()
+	if cpu > 0 && memoryGiB > 0 {
+		return cpu, memoryGiB, ""
+	}
 
-	k8sNodeGroupCount := k8sClusterInfo.NodeCount.Worker
+	warning := fmt.Sprintf("no node spec reported for %s nodes, sized by the default (%d vCPU, %d GiB)",
+		nodeType, defaultK8sNodeCPU, defaultK8sNodeMemoryGiB)
+	log.Warn().Msg(warning)
+	return defaultK8sNodeCPU, defaultK8sNodeMemoryGiB, warning
+}
 
-	// TODO: Implement spec recommendation logic based on actual resource requirements
-	// For now, using hardcoded spec for testing
+// recommendK8sNodeSpec recommends a VM spec for a K8s node group of the size through the same spec search used for VMs
+func recommendK8sNodeSpec(csp, region string, nodeType NodeType, cpu, memoryGiB int, architecture string) (cloudmodel.SpecInfo, error) {
+
+	// Represent the node group as a source node to reuse the VM spec search
+	representativeNode := onpremmodel.NodeProperty{
+		MachineId: fmt.Sprintf("k8s-%s", nodeType),
+		CPU: onpremmodel.CpuProperty{
+			Architecture: architecture,
+			Cpus:         uint32(cpu),
+			Threads:      1,
+		},
+		Memory: onpremmodel.MemoryProperty{
+			TotalSize: uint64(memoryGiB),
+		},
+	}
 
-	// Build K8sNodeGroupReq
-	recommendedNodeGroup := tbmodel.K8sNodeGroupReq{
-		Name:            "recommended-worker-nodegroup",
-		SpecId:          "azure+koreacentral+standard_b4ms", // Hardcoded for testing
-		ImageId:         "default",
-		DesiredNodeSize: k8sNodeGroupCount,
-		MinNodeSize:     1,
-		MaxNodeSize:     k8sNodeGroupCount,
-		OnAutoScaling:   "true",
-		RootDiskType:    "default",
-		RootDiskSize:    0,
-		Description:     fmt.Sprintf("A recommended node group with %d nodes", k8sNodeGroupCount),
+	specList, _, err := RecommendVmSpecs(csp, region, representativeNode, GetDefaultSpecsLimit())
+	if err != nil {
+		return cloudmodel.SpecInfo{}, err
+	}
+	if len(specList) == 0 {
+		return cloudmodel.SpecInfo{}, fmt.Errorf("no VM specs found for %s nodes (%d vCPU, %d GiB)", nodeType, cpu, memoryGiB)
 	}
 
-	log.Info().
-		Str("nodeGroupName", recommendedNodeGroup.Name).
-		Str("specId", recommendedNodeGroup.SpecId).
-		Int("desiredNodeSize", recommendedNodeGroup.DesiredNodeSize).
-		Msg("K8s worker node group recommendation completed")
+	// The list is sorted by proximity to the requested size with cost consideration
+	return specList[0], nil
+}
+
+// extractK8sNodeInfoValue returns the most common value of the given keys in the nodes' NodeInfo
+func extractK8sNodeInfoValue(k8s Kubernetes, keys ...string) string {
+	counts := map[string]int{}
+	best := ""
+
+	for _, node := range k8s.Nodes {
+		nodeInfo, ok := node.NodeInfo.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range keys {
+			value, ok := nodeInfo[key].(string)
+			if !ok || value == "" {
+				continue
+			}
+			counts[value]++
+			if counts[value] > counts[best] {
+				best = value
+			}
+			break
+		}
+	}
+
+	return best
+}
+
+// parseK8sVersion parses major and minor numbers from a K8s version string (e.g., "v1.28.3", "1.30", "1.30.1-aliyun.1")
+func parseK8sVersion(version string) (int, int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(version)), "v")
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return 0, 0, false
+	}
 
-	return recommendedNodeGroup, nil
+	return major, minor, true
 }
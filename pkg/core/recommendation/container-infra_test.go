@@ -0,0 +1,30 @@
+package recommendation
+
+import "testing"
+
+func TestK8sNodeSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		info          NodeGroupInfo
+		wantCPU       int
+		wantMemoryGiB int
+		wantWarning   bool
+	}{
+		{"average rounded up", NodeGroupInfo{Count: 2, TotalCPU: 6, TotalMemory: 12 * 1024}, 3, 6, false},
+		{"partial GiB rounded up", NodeGroupInfo{Count: 1, TotalCPU: 4, TotalMemory: 7800}, 4, 8, false},
+		{"no node spec", NodeGroupInfo{}, defaultK8sNodeCPU, defaultK8sNodeMemoryGiB, true},
+		{"no memory", NodeGroupInfo{Count: 1, TotalCPU: 8}, defaultK8sNodeCPU, defaultK8sNodeMemoryGiB, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, memoryGiB, warning := k8sNodeSize(NodeTypeWorker, tt.info)
+			if cpu != tt.wantCPU || memoryGiB != tt.wantMemoryGiB {
+				t.Errorf("k8sNodeSize() = %d vCPU, %d GiB, want %d vCPU, %d GiB", cpu, memoryGiB, tt.wantCPU, tt.wantMemoryGiB)
+			}
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("k8sNodeSize() warning = %q, wantWarning %v", warning, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,891 @@
+package recommendation
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * Models for K8s workload migration
+ */
+
+// WorkloadTranslationRules specifies how source workloads are rewritten for the target cluster.
+// Empty fields are filled with CSP defaults (see defaultWorkloadTranslationRules).
+type WorkloadTranslationRules struct {
+	StorageClassMap    map[string]string `json:"storageClassMap,omitempty"`    // Source StorageClass -> target StorageClass ("*" for any)
+	IngressClassName   string            `json:"ingressClassName,omitempty"`   // Target ingress class
+	LbAnnotations      map[string]string `json:"lbAnnotations,omitempty"`      // Annotations added to LoadBalancer Services
+	ImageRegistryMap   map[string]string `json:"imageRegistryMap,omitempty"`   // Source registry -> target registry (e.g., "registry.local:5000" -> "123456789012.dkr.ecr.ap-northeast-2.amazonaws.com")
+	ExcludedNamespaces []string          `json:"excludedNamespaces,omitempty"` // Namespaces not to migrate (system namespaces are always excluded)
+}
+
+// WorkloadManifest is a rewritten manifest for the target cluster
+type WorkloadManifest struct {
+	Kind      string                 `json:"kind"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Name      string                 `json:"name"`
+	Changes   []string               `json:"changes,omitempty"` // Rewrites applied to the manifest
+	Manifest  map[string]interface{} `json:"manifest"`
+}
+
+// HelmInstallStep is a step of the Helm install plan
+type HelmInstallStep struct {
+	Order        int               `json:"order"`
+	ReleaseName  string            `json:"releaseName"`
+	Namespace    string            `json:"namespace"`
+	Chart        string            `json:"chart"`
+	ChartVersion string            `json:"chartVersion,omitempty"`
+	AppVersion   string            `json:"appVersion,omitempty"`
+	RepoName     string            `json:"repoName,omitempty"`
+	RepoURL      string            `json:"repoUrl,omitempty"`
+	SetValues    map[string]string `json:"setValues,omitempty"` // Suggested value overrides (e.g., global.storageClass)
+	Command      string            `json:"command"`
+}
+
+// WorkloadMigrationIssue is a resource that cannot be migrated automatically or needs review
+type WorkloadMigrationIssue struct {
+	Severity  string `json:"severity"` // "warning" | "manual"
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"` // e.g., "hostPath-volume", "node-selector"
+	Detail    string `json:"detail,omitempty"`
+}
+
+// WorkloadMigrationPlan is the result of K8s workload migration planning
+type WorkloadMigrationPlan struct {
+	Status          string                   `json:"status"`
+	Description     string                   `json:"description"`
+	TargetCsp       string                   `json:"targetCsp"`
+	Rules           WorkloadTranslationRules `json:"rules"`     // Rules actually applied (with CSP defaults)
+	Manifests       []WorkloadManifest       `json:"manifests"` // Manifests not managed by Helm
+	Bundle          string                   `json:"bundle"`    // Manifests as a multi-document YAML
+	HelmInstallPlan []HelmInstallStep        `json:"helmInstallPlan"`
+	Issues          []WorkloadMigrationIssue `json:"issues"`
+}
+
+// Issue severities
+const (
+	workloadIssueWarning = "warning"
+	workloadIssueManual  = "manual"
+)
+
+// secretValuePlaceholder replaces the values of Secrets, which are not included in the plan
+const secretValuePlaceholder = "<REPLACE_WITH_SECRET_VALUE>"
+
+var (
+	// systemNamespaces are never migrated
+	systemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+	// skippedWorkloadKinds are runtime or cluster-specific resources that are not migrated
+	skippedWorkloadKinds = map[string]bool{
+		"Node": true, "Event": true, "Endpoints": true, "EndpointSlice": true, "Lease": true,
+		"ControllerRevision": true, "ComponentStatus": true, "CSINode": true, "CSIDriver": true,
+	}
+
+	// podSpecPaths are the paths to the pod spec per workload kind
+	podSpecPaths = map[string][]string{
+		"Pod":         {"spec"},
+		"Deployment":  {"spec", "template", "spec"},
+		"StatefulSet": {"spec", "template", "spec"},
+		"DaemonSet":   {"spec", "template", "spec"},
+		"ReplicaSet":  {"spec", "template", "spec"},
+		"Job":         {"spec", "template", "spec"},
+		"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+	}
+
+	// publicImageRegistries are reachable from any cloud and are not rewritten unless mapped explicitly
+	publicImageRegistries = []string{
+		"docker.io", "registry-1.docker.io", "gcr.io", "ghcr.io", "quay.io", "registry.k8s.io",
+		"k8s.gcr.io", "public.ecr.aws", "mcr.microsoft.com",
+	}
+
+	// lbAnnotationPrefixes are LoadBalancer annotations specific to a source environment
+	lbAnnotationPrefixes = []string{
+		"metallb.universe.tf/", "service.beta.kubernetes.io/", "service.kubernetes.io/",
+		"cloud.google.com/load-balancer", "networking.gke.io/", "kube-vip.io/",
+	}
+)
+
+// defaultWorkloadTranslationRules returns the default rules for the target CSP
+func defaultWorkloadTranslationRules(csp string) WorkloadTranslationRules {
+	rules := WorkloadTranslationRules{
+		StorageClassMap:  map[string]string{},
+		LbAnnotations:    map[string]string{},
+		ImageRegistryMap: map[string]string{},
+	}
+
+	switch csp {
+	case "aws":
+		rules.StorageClassMap["*"] = "gp3"
+		rules.IngressClassName = "alb"
+		rules.LbAnnotations["service.beta.kubernetes.io/aws-load-balancer-type"] = "nlb"
+	case "azure":
+		rules.StorageClassMap["*"] = "managed-csi"
+		rules.IngressClassName = "webapprouting.kubernetes.azure.com"
+	case "gcp":
+		rules.StorageClassMap["*"] = "standard-rwo"
+		rules.IngressClassName = "gce"
+	case "alibaba":
+		rules.StorageClassMap["*"] = "alicloud-disk-essd"
+		rules.IngressClassName = "alb"
+	case "ncp":
+		rules.StorageClassMap["*"] = "nks-block-storage"
+		rules.IngressClassName = "alb"
+	default:
+		rules.IngressClassName = "nginx"
+	}
+
+	return rules
+}
+
+// mergeWorkloadTranslationRules fills the empty fields of the given rules with the CSP defaults
+func mergeWorkloadTranslationRules(csp string, rules WorkloadTranslationRules) WorkloadTranslationRules {
+	merged := defaultWorkloadTranslationRules(csp)
+
+	for k, v := range rules.StorageClassMap {
+		merged.StorageClassMap[k] = v
+	}
+	if rules.IngressClassName != "" {
+		merged.IngressClassName = rules.IngressClassName
+	}
+	for k, v := range rules.LbAnnotations {
+		merged.LbAnnotations[k] = v
+	}
+	for k, v := range rules.ImageRegistryMap {
+		merged.ImageRegistryMap[k] = v
+	}
+	merged.ExcludedNamespaces = append(merged.ExcludedNamespaces, rules.ExcludedNamespaces...)
+
+	return merged
+}
+
+// RecommendK8sWorkloadMigration plans the migration of K8s workloads and Helm releases to the target cluster.
+// It rewrites StorageClass, ingress class, LoadBalancer annotations, and image registries,
+// and flags resources that cannot be migrated automatically (e.g., hostPath volumes, node selectors).
+// * Note: for the time being, the 1st kubernetes cluster in the list is used.
+func RecommendK8sWorkloadMigration(provider string, k8sInfoList KubernetesInfoList, rules WorkloadTranslationRules) (WorkloadMigrationPlan, error) {
+	var emptyRes = WorkloadMigrationPlan{}
+
+	csp := strings.ToLower(provider)
+
+	k8sClusterInfo, err := selectK8sCluster(k8sInfoList)
+	if err != nil {
+		return emptyRes, err
+	}
+
+	plan := WorkloadMigrationPlan{
+		TargetCsp:       csp,
+		Rules:           mergeWorkloadTranslationRules(csp, rules),
+		Manifests:       []WorkloadManifest{},
+		HelmInstallPlan: []HelmInstallStep{},
+		Issues:          []WorkloadMigrationIssue{},
+	}
+
+	excluded := map[string]bool{}
+	for _, ns := range append(append([]string{}, systemNamespaces...), plan.Rules.ExcludedNamespaces...) {
+		excluded[ns] = true
+	}
+
+	/*
+	 * [Process]
+	 */
+
+	// 1. Rewrite the workloads not managed by Helm
+	for _, obj := range flattenWorkloads(k8sClusterInfo.Workloads) {
+		kind, namespace, name := objectIdentity(obj)
+		if kind == "" || name == "" {
+			continue
+		}
+		if skippedWorkloadKinds[kind] || excluded[namespace] {
+			continue
+		}
+		if isOwnedObject(obj) {
+			// Created by a controller (e.g., Pods of a ReplicaSet); migrated through its owner
+			continue
+		}
+		if helmRelease := helmReleaseOf(obj); helmRelease != "" {
+			log.Debug().Msgf("skip %s %s/%s managed by Helm release %s", kind, namespace, name, helmRelease)
+			continue
+		}
+
+		manifest, changes, issues := translateWorkload(obj, kind, namespace, name, plan.Rules)
+		plan.Issues = append(plan.Issues, issues...)
+		if manifest == nil {
+			continue
+		}
+
+		plan.Manifests = append(plan.Manifests, WorkloadManifest{
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+			Changes:   changes,
+			Manifest:  manifest,
+		})
+	}
+
+	// Apply cluster-scoped and configuration resources first
+	sort.SliceStable(plan.Manifests, func(i, j int) bool {
+		return workloadApplyOrder(plan.Manifests[i].Kind) < workloadApplyOrder(plan.Manifests[j].Kind)
+	})
+
+	// 2. Build the Helm install plan
+	if k8sClusterInfo.Helm != nil {
+		plan.HelmInstallPlan, plan.Issues = buildHelmInstallPlan(*k8sClusterInfo.Helm, plan.Rules, excluded, plan.Issues)
+	}
+
+	// 3. Render the bundle
+	var docs []string
+	for _, m := range plan.Manifests {
+		doc, err := common.ConvertToOutput("yaml", m.Manifest)
+		if err != nil {
+			log.Warn().Err(err).Msgf("failed to render %s %s/%s", m.Kind, m.Namespace, m.Name)
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	plan.Bundle = strings.Join(docs, "---\n")
+
+	/*
+	 * [Output]
+	 */
+	manualCount := 0
+	for _, issue := range plan.Issues {
+		if issue.Severity == workloadIssueManual {
+			manualCount++
+		}
+	}
+
+	switch {
+	case len(plan.Manifests) == 0 && len(plan.HelmInstallPlan) == 0:
+		plan.Status = string(NothingRecommended)
+	case manualCount > 0:
+		plan.Status = string(PartiallyRecommended)
+	default:
+		plan.Status = string(FullyRecommended)
+	}
+	plan.Description = fmt.Sprintf("%d manifests, %d Helm releases, %d issues (%d require manual migration)",
+		len(plan.Manifests), len(plan.HelmInstallPlan), len(plan.Issues), manualCount)
+
+	log.Info().
+		Int("manifests", len(plan.Manifests)).
+		Int("helmReleases", len(plan.HelmInstallPlan)).
+		Int("issues", len(plan.Issues)).
+		Msg("K8s workload migration planning completed")
+
+	return plan, nil
+}
+
+// translateWorkload rewrites a source object for the target cluster.
+// It returns nil manifest if the object should not be included in the bundle.
+func translateWorkload(src map[string]interface{}, kind, namespace, name string, rules WorkloadTranslationRules) (map[string]interface{}, []string, []WorkloadMigrationIssue) {
+	var changes []string
+	var issues []WorkloadMigrationIssue
+
+	newIssue := func(severity, reason, detail string) {
+		issues = append(issues, WorkloadMigrationIssue{
+			Severity: severity, Kind: kind, Namespace: namespace, Name: name, Reason: reason, Detail: detail,
+		})
+	}
+
+	obj := deepCopyJSONMap(src)
+	sanitizeObject(obj)
+
+	switch kind {
+	case "StorageClass":
+		newIssue(workloadIssueWarning, "storage-class", "source StorageClass is not migrated; the target cluster's StorageClass is used instead")
+		return nil, nil, issues
+
+	case "PersistentVolume":
+		newIssue(workloadIssueManual, "persistent-volume", "PersistentVolumes are bound to the source storage; migrate the data and let PVCs provision new volumes")
+		return nil, nil, issues
+
+	case "Secret":
+		switch secretType, _ := getNestedString(obj, "type"); secretType {
+		case "kubernetes.io/service-account-token", "helm.sh/release.v1":
+			// Created by the target cluster (or by Helm through the Helm install plan)
+			return nil, nil, issues
+		}
+		if keys := redactSecretData(obj); len(keys) > 0 {
+			changes = append(changes, fmt.Sprintf("values of %d keys replaced with placeholders", len(keys)))
+			newIssue(workloadIssueManual, "secret-value",
+				fmt.Sprintf("secret values are not included in the plan; set the values of %s in the target cluster (e.g., by a sealed secret or an external secret store)", strings.Join(keys, ", ")))
+		}
+
+	case "ConfigMap":
+		if name == "kube-root-ca.crt" {
+			return nil, nil, issues
+		}
+
+	case "ServiceAccount":
+		if name == "default" {
+			return nil, nil, issues
+		}
+
+	case "PersistentVolumeClaim":
+		if spec, ok := getNestedMap(obj, "spec"); ok {
+			delete(spec, "volumeName")
+			if change := rewriteStorageClass(spec, rules); change != "" {
+				changes = append(changes, change)
+			}
+		}
+		newIssue(workloadIssueWarning, "data-migration", "a new volume is provisioned; migrate the data separately")
+
+	case "Service":
+		spec, _ := getNestedMap(obj, "spec")
+		if spec != nil {
+			delete(spec, "clusterIP")
+			delete(spec, "clusterIPs")
+			delete(spec, "healthCheckNodePort")
+			if svcType, _ := spec["type"].(string); svcType == "LoadBalancer" {
+				delete(spec, "loadBalancerIP")
+				changes = append(changes, rewriteLbAnnotations(obj, rules)...)
+			}
+		}
+
+	case "Ingress":
+		if spec, ok := getNestedMap(obj, "spec"); ok && rules.IngressClassName != "" {
+			old, _ := spec["ingressClassName"].(string)
+			if annotations, ok := getNestedMap(obj, "metadata", "annotations"); ok {
+				if v, ok := annotations["kubernetes.io/ingress.class"].(string); ok {
+					old = v
+					delete(annotations, "kubernetes.io/ingress.class")
+				}
+			}
+			if old != rules.IngressClassName {
+				spec["ingressClassName"] = rules.IngressClassName
+				changes = append(changes, fmt.Sprintf("ingressClassName: %q -> %q", old, rules.IngressClassName))
+			}
+		}
+		if tls, ok := getNestedSlice(obj, "spec", "tls"); ok && len(tls) > 0 {
+			newIssue(workloadIssueWarning, "tls-secret", "TLS secrets must exist in the target cluster (or be replaced with certificates of the target CSP)")
+		}
+	}
+
+	// Rewrite the pod spec of workloads
+	if path, ok := podSpecPaths[kind]; ok {
+		if podSpec, ok := getNestedMap(obj, path...); ok {
+			changes = append(changes, rewritePodSpecImages(podSpec, rules, newIssue)...)
+			checkPodSpecPortability(podSpec, newIssue)
+		}
+	}
+
+	// Rewrite StorageClass of the volume claim templates of StatefulSets
+	if kind == "StatefulSet" {
+		if templates, ok := getNestedSlice(obj, "spec", "volumeClaimTemplates"); ok {
+			for _, t := range templates {
+				if tmpl, ok := t.(map[string]interface{}); ok {
+					if spec, ok := getNestedMap(tmpl, "spec"); ok {
+						if change := rewriteStorageClass(spec, rules); change != "" {
+							changes = append(changes, "volumeClaimTemplates "+change)
+						}
+					}
+				}
+			}
+			newIssue(workloadIssueWarning, "data-migration", "new volumes are provisioned for volumeClaimTemplates; migrate the data separately")
+		}
+	}
+
+	return obj, changes, issues
+}
+
+// redactSecretData replaces the values of a Secret (data and stringData) with placeholders in stringData,
+// so that the plan does not leak the secret values. It returns the sorted keys replaced.
+func redactSecretData(obj map[string]interface{}) []string {
+	var keys []string
+	for _, field := range []string{"data", "stringData"} {
+		if values, ok := obj[field].(map[string]interface{}); ok {
+			for key := range values {
+				keys = append(keys, key)
+			}
+		}
+		delete(obj, field)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	keys = deduplicateSlice(keys)
+	sort.Strings(keys)
+
+	placeholders := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		placeholders[key] = secretValuePlaceholder
+	}
+	obj["stringData"] = placeholders
+	return keys
+}
+
+// rewriteStorageClass rewrites spec.storageClassName by the rules
+func rewriteStorageClass(spec map[string]interface{}, rules WorkloadTranslationRules) string {
+	old, _ := spec["storageClassName"].(string)
+
+	target, ok := rules.StorageClassMap[old]
+	if !ok {
+		target, ok = rules.StorageClassMap["*"]
+	}
+	if !ok || target == "" || target == old {
+		return ""
+	}
+
+	spec["storageClassName"] = target
+	return fmt.Sprintf("storageClassName: %q -> %q", old, target)
+}
+
+// rewriteLbAnnotations replaces the source-specific LoadBalancer annotations with those for the target CSP
+func rewriteLbAnnotations(obj map[string]interface{}, rules WorkloadTranslationRules) []string {
+	var changes []string
+
+	metadata, _ := getNestedMap(obj, "metadata")
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+	}
+
+	for key := range annotations {
+		for _, prefix := range lbAnnotationPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				delete(annotations, key)
+				changes = append(changes, fmt.Sprintf("annotation removed: %s", key))
+				break
+			}
+		}
+	}
+	for key, value := range rules.LbAnnotations {
+		annotations[key] = value
+		changes = append(changes, fmt.Sprintf("annotation added: %s=%s", key, value))
+	}
+
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+
+	sort.Strings(changes)
+	return changes
+}
+
+// rewritePodSpecImages rewrites the image registry of all containers in the pod spec
+func rewritePodSpecImages(podSpec map[string]interface{}, rules WorkloadTranslationRules, newIssue func(severity, reason, detail string)) []string {
+	var changes []string
+
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		containers, _ := podSpec[field].([]interface{})
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			image, _ := container["image"].(string)
+			if image == "" {
+				continue
+			}
+
+			registry, repository := splitImageRegistry(image)
+			if target, ok := rules.ImageRegistryMap[registry]; ok && target != "" {
+				newImage := strings.TrimSuffix(target, "/") + "/" + repository
+				container["image"] = newImage
+				changes = append(changes, fmt.Sprintf("image: %s -> %s", image, newImage))
+				continue
+			}
+
+			if !isPublicImageRegistry(registry) {
+				newIssue(workloadIssueManual, "private-registry",
+					fmt.Sprintf("image %s is in a private registry (%s) not mapped to the target; push the image and set imageRegistryMap", image, registry))
+			}
+		}
+	}
+
+	return changes
+}
+
+// checkPodSpecPortability flags pod spec settings bound to the source nodes
+func checkPodSpecPortability(podSpec map[string]interface{}, newIssue func(severity, reason, detail string)) {
+
+	if volumes, ok := podSpec["volumes"].([]interface{}); ok {
+		for _, v := range volumes {
+			volume, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			volumeName, _ := volume["name"].(string)
+			if hostPath, ok := volume["hostPath"].(map[string]interface{}); ok {
+				path, _ := hostPath["path"].(string)
+				newIssue(workloadIssueManual, "hostPath-volume", fmt.Sprintf("volume %s uses hostPath %s; data on the source node is not migrated", volumeName, path))
+			}
+			if _, ok := volume["local"]; ok {
+				newIssue(workloadIssueManual, "local-volume", fmt.Sprintf("volume %s uses a local volume bound to a source node", volumeName))
+			}
+		}
+	}
+
+	if nodeSelector, ok := podSpec["nodeSelector"].(map[string]interface{}); ok && len(nodeSelector) > 0 {
+		newIssue(workloadIssueManual, "node-selector", fmt.Sprintf("nodeSelector %v must match labels of the target nodes", nodeSelector))
+	}
+	if nodeName, ok := podSpec["nodeName"].(string); ok && nodeName != "" {
+		delete(podSpec, "nodeName")
+		newIssue(workloadIssueWarning, "node-name", fmt.Sprintf("nodeName %s removed; the pod is scheduled by the target cluster", nodeName))
+	}
+	if _, ok := getNestedMap(podSpec, "affinity", "nodeAffinity"); ok {
+		newIssue(workloadIssueManual, "node-affinity", "nodeAffinity must match labels of the target nodes")
+	}
+	if hostNetwork, ok := podSpec["hostNetwork"].(bool); ok && hostNetwork {
+		newIssue(workloadIssueWarning, "host-network", "hostNetwork is used; check port conflicts and security policies of the target cluster")
+	}
+}
+
+// buildHelmInstallPlan builds the Helm install plan from the source Helm releases
+func buildHelmInstallPlan(helm Helm, rules WorkloadTranslationRules, excluded map[string]bool, issues []WorkloadMigrationIssue) ([]HelmInstallStep, []WorkloadMigrationIssue) {
+	steps := []HelmInstallStep{}
+
+	releases := make([]Release, 0, len(helm.Release))
+	for _, r := range helm.Release {
+		if excluded[r.Namespace] {
+			continue
+		}
+		releases = append(releases, r)
+	}
+
+	// Install in the order of the source releases were updated, to respect implicit dependencies
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Updated.Before(releases[j].Updated)
+	})
+
+	for _, r := range releases {
+		chart, version := splitHelmChartNameVersion(r.ChartNameVersion)
+
+		step := HelmInstallStep{
+			Order:        len(steps) + 1,
+			ReleaseName:  r.Name,
+			Namespace:    r.Namespace,
+			Chart:        chart,
+			ChartVersion: version,
+			AppVersion:   r.AppVersion,
+			SetValues:    map[string]string{},
+		}
+
+		// * Note: A release does not record its repository.
+		// * Use it only if there is a single repository or a repository named after the chart.
+		for _, repo := range helm.Repo {
+			if len(helm.Repo) == 1 || repo.Name == chart {
+				step.RepoName = repo.Name
+				step.RepoURL = repo.URL
+
+				// Credentials in the repository URL are not included in the plan
+				if u, err := url.Parse(repo.URL); err == nil && u.User != nil {
+					u.User = nil
+					step.RepoURL = u.String()
+					issues = append(issues, WorkloadMigrationIssue{
+						Severity: workloadIssueManual, Kind: "HelmRelease", Namespace: r.Namespace, Name: r.Name,
+						Reason: "helm-repository-credentials", Detail: fmt.Sprintf("credentials of repository %s are not included in the plan; add the repository with its credentials (e.g., helm repo add --username)", repo.Name),
+					})
+				}
+				break
+			}
+		}
+		if step.RepoName == "" {
+			issues = append(issues, WorkloadMigrationIssue{
+				Severity: workloadIssueManual, Kind: "HelmRelease", Namespace: r.Namespace, Name: r.Name,
+				Reason: "helm-repository", Detail: fmt.Sprintf("repository of chart %s is not resolved; add the repository and set the chart reference", chart),
+			})
+		}
+		if !strings.EqualFold(r.Status, "deployed") {
+			issues = append(issues, WorkloadMigrationIssue{
+				Severity: workloadIssueWarning, Kind: "HelmRelease", Namespace: r.Namespace, Name: r.Name,
+				Reason: "helm-release-status", Detail: fmt.Sprintf("release status is %s in the source cluster", r.Status),
+			})
+		}
+
+		// Suggested overrides for the common global values of charts
+		if target := rules.StorageClassMap["*"]; target != "" {
+			step.SetValues["global.storageClass"] = target
+		}
+		for _, target := range rules.ImageRegistryMap {
+			if target != "" && len(rules.ImageRegistryMap) == 1 {
+				step.SetValues["global.imageRegistry"] = target
+			}
+		}
+
+		chartRef := chart
+		if step.RepoName != "" {
+			chartRef = step.RepoName + "/" + chart
+		}
+		// * Note: The names and the values come from the source cluster and the rules, so they are quoted for the shell.
+		command := fmt.Sprintf("helm upgrade --install %s %s -n %s --create-namespace",
+			shellQuote(r.Name), shellQuote(chartRef), shellQuote(r.Namespace))
+		if version != "" {
+			command += " --version " + shellQuote(version)
+		}
+		keys := make([]string, 0, len(step.SetValues))
+		for k := range step.SetValues {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			command += " --set " + shellQuote(k+"="+escapeHelmSetValue(step.SetValues[k]))
+		}
+		step.Command = command
+
+		steps = append(steps, step)
+	}
+
+	return steps, issues
+}
+
+/*
+ * Helpers for unstructured K8s objects
+ */
+
+// flattenWorkloads extracts K8s objects from the collected workloads.
+// Each value can be an object, a list of objects, or a List object having "items".
+func flattenWorkloads(workloads map[string]interface{}) []map[string]interface{} {
+	var objects []map[string]interface{}
+
+	// Sort keys for a deterministic output
+	keys := make([]string, 0, len(workloads))
+	for k := range workloads {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var collect func(kindHint string, v interface{})
+	collect = func(kindHint string, v interface{}) {
+		switch val := v.(type) {
+		case []interface{}:
+			for _, item := range val {
+				collect(kindHint, item)
+			}
+		case map[string]interface{}:
+			if items, ok := val["items"].([]interface{}); ok {
+				collect(kindHint, items)
+				return
+			}
+			if _, ok := val["metadata"]; !ok {
+				return
+			}
+			if _, ok := val["kind"]; !ok && kindHint != "" {
+				// Set the kind to a copy, not to modify the caller's workloads
+				obj := make(map[string]interface{}, len(val)+1)
+				for k, v := range val {
+					obj[k] = v
+				}
+				obj["kind"] = kindHint
+				val = obj
+			}
+			objects = append(objects, val)
+		}
+	}
+
+	for _, k := range keys {
+		collect(kindFromWorkloadKey(k), workloads[k])
+	}
+
+	return objects
+}
+
+// kindFromWorkloadKey derives a kind from a workload key (e.g., "deployments" -> "Deployment")
+func kindFromWorkloadKey(key string) string {
+	known := map[string]string{
+		"pods": "Pod", "deployments": "Deployment", "statefulsets": "StatefulSet", "daemonsets": "DaemonSet",
+		"replicasets": "ReplicaSet", "jobs": "Job", "cronjobs": "CronJob", "services": "Service",
+		"ingresses": "Ingress", "configmaps": "ConfigMap", "secrets": "Secret", "serviceaccounts": "ServiceAccount",
+		"persistentvolumeclaims": "PersistentVolumeClaim", "persistentvolumes": "PersistentVolume",
+		"storageclasses": "StorageClass", "namespaces": "Namespace",
+	}
+	if kind, ok := known[strings.ToLower(key)]; ok {
+		return kind
+	}
+	return ""
+}
+
+// objectIdentity returns kind, namespace, and name of an object
+func objectIdentity(obj map[string]interface{}) (string, string, string) {
+	kind, _ := obj["kind"].(string)
+	namespace, _ := getNestedString(obj, "metadata", "namespace")
+	name, _ := getNestedString(obj, "metadata", "name")
+	return kind, namespace, name
+}
+
+// isOwnedObject checks if an object is created by a controller
+func isOwnedObject(obj map[string]interface{}) bool {
+	owners, ok := getNestedSlice(obj, "metadata", "ownerReferences")
+	return ok && len(owners) > 0
+}
+
+// helmReleaseOf returns the Helm release managing the object, if any
+func helmReleaseOf(obj map[string]interface{}) string {
+	if release, ok := getNestedString(obj, "metadata", "annotations", "meta.helm.sh/release-name"); ok {
+		return release
+	}
+	if managedBy, ok := getNestedString(obj, "metadata", "labels", "app.kubernetes.io/managed-by"); ok && managedBy == "Helm" {
+		if instance, ok := getNestedString(obj, "metadata", "labels", "app.kubernetes.io/instance"); ok {
+			return instance
+		}
+		return "unknown"
+	}
+	return ""
+}
+
+// sanitizeObject removes runtime fields assigned by the source cluster
+func sanitizeObject(obj map[string]interface{}) {
+	delete(obj, "status")
+
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink", "ownerReferences"} {
+		delete(metadata, field)
+	}
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		delete(annotations, "deployment.kubernetes.io/revision")
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+}
+
+// workloadApplyOrder returns the order to apply a kind (lower first)
+func workloadApplyOrder(kind string) int {
+	switch kind {
+	case "Namespace":
+		return 0
+	case "ServiceAccount", "Role", "ClusterRole", "RoleBinding", "ClusterRoleBinding":
+		return 1
+	case "ConfigMap", "Secret":
+		return 2
+	case "PersistentVolumeClaim":
+		return 3
+	case "Service":
+		return 4
+	case "Ingress":
+		return 6
+	default:
+		return 5
+	}
+}
+
+// splitImageRegistry splits an image reference into registry and repository (with tag or digest)
+func splitImageRegistry(image string) (string, string) {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0], parts[1]
+	}
+	// Docker Hub (e.g., "nginx:1.25", "bitnami/redis:7")
+	return "docker.io", image
+}
+
+// isPublicImageRegistry checks if a registry is a well-known public registry
+func isPublicImageRegistry(registry string) bool {
+	for _, r := range publicImageRegistries {
+		if registry == r {
+			return true
+		}
+	}
+	return false
+}
+
+// splitHelmChartNameVersion splits a chart reference (e.g., "ingress-nginx-4.10.1") into name and version
+func splitHelmChartNameVersion(chart string) (string, string) {
+	for i := len(chart) - 1; i > 0; i-- {
+		if chart[i] == '-' && i+1 < len(chart) && chart[i+1] >= '0' && chart[i+1] <= '9' {
+			return chart[:i], chart[i+1:]
+		}
+	}
+	return chart, ""
+}
+
+// shellQuote quotes an argument for a POSIX shell (e.g., "a b" -> 'a b'); safe arguments are returned as-is
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range arg {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@%+=,", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// escapeHelmSetValue escapes the characters that helm --set parses in a value (e.g., "a,b" -> "a\,b"),
+// so the value is set as a single string
+func escapeHelmSetValue(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(`\,{}[]`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// deepCopyJSONMap deep-copies a JSON-like map
+func deepCopyJSONMap(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		dst[k] = deepCopyJSONValue(v)
+	}
+	return dst
+}
+
+func deepCopyJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return deepCopyJSONMap(val)
+	case []interface{}:
+		dst := make([]interface{}, len(val))
+		for i, item := range val {
+			dst[i] = deepCopyJSONValue(item)
+		}
+		return dst
+	default:
+		return val
+	}
+}
+
+func getNestedMap(obj map[string]interface{}, path ...string) (map[string]interface{}, bool) {
+	cur := obj
+	for _, p := range path {
+		next, ok := cur[p].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur = next
+	}
+	return cur, true
+}
+
+func getNestedSlice(obj map[string]interface{}, path ...string) ([]interface{}, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	parent, ok := getNestedMap(obj, path[:len(path)-1]...)
+	if !ok {
+		return nil, false
+	}
+	s, ok := parent[path[len(path)-1]].([]interface{})
+	return s, ok
+}
+
+func getNestedString(obj map[string]interface{}, path ...string) (string, bool) {
+	if len(path) == 0 {
+		return "", false
+	}
+	parent, ok := getNestedMap(obj, path[:len(path)-1]...)
+	if !ok {
+		return "", false
+	}
+	s, ok := parent[path[len(path)-1]].(string)
+	return s, ok
+}
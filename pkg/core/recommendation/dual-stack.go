@@ -0,0 +1,333 @@
+package recommendation
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Dual-stack (IPv4/IPv6) planning for the target vNets
+// ============================================================================
+
+// ipv6SupportByCsp is the IPv6 support of the vNets (VPCs) of each CSP.
+// * Note: CSPs not listed here are regarded as IPv4-only.
+var ipv6SupportByCsp = map[string]string{
+	"aws":       cloudmodel.IPv6SupportAssigned, // Amazon-provided /56 per VPC, /64 per subnet
+	"gcp":       cloudmodel.IPv6SupportAssigned, // Google-assigned /64 per subnet (internal ULA or external)
+	"alibaba":   cloudmodel.IPv6SupportAssigned,
+	"tencent":   cloudmodel.IPv6SupportAssigned,
+	"azure":     cloudmodel.IPv6SupportCustom, // User-defined prefixes in the address space
+	"openstack": cloudmodel.IPv6SupportCustom,
+	"ibm":       cloudmodel.IPv6SupportNone,
+	"ncp":       cloudmodel.IPv6SupportNone,
+	"nhn":       cloudmodel.IPv6SupportNone,
+	"kt":        cloudmodel.IPv6SupportNone,
+}
+
+// ipv6Support returns the IPv6 support level of the CSP.
+func ipv6Support(csp string) string {
+	if support, ok := ipv6SupportByCsp[strings.ToLower(csp)]; ok {
+		return support
+	}
+	return cloudmodel.IPv6SupportNone
+}
+
+// ulaNetwork is the IPv6 unique local address (ULA) range (RFC 4193).
+var ulaNetwork = &net.IPNet{IP: net.ParseIP("fc00::"), Mask: net.CIDRMask(7, 128)}
+
+// sourceIPv6Networks returns the IPv6 networks of the node interfaces.
+// Link-local, loopback and multicast addresses are excluded.
+func sourceIPv6Networks(node onpremmodel.NodeProperty) []string {
+	var networks []string
+	for _, nic := range node.Interfaces {
+		for _, cidr := range nic.IPv6CidrBlocks {
+			ip, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil || ip.To4() != nil {
+				continue
+			}
+			if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+				continue
+			}
+			networks = appendUnique(networks, ipNet.String())
+		}
+	}
+	return networks
+}
+
+// planIPv6 plans the dual-stack vNets for the source nodes with IPv6 addresses.
+// The nodes are mapped to the planned subnets by their IPv4 addresses (see selectNodeNetwork).
+//   - custom: the source ULA prefixes are kept; other subnets get free /64 blocks in the vNet prefix.
+//   - assigned: the vNets and subnets are dual-stack with CSP-assigned prefixes.
+//   - none: the vNets are IPv4-only, and the lost IPv6 reachability is reported as warnings.
+func planIPv6(csp string, srcInfra onpremmodel.OnpremInfra, vNets []cloudmodel.VNetReq, plan *cloudmodel.CidrPlan) {
+
+	// Map the source IPv6 networks to the planned subnets
+	sourcesBySubnet := map[subnetKey][]string{}
+	var sourceCidrs []string
+	countIPv6Nodes := 0
+	for _, node := range srcInfra.Nodes {
+		ipv6Networks := sourceIPv6Networks(node)
+		if len(ipv6Networks) == 0 {
+			continue
+		}
+		countIPv6Nodes++
+
+		key := subnetKey{0, 0}
+		vIdx, sIdx := matchSourceSubnet(plan, node)
+		if vIdx >= 0 {
+			key = subnetKey{vIdx, sIdx}
+		}
+		for _, cidr := range ipv6Networks {
+			sourcesBySubnet[key] = appendUnique(sourcesBySubnet[key], cidr)
+			sourceCidrs = appendUnique(sourceCidrs, cidr)
+		}
+	}
+	for _, cidr := range srcInfra.Network.IPv6Networks.CidrBlocks {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.IP.To4() == nil {
+			sourceCidrs = appendUnique(sourceCidrs, ipNet.String())
+		}
+	}
+
+	countIPv6Rules := 0
+	for _, node := range srcInfra.Nodes {
+		for _, rule := range node.FirewallTable {
+			if rule.Action != "deny" && isIPv6Rule(rule) {
+				countIPv6Rules++
+			}
+		}
+	}
+
+	if len(sourceCidrs) == 0 && countIPv6Rules == 0 {
+		return
+	}
+	sort.Strings(sourceCidrs)
+
+	support := ipv6Support(csp)
+	ipv6Plan := &cloudmodel.IPv6Plan{Support: support, SourceCidrBlocks: sourceCidrs}
+	plan.IPv6 = ipv6Plan
+
+	if support == cloudmodel.IPv6SupportNone {
+		if countIPv6Nodes > 0 {
+			ipv6Plan.Warnings = append(ipv6Plan.Warnings, fmt.Sprintf(
+				"%d source node(s) have IPv6 addresses, but %s does not support IPv6 in vNets; the target vNets are IPv4-only and the IPv6 reachability is lost",
+				countIPv6Nodes, csp))
+		}
+		if countIPv6Rules > 0 {
+			ipv6Plan.Warnings = append(ipv6Plan.Warnings, fmt.Sprintf(
+				"%d IPv6 firewall rule(s) of the source are not translated because %s does not support IPv6", countIPv6Rules, csp))
+		}
+		return
+	}
+
+	if countIPv6Nodes == 0 {
+		return
+	}
+
+	prefixesChanged := false
+	for i := range vNets {
+		hasIPv6 := false
+		var vNetSources []string
+		for j := range vNets[i].SubnetInfoList {
+			for _, cidr := range sourcesBySubnet[subnetKey{i, j}] {
+				hasIPv6 = true
+				vNetSources = appendUnique(vNetSources, cidr)
+			}
+		}
+		if !hasIPv6 {
+			continue
+		}
+
+		vNets[i].EnableIPv6 = true
+		for j := range vNets[i].SubnetInfoList {
+			vNets[i].SubnetInfoList[j].EnableIPv6 = true
+		}
+
+		// The source prefixes can be kept only if the CSP accepts user-defined prefixes and they are ULAs
+		keepPrefixes := support == cloudmodel.IPv6SupportCustom
+		for _, cidr := range vNetSources {
+			if ip, _, err := net.ParseCIDR(cidr); err != nil || !ulaNetwork.Contains(ip) {
+				keepPrefixes = false
+				if support == cloudmodel.IPv6SupportCustom {
+					ipv6Plan.Warnings = append(ipv6Plan.Warnings, fmt.Sprintf(
+						"the global IPv6 network %s of the source cannot be kept; the vNet for %s gets a prefix assigned by %s (bring-your-own-IP must be configured manually)",
+						cidr, vNets[i].CidrBlock, csp))
+				}
+			}
+		}
+		if !keepPrefixes {
+			prefixesChanged = true
+			planAssignedIPv6(sourcesBySubnet, i, plan)
+			continue
+		}
+
+		vNetPrefix := ipv6VNetPrefix(vNetSources[0], srcInfra.Network.IPv6Networks.CidrBlocks)
+		vNets[i].IPv6CidrBlock = vNetPrefix
+		if i < len(plan.VNets) {
+			plan.VNets[i].IPv6CidrBlock = vNetPrefix
+		}
+
+		var used []string
+		for j := range vNets[i].SubnetInfoList {
+			sources := sourcesBySubnet[subnetKey{i, j}]
+			if len(sources) > 1 {
+				ipv6Plan.Warnings = append(ipv6Plan.Warnings, fmt.Sprintf(
+					"the nodes in %s are in %d IPv6 networks (%s); only %s is kept for the subnet",
+					vNets[i].SubnetInfoList[j].IPv4_CIDR, len(sources), strings.Join(sources, ", "), sources[0]))
+			}
+			if len(sources) > 0 && cidrsOverlap(vNetPrefix, sources[0]) && !overlapsAny(sources[0], used) {
+				vNets[i].SubnetInfoList[j].IPv6_CIDR = sources[0]
+				used = append(used, sources[0])
+			}
+		}
+		for j := range vNets[i].SubnetInfoList {
+			subnet := &vNets[i].SubnetInfoList[j]
+			if subnet.IPv6_CIDR == "" {
+				cidr, err := nextFreeIPv6Subnet(vNetPrefix, used)
+				if err != nil {
+					ipv6Plan.Warnings = append(ipv6Plan.Warnings, fmt.Sprintf(
+						"no free /64 block in %s for the subnet %s (%v); the subnet is IPv4-only", vNetPrefix, subnet.IPv4_CIDR, err))
+					subnet.EnableIPv6 = false
+					continue
+				}
+				subnet.IPv6_CIDR = cidr
+				used = append(used, cidr)
+			}
+			if i < len(plan.VNets) && j < len(plan.VNets[i].Subnets) {
+				plan.VNets[i].Subnets[j].IPv6CidrBlock = subnet.IPv6_CIDR
+				if sources := sourcesBySubnet[subnetKey{i, j}]; len(sources) > 0 {
+					plan.VNets[i].Subnets[j].SourceIPv6CidrBlock = sources[0]
+				}
+			}
+		}
+	}
+
+	if prefixesChanged {
+		ipv6Plan.Warnings = append(ipv6Plan.Warnings, fmt.Sprintf(
+			"the IPv6 prefixes are assigned by %s, so the IPv6 addresses of the nodes change; update the DNS records and the IPv6 firewall rules referring to the source networks",
+			csp))
+	}
+
+	log.Debug().
+		Str("support", support).
+		Int("ipv6Nodes", countIPv6Nodes).
+		Int("ipv6Rules", countIPv6Rules).
+		Int("warnings", len(ipv6Plan.Warnings)).
+		Msg("IPv6 plan completed")
+}
+
+// subnetKey identifies a planned subnet by the indices of its vNet and itself.
+type subnetKey struct{ vNet, subnet int }
+
+// planAssignedIPv6 records the source IPv6 networks of a dual-stack vNet whose prefixes are assigned by the CSP.
+func planAssignedIPv6(sourcesBySubnet map[subnetKey][]string, vNetIdx int, plan *cloudmodel.CidrPlan) {
+	if vNetIdx >= len(plan.VNets) {
+		return
+	}
+	for j := range plan.VNets[vNetIdx].Subnets {
+		if sources := sourcesBySubnet[subnetKey{vNetIdx, j}]; len(sources) > 0 {
+			plan.VNets[vNetIdx].Subnets[j].SourceIPv6CidrBlock = sources[0]
+		}
+	}
+}
+
+// matchSourceSubnet returns the indices of the planned vNet and subnet whose source network contains the node
+// (-1, -1 if not matched).
+func matchSourceSubnet(plan *cloudmodel.CidrPlan, node onpremmodel.NodeProperty) (int, int) {
+	for _, nic := range node.Interfaces {
+		for _, cidr := range nic.IPv4CidrBlocks {
+			ip, _, err := net.ParseCIDR(cidr)
+			if err != nil {
+				ip = net.ParseIP(cidr)
+			}
+			if ip == nil || ip.IsLoopback() {
+				continue
+			}
+			for i, vNetPlan := range plan.VNets {
+				for j, subnetPlan := range vNetPlan.Subnets {
+					_, srcNet, err := net.ParseCIDR(subnetPlan.SourceCidrBlock)
+					if err == nil && srcNet.Contains(ip) {
+						return i, j
+					}
+				}
+			}
+		}
+	}
+	return -1, -1
+}
+
+// ipv6VNetPrefix returns the prefix of a dual-stack vNet containing the source network:
+// the declared source IPv6 network containing it, or its /48 (the ULA routing prefix) otherwise.
+func ipv6VNetPrefix(sourceCidr string, declared []string) string {
+	ip, srcNet, err := net.ParseCIDR(sourceCidr)
+	if err != nil {
+		return sourceCidr
+	}
+	srcLen, _ := srcNet.Mask.Size()
+	for _, cidr := range declared {
+		_, declaredNet, err := net.ParseCIDR(cidr)
+		if err != nil || declaredNet.IP.To4() != nil {
+			continue
+		}
+		if declaredLen, _ := declaredNet.Mask.Size(); declaredLen <= srcLen && declaredNet.Contains(ip) {
+			return declaredNet.String()
+		}
+	}
+	if srcLen <= 48 {
+		return srcNet.String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// nextFreeIPv6Subnet returns the first /64 block within the IPv6 prefix that does not overlap the used blocks.
+func nextFreeIPv6Subnet(prefix string, used []string) (string, error) {
+	_, prefixNet, err := net.ParseCIDR(prefix)
+	if err != nil || prefixNet.IP.To4() != nil {
+		return "", fmt.Errorf("invalid IPv6 prefix '%s'", prefix)
+	}
+	prefixLen, _ := prefixNet.Mask.Size()
+	if prefixLen > 64 {
+		return "", fmt.Errorf("prefix %s is longer than /64", prefix)
+	}
+
+	// Scan at most 2^16 blocks (a /48 holds 65536 /64 blocks)
+	blockCount := uint64(1) << 16
+	if 64-prefixLen < 16 {
+		blockCount = uint64(1) << (64 - prefixLen)
+	}
+
+	start := binary.BigEndian.Uint64(prefixNet.IP[:8])
+	for i := uint64(0); i < blockCount; i++ {
+		ip := make(net.IP, net.IPv6len)
+		binary.BigEndian.PutUint64(ip[:8], start+i)
+		candidate := (&net.IPNet{IP: ip, Mask: net.CIDRMask(64, 128)}).String()
+		if !overlapsAny(candidate, used) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("address space exhausted")
+}
+
+// overlapsAny reports whether the CIDR block overlaps any of the blocks.
+func overlapsAny(cidr string, blocks []string) bool {
+	for _, b := range blocks {
+		if cidrsOverlap(cidr, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipv6WarningNote returns a description suffix for the IPv6 warnings of the CIDR plan ("" if none).
+func ipv6WarningNote(plan *cloudmodel.CidrPlan) string {
+	if plan == nil || plan.IPv6 == nil || len(plan.IPv6.Warnings) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" | %d IPv6 warning(s): %s", len(plan.IPv6.Warnings), strings.Join(plan.IPv6.Warnings, "; "))
+}
@@ -0,0 +1,835 @@
+package recommendation
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Firewall policy compiler
+// The ordered (first-match, iptables-style) source firewall rules are evaluated
+// into the effective allow-set, from which the deny rules are subtracted, and
+// the allow-set is consolidated into minimal allow-only security group rules.
+// ============================================================================
+
+// cspAclServices lists the service of each CSP that enforces deny rules.
+// CSPs not listed here offer allow-only security groups.
+var cspAclServices = map[string]string{
+	"aws":     "Network ACL",
+	"azure":   "Network Security Group (deny rules)",
+	"gcp":     "VPC firewall rules (deny)",
+	"alibaba": "Network ACL",
+	"tencent": "Network ACL",
+	"ibm":     "VPC access control list",
+	"ncp":     "Network ACL",
+}
+
+// GetAclSupport returns the network ACL support level and the service enforcing deny rules of the CSP.
+func GetAclSupport(csp string) (string, string) {
+	if service, ok := cspAclServices[strings.ToLower(csp)]; ok {
+		return cloudmodel.AclSupportNative, service
+	}
+	return cloudmodel.AclSupportNone, ""
+}
+
+// maxNarrowingRules is the number of additional security group rules a deny rule may cause
+// by narrowing the allow rules. A deny rule exceeding it is enforced with a network ACL, if available.
+const maxNarrowingRules = 10
+
+// Protocols of the compiled policy ("other" is the protocols other than TCP, UDP and ICMP)
+const (
+	policyProtocolTcp   = "tcp"
+	policyProtocolUdp   = "udp"
+	policyProtocolIcmp  = "icmp"
+	policyProtocolOther = "other"
+)
+
+// firewallPolicy is the compiled firewall policy of a source node.
+type firewallPolicy struct {
+	allowRules []onpremmodel.FirewallRuleProperty // Effective allow-set as consolidated allow rules
+	narrowed   []onpremmodel.FirewallRuleProperty // Deny rules subtracted from the allow rules
+	aclOnly    []onpremmodel.FirewallRuleProperty // Deny rules enforced with a network ACL
+	unenforced []onpremmodel.FirewallRuleProperty // Deny rules enforceable neither with security groups nor with network ACLs
+}
+
+// compileFirewallPolicy compiles the ordered source firewall rules into allow-only rules.
+//   - Inbound deny rules are subtracted from the allow rules that follow them. If the subtraction
+//     fragments the allow rules beyond maxNarrowingRules and the CSP supports network ACLs,
+//     the deny rule is left to a network ACL instead.
+//   - Outbound deny rules can only be enforced with a network ACL, since security groups allow all outbound traffic by default.
+//
+// Only deny rules for specific TCP/UDP ports are left to network ACLs: network ACLs are stateless,
+// so deny rules for all ports would also drop the return traffic of the allowed connections.
+func compileFirewallPolicy(rules []onpremmodel.FirewallRuleProperty, aclSupported bool) firewallPolicy {
+	var policy firewallPolicy
+
+	skip := map[int]bool{}
+	base, effective := evaluatePolicy(rules, skip)
+	baseRules := renderPolicy(base)
+
+	for i, rule := range rules {
+		if rule.Action != "deny" || !effective[i] {
+			continue
+		}
+
+		aclEligible := aclSupported && isAclEligible(rule)
+		if rule.Direction == "outbound" {
+			if aclEligible {
+				policy.aclOnly = append(policy.aclOnly, rule)
+			} else {
+				policy.unenforced = append(policy.unenforced, rule)
+			}
+			continue
+		}
+
+		without, _ := evaluatePolicy(rules, map[int]bool{i: true})
+		withoutRules := renderPolicy(without)
+		if sameRules(baseRules, withoutRules) {
+			continue // The deny rule narrows nothing (e.g., a trailing deny-all rule)
+		}
+
+		if aclEligible && len(baseRules)-len(withoutRules) > maxNarrowingRules {
+			policy.aclOnly = append(policy.aclOnly, rule)
+			skip[i] = true
+			continue
+		}
+		policy.narrowed = append(policy.narrowed, rule)
+	}
+
+	if len(skip) > 0 {
+		final, _ := evaluatePolicy(rules, skip)
+		policy.allowRules = renderPolicy(final)
+	} else {
+		policy.allowRules = baseRules
+	}
+
+	log.Debug().
+		Int("sourceRules", len(rules)).
+		Int("allowRules", len(policy.allowRules)).
+		Int("narrowed", len(policy.narrowed)).
+		Int("aclOnly", len(policy.aclOnly)).
+		Int("unenforced", len(policy.unenforced)).
+		Msg("firewall policy compiled")
+
+	return policy
+}
+
+// isAclEligible reports whether the deny rule can be enforced with a stateless network ACL rule
+// without dropping the return traffic (i.e., it is for specific TCP/UDP ports).
+func isAclEligible(rule onpremmodel.FirewallRuleProperty) bool {
+	protocol := strings.ToLower(rule.Protocol)
+	if protocol != "tcp" && protocol != "udp" {
+		return false
+	}
+	ports, err := parsePolicyPorts(rule.DstPorts)
+	return err == nil && !(len(ports) == 1 && isFullPortSpan(ports[0]))
+}
+
+// ----------------------------------------------------------------------------
+// Span sets (addresses and ports)
+// ----------------------------------------------------------------------------
+
+// spanValue is a value of a span (netip.Addr or port).
+type spanValue[T any] interface {
+	comparable
+	Compare(T) int
+	Next() T
+	Prev() T
+}
+
+// port is a transport port (0 is used for protocols without ports).
+type port int
+
+func (p port) Compare(q port) int { return int(p) - int(q) }
+func (p port) Next() port         { return p + 1 }
+func (p port) Prev() port         { return p - 1 }
+
+// span is a closed range [lo, hi].
+type span[T spanValue[T]] struct{ lo, hi T }
+
+// normalizeSpans sorts the spans and merges the overlapping and adjacent ones.
+func normalizeSpans[T spanValue[T]](spans []span[T]) []span[T] {
+	if len(spans) == 0 {
+		return nil
+	}
+	sorted := append([]span[T](nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].lo.Compare(sorted[j].lo) < 0 })
+
+	merged := []span[T]{sorted[0]}
+	for _, s := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if s.lo.Compare(last.hi) <= 0 || s.lo.Prev().Compare(last.hi) == 0 {
+			if s.hi.Compare(last.hi) > 0 {
+				last.hi = s.hi
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// intersectSpans returns the intersection of the span sets.
+func intersectSpans[T spanValue[T]](a, b []span[T]) []span[T] {
+	var out []span[T]
+	for _, x := range a {
+		for _, y := range b {
+			lo, hi := x.lo, x.hi
+			if y.lo.Compare(lo) > 0 {
+				lo = y.lo
+			}
+			if y.hi.Compare(hi) < 0 {
+				hi = y.hi
+			}
+			if lo.Compare(hi) <= 0 {
+				out = append(out, span[T]{lo, hi})
+			}
+		}
+	}
+	return normalizeSpans(out)
+}
+
+// subtractSpans returns the spans of a not in b.
+func subtractSpans[T spanValue[T]](a, b []span[T]) []span[T] {
+	out := append([]span[T](nil), a...)
+	for _, y := range b {
+		var next []span[T]
+		for _, x := range out {
+			if y.hi.Compare(x.lo) < 0 || y.lo.Compare(x.hi) > 0 {
+				next = append(next, x)
+				continue
+			}
+			if y.lo.Compare(x.lo) > 0 {
+				next = append(next, span[T]{x.lo, y.lo.Prev()})
+			}
+			if y.hi.Compare(x.hi) < 0 {
+				next = append(next, span[T]{y.hi.Next(), x.hi})
+			}
+		}
+		out = next
+	}
+	return normalizeSpans(out)
+}
+
+// ----------------------------------------------------------------------------
+// Policy evaluation
+// ----------------------------------------------------------------------------
+
+// policyKey is the match dimension of a rule other than the addresses and ports.
+type policyKey struct {
+	direction string
+	protocol  string
+	ipv6      bool
+}
+
+// policyBox is the set of (address, port) pairs matched by a rule.
+type policyBox struct {
+	addrs []span[netip.Addr]
+	ports []span[port]
+}
+
+// subtract returns the pairs of the box not in d.
+func (b policyBox) subtract(d policyBox) []policyBox {
+	commonAddrs := intersectSpans(b.addrs, d.addrs)
+	commonPorts := intersectSpans(b.ports, d.ports)
+	if len(commonAddrs) == 0 || len(commonPorts) == 0 {
+		return []policyBox{b}
+	}
+
+	var out []policyBox
+	if addrs := subtractSpans(b.addrs, d.addrs); len(addrs) > 0 {
+		out = append(out, policyBox{addrs: addrs, ports: b.ports})
+	}
+	if ports := subtractSpans(b.ports, d.ports); len(ports) > 0 {
+		out = append(out, policyBox{addrs: commonAddrs, ports: ports})
+	}
+	return out
+}
+
+// evaluatePolicy evaluates the rules in order (first match wins), except the skipped ones.
+// It returns the allowed boxes per key and whether each rule matches anything not matched by the preceding rules.
+func evaluatePolicy(rules []onpremmodel.FirewallRuleProperty, skip map[int]bool) (map[policyKey][]policyBox, map[int]bool) {
+	allowed := map[policyKey][]policyBox{}
+	matched := map[policyKey][]policyBox{}
+	effective := map[int]bool{}
+
+	for i, rule := range rules {
+		if skip[i] {
+			continue
+		}
+		if rule.Action != "deny" && rule.DstPorts == "" {
+			continue // Allow rules without port information are not translated (see generateSecurityGroupRules)
+		}
+
+		keys, box, ok := ruleToBox(rule)
+		if !ok {
+			continue
+		}
+		for _, key := range keys {
+			keyBox := box
+			if key.protocol == policyProtocolIcmp || key.protocol == policyProtocolOther {
+				keyBox.ports = []span[port]{{0, 0}}
+			}
+
+			remaining := []policyBox{keyBox}
+			for _, m := range matched[key] {
+				var next []policyBox
+				for _, r := range remaining {
+					next = append(next, r.subtract(m)...)
+				}
+				remaining = next
+			}
+			if len(remaining) > 0 {
+				effective[i] = true
+			}
+			if rule.Action != "deny" {
+				allowed[key] = append(allowed[key], remaining...)
+			}
+			matched[key] = append(matched[key], keyBox)
+		}
+	}
+	return allowed, effective
+}
+
+// ruleToBox returns the keys and the box matched by the rule (false if the rule cannot be evaluated).
+func ruleToBox(rule onpremmodel.FirewallRuleProperty) ([]policyKey, policyBox, bool) {
+	direction := rule.Direction
+	cidr := rule.SrcCIDR
+	if direction == "outbound" {
+		cidr = rule.DstCIDR
+	} else if direction != "inbound" {
+		return nil, policyBox{}, false
+	}
+	if cidr == "" {
+		return nil, policyBox{}, false
+	}
+
+	ipv6 := isIPv6Rule(rule)
+	addrs, err := parsePolicyCidr(cidr, ipv6)
+	if err != nil {
+		log.Debug().Err(err).Msgf("skipping firewall rule with invalid CIDR: %+v", rule)
+		return nil, policyBox{}, false
+	}
+	ipv6 = addrs.lo.Is6()
+
+	ports, err := parsePolicyPorts(rule.DstPorts)
+	if err != nil {
+		log.Debug().Err(err).Msgf("skipping firewall rule with invalid ports: %+v", rule)
+		return nil, policyBox{}, false
+	}
+
+	var protocols []string
+	switch strings.ToLower(rule.Protocol) {
+	case "tcp":
+		protocols = []string{policyProtocolTcp}
+	case "udp":
+		protocols = []string{policyProtocolUdp}
+	case "icmp", "icmpv6":
+		protocols = []string{policyProtocolIcmp}
+	case "*", "all":
+		protocols = []string{policyProtocolTcp, policyProtocolUdp, policyProtocolIcmp, policyProtocolOther}
+		ports = []span[port]{{1, 65535}}
+	case "":
+		return nil, policyBox{}, false
+	default:
+		// Other protocols cannot be allowed in security groups, but their deny rules keep "ALL" rules from being merged
+		if rule.Action != "deny" {
+			return nil, policyBox{}, false
+		}
+		protocols = []string{policyProtocolOther}
+	}
+
+	var keys []policyKey
+	for _, p := range protocols {
+		keys = append(keys, policyKey{direction: direction, protocol: p, ipv6: ipv6})
+	}
+	return keys, policyBox{addrs: []span[netip.Addr]{addrs}, ports: ports}, true
+}
+
+// parsePolicyCidr parses a CIDR block, an address, or "anywhere" into an address span.
+func parsePolicyCidr(cidr string, ipv6 bool) (span[netip.Addr], error) {
+	if cidr == "anywhere" {
+		if ipv6 {
+			cidr = "::/0"
+		} else {
+			cidr = "0.0.0.0/0"
+		}
+	}
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return span[netip.Addr]{}, err
+		}
+		addr = addr.Unmap()
+		return span[netip.Addr]{addr, addr}, nil
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return span[netip.Addr]{}, err
+	}
+	prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()).Masked()
+	return span[netip.Addr]{prefix.Addr(), lastAddr(prefix)}, nil
+}
+
+// parsePolicyPorts parses ports (e.g., "80", "80,443", "1024-65535", "30000:40000", "*") into port spans.
+func parsePolicyPorts(ports string) ([]span[port], error) {
+	ports = strings.TrimSpace(ports)
+	if ports == "" || ports == "*" {
+		return []span[port]{{1, 65535}}, nil
+	}
+
+	var spans []span[port]
+	for _, part := range strings.Split(ports, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == ':' })
+		if len(bounds) == 0 || len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid port '%s'", part)
+		}
+		lo, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid port '%s'", part)
+		}
+		hi := lo
+		if len(bounds) == 2 {
+			if hi, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, fmt.Errorf("invalid port '%s'", part)
+			}
+		}
+		if lo < 0 || hi > 65535 || lo > hi {
+			return nil, fmt.Errorf("invalid port range '%s'", part)
+		}
+		spans = append(spans, span[port]{port(lo), port(hi)})
+	}
+	return normalizeSpans(spans), nil
+}
+
+// lastAddr returns the last address of the prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// spanPrefixes decomposes the address span into the minimal list of CIDR prefixes.
+func spanPrefixes(s span[netip.Addr]) []netip.Prefix {
+	var prefixes []netip.Prefix
+	lo := s.lo
+	for {
+		best := lo.BitLen()
+		for bits := lo.BitLen(); bits >= 0; bits-- {
+			p, err := lo.Prefix(bits)
+			if err != nil || p.Addr() != lo || lastAddr(p).Compare(s.hi) > 0 {
+				break
+			}
+			best = bits
+		}
+		p := netip.PrefixFrom(lo, best)
+		prefixes = append(prefixes, p)
+
+		last := lastAddr(p)
+		if last.Compare(s.hi) >= 0 {
+			return prefixes
+		}
+		lo = last.Next()
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Rendering
+// ----------------------------------------------------------------------------
+
+// renderPolicy consolidates the allowed boxes into minimal allow rules:
+// the addresses allowed for all ports of all protocols are expressed as "ALL" rules, and
+// the rest is grouped either by ports or by addresses, whichever results in fewer rules.
+func renderPolicy(allowed map[policyKey][]policyBox) []onpremmodel.FirewallRuleProperty {
+	type family struct {
+		direction string
+		ipv6      bool
+	}
+	families := map[family]bool{}
+	for key := range allowed {
+		families[family{key.direction, key.ipv6}] = true
+	}
+
+	var rules []onpremmodel.FirewallRuleProperty
+	for f := range families {
+		key := func(protocol string) policyKey { return policyKey{f.direction, protocol, f.ipv6} }
+
+		// Addresses allowed for all ports of all protocols
+		var allAddrs []span[netip.Addr]
+		for i, protocol := range []string{policyProtocolTcp, policyProtocolUdp, policyProtocolIcmp, policyProtocolOther} {
+			var fullAddrs []span[netip.Addr]
+			for _, box := range allowed[key(protocol)] {
+				if len(box.ports) == 1 && isFullPortSpan(box.ports[0]) {
+					fullAddrs = append(fullAddrs, box.addrs...)
+				}
+			}
+			if i == 0 {
+				allAddrs = normalizeSpans(fullAddrs)
+			} else {
+				allAddrs = intersectSpans(allAddrs, normalizeSpans(fullAddrs))
+			}
+		}
+		for _, addrs := range allAddrs {
+			for _, p := range spanPrefixes(addrs) {
+				rules = append(rules, newPolicyRule(f.direction, "*", p, "*"))
+			}
+		}
+
+		for _, protocol := range []string{policyProtocolTcp, policyProtocolUdp, policyProtocolIcmp} {
+			var boxes []policyBox
+			for _, box := range allowed[key(protocol)] {
+				if addrs := subtractSpans(box.addrs, allAddrs); len(addrs) > 0 {
+					boxes = append(boxes, policyBox{addrs: addrs, ports: box.ports})
+				}
+			}
+			byAddrs := renderBoxesByAddrs(f.direction, strings.ToUpper(protocol), boxes)
+			byPorts := renderBoxesByPorts(f.direction, strings.ToUpper(protocol), boxes)
+			if len(byPorts) < len(byAddrs) {
+				rules = append(rules, byPorts...)
+			} else {
+				rules = append(rules, byAddrs...)
+			}
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return policyRuleKey(rules[i]) < policyRuleKey(rules[j]) })
+	return rules
+}
+
+// renderBoxesByAddrs renders the boxes as one rule per address prefix sharing the same ports.
+func renderBoxesByAddrs(direction, protocol string, boxes []policyBox) []onpremmodel.FirewallRuleProperty {
+	portsByAddrs := atomizeSpans(len(boxes),
+		func(i int) []span[netip.Addr] { return boxes[i].addrs },
+		func(i int) []span[port] { return boxes[i].ports })
+
+	addrsByPorts := map[string][]span[netip.Addr]{}
+	for addrs, ports := range portsByAddrs {
+		portsStr := formatPolicyPorts(ports)
+		addrsByPorts[portsStr] = append(addrsByPorts[portsStr], addrs)
+	}
+
+	var rules []onpremmodel.FirewallRuleProperty
+	for portsStr, addrList := range addrsByPorts {
+		for _, addrs := range normalizeSpans(addrList) {
+			for _, p := range spanPrefixes(addrs) {
+				rules = append(rules, newPolicyRule(direction, protocol, p, portsStr))
+			}
+		}
+	}
+	return rules
+}
+
+// renderBoxesByPorts renders the boxes as one rule per address prefix for the ports sharing the same addresses.
+func renderBoxesByPorts(direction, protocol string, boxes []policyBox) []onpremmodel.FirewallRuleProperty {
+	addrsByPorts := atomizeSpans(len(boxes),
+		func(i int) []span[port] { return boxes[i].ports },
+		func(i int) []span[netip.Addr] { return boxes[i].addrs })
+
+	type portGroup struct {
+		addrs []span[netip.Addr]
+		ports []span[port]
+	}
+	groups := map[string]*portGroup{}
+	for ports, addrs := range addrsByPorts {
+		var addrKey []string
+		for _, a := range addrs {
+			addrKey = append(addrKey, a.lo.String()+"-"+a.hi.String())
+		}
+		k := strings.Join(addrKey, ",")
+		if _, ok := groups[k]; !ok {
+			groups[k] = &portGroup{addrs: addrs}
+		}
+		groups[k].ports = append(groups[k].ports, ports)
+	}
+
+	var rules []onpremmodel.FirewallRuleProperty
+	for _, g := range groups {
+		portsStr := formatPolicyPorts(normalizeSpans(g.ports))
+		for _, addrs := range g.addrs {
+			for _, p := range spanPrefixes(addrs) {
+				rules = append(rules, newPolicyRule(direction, protocol, p, portsStr))
+			}
+		}
+	}
+	return rules
+}
+
+// atomizeSpans splits the key spans of n entries at every span boundary and maps each piece
+// to the union of the value spans of the entries containing it.
+func atomizeSpans[K spanValue[K], V spanValue[V]](n int, keysOf func(int) []span[K], valuesOf func(int) []span[V]) map[span[K]][]span[V] {
+	var bounds []K
+	for i := 0; i < n; i++ {
+		for _, k := range keysOf(i) {
+			bounds = append(bounds, k.lo)
+			if next := k.hi.Next(); next.Compare(k.hi) > 0 {
+				bounds = append(bounds, next)
+			}
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Compare(bounds[j]) < 0 })
+
+	pieces := map[span[K]][]span[V]{}
+	for i := 0; i < n; i++ {
+		for _, k := range keysOf(i) {
+			lo := k.lo
+			for _, bound := range bounds {
+				if bound.Compare(lo) <= 0 || bound.Compare(k.hi) > 0 {
+					continue
+				}
+				piece := span[K]{lo, bound.Prev()}
+				pieces[piece] = normalizeSpans(append(pieces[piece], valuesOf(i)...))
+				lo = bound
+			}
+			piece := span[K]{lo, k.hi}
+			pieces[piece] = normalizeSpans(append(pieces[piece], valuesOf(i)...))
+		}
+	}
+	return pieces
+}
+
+// isFullPortSpan reports whether the span covers all ports (or the protocol has no ports).
+func isFullPortSpan(s span[port]) bool {
+	return s == span[port]{1, 65535} || s == span[port]{0, 0}
+}
+
+// formatPolicyPorts formats the port spans (e.g., "22,80-81"); "*" for all ports.
+func formatPolicyPorts(ports []span[port]) string {
+	if len(ports) == 0 || (len(ports) == 1 && isFullPortSpan(ports[0])) {
+		return "*"
+	}
+	parts := make([]string, len(ports))
+	for i, p := range ports {
+		if p.lo == p.hi {
+			parts[i] = strconv.Itoa(int(p.lo))
+		} else {
+			parts[i] = fmt.Sprintf("%d-%d", p.lo, p.hi)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// newPolicyRule returns a compiled allow rule.
+func newPolicyRule(direction, protocol string, prefix netip.Prefix, ports string) onpremmodel.FirewallRuleProperty {
+	rule := onpremmodel.FirewallRuleProperty{
+		Direction: direction,
+		Protocol:  protocol,
+		DstPorts:  ports,
+		Action:    "allow",
+	}
+	if direction == "outbound" {
+		rule.DstCIDR = prefix.String()
+	} else {
+		rule.SrcCIDR = prefix.String()
+	}
+	return rule
+}
+
+// policyRuleKey returns a sort and comparison key of a compiled rule.
+func policyRuleKey(rule onpremmodel.FirewallRuleProperty) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", rule.Direction, rule.Protocol, rule.SrcCIDR, rule.DstCIDR, rule.DstPorts)
+}
+
+// sameRules reports whether the compiled rule lists are identical.
+func sameRules(a, b []onpremmodel.FirewallRuleProperty) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if policyRuleKey(a[i]) != policyRuleKey(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+// Firewall policy report and network ACLs
+// ----------------------------------------------------------------------------
+
+// applyFirewallPolicy reports how the deny rules of the source nodes are enforced and
+// recommends a network ACL per subnet for the deny rules security groups cannot express.
+func applyFirewallPolicy(csp string, infra *cloudmodel.RecommendedInfra, srcNodes []onpremmodel.NodeProperty) {
+	aclSupport, aclService := GetAclSupport(csp)
+	report := &cloudmodel.FirewallPolicyReport{AclSupport: aclSupport, CspService: aclService}
+
+	nodeByMachineId := map[string]onpremmodel.NodeProperty{}
+	for _, node := range srcNodes {
+		nodeByMachineId[node.MachineId] = node
+	}
+
+	// ACL rules per subnet, in the order of the NodeGroups
+	type subnetAcl struct {
+		vNetId, subnetId string
+		machineIds       []string
+		rules            []cloudmodel.NetworkAclRuleReq
+		seen             map[string]bool
+	}
+	var subnetAcls []*subnetAcl
+	aclBySubnet := map[string]*subnetAcl{}
+	compiled := map[string]bool{}
+
+	for _, ng := range infra.TargetInfra.NodeGroups {
+		for _, machineId := range nodeGroupSourceMachineIds(ng) {
+			node, ok := nodeByMachineId[machineId]
+			if !ok || len(node.FirewallTable) == 0 {
+				continue
+			}
+
+			policy := compileFirewallPolicy(node.FirewallTable, aclSupport == cloudmodel.AclSupportNative)
+			if !compiled[machineId] {
+				compiled[machineId] = true
+				for _, rule := range policy.narrowed {
+					report.NarrowedRules = append(report.NarrowedRules, describeFirewallRule(machineId, rule))
+				}
+				for _, rule := range policy.aclOnly {
+					report.AclOnlyRules = append(report.AclOnlyRules, describeFirewallRule(machineId, rule))
+				}
+				for _, rule := range policy.unenforced {
+					report.UnenforcedRules = append(report.UnenforcedRules, describeFirewallRule(machineId, rule))
+				}
+			}
+			if len(policy.aclOnly) == 0 {
+				continue
+			}
+
+			aclKey := ng.VNetId + "/" + ng.SubnetId
+			acl, exists := aclBySubnet[aclKey]
+			if !exists {
+				acl = &subnetAcl{vNetId: ng.VNetId, subnetId: ng.SubnetId, seen: map[string]bool{}}
+				aclBySubnet[aclKey] = acl
+				subnetAcls = append(subnetAcls, acl)
+			}
+			acl.machineIds = appendUnique(acl.machineIds, machineId)
+			for _, rule := range policy.aclOnly {
+				for _, aclRule := range toNetworkAclRules(machineId, rule) {
+					key := fmt.Sprintf("%s|%s|%s|%s", aclRule.Direction, aclRule.Protocol, aclRule.CIDR, aclRule.Ports)
+					if acl.seen[key] {
+						continue
+					}
+					acl.seen[key] = true
+					acl.rules = append(acl.rules, aclRule)
+				}
+			}
+		}
+	}
+
+	if len(report.NarrowedRules) == 0 && len(report.AclOnlyRules) == 0 && len(report.UnenforcedRules) == 0 {
+		return
+	}
+
+	if len(report.UnenforcedRules) > 0 {
+		report.Notes = append(report.Notes,
+			"The deny rules in unenforcedRules are not enforced in the target cloud; keep them in the host firewall of the migrated VMs.")
+	}
+
+	var aclList []cloudmodel.NetworkAclReq
+	for i, acl := range subnetAcls {
+		rules := acl.rules
+		for j := range rules {
+			rules[j].RuleNumber = (j + 1) * 10
+		}
+		// Allow the rest; the security groups filter the allowed traffic
+		anyCidrs := []string{"0.0.0.0/0"}
+		for _, rule := range rules {
+			if strings.Contains(rule.CIDR, ":") {
+				anyCidrs = append(anyCidrs, "::/0")
+				break
+			}
+		}
+		for _, direction := range []string{"inbound", "outbound"} {
+			for k, anyCidr := range anyCidrs {
+				rules = append(rules, cloudmodel.NetworkAclRuleReq{
+					RuleNumber: 32000 + k, Direction: direction, Protocol: "ALL", CIDR: anyCidr, Action: "allow",
+				})
+			}
+		}
+		aclList = append(aclList, cloudmodel.NetworkAclReq{
+			Name:      fmt.Sprintf("mig-acl-%02d", i+1),
+			VNetId:    acl.vNetId,
+			SubnetIds: []string{acl.subnetId},
+			Description: fmt.Sprintf("Recommended network ACL for the deny rules of %s (applies to all nodes in the subnet)",
+				strings.Join(acl.machineIds, ", ")),
+			Rules: rules,
+		})
+	}
+	if len(aclList) > 0 {
+		report.Notes = append(report.Notes,
+			"Network ACLs are stateless and apply to all nodes in the subnet; review the deny rules before associating them with the subnets.")
+	}
+
+	infra.FirewallPolicy = report
+	infra.TargetNetworkAclList = aclList
+}
+
+// toNetworkAclRules converts a deny rule into network ACL rules (one per port range).
+func toNetworkAclRules(machineId string, rule onpremmodel.FirewallRuleProperty) []cloudmodel.NetworkAclRuleReq {
+	cidr := rule.SrcCIDR
+	if rule.Direction == "outbound" {
+		cidr = rule.DstCIDR
+	}
+	ipv6 := isIPv6Rule(rule)
+	addrs, err := parsePolicyCidr(cidr, ipv6)
+	if err != nil {
+		return nil
+	}
+
+	protocol := strings.ToUpper(rule.Protocol)
+	switch protocol {
+	case "*":
+		protocol = "ALL"
+	case "ICMPV6":
+		protocol = "ICMP"
+	}
+
+	aclRule := cloudmodel.NetworkAclRuleReq{
+		Direction:       rule.Direction,
+		Protocol:        protocol,
+		Action:          "deny",
+		SourceMachineId: machineId,
+	}
+
+	var aclRules []cloudmodel.NetworkAclRuleReq
+	for _, p := range spanPrefixes(addrs) {
+		aclRule.CIDR = p.String()
+		if protocol != "TCP" && protocol != "UDP" {
+			aclRules = append(aclRules, aclRule)
+			continue
+		}
+		ports, err := parsePolicyPorts(rule.DstPorts)
+		if err != nil {
+			continue
+		}
+		for _, portSpan := range ports {
+			aclRule.Ports = formatPolicyPorts([]span[port]{portSpan})
+			if aclRule.Ports == "*" {
+				aclRule.Ports = "1-65535"
+			}
+			aclRules = append(aclRules, aclRule)
+		}
+	}
+	return aclRules
+}
+
+// describeFirewallRule describes a source firewall rule for the report.
+func describeFirewallRule(machineId string, rule onpremmodel.FirewallRuleProperty) string {
+	cidr := "from " + rule.SrcCIDR
+	if rule.Direction == "outbound" {
+		cidr = "to " + rule.DstCIDR
+	}
+	ports := rule.DstPorts
+	if ports == "" {
+		ports = "*"
+	}
+	return fmt.Sprintf("[%s] %s %s %s %s %s", machineId, rule.Action, rule.Direction, rule.Protocol, ports, cidr)
+}
@@ -0,0 +1,391 @@
+package recommendation
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+)
+
+func inboundRule(action, protocol, srcCidr, dstPorts string) onpremmodel.FirewallRuleProperty {
+	return onpremmodel.FirewallRuleProperty{Direction: "inbound", Action: action, Protocol: protocol, SrcCIDR: srcCidr, DstPorts: dstPorts}
+}
+
+func outboundRule(action, protocol, dstCidr, dstPorts string) onpremmodel.FirewallRuleProperty {
+	return onpremmodel.FirewallRuleProperty{Direction: "outbound", Action: action, Protocol: protocol, DstCIDR: dstCidr, DstPorts: dstPorts}
+}
+
+func addrSpan(t *testing.T, cidr string) span[netip.Addr] {
+	t.Helper()
+	s, err := parsePolicyCidr(cidr, false)
+	if err != nil {
+		t.Fatalf("parsePolicyCidr(%q) failed: %v", cidr, err)
+	}
+	return s
+}
+
+func TestSubtractSpans(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []span[port]
+		want []span[port]
+	}{
+		{"disjoint", []span[port]{{80, 90}}, []span[port]{{100, 110}}, []span[port]{{80, 90}}},
+		{"hole in the middle", []span[port]{{1, 65535}}, []span[port]{{22, 22}}, []span[port]{{1, 21}, {23, 65535}}},
+		{"overlap at the start", []span[port]{{80, 90}}, []span[port]{{70, 85}}, []span[port]{{86, 90}}},
+		{"overlap at the end", []span[port]{{80, 90}}, []span[port]{{85, 95}}, []span[port]{{80, 84}}},
+		{"fully covered", []span[port]{{80, 90}}, []span[port]{{80, 90}}, nil},
+		{"several subtrahends", []span[port]{{1, 100}}, []span[port]{{10, 20}, {50, 60}, {90, 100}}, []span[port]{{1, 9}, {21, 49}, {61, 89}}},
+		{"adjacent remainders are merged", []span[port]{{1, 10}, {11, 20}}, []span[port]{{30, 40}}, []span[port]{{1, 20}}},
+		{"nothing subtracted", []span[port]{{5, 5}}, nil, []span[port]{{5, 5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subtractSpans(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subtractSpans(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("addresses", func(t *testing.T) {
+		got := subtractSpans([]span[netip.Addr]{addrSpan(t, "10.0.0.0/24")}, []span[netip.Addr]{addrSpan(t, "10.0.0.0/25")})
+		if want := []span[netip.Addr]{addrSpan(t, "10.0.0.128/25")}; !reflect.DeepEqual(got, want) {
+			t.Errorf("subtractSpans() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestPolicyBoxSubtract(t *testing.T) {
+	box := func(cidr string, ports ...span[port]) policyBox {
+		return policyBox{addrs: []span[netip.Addr]{addrSpan(t, cidr)}, ports: ports}
+	}
+
+	tests := []struct {
+		name string
+		b, d policyBox
+		want []policyBox
+	}{
+		{
+			name: "disjoint addresses",
+			b:    box("10.0.0.0/24", span[port]{22, 22}),
+			d:    box("10.0.1.0/24", span[port]{22, 22}),
+			want: []policyBox{box("10.0.0.0/24", span[port]{22, 22})},
+		},
+		{
+			name: "disjoint ports",
+			b:    box("10.0.0.0/24", span[port]{22, 22}),
+			d:    box("10.0.0.0/24", span[port]{80, 80}),
+			want: []policyBox{box("10.0.0.0/24", span[port]{22, 22})},
+		},
+		{
+			name: "part of the addresses for all ports",
+			b:    box("10.0.0.0/24", span[port]{22, 22}),
+			d:    box("10.0.0.0/25", span[port]{1, 65535}),
+			want: []policyBox{box("10.0.0.128/25", span[port]{22, 22})},
+		},
+		{
+			name: "part of the ports for all addresses",
+			b:    box("10.0.0.0/24", span[port]{1, 1024}),
+			d:    box("0.0.0.0/0", span[port]{22, 22}),
+			want: []policyBox{box("10.0.0.0/24", span[port]{1, 21}, span[port]{23, 1024})},
+		},
+		{
+			name: "part of the addresses and the ports",
+			b:    box("10.0.0.0/24", span[port]{20, 30}),
+			d:    box("10.0.0.0/25", span[port]{22, 22}),
+			want: []policyBox{
+				box("10.0.0.128/25", span[port]{20, 30}),
+				box("10.0.0.0/25", span[port]{20, 21}, span[port]{23, 30}),
+			},
+		},
+		{
+			name: "fully covered",
+			b:    box("10.0.0.0/25", span[port]{22, 22}),
+			d:    box("10.0.0.0/24", span[port]{1, 65535}),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.subtract(tt.d); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subtract() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	rules := []onpremmodel.FirewallRuleProperty{
+		inboundRule("allow", "tcp", "10.0.0.0/8", "22"),
+		inboundRule("allow", "tcp", "10.1.0.0/16", "22"), // Shadowed by rule 0
+		inboundRule("deny", "tcp", "10.0.0.0/8", "22"),   // Shadowed by rule 0
+		inboundRule("allow", "tcp", "10.1.0.0/16", "80"),
+		inboundRule("allow", "tcp", "anywhere", ""), // Not translated without ports
+	}
+
+	tests := []struct {
+		name          string
+		skip          map[int]bool
+		wantAllow     []onpremmodel.FirewallRuleProperty
+		wantEffective map[int]bool
+	}{
+		{
+			name: "first match wins",
+			skip: map[int]bool{},
+			wantAllow: []onpremmodel.FirewallRuleProperty{
+				inboundRule("allow", "TCP", "10.0.0.0/8", "22"),
+				inboundRule("allow", "TCP", "10.1.0.0/16", "80"),
+			},
+			wantEffective: map[int]bool{0: true, 3: true},
+		},
+		{
+			name: "skipped rule",
+			skip: map[int]bool{0: true},
+			wantAllow: []onpremmodel.FirewallRuleProperty{
+				inboundRule("allow", "TCP", "10.1.0.0/16", "22,80"),
+			},
+			wantEffective: map[int]bool{1: true, 2: true, 3: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, effective := evaluatePolicy(rules, tt.skip)
+			if got := renderPolicy(allowed); !reflect.DeepEqual(got, tt.wantAllow) {
+				t.Errorf("allowed = %v, want %v", got, tt.wantAllow)
+			}
+			if !reflect.DeepEqual(effective, tt.wantEffective) {
+				t.Errorf("effective = %v, want %v", effective, tt.wantEffective)
+			}
+		})
+	}
+}
+
+func TestCompileFirewallPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		rules        []onpremmodel.FirewallRuleProperty
+		aclSupported bool
+		want         firewallPolicy
+	}{
+		{
+			name: "deny before allow narrows the allow rule",
+			rules: []onpremmodel.FirewallRuleProperty{
+				inboundRule("deny", "tcp", "10.0.0.0/25", "22"),
+				inboundRule("allow", "tcp", "10.0.0.0/24", "22"),
+			},
+			want: firewallPolicy{
+				allowRules: []onpremmodel.FirewallRuleProperty{inboundRule("allow", "TCP", "10.0.0.128/25", "22")},
+				narrowed:   []onpremmodel.FirewallRuleProperty{inboundRule("deny", "tcp", "10.0.0.0/25", "22")},
+			},
+		},
+		{
+			name: "deny after allow is shadowed",
+			rules: []onpremmodel.FirewallRuleProperty{
+				inboundRule("allow", "tcp", "10.0.0.0/24", "22"),
+				inboundRule("deny", "tcp", "10.0.0.0/25", "22"),
+			},
+			want: firewallPolicy{
+				allowRules: []onpremmodel.FirewallRuleProperty{inboundRule("allow", "TCP", "10.0.0.0/24", "22")},
+			},
+		},
+		{
+			name: "deny narrowing the ports",
+			rules: []onpremmodel.FirewallRuleProperty{
+				inboundRule("deny", "udp", "anywhere", "161-162"),
+				inboundRule("allow", "udp", "10.0.0.0/24", "100-200"),
+			},
+			want: firewallPolicy{
+				allowRules: []onpremmodel.FirewallRuleProperty{inboundRule("allow", "UDP", "10.0.0.0/24", "100-160,163-200")},
+				narrowed:   []onpremmodel.FirewallRuleProperty{inboundRule("deny", "udp", "anywhere", "161-162")},
+			},
+		},
+		{
+			name: "trailing deny-all narrows nothing",
+			rules: []onpremmodel.FirewallRuleProperty{
+				inboundRule("allow", "tcp", "anywhere", "22"),
+				inboundRule("deny", "*", "anywhere", "*"),
+			},
+			aclSupported: true,
+			want: firewallPolicy{
+				allowRules: []onpremmodel.FirewallRuleProperty{inboundRule("allow", "TCP", "0.0.0.0/0", "22")},
+			},
+		},
+		{
+			name: "allow all protocols is rendered as an ALL rule",
+			rules: []onpremmodel.FirewallRuleProperty{
+				inboundRule("allow", "*", "10.0.0.0/24", "*"),
+			},
+			want: firewallPolicy{
+				allowRules: []onpremmodel.FirewallRuleProperty{inboundRule("allow", "*", "10.0.0.0/24", "*")},
+			},
+		},
+		{
+			name: "deny of another protocol splits the ALL rule",
+			rules: []onpremmodel.FirewallRuleProperty{
+				inboundRule("deny", "gre", "10.0.0.0/24", ""),
+				inboundRule("allow", "*", "10.0.0.0/24", "*"),
+			},
+			aclSupported: true,
+			want: firewallPolicy{
+				allowRules: []onpremmodel.FirewallRuleProperty{
+					inboundRule("allow", "ICMP", "10.0.0.0/24", "*"),
+					inboundRule("allow", "TCP", "10.0.0.0/24", "*"),
+					inboundRule("allow", "UDP", "10.0.0.0/24", "*"),
+				},
+				narrowed: []onpremmodel.FirewallRuleProperty{inboundRule("deny", "gre", "10.0.0.0/24", "")},
+			},
+		},
+		{
+			name: "IPv6 rules",
+			rules: []onpremmodel.FirewallRuleProperty{
+				inboundRule("deny", "tcp", "2001:db8::/33", "22"),
+				inboundRule("allow", "tcp", "2001:db8::/32", "22"),
+				inboundRule("allow", "tcp", "10.0.0.0/24", "22"),
+			},
+			want: firewallPolicy{
+				allowRules: []onpremmodel.FirewallRuleProperty{
+					inboundRule("allow", "TCP", "10.0.0.0/24", "22"),
+					inboundRule("allow", "TCP", "2001:db8:8000::/33", "22"),
+				},
+				narrowed: []onpremmodel.FirewallRuleProperty{inboundRule("deny", "tcp", "2001:db8::/33", "22")},
+			},
+		},
+		{
+			name: "IPv4 deny does not narrow IPv6 rules",
+			rules: []onpremmodel.FirewallRuleProperty{
+				inboundRule("deny", "tcp", "anywhere", "22"),
+				inboundRule("allow", "tcp", "::/0", "22"),
+			},
+			want: firewallPolicy{
+				allowRules: []onpremmodel.FirewallRuleProperty{inboundRule("allow", "TCP", "::/0", "22")},
+			},
+		},
+		{
+			name: "outbound deny for specific ports is left to a network ACL",
+			rules: []onpremmodel.FirewallRuleProperty{
+				outboundRule("deny", "tcp", "anywhere", "25"),
+			},
+			aclSupported: true,
+			want: firewallPolicy{
+				aclOnly: []onpremmodel.FirewallRuleProperty{outboundRule("deny", "tcp", "anywhere", "25")},
+			},
+		},
+		{
+			name: "outbound deny without network ACLs is unenforced",
+			rules: []onpremmodel.FirewallRuleProperty{
+				outboundRule("deny", "tcp", "anywhere", "25"),
+			},
+			want: firewallPolicy{
+				unenforced: []onpremmodel.FirewallRuleProperty{outboundRule("deny", "tcp", "anywhere", "25")},
+			},
+		},
+		{
+			name: "outbound deny for all ports is not eligible for a stateless network ACL",
+			rules: []onpremmodel.FirewallRuleProperty{
+				outboundRule("deny", "tcp", "10.0.0.0/8", "*"),
+				outboundRule("deny", "icmp", "10.0.0.0/8", ""),
+			},
+			aclSupported: true,
+			want: firewallPolicy{
+				unenforced: []onpremmodel.FirewallRuleProperty{
+					outboundRule("deny", "tcp", "10.0.0.0/8", "*"),
+					outboundRule("deny", "icmp", "10.0.0.0/8", ""),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compileFirewallPolicy(tt.rules, tt.aclSupported); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compileFirewallPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileFirewallPolicyAclFallback(t *testing.T) {
+	// Subtracting a single address from a /16 fragments the allow rule into 17 rules
+	allow := inboundRule("allow", "tcp", "10.0.0.0/16", "*")
+
+	tests := []struct {
+		name          string
+		deny          onpremmodel.FirewallRuleProperty
+		aclSupported  bool
+		wantAclOnly   bool
+		wantAllowLen  int
+		wantFirstRule onpremmodel.FirewallRuleProperty
+	}{
+		{
+			name:          "fragmenting deny is left to a network ACL",
+			deny:          inboundRule("deny", "tcp", "10.0.0.5", "22"),
+			aclSupported:  true,
+			wantAclOnly:   true,
+			wantAllowLen:  1,
+			wantFirstRule: inboundRule("allow", "TCP", "10.0.0.0/16", "*"),
+		},
+		{
+			name:          "fragmenting deny narrows the allow rules without network ACLs",
+			deny:          inboundRule("deny", "tcp", "10.0.0.5", "22"),
+			wantAllowLen:  17,
+			wantFirstRule: inboundRule("allow", "TCP", "10.0.0.0/30", "*"),
+		},
+		{
+			name:          "fragmenting deny for all ports is not eligible for a stateless network ACL",
+			deny:          inboundRule("deny", "tcp", "10.0.0.5", "*"),
+			aclSupported:  true,
+			wantAllowLen:  16,
+			wantFirstRule: inboundRule("allow", "TCP", "10.0.0.0/30", "*"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := compileFirewallPolicy([]onpremmodel.FirewallRuleProperty{tt.deny, allow}, tt.aclSupported)
+
+			wantDenied := []onpremmodel.FirewallRuleProperty{tt.deny}
+			if tt.wantAclOnly {
+				if !reflect.DeepEqual(policy.aclOnly, wantDenied) || len(policy.narrowed) != 0 {
+					t.Errorf("aclOnly = %v, narrowed = %v, want the deny rule in aclOnly", policy.aclOnly, policy.narrowed)
+				}
+			} else {
+				if !reflect.DeepEqual(policy.narrowed, wantDenied) || len(policy.aclOnly) != 0 {
+					t.Errorf("aclOnly = %v, narrowed = %v, want the deny rule in narrowed", policy.aclOnly, policy.narrowed)
+				}
+			}
+			if len(policy.allowRules) != tt.wantAllowLen {
+				t.Fatalf("len(allowRules) = %d, want %d: %v", len(policy.allowRules), tt.wantAllowLen, policy.allowRules)
+			}
+			if policy.allowRules[0] != tt.wantFirstRule {
+				t.Errorf("allowRules[0] = %+v, want %+v", policy.allowRules[0], tt.wantFirstRule)
+			}
+		})
+	}
+}
+
+func TestIsAclEligible(t *testing.T) {
+	tests := []struct {
+		rule onpremmodel.FirewallRuleProperty
+		want bool
+	}{
+		{inboundRule("deny", "tcp", "anywhere", "22"), true},
+		{inboundRule("deny", "UDP", "anywhere", "53,123"), true},
+		{inboundRule("deny", "tcp", "anywhere", "1024-65535"), true},
+		{inboundRule("deny", "tcp", "anywhere", "*"), false},
+		{inboundRule("deny", "tcp", "anywhere", ""), false},
+		{inboundRule("deny", "tcp", "anywhere", "1-65535"), false},
+		{inboundRule("deny", "tcp", "anywhere", "http"), false},
+		{inboundRule("deny", "icmp", "anywhere", ""), false},
+		{inboundRule("deny", "*", "anywhere", "22"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isAclEligible(tt.rule); got != tt.want {
+			t.Errorf("isAclEligible(%s %s) = %v, want %v", tt.rule.Protocol, tt.rule.DstPorts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package recommendation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// L7 (ALB) target mapping
+// ============================================================================
+
+// cspL7Services lists the managed L7 load balancer service of each CSP.
+// CSPs not listed here are treated as L4-only.
+var cspL7Services = map[string]string{
+	"aws":     "Application Load Balancer",
+	"azure":   "Application Gateway",
+	"gcp":     "Application Load Balancer",
+	"alibaba": "Application Load Balancer (ALB)",
+	"tencent": "Cloud Load Balancer (HTTP/HTTPS listener)",
+	"ibm":     "Application Load Balancer for VPC",
+	"ncp":     "Application Load Balancer",
+}
+
+// GetL7Support returns the L7 support level and the managed L7 service name of the CSP.
+func GetL7Support(csp string) (string, string) {
+	if service, ok := cspL7Services[strings.ToLower(csp)]; ok {
+		return cloudmodel.L7SupportNative, service
+	}
+	return cloudmodel.L7SupportL4Only, ""
+}
+
+// isHttpModeNlb reports whether the source NLB proxies HTTP (L7) traffic.
+func isHttpModeNlb(rnlb resolvedNlb) bool {
+	return strings.EqualFold(rnlb.sourceNlb.Backend.Protocol, "http")
+}
+
+// buildTargetAlbList converts HTTP-mode resolved NLB entries to cloudmodel.AlbReq list.
+// Source frontend-backend pairs sharing a listener (bind address and port) are merged into
+// one ALB whose routing rules select among the backends' target groups.
+// The list is identical for all candidates since ALB configuration does not vary by spec/image choice.
+func buildTargetAlbList(csp string, resolvedNlbs []resolvedNlb) []cloudmodel.AlbReq {
+	l7Support, cspService := GetL7Support(csp)
+
+	var targetAlbList []cloudmodel.AlbReq
+	albIndexByListener := map[string]int{}
+
+	for _, rnlb := range resolvedNlbs {
+		if !isHttpModeNlb(rnlb) {
+			continue
+		}
+		src := rnlb.sourceNlb
+
+		listenerKey := fmt.Sprintf("%s:%d", src.Listener.BindAddress, src.Listener.Port)
+		idx, exists := albIndexByListener[listenerKey]
+		if !exists {
+			albType := "PUBLIC"
+			name := fmt.Sprintf("alb-%d", src.Listener.Port)
+			if src.Listener.BindAddress != "" && src.Listener.BindAddress != "*" {
+				albType = "INTERNAL"
+				name = "alb-" + sanitizeName(src.Listener.BindAddress) + fmt.Sprintf("-%d", src.Listener.Port)
+			}
+
+			alb := cloudmodel.AlbReq{
+				Name:         name,
+				Description:  fmt.Sprintf("Migrated from %s HTTP frontend on %s", src.Software, listenerKey),
+				Type:         albType,
+				Scope:        defaultNlbScope,
+				L7Support:    l7Support,
+				CspService:   cspService,
+				Listener:     cloudmodel.AlbListenerReq{Protocol: "HTTP", Port: strconv.Itoa(src.Listener.Port)},
+				TargetGroups: []cloudmodel.AlbTargetGroupReq{},
+			}
+			switch l7Support {
+			case cloudmodel.L7SupportNative:
+				alb.Notes = append(alb.Notes, fmt.Sprintf(
+					"Provision as %s in %s. CB-Tumblebug provisions L4 NLBs only; the ALB migration creates an L4 NLB for the default target group and returns routing rules and TLS settings as manual actions.",
+					cspService, csp))
+			default:
+				alb.Notes = append(alb.Notes, fmt.Sprintf(
+					"[L4-only] %s does not offer a managed L7 load balancer. Host/path routing and TLS termination require a self-hosted proxy (e.g., HAProxy or nginx on a NodeGroup) behind an L4 NLB.",
+					csp))
+			}
+
+			targetAlbList = append(targetAlbList, alb)
+			idx = len(targetAlbList) - 1
+			albIndexByListener[listenerKey] = idx
+		}
+		alb := &targetAlbList[idx]
+
+		// TLS termination
+		if src.Listener.Tls {
+			alb.Listener.Protocol = "HTTPS"
+			if alb.Listener.Certificate == nil {
+				alb.Listener.Certificate = &cloudmodel.AlbCertificateReq{
+					Placeholder: fmt.Sprintf("<certificate-for-listener-%d>", src.Listener.Port),
+				}
+			}
+		}
+
+		// Target group (one per source backend)
+		targetGroupName := "tg-" + sanitizeName(src.Backend.Name)
+		if !hasAlbTargetGroup(*alb, targetGroupName) {
+			alb.TargetGroups = append(alb.TargetGroups, buildAlbTargetGroup(targetGroupName, rnlb))
+			if src.Backend.Balance != "" && src.Backend.Balance != "roundrobin" {
+				alb.Notes = append(alb.Notes, fmt.Sprintf(
+					"Backend '%s': load-balancing algorithm '%s' cannot be directly mapped; CSP default algorithm will be used.",
+					src.Backend.Name, src.Backend.Balance))
+			}
+		}
+
+		// Routing rules (empty = default backend)
+		if len(src.Rules) == 0 {
+			if alb.DefaultTargetGroup == "" {
+				alb.DefaultTargetGroup = targetGroupName
+			}
+			continue
+		}
+		for _, rule := range src.Rules {
+			alb.Rules = append(alb.Rules, cloudmodel.AlbRoutingRuleReq{
+				Priority:     len(alb.Rules) + 1,
+				Hosts:        rule.Hosts,
+				PathPrefixes: rule.PathPrefixes,
+				TargetGroup:  targetGroupName,
+			})
+			if alb.Listener.Certificate != nil {
+				alb.Listener.Certificate.Hosts = appendUnique(alb.Listener.Certificate.Hosts, rule.Hosts...)
+			}
+		}
+	}
+
+	for i := range targetAlbList {
+		alb := &targetAlbList[i]
+		if alb.DefaultTargetGroup == "" && len(alb.TargetGroups) > 0 {
+			alb.DefaultTargetGroup = alb.TargetGroups[0].Name
+			alb.Notes = append(alb.Notes, fmt.Sprintf(
+				"No default backend in the source frontend; target group '%s' is used for unmatched requests.",
+				alb.DefaultTargetGroup))
+		}
+		if alb.Listener.Certificate != nil {
+			alb.Notes = append(alb.Notes,
+				"TLS certificates are not collected from the source; replace the certificate placeholder with a certificate registered in the target CSP.")
+		}
+	}
+
+	log.Debug().Int("albs", len(targetAlbList)).Str("l7Support", l7Support).Msg("Target ALB list built")
+
+	return targetAlbList
+}
+
+// buildAlbTargetGroup builds an ALB target group from a resolved HTTP-mode NLB.
+func buildAlbTargetGroup(name string, rnlb resolvedNlb) cloudmodel.AlbTargetGroupReq {
+	src := rnlb.sourceNlb
+
+	hc := cloudmodel.AlbHealthCheckerReq{
+		Protocol:  "HTTP",
+		Path:      "/",
+		Interval:  defaultHealthCheckInterval,
+		Threshold: defaultHealthCheckThreshold,
+		Timeout:   defaultHealthCheckTimeout,
+	}
+	if src.HealthCheck.Enabled {
+		if strings.EqualFold(src.HealthCheck.Protocol, "tcp") {
+			hc.Protocol = "TCP"
+			hc.Path = ""
+		} else if src.HealthCheck.Path != "" {
+			hc.Path = src.HealthCheck.Path
+		}
+		if src.HealthCheck.Port > 0 {
+			hc.Port = strconv.Itoa(src.HealthCheck.Port)
+		}
+		if src.HealthCheck.Interval > 0 {
+			hc.Interval = src.HealthCheck.Interval
+		}
+		if src.HealthCheck.Timeout > 0 {
+			hc.Timeout = src.HealthCheck.Timeout
+		}
+		if src.HealthCheck.Threshold > 0 {
+			hc.Threshold = src.HealthCheck.Threshold
+		}
+	}
+
+	return cloudmodel.AlbTargetGroupReq{
+		Name:          name,
+		Protocol:      "HTTP",
+		Port:          strconv.Itoa(rnlb.backendPort),
+		NodeGroupId:   "ng-" + sanitizeName(src.Backend.Name),
+		HealthChecker: hc,
+	}
+}
+
+func hasAlbTargetGroup(alb cloudmodel.AlbReq, name string) bool {
+	for _, tg := range alb.TargetGroups {
+		if tg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique appends values not already in the list.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
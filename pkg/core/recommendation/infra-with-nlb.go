@@ -3,10 +3,12 @@ package recommendation
 import (
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 
 	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
 	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
 	"github.com/rs/zerolog/log"
 )
 
@@ -57,12 +59,13 @@ type nodeGroupBlueprint struct {
 // Processing phases:
 //  1. Build lookup indexes (IP → Node, MachineId → Node)
 //  2. Correlate NLB backend server IPs with source Nodes; normalize backend ports
-//  3. Build shared skeleton (vNet, SSH key)
+//  3. Build shared skeleton (vNets planned per private network, SSH key)
 //  4. Group source nodes into NodeGroups: NLB-related (N:1) and unrelated (1:1)
 //  5. Find ranked spec-image pairs per NodeGroup (sizingPolicy = upsizing)
-//  6. Build target NLB list — identical for all candidates
-//  7. Assemble candidates: candidate i assigns the i-th ranked pair to each NodeGroup
-func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra onpremmodel.OnpremInfra, limit int, minMatchRate float64) ([]cloudmodel.RecommendedInfra, error) {
+//  6. Build target NLB list (and L7 ALB list for HTTP-mode frontends) — identical for all candidates
+//  7. Assemble candidates: candidate i assigns the i-th ranked pair to each NodeGroup,
+//     then spreads load-balanced NodeGroups across the availability zones of the region
+func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra onpremmodel.OnpremInfra, limit int, minMatchRate float64, cidrPlanReq cloudmodel.CidrPlanReq) ([]cloudmodel.RecommendedInfra, error) {
 	if len(srcInfra.NLBs) == 0 {
 		return nil, fmt.Errorf("sourceInfra.nlbs is empty")
 	}
@@ -153,22 +156,19 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 		},
 	}
 
-	recommendedVNetList, err := RecommendVNet(csp, region, srcInfra)
-	if err != nil || len(recommendedVNetList) == 0 {
+	// One vNet per private network spanned by the source networks; CIDR blocks are planned by cidrPlanReq.
+	recommendedVNetList, cidrPlan, err := PlanVNets(csp, region, srcInfra, cidrPlanReq)
+	if err != nil {
+		if cidrPlanReq.Mode != "" || cidrPlanReq.NsId != "" {
+			return nil, fmt.Errorf("failed to plan vNets: %w", err)
+		}
 		log.Warn().Err(err).Msg("failed to recommend vNet for NLB-aware infra")
 	}
 	if len(recommendedVNetList) > 0 {
-		skeleton.TargetVNet = recommendedVNetList[0]
-	}
-	skeleton.TargetVNet.Name = "mig-vnet-01"
-	skeleton.TargetVNet.Description = "a recommended vNet for migration"
-	for i := range skeleton.TargetVNet.SubnetInfoList {
-		skeleton.TargetVNet.SubnetInfoList[i].Name = fmt.Sprintf("mig-subnet-%02d", i+1)
-		skeleton.TargetVNet.SubnetInfoList[i].Description = "a recommended subnet for migration"
-	}
-	firstSubnetId := ""
-	if len(skeleton.TargetVNet.SubnetInfoList) > 0 {
-		firstSubnetId = skeleton.TargetVNet.SubnetInfoList[0].Name
+		setPlannedVNets(&skeleton, recommendedVNetList, cidrPlan, "mig-vnet-%02d", "mig-subnet-%02d")
+	} else {
+		skeleton.TargetVNet.Name = "mig-vnet-01"
+		skeleton.TargetVNet.Description = "a recommended vNet for migration"
 	}
 
 	skeleton.TargetSshKey = cloudmodel.SshKeyReq{
@@ -183,6 +183,8 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 
 	var ngBlueprints []nodeGroupBlueprint
 	var deduplicatedSgList []cloudmodel.SecurityGroupReq
+	aclSupport, _ := GetAclSupport(csp)
+	aclSupported := aclSupport == cloudmodel.AclSupportNative
 
 	// NLB-related NodeGroups — added first so indexes remain stable when NLB target list references them.
 	// NodeGroup name "ng-<backendName>" must match the nodeGroupId used in buildTargetNlbList.
@@ -192,7 +194,7 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 		ngId := "ng-" + sanitizeName(backendName)
 		// Synthetic node: CPU from max-vCPU member; memory and root disk are per-dimension maxima.
 		syntheticNode := synthesizeGroupRepresentativeNode(rnlb.memberMachineIds, nodeByMachineId)
-		nodeWithMergedFirewallRules := mergeNodesFirewallRules(rnlb.memberMachineIds, nodeByMachineId, syntheticNode)
+		nodeWithMergedFirewallRules := mergeNodesFirewallRules(rnlb.memberMachineIds, nodeByMachineId, syntheticNode, aclSupported)
 
 		sg, sgErr := RecommendSecurityGroup(csp, region, nodeWithMergedFirewallRules)
 		if sgErr != nil {
@@ -211,14 +213,30 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 
 		rootDiskSize := max(int(syntheticNode.RootDisk.TotalSize), getCspMinRootDiskSizeGB(csp))
 
+		memberHostnames := make([]string, 0, len(rnlb.memberMachineIds))
+		for _, machineId := range rnlb.memberMachineIds {
+			memberHostnames = append(memberHostnames, nodeByMachineId[machineId].Hostname)
+		}
+
+		// Network of the first backend member (members are expected to share a source network)
+		vNetId, subnetId := selectNodeNetwork(skeleton, nodeByMachineId[rnlb.memberMachineIds[0]])
+		for _, machineId := range rnlb.memberMachineIds[1:] {
+			if memberVNetId, memberSubnetId := selectNodeNetwork(skeleton, nodeByMachineId[machineId]); memberVNetId != vNetId || memberSubnetId != subnetId {
+				warnings = append(warnings, fmt.Sprintf(
+					"NLB backend '%s': members span multiple source networks; NodeGroup %s is placed in subnet %s",
+					backendName, ngId, subnetId))
+				break
+			}
+		}
+
 		ngBlueprints = append(ngBlueprints, nodeGroupBlueprint{
 			representativeNode: syntheticNode,
 			isNlbRelated:       true,
 			skeleton: cloudmodel.CreateNodeGroupReq{
 				ConnectionName:   connectionName,
 				Name:             ngId,
-				VNetId:           skeleton.TargetVNet.Name,
-				SubnetId:         firstSubnetId,
+				VNetId:           vNetId,
+				SubnetId:         subnetId,
 				SecurityGroupIds: []string{sg.Name},
 				SshKeyId:         skeleton.TargetSshKey.Name,
 				RootDiskType:     "",
@@ -226,8 +244,9 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 				NodeGroupSize:    nodeGroupSize,
 				Description:      fmt.Sprintf("Recommended VM for NLB backend %s (%d nodes)", backendName, nodeGroupSize),
 				Label: map[string]string{
-					"sourceMachineIds": strings.Join(rnlb.memberMachineIds, ","),
-					"nlbBackend":       backendName,
+					common.LabelSourceMachineIds: strings.Join(rnlb.memberMachineIds, ","),
+					common.LabelSourceHostnames:  strings.Join(memberHostnames, ","),
+					"nlbBackend":                 backendName,
 				},
 			},
 		})
@@ -257,6 +276,7 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 		}
 
 		rootDiskSize := max(int(node.RootDisk.TotalSize), getCspMinRootDiskSizeGB(csp))
+		vNetId, subnetId := selectNodeNetwork(skeleton, node)
 
 		ngBlueprints = append(ngBlueprints, nodeGroupBlueprint{
 			representativeNode: node,
@@ -264,19 +284,20 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 			skeleton: cloudmodel.CreateNodeGroupReq{
 				ConnectionName:   connectionName,
 				Name:             ngName,
-				VNetId:           skeleton.TargetVNet.Name,
-				SubnetId:         firstSubnetId,
+				VNetId:           vNetId,
+				SubnetId:         subnetId,
 				SecurityGroupIds: []string{sg.Name},
 				SshKeyId:         skeleton.TargetSshKey.Name,
 				RootDiskType:     "",
 				RootDiskSize:     rootDiskSize,
 				NodeGroupSize:    1,
 				Description:      fmt.Sprintf("Recommended VM %02d for %s", i+1, node.MachineId),
-				Label:            map[string]string{"sourceMachineIds": node.MachineId},
+				Label:            map[string]string{common.LabelSourceMachineIds: node.MachineId, common.LabelSourceHostnames: node.Hostname},
 			},
 		})
 	}
 
+	rewriteSecurityGroupCidrs(deduplicatedSgList, cidrPlan)
 	skeleton.TargetSecurityGroupList = deduplicatedSgList
 
 	// ── Phase 5: find compatible spec-image pairs per NodeGroup ─────────────
@@ -319,6 +340,23 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 	// ── Phase 6: build target NLB list — identical for all candidates ─────────
 	targetNlbList := buildTargetNlbList(resolvedNlbs)
 
+	// HTTP-mode frontends are additionally recommended as L7 ALBs (host/path routing, TLS termination).
+	// The L4 NLBs above remain available as a fallback.
+	targetAlbList := buildTargetAlbList(csp, resolvedNlbs)
+	if len(targetAlbList) > 0 {
+		if l7Support, _ := GetL7Support(csp); l7Support == cloudmodel.L7SupportL4Only {
+			warnings = append(warnings, fmt.Sprintf(
+				"%d HTTP-mode frontend(s) found, but %s supports L4 load balancers only — L7 routing and TLS termination need a self-hosted proxy",
+				len(targetAlbList), csp))
+		}
+	}
+
+	// Availability zones of the region for NodeGroup placement (shared by all candidates)
+	zones, zoneErr := GetRegionZones(csp, region)
+	if zoneErr != nil {
+		log.Warn().Err(zoneErr).Msg("failed to get availability zones; NodeGroups are placed in the CSP default zone")
+	}
+
 	// ── Phase 7: assemble candidates — candidate i uses the i-th ranked pair per NodeGroup ──
 	// maxCandidates = max pairs available across all NodeGroups, capped at limit.
 	// NodeGroups with fewer than i+1 pairs are skipped for candidate i.
@@ -437,6 +475,8 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 		candidate.TargetSpecList = candidateSpecList
 		candidate.TargetOsImageList = candidateImageList
 		candidate.TargetNlbList = targetNlbList
+		candidate.TargetAlbList = targetAlbList
+		bindSecurityGroupsToVNets(&candidate)
 
 		overallStatus, overallStatusDesc, summary := calculateCandidateMatchRateWithDetails(
 			csp, candidateNodeGroups, syntheticSrcInfra, candidateSpecList, candidateImageList, minMatchRate,
@@ -447,16 +487,26 @@ func RecommendInfraWithNlbCandidates(desiredCsp, desiredRegion string, srcInfra
 		if len(warnings) > 0 {
 			nlbWarningNote = fmt.Sprintf(" | %d NLB warning(s): %s", len(warnings), strings.Join(warnings, "; "))
 		}
+		albNote := ""
+		if len(targetAlbList) > 0 {
+			albNote = fmt.Sprintf(" | %d ALB(s)", len(targetAlbList))
+		}
 		candidate.Description = fmt.Sprintf(
-			"Candidate #%d | %s | %d NLB(s) | Overall Match Rate: Min=%.1f%% Max=%.1f%% Avg=%.1f%% | %s%s",
+			"Candidate #%d | %s | %d NLB(s)%s | Overall Match Rate: Min=%.1f%% Max=%.1f%% Avg=%.1f%% | %s%s%s",
 			candidateIdx+1,
 			overallStatus,
 			len(targetNlbList),
+			albNote,
 			summary.MinMatchRate, summary.MaxMatchRate, summary.AvgMatchRate,
 			overallStatusDesc,
 			nlbWarningNote,
+			ipv6WarningNote(candidate.CidrPlan),
 		)
 
+		// Zone placement splits load-balanced NodeGroups, so it follows the match rate calculation
+		applyZonePlacement(&candidate, zones, srcInfra.Nodes)
+		applyFirewallPolicy(csp, &candidate, srcInfra.Nodes)
+
 		candidates = append(candidates, candidate)
 
 		log.Debug().
@@ -607,10 +657,12 @@ func synthesizeGroupRepresentativeNode(memberMachineIds []string, nodeByMachineI
 
 // mergeNodesFirewallRules returns a copy of representativeNode whose FirewallTable is the union
 // of all firewall rules from every member node. Used so the Security Group covers all members.
-func mergeNodesFirewallRules(memberMachineIds []string, nodeByMachineId map[string]onpremmodel.NodeProperty, representativeNode onpremmodel.NodeProperty) onpremmodel.NodeProperty {
+// * Note: The ordered rules of each member are compiled into allow rules first (see compileFirewallPolicy),
+// since the deny rules of a member must not narrow the allow rules of the other members.
+func mergeNodesFirewallRules(memberMachineIds []string, nodeByMachineId map[string]onpremmodel.NodeProperty, representativeNode onpremmodel.NodeProperty, aclSupported bool) onpremmodel.NodeProperty {
 	merged := representativeNode
 	ruleSet := make(map[string]onpremmodel.FirewallRuleProperty)
-	for _, rule := range representativeNode.FirewallTable {
+	for _, rule := range compileFirewallPolicy(representativeNode.FirewallTable, aclSupported).allowRules {
 		ruleSet[firewallRuleKey(rule)] = rule
 	}
 	for _, machineId := range memberMachineIds {
@@ -618,7 +670,7 @@ func mergeNodesFirewallRules(memberMachineIds []string, nodeByMachineId map[stri
 		if !ok || node.MachineId == representativeNode.MachineId {
 			continue
 		}
-		for _, rule := range node.FirewallTable {
+		for _, rule := range compileFirewallPolicy(node.FirewallTable, aclSupported).allowRules {
 			ruleSet[firewallRuleKey(rule)] = rule
 		}
 	}
@@ -626,6 +678,9 @@ func mergeNodesFirewallRules(memberMachineIds []string, nodeByMachineId map[stri
 	for _, rule := range ruleSet {
 		mergedRules = append(mergedRules, rule)
 	}
+	sort.Slice(mergedRules, func(i, j int) bool {
+		return firewallRuleKey(mergedRules[i]) < firewallRuleKey(mergedRules[j])
+	})
 	merged.FirewallTable = mergedRules
 	return merged
 }
@@ -130,3 +130,14 @@ type RecommendedInfraInfo struct {
 	Description string                `json:"description"`
 	TargetInfra tbmodel.InfraDynamicReq `json:"targetInfra"`
 }
+
+// RecommendedK8sCluster represents the recommended K8s cluster with its node groups.
+// TargetCluster is the request body for the cb-tumblebug k8sClusterDynamic API,
+// and each of TargetNodeGroups is the request body for the cb-tumblebug k8sNodeGroup API.
+type RecommendedK8sCluster struct {
+	Status           string                       `json:"status"`
+	Description      string                       `json:"description"`
+	SourceVersion    string                       `json:"sourceVersion,omitempty"`
+	TargetCluster    tbmodel.K8sClusterDynamicReq `json:"targetCluster"`
+	TargetNodeGroups []tbmodel.K8sNodeGroupReq    `json:"targetNodeGroups"`
+}
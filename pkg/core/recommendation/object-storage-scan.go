@@ -0,0 +1,309 @@
+package recommendation
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	storagemodel "github.com/cloud-barista/cm-beetle/imdl/storage-model"
+	"github.com/cloud-barista/cm-beetle/transx"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Source object storage scanner
+// Builds SourceObjectStorage (usage and inventory) by listing the objects of a
+// source bucket via transx.S3Provider (minio, spider, tumblebug access types).
+// ============================================================================
+
+// Limits of the prefix tree in the bucket inventory
+const (
+	maxPrefixDepth    = 2  // Levels of "/"-delimited prefixes
+	maxPrefixChildren = 20 // Largest prefixes kept per level (the rest are merged into "(others)")
+)
+
+// Thresholds to infer the access frequency from the last-modified distribution
+const (
+	frequentAgeDays    = 30
+	infrequentAgeDays  = 180
+	activeBytesPercent = 10.0 // Share of bytes modified within the age to be regarded as active
+)
+
+// sizeHistogramBounds are the upper bounds of the size histogram bins (the last bin is unbounded).
+// 5 GiB is the single-upload limit of S3-compatible storages (larger objects need multipart uploads).
+var sizeHistogramBounds = []struct {
+	label    string
+	maxBytes int64
+}{
+	{"0-1KiB", 1 << 10},
+	{"1KiB-64KiB", 64 << 10},
+	{"64KiB-1MiB", 1 << 20},
+	{"1MiB-16MiB", 16 << 20},
+	{"16MiB-128MiB", 128 << 20},
+	{"128MiB-1GiB", 1 << 30},
+	{"1GiB-5GiB", 5 << 30},
+	{"5GiB+", 0},
+}
+
+// lastModifiedBounds are the upper bounds (in days) of the last-modified distribution bins (the last bin is unbounded).
+var lastModifiedBounds = []struct {
+	label      string
+	maxAgeDays int
+}{
+	{"0d-7d", 7},
+	{"7d-30d", 30},
+	{"30d-90d", 90},
+	{"90d-180d", 180},
+	{"180d-365d", 365},
+	{"365d+", 0},
+}
+
+// ScanSourceObjectStorage lists the objects of the source bucket at the location and builds
+// a SourceObjectStorage with the usage (size, object count, access frequency) and the inventory.
+// The key part of loc.Path (e.g., "my-bucket/logs/") limits the scan to the prefix.
+//
+// * Note: The features (versioning, CORS, encryption, public access) and tags of the bucket are not
+// * observable with S3Provider, so they are left empty to be supplied by the caller.
+func ScanSourceObjectStorage(loc transx.DataLocation) (storagemodel.SourceObjectStorage, error) {
+	provider, err := transx.NewS3Provider(loc)
+	if err != nil {
+		return storagemodel.SourceObjectStorage{}, fmt.Errorf("invalid source location (%s): %w", loc.Path, err)
+	}
+
+	_, prefix := transx.ParseBucketAndKey(loc.Path)
+
+	log.Info().
+		Str("bucket", provider.GetBucket()).
+		Str("prefix", prefix).
+		Str("accessType", loc.ObjectStorage.AccessType).
+		Msg("Scanning source object storage")
+
+	objects, err := provider.ListObjects(prefix)
+	if err != nil {
+		return storagemodel.SourceObjectStorage{}, fmt.Errorf("failed to list objects of the bucket (%s): %w", provider.GetBucket(), err)
+	}
+
+	source := buildSourceObjectStorage(provider.GetBucket(), objects, time.Now().UTC())
+
+	log.Info().
+		Str("bucket", source.BucketName).
+		Int64("objectCount", source.ObjectCount).
+		Int64("totalSizeBytes", source.TotalSizeBytes).
+		Str("accessFrequency", source.AccessFrequency).
+		Msg("Source object storage scanned")
+
+	return source, nil
+}
+
+// buildSourceObjectStorage builds the usage and inventory of the bucket from its objects.
+func buildSourceObjectStorage(bucketName string, objects []transx.ObjectInfo, now time.Time) storagemodel.SourceObjectStorage {
+	inventory := &storagemodel.BucketInventoryProperty{
+		ScannedAt:                now.Format(time.RFC3339),
+		SizeHistogram:            make([]storagemodel.SizeHistogramBin, len(sizeHistogramBounds)),
+		LastModifiedDistribution: make([]storagemodel.LastModifiedBin, len(lastModifiedBounds)),
+	}
+
+	var minBytes int64
+	for i, b := range sizeHistogramBounds {
+		inventory.SizeHistogram[i] = storagemodel.SizeHistogramBin{Label: b.label, MinBytes: minBytes, MaxBytes: b.maxBytes}
+		minBytes = b.maxBytes
+	}
+	minAgeDays := 0
+	for i, b := range lastModifiedBounds {
+		inventory.LastModifiedDistribution[i] = storagemodel.LastModifiedBin{Label: b.label, MinAgeDays: minAgeDays, MaxAgeDays: b.maxAgeDays}
+		minAgeDays = b.maxAgeDays
+	}
+
+	var totalSize, unknownAge int64
+	var oldest, newest time.Time
+	root := &prefixTrieNode{}
+
+	for _, obj := range objects {
+		totalSize += obj.Size
+
+		bin := &inventory.SizeHistogram[sizeHistogramBin(obj.Size)]
+		bin.ObjectCount++
+		bin.TotalSizeBytes += obj.Size
+
+		modified, ok := parseLastModified(obj.LastModified)
+		if !ok {
+			unknownAge++
+		} else {
+			if oldest.IsZero() || modified.Before(oldest) {
+				oldest = modified
+			}
+			if newest.IsZero() || modified.After(newest) {
+				newest = modified
+			}
+			ageBin := &inventory.LastModifiedDistribution[lastModifiedBin(now.Sub(modified))]
+			ageBin.ObjectCount++
+			ageBin.TotalSizeBytes += obj.Size
+		}
+
+		root.add(obj.Key, obj.Size, maxPrefixDepth)
+	}
+
+	if !oldest.IsZero() {
+		inventory.OldestModified = oldest.Format(time.RFC3339)
+		inventory.NewestModified = newest.Format(time.RFC3339)
+	}
+	inventory.PrefixTree = root.toPrefixNodes("")
+
+	accessFrequency, basis := inferAccessFrequency(inventory.LastModifiedDistribution)
+	if unknownAge > 0 {
+		basis += fmt.Sprintf(" (%d object(s) without a valid last-modified time are excluded)", unknownAge)
+	}
+	inventory.AccessFrequencyBasis = basis
+
+	return storagemodel.SourceObjectStorage{
+		BucketName: bucketName,
+		BucketUsageProperty: storagemodel.BucketUsageProperty{
+			TotalSizeBytes:  totalSize,
+			ObjectCount:     int64(len(objects)),
+			AccessFrequency: accessFrequency,
+		},
+		Inventory: inventory,
+	}
+}
+
+// sizeHistogramBin returns the index of the size histogram bin for the object size.
+func sizeHistogramBin(size int64) int {
+	for i, b := range sizeHistogramBounds {
+		if b.maxBytes == 0 || size < b.maxBytes {
+			return i
+		}
+	}
+	return len(sizeHistogramBounds) - 1
+}
+
+// lastModifiedBin returns the index of the last-modified distribution bin for the age.
+func lastModifiedBin(age time.Duration) int {
+	days := int(age.Hours() / 24)
+	for i, b := range lastModifiedBounds {
+		if b.maxAgeDays == 0 || days < b.maxAgeDays {
+			return i
+		}
+	}
+	return len(lastModifiedBounds) - 1
+}
+
+// parseLastModified parses the last-modified time of an object.
+// The providers return RFC 3339 (minio, tumblebug, S3 XML) or HTTP date (some S3-compatible storages) formats.
+func parseLastModified(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, http.TimeFormat, time.RFC1123Z} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inferAccessFrequency infers the access frequency from the share of bytes modified recently.
+// Only the last-modified times are observable (reads are not), so buckets read often but rarely
+// written may be inferred as less frequently accessed than they are.
+func inferAccessFrequency(distribution []storagemodel.LastModifiedBin) (accessFrequency, basis string) {
+	var total, frequent, infrequent int64
+	for _, bin := range distribution {
+		total += bin.TotalSizeBytes
+		if bin.MaxAgeDays != 0 && bin.MaxAgeDays <= frequentAgeDays {
+			frequent += bin.TotalSizeBytes
+		}
+		if bin.MaxAgeDays != 0 && bin.MaxAgeDays <= infrequentAgeDays {
+			infrequent += bin.TotalSizeBytes
+		}
+	}
+
+	if total == 0 {
+		return accessFrequent, "no data with a last-modified time; assumed frequent"
+	}
+
+	frequentPercent := float64(frequent) / float64(total) * 100
+	infrequentPercent := float64(infrequent) / float64(total) * 100
+
+	switch {
+	case frequentPercent >= activeBytesPercent:
+		return accessFrequent, fmt.Sprintf("%.1f%% of bytes modified within %d days", frequentPercent, frequentAgeDays)
+	case infrequentPercent >= activeBytesPercent:
+		return accessInfrequent, fmt.Sprintf("%.1f%% of bytes modified within %d days (%.1f%% within %d days)",
+			infrequentPercent, infrequentAgeDays, frequentPercent, frequentAgeDays)
+	default:
+		return accessArchive, fmt.Sprintf("%.1f%% of bytes modified within %d days", infrequentPercent, infrequentAgeDays)
+	}
+}
+
+// prefixTrieNode aggregates the objects under a "/"-delimited key prefix.
+type prefixTrieNode struct {
+	objectCount int64
+	totalSize   int64
+	children    map[string]*prefixTrieNode // Keyed by the path segment (with the trailing "/")
+}
+
+// add counts the object in the prefixes of its key up to depth levels.
+// Objects directly under the node (no further "/") are counted in the node only.
+func (n *prefixTrieNode) add(key string, size int64, depth int) {
+	n.objectCount++
+	n.totalSize += size
+
+	if depth == 0 {
+		return
+	}
+	idx := strings.Index(key, "/")
+	if idx < 0 {
+		return
+	}
+
+	segment := key[:idx+1]
+	if n.children == nil {
+		n.children = map[string]*prefixTrieNode{}
+	}
+	child, ok := n.children[segment]
+	if !ok {
+		child = &prefixTrieNode{}
+		n.children[segment] = child
+	}
+	child.add(key[idx+1:], size, depth-1)
+}
+
+// toPrefixNodes converts the children of the node to PrefixNodes, largest first.
+// Children beyond maxPrefixChildren are merged into an "(others)" node.
+func (n *prefixTrieNode) toPrefixNodes(parent string) []storagemodel.PrefixNode {
+	if len(n.children) == 0 {
+		return nil
+	}
+
+	segments := make([]string, 0, len(n.children))
+	for segment := range n.children {
+		segments = append(segments, segment)
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		a, b := n.children[segments[i]], n.children[segments[j]]
+		if a.totalSize != b.totalSize {
+			return a.totalSize > b.totalSize
+		}
+		return segments[i] < segments[j]
+	})
+
+	nodes := make([]storagemodel.PrefixNode, 0, min(len(segments), maxPrefixChildren+1))
+	for i, segment := range segments {
+		child := n.children[segment]
+		if i >= maxPrefixChildren {
+			others := &nodes[len(nodes)-1]
+			if others.Prefix != parent+"(others)" {
+				nodes = append(nodes, storagemodel.PrefixNode{Prefix: parent + "(others)"})
+				others = &nodes[len(nodes)-1]
+			}
+			others.ObjectCount += child.objectCount
+			others.TotalSizeBytes += child.totalSize
+			continue
+		}
+		nodes = append(nodes, storagemodel.PrefixNode{
+			Prefix:         parent + segment,
+			ObjectCount:    child.objectCount,
+			TotalSizeBytes: child.totalSize,
+			Children:       child.toPrefixNodes(parent + segment),
+		})
+	}
+	return nodes
+}
@@ -0,0 +1,323 @@
+package recommendation
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	storagemodel "github.com/cloud-barista/cm-beetle/imdl/storage-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Object storage (bucket) recommendation
+// ============================================================================
+
+// Access frequencies of the source buckets
+const (
+	accessFrequent   = "frequent"
+	accessInfrequent = "infrequent"
+	accessArchive    = "archive"
+)
+
+// cspStorageClasses maps the source access frequency to the storage class of each CSP.
+// A missing access tier falls back to the next more frequent tier (see storageClassFor).
+var cspStorageClasses = map[string]map[string]string{
+	"aws":       {accessFrequent: "STANDARD", accessInfrequent: "STANDARD_IA", accessArchive: "GLACIER"},
+	"azure":     {accessFrequent: "Hot", accessInfrequent: "Cool", accessArchive: "Archive"},
+	"gcp":       {accessFrequent: "STANDARD", accessInfrequent: "NEARLINE", accessArchive: "ARCHIVE"},
+	"alibaba":   {accessFrequent: "Standard", accessInfrequent: "IA", accessArchive: "Archive"},
+	"tencent":   {accessFrequent: "STANDARD", accessInfrequent: "STANDARD_IA", accessArchive: "ARCHIVE"},
+	"ibm":       {accessFrequent: "standard", accessInfrequent: "vault", accessArchive: "cold"},
+	"ncp":       {accessFrequent: "STANDARD"},
+	"nhn":       {accessFrequent: "STANDARD"},
+	"kt":        {accessFrequent: "STANDARD"},
+	"openstack": {accessFrequent: "STANDARD"},
+}
+
+// Server-side encryption support levels of the target CSP
+const (
+	encryptionDefault  = "default"  // Always encrypted with CSP-managed keys
+	encryptionOptional = "optional" // Must be enabled per bucket
+	encryptionNone     = "none"     // Not offered (objects must be encrypted client-side)
+)
+
+// cspBucketEncryption lists the server-side encryption support of each CSP.
+// CSPs not listed here are treated as not offering server-side encryption.
+var cspBucketEncryption = map[string]string{
+	"aws":     encryptionDefault,
+	"azure":   encryptionDefault,
+	"gcp":     encryptionDefault,
+	"ibm":     encryptionDefault,
+	"alibaba": encryptionOptional,
+	"tencent": encryptionOptional,
+	"ncp":     encryptionOptional,
+}
+
+// bucketTagRule is the tag (label) restrictions of a CSP.
+type bucketTagRule struct {
+	maxTags     int
+	maxKeyLen   int
+	maxValueLen int
+	invalid     *regexp.Regexp // Characters replaced with '_' (nil: no restriction)
+	lowercase   bool
+}
+
+// defaultBucketTagRule is the S3-compatible tag restrictions.
+var defaultBucketTagRule = bucketTagRule{maxTags: 10, maxKeyLen: 128, maxValueLen: 256}
+
+// cspBucketTagRules holds the CSP-specific tag restrictions.
+var cspBucketTagRules = map[string]bucketTagRule{
+	"aws":   {maxTags: 50, maxKeyLen: 128, maxValueLen: 256},
+	"azure": {maxTags: 50, maxKeyLen: 128, maxValueLen: 256, invalid: regexp.MustCompile(`[^A-Za-z0-9_]`)}, // Container metadata (C# identifiers)
+	"gcp":   {maxTags: 64, maxKeyLen: 63, maxValueLen: 63, invalid: regexp.MustCompile(`[^a-z0-9_-]`), lowercase: true},
+}
+
+// RecommendObjectStorage recommends the target buckets for the source object storages.
+// It carries versioning, CORS, server-side encryption, public access and tags of the source buckets,
+// and derives the storage class from the access frequency.
+// The recommendation is degraded (with warnings) where the target CSP does not support a feature.
+func RecommendObjectStorage(csp, region string, sources []storagemodel.SourceObjectStorage) (storagemodel.RecommendedObjectStorage, error) {
+	csp = strings.ToLower(csp)
+
+	if len(sources) == 0 {
+		return storagemodel.RecommendedObjectStorage{}, fmt.Errorf("at least one source object storage is required")
+	}
+
+	// Fetch CSP feature support from Tumblebug
+	supportResp, err := tbclient.NewSession().GetObjectStorageSupport(csp)
+	if err != nil {
+		log.Warn().Err(err).Str("csp", csp).Msg("Failed to fetch CSP object storage support info; proceeding without support check")
+	}
+
+	support, hasSupportInfo := supportResp.Supports[csp]
+	if !hasSupportInfo {
+		log.Warn().Str("csp", csp).Msg("No support info found for CSP; all features assumed supported")
+	}
+
+	corsSupported := !hasSupportInfo || support.Cors
+	versioningSupported := !hasSupportInfo || support.Versioning
+
+	log.Debug().
+		Str("csp", csp).
+		Bool("corsSupported", corsSupported).
+		Bool("versioningSupported", versioningSupported).
+		Msg("CSP object storage feature support")
+
+	var warnings []string
+	warn := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		warnings = append(warnings, msg)
+		log.Info().Msg(msg)
+	}
+
+	targetObjectStorages := make([]storagemodel.TargetObjectStorage, 0, len(sources))
+	bucketNames := map[string]bool{}
+
+	for _, source := range sources {
+		spec := storagemodel.BucketSpecProperty{
+			VersioningEnabled: source.VersioningEnabled,
+			CORSEnabled:       source.CORSEnabled,
+			CORSRule:          source.CORSRule,
+		}
+
+		// Adjust based on CSP support and emit warnings
+		if source.VersioningEnabled && !versioningSupported {
+			spec.VersioningEnabled = false
+			warn("Bucket '%s': versioning disabled (not supported on %s)", source.BucketName, csp)
+		}
+		if source.CORSEnabled && !corsSupported {
+			spec.CORSEnabled = false
+			spec.CORSRule = nil
+			warn("Bucket '%s': CORS disabled (not supported on %s)", source.BucketName, csp)
+		}
+
+		// Server-side encryption
+		switch cspBucketEncryption[csp] {
+		case encryptionDefault:
+			spec.EncryptionEnabled = true
+		case encryptionOptional:
+			spec.EncryptionEnabled = source.EncryptionEnabled
+		default:
+			if source.EncryptionEnabled {
+				warn("Bucket '%s': server-side encryption disabled (not offered on %s); encrypt the objects client-side", source.BucketName, csp)
+			}
+		}
+		if spec.EncryptionEnabled {
+			spec.EncryptionAlgorithm = "AES256"
+		}
+
+		// Public access
+		spec.PublicAccessBlocked = !source.IsPublic
+		if source.IsPublic {
+			warn("Bucket '%s': public access is not blocked since the source bucket is public; review the bucket policy before exposing it on %s", source.BucketName, csp)
+		}
+
+		// Storage class
+		storageClass, fallback := storageClassFor(csp, source.AccessFrequency)
+		spec.StorageClass = storageClass
+		if fallback {
+			warn("Bucket '%s': storage class '%s' is used since %s offers no storage class for '%s' access", source.BucketName, storageClass, csp, source.AccessFrequency)
+		}
+
+		// Tags
+		tags, dropped := normalizeBucketTags(csp, source.Tags)
+		spec.Tags = tags
+		if len(dropped) > 0 {
+			warn("Bucket '%s': %d tag(s) not propagated (tag limit exceeded on %s): %s", source.BucketName, len(dropped), csp, strings.Join(dropped, ", "))
+		}
+
+		targetBucketName, err := generateBucketName(csp, source.BucketName, bucketNames)
+		if err != nil {
+			return storagemodel.RecommendedObjectStorage{}, fmt.Errorf("failed to generate a bucket name for '%s': %w", source.BucketName, err)
+		}
+		bucketNames[targetBucketName] = true
+
+		targetObjectStorages = append(targetObjectStorages, storagemodel.TargetObjectStorage{
+			SourceBucketName:   source.BucketName,
+			BucketName:         targetBucketName,
+			BucketSpecProperty: spec,
+		})
+
+		log.Debug().
+			Str("sourceBucket", source.BucketName).
+			Str("targetBucket", targetBucketName).
+			Bool("versioning", spec.VersioningEnabled).
+			Bool("cors", spec.CORSEnabled).
+			Bool("encryption", spec.EncryptionEnabled).
+			Bool("publicAccessBlocked", spec.PublicAccessBlocked).
+			Str("storageClass", spec.StorageClass).
+			Int("tags", len(spec.Tags)).
+			Msg("Generated target object storage recommendation")
+	}
+
+	// Determine overall status
+	status := "success"
+	if len(warnings) > 0 {
+		status = "partial"
+	}
+
+	return storagemodel.RecommendedObjectStorage{
+		Status:               status,
+		Description:          fmt.Sprintf("Successfully recommended %d object storage configuration(s)", len(targetObjectStorages)),
+		Warnings:             warnings,
+		TargetCloud:          storagemodel.CloudProperty{Csp: csp, Region: region},
+		TargetObjectStorages: targetObjectStorages,
+	}, nil
+}
+
+// storageClassFor returns the storage class of the CSP for the access frequency.
+// An empty or unknown access frequency is treated as frequent.
+// fallback is true if the CSP offers no storage class for the access frequency.
+func storageClassFor(csp, accessFrequency string) (storageClass string, fallback bool) {
+	classes, ok := cspStorageClasses[csp]
+	if !ok {
+		return "", false
+	}
+
+	tiers := []string{accessFrequent, accessInfrequent, accessArchive}
+	idx := 0
+	for i, tier := range tiers {
+		if strings.EqualFold(accessFrequency, tier) {
+			idx = i
+		}
+	}
+
+	for i := idx; i >= 0; i-- {
+		if class, ok := classes[tiers[i]]; ok {
+			return class, i != idx
+		}
+	}
+	return "", false
+}
+
+// normalizeBucketTags adapts the source tags to the tag restrictions of the CSP.
+// Tags beyond the CSP limit are dropped (in key order) and returned as dropped.
+func normalizeBucketTags(csp string, srcTags map[string]string) (tags map[string]string, dropped []string) {
+	if len(srcTags) == 0 {
+		return nil, nil
+	}
+
+	rule, ok := cspBucketTagRules[csp]
+	if !ok {
+		rule = defaultBucketTagRule
+	}
+
+	keys := make([]string, 0, len(srcTags))
+	for k := range srcTags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	normalize := func(s string, maxLen int) string {
+		if rule.lowercase {
+			s = strings.ToLower(s)
+		}
+		if rule.invalid != nil {
+			s = rule.invalid.ReplaceAllString(s, "_")
+		}
+		if len(s) > maxLen {
+			s = s[:maxLen]
+		}
+		return s
+	}
+
+	tags = make(map[string]string, len(srcTags))
+	for _, k := range keys {
+		key := normalize(k, rule.maxKeyLen)
+		if key == "" {
+			continue
+		}
+		if len(tags) >= rule.maxTags {
+			dropped = append(dropped, k)
+			continue
+		}
+		tags[key] = normalize(srcTags[k], rule.maxValueLen)
+	}
+	return tags, dropped
+}
+
+// nonBucketNameChars matches the characters not allowed in bucket names.
+var nonBucketNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// generateBucketName derives a globally-unique, CSP-valid bucket name from the source bucket name.
+// The name is "<sanitized source name>-<random hex>" and leaves room for a nameSeed prefix
+// (see common.BucketNameSeedReserve) so that late binding keeps it within the CSP limits.
+func generateBucketName(csp, sourceName string, taken map[string]bool) (string, error) {
+	const suffixLen = 6
+
+	base := nonBucketNameChars.ReplaceAllString(strings.ToLower(sourceName), "-")
+	if csp == "gcp" {
+		base = strings.ReplaceAll(base, "google", "g-oogle")
+		base = strings.ReplaceAll(base, "goog", "g-oog")
+	}
+
+	maxBaseLen := common.BucketNameMaxLength(csp) - common.BucketNameSeedReserve - 1 - suffixLen
+	if len(base) > maxBaseLen {
+		base = base[:maxBaseLen]
+	}
+	base = strings.Trim(base, "-")
+	if base == "" {
+		base = "bucket"
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		suffix := make([]byte, suffixLen/2)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		name := fmt.Sprintf("%s-%s", base, hex.EncodeToString(suffix))
+		if ok, detail := common.IsValidBucketName(csp, name); !ok {
+			return "", fmt.Errorf("invalid bucket name (%s): %s", name, detail)
+		}
+		if !taken[name] {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate a unique bucket name for '%s'", sourceName)
+}
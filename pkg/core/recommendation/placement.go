@@ -0,0 +1,361 @@
+package recommendation
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// Availability zone placement
+// ============================================================================
+
+// Placement policies
+const (
+	PlacementPolicySpread = "spread" // NodeGroups sharing a role are spread across zones (load-balanced tiers are not)
+	PlacementPolicyNone   = "none"   // No zone information; the CSP default zone is used
+)
+
+// Tier kinds of a NodeGroup
+const (
+	placementTierNlb  = "nlb"  // NodeGroup serving an NLB backend (replicas in one NodeGroup)
+	placementTierRole = "role" // NodeGroups sharing the role of their source nodes
+	placementTierNode = "node" // Single NodeGroup with no replica information
+)
+
+// placementTier is a set of NodeGroups holding replicas of the same tier.
+type placementTier struct {
+	key          string // e.g., "nlb:web-backend", "role:worker", "node:vm-01"
+	kind         string
+	nodeGroupIdx []int
+}
+
+// GetRegionZones returns the availability zones of the region reported by CB-Tumblebug.
+func GetRegionZones(csp, region string) ([]string, error) {
+	regionInfo, err := tbclient.NewSession().ReadRegionInfo(strings.ToLower(csp), strings.ToLower(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read region info (%s/%s): %w", csp, region, err)
+	}
+	return regionInfo.Zones, nil
+}
+
+// applyZonePlacement places the NodeGroups of the recommended infra across the availability zones.
+//
+// Tiers are identified from NLB backend membership (label "nlbBackend") and source node roles:
+//   - NLB backend NodeGroups stay whole in the primary zone, since CB-Tumblebug binds
+//     a single NodeGroup to an NLB target group.
+//   - NodeGroups whose source nodes share a role are assigned zones in round-robin order.
+//   - Other NodeGroups stay in the primary zone to avoid cross-zone traffic.
+//
+// Spreading the load-balanced tiers across zones is out of scope: it would split a tier into
+// zonal NodeGroups and register all of them to the target group, which neither the NLB migration
+// nor the IaC export supports. The plan notes it for each load-balanced tier with replicas.
+//
+// A zonal subnet is added to the vNet for each zone in use. The candidate is updated in place;
+// slices shared with other candidates are copied before modification.
+func applyZonePlacement(candidate *cloudmodel.RecommendedInfra, zones []string, srcNodes []onpremmodel.NodeProperty) {
+	plan := &cloudmodel.PlacementPlan{
+		Policy:     PlacementPolicySpread,
+		Zones:      zones,
+		NodeGroups: []cloudmodel.NodeGroupPlacement{},
+	}
+	candidate.Placement = plan
+
+	if len(zones) == 0 {
+		plan.Policy = PlacementPolicyNone
+		plan.Notes = append(plan.Notes, "No availability zone information for the region; NodeGroups are placed in the CSP default zone.")
+		return
+	}
+	if len(candidate.TargetVNet.SubnetInfoList) == 0 {
+		plan.Policy = PlacementPolicyNone
+		plan.Notes = append(plan.Notes, "No subnet in the recommended vNet; zone placement is skipped.")
+		return
+	}
+	if len(zones) == 1 {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(
+			"The region has a single availability zone (%s); load-balanced tiers have a single-zone failure domain.", zones[0]))
+	}
+
+	// Copy the slices shared with the skeleton and other candidates
+	candidate.TargetVNet.SubnetInfoList = append([]cloudmodel.SubnetReq(nil), candidate.TargetVNet.SubnetInfoList...)
+	if candidate.AdditionalVNetList != nil {
+		vNetList := make([]cloudmodel.VNetReq, len(candidate.AdditionalVNetList))
+		for i, vNet := range candidate.AdditionalVNetList {
+			vNet.SubnetInfoList = append([]cloudmodel.SubnetReq(nil), vNet.SubnetInfoList...)
+			vNetList[i] = vNet
+		}
+		candidate.AdditionalVNetList = vNetList
+	}
+
+	subnets := &zonalSubnets{vNets: []*cloudmodel.VNetReq{&candidate.TargetVNet}, byKey: map[string]string{}}
+	for i := range candidate.AdditionalVNetList {
+		subnets.vNets = append(subnets.vNets, &candidate.AdditionalVNetList[i])
+	}
+	roleByMachineId := map[string]string{}
+	for _, node := range srcNodes {
+		roleByMachineId[node.MachineId] = strings.ToLower(strings.TrimSpace(node.Role))
+	}
+
+	// Tier and turn (order within the tier) of each NodeGroup
+	tierByIdx := map[int]placementTier{}
+	turnByIdx := map[int]int{}
+	for _, tier := range groupPlacementTiers(candidate.TargetInfra.NodeGroups, roleByMachineId) {
+		for turn, ngIdx := range tier.nodeGroupIdx {
+			tierByIdx[ngIdx] = tier
+			turnByIdx[ngIdx] = turn
+		}
+	}
+
+	var placedNodeGroups []cloudmodel.CreateNodeGroupReq
+	for ngIdx, ng := range candidate.TargetInfra.NodeGroups {
+		tier, turn := tierByIdx[ngIdx], turnByIdx[ngIdx]
+		switch {
+		case tier.kind == placementTierNlb:
+			// CB-Tumblebug binds a single NodeGroup to an NLB target group, so the replicas
+			// are kept in one NodeGroup instead of being split into zonal NodeGroups.
+			zone := zones[0]
+			ng.SubnetId, _ = subnets.subnetFor(ng.VNetId, ng.SubnetId, zone)
+			rationale := "Load-balanced tier with a single replica; placed in the primary zone"
+			switch {
+			case ng.NodeGroupSize >= 2 && len(zones) < 2:
+				rationale = "Load-balanced tier; the region has a single zone"
+			case ng.NodeGroupSize >= 2:
+				rationale = fmt.Sprintf("Load-balanced tier with %d replicas kept in one NodeGroup bound to the NLB target group; placed in the primary zone",
+					ng.NodeGroupSize)
+				plan.Notes = append(plan.Notes, fmt.Sprintf(
+					"NodeGroup '%s' (%d replicas) is placed in a single zone (%s) since an NLB target group binds a single NodeGroup; the replicas share a single-zone failure domain. Spreading load-balanced tiers across zones is not supported.",
+					ng.Name, ng.NodeGroupSize, zone))
+			}
+			placedNodeGroups = append(placedNodeGroups, withZone(ng, zone))
+			plan.NodeGroups = append(plan.NodeGroups, newNodeGroupPlacement(ng, tier.key, zone, rationale))
+
+		case tier.kind == placementTierRole:
+			zone := zones[turn%len(zones)]
+			subnetId, note := subnets.subnetFor(ng.VNetId, ng.SubnetId, zone)
+			if note != "" {
+				plan.Notes = append(plan.Notes, note)
+			}
+			ng.SubnetId = subnetId
+			placedNodeGroups = append(placedNodeGroups, withZone(ng, zone))
+			plan.NodeGroups = append(plan.NodeGroups, newNodeGroupPlacement(ng, tier.key, zone, fmt.Sprintf(
+				"Replica %d of %d NodeGroups sharing the role; zones assigned in round-robin order",
+				turn+1, len(tier.nodeGroupIdx))))
+
+		default:
+			zone := zones[0]
+			ng.SubnetId, _ = subnets.subnetFor(ng.VNetId, ng.SubnetId, zone)
+			placedNodeGroups = append(placedNodeGroups, withZone(ng, zone))
+			plan.NodeGroups = append(plan.NodeGroups, newNodeGroupPlacement(ng, tier.key, zone,
+				"No replicas identified (no NLB membership or shared role); placed in the primary zone to avoid cross-zone traffic"))
+		}
+	}
+	candidate.TargetInfra.NodeGroups = placedNodeGroups
+
+	log.Debug().
+		Strs("zones", zones).
+		Int("nodeGroups", len(placedNodeGroups)).
+		Int("subnets", len(candidate.TargetVNet.SubnetInfoList)).
+		Msg("Zone placement applied")
+}
+
+// groupPlacementTiers groups NodeGroups into placement tiers, in the order of first appearance.
+func groupPlacementTiers(nodeGroups []cloudmodel.CreateNodeGroupReq, roleByMachineId map[string]string) []placementTier {
+	var tiers []placementTier
+	tierIdx := map[string]int{}
+
+	for i, ng := range nodeGroups {
+		key, kind := "", placementTierNode
+		if backend := ng.Label["nlbBackend"]; backend != "" {
+			key, kind = placementTierNlb+":"+backend, placementTierNlb
+		} else if role := nodeGroupRole(ng, roleByMachineId); role != "" && role != "standalone" {
+			key, kind = placementTierRole+":"+role, placementTierRole
+		} else {
+			key = placementTierNode + ":" + ng.Name
+		}
+
+		idx, ok := tierIdx[key]
+		if !ok {
+			tiers = append(tiers, placementTier{key: key, kind: kind})
+			idx = len(tiers) - 1
+			tierIdx[key] = idx
+		}
+		tiers[idx].nodeGroupIdx = append(tiers[idx].nodeGroupIdx, i)
+	}
+	return tiers
+}
+
+// nodeGroupRole returns the role shared by all source nodes of the NodeGroup ("" if none or mixed).
+func nodeGroupRole(ng cloudmodel.CreateNodeGroupReq, roleByMachineId map[string]string) string {
+	role := ""
+	for _, machineId := range nodeGroupSourceMachineIds(ng) {
+		r := roleByMachineId[machineId]
+		if r == "" || (role != "" && r != role) {
+			return ""
+		}
+		role = r
+	}
+	return role
+}
+
+// nodeGroupSourceMachineIds returns the source machine IDs recorded in the NodeGroup labels.
+func nodeGroupSourceMachineIds(ng cloudmodel.CreateNodeGroupReq) []string {
+	return common.SourceMachineIds(ng.Label)
+}
+
+// withZone returns a copy of the NodeGroup labeled with the zone.
+func withZone(ng cloudmodel.CreateNodeGroupReq, zone string) cloudmodel.CreateNodeGroupReq {
+	label := make(map[string]string, len(ng.Label)+1)
+	for k, v := range ng.Label {
+		label[k] = v
+	}
+	label["zone"] = zone
+	ng.Label = label
+	return ng
+}
+
+func newNodeGroupPlacement(ng cloudmodel.CreateNodeGroupReq, tierKey, zone, rationale string) cloudmodel.NodeGroupPlacement {
+	return cloudmodel.NodeGroupPlacement{
+		NodeGroup:     ng.Name,
+		Tier:          tierKey,
+		Zone:          zone,
+		SubnetId:      ng.SubnetId,
+		NodeGroupSize: ng.NodeGroupSize,
+		Rationale:     rationale,
+	}
+}
+
+// ============================================================================
+// Zonal subnets
+// ============================================================================
+
+// zonalSubnets assigns zones to the subnets of the vNets, adding a subnet per zone as needed.
+type zonalSubnets struct {
+	vNets []*cloudmodel.VNetReq
+	byKey map[string]string // "<vNet>/<baseSubnet>/<zone>" → subnet name
+}
+
+// subnetFor returns the subnet for the zone derived from the base subnet.
+// The base subnet is assigned to the first zone requested; other zones get a new subnet
+// with the same prefix length carved from the vNet CIDR. If no CIDR is available,
+// the base subnet is returned with a note.
+func (z *zonalSubnets) subnetFor(vNetId, baseSubnetId, zone string) (string, string) {
+	key := vNetId + "/" + baseSubnetId + "/" + zone
+	if name, ok := z.byKey[key]; ok {
+		return name, ""
+	}
+
+	var vNet *cloudmodel.VNetReq
+	for _, v := range z.vNets {
+		if v.Name == vNetId {
+			vNet = v
+			break
+		}
+	}
+	baseIdx := -1
+	if vNet != nil {
+		for i, s := range vNet.SubnetInfoList {
+			if s.Name == baseSubnetId {
+				baseIdx = i
+				break
+			}
+		}
+	}
+	if baseIdx < 0 {
+		return baseSubnetId, fmt.Sprintf("Subnet '%s' not found in vNet '%s'; zone %s is not applied.", baseSubnetId, vNetId, zone)
+	}
+
+	base := &vNet.SubnetInfoList[baseIdx]
+	if base.Zone == "" || base.Zone == zone {
+		base.Zone = zone
+		z.byKey[key] = base.Name
+		return base.Name, ""
+	}
+
+	_, baseNet, err := net.ParseCIDR(base.IPv4_CIDR)
+	if err != nil {
+		return base.Name, fmt.Sprintf("Subnet '%s' has an invalid CIDR (%s); zone %s is not applied.", base.Name, base.IPv4_CIDR, zone)
+	}
+	prefixLen, _ := baseNet.Mask.Size()
+
+	var used []string
+	for _, s := range vNet.SubnetInfoList {
+		used = append(used, s.IPv4_CIDR)
+	}
+	cidr, err := nextFreeSubnet(vNet.CidrBlock, prefixLen, used)
+	if err != nil {
+		return base.Name, fmt.Sprintf("No free /%d block in vNet %s for zone %s (%v); subnet '%s' is used instead.",
+			prefixLen, vNet.CidrBlock, zone, err, base.Name)
+	}
+
+	zonal := cloudmodel.SubnetReq{
+		Name:        fmt.Sprintf("%s-%s", base.Name, sanitizeName(zone)),
+		IPv4_CIDR:   cidr,
+		Zone:        zone,
+		Description: fmt.Sprintf("a recommended subnet for migration (zone %s)", zone),
+		EnableIPv6:  base.EnableIPv6,
+	}
+	if base.EnableIPv6 && vNet.IPv6CidrBlock != "" {
+		var usedIPv6 []string
+		for _, s := range vNet.SubnetInfoList {
+			if s.IPv6_CIDR != "" {
+				usedIPv6 = append(usedIPv6, s.IPv6_CIDR)
+			}
+		}
+		ipv6Cidr, err := nextFreeIPv6Subnet(vNet.IPv6CidrBlock, usedIPv6)
+		if err != nil {
+			zonal.EnableIPv6 = false
+		}
+		zonal.IPv6_CIDR = ipv6Cidr
+	}
+	vNet.SubnetInfoList = append(vNet.SubnetInfoList, zonal)
+	z.byKey[key] = zonal.Name
+	return zonal.Name, ""
+}
+
+// nextFreeSubnet returns the first IPv4 block of the prefix length within the vNet CIDR
+// that does not overlap any of the used CIDR blocks.
+func nextFreeSubnet(vNetCidr string, prefixLen int, used []string) (string, error) {
+	_, vNet, err := net.ParseCIDR(vNetCidr)
+	if err != nil || vNet.IP.To4() == nil {
+		return "", fmt.Errorf("invalid IPv4 vNet CIDR '%s'", vNetCidr)
+	}
+	vNetPrefixLen, _ := vNet.Mask.Size()
+	if prefixLen < vNetPrefixLen || prefixLen > 32 {
+		return "", fmt.Errorf("prefix length /%d does not fit in %s", prefixLen, vNetCidr)
+	}
+
+	var usedNets []*net.IPNet
+	for _, c := range used {
+		if _, n, err := net.ParseCIDR(c); err == nil {
+			usedNets = append(usedNets, n)
+		}
+	}
+
+	start := binary.BigEndian.Uint32(vNet.IP.To4())
+	blockSize := uint64(1) << (32 - prefixLen)
+	blockCount := uint64(1) << (prefixLen - vNetPrefixLen)
+	for i := uint64(0); i < blockCount; i++ {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, start+uint32(i*blockSize))
+		candidate := &net.IPNet{IP: ip, Mask: net.CIDRMask(prefixLen, 32)}
+
+		overlaps := false
+		for _, n := range usedNets {
+			if n.Contains(candidate.IP) || candidate.Contains(n.IP) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			return candidate.String(), nil
+		}
+	}
+	return "", fmt.Errorf("address space exhausted")
+}
@@ -0,0 +1,122 @@
+package recommendation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNextFreeSubnet(t *testing.T) {
+	tests := []struct {
+		name      string
+		vNetCidr  string
+		prefixLen int
+		used      []string
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "empty vNet",
+			vNetCidr:  "10.0.0.0/16",
+			prefixLen: 24,
+			want:      "10.0.0.0/24",
+		},
+		{
+			name:      "used subnets are skipped",
+			vNetCidr:  "10.0.0.0/16",
+			prefixLen: 24,
+			used:      []string{"10.0.0.0/24", "10.0.1.0/24"},
+			want:      "10.0.2.0/24",
+		},
+		{
+			name:      "smaller used subnet inside a candidate",
+			vNetCidr:  "10.0.0.0/16",
+			prefixLen: 24,
+			used:      []string{"10.0.0.128/26"},
+			want:      "10.0.1.0/24",
+		},
+		{
+			name:      "larger used subnet covering candidates",
+			vNetCidr:  "10.0.0.0/16",
+			prefixLen: 24,
+			used:      []string{"10.0.0.0/22"},
+			want:      "10.0.4.0/24",
+		},
+		{
+			name:      "gap between used subnets",
+			vNetCidr:  "10.0.0.0/16",
+			prefixLen: 24,
+			used:      []string{"10.0.0.0/24", "10.0.2.0/24"},
+			want:      "10.0.1.0/24",
+		},
+		{
+			name:      "invalid and IPv6 used blocks are ignored",
+			vNetCidr:  "10.0.0.0/16",
+			prefixLen: 24,
+			used:      []string{"invalid", "2001:db8::/64"},
+			want:      "10.0.0.0/24",
+		},
+		{
+			name:      "host bits of the vNet are masked",
+			vNetCidr:  "10.0.3.5/16",
+			prefixLen: 24,
+			want:      "10.0.0.0/24",
+		},
+		{
+			name:      "single address blocks",
+			vNetCidr:  "10.0.0.0/30",
+			prefixLen: 32,
+			used:      []string{"10.0.0.0/31"},
+			want:      "10.0.0.2/32",
+		},
+		{
+			name:      "address space exhausted",
+			vNetCidr:  "10.0.0.0/23",
+			prefixLen: 24,
+			used:      []string{"10.0.0.0/24", "10.0.1.0/24"},
+			wantErr:   "address space exhausted",
+		},
+		{
+			name:      "address space exhausted by the vNet itself",
+			vNetCidr:  "10.0.0.0/16",
+			prefixLen: 24,
+			used:      []string{"10.0.0.0/16"},
+			wantErr:   "address space exhausted",
+		},
+		{
+			name:      "prefix shorter than the vNet",
+			vNetCidr:  "10.0.0.0/24",
+			prefixLen: 16,
+			wantErr:   "prefix length /16 does not fit in 10.0.0.0/24",
+		},
+		{
+			name:      "prefix longer than an address",
+			vNetCidr:  "10.0.0.0/24",
+			prefixLen: 33,
+			wantErr:   "prefix length /33 does not fit in 10.0.0.0/24",
+		},
+		{
+			name:      "IPv6 vNet",
+			vNetCidr:  "2001:db8::/56",
+			prefixLen: 64,
+			wantErr:   "invalid IPv4 vNet CIDR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextFreeSubnet(tt.vNetCidr, tt.prefixLen, tt.used)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("nextFreeSubnet() = %q, %v, want error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("nextFreeSubnet failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("nextFreeSubnet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -45,7 +45,10 @@ func RecommendSecurityGroup(csp string, region string, node onpremmodel.NodeProp
 		firewallRulesPtr = nil // Use nil to indicate no rules defined
 	} else {
 		log.Info().Msgf("Generating security group rules based on %d firewall rules from node configuration", len(firewallRules))
-		sgRules = generateSecurityGroupRules(firewallRules)
+		// Compile the ordered rules (including deny rules) into allow-only rules
+		aclSupport, _ := GetAclSupport(csp)
+		policy := compileFirewallPolicy(firewallRules, aclSupport == cloudmodel.AclSupportNative)
+		sgRules = generateSecurityGroupRules(policy.allowRules, ipv6Support(csp) != cloudmodel.IPv6SupportNone)
 		firewallRulesPtr = &sgRules // Point to the generated rules
 	}
 
@@ -200,29 +203,41 @@ func containSg(sgList []cloudmodel.SecurityGroupReq, sg cloudmodel.SecurityGroup
 }
 
 // formatCIDR formats the CIDR string:
-// - If it's "anywhere", return "0.0.0.0/0"
-// - If it doesn't have a prefix (like "/24"), add "/32"
+// - If it's "anywhere", return "0.0.0.0/0" ("::/0" for IPv6 rules)
+// - If it doesn't have a prefix (like "/24"), add "/32" ("/128" for IPv6 addresses)
 // - Otherwise return as is
-func formatCIDR(cidr string) string {
+func formatCIDR(cidr string, ipv6 bool) string {
 	if cidr == "anywhere" {
+		if ipv6 {
+			return "::/0"
+		}
 		return "0.0.0.0/0"
 	}
 
 	// Check if the CIDR has a prefix
 	if !strings.Contains(cidr, "/") {
-		// If it's a valid IP without prefix, add "/32"
+		// If it's a valid IP without prefix, add "/128" or "/32"
+		if strings.Contains(cidr, ":") {
+			return cidr + "/128"
+		}
 		return cidr + "/32"
 	}
 
 	return cidr
 }
 
+// isAnyCIDR checks if the CIDR allows all IPv4 or IPv6 addresses
+func isAnyCIDR(cidr string) bool {
+	return cidr == "0.0.0.0/0" || cidr == "::/0"
+}
+
 // generateSecurityGroupRules converts FirewallRuleProperty to tbmodel.TbFirewallRuleInfo
-func generateSecurityGroupRules(rules []onpremmodel.FirewallRuleProperty) []cloudmodel.FirewallRuleReq {
+// IPv6 rules are translated only if ipv6Enabled is true (i.e., the target CSP supports IPv6).
+func generateSecurityGroupRules(rules []onpremmodel.FirewallRuleProperty, ipv6Enabled bool) []cloudmodel.FirewallRuleReq {
 	var tbRules []cloudmodel.FirewallRuleReq
 
 	for _, rule := range rules {
-		// Skip 'deny' rules (note: SecurityGroup does not support adding 'deny' rules)
+		// Skip 'deny' rules (note: SecurityGroup does not support adding 'deny' rules; see compileFirewallPolicy)
 		if rule.Action == "deny" {
 			continue
 		}
@@ -233,9 +248,10 @@ func generateSecurityGroupRules(rules []onpremmodel.FirewallRuleProperty) []clou
 			continue
 		}
 
-		// Skip IPv6 rules (currently not supported)
-		if isIPv6Rule(rule) {
-			log.Warn().Msgf("IPv6 rule detected but not currently supported: %+v - skipping rule", rule)
+		// Skip IPv6 rules if the target CSP does not support IPv6 (reported in the IPv6 plan)
+		ipv6 := isIPv6Rule(rule)
+		if ipv6 && !ipv6Enabled {
+			log.Warn().Msgf("IPv6 rule detected but the target CSP does not support IPv6: %+v - skipping rule", rule)
 			continue
 		}
 
@@ -245,6 +261,11 @@ func generateSecurityGroupRules(rules []onpremmodel.FirewallRuleProperty) []clou
 			protocol = "ALL"
 		}
 
+		// ICMPv6 is expressed as ICMP with IPv6 CIDR blocks
+		if strings.ToLower(protocol) == "icmpv6" {
+			protocol = "ICMP"
+		}
+
 		switch rule.Direction {
 		case "inbound":
 			// Set CIDR block for source - For inbound, use source CIDR (where traffic comes from)
@@ -254,12 +275,12 @@ func generateSecurityGroupRules(rules []onpremmodel.FirewallRuleProperty) []clou
 			}
 
 			// Format the CIDR correctly
-			srcCIDR := formatCIDR(rule.SrcCIDR)
+			srcCIDR := formatCIDR(rule.SrcCIDR, ipv6)
 			log.Debug().Msgf("Formatted SrcCIDR from '%s' to '%s'", rule.SrcCIDR, srcCIDR)
 
 			// ! Skip default outbound rule that allows all traffic because it is automatically created by cloud providers, CB-Spider, or CB-Tumblebug.
 			// TODO: To be updated if the default rule is needed in the future.
-			if strings.ToLower(protocol) == "all" && srcCIDR == "0.0.0.0/0" {
+			if strings.ToLower(protocol) == "all" && isAnyCIDR(srcCIDR) {
 				log.Debug().Msgf("Skipping default inbound ALL traffic rule (may conflict with existing rules): %+v", rule)
 				continue
 			}
@@ -335,12 +356,12 @@ func generateSecurityGroupRules(rules []onpremmodel.FirewallRuleProperty) []clou
 			}
 
 			// Format the CIDR correctly
-			dstCIDR := formatCIDR(rule.DstCIDR)
+			dstCIDR := formatCIDR(rule.DstCIDR, ipv6)
 			log.Debug().Msgf("Formatted outbound CIDR from '%s' to '%s'", rule.DstCIDR, dstCIDR)
 
 			// ! Skip default outbound rule that allows all traffic because it is automatically created by cloud providers, CB-Spider, or CB-Tumblebug.
 			// TODO: To be updated if the default rule is needed in the future.
-			if strings.ToLower(protocol) == "all" && dstCIDR == "0.0.0.0/0" {
+			if strings.ToLower(protocol) == "all" && isAnyCIDR(dstCIDR) {
 				log.Debug().Msgf("Skipping default outbound ALL traffic rule (may conflict with existing rules): %+v", rule)
 				continue
 			}
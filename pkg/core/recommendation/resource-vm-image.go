@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	tbmodel "github.com/cloud-barista/cb-tumblebug/src/core/model"
 	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
@@ -85,7 +86,8 @@ func RecommendVmOsImage(csp string, region string, node onpremmodel.NodeProperty
 	log.Debug().Msg("keywords for the VM OS image recommendation: " + keywords)
 
 	// Find the best VM OS image
-	bestVmOsImage := FindBestVmOsImage(csp, keywords, kwDelimiters, imageList, imgDelimiters)
+	srcOs := similarity.ParseOsIdentityFromOsProperty(node.OS, time.Now())
+	bestVmOsImage := FindBestVmOsImage(csp, srcOs, keywords, kwDelimiters, imageList, imgDelimiters)
 
 	log.Debug().Msgf("Best VM OS image found: %+v", bestVmOsImage)
 
@@ -105,7 +107,8 @@ func RecommendVmOsImageId(csp string, region string, node onpremmodel.NodeProper
 	keywords, kwDelimiters, imgDelimiters := SetKeywordsAndDelimeters(node)
 	log.Debug().Msg("keywords for the VM OS image recommendation: " + keywords)
 
-	vmOsImageId := FindBestVmOsImageNameUsedInCsp(csp, keywords, kwDelimiters, imageList, imgDelimiters)
+	srcOs := similarity.ParseOsIdentityFromOsProperty(node.OS, time.Now())
+	vmOsImageId := FindBestVmOsImageNameUsedInCsp(csp, srcOs, keywords, kwDelimiters, imageList, imgDelimiters)
 
 	log.Debug().Msgf("Best VM OS image ID found: %s", vmOsImageId)
 
@@ -114,6 +117,12 @@ func RecommendVmOsImageId(csp string, region string, node onpremmodel.NodeProper
 
 // RecommendVmOsImages recommends an appropriate VM OS image (e.g., Ubuntu 22.04) for the given VM spec
 func RecommendVmOsImages(csp string, region string, node onpremmodel.NodeProperty, limit int) ([]cloudmodel.ImageInfo, error) {
+	return recommendVmOsImages(csp, region, node, limit, nil)
+}
+
+// recommendVmOsImages recommends appropriate VM OS images for the given node.
+// If queryTrace is not nil, the search query actually applied is recorded to it (used in explain mode).
+func recommendVmOsImages(csp string, region string, node onpremmodel.NodeProperty, limit int, queryTrace *cloudmodel.ImageSearchQuery) ([]cloudmodel.ImageInfo, error) {
 
 	var emptyRes = []cloudmodel.ImageInfo{}
 	var vmOsImageInfoList = []cloudmodel.ImageInfo{}
@@ -185,9 +194,16 @@ func RecommendVmOsImages(csp string, region string, node onpremmodel.NodePropert
 		return emptyRes, err
 	}
 
+	if queryTrace != nil {
+		queryTrace.OSType = osType
+	}
+
 	// Filter VM OS images to support stability
 	for _, img := range resSearchImage.ImageList {
 		if strings.Contains(strings.ToLower(img.CspImageName), "uefi") {
+			if queryTrace != nil {
+				queryTrace.Dropped = append(queryTrace.Dropped, img.CspImageName)
+			}
 			continue
 		}
 		// Add more filters as needed
@@ -210,10 +226,18 @@ func RecommendVmOsImages(csp string, region string, node onpremmodel.NodePropert
 			return emptyRes, err
 		}
 
+		if queryTrace != nil {
+			queryTrace.OSType = node.OS.ID
+			queryTrace.Fallback = true
+		}
+
 		// Filter VM OS images again
 		filteredImages = []tbmodel.ImageInfo{} // Reset filtered images
 		for _, img := range resSearchImage.ImageList {
 			if strings.Contains(strings.ToLower(img.CspImageName), "uefi") {
+				if queryTrace != nil {
+					queryTrace.Dropped = append(queryTrace.Dropped, img.CspImageName)
+				}
 				continue
 			}
 			// Add more filters as needed
@@ -252,9 +276,13 @@ func RecommendVmOsImages(csp string, region string, node onpremmodel.NodePropert
 	// Set keywords and delimiters to calculate text similarity
 	keywords, kwDelimiters, imgDelimiters := SetKeywordsAndDelimeters(node)
 	log.Debug().Msg("keywords for the VM OS image recommendation: " + keywords)
+	if queryTrace != nil {
+		queryTrace.Keywords = keywords
+	}
 
-	// Select VM OS image via LevenshteinDistance-based text similarity
-	vmOsImageInfoList = FindAndSortVmOsImageInfoListBySimilarity(csp, keywords, kwDelimiters, imageList, imgDelimiters)
+	// Select VM OS image via the graded OS identity match and LevenshteinDistance-based text similarity
+	srcOs := similarity.ParseOsIdentityFromOsProperty(node.OS, time.Now())
+	vmOsImageInfoList = FindAndSortVmOsImageInfoListBySimilarity(csp, srcOs, keywords, kwDelimiters, imageList, imgDelimiters)
 
 	count := len(vmOsImageInfoList)
 	if count == 0 {
@@ -291,22 +319,47 @@ func SetKeywordsAndDelimeters(node onpremmodel.NodeProperty) (string, []string,
 	return keywords, kwDelimiters, imgDelimiters
 }
 
+// osIdentityWeight is the weight of the graded OS identity match in the image similarity.
+// The rest is the token similarity, which reflects the architecture, disk type, and other keywords.
+const osIdentityWeight = 0.8
+
+// calculateOsImageSimilarity calculates the similarity between the source OS and a VM OS image (0.0 to 1.0).
+// It combines the graded OS identity match (e.g., exact, same major, newer LTS) with the token similarity,
+// and falls back to the token similarity only if either OS identity cannot be parsed.
+// The EOL of the image OS is judged at the reference time.
+func calculateOsImageSimilarity(srcOs similarity.OsIdentity, now time.Time, keywords string, kwDelimiters []string, image cloudmodel.ImageInfo, imgDelimiters []string) float64 {
+
+	vmImgKeywords := fmt.Sprintf("%s %s %s %s",
+		image.OSType,
+		image.OSArchitecture,
+		image.OSDiskType,
+		image.OSDistribution,
+	)
+
+	tokenScore := similarity.CalcResourceSimilarity(keywords, kwDelimiters, vmImgKeywords, imgDelimiters)
+
+	osMatch := similarity.CompareOsIdentity(srcOs, similarity.ParseOsIdentityFromImage(image, now))
+	if osMatch.Grade == similarity.OsMatchUnknown {
+		return tokenScore
+	}
+
+	log.Trace().Msgf("OS match - source: %s, image: %s (%s), grade: %s, score: %.2f, token score: %.2f",
+		osMatch.Source, image.CspImageName, osMatch.Target, osMatch.Grade, osMatch.Score, tokenScore)
+
+	return osIdentityWeight*osMatch.Score + (1-osIdentityWeight)*tokenScore
+}
+
 // FindBestVmOsImage finds the best matching image based on the similarity scores
-func FindBestVmOsImage(csp string, keywords string, kwDelimiters []string, vmImages []cloudmodel.ImageInfo, imgDelimiters []string) cloudmodel.ImageInfo {
+func FindBestVmOsImage(csp string, srcOs similarity.OsIdentity, keywords string, kwDelimiters []string, vmImages []cloudmodel.ImageInfo, imgDelimiters []string) cloudmodel.ImageInfo {
 
 	var bestVmOsImage cloudmodel.ImageInfo
 	var highestScore float64 = 0.0
 
+	// The source OS identity (parsed from the OS information of the source server) is graded against each image
+	now := time.Now()
 	for _, image := range vmImages {
 
-		vmImgKeywords := fmt.Sprintf("%s %s %s %s",
-			image.OSType,
-			image.OSArchitecture,
-			image.OSDiskType,
-			image.OSDistribution,
-		)
-
-		score := similarity.CalcResourceSimilarity(keywords, kwDelimiters, vmImgKeywords, imgDelimiters)
+		score := calculateOsImageSimilarity(srcOs, now, keywords, kwDelimiters, image, imgDelimiters)
 
 		// Apply penalty for low-priority images (e.g., Marketplace images)
 		priority := compat.GetImagePriority(csp, image)
@@ -332,21 +385,16 @@ type VmOsImageInfoWithScore struct {
 }
 
 // FindAndSortVmOsImageInfoListBySimilarity finds VM OS images that match the keywords and sorts them by similarity score
-func FindAndSortVmOsImageInfoListBySimilarity(csp string, keywords string, kwDelimiters []string, vmImages []cloudmodel.ImageInfo, imgDelimiters []string) []cloudmodel.ImageInfo {
+func FindAndSortVmOsImageInfoListBySimilarity(csp string, srcOs similarity.OsIdentity, keywords string, kwDelimiters []string, vmImages []cloudmodel.ImageInfo, imgDelimiters []string) []cloudmodel.ImageInfo {
 
 	var imageInfoListForSorting []VmOsImageInfoWithScore
 	var imageInfoList []cloudmodel.ImageInfo
 
+	// The source OS identity (parsed from the OS information of the source server) is graded against each image
+	now := time.Now()
 	for _, image := range vmImages {
 
-		vmImgKeywords := fmt.Sprintf("%s %s %s %s",
-			image.OSType,
-			image.OSArchitecture,
-			image.OSDiskType,
-			image.OSDistribution,
-		)
-
-		score := similarity.CalcResourceSimilarity(keywords, kwDelimiters, vmImgKeywords, imgDelimiters)
+		score := calculateOsImageSimilarity(srcOs, now, keywords, kwDelimiters, image, imgDelimiters)
 
 		// Apply penalty for low-priority images (e.g., Marketplace images)
 		priority := compat.GetImagePriority(csp, image)
@@ -396,20 +444,15 @@ func FindAndSortVmOsImageInfoListBySimilarity(csp string, keywords string, kwDel
 }
 
 // FindBestVmOsImageNameUsedInCsp finds the best matching image based on the similarity scores
-func FindBestVmOsImageNameUsedInCsp(csp string, keywords string, kwDelimiters []string, vmImages []cloudmodel.ImageInfo, imgDelimiters []string) string {
+func FindBestVmOsImageNameUsedInCsp(csp string, srcOs similarity.OsIdentity, keywords string, kwDelimiters []string, vmImages []cloudmodel.ImageInfo, imgDelimiters []string) string {
 
 	var bestVmOsImageNameUsedInCsp string
 	var highestScore float64 = 0.0
 
+	// The source OS identity (parsed from the OS information of the source server) is graded against each image
+	now := time.Now()
 	for _, image := range vmImages {
-		vmImgKeywords := fmt.Sprintf("%s %s %s %s",
-			image.OSType,
-			image.OSArchitecture,
-			image.OSDiskType,
-			image.OSDistribution,
-		)
-
-		score := similarity.CalcResourceSimilarity(keywords, kwDelimiters, vmImgKeywords, imgDelimiters)
+		score := calculateOsImageSimilarity(srcOs, now, keywords, kwDelimiters, image, imgDelimiters)
 
 		// Apply penalty for low-priority images (e.g., Marketplace images)
 		priority := compat.GetImagePriority(csp, image)
@@ -65,6 +65,12 @@ func RecommendVmSpecsForImage(csp string, region string, node onpremmodel.NodePr
 
 // RecommendVmSpecs recommends appropriate VM specs for the given node
 func RecommendVmSpecs(csp string, region string, node onpremmodel.NodeProperty, limit int) (vmSpecList []cloudmodel.SpecInfo, length int, err error) {
+	return recommendVmSpecs(csp, region, node, limit, nil)
+}
+
+// recommendVmSpecs recommends appropriate VM specs for the given node.
+// If filterTrace is not nil, the deployment-plan filters actually applied are recorded to it (used in explain mode).
+func recommendVmSpecs(csp string, region string, node onpremmodel.NodeProperty, limit int, filterTrace *cloudmodel.SpecSearchFilter) (vmSpecList []cloudmodel.SpecInfo, length int, err error) {
 
 	// Constants
 	const (
@@ -182,6 +188,22 @@ func RecommendVmSpecs(csp string, region string, node onpremmodel.NodeProperty,
 		)
 		log.Debug().Msgf("Deployment plan for machine %s: %s", node.MachineId, planToSearchProperVm)
 
+		// Record the filters of this attempt (the last attempt is the one that produced the result)
+		if filterTrace != nil {
+			*filterTrace = cloudmodel.SpecSearchFilter{
+				VCPUMin:      vcpusMin,
+				VCPUMax:      vcpusMax,
+				MemoryGiBMin: memoryMin,
+				MemoryGiBMax: memoryMax,
+				ProviderName: providerName,
+				RegionName:   regionName,
+				Architecture: architecture,
+				RangeWeight:  rangeWeight,
+				Limit:        limit,
+				Priority:     "cost",
+			}
+		}
+
 		// Call Tumblebug API to get recommended VM specs
 		var err error
 		vmSpecInfoList, err = tbclient.NewSession().InfraRecommendSpec(planToSearchProperVm)
@@ -3,9 +3,11 @@ package recommendation
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
 	"github.com/cloud-barista/cm-beetle/pkg/compat"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
 	"github.com/cloud-barista/cm-beetle/pkg/similarity"
 
 	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
@@ -321,26 +323,19 @@ func RecommendVmInfra(desiredCsp string, desiredRegion string, srcInfra onpremmo
 	 */
 
 	// 1. Recommend vNet and subnets (Note: vNet can be a VPC or a VNet depending on the CSP)
-	recommendedVNetInfoList, err := RecommendVNet(csp, region, srcInfra)
+	// * Note: One vNet is planned per private network spanned by the source networks (the source addressing is kept).
+	recommendedVNetInfoList, cidrPlan, err := PlanVNets(csp, region, srcInfra, cloudmodel.CidrPlanReq{})
 	if err != nil {
 		log.Warn().Err(err).Msg("failed to recommend a virtual network for the source computing infrastructure")
 	}
 
 	if len(recommendedVNetInfoList) == 0 {
 		log.Warn().Msg("no recommended virtual network found for the source computing infrastructure")
+		return recommendedVmInfra, fmt.Errorf("no recommended virtual network found for the source computing infrastructure")
 	}
 
-	// Assign the recommended virtural network to the response body
-	// TODO: Consider the other index in the recommended virtual network
-	recommendedVmInfra.TargetVNet = recommendedVNetInfoList[0]
-
-	// * Set a name to indicate a dependency between resources.
-	recommendedVmInfra.TargetVNet.Name = "mig-vnet-01"
-	recommendedVmInfra.TargetVNet.Description = "a recommended vNet for migration"
-	for i := range recommendedVmInfra.TargetVNet.SubnetInfoList {
-		recommendedVmInfra.TargetVNet.SubnetInfoList[i].Name = fmt.Sprintf("mig-subnet-%02d", i+1)
-		recommendedVmInfra.TargetVNet.SubnetInfoList[i].Description = "a recommended subnet for migration"
-	}
+	// * Set names to indicate a dependency between resources.
+	setPlannedVNets(&recommendedVmInfra, recommendedVNetInfoList, cidrPlan, "mig-vnet-%02d", "mig-subnet-%02d")
 
 	// 2. Recommend(?) SSH key pair
 	// var recommendedSshKey = tbmodel.SshKeyReq{}
@@ -517,8 +512,6 @@ func RecommendVmInfra(desiredCsp string, desiredRegion string, srcInfra onpremmo
 		/*
 		 * Recommend VM by specifying the recommended VM specs, OS images, and security groups
 		 */
-		// TODO: Select a subnet by the node's network information (for now, select the first one)
-
 		// Ref: https://github.com/cloud-barista/cb-spider/blob/master/cloud-driver-libs/cloudos_meta.yaml
 		// Note: "TYPE1" for RootDiskType is the first in the list
 		// - AWS: ["standard", "gp2", "gp3"],
@@ -534,24 +527,26 @@ func RecommendVmInfra(desiredCsp string, desiredRegion string, srcInfra onpremmo
 		// Use the source disk size as the base, with a CSP-specific minimum floor to avoid
 		// over-provisioning and keep costs low (e.g., AWS/GCP support as low as 10 GB).
 		rootDiskSize := max(int(node.RootDisk.TotalSize), getCspMinRootDiskSizeGB(csp))
+		vNetId, subnetId := selectNodeNetwork(recommendedVmInfra, node) // The subnet of the source network the node belongs to
 		tempCreateNodeGroupReq := cloudmodel.CreateNodeGroupReq{
 			ConnectionName:   fmt.Sprintf("%s-%s", csp, region),
 			Description:      fmt.Sprintf("a recommended virtual machine %02d for %s", i+1, node.MachineId), // Set MachineId to identify the source node
 			SpecId:           selectedVmSpec.Id,
 			ImageId:          selectedVmOsImage.Id,
 			CspImageName:     resolvedCspImageName, // Set only when review resolved a newer image; TumbleBug sends this to Spider instead of looking up via ImageId.
-			VNetId:           recommendedVmInfra.TargetVNet.Name,
-			SubnetId:         recommendedVmInfra.TargetVNet.SubnetInfoList[0].Name, // Set the first subnet for simplicity (TBD, select the appropriate subnet)
-			SecurityGroupIds: []string{recommendedSg.Name},                         // Set the security group ID
-			Name:             fmt.Sprintf("migrated-%s", node.MachineId),           // Set MachineId to identify the source node
-			RootDiskType:     suggestedSystemDisk,                                  // Confirmed-available disk type from specImagePairReview; empty → CSP default
-			RootDiskSize:     rootDiskSize,                                         // max(source disk size, CSP minimum) to keep costs low
-			SshKeyId:         recommendedVmInfra.TargetSshKey.Name,                 // Set the SSH key ID
-			NodeUserName:     "",                                                   // TBD: Set the VM user name if needed
-			NodeUserPassword: "",                                                   // TBD
-			NodeGroupSize:    1,                                                    // TBD
+			VNetId:           vNetId,
+			SubnetId:         subnetId,
+			SecurityGroupIds: []string{recommendedSg.Name},               // Set the security group ID
+			Name:             fmt.Sprintf("migrated-%s", node.MachineId), // Set MachineId to identify the source node
+			RootDiskType:     suggestedSystemDisk,                        // Confirmed-available disk type from specImagePairReview; empty → CSP default
+			RootDiskSize:     rootDiskSize,                               // max(source disk size, CSP minimum) to keep costs low
+			SshKeyId:         recommendedVmInfra.TargetSshKey.Name,       // Set the SSH key ID
+			NodeUserName:     "",                                         // TBD: Set the VM user name if needed
+			NodeUserPassword: "",                                         // TBD
+			NodeGroupSize:    1,                                          // TBD
 			Label: map[string]string{
-				"sourceMachineId": node.MachineId,
+				common.LabelSourceMachineId: node.MachineId,
+				common.LabelSourceHostname:  node.Hostname,
 			},
 		}
 
@@ -566,6 +561,17 @@ func RecommendVmInfra(desiredCsp string, desiredRegion string, srcInfra onpremmo
 	recommendedVmInfra.TargetSpecList = recommendedVmSpecList
 	recommendedVmInfra.TargetOsImageList = recommendedVmOsImageList
 	recommendedVmInfra.TargetSecurityGroupList = recommendedSecurityGroupList
+	rewriteSecurityGroupCidrs(recommendedVmInfra.TargetSecurityGroupList, cidrPlan)
+	bindSecurityGroupsToVNets(&recommendedVmInfra)
+
+	// Place the NodeGroups across the availability zones of the region
+	zones, err := GetRegionZones(csp, region)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to get availability zones; NodeGroups are placed in the CSP default zone")
+	}
+	applyZonePlacement(&recommendedVmInfra, zones, srcInfra.Nodes)
+	applyFirewallPolicy(csp, &recommendedVmInfra, srcInfra.Nodes)
+	recommendedVmInfra.Description += ipv6WarningNote(recommendedVmInfra.CidrPlan)
 
 	log.Trace().Msgf("the recommended infra info: %+v", recommendedVmInfra)
 
@@ -573,7 +579,9 @@ func RecommendVmInfra(desiredCsp string, desiredRegion string, srcInfra onpremmo
 }
 
 // RecommendVmInfraCandidates an appropriate multi-cloud infrastructure (MCI) for cloud migration
-func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfra onpremmodel.OnpremInfra, limit int, minMatchRate float64) ([]cloudmodel.RecommendedInfra, error) {
+// If explain is true, a structured decision trace is attached to each candidate.
+// The CIDR blocks of the target vNets are planned by cidrPlanReq (see PlanVNets).
+func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfra onpremmodel.OnpremInfra, limit int, minMatchRate float64, explain bool, cidrPlanReq cloudmodel.CidrPlanReq) ([]cloudmodel.RecommendedInfra, error) {
 
 	// * To recommend multiple infra candidates (i.e., multiple VM spec and OS image combinations),
 	// * this function estimates, recommends or just generates vNets, subnets, SSH key pair, and security groups
@@ -609,27 +617,21 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 	 * [Process]
 	 */
 
-	// 1. Recommend vNet and subnets (Note: vNet can be a VPC or a VNet depending on the CSP)
-	recommendedVNetInfoList, err := RecommendVNet(csp, region, srcInfra)
+	// 1. Plan vNets and subnets (Note: vNet can be a VPC or a VNet depending on the CSP)
+	// * Note: One vNet is planned per private network spanned by the source networks.
+	recommendedVNetInfoList, cidrPlan, err := PlanVNets(csp, region, srcInfra, cidrPlanReq)
 	if err != nil {
-		log.Warn().Err(err).Msg("failed to recommend a virtual network for the source computing infrastructure")
+		log.Warn().Err(err).Msg("failed to plan virtual networks for the source computing infrastructure")
+		return recommendedVmInfraCandidates, err
 	}
 
 	if len(recommendedVNetInfoList) == 0 {
 		log.Warn().Msg("no recommended virtual network found for the source computing infrastructure")
+		return recommendedVmInfraCandidates, fmt.Errorf("no recommended virtual network found for the source computing infrastructure")
 	}
 
-	// Assign the recommended virtural network to the response body
-	// TODO: Consider the other index in the recommended virtual network
-	skeletonVmInfra.TargetVNet = recommendedVNetInfoList[0]
-
-	// * Set a name to indicate a dependency between resources.
-	skeletonVmInfra.TargetVNet.Name = "vnet-01"
-	skeletonVmInfra.TargetVNet.Description = "a recommended vNet for migration"
-	for i := range skeletonVmInfra.TargetVNet.SubnetInfoList {
-		skeletonVmInfra.TargetVNet.SubnetInfoList[i].Name = fmt.Sprintf("subnet-%02d", i+1)
-		skeletonVmInfra.TargetVNet.SubnetInfoList[i].Description = "a recommended subnet for migration"
-	}
+	// * Set names to indicate a dependency between resources.
+	setPlannedVNets(&skeletonVmInfra, recommendedVNetInfoList, cidrPlan, "vnet-%02d", "subnet-%02d")
 
 	// 2. Recommend(?) SSH key pair
 	// var recommendedSshKey = tbmodel.SshKeyReq{}
@@ -641,7 +643,6 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 	// 3. Generate a skeleton of NodeGroup List for VMs
 	var skeletonNodegroupList = []cloudmodel.CreateNodeGroupReq{}
 
-	// TODO: Select a subnet by the node's network information (for now, select the first one)
 	// Ref: https://github.com/cloud-barista/cb-spider/blob/master/cloud-driver-libs/cloudos_meta.yaml
 	// Note: "TYPE1" for RootDiskType is the first in the list
 	// - AWS: ["standard", "gp2", "gp3"],
@@ -659,20 +660,22 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 		// Use the source disk size as the base, with a CSP-specific minimum floor to avoid
 		// over-provisioning and keep costs low (e.g., AWS/GCP support as low as 10 GB).
 		rootDiskSize := max(int(node.RootDisk.TotalSize), getCspMinRootDiskSizeGB(csp))
+		vNetId, subnetId := selectNodeNetwork(skeletonVmInfra, node) // The subnet of the source network the node belongs to
 		tempCreateNodeGroupReq := cloudmodel.CreateNodeGroupReq{
 			ConnectionName:   fmt.Sprintf("%s-%s", csp, region),
 			Description:      fmt.Sprintf("Recommended VM %02d for %s", i+1, node.MachineId), // Set MachineId to identify the source node
-			VNetId:           skeletonVmInfra.TargetVNet.Name,
-			SubnetId:         skeletonVmInfra.TargetVNet.SubnetInfoList[0].Name, // Set the first subnet for simplicity (TBD, select the appropriate subnet)
-			Name:             fmt.Sprintf("vm-%s", node.MachineId),              // Set MachineId to identify the source node
-			RootDiskType:     "",                                                // Set "" or default to use CSP's default
-			RootDiskSize:     rootDiskSize,                                      // max(source disk size, CSP minimum) to keep costs low
-			SshKeyId:         skeletonVmInfra.TargetSshKey.Name,                 // Set the SSH key ID
-			NodeUserName:     "",                                                // TBD: Set the VM user name if needed
-			NodeUserPassword: "",                                                // TBD
-			NodeGroupSize:    1,                                                 // Default: 1
+			VNetId:           vNetId,
+			SubnetId:         subnetId,
+			Name:             fmt.Sprintf("vm-%s", node.MachineId), // Set MachineId to identify the source node
+			RootDiskType:     "",                                   // Set "" or default to use CSP's default
+			RootDiskSize:     rootDiskSize,                         // max(source disk size, CSP minimum) to keep costs low
+			SshKeyId:         skeletonVmInfra.TargetSshKey.Name,    // Set the SSH key ID
+			NodeUserName:     "",                                   // TBD: Set the VM user name if needed
+			NodeUserPassword: "",                                   // TBD
+			NodeGroupSize:    1,                                    // Default: 1
 			Label: map[string]string{
-				"sourceMachineId": node.MachineId,
+				common.LabelSourceMachineId: node.MachineId,
+				common.LabelSourceHostname:  node.Hostname,
 			},
 		}
 
@@ -680,6 +683,12 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 		skeletonNodegroupList = append(skeletonNodegroupList, tempCreateNodeGroupReq)
 	}
 
+	// Availability zones of the region for NodeGroup placement (shared by all candidates)
+	zones, err := GetRegionZones(csp, region)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to get availability zones; NodeGroups are placed in the CSP default zone")
+	}
+
 	// 4. Recommend security groups with removing duplicates,
 	// and set the recommended security groups to the skeleton NodeGroup List
 	var deduplicatedSecurityGroupList = []cloudmodel.SecurityGroupReq{}
@@ -712,6 +721,9 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 		skeletonNodegroupList[i].SecurityGroupIds = []string{recommendedSg.Name}
 	}
 
+	// Replace the source networks in the firewall rules with the planned subnets (pool mode only)
+	rewriteSecurityGroupCidrs(deduplicatedSecurityGroupList, cidrPlan)
+
 	/*
 	 *
 	 */
@@ -720,19 +732,46 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 	// Note: Don't need to register specs and OS images.
 	var compatiblePairsForEachServer = make([][]CompatibleSpecImagePair, len(srcInfra.Nodes))
 
+	// * Decision traces for each server (only used in explain mode)
+	var nodeTraces []cloudmodel.NodeDecisionTrace
+	if explain {
+		nodeTraces = make([]cloudmodel.NodeDecisionTrace, len(srcInfra.Nodes))
+	}
+
 	// Find compatible pairs of VM specs and OS images for servers
 	for i, node := range srcInfra.Nodes {
 
+		var nodeTrace *cloudmodel.NodeDecisionTrace
+		var specFilterTrace *cloudmodel.SpecSearchFilter
+		var imageQueryTrace *cloudmodel.ImageSearchQuery
+		if explain {
+			nodeTrace = &nodeTraces[i]
+			nodeTrace.SourceMachineId = node.MachineId
+			specFilterTrace = &nodeTrace.SpecFilter
+			imageQueryTrace = &nodeTrace.ImageFilter
+		}
+
 		// Lookup the appropriate VM specs for the node
-		recommendedVmSpecInfoList, _, err := RecommendVmSpecs(csp, region, node, limitSpecs)
+		recommendedVmSpecInfoList, _, err := recommendVmSpecs(csp, region, node, limitSpecs, specFilterTrace)
 		if err != nil {
 			log.Warn().Msgf("failed to recommend VM specs for node %s: %v", node.MachineId, err)
+			if explain {
+				nodeTrace.Notes = append(nodeTrace.Notes, fmt.Sprintf("spec search failed: %v", err))
+			}
 		}
 
 		// Lookup the appropriate VM OS images for the node
-		recommendedVmOsImageInfoList, err := RecommendVmOsImages(csp, region, node, limitImages)
+		recommendedVmOsImageInfoList, err := recommendVmOsImages(csp, region, node, limitImages, imageQueryTrace)
 		if err != nil {
 			log.Warn().Msgf("failed to recommend VM OS images for node %s: %v", node.MachineId, err)
+			if explain {
+				nodeTrace.Notes = append(nodeTrace.Notes, fmt.Sprintf("image search failed: %v", err))
+			}
+		}
+
+		if explain {
+			nodeTrace.SpecsConsidered = len(recommendedVmSpecInfoList)
+			nodeTrace.ImagesConsidered = len(recommendedVmOsImageInfoList)
 		}
 
 		lenSpecList := len(recommendedVmSpecInfoList)
@@ -753,7 +792,7 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 			log.Warn().Msgf("no recommended VM specs or OS images found for node %s", node.MachineId)
 		} else {
 			// Find compatible VM spec and image pairs
-			compatiblePairsForEachServer[i], err = FindCompatibleVmSpecAndImagePairs(recommendedVmSpecInfoList, recommendedVmOsImageInfoList, csp)
+			compatiblePairsForEachServer[i], err = findCompatibleVmSpecAndImagePairs(recommendedVmSpecInfoList, recommendedVmOsImageInfoList, csp, nodeTrace)
 
 			// * Uncomment to check specs of compatible pairs
 			// tempLoggingLimit := 5
@@ -765,6 +804,9 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 			if err != nil {
 				log.Warn().Msgf("failed to find compatible spec-image pair for node %s: %v", node.MachineId, err)
 				// Use fallback selection (first spec, first image)
+				if explain {
+					nodeTrace.Notes = append(nodeTrace.Notes, fmt.Sprintf("no compatible pair: %v", err))
+				}
 			} else {
 				// Log details about found compatible pairs for this node
 				log.Debug().
@@ -786,6 +828,14 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 						Str("imageName", pair.Image.CspImageName).
 						Msg("Compatible pair details")
 				}
+
+				// Record the ranking of compatible pairs with their similarity scores
+				if explain {
+					for pairIdx := 0; pairIdx < len(compatiblePairsForEachServer[i]) && pairIdx < maxTracedRanking; pairIdx++ {
+						pair := compatiblePairsForEachServer[i][pairIdx]
+						nodeTrace.Ranking = append(nodeTrace.Ranking, newRankedPairTrace(csp, node, pair, pairIdx+1))
+					}
+				}
 			}
 		}
 	}
@@ -829,6 +879,13 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 		var selectedVmSpec cloudmodel.SpecInfo
 		var selectedVmOsImage cloudmodel.ImageInfo
 
+		// Copy the per-server traces so that each candidate carries its own selection
+		var candidateNodeTraces []cloudmodel.NodeDecisionTrace
+		if explain {
+			candidateNodeTraces = make([]cloudmodel.NodeDecisionTrace, len(nodeTraces))
+			copy(candidateNodeTraces, nodeTraces)
+		}
+
 		// For each node, select the i-th compatible pair of VM spec and OS image
 		for j, node := range srcInfra.Nodes {
 
@@ -849,6 +906,10 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 				pair = compatiblePairs[i]
 			} else {
 				log.Warn().Msgf("candidate %d: node %s has only %d pairs available, skipping this node for this candidate", i+1, node.MachineId, len(compatiblePairs))
+				if explain {
+					candidateNodeTraces[j].Notes = append(append([]string{}, candidateNodeTraces[j].Notes...),
+						fmt.Sprintf("only %d pairs available, node skipped for this candidate", len(compatiblePairs)))
+				}
 				continue
 			}
 
@@ -858,6 +919,11 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 			// Calculate match rate vector for this node-VM pair
 			matchRateVec := calculateMatchRateVector(csp, node, selectedVmSpec, selectedVmOsImage)
 
+			if explain {
+				selected := newRankedPairTrace(csp, node, pair, i+1)
+				candidateNodeTraces[j].Selected = &selected
+			}
+
 			// Log candidate and spec selection details with match rate
 			log.Debug().
 				Str("machineId", node.MachineId).
@@ -971,6 +1037,7 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 		candidateInfra.TargetOsImageList = deduplicatedVmOsImageList
 		candidateInfra.TargetSecurityGroupList = deduplicatedSecurityGroupList
 		candidateInfra.TargetInfra.Description = "Recommended VMs comprising multi-cloud infrastructure"
+		bindSecurityGroupsToVNets(&candidateInfra)
 
 		// Calculate overall match rate with detailed information
 		overallStatus, overallStatusDesc, infraMatchRateSummary := calculateCandidateMatchRateWithDetails(csp, tempNodeGroupList, srcInfra, deduplicatedVmSpecList, deduplicatedVmOsImageList, minMatchRate)
@@ -978,15 +1045,27 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 		// Set the status and enhanced description with match rate summary
 		candidateInfra.Status = overallStatus
 		candidateInfra.Description = fmt.Sprintf(
-			"Candidate #%d | %s | Overall Match Rate: Min=%.1f%% Max=%.1f%% Avg=%.1f%% | %s",
+			"Candidate #%d | %s | Overall Match Rate: Min=%.1f%% Max=%.1f%% Avg=%.1f%% | %s%s",
 			i+1,
 			overallStatus,
 			infraMatchRateSummary.MinMatchRate,
 			infraMatchRateSummary.MaxMatchRate,
 			infraMatchRateSummary.AvgMatchRate,
 			overallStatusDesc,
+			ipv6WarningNote(candidateInfra.CidrPlan),
 		)
 
+		if explain {
+			candidateInfra.Trace = &cloudmodel.RecommendationTrace{
+				CandidateIndex: i + 1,
+				Nodes:          candidateNodeTraces,
+			}
+		}
+
+		// Place the NodeGroups across the availability zones (after the match rate calculation)
+		applyZonePlacement(&candidateInfra, zones, srcInfra.Nodes)
+		applyFirewallPolicy(csp, &candidateInfra, srcInfra.Nodes)
+
 		recommendedVmInfraCandidates = append(recommendedVmInfraCandidates, candidateInfra)
 	}
 
@@ -998,6 +1077,23 @@ func RecommendVmInfraCandidates(desiredCsp string, desiredRegion string, srcInfr
 	return recommendedVmInfraCandidates, nil
 }
 
+// newRankedPairTrace builds a trace entry of a compatible spec-image pair with its similarity scores
+func newRankedPairTrace(csp string, node onpremmodel.NodeProperty, pair CompatibleSpecImagePair, rank int) cloudmodel.RankedPairTrace {
+	matchRateVec := calculateMatchRateVector(csp, node, pair.Spec, pair.Image)
+
+	return cloudmodel.RankedPairTrace{
+		Rank:            rank,
+		SpecId:          pair.Spec.Id,
+		CspSpecName:     pair.Spec.CspSpecName,
+		ImageId:         pair.Image.Id,
+		CspImageName:    pair.Image.CspImageName,
+		CpuMatchRate:    matchRateVec.CPU,
+		MemoryMatchRate: matchRateVec.Memory,
+		ImageMatchRate:  matchRateVec.Image,
+		AvgMatchRate:    matchRateVec.AverageMatchRate(),
+	}
+}
+
 // InfraMatchRateSummary represents overall infrastructure match rate metrics
 type InfraMatchRateSummary struct {
 	MinMatchRate   float64 // Minimum match rate across all VMs (weakest link)
@@ -1227,14 +1323,6 @@ func calculateImageMatchRateScore(csp string, node onpremmodel.NodeProperty, vmI
 	// Set keywords and delimiters similar to existing image recommendation logic
 	keywords, kwDelimiters, imgDelimiters := SetKeywordsAndDelimeters(node)
 
-	// Create image keywords for similarity calculation
-	vmImgKeywords := fmt.Sprintf("%s %s %s %s",
-		vmImage.OSType,
-		vmImage.OSArchitecture,
-		vmImage.OSDiskType,
-		vmImage.OSDistribution,
-	)
-
 	log.Debug().
 		Str("machineId", node.MachineId).
 		Str("osId", node.OS.ID).
@@ -1249,7 +1337,10 @@ func calculateImageMatchRateScore(csp string, node onpremmodel.NodeProperty, vmI
 		Msg("Image similarity input details")
 
 	// Calculate similarity score (0.0 to 1.0, where 1.0 is perfect match)
-	similarityScore := similarity.CalcResourceSimilarity(keywords, kwDelimiters, vmImgKeywords, imgDelimiters)
+	// based on the graded OS identity match (e.g., exact, same major, newer LTS) and the token similarity
+	now := time.Now()
+	srcOs := similarity.ParseOsIdentityFromOsProperty(node.OS, now)
+	similarityScore := calculateOsImageSimilarity(srcOs, now, keywords, kwDelimiters, vmImage, imgDelimiters)
 
 	// Apply penalty for low-priority images (e.g., Marketplace images)
 	priority := compat.GetImagePriority(csp, vmImage)
@@ -1265,7 +1356,8 @@ func calculateImageMatchRateScore(csp string, node onpremmodel.NodeProperty, vmI
 	log.Debug().
 		Str("machineId", node.MachineId).
 		Str("serverKeywords", keywords).
-		Str("imageKeywords", vmImgKeywords).
+		Str("serverOs", srcOs.String()).
+		Str("imageOs", similarity.ParseOsIdentityFromImage(vmImage, now).String()).
 		Float64("similarity", similarityScore).
 		Msg("Image match rate calculation")
 
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/cloud-barista/cm-beetle/pkg/core/summary"
+	"github.com/cloud-barista/cm-beetle/pkg/core/validation"
 )
 
 // GenerateMigrationReportMarkdown generates a markdown formatted migration report
@@ -55,6 +56,9 @@ func GenerateMigrationReportMarkdown(report *MigrationReport) string {
 	// SSH Key Status
 	writeSshKeyStatus(&md, report)
 
+	// Post-Migration Validation
+	writePostMigrationValidation(&md, report.Validation)
+
 	// Cost Summary
 	writeCostSummary(&md, &report.CostSummary)
 
@@ -318,15 +322,15 @@ func writeVmMigrationStatus(md *strings.Builder, report *MigrationReport) {
 
 	if report.TargetDetails != nil && report.TargetDetails.ComputeResources.Vms != nil {
 		for i, vm := range report.TargetDetails.ComputeResources.Vms {
-			// Extract sourceMachineId from VM name
-			sourceMachineId := extractSourceMachineId(vm.Name)
+			// Get sourceMachineId from the VM labels
+			sourceMachineId := sourceMachineIdOf(vm)
 
 			// Format VM info
 			vmInfo := fmt.Sprintf("**VM Name:** %s<br>**VM ID:** %s<br>**Label(sourceMachineId):** %s",
 				vm.Name, vm.CspVmId, sourceMachineId)
 
 			// Get source node info
-			sourceInfo := extractSourceServerInfoDetailed(vm.Name, report.SourceDetails)
+			sourceInfo := extractSourceServerInfoDetailed(vm, report.SourceDetails)
 
 			md.WriteString(fmt.Sprintf("| %d | %s | %s |\n", i+1, vmInfo, sourceInfo))
 		}
@@ -357,8 +361,8 @@ func writeVmSpecStatus(md *strings.Builder, report *MigrationReport) {
 		for i, vm := range report.TargetDetails.ComputeResources.Vms {
 			vmName := vm.Name
 			specInfo := formatVmSpecInfo(vm)
-			sourceInfo := extractSourceServerInfoDetailed(vm.Name, report.SourceDetails)
-			sourceSpec := formatSourceServerSpecInfo(vm.Name, report.SourceDetails)
+			sourceInfo := extractSourceServerInfoDetailed(vm, report.SourceDetails)
+			sourceSpec := formatSourceServerSpecInfo(vm, report.SourceDetails)
 			md.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n", i+1, vmName, specInfo, sourceInfo, sourceSpec))
 		}
 	}
@@ -388,8 +392,8 @@ func writeVmImageStatus(md *strings.Builder, report *MigrationReport) {
 		for i, vm := range report.TargetDetails.ComputeResources.Vms {
 			vmName := vm.Name
 			imageInfo := fmt.Sprintf("**Image ID:** %s<br>**OS Type:** %s<br>**OS Distribution:** %s", vm.Image.Id, vm.Image.OsType, vm.Image.Distribution)
-			sourceInfo := extractSourceServerInfoDetailed(vm.Name, report.SourceDetails)
-			sourceOS := extractSourceOSInfoDetailed(vm.Name, report.SourceDetails)
+			sourceInfo := extractSourceServerInfoDetailed(vm, report.SourceDetails)
+			sourceOS := extractSourceOSInfoDetailed(vm, report.SourceDetails)
 			md.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n", i+1, vmName, imageInfo, sourceInfo, sourceOS))
 		}
 	}
@@ -423,7 +427,7 @@ func writeSecurityGroupStatus(md *strings.Builder, report *MigrationReport) {
 				md.WriteString("**Assigned VMs:**\n\n")
 				for _, vm := range assignedVMsList {
 					sourceInfo := extractSourceServerInfoDetailed(vm, report.SourceDetails)
-					md.WriteString(fmt.Sprintf("- **VM:** %s\n", vm))
+					md.WriteString(fmt.Sprintf("- **VM:** %s\n", vm.Name))
 					md.WriteString(fmt.Sprintf("  - **Source Server:** %s\n", strings.ReplaceAll(sourceInfo, "<br>", ", ")))
 				}
 				md.WriteString("\n")
@@ -605,109 +609,96 @@ func writeSshKeyStatus(md *strings.Builder, report *MigrationReport) {
 
 // Helper functions for extracting source information
 
-// extractSourceMachineId extracts machine ID from VM name
-func extractSourceMachineId(vmName string) string {
-	// VM name format: "migrated-{machineId}-..."
-	parts := strings.Split(vmName, "-")
-	if len(parts) >= 6 {
-		// Reconstruct full machine ID from parts (e.g., 0036e4b9-c8b4-e811-906e-000ffee02d5c)
-		return fmt.Sprintf("%s-%s-%s-%s-%s", parts[1], parts[2], parts[3], parts[4], parts[5])
+// findSourceServer finds the source server of the VM by its source labels set by the migration
+func findSourceServer(vm summary.SummaryVmInfo, sourceDetails *summary.SourceInfraSummary) (summary.SourceServerInfo, bool) {
+	if sourceDetails == nil {
+		return summary.SourceServerInfo{}, false
 	}
-	if len(parts) >= 2 {
-		return parts[1]
+	for _, node := range sourceDetails.ComputeResources.Servers {
+		if isMigratedFrom(vm, node) {
+			return node, true
+		}
+	}
+	return summary.SourceServerInfo{}, false
+}
+
+// sourceMachineIdOf returns the source machine ID labeled on the VM (or in the VM name without the labels), or "N/A"
+func sourceMachineIdOf(vm summary.SummaryVmInfo) string {
+	if vm.Source.MachineId != "" {
+		return vm.Source.MachineId
+	}
+	if vm.Source.Hostname == "" {
+		if machineID := extractSourceMachineID(vm.Name); machineID != "" {
+			return machineID
+		}
 	}
 	return "N/A"
 }
 
 // extractSourceServerInfoDetailed extracts detailed source node information
-func extractSourceServerInfoDetailed(vmName string, sourceDetails *summary.SourceInfraSummary) string {
+func extractSourceServerInfoDetailed(vm summary.SummaryVmInfo, sourceDetails *summary.SourceInfraSummary) string {
 	if sourceDetails == nil || sourceDetails.ComputeResources.Servers == nil {
 		return "**Hostname:** N/A<br>**Machine ID:** N/A"
 	}
 
-	// Extract machine ID from VM name (format: "migrated-{MachineId}")
-	machineID := extractSourceMachineId(vmName)
-
-	// Match source node by machine ID field directly
-	for _, node := range sourceDetails.ComputeResources.Servers {
-		if node.MachineId == machineID {
-			return fmt.Sprintf("**Hostname:** %s<br>**Machine ID:** %s", node.Hostname, node.MachineId)
-		}
+	// Match source node by the source labels of the VM
+	if node, ok := findSourceServer(vm, sourceDetails); ok {
+		return fmt.Sprintf("**Hostname:** %s<br>**Machine ID:** %s", node.Hostname, node.MachineId)
 	}
 
-	// If no match found, still show the machine ID extracted from VM name
-	return fmt.Sprintf("**Hostname:** N/A<br>**Machine ID:** %s", machineID)
+	// If no match found, still show the machine ID labeled on the VM
+	return fmt.Sprintf("**Hostname:** N/A<br>**Machine ID:** %s", sourceMachineIdOf(vm))
 }
 
-func extractSourceServerInfo(vmName string, sourceDetails *summary.SourceInfraSummary) string {
+func extractSourceServerInfo(vm summary.SummaryVmInfo, sourceDetails *summary.SourceInfraSummary) string {
 	if sourceDetails == nil || sourceDetails.ComputeResources.Servers == nil {
 		return "N/A"
 	}
 
-	// Extract machine ID from VM name
-	machineID := extractSourceMachineId(vmName)
-
-	// Match by machine ID field
-	for _, node := range sourceDetails.ComputeResources.Servers {
-		if node.MachineId == machineID {
-			return fmt.Sprintf("%s<br>%s", node.Hostname, node.MachineId)
-		}
+	// Match by the source labels of the VM
+	if node, ok := findSourceServer(vm, sourceDetails); ok {
+		return fmt.Sprintf("%s<br>%s", node.Hostname, node.MachineId)
 	}
 
-	return fmt.Sprintf("Source node<br>%s", machineID)
+	return fmt.Sprintf("Source node<br>%s", sourceMachineIdOf(vm))
 }
 
-func extractSourceSpecInfo(vmName string, sourceDetails *summary.SourceInfraSummary) string {
+func extractSourceSpecInfo(vm summary.SummaryVmInfo, sourceDetails *summary.SourceInfraSummary) string {
 	if sourceDetails == nil || sourceDetails.ComputeResources.Servers == nil {
 		return "N/A"
 	}
 
-	// Extract machine ID from VM name
-	machineID := extractSourceMachineId(vmName)
-
-	// Match by machine ID field
-	for _, node := range sourceDetails.ComputeResources.Servers {
-		if node.MachineId == machineID {
-			return fmt.Sprintf("%d CPUs, %d Threads<br>%d GB RAM, %d GB Disk",
-				node.CPU.CPUs, node.CPU.Threads, node.Memory.TotalGB, node.Disk.TotalGB)
-		}
+	// Match by the source labels of the VM
+	if node, ok := findSourceServer(vm, sourceDetails); ok {
+		return fmt.Sprintf("%d CPUs, %d Threads<br>%d GB RAM, %d GB Disk",
+			node.CPU.CPUs, node.CPU.Threads, node.Memory.TotalGB, node.Disk.TotalGB)
 	}
 
 	return "N/A"
 }
 
-func extractSourceOSInfo(vmName string, sourceDetails *summary.SourceInfraSummary) string {
+func extractSourceOSInfo(vm summary.SummaryVmInfo, sourceDetails *summary.SourceInfraSummary) string {
 	if sourceDetails == nil || sourceDetails.ComputeResources.Servers == nil {
 		return "N/A"
 	}
 
-	// Extract machine ID from VM name
-	machineID := extractSourceMachineId(vmName)
-
-	// Match by machine ID field
-	for _, node := range sourceDetails.ComputeResources.Servers {
-		if node.MachineId == machineID {
-			return fmt.Sprintf("%s %s", node.OS.Name, node.OS.Version)
-		}
+	// Match by the source labels of the VM
+	if node, ok := findSourceServer(vm, sourceDetails); ok {
+		return fmt.Sprintf("%s %s", node.OS.Name, node.OS.Version)
 	}
 
 	return "N/A"
 }
 
-func extractSourceOSInfoDetailed(vmName string, sourceDetails *summary.SourceInfraSummary) string {
+func extractSourceOSInfoDetailed(vm summary.SummaryVmInfo, sourceDetails *summary.SourceInfraSummary) string {
 	if sourceDetails == nil || sourceDetails.ComputeResources.Servers == nil {
 		return "**PrettyName:** N/A<br>**Name:** N/A<br>**Version:** N/A"
 	}
 
-	// Extract machine ID from VM name
-	machineID := extractSourceMachineId(vmName)
-
-	// Match by machine ID field
-	for _, node := range sourceDetails.ComputeResources.Servers {
-		if node.MachineId == machineID {
-			return fmt.Sprintf("**PrettyName:** %s<br>**Name:** %s<br>**Version:** %s",
-				node.OS.PrettyName, node.OS.Name, node.OS.Version)
-		}
+	// Match by the source labels of the VM
+	if node, ok := findSourceServer(vm, sourceDetails); ok {
+		return fmt.Sprintf("**PrettyName:** %s<br>**Name:** %s<br>**Version:** %s",
+			node.OS.PrettyName, node.OS.Name, node.OS.Version)
 	}
 
 	return "**PrettyName:** N/A<br>**Name:** N/A<br>**Version:** N/A"
@@ -722,20 +713,15 @@ func formatVmSpecInfo(vm summary.SummaryVmInfo) string {
 }
 
 // formatSourceServerSpecInfo formats source node spec information for display
-func formatSourceServerSpecInfo(vmName string, sourceDetails *summary.SourceInfraSummary) string {
+func formatSourceServerSpecInfo(vm summary.SummaryVmInfo, sourceDetails *summary.SourceInfraSummary) string {
 	if sourceDetails == nil || sourceDetails.ComputeResources.Servers == nil {
 		return "**CPUs:** N/A<br>**Threads:** N/A<br>**Memory:** N/A<br>**Root Disk:** N/A"
 	}
 
-	// Extract machine ID from VM name
-	machineID := extractSourceMachineId(vmName)
-
-	// Match by machine ID field
-	for _, node := range sourceDetails.ComputeResources.Servers {
-		if node.MachineId == machineID {
-			return fmt.Sprintf("**CPUs:** %d<br>**Threads:** %d<br>**Memory:** %d GB<br>**Root Disk:** %d GB",
-				node.CPU.CPUs, node.CPU.Threads, node.Memory.TotalGB, node.Disk.TotalGB)
-		}
+	// Match by the source labels of the VM
+	if node, ok := findSourceServer(vm, sourceDetails); ok {
+		return fmt.Sprintf("**CPUs:** %d<br>**Threads:** %d<br>**Memory:** %d GB<br>**Root Disk:** %d GB",
+			node.CPU.CPUs, node.CPU.Threads, node.Memory.TotalGB, node.Disk.TotalGB)
 	}
 
 	return "**CPUs:** N/A<br>**Threads:** N/A<br>**Memory:** N/A<br>**Root Disk:** N/A"
@@ -763,8 +749,8 @@ func getVMsUsingSecurityGroup(sgName string, targetDetails *summary.TargetInfraS
 	return strings.Join(vms, "<br>")
 }
 
-func getVMsUsingSecurityGroupList(sgName string, targetDetails *summary.TargetInfraSummary) []string {
-	vms := []string{}
+func getVMsUsingSecurityGroupList(sgName string, targetDetails *summary.TargetInfraSummary) []summary.SummaryVmInfo {
+	vms := []summary.SummaryVmInfo{}
 	if targetDetails == nil || targetDetails.ComputeResources.Vms == nil {
 		return vms
 	}
@@ -772,7 +758,7 @@ func getVMsUsingSecurityGroupList(sgName string, targetDetails *summary.TargetIn
 	for _, vm := range targetDetails.ComputeResources.Vms {
 		for _, sg := range vm.Misc.SecurityGroups {
 			if sg == sgName {
-				vms = append(vms, vm.Name)
+				vms = append(vms, vm)
 				break
 			}
 		}
@@ -1057,6 +1043,51 @@ func writeSecurityAnalysis(md *strings.Builder, analysis *SecurityMigrationAnaly
 	md.WriteString("---\n\n")
 }
 
+func writePostMigrationValidation(md *strings.Builder, result *validation.ValidationResult) {
+	md.WriteString("## ✅ Post-Migration Validation\n\n")
+
+	if result == nil {
+		md.WriteString("*The migrated VMs have not been validated yet.*\n\n")
+		md.WriteString("---\n\n")
+		return
+	}
+
+	md.WriteString(fmt.Sprintf("*Validated: %s*\n\n", result.ValidatedAt.Format("2006-01-02 15:04:05")))
+	md.WriteString("| Total VMs | Passed | Warned | Failed | Overall |\n")
+	md.WriteString("|-----------|--------|--------|--------|---------|\n")
+	md.WriteString(fmt.Sprintf("| %d | %d | %d | %d | %s |\n\n",
+		result.Summary.TotalNodes, result.Summary.PassedNodes, result.Summary.WarnedNodes, result.Summary.FailedNodes,
+		formatValidationStatus(result.Status)))
+
+	for _, node := range result.Nodes {
+		md.WriteString(fmt.Sprintf("### %s %s\n\n", formatValidationStatus(node.Status), node.NodeName))
+		if node.SourceHostname != "" {
+			md.WriteString(fmt.Sprintf("- **Source Server**: %s (%s)\n", node.SourceHostname, node.SourceMachineId))
+		}
+		if node.Error != "" {
+			md.WriteString(fmt.Sprintf("- **Error**: %s\n", node.Error))
+		}
+		md.WriteString("\n")
+
+		if len(node.Checks) == 0 {
+			continue
+		}
+		md.WriteString("| Check | Status | Expected | Actual | Message |\n")
+		md.WriteString("|-------|--------|----------|--------|---------|\n")
+		for _, check := range node.Checks {
+			md.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+				check.Name,
+				formatValidationStatus(check.Status),
+				formatIfEmpty(check.Expected, "-"),
+				formatIfEmpty(check.Actual, "-"),
+				formatIfEmpty(strings.ReplaceAll(check.Message, "\n", " "), "-")))
+		}
+		md.WriteString("\n")
+	}
+
+	md.WriteString("---\n\n")
+}
+
 func writeCostSummary(md *strings.Builder, summary *CostSummary) {
 	md.WriteString("## 💰 Cost Summary\n\n")
 
@@ -1185,6 +1216,19 @@ func formatResourceChangeFloat(source int, target int) string {
 	}
 }
 
+func formatValidationStatus(status string) string {
+	switch status {
+	case validation.StatusPass:
+		return "✅ Pass"
+	case validation.StatusWarn:
+		return "⚠️ Warn"
+	case validation.StatusFail:
+		return "❌ Fail"
+	default:
+		return "⏭️ Skip"
+	}
+}
+
 func formatConversionStatus(converted bool) string {
 	if converted {
 		return "✅ Converted"
@@ -22,6 +22,7 @@ import (
 
 	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
 	"github.com/cloud-barista/cm-beetle/pkg/core/summary"
+	"github.com/cloud-barista/cm-beetle/pkg/core/validation"
 	"github.com/rs/zerolog/log"
 )
 
@@ -84,6 +85,13 @@ func GenerateMigrationReport(nsId, infraId string, sourceInfra onpremmodel.Onpre
 		TargetDetails:     targetSummary,
 	}
 
+	// Step 11: Attach the latest post-migration validation, if any
+	if result, err := validation.GetLatestValidation(nsId, infraId); err == nil {
+		report.Validation = &result
+	} else {
+		log.Debug().Msgf("No post-migration validation to attach (nsId: %s, infraId: %s)", nsId, infraId)
+	}
+
 	log.Info().Msgf("Successfully generated migration report (nsId: %s, infraId: %s)", nsId, infraId)
 	return report, nil
 }
@@ -120,13 +128,21 @@ func buildExecutiveSummary(sourceSummary *summary.SourceInfraSummary, targetSumm
 func buildMigrationMappings(sourceSummary *summary.SourceInfraSummary, targetSummary *summary.TargetInfraSummary) []SourceTargetMapping {
 	var mappings []SourceTargetMapping
 
-	// Create a map of machine IDs from target VMs
+	// Create maps of the source machine IDs and hostnames (labeled by the migration) to target VMs
 	vmByMachineID := make(map[string]summary.SummaryVmInfo)
+	vmByHostname := make(map[string]summary.SummaryVmInfo)
+	vmByNamedMachineID := make(map[string]summary.SummaryVmInfo) // For the VMs migrated before the labels
 	for _, vm := range targetSummary.ComputeResources.Vms {
-		// Extract sourceMachineId from VM name or labels
-		machineID := extractSourceMachineID(vm.Name)
-		if machineID != "" {
-			vmByMachineID[machineID] = vm
+		if vm.Source.MachineId != "" {
+			vmByMachineID[vm.Source.MachineId] = vm
+		}
+		if vm.Source.Hostname != "" {
+			vmByHostname[vm.Source.Hostname] = vm
+		}
+		if vm.Source.MachineId == "" && vm.Source.Hostname == "" {
+			if machineID := extractSourceMachineID(vm.Name); machineID != "" {
+				vmByNamedMachineID[machineID] = vm
+			}
 		}
 	}
 
@@ -136,9 +152,16 @@ func buildMigrationMappings(sourceSummary *summary.SourceInfraSummary, targetSum
 		// Try to find matching VM by machine ID (now directly from source node data)
 		machineID := sourceNode.MachineId
 		targetVM, found := vmByMachineID[machineID]
+		if !found {
+			// Fall back to the hostname (e.g., the source node without a machine ID)
+			targetVM, found = vmByHostname[sourceNode.Hostname]
+		}
+		if !found && machineID != "" {
+			// Last resort: the machine ID in the VM name (e.g., the infra migrated before the labels)
+			targetVM, found = vmByNamedMachineID[machineID]
+		}
 
 		if !found {
-			// Try alternative matching strategies
 			log.Warn().Msgf("No matching target VM found for source node: %s (MachineId: %s)", sourceNode.Hostname, machineID)
 			continue
 		}
@@ -315,11 +338,9 @@ func buildNetworkAnalysis(sourceSummary *summary.SourceInfraSummary, targetSumma
 	// Build IP mappings
 	var ipMappings []IPMapping
 	for _, node := range sourceSummary.ComputeResources.Servers {
-		machineID := node.MachineId
-
 		// Find matching target VM
 		for _, vm := range targetSummary.ComputeResources.Vms {
-			if strings.Contains(vm.Name, machineID) {
+			if isMigratedFrom(vm, node) {
 				mapping := IPMapping{
 					SourceIP:        node.Network.IPAddress,
 					SourceHostname:  node.Hostname,
@@ -500,12 +521,25 @@ func generateRecommendations(sourceSummary *summary.SourceInfraSummary, targetSu
 
 // Helper functions
 
-// extractSourceMachineID extracts machine ID from VM name
+// isMigratedFrom reports whether the VM is migrated from the source node, by the source labels of the VM
+// or, for the VM without the labels (e.g., migrated before the labels), by the machine ID in the VM name.
+func isMigratedFrom(vm summary.SummaryVmInfo, node summary.SourceServerInfo) bool {
+	if vm.Source.MachineId != "" && node.MachineId != "" {
+		return vm.Source.MachineId == node.MachineId
+	}
+	if vm.Source.Hostname != "" {
+		return vm.Source.Hostname == node.Hostname
+	}
+	return node.MachineId != "" && extractSourceMachineID(vm.Name) == node.MachineId
+}
+
+// machineIDPattern is a UUID pattern: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+var machineIDPattern = regexp.MustCompile(`[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}`)
+
+// extractSourceMachineID extracts machine ID from VM name (the fallback for the VMs without the source labels)
 // Example: "migrated-0036e4b9-c8b4-e811-906e-000ffee02d5c-1" -> "0036e4b9-c8b4-e811-906e-000ffee02d5c"
 func extractSourceMachineID(vmName string) string {
-	// A UUID pattern: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
-	re := regexp.MustCompile(`[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}`)
-	match := re.FindString(vmName)
+	match := machineIDPattern.FindString(vmName)
 	if match != "" {
 		return match
 	}
@@ -526,14 +560,3 @@ func extractSourceMachineID(vmName string) string {
 	}
 	return ""
 }
-
-// extractMachineIDFromHostname attempts to extract machine ID from source node
-// This is a placeholder - actual implementation depends on source data structure
-func extractMachineIDFromHostname(hostname string) string {
-	// In real implementation, this would look up machine ID from source data
-	// For now, we'll use a placeholder that matches the VM naming pattern
-
-	// This is a simplified approach - in production, you'd need actual machine IDs
-	// from the source infrastructure data
-	return "" // Will be populated from actual source data
-}
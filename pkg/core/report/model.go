@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/cloud-barista/cm-beetle/pkg/core/summary"
+	"github.com/cloud-barista/cm-beetle/pkg/core/validation"
 )
 
 // MigrationReport represents a comprehensive migration analysis report
@@ -31,6 +32,8 @@ type MigrationReport struct {
 	Recommendations   []Recommendation            `json:"recommendations"`
 	SourceDetails     *summary.SourceInfraSummary `json:"sourceDetails"`
 	TargetDetails     *summary.TargetInfraSummary `json:"targetDetails"`
+	// Validation is the latest post-migration validation of the migrated VMs (nil if not validated yet)
+	Validation *validation.ValidationResult `json:"validation,omitempty"`
 }
 
 // ReportMetadata contains report generation metadata
@@ -132,14 +132,25 @@ type SummaryImageInfoWithUsage struct {
 
 // SummaryVmInfo represents VM information for summary with restructured format
 type SummaryVmInfo struct {
-	Name    string             `json:"name" example:"migrated-server-1"`
-	CspVmId string             `json:"cspVmId" example:"i-0a1b2c3d4e5f6g7h8"`
-	Status  string             `json:"status" example:"Running"`
-	Spec    SummaryVmSpecInfo  `json:"spec"`
-	Image   SummaryVmImageInfo `json:"image"`
-	Misc    SummaryVmMiscInfo  `json:"misc"`
-	Region  string             `json:"region" example:"ap-northeast-2"`
-	Zone    string             `json:"zone,omitempty" example:"ap-northeast-2a"`
+	Name    string              `json:"name" example:"migrated-server-1"`
+	CspVmId string              `json:"cspVmId" example:"i-0a1b2c3d4e5f6g7h8"`
+	Status  string              `json:"status" example:"Running"`
+	Spec    SummaryVmSpecInfo   `json:"spec"`
+	Image   SummaryVmImageInfo  `json:"image"`
+	Misc    SummaryVmMiscInfo   `json:"misc"`
+	Region  string              `json:"region" example:"ap-northeast-2"`
+	Zone    string              `json:"zone,omitempty" example:"ap-northeast-2a"`
+	Source  SummaryVmSourceInfo `json:"source"`
+}
+
+// SummaryVmSourceInfo represents the source server and the migration of the VM (from the labels set by the migration)
+type SummaryVmSourceInfo struct {
+	MachineId  string `json:"machineId,omitempty" example:"ec288dd0-c6fa-8a49-2f60-bfe8b6dc5c9b"`
+	Hostname   string `json:"hostname,omitempty" example:"cm-web"`
+	ProjectId  string `json:"projectId,omitempty" example:"prj-web"`
+	Wave       string `json:"wave,omitempty" example:"plan01/wave-1"`
+	MigratedAt string `json:"migratedAt,omitempty" example:"2025-01-01T00:00:00Z"`
+	ReqId      string `json:"reqId,omitempty" example:"1735689600000000000"`
 }
 
 // SummaryVmSpecInfo represents VM Spec summary embedded in VM info
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
 	"github.com/rs/zerolog/log"
 
 	tbmodel "github.com/cloud-barista/cb-tumblebug/src/core/model"
@@ -375,6 +376,7 @@ func collectComputeResources(nsId string, infraInfo *tbmodel.InfraInfo, specIds,
 			},
 			Region: node.Region.Region,
 			Zone:   node.Region.Zone,
+			Source: buildVmSourceInfo(node.Label),
 		}
 
 		resources.Vms = append(resources.Vms, reportVm)
@@ -383,6 +385,18 @@ func collectComputeResources(nsId string, infraInfo *tbmodel.InfraInfo, specIds,
 	return resources, nil
 }
 
+// buildVmSourceInfo builds the source information of a VM from its labels set by the migration
+func buildVmSourceInfo(label map[string]string) SummaryVmSourceInfo {
+	return SummaryVmSourceInfo{
+		MachineId:  label[common.LabelSourceMachineId],
+		Hostname:   label[common.LabelSourceHostname],
+		ProjectId:  label[common.LabelMigrationProject],
+		Wave:       label[common.LabelMigrationWave],
+		MigratedAt: label[common.LabelMigratedAt],
+		ReqId:      label[common.LabelMigrationReqId],
+	}
+}
+
 // buildInfraOverview builds the migration summary from Infra info
 func buildInfraOverview(infraInfo *tbmodel.InfraInfo) TargetInfraOverview {
 	runningCount := 0
@@ -0,0 +1,651 @@
+// Package validation verifies the migrated VMs against the source servers
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	tbclient "github.com/cloud-barista/cm-beetle/pkg/client/tumblebug"
+	"github.com/cloud-barista/cm-beetle/pkg/core/common"
+	"github.com/cloud-barista/cm-beetle/pkg/lkvstore"
+	"github.com/rs/zerolog/log"
+
+	tbmodel "github.com/cloud-barista/cb-tumblebug/src/core/model"
+)
+
+// ============================================================================
+// Post-migration validation
+// Runs the checks (OS release, listening ports, data disks, hostname, timezone
+// and user-defined checks) on the migrated VMs via the remote command of Tumblebug,
+// and compares the results with the source servers.
+// ============================================================================
+
+// Status of a check and a node
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Names of the built-in checks
+const (
+	CheckOsRelease      = "os-release"
+	CheckListeningPorts = "listening-ports"
+	CheckDataDisks      = "data-disks"
+	CheckHostname       = "hostname"
+	CheckTimezone       = "timezone"
+)
+
+const (
+	validationKeyPrefix = "/beetle/validation/"
+
+	// exitCodeMarker is appended to the output of each command to get its exit code
+	exitCodeMarker = "__beetle_rc="
+
+	// diskCapacityTolerance is the ratio of the expected capacity that a data disk should have
+	// (the file system overhead makes `df` report a slightly smaller size)
+	diskCapacityTolerance = 0.95
+)
+
+// CustomCheck is a user-defined check executed on the migrated VMs.
+type CustomCheck struct {
+	Name    string `json:"name" validate:"required" example:"nginx-active"`
+	Command string `json:"command" validate:"required" example:"systemctl is-active nginx"`
+	// ExpectedExitCode is the exit code of the command for the check to pass (default: 0)
+	ExpectedExitCode int `json:"expectedExitCode,omitempty" example:"0"`
+	// ExpectedOutput is a regular expression the output (stdout) should match (optional)
+	ExpectedOutput string `json:"expectedOutput,omitempty" example:"^active"`
+	// NodeGroups restricts the check to the NodeGroups (all NodeGroups if empty)
+	NodeGroups []string `json:"nodeGroups,omitempty" example:"g1"`
+}
+
+// ValidationReq is the request to validate the migrated VMs of an Infra.
+type ValidationReq struct {
+	// SourceInfra is the source computing infrastructure the Infra has been migrated from
+	SourceInfra onpremmodel.OnpremInfra `json:"sourceInfra"`
+	// UserName is the SSH username of the VMs (Tumblebug decides if empty)
+	UserName string `json:"userName,omitempty" example:"cb-user"`
+	// ExpectedTimezone is the timezone the VMs should have (only reported if empty)
+	ExpectedTimezone string `json:"expectedTimezone,omitempty" example:"Asia/Seoul"`
+	// CustomChecks are the user-defined checks
+	CustomChecks []CustomCheck `json:"customChecks,omitempty"`
+}
+
+// CheckResult is the result of a check on a VM.
+type CheckResult struct {
+	Name     string `json:"name" example:"os-release"`
+	Status   string `json:"status" enums:"pass,warn,fail,skip" example:"pass"`
+	Expected string `json:"expected,omitempty" example:"ubuntu 22.04"`
+	Actual   string `json:"actual,omitempty" example:"ubuntu 22.04"`
+	Message  string `json:"message,omitempty"`
+}
+
+// NodeValidationResult is the validation result of a migrated VM.
+type NodeValidationResult struct {
+	NodeId          string        `json:"nodeId" example:"g1-1"`
+	NodeName        string        `json:"nodeName" example:"migrated-0036e4b9-c8b4-e811-906e-000ffee02d5c-1"`
+	NodeGroupId     string        `json:"nodeGroupId" example:"g1"`
+	PublicIp        string        `json:"publicIp,omitempty"`
+	SourceHostname  string        `json:"sourceHostname,omitempty" example:"cm-nfs"`
+	SourceMachineId string        `json:"sourceMachineId,omitempty" example:"0036e4b9-c8b4-e811-906e-000ffee02d5c"`
+	Status          string        `json:"status" enums:"pass,warn,fail" example:"pass"`
+	Checks          []CheckResult `json:"checks"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// ValidationSummary is the number of nodes per status.
+type ValidationSummary struct {
+	TotalNodes  int `json:"totalNodes"`
+	PassedNodes int `json:"passedNodes"`
+	WarnedNodes int `json:"warnedNodes"`
+	FailedNodes int `json:"failedNodes"`
+}
+
+// ValidationResult is the validation result of the migrated VMs of an Infra.
+type ValidationResult struct {
+	NsId        string                 `json:"nsId" example:"mig01"`
+	InfraId     string                 `json:"infraId" example:"mmci01"`
+	ValidatedAt time.Time              `json:"validatedAt"`
+	Status      string                 `json:"status" enums:"pass,warn,fail" example:"pass"`
+	Summary     ValidationSummary      `json:"summary"`
+	Nodes       []NodeValidationResult `json:"nodes"`
+}
+
+// ValidateInfra runs the validation checks on the VMs of the Infra and stores the result as the latest one.
+func ValidateInfra(nsId, infraId string, req ValidationReq) (ValidationResult, error) {
+	log.Info().Msgf("Validating the migrated VMs (nsId: %s, infraId: %s)", nsId, infraId)
+
+	for _, check := range req.CustomChecks {
+		if check.Name == "" || check.Command == "" {
+			return ValidationResult{}, fmt.Errorf("the name and command of a custom check cannot be empty")
+		}
+		if check.ExpectedOutput != "" {
+			if _, err := regexp.Compile(check.ExpectedOutput); err != nil {
+				return ValidationResult{}, fmt.Errorf("the expected output of the custom check (%s) cannot be compiled: %w", check.Name, err)
+			}
+		}
+	}
+
+	infraInfo, err := tbclient.NewSession().ReadInfra(nsId, infraId)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to read the Infra (nsId: %s, infraId: %s)", nsId, infraId)
+		return ValidationResult{}, fmt.Errorf("the Infra does not exist or cannot be read (nsId: %s, infraId: %s): %w", nsId, infraId, err)
+	}
+	if len(infraInfo.Node) == 0 {
+		return ValidationResult{}, fmt.Errorf("the Infra has no node to validate (nsId: %s, infraId: %s)", nsId, infraId)
+	}
+
+	// Validate the nodes concurrently
+	results := make([]NodeValidationResult, len(infraInfo.Node))
+	var wg sync.WaitGroup
+	for i, node := range infraInfo.Node {
+		wg.Add(1)
+		go func(i int, node tbmodel.NodeInfo) {
+			defer wg.Done()
+			source := matchSourceNode(node, req.SourceInfra.Nodes)
+			results[i] = validateNode(nsId, infraId, node, source, req)
+		}(i, node)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].NodeId < results[j].NodeId })
+
+	result := ValidationResult{
+		NsId:        nsId,
+		InfraId:     infraId,
+		ValidatedAt: time.Now(),
+		Nodes:       results,
+	}
+	result.Summary.TotalNodes = len(results)
+	for _, node := range results {
+		switch node.Status {
+		case StatusPass:
+			result.Summary.PassedNodes++
+		case StatusWarn:
+			result.Summary.WarnedNodes++
+		default:
+			result.Summary.FailedNodes++
+		}
+	}
+	result.Status = StatusPass
+	if result.Summary.WarnedNodes > 0 {
+		result.Status = StatusWarn
+	}
+	if result.Summary.FailedNodes > 0 {
+		result.Status = StatusFail
+	}
+
+	if err := lkvstore.Put(validationKeyPrefix+nsId+"/"+infraId, result); err != nil {
+		log.Error().Err(err).Msgf("failed to store the validation result (nsId: %s, infraId: %s)", nsId, infraId)
+	}
+
+	log.Info().Msgf("Validated the migrated VMs (nsId: %s, infraId: %s, status: %s, passed: %d, warned: %d, failed: %d)",
+		nsId, infraId, result.Status, result.Summary.PassedNodes, result.Summary.WarnedNodes, result.Summary.FailedNodes)
+	return result, nil
+}
+
+// GetLatestValidation returns the latest validation result of the Infra.
+func GetLatestValidation(nsId, infraId string) (ValidationResult, error) {
+	value, ok := lkvstore.Get(validationKeyPrefix + nsId + "/" + infraId)
+	if !ok {
+		return ValidationResult{}, fmt.Errorf("the validation result does not exist (nsId: %s, infraId: %s)", nsId, infraId)
+	}
+	result, ok := convertToValidationResult(value)
+	if !ok {
+		return ValidationResult{}, fmt.Errorf("failed to read the validation result (nsId: %s, infraId: %s)", nsId, infraId)
+	}
+	return result, nil
+}
+
+// convertToValidationResult converts the stored value (a struct, or a map once loaded from the file) to ValidationResult.
+func convertToValidationResult(value any) (ValidationResult, bool) {
+	switch v := value.(type) {
+	case ValidationResult:
+		return v, true
+	case map[string]any:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to marshal value to JSON")
+			return ValidationResult{}, false
+		}
+
+		var result ValidationResult
+		if err := json.Unmarshal(jsonBytes, &result); err != nil {
+			log.Error().Err(err).Msg("Failed to unmarshal JSON to ValidationResult")
+			return ValidationResult{}, false
+		}
+		return result, true
+	default:
+		log.Error().Msgf("Unexpected value type: %T", value)
+		return ValidationResult{}, false
+	}
+}
+
+// matchSourceNode finds the source node of a migrated VM by the source labels set by the migration
+// (sourceMachineId, sourceHostname), or by the machine ID or the hostname in the VM name
+// (e.g., "migrated-0036e4b9-c8b4-e811-906e-000ffee02d5c-1") for the VMs migrated without the labels.
+func matchSourceNode(vm tbmodel.NodeInfo, sourceNodes []onpremmodel.NodeProperty) *onpremmodel.NodeProperty {
+	if machineId := vm.Label[common.LabelSourceMachineId]; machineId != "" {
+		for i, node := range sourceNodes {
+			if node.MachineId == machineId {
+				return &sourceNodes[i]
+			}
+		}
+	}
+	if hostname := vm.Label[common.LabelSourceHostname]; hostname != "" {
+		for i, node := range sourceNodes {
+			if node.Hostname == hostname {
+				return &sourceNodes[i]
+			}
+		}
+	}
+
+	for i, node := range sourceNodes {
+		if node.MachineId != "" && strings.Contains(vm.Name, node.MachineId) {
+			return &sourceNodes[i]
+		}
+	}
+	for i, node := range sourceNodes {
+		if node.Hostname != "" && strings.Contains(vm.Name, node.Hostname) {
+			return &sourceNodes[i]
+		}
+	}
+	return nil
+}
+
+// validateNode runs the checks on a VM in a single remote command request.
+func validateNode(nsId, infraId string, node tbmodel.NodeInfo, source *onpremmodel.NodeProperty, req ValidationReq) NodeValidationResult {
+	result := NodeValidationResult{
+		NodeId:      node.Id,
+		NodeName:    node.Name,
+		NodeGroupId: node.NodeGroupId,
+		PublicIp:    node.PublicIP,
+		Checks:      []CheckResult{},
+	}
+	if source != nil {
+		result.SourceHostname = source.Hostname
+		result.SourceMachineId = source.MachineId
+	}
+
+	// The built-in checks are based on the source node; a Windows source is not supported yet
+	runBuiltIn := source != nil && !isWindows(source.OS)
+	var customChecks []CustomCheck
+	for _, check := range req.CustomChecks {
+		if len(check.NodeGroups) == 0 || contains(check.NodeGroups, node.NodeGroupId) {
+			customChecks = append(customChecks, check)
+		}
+	}
+
+	var commands []string
+	if runBuiltIn {
+		commands = append(commands,
+			"cat /etc/os-release",
+			"ss -Hltn 2>/dev/null || netstat -ltn 2>/dev/null",
+			"df -B1 --output=target,size -x tmpfs -x devtmpfs -x overlay -x squashfs 2>/dev/null",
+			"hostname",
+			"timedatectl show -p Timezone --value 2>/dev/null || cat /etc/timezone 2>/dev/null || readlink /etc/localtime",
+		)
+	}
+	for _, check := range customChecks {
+		commands = append(commands, check.Command)
+	}
+
+	if len(commands) == 0 {
+		reason := "no source node matches the VM and no custom check is given"
+		if source != nil {
+			reason = fmt.Sprintf("the source OS (%s) is not supported and no custom check is given", source.OS.PrettyName)
+		}
+		result.Checks = append(result.Checks, CheckResult{Name: "all", Status: StatusSkip, Message: reason})
+		result.Status = StatusWarn
+		return result
+	}
+
+	// Append the exit code to the output of each command
+	cmdReq := tbmodel.InfraCmdReq{UserName: req.UserName}
+	for _, cmd := range commands {
+		cmdReq.Command = append(cmdReq.Command, fmt.Sprintf("{ %s; }; echo \"%s$?\"", cmd, exitCodeMarker))
+	}
+
+	cmdResult, err := tbclient.NewSession().RunRemoteCommand(nsId, infraId, node.Id, cmdReq)
+	if err == nil && len(cmdResult.Results) == 0 {
+		err = fmt.Errorf("no result of the remote command")
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to run the validation commands on the node (nodeId: %s)", node.Id)
+		result.Error = fmt.Sprintf("failed to run the validation commands: %v", err)
+		result.Status = StatusFail
+		return result
+	}
+	sshResult := cmdResult.Results[0]
+	if sshResult.Err != nil && len(sshResult.Stdout) == 0 {
+		result.Error = fmt.Sprintf("failed to run the validation commands: %v", sshResult.Err)
+		result.Status = StatusFail
+		return result
+	}
+
+	idx := 0
+	next := func() (string, int) {
+		output, exitCode := splitExitCode(sshResult.Stdout[idx])
+		idx++
+		return output, exitCode
+	}
+
+	if runBuiltIn {
+		osRelease, _ := next()
+		ports, _ := next()
+		disks, _ := next()
+		hostname, _ := next()
+		timezone, _ := next()
+
+		result.Checks = append(result.Checks,
+			checkOsRelease(source.OS, osRelease),
+			checkListeningPorts(source.FirewallTable, ports),
+			checkDataDisks(source.DataDisks, disks),
+			checkHostname(source.Hostname, hostname),
+			checkTimezone(req.ExpectedTimezone, timezone),
+		)
+	} else {
+		reason := "no source node matches the VM"
+		if source != nil {
+			reason = fmt.Sprintf("the source OS (%s) is not supported", source.OS.PrettyName)
+		}
+		result.Checks = append(result.Checks, CheckResult{Name: "built-in", Status: StatusSkip, Message: reason})
+	}
+
+	for _, check := range customChecks {
+		output, exitCode := next()
+		result.Checks = append(result.Checks, checkCustom(check, output, exitCode))
+	}
+
+	result.Status = StatusPass
+	for _, check := range result.Checks {
+		if check.Status == StatusFail {
+			result.Status = StatusFail
+			break
+		}
+		if check.Status == StatusWarn || check.Status == StatusSkip {
+			result.Status = StatusWarn
+		}
+	}
+	return result
+}
+
+// splitExitCode splits the output of a command into the output and the exit code appended by the marker.
+func splitExitCode(stdout string) (string, int) {
+	i := strings.LastIndex(stdout, exitCodeMarker)
+	if i < 0 {
+		return strings.TrimSpace(stdout), -1
+	}
+	exitCode, err := strconv.Atoi(strings.TrimSpace(stdout[i+len(exitCodeMarker):]))
+	if err != nil {
+		exitCode = -1
+	}
+	return strings.TrimSpace(stdout[:i]), exitCode
+}
+
+// checkOsRelease compares the OS ID and version of the VM with the source (a different minor version is a warning).
+func checkOsRelease(sourceOs onpremmodel.OsProperty, osRelease string) CheckResult {
+	values := parseOsRelease(osRelease)
+	check := CheckResult{
+		Name:     CheckOsRelease,
+		Expected: strings.TrimSpace(sourceOs.ID + " " + sourceOs.VersionID),
+		Actual:   strings.TrimSpace(values["ID"] + " " + values["VERSION_ID"]),
+	}
+
+	switch {
+	case values["ID"] == "":
+		check.Status = StatusFail
+		check.Message = "failed to read /etc/os-release"
+	case !strings.EqualFold(values["ID"], sourceOs.ID):
+		check.Status = StatusFail
+		check.Message = "the OS distribution differs from the source"
+	case values["VERSION_ID"] == sourceOs.VersionID:
+		check.Status = StatusPass
+	case majorVersion(values["VERSION_ID"]) == majorVersion(sourceOs.VersionID):
+		check.Status = StatusWarn
+		check.Message = "the OS minor version differs from the source"
+	default:
+		check.Status = StatusFail
+		check.Message = "the OS major version differs from the source"
+	}
+	return check
+}
+
+// parseOsRelease parses the KEY=value lines of /etc/os-release.
+func parseOsRelease(osRelease string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(osRelease, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found {
+			continue
+		}
+		values[key] = strings.Trim(value, `"'`)
+	}
+	return values
+}
+
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
+
+// checkListeningPorts checks if the TCP ports allowed inbound by the source firewall rules are listening on the VM.
+// The port ranges and the wildcard are not checked, since they don't mean listening services.
+func checkListeningPorts(rules []onpremmodel.FirewallRuleProperty, listening string) CheckResult {
+	check := CheckResult{Name: CheckListeningPorts}
+
+	expected := map[int]bool{}
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.Direction, "inbound") || !strings.EqualFold(rule.Action, "allow") ||
+			!strings.EqualFold(rule.Protocol, "tcp") {
+			continue
+		}
+		for _, p := range strings.Split(rule.DstPorts, ",") {
+			port, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil || port <= 0 || port > 65535 {
+				continue
+			}
+			expected[port] = true
+		}
+	}
+	if len(expected) == 0 {
+		check.Status = StatusSkip
+		check.Message = "no single TCP port is allowed inbound by the source firewall rules"
+		return check
+	}
+
+	// The local address is the 4th column of both `ss -Hltn` and `netstat -ltn`
+	actual := map[int]bool{}
+	for _, line := range strings.Split(listening, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		local := fields[3]
+		port, err := strconv.Atoi(local[strings.LastIndex(local, ":")+1:])
+		if err != nil {
+			continue
+		}
+		actual[port] = true
+	}
+
+	var missing []string
+	check.Expected = joinPorts(expected)
+	check.Actual = joinPorts(actual)
+	for port := range expected {
+		if !actual[port] {
+			missing = append(missing, strconv.Itoa(port))
+		}
+	}
+	if len(missing) == 0 {
+		check.Status = StatusPass
+		return check
+	}
+	sort.Strings(missing)
+	check.Status = StatusFail
+	check.Message = fmt.Sprintf("the ports are not listening: %s", strings.Join(missing, ","))
+	return check
+}
+
+func joinPorts(ports map[int]bool) string {
+	sorted := make([]int, 0, len(ports))
+	for port := range ports {
+		sorted = append(sorted, port)
+	}
+	sort.Ints(sorted)
+
+	strs := make([]string, len(sorted))
+	for i, port := range sorted {
+		strs[i] = strconv.Itoa(port)
+	}
+	return strings.Join(strs, ",")
+}
+
+// checkDataDisks checks if a file system is mounted for each source data disk with the expected capacity.
+// A data disk is matched by its label if it's a mount point (e.g., "/data"), otherwise by the capacity.
+func checkDataDisks(dataDisks []onpremmodel.DiskProperty, df string) CheckResult {
+	check := CheckResult{Name: CheckDataDisks}
+	if len(dataDisks) == 0 {
+		check.Status = StatusSkip
+		check.Message = "the source has no data disk"
+		return check
+	}
+
+	// Parse the `df -B1 --output=target,size` lines except the header and the root/boot file systems
+	mounts := map[string]uint64{}
+	for _, line := range strings.Split(df, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		size, err := strconv.ParseUint(fields[len(fields)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		target := strings.Join(fields[:len(fields)-1], " ")
+		if target == "/" || strings.HasPrefix(target, "/boot") {
+			continue
+		}
+		mounts[target] = size
+	}
+
+	var expected, actual, problems []string
+	used := map[string]bool{}
+	for _, disk := range dataDisks {
+		want := disk.TotalSize * 1024 * 1024 * 1024
+		expected = append(expected, fmt.Sprintf("%s(%dGiB)", disk.Label, disk.TotalSize))
+
+		target := ""
+		if size, ok := mounts[disk.Label]; ok && strings.HasPrefix(disk.Label, "/") {
+			target = disk.Label
+			if float64(size) < float64(want)*diskCapacityTolerance {
+				problems = append(problems, fmt.Sprintf("%s has %dGiB (expected %dGiB)", target, size>>30, disk.TotalSize))
+			}
+		} else {
+			for mount, size := range mounts {
+				if !used[mount] && float64(size) >= float64(want)*diskCapacityTolerance {
+					target = mount
+					break
+				}
+			}
+			if target == "" {
+				problems = append(problems, fmt.Sprintf("no file system is mounted for %s (%dGiB)", disk.Label, disk.TotalSize))
+			}
+		}
+		if target != "" {
+			used[target] = true
+			actual = append(actual, fmt.Sprintf("%s(%dGiB)", target, mounts[target]>>30))
+		}
+	}
+
+	check.Expected = strings.Join(expected, ",")
+	check.Actual = strings.Join(actual, ",")
+	if len(problems) == 0 {
+		check.Status = StatusPass
+		return check
+	}
+	check.Status = StatusFail
+	check.Message = strings.Join(problems, "; ")
+	return check
+}
+
+// checkHostname compares the hostname of the VM with the source (a different hostname is a warning,
+// since the CSP usually assigns its own hostname).
+func checkHostname(sourceHostname, hostname string) CheckResult {
+	check := CheckResult{Name: CheckHostname, Expected: sourceHostname, Actual: hostname, Status: StatusPass}
+	if !strings.EqualFold(sourceHostname, hostname) {
+		check.Status = StatusWarn
+		check.Message = "the hostname differs from the source"
+	}
+	return check
+}
+
+// checkTimezone compares the timezone of the VM with the expected one (only reported if no timezone is expected,
+// since the source model has no timezone).
+func checkTimezone(expectedTimezone, timezone string) CheckResult {
+	// readlink returns the path of the zoneinfo file (e.g., /usr/share/zoneinfo/Asia/Seoul)
+	if i := strings.Index(timezone, "zoneinfo/"); i >= 0 {
+		timezone = timezone[i+len("zoneinfo/"):]
+	}
+
+	check := CheckResult{Name: CheckTimezone, Expected: expectedTimezone, Actual: timezone}
+	switch {
+	case expectedTimezone == "":
+		check.Status = StatusPass
+		check.Message = "no timezone is expected; reported only"
+	case timezone == expectedTimezone:
+		check.Status = StatusPass
+	default:
+		check.Status = StatusFail
+		check.Message = "the timezone differs from the expected one"
+	}
+	return check
+}
+
+// checkCustom checks the exit code and the output of a user-defined check.
+func checkCustom(custom CustomCheck, output string, exitCode int) CheckResult {
+	check := CheckResult{
+		Name:     "custom:" + custom.Name,
+		Expected: fmt.Sprintf("exit code %d", custom.ExpectedExitCode),
+		Actual:   fmt.Sprintf("exit code %d", exitCode),
+		Status:   StatusPass,
+	}
+	if custom.ExpectedOutput != "" {
+		check.Expected += fmt.Sprintf(", output matches %q", custom.ExpectedOutput)
+	}
+
+	if exitCode != custom.ExpectedExitCode {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("unexpected exit code (output: %s)", output)
+		return check
+	}
+	if custom.ExpectedOutput != "" {
+		re := regexp.MustCompile(custom.ExpectedOutput)
+		if !re.MatchString(output) {
+			check.Status = StatusFail
+			check.Message = fmt.Sprintf("the output does not match (output: %s)", output)
+		}
+	}
+	return check
+}
+
+func isWindows(os onpremmodel.OsProperty) bool {
+	return strings.Contains(strings.ToLower(os.ID+" "+os.Name+" "+os.PrettyName), "windows")
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
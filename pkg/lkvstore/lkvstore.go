@@ -15,6 +15,9 @@ import (
 var (
 	lkvstore   sync.Map
 	dbFilePath string
+
+	// saveMutex serializes the saves to the file
+	saveMutex sync.Mutex
 )
 
 type Config struct {
@@ -35,11 +38,15 @@ func Init(config Config) {
 }
 
 // Save lkvstore to file
+// The file is replaced atomically, so a crash while saving leaves the previous file intact.
 func SaveLkvStore() error {
 	if dbFilePath == "" {
 		return fmt.Errorf("db file path is not set")
 	}
 
+	saveMutex.Lock()
+	defer saveMutex.Unlock()
+
 	// Ensure the DB file directory exists before creating the log file
 	dir := filepath.Dir(dbFilePath)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -51,11 +58,11 @@ func SaveLkvStore() error {
 		}
 	}
 
-	file, err := os.Create(dbFilePath)
+	tmpFilePath := dbFilePath + ".tmp"
+	file, err := os.Create(tmpFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to create db file: %w", err)
 	}
-	defer file.Close()
 
 	tempMap := make(map[string]any)
 	lkvstore.Range(func(key, value any) bool {
@@ -65,9 +72,23 @@ func SaveLkvStore() error {
 
 	encoder := json.NewEncoder(file)
 	if err := encoder.Encode(tempMap); err != nil {
+		file.Close()
+		os.Remove(tmpFilePath)
 		return fmt.Errorf("failed to encode map: %w", err)
 	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmpFilePath)
+		return fmt.Errorf("failed to sync db file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpFilePath)
+		return fmt.Errorf("failed to close db file: %w", err)
+	}
 
+	if err := os.Rename(tmpFilePath, dbFilePath); err != nil {
+		return fmt.Errorf("failed to replace db file: %w", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,709 @@
+package nlbparser
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	"github.com/rs/zerolog/log"
+)
+
+// haproxySection is a parsed section of haproxy.cfg (defaults, frontend, backend, or listen)
+type haproxySection struct {
+	kind string // "defaults" | "frontend" | "backend" | "listen"
+	name string
+
+	binds          []haproxyBind
+	mode           string // "tcp" | "http"
+	balance        string
+	defaultBackend string
+	useBackends    []haproxyUseBackend
+	acls           map[string][]onpremmodel.NlbRoutingRuleProperty // ACL name → routing conditions (ORed)
+	unsupportedAcl map[string]bool                                 // ACLs not expressible as host/path conditions
+
+	httpCheck     bool
+	httpCheckPath string
+	noHttpCheck   bool // "no option httpchk" (overrides the defaults section)
+	checkTimeout  string
+	defaultServer haproxyServerOptions
+	servers       []haproxyServer
+}
+
+// haproxyServerOptions are health-check related options of a server or default-server line
+type haproxyServerOptions struct {
+	check     bool
+	inter     string
+	fall      string
+	checkPort string
+	weight    string
+}
+
+type haproxyServer struct {
+	name    string
+	address string
+	options haproxyServerOptions
+}
+
+type haproxyBind struct {
+	address string
+	tls     bool // "ssl" bind option
+}
+
+// haproxyUseBackend is a "use_backend <backend> [if <condition>]" rule
+type haproxyUseBackend struct {
+	backend       string
+	conditional   bool
+	rules         []onpremmodel.NlbRoutingRuleProperty // empty if the condition is not expressible
+	unsatisfiable bool                                 // the condition never matches (e.g., disjoint host/path conditions)
+}
+
+// ParseHaproxy parses a raw haproxy.cfg into NLB properties (one per frontend-backend pair).
+// A "listen" section is treated as a frontend with its own backend.
+func ParseHaproxy(content string, hostMachineId string) ([]onpremmodel.NlbProperty, error) {
+
+	sections, err := parseHaproxySections(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply "defaults" sections to the following sections (as HAProxy does)
+	backends := map[string]*haproxySection{}
+	var frontends []*haproxySection
+	var defaults haproxySection
+	for _, section := range sections {
+		switch section.kind {
+		case "defaults":
+			defaults = *section
+			continue
+		case "frontend", "listen", "backend":
+			inheritHaproxyDefaults(section, defaults)
+		}
+
+		if section.kind == "backend" || section.kind == "listen" {
+			backends[section.name] = section
+		}
+		if section.kind == "frontend" || section.kind == "listen" {
+			frontends = append(frontends, section)
+		}
+	}
+
+	var nlbs []onpremmodel.NlbProperty
+	for _, frontend := range frontends {
+
+		// Collect the backends served by the frontend and their routing rules (L7)
+		var backendNames []string
+		if frontend.kind == "listen" {
+			backendNames = append(backendNames, frontend.name)
+		}
+		if frontend.defaultBackend != "" {
+			backendNames = append(backendNames, frontend.defaultBackend)
+		}
+		rulesByBackend := map[string][]onpremmodel.NlbRoutingRuleProperty{}
+		for _, ub := range frontend.useBackends {
+			if ub.unsatisfiable {
+				// Not to be served as a catch-all without rules
+				log.Warn().Msgf("haproxy: condition of use_backend %s in %s %s never matches (disjoint host/path conditions), ignored", ub.backend, frontend.kind, frontend.name)
+				continue
+			}
+			backendNames = append(backendNames, ub.backend)
+			if ub.backend == frontend.defaultBackend || !ub.conditional {
+				continue
+			}
+			if len(ub.rules) == 0 {
+				log.Warn().Msgf("haproxy: condition of use_backend %s in %s %s is not expressible as host/path rules, ignored", ub.backend, frontend.kind, frontend.name)
+				continue
+			}
+			rulesByBackend[ub.backend] = append(rulesByBackend[ub.backend], ub.rules...)
+		}
+		backendNames = uniqueStrings(backendNames)
+
+		if len(backendNames) == 0 {
+			log.Warn().Msgf("haproxy: frontend %s has no backend, skipped", frontend.name)
+			continue
+		}
+
+		for _, bind := range frontend.binds {
+			host, port, err := splitHostPort(bind.address)
+			if err != nil {
+				log.Warn().Err(err).Msgf("haproxy: invalid bind %q in %s %s, skipped", bind.address, frontend.kind, frontend.name)
+				continue
+			}
+			if port == 0 {
+				log.Warn().Msgf("haproxy: bind %q in %s %s has no port, skipped", bind.address, frontend.kind, frontend.name)
+				continue
+			}
+
+			for _, backendName := range backendNames {
+				backend, ok := backends[backendName]
+				if !ok {
+					log.Warn().Msgf("haproxy: backend %s used by %s %s is not defined, skipped", backendName, frontend.kind, frontend.name)
+					continue
+				}
+				if len(backend.servers) == 0 {
+					log.Warn().Msgf("haproxy: backend %s has no server, skipped", backendName)
+					continue
+				}
+
+				nlb := buildHaproxyNlb(hostMachineId, host, port, frontend, backend)
+				nlb.Listener.Tls = bind.tls
+				nlb.Rules = rulesByBackend[backendName]
+				nlbs = append(nlbs, nlb)
+			}
+		}
+	}
+
+	if len(nlbs) == 0 {
+		return nil, fmt.Errorf("no frontend-backend pair found in the haproxy configuration")
+	}
+
+	return nlbs, nil
+}
+
+// buildHaproxyNlb builds an NLB property from a listener and a backend
+func buildHaproxyNlb(hostMachineId string, host string, port int, frontend, backend *haproxySection) onpremmodel.NlbProperty {
+
+	mode := backend.mode
+	if mode == "" {
+		mode = frontend.mode
+	}
+	if mode == "" {
+		mode = "tcp" // HAProxy default mode
+	}
+
+	balance := backend.balance
+	if balance == "" {
+		balance = "roundrobin" // HAProxy default algorithm
+	}
+
+	nlb := onpremmodel.NlbProperty{
+		HostMachineId: hostMachineId,
+		Software:      SoftwareHaproxy,
+		Listener: onpremmodel.NlbListenerProperty{
+			BindAddress: host,
+			Port:        port,
+			Protocol:    "tcp", // HAProxy listeners are TCP (both for tcp and http modes)
+		},
+		Backend: onpremmodel.NlbBackendProperty{
+			Name:     backend.name,
+			Balance:  balance,
+			Protocol: mode,
+			Servers:  []onpremmodel.NlbServerProperty{},
+		},
+	}
+
+	for _, server := range backend.servers {
+		opts := mergeHaproxyServerOptions(backend.defaultServer, server.options)
+
+		ip, serverPort, err := splitHostPort(server.address)
+		if err != nil {
+			log.Warn().Err(err).Msgf("haproxy: invalid server address %q in backend %s, skipped", server.address, backend.name)
+			continue
+		}
+		if serverPort == 0 {
+			// Without a port, HAProxy uses the port the client connected to
+			serverPort = port
+		}
+
+		weight := 1 // HAProxy default weight
+		if opts.weight != "" {
+			if w, err := strconv.Atoi(opts.weight); err == nil {
+				weight = w
+			}
+		}
+
+		nlb.Backend.Servers = append(nlb.Backend.Servers, onpremmodel.NlbServerProperty{
+			Name:   server.name,
+			IP:     ip,
+			Port:   serverPort,
+			Weight: weight,
+		})
+
+		// Health check settings (the first server with "check" is representative)
+		if opts.check && !nlb.HealthCheck.Enabled {
+			nlb.HealthCheck = buildHaproxyHealthCheck(backend, opts)
+		}
+	}
+
+	return nlb
+}
+
+// buildHaproxyHealthCheck builds health check settings from "option httpchk", "timeout check", and server check options
+func buildHaproxyHealthCheck(backend *haproxySection, opts haproxyServerOptions) onpremmodel.NlbHealthCheckProperty {
+	hc := onpremmodel.NlbHealthCheckProperty{
+		Enabled:  true,
+		Protocol: "tcp",
+	}
+	if backend.httpCheck {
+		hc.Protocol = "http"
+		hc.Path = backend.httpCheckPath
+	}
+
+	// * Note: HAProxy time values without a unit are in milliseconds.
+	if opts.inter != "" {
+		if v, err := parseDurationSeconds(opts.inter, time.Millisecond); err == nil {
+			hc.Interval = v
+		}
+	} else {
+		hc.Interval = 2 // HAProxy default "inter 2s"
+	}
+	if backend.checkTimeout != "" {
+		if v, err := parseDurationSeconds(backend.checkTimeout, time.Millisecond); err == nil {
+			hc.Timeout = v
+		}
+	}
+	if opts.fall != "" {
+		if v, err := strconv.Atoi(opts.fall); err == nil {
+			hc.Threshold = v
+		}
+	} else {
+		hc.Threshold = 3 // HAProxy default "fall 3"
+	}
+	if opts.checkPort != "" {
+		if v, err := strconv.Atoi(opts.checkPort); err == nil {
+			hc.Port = v
+		}
+	}
+
+	return hc
+}
+
+// parseHaproxySections parses haproxy.cfg into sections
+func parseHaproxySections(content string) ([]*haproxySection, error) {
+	var sections []*haproxySection
+	var current *haproxySection
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := haproxyFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		keyword := strings.ToLower(fields[0])
+		switch keyword {
+		case "global", "defaults", "frontend", "backend", "listen", "resolvers", "peers", "userlist", "mailers", "program", "cache", "http-errors", "ring":
+			current = &haproxySection{kind: keyword}
+			if len(fields) > 1 {
+				current.name = fields[1]
+			}
+			if keyword == "listen" && len(fields) > 2 {
+				// Legacy syntax: listen <name> <address:port>
+				for _, addr := range strings.Split(fields[2], ",") {
+					current.binds = append(current.binds, haproxyBind{address: addr})
+				}
+			}
+			sections = append(sections, current)
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: keyword %q outside of a section", lineNo, fields[0])
+		}
+		parseHaproxyKeyword(current, keyword, fields[1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the haproxy configuration: %w", err)
+	}
+
+	// Keep only sections relevant to NLBs
+	var relevant []*haproxySection
+	for _, s := range sections {
+		switch s.kind {
+		case "defaults", "frontend", "backend", "listen":
+			relevant = append(relevant, s)
+		}
+	}
+	return relevant, nil
+}
+
+// parseHaproxyKeyword parses a keyword line in a section
+func parseHaproxyKeyword(section *haproxySection, keyword string, args []string) {
+	switch keyword {
+	case "bind":
+		if len(args) > 0 {
+			// e.g., "bind *:80,*:8080 ssl crt ..."
+			tls := false
+			for _, opt := range args[1:] {
+				if strings.EqualFold(opt, "ssl") {
+					tls = true
+				}
+			}
+			for _, addr := range strings.Split(args[0], ",") {
+				section.binds = append(section.binds, haproxyBind{address: addr, tls: tls})
+			}
+		}
+	case "mode":
+		if len(args) > 0 {
+			section.mode = strings.ToLower(args[0])
+		}
+	case "balance":
+		if len(args) > 0 {
+			section.balance = strings.ToLower(args[0])
+		}
+	case "default_backend":
+		if len(args) > 0 {
+			section.defaultBackend = args[0]
+		}
+	case "use_backend":
+		if len(args) > 0 {
+			ub := haproxyUseBackend{backend: args[0]}
+			if len(args) > 2 {
+				ub.conditional = true
+				if strings.EqualFold(args[1], "if") {
+					ub.rules, ub.unsatisfiable = section.resolveCondition(args[2:])
+				}
+			}
+			section.useBackends = append(section.useBackends, ub)
+		}
+	case "acl":
+		if len(args) > 2 {
+			if section.acls == nil {
+				section.acls = map[string][]onpremmodel.NlbRoutingRuleProperty{}
+				section.unsupportedAcl = map[string]bool{}
+			}
+			if rule, ok := parseHaproxyAclCriterion(args[1], args[2:]); ok {
+				section.acls[args[0]] = append(section.acls[args[0]], rule)
+			} else {
+				section.unsupportedAcl[args[0]] = true
+			}
+		}
+	case "option":
+		if len(args) > 0 && strings.EqualFold(args[0], "httpchk") {
+			section.httpCheck = true
+			// e.g., "option httpchk /health", "option httpchk GET /health HTTP/1.1"
+			switch {
+			case len(args) == 2:
+				section.httpCheckPath = args[1]
+			case len(args) > 2:
+				section.httpCheckPath = args[2]
+			}
+		}
+	case "http-check":
+		// e.g., "http-check send meth GET uri /health"
+		for i := 1; i+1 < len(args); i++ {
+			if strings.EqualFold(args[i], "uri") {
+				section.httpCheckPath = args[i+1]
+			}
+		}
+	case "no":
+		if len(args) > 1 && strings.EqualFold(args[0], "option") && strings.EqualFold(args[1], "httpchk") {
+			section.httpCheck = false
+			section.noHttpCheck = true
+		}
+	case "timeout":
+		if len(args) > 1 && strings.EqualFold(args[0], "check") {
+			section.checkTimeout = args[1]
+		}
+	case "default-server":
+		section.defaultServer = mergeHaproxyServerOptions(section.defaultServer, parseHaproxyServerOptions(args))
+	case "server":
+		if len(args) >= 2 {
+			section.servers = append(section.servers, haproxyServer{
+				name:    args[0],
+				address: args[1],
+				options: parseHaproxyServerOptions(args[2:]),
+			})
+		}
+	}
+}
+
+// parseHaproxyServerOptions parses options of a server or default-server line
+func parseHaproxyServerOptions(args []string) haproxyServerOptions {
+	var opts haproxyServerOptions
+	for i := 0; i < len(args); i++ {
+		next := ""
+		if i+1 < len(args) {
+			next = args[i+1]
+		}
+		switch strings.ToLower(args[i]) {
+		case "check":
+			opts.check = true
+		case "inter":
+			opts.inter = next
+			i++
+		case "fall":
+			opts.fall = next
+			i++
+		case "port":
+			opts.checkPort = next
+			i++
+		case "weight":
+			opts.weight = next
+			i++
+		}
+	}
+	return opts
+}
+
+// mergeHaproxyServerOptions overrides the base options with the non-empty options
+func mergeHaproxyServerOptions(base, override haproxyServerOptions) haproxyServerOptions {
+	merged := base
+	merged.check = base.check || override.check
+	if override.inter != "" {
+		merged.inter = override.inter
+	}
+	if override.fall != "" {
+		merged.fall = override.fall
+	}
+	if override.checkPort != "" {
+		merged.checkPort = override.checkPort
+	}
+	if override.weight != "" {
+		merged.weight = override.weight
+	}
+	return merged
+}
+
+// inheritHaproxyDefaults applies the settings of the "defaults" section not set in the section
+func inheritHaproxyDefaults(section *haproxySection, defaults haproxySection) {
+	if section.mode == "" {
+		section.mode = defaults.mode
+	}
+	if section.balance == "" {
+		section.balance = defaults.balance
+	}
+	if section.defaultBackend == "" && section.kind != "backend" {
+		section.defaultBackend = defaults.defaultBackend
+	}
+	if !section.httpCheck && !section.noHttpCheck {
+		section.httpCheck = defaults.httpCheck
+	}
+	if section.httpCheckPath == "" {
+		section.httpCheckPath = defaults.httpCheckPath
+	}
+	if section.checkTimeout == "" {
+		section.checkTimeout = defaults.checkTimeout
+	}
+	section.defaultServer = mergeHaproxyServerOptions(defaults.defaultServer, section.defaultServer)
+}
+
+// maxHaproxyRulesPerCondition limits the rules expanded from a single condition
+const maxHaproxyRulesPerCondition = 16
+
+// resolveCondition resolves a use_backend condition into routing rules (one per "||" alternative).
+// Returns nil if any part of the condition is not expressible as host/path rules
+// (e.g., negation, unsupported criteria). The alternatives that never match are dropped,
+// and unsatisfiable is set if no alternative is left.
+func (section *haproxySection) resolveCondition(tokens []string) (rules []onpremmodel.NlbRoutingRuleProperty, unsatisfiable bool) {
+	// Split into alternatives by "||" (or "or")
+	var alternatives [][]string
+	var current []string
+	for _, tok := range tokens {
+		if tok == "||" || strings.EqualFold(tok, "or") {
+			alternatives = append(alternatives, current)
+			current = nil
+			continue
+		}
+		current = append(current, tok)
+	}
+	alternatives = append(alternatives, current)
+
+	for _, terms := range alternatives {
+		// Each alternative is an AND of terms; a term is an ACL name or an anonymous ACL "{ ... }"
+		partial := []onpremmodel.NlbRoutingRuleProperty{{}}
+		for i := 0; i < len(terms); i++ {
+			var termRules []onpremmodel.NlbRoutingRuleProperty
+			switch {
+			case terms[i] == "{":
+				end := i + 1
+				for end < len(terms) && terms[end] != "}" {
+					end++
+				}
+				if end >= len(terms) || end-i < 3 {
+					return nil, false
+				}
+				rule, ok := parseHaproxyAclCriterion(terms[i+1], terms[i+2:end])
+				if !ok {
+					return nil, false
+				}
+				termRules = []onpremmodel.NlbRoutingRuleProperty{rule}
+				i = end
+			case strings.HasPrefix(terms[i], "!"):
+				return nil, false
+			default:
+				if section.unsupportedAcl[terms[i]] || len(section.acls[terms[i]]) == 0 {
+					return nil, false
+				}
+				termRules = section.acls[terms[i]]
+			}
+
+			var next []onpremmodel.NlbRoutingRuleProperty
+			for _, p := range partial {
+				for _, t := range termRules {
+					if rule, ok := andRoutingRules(p, t); ok {
+						next = append(next, rule)
+					}
+				}
+			}
+			if len(next) > maxHaproxyRulesPerCondition {
+				return nil, false
+			}
+			partial = next
+		}
+		rules = append(rules, partial...)
+	}
+
+	return rules, len(rules) == 0
+}
+
+// parseHaproxyAclCriterion parses an ACL criterion with its flags and values into a routing rule.
+// Supported criteria: path_beg, path, hdr(host), hdr_beg(host), hdr_dom(host), hdr_end(host) (and "-m beg|str|dom|end").
+func parseHaproxyAclCriterion(criterion string, args []string) (onpremmodel.NlbRoutingRuleProperty, bool) {
+	var rule onpremmodel.NlbRoutingRuleProperty
+
+	criterion = strings.TrimPrefix(strings.ToLower(criterion), "req.")
+	matchMethod := ""
+	var values []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-m" && i+1 < len(args):
+			matchMethod = args[i+1]
+			i++
+		case args[i] == "-f" || args[i] == "-M":
+			// Values in files cannot be followed
+			return rule, false
+		case strings.HasPrefix(args[i], "-"):
+			// Flags (e.g., "-i")
+		default:
+			values = append(values, args[i])
+		}
+	}
+	if len(values) == 0 {
+		return rule, false
+	}
+
+	switch {
+	case criterion == "path_beg" || (criterion == "path" && (matchMethod == "" || matchMethod == "beg" || matchMethod == "str")):
+		rule.PathPrefixes = values
+	case strings.HasSuffix(criterion, "(host)"):
+		method := strings.TrimSuffix(criterion, "(host)")
+		switch {
+		case (method == "hdr" || method == "host") && (matchMethod == "" || matchMethod == "str" || matchMethod == "dom"),
+			method == "hdr_dom":
+			rule.Hosts = values
+		case method == "hdr_end" || (method == "hdr" && matchMethod == "end"):
+			for _, v := range values {
+				rule.Hosts = append(rule.Hosts, "*."+strings.TrimPrefix(v, "."))
+			}
+		default:
+			return rule, false
+		}
+	default:
+		return rule, false
+	}
+
+	return rule, true
+}
+
+// andRoutingRules combines two routing rules with AND (conditions of the same kind are intersected).
+// It returns false if the combined rule never matches (e.g., "/api" AND "/static").
+func andRoutingRules(a, b onpremmodel.NlbRoutingRuleProperty) (onpremmodel.NlbRoutingRuleProperty, bool) {
+	hosts, ok := intersectConditions(a.Hosts, b.Hosts, hostCovers)
+	if !ok {
+		return onpremmodel.NlbRoutingRuleProperty{}, false
+	}
+	pathPrefixes, ok := intersectConditions(a.PathPrefixes, b.PathPrefixes, strings.HasPrefix)
+	if !ok {
+		return onpremmodel.NlbRoutingRuleProperty{}, false
+	}
+	return onpremmodel.NlbRoutingRuleProperty{Hosts: hosts, PathPrefixes: pathPrefixes}, true
+}
+
+// intersectConditions intersects two sets of conditions (an empty set matches all).
+// covers(narrow, wide) reports whether every request matching narrow also matches wide,
+// and the narrower one of each overlapping pair is kept (e.g., "/api" AND "/api/v1" is "/api/v1").
+// It returns false if no pair overlaps, i.e., the intersection never matches.
+func intersectConditions(a, b []string, covers func(narrow, wide string) bool) ([]string, bool) {
+	if len(a) == 0 {
+		return b, true
+	}
+	if len(b) == 0 {
+		return a, true
+	}
+	var result []string
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case covers(x, y):
+				result = append(result, x)
+			case covers(y, x):
+				result = append(result, y)
+			}
+		}
+	}
+	result = uniqueStrings(result)
+	return result, len(result) > 0
+}
+
+// hostCovers reports whether the host (or wildcard host "*.example.com") is within the other one
+func hostCovers(narrow, wide string) bool {
+	narrow, wide = strings.ToLower(narrow), strings.ToLower(wide)
+	if narrow == wide {
+		return true
+	}
+	if suffix, ok := strings.CutPrefix(wide, "*"); ok {
+		return strings.HasSuffix(narrow, suffix)
+	}
+	return false
+}
+
+// haproxyFields splits a line into fields, removing comments and handling quotes and escapes
+func haproxyFields(line string) []string {
+	var fields []string
+	var sb strings.Builder
+	inQuote := byte(0)
+	hasField := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			sb.WriteByte(line[i])
+			hasField = true
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			} else {
+				sb.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			inQuote = c
+			hasField = true
+		case c == '#':
+			i = len(line)
+		case c == ' ' || c == '\t':
+			if hasField {
+				fields = append(fields, sb.String())
+				sb.Reset()
+				hasField = false
+			}
+		default:
+			sb.WriteByte(c)
+			hasField = true
+		}
+	}
+	if hasField {
+		fields = append(fields, sb.String())
+	}
+	return fields
+}
+
+// uniqueStrings removes duplicates while keeping the order
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
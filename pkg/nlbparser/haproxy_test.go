@@ -0,0 +1,318 @@
+package nlbparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+)
+
+func TestAndRoutingRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      onpremmodel.NlbRoutingRuleProperty
+		want      onpremmodel.NlbRoutingRuleProperty
+		satisfied bool
+	}{
+		{
+			name:      "different kinds are combined",
+			a:         onpremmodel.NlbRoutingRuleProperty{Hosts: []string{"api.example.com"}},
+			b:         onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/v1"}},
+			want:      onpremmodel.NlbRoutingRuleProperty{Hosts: []string{"api.example.com"}, PathPrefixes: []string{"/v1"}},
+			satisfied: true,
+		},
+		{
+			name:      "longer path prefix is kept",
+			a:         onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/api"}},
+			b:         onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/api/v1"}},
+			want:      onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/api/v1"}},
+			satisfied: true,
+		},
+		{
+			name:      "overlapping alternatives are kept",
+			a:         onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/api", "/static"}},
+			b:         onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/api/v1", "/img"}},
+			want:      onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/api/v1"}},
+			satisfied: true,
+		},
+		{
+			name:      "wildcard host is narrowed",
+			a:         onpremmodel.NlbRoutingRuleProperty{Hosts: []string{"*.example.com"}},
+			b:         onpremmodel.NlbRoutingRuleProperty{Hosts: []string{"API.example.com"}},
+			want:      onpremmodel.NlbRoutingRuleProperty{Hosts: []string{"API.example.com"}},
+			satisfied: true,
+		},
+		{
+			name: "disjoint paths never match",
+			a:    onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/api"}},
+			b:    onpremmodel.NlbRoutingRuleProperty{PathPrefixes: []string{"/static"}},
+		},
+		{
+			name: "disjoint hosts never match",
+			a:    onpremmodel.NlbRoutingRuleProperty{Hosts: []string{"a.example.com"}, PathPrefixes: []string{"/api"}},
+			b:    onpremmodel.NlbRoutingRuleProperty{Hosts: []string{"b.example.com"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := andRoutingRules(tt.a, tt.b)
+			if ok != tt.satisfied {
+				t.Fatalf("satisfiable = %v, want %v", ok, tt.satisfied)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rule = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHaproxyUnsatisfiableCondition(t *testing.T) {
+	cfg := `
+frontend web
+    bind *:80
+    mode http
+    acl is_api path_beg /api
+    acl is_static path_beg /static
+    acl is_v1 path_beg /api/v1
+    use_backend never if is_api is_static
+    use_backend v1 if is_api is_v1
+    default_backend app
+
+backend never
+    server n1 10.0.0.1:8080
+
+backend v1
+    server v1 10.0.0.2:8080
+
+backend app
+    server a1 10.0.0.3:8080
+`
+	nlbs, err := ParseHaproxy(cfg, "lb-01")
+	if err != nil {
+		t.Fatalf("ParseHaproxy failed: %v", err)
+	}
+
+	rulesByBackend := map[string][]onpremmodel.NlbRoutingRuleProperty{}
+	for _, nlb := range nlbs {
+		rulesByBackend[nlb.Backend.Name] = nlb.Rules
+	}
+	if _, ok := rulesByBackend["never"]; ok {
+		t.Error("backend with an unsatisfiable condition should not be served (it would be a catch-all)")
+	}
+	want := []onpremmodel.NlbRoutingRuleProperty{{PathPrefixes: []string{"/api/v1"}}}
+	if !reflect.DeepEqual(rulesByBackend["v1"], want) {
+		t.Errorf("rules of v1 = %+v, want %+v", rulesByBackend["v1"], want)
+	}
+	if rules, ok := rulesByBackend["app"]; !ok || len(rules) != 0 {
+		t.Errorf("default backend should be served without rules: %+v (found: %v)", rules, ok)
+	}
+}
+
+func TestParseHaproxy(t *testing.T) {
+	cfg := `
+global
+    daemon
+
+defaults
+    mode http
+    balance leastconn
+    option httpchk GET /health
+    timeout check 5s
+    default-server inter 3s fall 2
+
+frontend web
+    bind *:80
+    bind 10.0.0.10:443 ssl crt /etc/ssl/site.pem
+    acl is_api path_beg /api
+    acl host_admin hdr(host) -i admin.example.com
+    use_backend api if is_api
+    use_backend admin if host_admin || { path_beg /admin }
+    default_backend app
+
+backend app
+    server app1 10.0.1.1:8080 check
+    server app2 10.0.1.2:8080 check weight 5
+
+backend api
+    balance roundrobin
+    timeout check 1d
+    server api1 10.0.2.1:9000 check inter 1m fall 5 port 9001
+
+backend admin
+    server adm1 10.0.3.1 check inter 500
+
+listen mysql 10.0.0.10:3306
+    mode tcp
+    balance source
+    no option httpchk
+    server db1 10.0.4.1:3306 check inter 2000
+`
+	nlbs, err := ParseHaproxy(cfg, "lb-01")
+	if err != nil {
+		t.Fatalf("ParseHaproxy failed: %v", err)
+	}
+
+	// Frontend "web" serves its default backend and the use_backend ones on each bind, and "listen" serves itself
+	byKey := map[string]onpremmodel.NlbProperty{}
+	for _, nlb := range nlbs {
+		if nlb.HostMachineId != "lb-01" || nlb.Software != SoftwareHaproxy {
+			t.Errorf("unexpected host/software: %s/%s", nlb.HostMachineId, nlb.Software)
+		}
+		byKey[fmt.Sprintf("%s:%d/%s", nlb.Listener.BindAddress, nlb.Listener.Port, nlb.Backend.Name)] = nlb
+	}
+	var keys []string
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	wantKeys := []string{"*:80/admin", "*:80/api", "*:80/app", "10.0.0.10:3306/mysql", "10.0.0.10:443/admin", "10.0.0.10:443/api", "10.0.0.10:443/app"}
+	if len(nlbs) != len(wantKeys) || !reflect.DeepEqual(keys, wantKeys) {
+		t.Fatalf("NLBs = %v (%d), want %v", keys, len(nlbs), wantKeys)
+	}
+
+	tests := []struct {
+		key         string
+		listener    onpremmodel.NlbListenerProperty
+		backend     onpremmodel.NlbBackendProperty
+		healthCheck onpremmodel.NlbHealthCheckProperty
+		rules       []onpremmodel.NlbRoutingRuleProperty
+	}{
+		{
+			// Mode, balance, httpchk, timeout check, and default-server are inherited from defaults
+			key:      "*:80/app",
+			listener: onpremmodel.NlbListenerProperty{BindAddress: "*", Port: 80, Protocol: "tcp"},
+			backend: onpremmodel.NlbBackendProperty{Name: "app", Balance: "leastconn", Protocol: "http", Servers: []onpremmodel.NlbServerProperty{
+				{Name: "app1", IP: "10.0.1.1", Port: 8080, Weight: 1},
+				{Name: "app2", IP: "10.0.1.2", Port: 8080, Weight: 5},
+			}},
+			healthCheck: onpremmodel.NlbHealthCheckProperty{Enabled: true, Protocol: "http", Path: "/health", Interval: 3, Timeout: 5, Threshold: 2},
+		},
+		{
+			// Server options and "timeout check 1d" override the defaults
+			key:      "10.0.0.10:443/api",
+			listener: onpremmodel.NlbListenerProperty{BindAddress: "10.0.0.10", Port: 443, Protocol: "tcp", Tls: true},
+			backend: onpremmodel.NlbBackendProperty{Name: "api", Balance: "roundrobin", Protocol: "http", Servers: []onpremmodel.NlbServerProperty{
+				{Name: "api1", IP: "10.0.2.1", Port: 9000, Weight: 1},
+			}},
+			healthCheck: onpremmodel.NlbHealthCheckProperty{Enabled: true, Protocol: "http", Path: "/health", Port: 9001, Interval: 60, Timeout: 86400, Threshold: 5},
+			rules:       []onpremmodel.NlbRoutingRuleProperty{{PathPrefixes: []string{"/api"}}},
+		},
+		{
+			// A server without a port uses the listener port, and "inter 500" (ms) is rounded up to 1 second
+			key:      "*:80/admin",
+			listener: onpremmodel.NlbListenerProperty{BindAddress: "*", Port: 80, Protocol: "tcp"},
+			backend: onpremmodel.NlbBackendProperty{Name: "admin", Balance: "leastconn", Protocol: "http", Servers: []onpremmodel.NlbServerProperty{
+				{Name: "adm1", IP: "10.0.3.1", Port: 80, Weight: 1},
+			}},
+			healthCheck: onpremmodel.NlbHealthCheckProperty{Enabled: true, Protocol: "http", Path: "/health", Interval: 1, Timeout: 5, Threshold: 2},
+			rules: []onpremmodel.NlbRoutingRuleProperty{
+				{Hosts: []string{"admin.example.com"}},
+				{PathPrefixes: []string{"/admin"}},
+			},
+		},
+		{
+			// A legacy "listen <name> <address>" section with its own settings
+			key:      "10.0.0.10:3306/mysql",
+			listener: onpremmodel.NlbListenerProperty{BindAddress: "10.0.0.10", Port: 3306, Protocol: "tcp"},
+			backend: onpremmodel.NlbBackendProperty{Name: "mysql", Balance: "source", Protocol: "tcp", Servers: []onpremmodel.NlbServerProperty{
+				{Name: "db1", IP: "10.0.4.1", Port: 3306, Weight: 1},
+			}},
+			healthCheck: onpremmodel.NlbHealthCheckProperty{Enabled: true, Protocol: "tcp", Interval: 2, Timeout: 5, Threshold: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			nlb := byKey[tt.key]
+			if !reflect.DeepEqual(nlb.Listener, tt.listener) {
+				t.Errorf("listener = %+v, want %+v", nlb.Listener, tt.listener)
+			}
+			if !reflect.DeepEqual(nlb.Backend, tt.backend) {
+				t.Errorf("backend = %+v, want %+v", nlb.Backend, tt.backend)
+			}
+			if !reflect.DeepEqual(nlb.HealthCheck, tt.healthCheck) {
+				t.Errorf("health check = %+v, want %+v", nlb.HealthCheck, tt.healthCheck)
+			}
+			if !reflect.DeepEqual(nlb.Rules, tt.rules) {
+				t.Errorf("rules = %+v, want %+v", nlb.Rules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestParseHaproxyDefaultsReplaced(t *testing.T) {
+	cfg := `
+defaults
+    mode http
+    balance leastconn
+
+frontend web
+    bind :8080
+    default_backend app
+
+backend app
+    server app1 10.0.1.1:8080
+
+defaults
+    timeout connect 5s
+
+listen tcp-in
+    bind :9000
+    server t1 10.0.2.1:9000
+`
+	nlbs, err := ParseHaproxy(cfg, "")
+	if err != nil {
+		t.Fatalf("ParseHaproxy failed: %v", err)
+	}
+	if len(nlbs) != 2 {
+		t.Fatalf("got %d NLBs, want 2", len(nlbs))
+	}
+
+	// A later defaults section replaces the earlier one (HAProxy defaults: mode tcp, balance roundrobin, no check)
+	if got := nlbs[0].Backend; got.Protocol != "http" || got.Balance != "leastconn" {
+		t.Errorf("backend app = %s/%s, want http/leastconn", got.Protocol, got.Balance)
+	}
+	if got := nlbs[1].Backend; got.Protocol != "tcp" || got.Balance != "roundrobin" {
+		t.Errorf("listen tcp-in = %s/%s, want tcp/roundrobin", got.Protocol, got.Balance)
+	}
+	if nlbs[1].HealthCheck.Enabled {
+		t.Errorf("health check of a server without check should be disabled: %+v", nlbs[1].HealthCheck)
+	}
+}
+
+func TestParseHaproxyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr string
+	}{
+		{
+			name:    "keyword outside of a section",
+			cfg:     "bind *:80\nfrontend web\n",
+			wantErr: `line 1: keyword "bind" outside of a section`,
+		},
+		{
+			name:    "undefined backend",
+			cfg:     "frontend web\n    bind *:80\n    default_backend app\n",
+			wantErr: "no frontend-backend pair",
+		},
+		{
+			name:    "backend without server",
+			cfg:     "frontend web\n    bind *:80\n    default_backend app\nbackend app\n    balance roundrobin\n",
+			wantErr: "no frontend-backend pair",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseHaproxy(tt.cfg, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseHaproxy() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
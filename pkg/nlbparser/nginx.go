@@ -0,0 +1,548 @@
+package nlbparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+	"github.com/rs/zerolog/log"
+)
+
+// nginxDirective is a directive (simple or block) of an nginx configuration
+type nginxDirective struct {
+	name     string
+	args     []string
+	children []*nginxDirective
+	line     int
+}
+
+// nginxUpstream is a parsed upstream block
+type nginxUpstream struct {
+	name    string
+	balance string
+	servers []onpremmodel.NlbServerProperty
+}
+
+// ParseNginx parses a raw nginx configuration into NLB properties (one per listen-upstream pair).
+// Both "stream" (L4) and "http" (L7, via location proxy_pass) contexts are supported.
+// A configuration snippet without "stream"/"http" blocks (e.g., a file in conf.d) is also accepted.
+func ParseNginx(content string, hostMachineId string) ([]onpremmodel.NlbProperty, error) {
+
+	root, err := parseNginxDirectives(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var nlbs []onpremmodel.NlbProperty
+
+	// Top-level snippet (e.g., a file included into the http or stream context)
+	nlbs = append(nlbs, collectNginxNlbs(root, "", hostMachineId)...)
+
+	for _, d := range root {
+		switch d.name {
+		case "stream", "http":
+			nlbs = append(nlbs, collectNginxNlbs(d.children, d.name, hostMachineId)...)
+		}
+	}
+
+	if len(nlbs) == 0 {
+		return nil, fmt.Errorf("no listen-upstream pair found in the nginx configuration")
+	}
+
+	return nlbs, nil
+}
+
+// collectNginxNlbs builds NLB properties from the server and upstream blocks in a context.
+// The context is "stream", "http", or "" (unknown; decided by the server block contents).
+func collectNginxNlbs(directives []*nginxDirective, context string, hostMachineId string) []onpremmodel.NlbProperty {
+
+	upstreams := map[string]nginxUpstream{}
+	for _, d := range directives {
+		if d.name == "upstream" && len(d.args) > 0 {
+			upstreams[d.args[0]] = parseNginxUpstream(d)
+		}
+	}
+
+	var nlbs []onpremmodel.NlbProperty
+	for _, server := range directives {
+		if server.name != "server" || server.children == nil {
+			continue
+		}
+
+		serverContext := context
+		if serverContext == "" {
+			serverContext = "stream"
+			if len(findNginxDirectives(server.children, "location")) > 0 {
+				serverContext = "http"
+			}
+		}
+
+		// Collect proxy_pass targets (in the server block for stream, in location blocks for http)
+		// and the routing rules selecting them (http only; server_name and location prefix)
+		var targets []string
+		healthCheckByTarget := map[string]*nginxDirective{}
+		rulesByTarget := map[string][]onpremmodel.NlbRoutingRuleProperty{}
+		defaultTargets := map[string]bool{}
+		if serverContext == "stream" {
+			for _, d := range findNginxDirectives(server.children, "proxy_pass") {
+				targets = append(targets, d.args...)
+			}
+			if healthChecks := findNginxDirectives(server.children, "health_check"); len(healthChecks) > 0 {
+				for _, target := range targets {
+					healthCheckByTarget[target] = healthChecks[0]
+				}
+			}
+		} else {
+			hosts := nginxServerNames(server)
+			for _, location := range findNginxDirectives(server.children, "location") {
+				pathPrefix, ok := nginxLocationPrefix(location.args)
+				healthChecks := findNginxDirectivesRecursive(location.children, "health_check")
+				for _, d := range findNginxDirectivesRecursive(location.children, "proxy_pass") {
+					for _, target := range d.args {
+						targets = append(targets, target)
+						if _, exists := healthCheckByTarget[target]; !exists && len(healthChecks) > 0 {
+							healthCheckByTarget[target] = healthChecks[0]
+						}
+						switch {
+						case !ok:
+							log.Warn().Msgf("nginx: location %q (line %d) is not expressible as a path prefix rule, ignored", strings.Join(location.args, " "), location.line)
+						case pathPrefix == "/" && len(hosts) == 0:
+							defaultTargets[target] = true
+						default:
+							rule := onpremmodel.NlbRoutingRuleProperty{Hosts: hosts}
+							if pathPrefix != "/" {
+								rule.PathPrefixes = []string{pathPrefix}
+							}
+							rulesByTarget[target] = append(rulesByTarget[target], rule)
+						}
+					}
+				}
+			}
+		}
+		targets = uniqueStrings(targets)
+
+		if len(targets) == 0 {
+			continue
+		}
+
+		listened := map[string]bool{}
+		for _, listen := range findNginxDirectives(server.children, "listen") {
+			if len(listen.args) == 0 || strings.HasPrefix(listen.args[0], "unix:") {
+				continue
+			}
+			host, port, err := splitHostPort(listen.args[0])
+			if err != nil || port == 0 {
+				log.Warn().Msgf("nginx: invalid listen %q (line %d), skipped", listen.args[0], listen.line)
+				continue
+			}
+
+			listenerProtocol := "tcp"
+			tls := false
+			for _, arg := range listen.args[1:] {
+				switch arg {
+				case "udp":
+					listenerProtocol = "udp"
+				case "ssl":
+					tls = true
+				}
+			}
+
+			// Dual-stack listens (e.g., "listen 80;" and "listen [::]:80;") are the same listener
+			listenKey := fmt.Sprintf("%s/%d/%s", host, port, listenerProtocol)
+			if listened[listenKey] {
+				continue
+			}
+			listened[listenKey] = true
+
+			for _, target := range targets {
+				backend, ok := resolveNginxTarget(target, serverContext, listenerProtocol, upstreams)
+				if !ok {
+					log.Warn().Msgf("nginx: proxy_pass %q is not resolvable (line %d), skipped", target, listen.line)
+					continue
+				}
+
+				nlb := onpremmodel.NlbProperty{
+					HostMachineId: hostMachineId,
+					Software:      SoftwareNginx,
+					Listener: onpremmodel.NlbListenerProperty{
+						BindAddress: host,
+						Port:        port,
+						Protocol:    listenerProtocol,
+						Tls:         tls,
+					},
+					Backend: backend,
+				}
+				if !defaultTargets[target] {
+					nlb.Rules = rulesByTarget[target]
+				}
+				if hc, ok := healthCheckByTarget[target]; ok {
+					nlb.HealthCheck = buildNginxHealthCheck(hc, backend.Protocol)
+				}
+
+				nlbs = append(nlbs, nlb)
+			}
+		}
+	}
+
+	return nlbs
+}
+
+// resolveNginxTarget resolves a proxy_pass target into a backend (an upstream or a single server address)
+func resolveNginxTarget(target, context, listenerProtocol string, upstreams map[string]nginxUpstream) (onpremmodel.NlbBackendProperty, bool) {
+
+	protocol := listenerProtocol
+	if context == "http" {
+		protocol = "http"
+	}
+
+	// Remove the scheme and the URI (e.g., "http://backend/api/" -> "backend")
+	name := target
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	if i := strings.Index(name, "/"); i >= 0 {
+		name = name[:i]
+	}
+
+	// Variables (e.g., "$upstream") are resolved at runtime and cannot be followed
+	if name == "" || strings.Contains(name, "$") {
+		return onpremmodel.NlbBackendProperty{}, false
+	}
+
+	if upstream, ok := upstreams[name]; ok {
+		if len(upstream.servers) == 0 {
+			return onpremmodel.NlbBackendProperty{}, false
+		}
+		return onpremmodel.NlbBackendProperty{
+			Name:     upstream.name,
+			Balance:  upstream.balance,
+			Protocol: protocol,
+			Servers:  upstream.servers,
+		}, true
+	}
+
+	// A single server address (e.g., "proxy_pass 10.0.0.1:8080;")
+	ip, port, err := splitHostPort(name)
+	if err != nil || port == 0 {
+		if err == nil && context == "http" {
+			// Default port of the scheme
+			port = 80
+			if strings.HasPrefix(target, "https://") {
+				port = 443
+			}
+		} else {
+			return onpremmodel.NlbBackendProperty{}, false
+		}
+	}
+
+	return onpremmodel.NlbBackendProperty{
+		Name:     name,
+		Balance:  "roundrobin",
+		Protocol: protocol,
+		Servers: []onpremmodel.NlbServerProperty{
+			{Name: name, IP: ip, Port: port, Weight: 1},
+		},
+	}, true
+}
+
+// parseNginxUpstream parses an upstream block
+func parseNginxUpstream(d *nginxDirective) nginxUpstream {
+	upstream := nginxUpstream{
+		name:    d.args[0],
+		balance: "roundrobin", // nginx default algorithm
+		servers: []onpremmodel.NlbServerProperty{},
+	}
+
+	for _, child := range d.children {
+		switch child.name {
+		case "least_conn":
+			upstream.balance = "leastconn"
+		case "ip_hash":
+			upstream.balance = "source"
+		case "hash":
+			upstream.balance = "hash"
+		case "random":
+			upstream.balance = "random"
+		case "least_time":
+			upstream.balance = "leasttime"
+		case "server":
+			if len(child.args) == 0 {
+				continue
+			}
+
+			weight := 1 // nginx default weight
+			down := false
+			for _, param := range child.args[1:] {
+				switch {
+				case strings.HasPrefix(param, "weight="):
+					if w, err := strconv.Atoi(strings.TrimPrefix(param, "weight=")); err == nil {
+						weight = w
+					}
+				case param == "down":
+					down = true
+				}
+			}
+			if down {
+				log.Debug().Msgf("nginx: server %s in upstream %s is marked down, skipped", child.args[0], upstream.name)
+				continue
+			}
+
+			ip, port, err := splitHostPort(child.args[0])
+			if err != nil || strings.HasPrefix(child.args[0], "unix:") {
+				log.Warn().Msgf("nginx: invalid server %q in upstream %s (line %d), skipped", child.args[0], upstream.name, child.line)
+				continue
+			}
+			if port == 0 {
+				port = 80 // nginx default server port
+			}
+
+			upstream.servers = append(upstream.servers, onpremmodel.NlbServerProperty{
+				Name:   child.args[0],
+				IP:     ip,
+				Port:   port,
+				Weight: weight,
+			})
+		}
+	}
+
+	return upstream
+}
+
+// buildNginxHealthCheck builds health check settings from a "health_check" directive (NGINX Plus)
+// e.g., "health_check interval=5s fails=3 passes=2 port=8080 uri=/healthz;"
+func buildNginxHealthCheck(d *nginxDirective, backendProtocol string) onpremmodel.NlbHealthCheckProperty {
+	hc := onpremmodel.NlbHealthCheckProperty{
+		Enabled:   true,
+		Protocol:  "tcp",
+		Interval:  5, // nginx default "interval=5s"
+		Threshold: 1, // nginx default "fails=1"
+	}
+	if backendProtocol == "http" {
+		hc.Protocol = "http"
+	}
+
+	for _, param := range d.args {
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			if param == "udp" {
+				hc.Protocol = "udp"
+			}
+			continue
+		}
+		switch key {
+		case "interval":
+			// * Note: nginx time values without a unit are in seconds.
+			if v, err := parseDurationSeconds(value, time.Second); err == nil {
+				hc.Interval = v
+			}
+		case "fails":
+			if v, err := strconv.Atoi(value); err == nil {
+				hc.Threshold = v
+			}
+		case "port":
+			if v, err := strconv.Atoi(value); err == nil {
+				hc.Port = v
+			}
+		case "uri":
+			hc.Protocol = "http"
+			hc.Path = value
+		}
+	}
+
+	return hc
+}
+
+// nginxServerNames returns the host names of a server block ("server_name"), excluding
+// catch-all ("_", "") and regular expression names.
+func nginxServerNames(server *nginxDirective) []string {
+	var hosts []string
+	for _, d := range findNginxDirectives(server.children, "server_name") {
+		for _, name := range d.args {
+			switch {
+			case name == "_" || name == "" || name == `""`:
+			case strings.HasPrefix(name, "~"):
+				log.Warn().Msgf("nginx: regular expression server_name %q (line %d) is not expressible as a host rule, ignored", name, d.line)
+			default:
+				hosts = append(hosts, name)
+			}
+		}
+	}
+	return hosts
+}
+
+// nginxLocationPrefix returns the path prefix of a location block.
+// Returns false for regular expression and named locations.
+func nginxLocationPrefix(args []string) (string, bool) {
+	switch {
+	case len(args) == 1 && !strings.HasPrefix(args[0], "@") && !strings.HasPrefix(args[0], "~"):
+		return args[0], true
+	case len(args) == 2 && (args[0] == "=" || args[0] == "^~"):
+		// * Note: an exact match ("=") is approximated as a prefix.
+		return args[1], true
+	default:
+		return "", false
+	}
+}
+
+// findNginxDirectives returns the directives with the name (non-recursive)
+func findNginxDirectives(directives []*nginxDirective, name string) []*nginxDirective {
+	var found []*nginxDirective
+	for _, d := range directives {
+		if d.name == name {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+// findNginxDirectivesRecursive returns the directives with the name, including nested blocks (e.g., nested locations)
+func findNginxDirectivesRecursive(directives []*nginxDirective, name string) []*nginxDirective {
+	var found []*nginxDirective
+	for _, d := range directives {
+		if d.name == name {
+			found = append(found, d)
+		}
+		found = append(found, findNginxDirectivesRecursive(d.children, name)...)
+	}
+	return found
+}
+
+/*
+ * nginx configuration tokenizer and parser
+ */
+
+type nginxToken struct {
+	value  string
+	quoted bool
+	line   int
+}
+
+// parseNginxDirectives parses an nginx configuration into a directive tree
+func parseNginxDirectives(content string) ([]*nginxDirective, error) {
+	tokens, err := tokenizeNginx(content)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := 0
+	directives, err := parseNginxBlock(tokens, &pos, false)
+	if err != nil {
+		return nil, err
+	}
+	return directives, nil
+}
+
+func parseNginxBlock(tokens []nginxToken, pos *int, inBlock bool) ([]*nginxDirective, error) {
+	directives := []*nginxDirective{}
+	var current *nginxDirective
+
+	for *pos < len(tokens) {
+		tok := tokens[*pos]
+		*pos++
+
+		if !tok.quoted {
+			switch tok.value {
+			case ";":
+				if current == nil {
+					return nil, fmt.Errorf("line %d: unexpected \";\"", tok.line)
+				}
+				directives = append(directives, current)
+				current = nil
+				continue
+			case "{":
+				if current == nil {
+					return nil, fmt.Errorf("line %d: unexpected \"{\"", tok.line)
+				}
+				children, err := parseNginxBlock(tokens, pos, true)
+				if err != nil {
+					return nil, err
+				}
+				current.children = children
+				directives = append(directives, current)
+				current = nil
+				continue
+			case "}":
+				if !inBlock || current != nil {
+					return nil, fmt.Errorf("line %d: unexpected \"}\"", tok.line)
+				}
+				return directives, nil
+			}
+		}
+
+		if current == nil {
+			current = &nginxDirective{name: tok.value, line: tok.line}
+		} else {
+			current.args = append(current.args, tok.value)
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("line %d: directive %q is not terminated by \";\"", current.line, current.name)
+	}
+	if inBlock {
+		return nil, fmt.Errorf("unexpected end of file, expecting \"}\"")
+	}
+	return directives, nil
+}
+
+// tokenizeNginx splits an nginx configuration into tokens, removing comments and handling quotes
+func tokenizeNginx(content string) ([]nginxToken, error) {
+	var tokens []nginxToken
+	var sb strings.Builder
+	line := 1
+	hasToken := false
+
+	flush := func() {
+		if hasToken {
+			tokens = append(tokens, nginxToken{value: sb.String(), line: line})
+			sb.Reset()
+			hasToken = false
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '\n':
+			flush()
+			line++
+		case ' ', '\t', '\r':
+			flush()
+		case '#':
+			flush()
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			i--
+		case ';', '{', '}':
+			flush()
+			tokens = append(tokens, nginxToken{value: string(c), line: line})
+		case '"', '\'':
+			flush()
+			startLine := line
+			var qb strings.Builder
+			i++
+			for ; i < len(content) && content[i] != c; i++ {
+				if content[i] == '\\' && i+1 < len(content) {
+					i++
+				}
+				if content[i] == '\n' {
+					line++
+				}
+				qb.WriteByte(content[i])
+			}
+			if i >= len(content) {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", startLine)
+			}
+			tokens = append(tokens, nginxToken{value: qb.String(), quoted: true, line: startLine})
+		default:
+			sb.WriteByte(c)
+			hasToken = true
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
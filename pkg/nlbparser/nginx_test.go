@@ -0,0 +1,219 @@
+package nlbparser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+)
+
+func TestParseNginx(t *testing.T) {
+	cfg := `
+user nginx;
+
+stream {
+    upstream db {
+        least_conn;
+        server 10.0.4.1:3306 weight=3;
+        server 10.0.4.2:3306 down;
+        server 10.0.4.3:3306;
+    }
+
+    server {
+        listen 3306;
+        listen [::]:3306;   # dual-stack, the same listener
+        proxy_pass db;
+        health_check interval=1d fails=2 port=3307;
+    }
+
+    server {
+        listen 53 udp;
+        proxy_pass 10.0.5.1:53;
+    }
+}
+
+http {
+    upstream app {
+        ip_hash;
+        server 10.0.1.1:8080;
+        server 10.0.1.2;
+    }
+    upstream api {
+        server 10.0.2.1:9000;
+    }
+
+    server {
+        listen 80;
+        server_name _;
+        location / {
+            proxy_pass http://app;
+        }
+        location /api/ {
+            proxy_pass http://api/;
+            health_check uri=/healthz interval=10;
+        }
+    }
+
+    server {
+        listen 443 ssl;
+        server_name "admin.example.com";
+        location / {
+            proxy_pass http://app;
+        }
+    }
+}
+`
+	nlbs, err := ParseNginx(cfg, "lb-02")
+	if err != nil {
+		t.Fatalf("ParseNginx failed: %v", err)
+	}
+
+	appServers := []onpremmodel.NlbServerProperty{
+		{Name: "10.0.1.1:8080", IP: "10.0.1.1", Port: 8080, Weight: 1},
+		{Name: "10.0.1.2", IP: "10.0.1.2", Port: 80, Weight: 1},
+	}
+	want := []onpremmodel.NlbProperty{
+		{
+			// Stream upstream with a weight, a server marked down, and a health check of 1 day
+			HostMachineId: "lb-02",
+			Software:      SoftwareNginx,
+			Listener:      onpremmodel.NlbListenerProperty{BindAddress: "*", Port: 3306, Protocol: "tcp"},
+			Backend: onpremmodel.NlbBackendProperty{Name: "db", Balance: "leastconn", Protocol: "tcp", Servers: []onpremmodel.NlbServerProperty{
+				{Name: "10.0.4.1:3306", IP: "10.0.4.1", Port: 3306, Weight: 3},
+				{Name: "10.0.4.3:3306", IP: "10.0.4.3", Port: 3306, Weight: 1},
+			}},
+			HealthCheck: onpremmodel.NlbHealthCheckProperty{Enabled: true, Protocol: "tcp", Port: 3307, Interval: 86400, Threshold: 2},
+		},
+		{
+			// Stream proxy_pass to a single address over UDP
+			HostMachineId: "lb-02",
+			Software:      SoftwareNginx,
+			Listener:      onpremmodel.NlbListenerProperty{BindAddress: "*", Port: 53, Protocol: "udp"},
+			Backend: onpremmodel.NlbBackendProperty{Name: "10.0.5.1:53", Balance: "roundrobin", Protocol: "udp", Servers: []onpremmodel.NlbServerProperty{
+				{Name: "10.0.5.1:53", IP: "10.0.5.1", Port: 53, Weight: 1},
+			}},
+		},
+		{
+			// "location /" with a catch-all server_name is the default backend
+			HostMachineId: "lb-02",
+			Software:      SoftwareNginx,
+			Listener:      onpremmodel.NlbListenerProperty{BindAddress: "*", Port: 80, Protocol: "tcp"},
+			Backend:       onpremmodel.NlbBackendProperty{Name: "app", Balance: "source", Protocol: "http", Servers: appServers},
+		},
+		{
+			HostMachineId: "lb-02",
+			Software:      SoftwareNginx,
+			Listener:      onpremmodel.NlbListenerProperty{BindAddress: "*", Port: 80, Protocol: "tcp"},
+			Backend: onpremmodel.NlbBackendProperty{Name: "api", Balance: "roundrobin", Protocol: "http", Servers: []onpremmodel.NlbServerProperty{
+				{Name: "10.0.2.1:9000", IP: "10.0.2.1", Port: 9000, Weight: 1},
+			}},
+			HealthCheck: onpremmodel.NlbHealthCheckProperty{Enabled: true, Protocol: "http", Path: "/healthz", Interval: 10, Threshold: 1},
+			Rules:       []onpremmodel.NlbRoutingRuleProperty{{PathPrefixes: []string{"/api/"}}},
+		},
+		{
+			// server_name makes a host rule even for "location /"
+			HostMachineId: "lb-02",
+			Software:      SoftwareNginx,
+			Listener:      onpremmodel.NlbListenerProperty{BindAddress: "*", Port: 443, Protocol: "tcp", Tls: true},
+			Backend:       onpremmodel.NlbBackendProperty{Name: "app", Balance: "source", Protocol: "http", Servers: appServers},
+			Rules:         []onpremmodel.NlbRoutingRuleProperty{{Hosts: []string{"admin.example.com"}}},
+		},
+	}
+
+	if len(nlbs) != len(want) {
+		t.Fatalf("got %d NLBs, want %d: %+v", len(nlbs), len(want), nlbs)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(nlbs[i], want[i]) {
+			t.Errorf("nlbs[%d] = %+v, want %+v", i, nlbs[i], want[i])
+		}
+	}
+}
+
+func TestParseNginxSnippet(t *testing.T) {
+	// A file in conf.d (included into the http context) without "stream"/"http" blocks
+	cfg := `
+upstream web {
+    server 10.0.1.1:8080;
+}
+server {
+    listen 10.0.0.10:8080;
+    server_name www.example.com *.example.org;
+    location ^~ /static {
+        proxy_pass http://web;
+    }
+    location /legacy {
+        proxy_pass http://10.0.9.9;
+    }
+}
+server {
+    listen 9000;
+    proxy_pass web;
+}
+`
+	nlbs, err := ParseNginx(cfg, "")
+	if err != nil {
+		t.Fatalf("ParseNginx failed: %v", err)
+	}
+	if len(nlbs) != 3 {
+		t.Fatalf("got %d NLBs, want 3: %+v", len(nlbs), nlbs)
+	}
+
+	// The server block with locations is an http server
+	if got := nlbs[0]; got.Backend.Name != "web" || got.Backend.Protocol != "http" ||
+		!reflect.DeepEqual(got.Rules, []onpremmodel.NlbRoutingRuleProperty{{Hosts: []string{"www.example.com", "*.example.org"}, PathPrefixes: []string{"/static"}}}) {
+		t.Errorf("http NLB = %+v", got)
+	}
+	if got := nlbs[0].Listener; got.BindAddress != "10.0.0.10" || got.Port != 8080 {
+		t.Errorf("listener = %+v, want 10.0.0.10:8080", got)
+	}
+
+	// A proxy_pass without a port uses the default port of the scheme
+	if got := nlbs[1].Backend.Servers; len(got) != 1 || got[0].IP != "10.0.9.9" || got[0].Port != 80 {
+		t.Errorf("servers of /legacy = %+v, want 10.0.9.9:80", got)
+	}
+
+	// The server block without locations is a stream server
+	if got := nlbs[2]; got.Backend.Name != "web" || got.Backend.Protocol != "tcp" || got.Listener.Port != 9000 || len(got.Rules) != 0 {
+		t.Errorf("stream NLB = %+v", got)
+	}
+}
+
+func TestParseNginxErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr string
+	}{
+		{
+			name:    "unterminated directive",
+			cfg:     "stream {\n    server {\n        listen 80\n    }\n}",
+			wantErr: `line 4: unexpected "}"`,
+		},
+		{
+			name:    "unclosed block",
+			cfg:     "stream {\n    server {\n        listen 80;\n    }\n",
+			wantErr: "unexpected end of file",
+		},
+		{
+			name:    "unterminated quoted string",
+			cfg:     "http {\n    server_name \"example.com;\n}\n",
+			wantErr: "line 2: unterminated quoted string",
+		},
+		{
+			name:    "variable proxy_pass only",
+			cfg:     "stream {\n    server {\n        listen 80;\n        proxy_pass $backend;\n    }\n}\n",
+			wantErr: "no listen-upstream pair",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNginx(tt.cfg, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseNginx() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Package nlbparser provides parsers that convert raw load balancer configurations
+// (e.g., haproxy.cfg, nginx stream/upstream blocks) into the on-premise NLB model.
+package nlbparser
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+)
+
+// Supported load balancer software
+const (
+	SoftwareHaproxy = "haproxy"
+	SoftwareNginx   = "nginx"
+)
+
+// NlbConfig is a raw load balancer configuration collected from a source node
+type NlbConfig struct {
+	Software      string `json:"software" example:"haproxy"`                      // "haproxy" | "nginx"
+	HostMachineId string `json:"hostMachineId,omitempty" example:"machine-01"`    // MachineId of the node running the load balancer
+	Content       string `json:"content" example:"frontend fe\n  bind *:80\n..."` // Raw configuration (e.g., haproxy.cfg, nginx.conf)
+}
+
+// Parse parses a raw load balancer configuration of the given software into NLB properties
+func Parse(config NlbConfig) ([]onpremmodel.NlbProperty, error) {
+	switch strings.ToLower(strings.TrimSpace(config.Software)) {
+	case SoftwareHaproxy:
+		return ParseHaproxy(config.Content, config.HostMachineId)
+	case SoftwareNginx:
+		return ParseNginx(config.Content, config.HostMachineId)
+	default:
+		return nil, fmt.Errorf("unsupported load balancer software: %q (supported: %s, %s)", config.Software, SoftwareHaproxy, SoftwareNginx)
+	}
+}
+
+// ParseAll parses multiple raw load balancer configurations into NLB properties
+func ParseAll(configs []NlbConfig) ([]onpremmodel.NlbProperty, error) {
+	var nlbs []onpremmodel.NlbProperty
+	for i, config := range configs {
+		parsed, err := Parse(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse nlbConfigs[%d] (software: %s, host: %s): %w", i, config.Software, config.HostMachineId, err)
+		}
+		nlbs = append(nlbs, parsed...)
+	}
+	return nlbs, nil
+}
+
+// ToSourceNlbModel converts NLB properties to the source NLB model (as received from cm-honeybee)
+func ToSourceNlbModel(nlbs []onpremmodel.NlbProperty) onpremmodel.SourceNlbModel {
+	model := onpremmodel.SourceNlbModel{SourceNlbModel: []onpremmodel.SourceNlb{}}
+	for _, nlb := range nlbs {
+		model.SourceNlbModel = append(model.SourceNlbModel, onpremmodel.SourceNlb{
+			Software:    nlb.Software,
+			Listener:    nlb.Listener,
+			Backend:     nlb.Backend,
+			HealthCheck: nlb.HealthCheck,
+			Rules:       nlb.Rules,
+		})
+	}
+	return model
+}
+
+/*
+ * Common helpers
+ */
+
+// splitHostPort splits an address (e.g., "10.0.0.1:80", "*:80", ":80", "[::]:80", "80", "ipv4@:80")
+// into host and port. The host is "*" for all interfaces; the port is 0 if not specified.
+func splitHostPort(addr string) (string, int, error) {
+	// Remove the address family prefix (e.g., "ipv4@", "ipv6@")
+	if i := strings.Index(addr, "@"); i >= 0 {
+		addr = addr[i+1:]
+	}
+
+	// Port only (e.g., nginx "listen 80")
+	if port, err := strconv.Atoi(addr); err == nil {
+		return "*", port, validatePort(port)
+	}
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		// Host only (e.g., server address without port)
+		return strings.Trim(addr, "[]"), 0, nil
+	}
+
+	// Port range (e.g., "8000-8010") is not supported in the NLB model; use the first port
+	portStr = strings.SplitN(portStr, "-", 2)[0]
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in address %q", addr)
+	}
+
+	switch host {
+	case "", "*", "0.0.0.0", "::":
+		host = "*"
+	}
+	return host, port, validatePort(port)
+}
+
+func validatePort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port out of range: %d", port)
+	}
+	return nil
+}
+
+// durationUnits are the time units of HAProxy and nginx values (time.ParseDuration does not support days and longer)
+var durationUnits = map[string]time.Duration{
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,   // nginx
+	"M":  30 * 24 * time.Hour,  // nginx
+	"y":  365 * 24 * time.Hour, // nginx
+}
+
+// parseDurationSeconds parses a duration (e.g., "2s", "500ms", "1m", "1d", "1h 30m", "2000" in the default unit) into seconds.
+// The result is rounded up to at least 1 second.
+func parseDurationSeconds(value string, defaultUnit time.Duration) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var d time.Duration
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		d = time.Duration(n * float64(defaultUnit))
+	} else {
+		// A sequence of numbers with units (e.g., "1h30m", "1h 30m" in nginx)
+		rest := strings.Join(strings.Fields(value), "")
+		for rest != "" {
+			numEnd := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+			if numEnd <= 0 {
+				return 0, fmt.Errorf("invalid duration: %q", value)
+			}
+			unitEnd := strings.IndexFunc(rest[numEnd:], func(r rune) bool { return r >= '0' && r <= '9' })
+			if unitEnd < 0 {
+				unitEnd = len(rest) - numEnd
+			}
+			n, err := strconv.ParseFloat(rest[:numEnd], 64)
+			unit, ok := durationUnits[rest[numEnd:numEnd+unitEnd]]
+			if err != nil || !ok {
+				return 0, fmt.Errorf("invalid duration: %q", value)
+			}
+			d += time.Duration(n * float64(unit))
+			rest = rest[numEnd+unitEnd:]
+		}
+	}
+
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds, nil
+}
@@ -0,0 +1,86 @@
+package nlbparser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDurationSeconds(t *testing.T) {
+	tests := []struct {
+		value       string
+		defaultUnit time.Duration
+		want        int
+		wantErr     bool
+	}{
+		{value: "2000", defaultUnit: time.Millisecond, want: 2},
+		{value: "30", defaultUnit: time.Second, want: 30},
+		{value: "500ms", defaultUnit: time.Second, want: 1},
+		{value: "1.5s", defaultUnit: time.Millisecond, want: 2},
+		{value: "100us", defaultUnit: time.Millisecond, want: 1},
+		{value: "1m", defaultUnit: time.Millisecond, want: 60},
+		{value: "2h", defaultUnit: time.Millisecond, want: 7200},
+		{value: "1d", defaultUnit: time.Millisecond, want: 86400},
+		{value: "1w", defaultUnit: time.Second, want: 604800},
+		{value: "1h30m", defaultUnit: time.Second, want: 5400},
+		{value: "1h 30s", defaultUnit: time.Second, want: 3630},
+		{value: "", defaultUnit: time.Second, wantErr: true},
+		{value: "abc", defaultUnit: time.Second, wantErr: true},
+		{value: "10x", defaultUnit: time.Second, wantErr: true},
+		{value: "s", defaultUnit: time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDurationSeconds(tt.value, tt.defaultUnit)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseDurationSeconds(%q) = %d, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDurationSeconds(%q) failed: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseDurationSeconds(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitHostPort(t *testing.T) {
+	tests := []struct {
+		addr     string
+		wantHost string
+		wantPort int
+		wantErr  bool
+	}{
+		{addr: "10.0.0.1:80", wantHost: "10.0.0.1", wantPort: 80},
+		{addr: "*:80", wantHost: "*", wantPort: 80},
+		{addr: ":8080", wantHost: "*", wantPort: 8080},
+		{addr: "[::]:443", wantHost: "*", wantPort: 443},
+		{addr: "ipv4@0.0.0.0:80", wantHost: "*", wantPort: 80},
+		{addr: "80", wantHost: "*", wantPort: 80},
+		{addr: "10.0.0.1:8000-8010", wantHost: "10.0.0.1", wantPort: 8000},
+		{addr: "10.0.0.1", wantHost: "10.0.0.1", wantPort: 0},
+		{addr: "10.0.0.1:70000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			host, port, err := splitHostPort(tt.addr)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("splitHostPort(%q) = %s, %d, want error", tt.addr, host, port)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitHostPort(%q) failed: %v", tt.addr, err)
+			}
+			if host != tt.wantHost || port != tt.wantPort {
+				t.Errorf("splitHostPort(%q) = %s, %d, want %s, %d", tt.addr, host, port, tt.wantHost, tt.wantPort)
+			}
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package similarity
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudmodel "github.com/cloud-barista/cm-beetle/imdl/cloud-model"
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+)
+
+/*
+OS identity matching methods.
+Methods for parsing OS information into a structured identity (distro family, version, LTS/EOL, edition)
+and grading how compatible a target OS image is with a source OS.
+*/
+
+// OsIdentity is a structured OS identity parsed from OS information text
+type OsIdentity struct {
+	Family  string `json:"family"`            // Normalized distro family (e.g., ubuntu, debian, rhel, rocky, amazonlinux, windows)
+	Lineage string `json:"lineage,omitempty"` // Distro lineage (e.g., debian, rhel, suse)
+	Major   int    `json:"major"`             // Major version (0 if unknown)
+	Minor   int    `json:"minor"`             // Minor version (-1 if unknown)
+	Lts     bool   `json:"lts"`               // Long-term support release
+	Eol     bool   `json:"eol"`               // End of (standard) life reached
+	Edition string `json:"edition,omitempty"` // Edition (e.g., minimal, pro, stream)
+}
+
+// Version returns the version string of the OS identity (e.g., "22.04", "9")
+func (o OsIdentity) Version() string {
+	if o.Major == 0 {
+		return ""
+	}
+	if o.Minor < 0 {
+		return strconv.Itoa(o.Major)
+	}
+	if o.Family == "ubuntu" {
+		return fmt.Sprintf("%d.%02d", o.Major, o.Minor)
+	}
+	return fmt.Sprintf("%d.%d", o.Major, o.Minor)
+}
+
+// String returns a human-readable OS identity (e.g., "ubuntu 22.04 lts")
+func (o OsIdentity) String() string {
+	parts := []string{o.Family}
+	if v := o.Version(); v != "" {
+		parts = append(parts, v)
+	}
+	if o.Edition != "" {
+		parts = append(parts, o.Edition)
+	}
+	if o.Lts {
+		parts = append(parts, "lts")
+	}
+	if o.Eol {
+		parts = append(parts, "eol")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Known returns true if the OS family is identified
+func (o OsIdentity) Known() bool {
+	return o.Family != ""
+}
+
+// OsMatchGrade is a grade of OS compatibility between a source OS and a target OS image
+type OsMatchGrade string
+
+const (
+	OsMatchExact       OsMatchGrade = "exact"        // Same family and version
+	OsMatchSameMajor   OsMatchGrade = "same-major"   // Same family and major version
+	OsMatchNewerLts    OsMatchGrade = "newer-lts"    // Same family, newer LTS version
+	OsMatchSameFamily  OsMatchGrade = "same-family"  // Same family, other version
+	OsMatchCrossFamily OsMatchGrade = "cross-family" // Other family in the same lineage (e.g., centos -> rocky)
+	OsMatchMismatch    OsMatchGrade = "mismatch"     // Unrelated OS
+	OsMatchUnknown     OsMatchGrade = "unknown"      // OS identity could not be parsed
+)
+
+// OsMatch is the result of OS identity comparison
+type OsMatch struct {
+	Grade  OsMatchGrade `json:"grade"`
+	Score  float64      `json:"score"` // 0.0 to 1.0
+	Source OsIdentity   `json:"source"`
+	Target OsIdentity   `json:"target"`
+}
+
+// Base scores per grade
+var osMatchGradeScores = map[OsMatchGrade]float64{
+	OsMatchExact:       1.0,
+	OsMatchSameMajor:   0.9,
+	OsMatchNewerLts:    0.75,
+	OsMatchSameFamily:  0.5,
+	OsMatchCrossFamily: 0.4,
+	OsMatchMismatch:    0.0,
+	OsMatchUnknown:     0.0,
+}
+
+// Predefined phrases normalized to a single token before parsing
+var osPhraseReplacer = strings.NewReplacer(
+	"red hat enterprise linux", "rhel",
+	"redhat enterprise linux", "rhel",
+	"red hat", "rhel",
+	"redhat", "rhel",
+	"amazon linux", "amazonlinux",
+	"oracle linux", "oraclelinux",
+	"alma linux", "almalinux",
+	"rocky linux", "rocky",
+	"suse linux enterprise server", "sles",
+	"suse linux enterprise", "sles",
+	"windows server", "windows",
+	"debian gnu linux", "debian",
+)
+
+// Predefined OS family map (alias -> family)
+var osFamilyDict = map[string]string{
+	"ubuntu":      "ubuntu",
+	"debian":      "debian",
+	"rhel":        "rhel",
+	"centos":      "centos",
+	"rocky":       "rocky",
+	"rockylinux":  "rocky",
+	"almalinux":   "almalinux",
+	"alma":        "almalinux",
+	"oraclelinux": "oraclelinux",
+	"oel":         "oraclelinux",
+	"ol":          "oraclelinux",
+	"amazonlinux": "amazonlinux",
+	"amzn":        "amazonlinux",
+	"al":          "amazonlinux",
+	"sles":        "sles",
+	"suse":        "sles",
+	"opensuse":    "opensuse",
+	"fedora":      "fedora",
+	"windows":     "windows",
+	"win":         "windows",
+	"freebsd":     "freebsd",
+}
+
+// Predefined OS lineage map (family -> lineage)
+var osLineageDict = map[string]string{
+	"ubuntu":      "debian",
+	"debian":      "debian",
+	"rhel":        "rhel",
+	"centos":      "rhel",
+	"rocky":       "rhel",
+	"almalinux":   "rhel",
+	"oraclelinux": "rhel",
+	"amazonlinux": "rhel",
+	"fedora":      "rhel",
+	"sles":        "suse",
+	"opensuse":    "suse",
+	"windows":     "windows",
+	"freebsd":     "bsd",
+}
+
+// Families whose releases are all long-term supported
+var osLongTermFamilies = map[string]bool{
+	"debian": true, "rhel": true, "rocky": true, "almalinux": true, "oraclelinux": true,
+	"amazonlinux": true, "sles": true, "windows": true,
+}
+
+// Families whose minor version is a separate release (e.g., ubuntu 22.04 and 22.10), not a point release
+var osReleasedByMinor = map[string]bool{
+	"ubuntu": true,
+}
+
+// Families sharing the major version numbering (e.g., RHEL rebuilds)
+var osSharedMajorFamilies = map[string]bool{
+	"rhel": true, "centos": true, "rocky": true, "almalinux": true, "oraclelinux": true,
+}
+
+// Predefined release codename map (codename -> family and version)
+var osCodenameDict = map[string][3]string{
+	"xenial":   {"ubuntu", "16", "4"},
+	"bionic":   {"ubuntu", "18", "4"},
+	"focal":    {"ubuntu", "20", "4"},
+	"jammy":    {"ubuntu", "22", "4"},
+	"noble":    {"ubuntu", "24", "4"},
+	"stretch":  {"debian", "9", ""},
+	"buster":   {"debian", "10", ""},
+	"bullseye": {"debian", "11", ""},
+	"bookworm": {"debian", "12", ""},
+	"trixie":   {"debian", "13", ""},
+}
+
+// Predefined edition keywords
+var osEditionDict = map[string]string{
+	"minimal":    "minimal",
+	"pro":        "pro",
+	"stream":     "stream",
+	"datacenter": "datacenter",
+	"sap":        "sap",
+	"byol":       "byol",
+}
+
+// Predefined end-of-life (end of standard support) dates; key is "<family> <version>"
+// * Note: Non-LTS Ubuntu releases reach EOL 9 months after the release and are not listed.
+var osEolDict = map[string]string{
+	"ubuntu 16.04":     "2021-04-30",
+	"ubuntu 18.04":     "2023-05-31",
+	"ubuntu 20.04":     "2025-05-31",
+	"ubuntu 22.04":     "2027-06-30",
+	"ubuntu 24.04":     "2029-05-31",
+	"debian 9":         "2020-07-06",
+	"debian 10":        "2022-09-10",
+	"debian 11":        "2024-08-14",
+	"debian 12":        "2026-06-10",
+	"centos 6":         "2020-11-30",
+	"centos 7":         "2024-06-30",
+	"centos 8":         "2021-12-31",
+	"centos stream 8":  "2024-05-31",
+	"centos stream 9":  "2027-05-31",
+	"rhel 7":           "2024-06-30",
+	"rhel 8":           "2029-05-31",
+	"rhel 9":           "2032-05-31",
+	"rocky 8":          "2029-05-31",
+	"rocky 9":          "2032-05-31",
+	"almalinux 8":      "2029-05-31",
+	"almalinux 9":      "2032-05-31",
+	"oraclelinux 7":    "2024-12-31",
+	"oraclelinux 8":    "2029-07-31",
+	"oraclelinux 9":    "2032-06-30",
+	"amazonlinux 1":    "2023-12-31",
+	"amazonlinux 2":    "2026-06-30",
+	"amazonlinux 2023": "2029-06-30",
+	"sles 12":          "2024-10-31",
+	"sles 15":          "2031-07-31",
+	"windows 2012":     "2023-10-10",
+	"windows 2016":     "2027-01-12",
+	"windows 2019":     "2029-01-09",
+	"windows 2022":     "2031-10-14",
+}
+
+// Predefined architecture terms normalized before parsing (to avoid being parsed as versions)
+var osArchReplacer = strings.NewReplacer(
+	"x86_64", "amd64",
+	"x86-64", "amd64",
+)
+
+var osUnderscoreVersion = regexp.MustCompile(`(\d)_(\d)`) // e.g., "22_04" in Azure image SKUs
+var osTokenSplitter = regexp.MustCompile(`[\s\-_/(),~:;"']+`)
+var osVersionPattern = regexp.MustCompile(`^(\d{1,4})(?:\.(\d{1,2}))?(?:\.\d+)*$`)
+var osAliasWithVersionPattern = regexp.MustCompile(`^([a-z]+?)(\d{1,4})(?:\.(\d{1,2}))?$`)
+
+// ParseOsIdentity parses OS information texts (e.g., "ubuntu 22.04", "Ubuntu 22.04.3 LTS", "Rocky Linux 9.3")
+// into a structured OS identity. The texts are parsed in order, so put the most reliable text first.
+// The EOL is judged at the reference time (e.g., time.Now()).
+func ParseOsIdentity(now time.Time, texts ...string) OsIdentity {
+	identity := OsIdentity{Minor: -1}
+
+	text := osArchReplacer.Replace(strings.ToLower(strings.Join(texts, " ")))
+	text = osUnderscoreVersion.ReplaceAllString(text, "$1.$2")
+	text = strings.Join(strings.Fields(osTokenSplitter.ReplaceAllString(text, " ")), " ")
+	text = osPhraseReplacer.Replace(text)
+	tokens := strings.Fields(text)
+
+	// 1. Family and version (the version follows the family, or is embedded in it such as "rhel8" and "al2023")
+	for i, token := range tokens {
+		if family, ok := osFamilyDict[token]; ok && len(token) > 2 {
+			identity.Family = family
+			identity.Major, identity.Minor = findOsVersion(tokens[i+1:])
+			break
+		}
+		if m := osAliasWithVersionPattern.FindStringSubmatch(token); m != nil {
+			if family, ok := osFamilyDict[m[1]]; ok {
+				identity.Family = family
+				identity.Major, _ = strconv.Atoi(m[2])
+				if m[3] != "" {
+					identity.Minor, _ = strconv.Atoi(m[3])
+				}
+				break
+			}
+		}
+	}
+
+	// 2. Codename (e.g., "jammy") if the family or version is missing
+	for _, token := range tokens {
+		if release, ok := osCodenameDict[token]; ok {
+			if identity.Family == "" {
+				identity.Family = release[0]
+			}
+			if identity.Family == release[0] && identity.Major == 0 {
+				identity.Major, _ = strconv.Atoi(release[1])
+				if release[2] != "" {
+					identity.Minor, _ = strconv.Atoi(release[2])
+				}
+			}
+			break
+		}
+	}
+
+	if identity.Family == "" {
+		return identity
+	}
+	identity.Lineage = osLineageDict[identity.Family]
+
+	// 3. Edition and LTS
+	for _, token := range tokens {
+		if edition, ok := osEditionDict[token]; ok && identity.Edition == "" {
+			identity.Edition = edition
+		}
+		if token == "lts" {
+			identity.Lts = true
+		}
+	}
+	if identity.Family == "ubuntu" {
+		// Ubuntu LTS releases are published in April of even years
+		identity.Lts = identity.Lts || (identity.Major%2 == 0 && identity.Minor == 4)
+	} else if osLongTermFamilies[identity.Family] {
+		identity.Lts = true
+	}
+
+	// 4. EOL
+	identity.Eol = isOsEol(identity, now)
+
+	return identity
+}
+
+// ParseOsIdentityFromOsProperty parses the OS information of a source server (the EOL is judged at the reference time)
+func ParseOsIdentityFromOsProperty(os onpremmodel.OsProperty, now time.Time) OsIdentity {
+	identity := ParseOsIdentity(now, os.ID, os.VersionID, os.PrettyName, os.Version, os.Name, os.VersionCodename)
+
+	// Use the ID_LIKE (e.g., "rhel centos fedora") as lineage for derived distros
+	if identity.Lineage == "" && os.IDLike != "" {
+		like := ParseOsIdentity(now, os.IDLike)
+		if like.Known() {
+			if identity.Family == "" {
+				identity.Family = strings.ToLower(os.ID)
+				identity.Major, identity.Minor = findOsVersion(strings.Fields(os.VersionID))
+			}
+			identity.Lineage = like.Lineage
+		}
+	}
+	return identity
+}
+
+// ParseOsIdentityFromImage parses the OS information of a VM OS image (the EOL is judged at the reference time)
+func ParseOsIdentityFromImage(image cloudmodel.ImageInfo, now time.Time) OsIdentity {
+	return ParseOsIdentity(now, image.OSType, image.OSDistribution)
+}
+
+// CompareOsIdentity grades the compatibility of a target OS with a source OS
+func CompareOsIdentity(source, target OsIdentity) OsMatch {
+	match := OsMatch{Source: source, Target: target}
+
+	switch {
+	case !source.Known() || !target.Known():
+		match.Grade = OsMatchUnknown
+	case source.Family == target.Family:
+		switch {
+		case source.Major == 0 || target.Major == 0:
+			match.Grade = OsMatchSameFamily
+		case source.Major == target.Major && (source.Minor == target.Minor || source.Minor < 0 || target.Minor < 0):
+			match.Grade = OsMatchExact
+		case source.Major == target.Major && !osReleasedByMinor[source.Family]:
+			match.Grade = OsMatchSameMajor
+		case (target.Major > source.Major || (target.Major == source.Major && target.Minor > source.Minor)) && target.Lts:
+			match.Grade = OsMatchNewerLts
+		default:
+			match.Grade = OsMatchSameFamily
+		}
+	case source.Lineage != "" && source.Lineage == target.Lineage:
+		match.Grade = OsMatchCrossFamily
+	default:
+		match.Grade = OsMatchMismatch
+	}
+
+	score := osMatchGradeScores[match.Grade]
+
+	switch match.Grade {
+	case OsMatchSameMajor:
+		// Prefer a newer minor version
+		if target.Minor < source.Minor {
+			score -= 0.05
+		}
+	case OsMatchNewerLts:
+		// Prefer the nearest newer LTS version
+		gap := target.Major - source.Major
+		if source.Family == "ubuntu" {
+			gap /= 2
+		}
+		if gap > 1 {
+			score -= 0.05 * float64(gap-1)
+		}
+	case OsMatchSameFamily:
+		// Prefer a newer version
+		if target.Major > 0 && target.Major < source.Major {
+			score -= 0.1
+		}
+	case OsMatchCrossFamily:
+		// Prefer the same or the nearest newer major version (e.g., centos 7 -> rocky 8)
+		switch {
+		case source.Major == 0 || target.Major == 0:
+		case !osSharedMajorFamilies[source.Family] || !osSharedMajorFamilies[target.Family]:
+		case source.Major == target.Major:
+			score += 0.1
+		case target.Major > source.Major:
+			score -= 0.05 * float64(target.Major-source.Major-1)
+		default:
+			score -= 0.1
+		}
+	}
+
+	if match.Grade != OsMatchUnknown && match.Grade != OsMatchMismatch {
+		if target.Eol && !source.Eol {
+			score -= 0.1
+		}
+		if target.Edition != source.Edition && target.Edition != "" {
+			score -= 0.05
+		}
+	}
+
+	if score < 0 {
+		score = 0
+	}
+	match.Score = score
+	return match
+}
+
+// findOsVersion finds the first version-like token (e.g., "22.04", "9", "2023")
+func findOsVersion(tokens []string) (int, int) {
+	for _, token := range tokens {
+		if m := osVersionPattern.FindStringSubmatch(token); m != nil {
+			major, _ := strconv.Atoi(m[1])
+			minor := -1
+			if m[2] != "" {
+				minor, _ = strconv.Atoi(m[2])
+			}
+			return major, minor
+		}
+	}
+	return 0, -1
+}
+
+// isOsEol checks if the OS reached the end of (standard) life at the given time
+func isOsEol(identity OsIdentity, now time.Time) bool {
+	if identity.Major == 0 {
+		return false
+	}
+
+	keys := []string{}
+	if identity.Edition == "stream" {
+		keys = append(keys, fmt.Sprintf("%s stream %d", identity.Family, identity.Major))
+	}
+	keys = append(keys,
+		fmt.Sprintf("%s %s", identity.Family, identity.Version()),
+		fmt.Sprintf("%s %d", identity.Family, identity.Major),
+	)
+
+	for _, key := range keys {
+		if date, ok := osEolDict[key]; ok {
+			eol, err := time.Parse("2006-01-02", date)
+			return err == nil && now.After(eol)
+		}
+	}
+
+	// Non-LTS Ubuntu releases (YY.MM) are supported for 9 months
+	if identity.Family == "ubuntu" && !identity.Lts && identity.Minor > 0 {
+		release := time.Date(2000+identity.Major, time.Month(identity.Minor), 1, 0, 0, 0, 0, time.UTC)
+		return now.After(release.AddDate(0, 9, 0))
+	}
+
+	return false
+}
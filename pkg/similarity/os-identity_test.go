@@ -0,0 +1,161 @@
+package similarity
+
+import (
+	"testing"
+	"time"
+
+	onpremmodel "github.com/cloud-barista/cm-beetle/imdl/on-premise-model"
+)
+
+// refTime is the reference time of the EOL judgement in the tests
+var refTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestParseOsIdentity(t *testing.T) {
+	tests := []struct {
+		texts []string
+		want  OsIdentity
+	}{
+		{
+			texts: []string{"ubuntu 22.04 jammy amd64 ssd"},
+			want:  OsIdentity{Family: "ubuntu", Lineage: "debian", Major: 22, Minor: 4, Lts: true},
+		},
+		{
+			texts: []string{"Ubuntu 22.04.3 LTS"},
+			want:  OsIdentity{Family: "ubuntu", Lineage: "debian", Major: 22, Minor: 4, Lts: true},
+		},
+		{
+			// Non-LTS releases are supported for 9 months
+			texts: []string{"ubuntu", "23.10"},
+			want:  OsIdentity{Family: "ubuntu", Lineage: "debian", Major: 23, Minor: 10, Eol: true},
+		},
+		{
+			// Azure image SKU with an underscore version
+			texts: []string{"Canonical 0001-com-ubuntu-server-jammy 22_04-lts-gen2"},
+			want:  OsIdentity{Family: "ubuntu", Lineage: "debian", Major: 22, Minor: 4, Lts: true},
+		},
+		{
+			// Version by the codename
+			texts: []string{"debian bookworm"},
+			want:  OsIdentity{Family: "debian", Lineage: "debian", Major: 12, Minor: -1, Lts: true},
+		},
+		{
+			texts: []string{"Rocky Linux 9.3 (Blue Onyx)"},
+			want:  OsIdentity{Family: "rocky", Lineage: "rhel", Major: 9, Minor: 3, Lts: true},
+		},
+		{
+			texts: []string{"Red Hat Enterprise Linux 8.9"},
+			want:  OsIdentity{Family: "rhel", Lineage: "rhel", Major: 8, Minor: 9, Lts: true},
+		},
+		{
+			// Version embedded in the alias, with an architecture not parsed as a version
+			texts: []string{"al2023-ami-2023.6 x86_64"},
+			want:  OsIdentity{Family: "amazonlinux", Lineage: "rhel", Major: 2023, Minor: -1, Lts: true},
+		},
+		{
+			texts: []string{"CentOS 7.9.2009"},
+			want:  OsIdentity{Family: "centos", Lineage: "rhel", Major: 7, Minor: 9, Eol: true},
+		},
+		{
+			texts: []string{"centos stream 9"},
+			want:  OsIdentity{Family: "centos", Lineage: "rhel", Major: 9, Minor: -1, Edition: "stream"},
+		},
+		{
+			texts: []string{"Windows Server 2022 Datacenter"},
+			want:  OsIdentity{Family: "windows", Lineage: "windows", Major: 2022, Minor: -1, Lts: true, Edition: "datacenter"},
+		},
+		{
+			texts: []string{"ubuntu-pro 20.04"},
+			want:  OsIdentity{Family: "ubuntu", Lineage: "debian", Major: 20, Minor: 4, Lts: true, Edition: "pro"},
+		},
+		{
+			texts: []string{"some custom image"},
+			want:  OsIdentity{Minor: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want.String()+"/"+tt.texts[0], func(t *testing.T) {
+			if got := ParseOsIdentity(refTime, tt.texts...); got != tt.want {
+				t.Errorf("ParseOsIdentity(%q) = %+v, want %+v", tt.texts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOsIdentityEolAtReferenceTime(t *testing.T) {
+	tests := []struct {
+		text string
+		now  time.Time
+		want bool
+	}{
+		{text: "ubuntu 20.04", now: time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC), want: false},
+		{text: "ubuntu 20.04", now: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), want: true},
+		{text: "centos stream 8", now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), want: false},
+		{text: "centos stream 8", now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), want: true},
+		{text: "ubuntu 23.10", now: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), want: false},
+		{text: "ubuntu 23.10", now: time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC), want: true},
+		{text: "fedora 40", now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), want: false}, // Unknown EOL
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text+"@"+tt.now.Format("2006-01-02"), func(t *testing.T) {
+			if got := ParseOsIdentity(tt.now, tt.text).Eol; got != tt.want {
+				t.Errorf("EOL of %q at %s = %v, want %v", tt.text, tt.now.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOsIdentityFromOsProperty(t *testing.T) {
+	// A derived distro not in the dictionary takes the lineage from ID_LIKE
+	os := onpremmodel.OsProperty{ID: "openeuler", VersionID: "22.03", IDLike: "rhel centos fedora"}
+	want := OsIdentity{Family: "openeuler", Lineage: "rhel", Major: 22, Minor: 3}
+	if got := ParseOsIdentityFromOsProperty(os, refTime); got != want {
+		t.Errorf("ParseOsIdentityFromOsProperty() = %+v, want %+v", got, want)
+	}
+
+	os = onpremmodel.OsProperty{ID: "ubuntu", VersionID: "22.04", PrettyName: "Ubuntu 22.04.4 LTS", VersionCodename: "jammy"}
+	want = OsIdentity{Family: "ubuntu", Lineage: "debian", Major: 22, Minor: 4, Lts: true}
+	if got := ParseOsIdentityFromOsProperty(os, refTime); got != want {
+		t.Errorf("ParseOsIdentityFromOsProperty() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompareOsIdentity(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		target    string
+		wantGrade OsMatchGrade
+		wantScore float64
+	}{
+		{name: "exact", source: "ubuntu 22.04", target: "ubuntu 22.04", wantGrade: OsMatchExact, wantScore: 1.0},
+		{name: "exact without minor", source: "rocky 9.3", target: "rocky 9", wantGrade: OsMatchExact, wantScore: 1.0},
+		{name: "same major, newer minor", source: "rhel 8.6", target: "rhel 8.9", wantGrade: OsMatchSameMajor, wantScore: 0.9},
+		{name: "same major, older minor", source: "rhel 8.9", target: "rhel 8.6", wantGrade: OsMatchSameMajor, wantScore: 0.85},
+		{name: "ubuntu minor is a release (EOL)", source: "ubuntu 22.04", target: "ubuntu 22.10", wantGrade: OsMatchSameFamily, wantScore: 0.4},
+		{name: "nearest newer LTS", source: "ubuntu 20.04", target: "ubuntu 22.04", wantGrade: OsMatchNewerLts, wantScore: 0.75},
+		{name: "farther newer LTS", source: "ubuntu 18.04", target: "ubuntu 22.04", wantGrade: OsMatchNewerLts, wantScore: 0.70},
+		{name: "cross family, other numbering", source: "debian 12", target: "ubuntu 22.04", wantGrade: OsMatchCrossFamily, wantScore: 0.4},
+		{name: "same family, older version", source: "ubuntu 22.04", target: "ubuntu 20.04", wantGrade: OsMatchSameFamily, wantScore: 0.4},
+		{name: "cross family, same major", source: "centos 8", target: "rocky 8", wantGrade: OsMatchCrossFamily, wantScore: 0.5},
+		{name: "cross family, next major", source: "centos 7", target: "rocky 8", wantGrade: OsMatchCrossFamily, wantScore: 0.4},
+		{name: "cross family, older major", source: "rocky 9", target: "centos 7", wantGrade: OsMatchCrossFamily, wantScore: 0.2},
+		{name: "EOL target", source: "rocky 8", target: "centos 8", wantGrade: OsMatchCrossFamily, wantScore: 0.4},
+		{name: "other edition", source: "ubuntu 22.04", target: "ubuntu pro 22.04", wantGrade: OsMatchExact, wantScore: 0.95},
+		{name: "mismatch", source: "ubuntu 22.04", target: "windows 2022", wantGrade: OsMatchMismatch, wantScore: 0},
+		{name: "unknown", source: "ubuntu 22.04", target: "custom image", wantGrade: OsMatchUnknown, wantScore: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := CompareOsIdentity(ParseOsIdentity(refTime, tt.source), ParseOsIdentity(refTime, tt.target))
+			if match.Grade != tt.wantGrade {
+				t.Errorf("grade of %q -> %q = %s, want %s", tt.source, tt.target, match.Grade, tt.wantGrade)
+			}
+			if diff := match.Score - tt.wantScore; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("score of %q -> %q = %.2f, want %.2f", tt.source, tt.target, match.Score, tt.wantScore)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package transx
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Bucket-Content Migration
+// Copies all object versions in order with their content headers, user metadata,
+// tags and public-read grants, streaming each version from the source to the target.
+// ============================================================================
+
+// Object attributes counted in BucketContentResult
+const (
+	AttrVersions           = "versions"
+	AttrContentType        = "contentType"
+	AttrCacheControl       = "cacheControl"
+	AttrContentEncoding    = "contentEncoding"
+	AttrContentDisposition = "contentDisposition"
+	AttrUserMetadata       = "userMetadata"
+	AttrTags               = "tags"
+	AttrPublicRead         = "publicRead"
+)
+
+// maxResultObjectKeys limits the object keys listed in BucketContentResult.
+const maxResultObjectKeys = 100
+
+// BucketContentResult is the per-bucket result of the bucket-content migration.
+// Preserved and Dropped count the object versions carrying each attribute (see Attr* constants);
+// Dropped["versions"] counts the versions not copied.
+type BucketContentResult struct {
+	SourceBucket      string         `json:"sourceBucket"`
+	TargetBucket      string         `json:"targetBucket"`
+	AllVersions       bool           `json:"allVersions"`       // Whether all versions were copied (false: current versions only)
+	ObjectCount       int            `json:"objectCount"`       // Objects (keys) copied
+	VersionCount      int            `json:"versionCount"`      // Object versions copied
+	DeleteMarkerCount int            `json:"deleteMarkerCount"` // Delete markers replicated
+	TotalSizeBytes    int64          `json:"totalSizeBytes"`    // Bytes copied
+	Preserved         map[string]int `json:"preserved"`
+	Dropped           map[string]int `json:"dropped"`
+	PublicReadObjects []string       `json:"publicReadObjects,omitempty"` // Public-read objects to expose with the target's public access model (up to 100)
+	FailedObjects     []string       `json:"failedObjects,omitempty"`     // "<key>[@<versionId>]: <error>" (up to 100)
+	Warnings          []string       `json:"warnings,omitempty"`
+}
+
+// MigrateBucketContent copies the content of the source bucket to the destination bucket
+// as defined by the DataMigrationModel (mode: bucket-content).
+// Failures of individual objects do not stop the migration; they are listed in the result
+// and reported as an error after all objects are processed.
+func MigrateBucketContent(dmm DataMigrationModel) (*BucketContentResult, error) {
+	if err := Validate(dmm); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if !dmm.Source.IsObjectStorage() || !dmm.Destination.IsObjectStorage() {
+		return nil, fmt.Errorf("bucket-content migration requires object storage for both source and destination")
+	}
+
+	src, err := NewS3Provider(dmm.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source S3 provider: %w", err)
+	}
+	dst, err := NewS3Provider(dmm.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination S3 provider: %w", err)
+	}
+
+	opt := BucketContentOption{PublicRead: PublicReadReport}
+	if dmm.BucketContent != nil {
+		opt = *dmm.BucketContent
+		if opt.PublicRead == "" {
+			opt.PublicRead = PublicReadReport
+		}
+	}
+
+	_, srcPrefix := ParseBucketAndKey(dmm.Source.Path)
+	_, dstPrefix := ParseBucketAndKey(dmm.Destination.Path)
+
+	return migrateBucketContent(src, dst, srcPrefix, dstPrefix, opt)
+}
+
+// migrateBucketContent copies the objects under srcPrefix to dstPrefix.
+func migrateBucketContent(src, dst S3Provider, srcPrefix, dstPrefix string, opt BucketContentOption) (*BucketContentResult, error) {
+	result := &BucketContentResult{
+		SourceBucket: src.GetBucket(),
+		TargetBucket: dst.GetBucket(),
+		Preserved:    map[string]int{},
+		Dropped:      map[string]int{},
+	}
+
+	srcContent, srcFull := src.(S3ContentProvider)
+	dstContent, dstFull := dst.(S3ContentProvider)
+
+	// Decide whether all versions can be copied
+	allVersions := !opt.CurrentVersionOnly
+	if allVersions && !srcFull {
+		result.Warnings = append(result.Warnings, "the source is accessed via presigned URLs; only the current versions are copied")
+		allVersions = false
+	}
+	if allVersions && !dstFull {
+		result.Warnings = append(result.Warnings, "the target is accessed via presigned URLs and its versioning cannot be verified; only the current versions are copied")
+		allVersions = false
+	}
+	if allVersions {
+		enabled, err := dstContent.IsVersioningEnabled()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check the versioning of the target bucket: %w", err)
+		}
+		if !enabled {
+			result.Warnings = append(result.Warnings, "versioning is not enabled on the target bucket; only the current versions are copied")
+			allVersions = false
+		}
+	}
+	result.AllVersions = allVersions
+
+	if opt.PublicRead == PublicReadACL && !dstFull {
+		result.Warnings = append(result.Warnings, "object ACLs cannot be applied via presigned URLs; public-read objects are reported instead")
+	}
+
+	// List the object versions
+	var versions []ObjectVersionInfo
+	if srcFull {
+		listed, err := srcContent.ListObjectVersions(srcPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list object versions: %w", err)
+		}
+		versions = listed
+	} else {
+		objects, err := src.ListObjects(srcPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range objects {
+			versions = append(versions, ObjectVersionInfo{ObjectInfo: obj, IsLatest: true})
+		}
+	}
+
+	for _, objectVersions := range groupObjectVersions(versions) {
+		key := objectVersions[0].Key
+		dstKey := mapObjectKey(key, srcPrefix, dstPrefix)
+
+		toCopy := objectVersions
+		if !allVersions {
+			toCopy = objectVersions[len(objectVersions)-1:]
+			for _, v := range objectVersions[:len(objectVersions)-1] {
+				if !v.IsDeleteMarker {
+					result.Dropped[AttrVersions]++
+				}
+			}
+		}
+
+		copied := false
+		for i, v := range toCopy {
+			isCurrent := i == len(toCopy)-1
+
+			if v.IsDeleteMarker {
+				// A delete marker is replicated only after a copied version (i.e., all versions are copied)
+				if copied && allVersions {
+					if err := dstContent.DeleteObject(dstKey); err != nil {
+						result.addFailure(key, v.VersionId, err)
+						break
+					}
+					result.DeleteMarkerCount++
+				}
+				continue
+			}
+
+			if err := copyObjectVersion(src, dst, v, dstKey, isCurrent, opt, result); err != nil {
+				result.addFailure(key, v.VersionId, err)
+				break
+			}
+			copied = true
+		}
+		if copied {
+			result.ObjectCount++
+		}
+	}
+
+	if len(result.FailedObjects) > 0 {
+		return result, fmt.Errorf("failed to copy %d object version(s) of the bucket (%s)", len(result.FailedObjects), result.SourceBucket)
+	}
+	return result, nil
+}
+
+// copyObjectVersion streams an object version from the source to the target and counts its attributes.
+func copyObjectVersion(src, dst S3Provider, v ObjectVersionInfo, dstKey string, isCurrent bool, opt BucketContentOption, result *BucketContentResult) error {
+	srcContent, srcFull := src.(S3ContentProvider)
+	dstContent, dstFull := dst.(S3ContentProvider)
+
+	var body io.ReadCloser
+	var size int64
+	var attrs ObjectAttributes
+	var err error
+	if srcFull {
+		body, size, attrs, err = srcContent.GetObjectContent(v.Key, v.VersionId)
+	} else {
+		body, size, attrs, err = getObjectViaPresignedURL(src, v.Key)
+	}
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if size < 0 {
+		size = v.Size
+	}
+
+	// Public-read grants apply to the current version only
+	if isCurrent && srcFull {
+		publicRead, err := srcContent.IsPublicRead(v.Key)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: public-read is not checked (%v)", v.Key, err))
+		}
+		attrs.PublicRead = publicRead
+	}
+
+	// Map the attributes to the target capabilities
+	count := func(attr string, present, preserved bool) {
+		switch {
+		case !present:
+		case preserved:
+			result.Preserved[attr]++
+		default:
+			result.Dropped[attr]++
+		}
+	}
+	count(AttrContentType, attrs.ContentType != "", true)
+	count(AttrCacheControl, attrs.CacheControl != "", true)
+	count(AttrContentEncoding, attrs.ContentEncoding != "", true)
+	count(AttrContentDisposition, attrs.ContentDisposition != "", true)
+	count(AttrUserMetadata, len(attrs.UserMetadata) > 0, dstFull)
+	count(AttrTags, len(attrs.Tags) > 0 || attrs.TagCount > 0, dstFull && len(attrs.Tags) > 0)
+
+	grantPublicRead := attrs.PublicRead && opt.PublicRead == PublicReadACL && dstFull
+	count(AttrPublicRead, attrs.PublicRead, grantPublicRead)
+	if attrs.PublicRead && !grantPublicRead && len(result.PublicReadObjects) < maxResultObjectKeys {
+		result.PublicReadObjects = append(result.PublicReadObjects, dstKey)
+	}
+	attrs.PublicRead = grantPublicRead
+
+	if dstFull {
+		err = dstContent.PutObjectContent(dstKey, body, size, attrs)
+	} else {
+		err = putObjectViaPresignedURL(dst, dstKey, body, size, attrs)
+	}
+	if err != nil {
+		return err
+	}
+
+	result.VersionCount++
+	result.TotalSizeBytes += size
+	return nil
+}
+
+// addFailure records a failed object version.
+func (r *BucketContentResult) addFailure(key, versionId string, err error) {
+	if len(r.FailedObjects) >= maxResultObjectKeys {
+		return
+	}
+	if versionId != "" {
+		key = key + "@" + versionId
+	}
+	r.FailedObjects = append(r.FailedObjects, fmt.Sprintf("%s: %v", key, err))
+}
+
+// groupObjectVersions groups the versions by key (in key order) and sorts the versions
+// of each key from the oldest to the current one.
+// S3 lists the versions of a key from the newest, so the listed order is reversed before
+// the stable sort by last-modified time to keep versions with the same timestamp in order.
+func groupObjectVersions(versions []ObjectVersionInfo) [][]ObjectVersionInfo {
+	byKey := map[string][]ObjectVersionInfo{}
+	var keys []string
+	for _, v := range versions {
+		if _, ok := byKey[v.Key]; !ok {
+			keys = append(keys, v.Key)
+		}
+		byKey[v.Key] = append(byKey[v.Key], v)
+	}
+	sort.Strings(keys)
+
+	grouped := make([][]ObjectVersionInfo, 0, len(keys))
+	for _, key := range keys {
+		vs := byKey[key]
+		for i, j := 0, len(vs)-1; i < j; i, j = i+1, j-1 {
+			vs[i], vs[j] = vs[j], vs[i]
+		}
+		sort.SliceStable(vs, func(i, j int) bool {
+			ti, _ := time.Parse(time.RFC3339Nano, vs[i].LastModified)
+			tj, _ := time.Parse(time.RFC3339Nano, vs[j].LastModified)
+			return ti.Before(tj)
+		})
+
+		// The current version must be the last one even if the timestamps are not ordered
+		for i, v := range vs {
+			if v.IsLatest && i != len(vs)-1 {
+				vs = append(append(vs[:i:i], vs[i+1:]...), v)
+				break
+			}
+		}
+		grouped = append(grouped, vs)
+	}
+	return grouped
+}
+
+// mapObjectKey maps the source object key under srcPrefix to the target key under dstPrefix.
+func mapObjectKey(key, srcPrefix, dstPrefix string) string {
+	rel := strings.TrimPrefix(strings.TrimPrefix(key, srcPrefix), "/")
+	if dstPrefix == "" {
+		return rel
+	}
+	return path.Join(dstPrefix, rel)
+}
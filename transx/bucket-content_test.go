@@ -0,0 +1,291 @@
+package transx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// memVersion is an object version stored in memBucket.
+type memVersion struct {
+	info  ObjectVersionInfo
+	data  []byte
+	attrs ObjectAttributes
+}
+
+// memBucket is an in-memory S3ContentProvider (versions are kept oldest first).
+type memBucket struct {
+	name       string
+	versioning bool
+	objects    map[string][]memVersion
+	publicRead map[string]bool
+	seq        int
+}
+
+func newMemBucket(name string, versioning bool) *memBucket {
+	return &memBucket{name: name, versioning: versioning, objects: map[string][]memVersion{}, publicRead: map[string]bool{}}
+}
+
+func (b *memBucket) put(key, data string, deleteMarker bool, attrs ObjectAttributes) {
+	b.seq++
+	vs := b.objects[key]
+	if !b.versioning {
+		vs = nil
+	}
+	for i := range vs {
+		vs[i].info.IsLatest = false
+	}
+	info := ObjectVersionInfo{
+		ObjectInfo:     ObjectInfo{Key: key, Size: int64(len(data)), LastModified: fmt.Sprintf("2024-01-01T00:00:%02dZ", b.seq)},
+		IsLatest:       true,
+		IsDeleteMarker: deleteMarker,
+	}
+	if b.versioning {
+		info.VersionId = fmt.Sprintf("v%d", b.seq)
+	}
+	b.objects[key] = append(vs, memVersion{info: info, data: []byte(data), attrs: attrs})
+	b.publicRead[key] = attrs.PublicRead
+}
+
+func (b *memBucket) GeneratePresignedURL(action, key string) (PresignedURLResult, error) {
+	return PresignedURLResult{}, fmt.Errorf("not supported")
+}
+
+func (b *memBucket) ListObjects(prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for key, vs := range b.objects {
+		latest := vs[len(vs)-1]
+		if strings.HasPrefix(key, prefix) && !latest.info.IsDeleteMarker {
+			objects = append(objects, latest.info.ObjectInfo)
+		}
+	}
+	return objects, nil
+}
+
+func (b *memBucket) GetBucket() string { return b.name }
+
+func (b *memBucket) IsVersioningEnabled() (bool, error) { return b.versioning, nil }
+
+func (b *memBucket) ListObjectVersions(prefix string) ([]ObjectVersionInfo, error) {
+	var versions []ObjectVersionInfo
+	for key, vs := range b.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		// Newest first, as S3 lists them
+		for i := len(vs) - 1; i >= 0; i-- {
+			versions = append(versions, vs[i].info)
+		}
+	}
+	return versions, nil
+}
+
+func (b *memBucket) GetObjectContent(key, versionId string) (io.ReadCloser, int64, ObjectAttributes, error) {
+	for _, v := range b.objects[key] {
+		if v.info.VersionId == versionId && !v.info.IsDeleteMarker {
+			attrs := v.attrs
+			attrs.PublicRead = false
+			return io.NopCloser(bytes.NewReader(v.data)), int64(len(v.data)), attrs, nil
+		}
+	}
+	return nil, 0, ObjectAttributes{}, fmt.Errorf("no such version: %s@%s", key, versionId)
+}
+
+func (b *memBucket) IsPublicRead(key string) (bool, error) { return b.publicRead[key], nil }
+
+func (b *memBucket) PutObjectContent(key string, body io.Reader, size int64, attrs ObjectAttributes) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	b.put(key, string(data), false, attrs)
+	return nil
+}
+
+func (b *memBucket) DeleteObject(key string) error {
+	b.put(key, "", true, ObjectAttributes{})
+	return nil
+}
+
+// presignedBucket is an S3Provider accessible via presigned URLs only (served by httptest).
+type presignedBucket struct {
+	name    string
+	server  *httptest.Server
+	objects map[string][]byte
+	headers map[string]http.Header
+}
+
+func newPresignedBucket(t *testing.T, name string) *presignedBucket {
+	b := &presignedBucket{name: name, objects: map[string][]byte{}, headers: map[string]http.Header{}}
+	b.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			b.objects[key] = data
+			b.headers[key] = r.Header.Clone()
+		case http.MethodGet:
+			data, ok := b.objects[key]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			for name, values := range b.headers[key] {
+				w.Header()[name] = values
+			}
+			w.Write(data)
+		}
+	}))
+	t.Cleanup(b.server.Close)
+	return b
+}
+
+func (b *presignedBucket) GeneratePresignedURL(action, key string) (PresignedURLResult, error) {
+	return PresignedURLResult{URL: b.server.URL + "/" + key}, nil
+}
+
+func (b *presignedBucket) ListObjects(prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for key, data := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{Key: key, Size: int64(len(data))})
+		}
+	}
+	return objects, nil
+}
+
+func (b *presignedBucket) GetBucket() string { return b.name }
+
+func TestMigrateBucketContentAllVersions(t *testing.T) {
+	src := newMemBucket("src", true)
+	attrs := ObjectAttributes{
+		ContentType:  "text/plain",
+		CacheControl: "max-age=60",
+		UserMetadata: map[string]string{"owner": "team-a"},
+		Tags:         map[string]string{"env": "prod"},
+	}
+	src.put("data/a.txt", "a1", false, attrs)
+	src.put("data/a.txt", "a2", false, attrs)
+	src.put("data/b.txt", "b1", false, ObjectAttributes{ContentType: "text/plain", PublicRead: true})
+	src.put("data/b.txt", "", true, ObjectAttributes{})
+	src.put("data/c.txt", "c1", false, ObjectAttributes{PublicRead: true})
+
+	dst := newMemBucket("dst", true)
+
+	result, err := migrateBucketContent(src, dst, "data/", "copy", BucketContentOption{PublicRead: PublicReadACL})
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	if !result.AllVersions {
+		t.Errorf("expected all versions to be copied, warnings: %v", result.Warnings)
+	}
+	if result.ObjectCount != 3 || result.VersionCount != 4 || result.DeleteMarkerCount != 1 {
+		t.Errorf("unexpected counts: objects=%d versions=%d deleteMarkers=%d", result.ObjectCount, result.VersionCount, result.DeleteMarkerCount)
+	}
+
+	// Versions are replayed oldest first, so the current version stays current
+	a := dst.objects["copy/a.txt"]
+	if len(a) != 2 || string(a[0].data) != "a1" || string(a[1].data) != "a2" {
+		t.Fatalf("unexpected versions of copy/a.txt: %+v", a)
+	}
+	if a[1].attrs.UserMetadata["owner"] != "team-a" || a[1].attrs.Tags["env"] != "prod" || a[1].attrs.CacheControl != "max-age=60" {
+		t.Errorf("attributes not preserved: %+v", a[1].attrs)
+	}
+	if b := dst.objects["copy/b.txt"]; len(b) != 2 || !b[1].info.IsDeleteMarker {
+		t.Errorf("delete marker of copy/b.txt not replicated: %+v", b)
+	}
+
+	// Only the current version of c.txt is public-read (b.txt is deleted)
+	if !dst.publicRead["copy/c.txt"] {
+		t.Error("public-read of copy/c.txt not granted")
+	}
+	if result.Preserved[AttrPublicRead] != 1 || len(result.PublicReadObjects) != 0 {
+		t.Errorf("unexpected public-read mapping: preserved=%d objects=%v", result.Preserved[AttrPublicRead], result.PublicReadObjects)
+	}
+	if result.Preserved[AttrUserMetadata] != 2 || result.Preserved[AttrTags] != 2 || result.Preserved[AttrContentType] != 3 {
+		t.Errorf("unexpected preserved counts: %v", result.Preserved)
+	}
+	if len(result.Dropped) != 0 {
+		t.Errorf("unexpected dropped counts: %v", result.Dropped)
+	}
+}
+
+func TestMigrateBucketContentUnversionedTarget(t *testing.T) {
+	src := newMemBucket("src", true)
+	src.put("a.txt", "a1", false, ObjectAttributes{})
+	src.put("a.txt", "a2", false, ObjectAttributes{PublicRead: true})
+	src.put("b.txt", "b1", false, ObjectAttributes{})
+	src.put("b.txt", "", true, ObjectAttributes{})
+
+	dst := newMemBucket("dst", false)
+
+	result, err := migrateBucketContent(src, dst, "", "", BucketContentOption{PublicRead: PublicReadReport})
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	if result.AllVersions || len(result.Warnings) == 0 {
+		t.Errorf("expected current versions only with a warning, got allVersions=%v warnings=%v", result.AllVersions, result.Warnings)
+	}
+	if a := dst.objects["a.txt"]; len(a) != 1 || string(a[0].data) != "a2" {
+		t.Errorf("unexpected a.txt: %+v", a)
+	}
+	if _, ok := dst.objects["b.txt"]; ok {
+		t.Error("deleted object b.txt should not be copied")
+	}
+	if result.Dropped[AttrVersions] != 2 {
+		t.Errorf("expected 2 dropped versions, got %d", result.Dropped[AttrVersions])
+	}
+
+	// Report mode lists the public-read objects without granting them
+	if dst.publicRead["a.txt"] || len(result.PublicReadObjects) != 1 || result.PublicReadObjects[0] != "a.txt" {
+		t.Errorf("unexpected public-read mapping: granted=%v objects=%v", dst.publicRead["a.txt"], result.PublicReadObjects)
+	}
+}
+
+func TestMigrateBucketContentPresignedTarget(t *testing.T) {
+	src := newMemBucket("src", true)
+	src.put("a.txt", "hello", false, ObjectAttributes{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"owner": "team-a"},
+		Tags:         map[string]string{"env": "prod"},
+		PublicRead:   true,
+	})
+
+	dst := newPresignedBucket(t, "dst")
+
+	result, err := migrateBucketContent(src, dst, "", "", BucketContentOption{PublicRead: PublicReadACL})
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	if string(dst.objects["a.txt"]) != "hello" || dst.headers["a.txt"].Get("Content-Type") != "text/plain" {
+		t.Errorf("unexpected a.txt: %q %v", dst.objects["a.txt"], dst.headers["a.txt"])
+	}
+	if result.Preserved[AttrContentType] != 1 {
+		t.Errorf("content type not preserved: %v", result.Preserved)
+	}
+	for _, attr := range []string{AttrUserMetadata, AttrTags, AttrPublicRead} {
+		if result.Dropped[attr] != 1 {
+			t.Errorf("expected %s to be dropped: %v", attr, result.Dropped)
+		}
+	}
+	if len(result.PublicReadObjects) != 1 {
+		t.Errorf("expected the public-read object to be reported: %v", result.PublicReadObjects)
+	}
+
+	// Copy back from the presigned bucket (current versions only)
+	back := newMemBucket("back", true)
+	result, err = migrateBucketContent(dst, back, "", "", BucketContentOption{})
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	if result.AllVersions || result.VersionCount != 1 || back.objects["a.txt"][0].attrs.ContentType != "text/plain" {
+		t.Errorf("unexpected copy from presigned source: %+v", result)
+	}
+}
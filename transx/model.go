@@ -57,6 +57,29 @@ const (
 	StrategyRelay = "relay"
 )
 
+// ============================================================================
+// Migration Modes: What is migrated
+// ============================================================================
+
+const (
+	// ModeFiles copies the current data (files or object bytes). This is the default.
+	ModeFiles = "files"
+
+	// ModeBucketContent copies all object versions in order with their content headers,
+	// user metadata, tags and public-read grants (object storage to object storage only).
+	ModeBucketContent = "bucket-content"
+)
+
+// Public-read mappings for the bucket-content mode
+const (
+	// PublicReadReport does not expose objects; public-read objects are listed in the result
+	// so that they can be exposed with the public access model of the target (e.g., bucket policy).
+	PublicReadReport = "report"
+
+	// PublicReadACL grants public-read with object ACLs (targets accepting the x-amz-acl header).
+	PublicReadACL = "acl"
+)
+
 // ============================================================================
 // Pipeline and Step Names (for consistent naming)
 // ============================================================================
@@ -107,6 +130,14 @@ type DataMigrationModel struct {
 	// "relay": Force relay via local machine.
 	Strategy string `json:"strategy,omitempty" default:"auto" validate:"omitempty,oneof=auto direct relay"`
 
+	// Mode determines what is migrated.
+	// "files": Copy the current data (default).
+	// "bucket-content": Copy all object versions with metadata, tags and public-read grants (object storage only).
+	Mode string `json:"mode,omitempty" default:"files" validate:"omitempty,oneof=files bucket-content"`
+
+	// BucketContent holds the options of the bucket-content mode (optional).
+	BucketContent *BucketContentOption `json:"bucketContent,omitempty"`
+
 	// EncryptionKeyID indicates that sensitive fields are encrypted.
 	// Empty string means plaintext, non-empty means encrypted with the specified key.
 	// The key is one-time use and will be deleted after decryption.
@@ -118,6 +149,17 @@ func (m DataMigrationModel) IsEncrypted() bool {
 	return m.EncryptionKeyID != ""
 }
 
+// BucketContentOption defines the options of the bucket-content migration mode.
+type BucketContentOption struct {
+	// CurrentVersionOnly copies only the current version of each object (default: all versions in order).
+	CurrentVersionOnly bool `json:"currentVersionOnly,omitempty"`
+
+	// PublicRead determines how public-read objects are mapped to the target.
+	// "report": Listed in the result to be exposed with the target's public access model (default).
+	// "acl": Granted public-read with object ACLs.
+	PublicRead string `json:"publicRead,omitempty" default:"report" validate:"omitempty,oneof=report acl"`
+}
+
 // ============================================================================
 // Data Location (Unified Structure)
 // ============================================================================
@@ -299,6 +341,19 @@ func Validate(dmm DataMigrationModel) error {
 	if err := validateLocation(dmm.Destination, "destination"); err != nil {
 		return err
 	}
+
+	switch dmm.Mode {
+	case "", ModeFiles:
+	case ModeBucketContent:
+		if !dmm.Source.IsObjectStorage() || !dmm.Destination.IsObjectStorage() {
+			return fmt.Errorf("mode %s requires object storage for both source and destination", ModeBucketContent)
+		}
+		if opt := dmm.BucketContent; opt != nil && opt.PublicRead != "" && opt.PublicRead != PublicReadReport && opt.PublicRead != PublicReadACL {
+			return fmt.Errorf("unsupported public-read mapping: %s", opt.PublicRead)
+		}
+	default:
+		return fmt.Errorf("unsupported mode: %s", dmm.Mode)
+	}
 	return nil
 }
 
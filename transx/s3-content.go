@@ -0,0 +1,148 @@
+package transx
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Object Content Access (bucket-content mode)
+// ============================================================================
+
+// ObjectVersionInfo represents a version (or a delete marker) of a storage object.
+type ObjectVersionInfo struct {
+	ObjectInfo
+	VersionId      string // Empty for unversioned objects
+	IsLatest       bool   // Whether this is the current version
+	IsDeleteMarker bool   // Whether this version is a delete marker
+}
+
+// ObjectAttributes holds the attributes of an object version preserved by the bucket-content mode.
+type ObjectAttributes struct {
+	ContentType        string
+	CacheControl       string
+	ContentEncoding    string
+	ContentDisposition string
+	UserMetadata       map[string]string // User-defined metadata (without the x-amz-meta- prefix)
+	Tags               map[string]string // Object tags
+	TagCount           int               // Number of tags reported by the source (set even if the tags are not readable)
+	PublicRead         bool              // Whether the object is readable by anyone
+}
+
+// S3ContentProvider is implemented by providers with full S3 API access
+// (object versions, user metadata, tags and ACLs), i.e., MinioProvider.
+//
+// Providers without it (spider, tumblebug) are accessed via presigned URLs:
+// only the current versions and the content headers (Content-Type, Cache-Control, ...) are copied.
+type S3ContentProvider interface {
+	S3Provider
+
+	// IsVersioningEnabled returns whether versioning is enabled on the bucket.
+	IsVersioningEnabled() (bool, error)
+
+	// ListObjectVersions lists all versions and delete markers of the objects with the given prefix.
+	ListObjectVersions(prefix string) ([]ObjectVersionInfo, error)
+
+	// GetObjectContent opens the object version (the current version if versionId is empty)
+	// and returns its content, size and attributes.
+	GetObjectContent(key, versionId string) (io.ReadCloser, int64, ObjectAttributes, error)
+
+	// IsPublicRead returns whether the current version of the object is readable by anyone.
+	IsPublicRead(key string) (bool, error)
+
+	// PutObjectContent writes the object with the attributes (a new version on versioned buckets).
+	PutObjectContent(key string, body io.Reader, size int64, attrs ObjectAttributes) error
+
+	// DeleteObject deletes the object (a delete marker on versioned buckets).
+	DeleteObject(key string) error
+}
+
+// getObjectViaPresignedURL downloads the current version of the object via a presigned URL.
+// The content headers and the user metadata headers (x-amz-meta-*, x-ms-meta-*) of the response are
+// returned as the attributes; tags are not readable, so only their count (x-amz-tagging-count) is returned.
+func getObjectViaPresignedURL(p S3Provider, key string) (io.ReadCloser, int64, ObjectAttributes, error) {
+	result, err := p.GeneratePresignedURL("download", key)
+	if err != nil {
+		return nil, 0, ObjectAttributes{}, fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	resp, err := http.Get(result.URL)
+	if err != nil {
+		return nil, 0, ObjectAttributes{}, fmt.Errorf("download request failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, 0, ObjectAttributes{}, fmt.Errorf("download failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	attrs := ObjectAttributes{
+		ContentType:        resp.Header.Get("Content-Type"),
+		CacheControl:       resp.Header.Get("Cache-Control"),
+		ContentEncoding:    resp.Header.Get("Content-Encoding"),
+		ContentDisposition: resp.Header.Get("Content-Disposition"),
+	}
+	for name, values := range resp.Header {
+		lower := strings.ToLower(name)
+		for _, prefix := range []string{"x-amz-meta-", "x-ms-meta-"} {
+			if strings.HasPrefix(lower, prefix) && len(values) > 0 {
+				if attrs.UserMetadata == nil {
+					attrs.UserMetadata = map[string]string{}
+				}
+				attrs.UserMetadata[strings.TrimPrefix(lower, prefix)] = values[0]
+			}
+		}
+	}
+	if count, err := strconv.Atoi(resp.Header.Get("x-amz-tagging-count")); err == nil {
+		attrs.TagCount = count
+	}
+
+	return resp.Body, resp.ContentLength, attrs, nil
+}
+
+// putObjectViaPresignedURL uploads the object via a presigned URL with its content headers.
+// User metadata, tags and ACLs are not applied since presigned URLs do not sign those (x-amz-*) headers.
+func putObjectViaPresignedURL(p S3Provider, key string, body io.Reader, size int64, attrs ObjectAttributes) error {
+	result, err := p.GeneratePresignedURL("upload", key)
+	if err != nil {
+		return fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, result.URL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+
+	for name, value := range map[string]string{
+		"Content-Type":        attrs.ContentType,
+		"Cache-Control":       attrs.CacheControl,
+		"Content-Encoding":    attrs.ContentEncoding,
+		"Content-Disposition": attrs.ContentDisposition,
+	} {
+		if value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	// Apply CSP-specific headers provided by Tumblebug (e.g. x-ms-blob-type for Azure).
+	for k, v := range result.RequiredHeaders {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
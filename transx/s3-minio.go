@@ -3,6 +3,7 @@ package transx
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/minio/minio-go/v7"
@@ -116,3 +117,136 @@ func (p *MinioProvider) DownloadFile(key, localPath string) error {
 	}
 	return nil
 }
+
+// ============================================================================
+// S3ContentProvider implementation (bucket-content mode)
+// ============================================================================
+
+// allUsersGroupURI is the ACL grantee URI of anyone (public access).
+const allUsersGroupURI = "http://acs.amazonaws.com/groups/global/AllUsers"
+
+// IsVersioningEnabled returns whether versioning is enabled on the bucket.
+func (p *MinioProvider) IsVersioningEnabled() (bool, error) {
+	cfg, err := p.client.GetBucketVersioning(context.Background(), p.bucket)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bucket versioning: %w", err)
+	}
+	return cfg.Enabled(), nil
+}
+
+// ListObjectVersions lists all versions and delete markers of the objects with the given prefix.
+func (p *MinioProvider) ListObjectVersions(prefix string) ([]ObjectVersionInfo, error) {
+	ctx := context.Background()
+	var versions []ObjectVersionInfo
+
+	objectCh := p.client.ListObjects(ctx, p.bucket, minio.ListObjectsOptions{
+		Prefix:       prefix,
+		Recursive:    true,
+		WithVersions: true,
+	})
+
+	for obj := range objectCh {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("error listing object versions: %w", obj.Err)
+		}
+		versions = append(versions, ObjectVersionInfo{
+			ObjectInfo: ObjectInfo{
+				Key:          obj.Key,
+				Size:         obj.Size,
+				LastModified: obj.LastModified.Format(time.RFC3339Nano),
+				ETag:         obj.ETag,
+			},
+			VersionId:      obj.VersionID,
+			IsLatest:       obj.IsLatest,
+			IsDeleteMarker: obj.IsDeleteMarker,
+		})
+	}
+
+	return versions, nil
+}
+
+// GetObjectContent opens the object version and returns its content, size and attributes.
+func (p *MinioProvider) GetObjectContent(key, versionId string) (io.ReadCloser, int64, ObjectAttributes, error) {
+	ctx := context.Background()
+
+	obj, err := p.client.GetObject(ctx, p.bucket, key, minio.GetObjectOptions{VersionID: versionId})
+	if err != nil {
+		return nil, 0, ObjectAttributes{}, fmt.Errorf("failed to get object: %w", err)
+	}
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, 0, ObjectAttributes{}, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	attrs := ObjectAttributes{
+		ContentType:        info.ContentType,
+		CacheControl:       info.Metadata.Get("Cache-Control"),
+		ContentEncoding:    info.Metadata.Get("Content-Encoding"),
+		ContentDisposition: info.Metadata.Get("Content-Disposition"),
+		TagCount:           info.UserTagCount,
+	}
+	if len(info.UserMetadata) > 0 {
+		attrs.UserMetadata = map[string]string{}
+		for k, v := range info.UserMetadata {
+			attrs.UserMetadata[k] = v
+		}
+	}
+	if attrs.TagCount > 0 {
+		t, err := p.client.GetObjectTagging(ctx, p.bucket, key, minio.GetObjectTaggingOptions{VersionID: versionId})
+		if err != nil {
+			obj.Close()
+			return nil, 0, ObjectAttributes{}, fmt.Errorf("failed to get object tags: %w", err)
+		}
+		attrs.Tags = t.ToMap()
+	}
+
+	return obj, info.Size, attrs, nil
+}
+
+// IsPublicRead returns whether the current version of the object is readable by anyone.
+func (p *MinioProvider) IsPublicRead(key string) (bool, error) {
+	info, err := p.client.GetObjectACL(context.Background(), p.bucket, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to get object ACL: %w", err)
+	}
+	for _, grant := range info.Grant {
+		if grant.Grantee.URI == allUsersGroupURI && (grant.Permission == "READ" || grant.Permission == "FULL_CONTROL") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PutObjectContent writes the object with the attributes.
+// Public-read objects are granted with the canned ACL (x-amz-acl: public-read).
+func (p *MinioProvider) PutObjectContent(key string, body io.Reader, size int64, attrs ObjectAttributes) error {
+	userMetadata := make(map[string]string, len(attrs.UserMetadata)+1)
+	for k, v := range attrs.UserMetadata {
+		userMetadata[k] = v
+	}
+	if attrs.PublicRead {
+		userMetadata["x-amz-acl"] = "public-read" // Sent as a header (not as user metadata) by minio-go
+	}
+
+	_, err := p.client.PutObject(context.Background(), p.bucket, key, body, size, minio.PutObjectOptions{
+		ContentType:        attrs.ContentType,
+		CacheControl:       attrs.CacheControl,
+		ContentEncoding:    attrs.ContentEncoding,
+		ContentDisposition: attrs.ContentDisposition,
+		UserMetadata:       userMetadata,
+		UserTags:           attrs.Tags,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put object: %w", err)
+	}
+	return nil
+}
+
+// DeleteObject deletes the object (a delete marker on versioned buckets).
+func (p *MinioProvider) DeleteObject(key string) error {
+	if err := p.client.RemoveObject(context.Background(), p.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
@@ -23,6 +23,12 @@ func Transfer(dmm DataMigrationModel) error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Bucket-content mode copies object versions and attributes directly between buckets
+	if dmm.Mode == ModeBucketContent {
+		_, err := MigrateBucketContent(dmm)
+		return err
+	}
+
 	// Plan the transfer pipeline
 	pipeline, err := Plan(dmm)
 	if err != nil {